@@ -78,6 +78,7 @@ function build() {
 	rm -rf "$DIST"/web/public/js/components
 	rm -f "$DIST"/web/public/js/components.src.js
 	cp "$ROOT"/configs/server.template.yaml "$DIST"/configs/
+	cp "$ROOT"/configs/setup.template.yaml "$DIST"/configs/
 
 	# change _plus.[ext] to .[ext]
 	if [ "${TAG}" = "plus" ]; then
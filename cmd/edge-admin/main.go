@@ -15,8 +15,10 @@ import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/gen"
 	"github.com/TeaOSLab/EdgeAdmin/internal/nodes"
 	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup/unattended"
 	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
 	executils "github.com/TeaOSLab/EdgeAdmin/internal/utils/exec"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/setuputils"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/updates/updateutils"
 	_ "github.com/TeaOSLab/EdgeCommon/pkg/langs/messages"
@@ -30,7 +32,7 @@ func main() {
 	var app = apps.NewAppCmd().
 		Version(teaconst.Version).
 		Product(teaconst.ProductName).
-		Usage(teaconst.ProcessName+" [-h|-v|start|stop|restart|service|daemon|reset|recover|demo|upgrade]").
+		Usage(teaconst.ProcessName+" [-h|-v|start|stop|restart|service|daemon|reset|recover|demo|upgrade|setup]").
 		Usage(teaconst.ProcessName+" [dev|prod]").
 		Option("-h", "show this help").
 		Option("-v", "show version").
@@ -45,6 +47,7 @@ func main() {
 		Option("dev", "switch to 'dev' mode").
 		Option("prod", "switch to 'prod' mode").
 		Option("upgrade [--url=URL]", "upgrade from official site or an url").
+		Option("setup --config=FILE", "setup the system unattended with a config file").
 		Option("install-local-node", "install a local node").
 		Option("security.reset", "reset security config")
 
@@ -199,6 +202,37 @@ func main() {
 		log.Println("restarting ...")
 		app.RunRestart()
 	})
+	app.On("setup", func() {
+		var configFile = ""
+		var flagSet = flag.NewFlagSet("", flag.ContinueOnError)
+		flagSet.StringVar(&configFile, "config", "", "setup config file")
+		_ = flagSet.Parse(os.Args[2:])
+		if len(configFile) == 0 {
+			fmt.Println("[ERROR]'--config' should not be empty")
+			os.Exit(1)
+		}
+
+		config, err := setuputils.LoadConfig(configFile)
+		if err != nil {
+			fmt.Println("[ERROR]load config failed: " + err.Error())
+			os.Exit(1)
+		}
+
+		err = unattended.NewInstaller(config).Run()
+		if err != nil {
+			fmt.Println("[ERROR]setup failed: " + err.Error())
+			os.Exit(1)
+		}
+
+		// 已经在运行的服务需要重启后才能使用新的监听端口
+		if config.Server != nil {
+			var sock = gosock.NewTmpSock(teaconst.ProcessName)
+			if sock.IsListening() {
+				fmt.Println("please restart the service to apply new server config")
+			}
+		}
+		fmt.Println("done")
+	})
 	app.On("security.reset", func() {
 		var sock = gosock.NewTmpSock(teaconst.ProcessName)
 		if !sock.IsListening() {
@@ -7,6 +7,7 @@ replace github.com/TeaOSLab/EdgeCommon => ../EdgeCommon
 replace github.com/TeaOSLab/EdgePlus => ../EdgePlus
 
 require (
+	github.com/ProtonMail/go-crypto v1.1.6
 	github.com/TeaOSLab/EdgeCommon v0.0.0-00010101000000-000000000000
 	github.com/TeaOSLab/EdgePlus v0.0.0-00010101000000-000000000000
 	github.com/cespare/xxhash/v2 v2.3.0
@@ -27,6 +28,7 @@ require (
 
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/frankban/quicktest v1.11.3 // indirect
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/go-task/slim-sprig/v3 v3.0.0 // indirect
@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+)
+
+const (
+	AdminActivityConfigSettingName = "adminActivityConfig"
+	AdminActivityStateSettingName  = "adminActivityState"
+)
+
+var activityConfigStore = newSysSettingStore[activityutils.Config](AdminActivityConfigSettingName, activityutils.NewConfig, nil)
+var activityStateStore = newSysSettingStore(AdminActivityStateSettingName, activityutils.NewState, (*activityutils.State).Clone)
+
+// LoadAdminActivityConfig 读取管理员异常活动检测配置
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminActivityConfig()
+func LoadAdminActivityConfig() (*activityutils.Config, error) {
+	return activityConfigStore.Load()
+}
+
+// UpdateAdminActivityConfig 修改管理员异常活动检测配置
+func UpdateAdminActivityConfig(config *activityutils.Config) error {
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+	return activityConfigStore.Save(config)
+}
+
+// LoadAdminActivityState 读取管理员异常活动检测状态
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminActivityState()
+func LoadAdminActivityState() (*activityutils.State, error) {
+	return activityStateStore.Load()
+}
+
+// UpdateAdminActivityState 修改管理员异常活动检测状态
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateAdminActivityState(f func(state *activityutils.State) error) error {
+	return activityStateStore.Update(f)
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+)
+
+const AdminDashboardsSettingName = "adminCustomDashboards"
+
+var boardStore = newSysSettingStore(AdminDashboardsSettingName, boardutils.NewStore, (*boardutils.Store).Clone)
+
+// LoadAdminBoardStore 读取管理员自定义看板
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminBoardStore()
+func LoadAdminBoardStore() (*boardutils.Store, error) {
+	return boardStore.Load()
+}
+
+// UpdateAdminBoardStore 修改管理员自定义看板
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateAdminBoardStore(f func(store *boardutils.Store) error) error {
+	return boardStore.Update(f)
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+)
+
+const AdminLDAPSettingName = "adminLDAPConfig"
+
+var ldapConfigStore = newSysSettingStore(AdminLDAPSettingName, ldaputils.NewConfig, (*ldaputils.Config).Clone)
+
+// LoadAdminLDAPConfig 读取LDAP认证配置
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminLDAPConfig()
+func LoadAdminLDAPConfig() (*ldaputils.Config, error) {
+	return ldapConfigStore.Load()
+}
+
+// UpdateAdminLDAPConfig 修改LDAP认证配置
+func UpdateAdminLDAPConfig(config *ldaputils.Config) error {
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+	err = ldapConfigStore.Save(config)
+	if err != nil {
+		return err
+	}
+
+	// 分组对应关系可能已经变化
+	ldaputils.SharedGroupCache.Clear()
+	return nil
+}
+
+const AdminLDAPLinksSettingName = "adminLDAPLinks"
+
+var ldapLinkStore = newSysSettingStore(AdminLDAPLinksSettingName, ldaputils.NewLinkStore, (*ldaputils.LinkStore).Clone)
+
+// LoadAdminLDAPLinks 读取LDAP用户和管理员的关联
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminLDAPLinks()
+func LoadAdminLDAPLinks() (*ldaputils.LinkStore, error) {
+	return ldapLinkStore.Load()
+}
+
+// UpdateAdminLDAPLinks 修改LDAP用户和管理员的关联
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateAdminLDAPLinks(f func(store *ldaputils.LinkStore) error) error {
+	return ldapLinkStore.Update(f)
+}
@@ -0,0 +1,26 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+)
+
+const AdminLoginLimitSettingName = "adminLoginLimitConfig"
+
+var loginLimitConfigStore = newSysSettingStore[loginlimitutils.Config](AdminLoginLimitSettingName, loginlimitutils.NewConfig, nil)
+
+// LoadAdminLoginLimitConfig 读取登录保护配置
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminLoginLimitConfig()
+func LoadAdminLoginLimitConfig() (*loginlimitutils.Config, error) {
+	return loginLimitConfigStore.Load()
+}
+
+// UpdateAdminLoginLimitConfig 修改登录保护配置
+func UpdateAdminLoginLimitConfig(config *loginlimitutils.Config) error {
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+	return loginLimitConfigStore.Save(config)
+}
@@ -93,34 +93,53 @@ func CheckAdmin(adminId int64) bool {
 
 // AllowModule 检查模块是否允许访问
 func AllowModule(adminId int64, module string) bool {
-	locker.Lock()
-	defer locker.Unlock()
-
 	if module == AdminModuleCodeCommon {
 		return true
 	}
 
+	locker.Lock()
 	if len(sharedAdminModuleMapping) == 0 {
 		_, _ = loadAdminModuleMapping()
 	}
-
 	list, ok := sharedAdminModuleMapping[adminId]
-	if ok {
-		return list.Allow(module)
+	locker.Unlock()
+
+	if !ok {
+		return false
+	}
+	if list.IsSuper {
+		return true
+	}
+
+	// 分配了角色的管理员使用角色中的权限
+	var grant = FindAdminGrant(adminId)
+	if grant != nil {
+		return grant.AllowModule(module)
 	}
 
-	return false
+	return list.Allow(module)
 }
 
 // FindFirstAdminModule 获取管理员第一个可访问模块
 func FindFirstAdminModule(adminId int64) (module AdminModuleCode, ok bool) {
 	locker.Lock()
-	defer locker.Unlock()
 	list, ok2 := sharedAdminModuleMapping[adminId]
+	locker.Unlock()
 	if ok2 {
 		if list.IsSuper {
 			return AdminModuleCodeDashboard, true
-		} else if len(list.Modules) > 0 {
+		}
+
+		var grant = FindAdminGrant(adminId)
+		if grant != nil {
+			var moduleCodes = grant.ModuleCodes()
+			if len(moduleCodes) > 0 {
+				return moduleCodes[0], true
+			}
+			return
+		}
+
+		if len(list.Modules) > 0 {
 			return list.Modules[0].Code, true
 		}
 	}
@@ -0,0 +1,108 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"errors"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
+)
+
+const (
+	AdminPasswordPolicySettingName  = "adminPasswordPolicy"
+	AdminPasswordHistorySettingName = "adminPasswordHistory"
+)
+
+var passwordPolicyStore = newSysSettingStore[passwordpolicyutils.Policy](AdminPasswordPolicySettingName, passwordpolicyutils.NewPolicy, nil)
+var passwordHistoryStore = newSysSettingStore(AdminPasswordHistorySettingName, passwordpolicyutils.NewHistory, (*passwordpolicyutils.History).Clone)
+
+// LoadAdminPasswordPolicy 读取密码策略
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminPasswordPolicy()
+func LoadAdminPasswordPolicy() (*passwordpolicyutils.Policy, error) {
+	return passwordPolicyStore.Load()
+}
+
+// UpdateAdminPasswordPolicy 修改密码策略
+func UpdateAdminPasswordPolicy(policy *passwordpolicyutils.Policy) error {
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+	return passwordPolicyStore.Save(policy)
+}
+
+// CheckPassword 检查密码是否符合策略，包括最近使用过的密码
+// kind 为 passwordpolicyutils.KindAdmin 或 passwordpolicyutils.KindUser，id 为0表示新账号
+func CheckPassword(kind string, id int64, username string, password string) error {
+	policy, err := passwordPolicyStore.Load()
+	if err != nil {
+		return err
+	}
+	if !policy.IsOn || (kind == passwordpolicyutils.KindUser && !policy.ApplyToUsers) {
+		return nil
+	}
+	err = policy.Check(password, username)
+	if err != nil {
+		return err
+	}
+
+	if id > 0 && policy.HistoryCount > 0 {
+		history, err := passwordHistoryStore.Load()
+		if err != nil {
+			return err
+		}
+		if history.IsReused(passwordpolicyutils.Key(kind, id), password, policy.HistoryCount) {
+			return errors.New("不能使用最近使用过的密码")
+		}
+	}
+	return nil
+}
+
+// RecordPasswordChange 记录密码修改
+func RecordPasswordChange(kind string, id int64, password string) error {
+	policy, err := passwordPolicyStore.Load()
+	if err != nil {
+		return err
+	}
+
+	// 即使策略未启用也记录，以便启用后能检查历史密码
+	var keep = policy.HistoryCount
+	if keep <= 0 {
+		keep = passwordpolicyutils.NewPolicy().HistoryCount
+	}
+	return passwordHistoryStore.Update(func(history *passwordpolicyutils.History) error {
+		history.Add(passwordpolicyutils.Key(kind, id), password, keep, time.Now())
+		return nil
+	})
+}
+
+// IsAdminPasswordExpired 检查管理员密码是否已经超过最长使用天数
+func IsAdminPasswordExpired(adminId int64) (bool, error) {
+	policy, err := passwordPolicyStore.Load()
+	if err != nil {
+		return false, err
+	}
+	if !policy.IsOn || policy.MaxAgeDays <= 0 {
+		return false, nil
+	}
+	history, err := passwordHistoryStore.Load()
+	if err != nil {
+		return false, err
+	}
+
+	var key = passwordpolicyutils.Key(passwordpolicyutils.KindAdmin, adminId)
+	var now = time.Now()
+
+	// 没有修改记录的管理员从现在开始计算
+	if _, ok := history.Records[key]; !ok {
+		err = passwordHistoryStore.Update(func(history *passwordpolicyutils.History) error {
+			if _, ok := history.Records[key]; !ok {
+				history.Touch(key, now)
+			}
+			return nil
+		})
+		return false, err
+	}
+	return history.IsExpired(key, policy.MaxAgeDays, now), nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/iwind/TeaGo/logs"
+)
+
+const AdminRBACSettingName = "adminRBACPolicy"
+
+var rbacPolicyStore = newSysSettingStore(AdminRBACSettingName, rbacutils.NewPolicy, (*rbacutils.Policy).Clone)
+
+// LoadAdminRBACPolicy 读取角色定义
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminRBACPolicy()
+func LoadAdminRBACPolicy() (*rbacutils.Policy, error) {
+	return rbacPolicyStore.Load()
+}
+
+// UpdateAdminRBACPolicy 修改角色定义
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateAdminRBACPolicy(f func(policy *rbacutils.Policy) error) error {
+	return rbacPolicyStore.Update(f)
+}
+
+// FindAdminGrant 查找管理员通过角色获得的授权
+// 超级管理员和没有分配角色的管理员返回nil，此时使用管理员自身的模块权限
+func FindAdminGrant(adminId int64) *rbacutils.Grant {
+	locker.Lock()
+	list, ok := sharedAdminModuleMapping[adminId]
+	locker.Unlock()
+	if ok && list.IsSuper {
+		return nil
+	}
+
+	policy, err := LoadAdminRBACPolicy()
+	if err != nil {
+		logs.Println("[RBAC]load policy failed: " + err.Error())
+		return nil
+	}
+	return policy.FindAdminGrant(adminId)
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+)
+
+const (
+	AdminSSOSettingName      = "adminSSOConfig"
+	AdminSSOLinksSettingName = "adminSSOLinks"
+)
+
+var ssoConfigStore = newSysSettingStore(AdminSSOSettingName, ssoutils.NewConfig, (*ssoutils.Config).Clone)
+var ssoLinkStore = newSysSettingStore(AdminSSOLinksSettingName, newSSOLinks, cloneSSOLinks)
+
+// LoadAdminSSOConfig 读取单点登录配置
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminSSOConfig()
+func LoadAdminSSOConfig() (*ssoutils.Config, error) {
+	return ssoConfigStore.Load()
+}
+
+// UpdateAdminSSOConfig 修改单点登录配置
+func UpdateAdminSSOConfig(config *ssoutils.Config) error {
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+	return ssoConfigStore.Save(config)
+}
+
+// FindAdminSSOLink 查找身份提供商中的用户关联的管理员ID
+func FindAdminSSOLink(protocol string, subject string) (adminId int64, err error) {
+	links, err := ssoLinkStore.Load()
+	if err != nil {
+		return 0, err
+	}
+	return (*links)[protocol+"|"+subject], nil
+}
+
+// UpdateAdminSSOLink 关联身份提供商中的用户和管理员
+func UpdateAdminSSOLink(protocol string, subject string, adminId int64) error {
+	return ssoLinkStore.Update(func(links *map[string]int64) error {
+		(*links)[protocol+"|"+subject] = adminId
+		return nil
+	})
+}
+
+// 身份提供商中的用户和管理员的关联，键为"协议|用户标识"
+func newSSOLinks() *map[string]int64 {
+	var links = map[string]int64{}
+	return &links
+}
+
+func cloneSSOLinks(links *map[string]int64) *map[string]int64 {
+	var newLinks = newSSOLinks()
+	for key, adminId := range *links {
+		(*newLinks)[key] = adminId
+	}
+	return newLinks
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+const (
+	AdminWebAuthnSettingName       = "adminWebAuthn"
+	AdminWebAuthnPolicySettingName = "adminWebAuthnPolicy"
+)
+
+var webAuthnStore = newSysSettingStore(AdminWebAuthnSettingName, webauthnutils.NewStore, (*webauthnutils.Store).Clone)
+var webAuthnPolicyStore = newSysSettingStore[webauthnutils.Policy](AdminWebAuthnPolicySettingName, webauthnutils.NewPolicy, nil)
+
+// LoadAdminWebAuthnStore 读取管理员认证器信息
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateAdminWebAuthnStore()
+func LoadAdminWebAuthnStore() (*webauthnutils.Store, error) {
+	return webAuthnStore.Load()
+}
+
+// UpdateAdminWebAuthnStore 修改管理员认证器信息
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateAdminWebAuthnStore(f func(store *webauthnutils.Store) error) error {
+	return webAuthnStore.Update(f)
+}
+
+// LoadAdminWebAuthnPolicy 读取认证器安全策略
+func LoadAdminWebAuthnPolicy() (*webauthnutils.Policy, error) {
+	return webAuthnPolicyStore.Load()
+}
+
+// UpdateAdminWebAuthnPolicy 修改认证器安全策略
+func UpdateAdminWebAuthnPolicy(policy *webauthnutils.Policy) error {
+	return webAuthnPolicyStore.Save(policy)
+}
+
+// FindSuperAdminsWithoutWebAuthn 查找尚未注册安全密钥的超级管理员
+// 要求超级管理员必须使用安全密钥登录时，这些管理员将无法登录
+func FindSuperAdminsWithoutWebAuthn() ([]*pb.Admin, error) {
+	store, err := LoadAdminWebAuthnStore()
+	if err != nil {
+		return nil, err
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return nil, err
+	}
+	countResp, err := rpcClient.AdminRPC().CountAllEnabledAdmins(rpcClient.Context(0), &pb.CountAllEnabledAdminsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var result = []*pb.Admin{}
+	if countResp.Count == 0 {
+		return result, nil
+	}
+	adminsResp, err := rpcClient.AdminRPC().ListEnabledAdmins(rpcClient.Context(0), &pb.ListEnabledAdminsRequest{
+		Offset: 0,
+		Size:   countResp.Count,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, admin := range adminsResp.Admins {
+		if admin.IsSuper && !store.HasCredentials(admin.Id) {
+			result = append(result, admin)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+)
+
+const (
+	SMTPSettingName          = "adminSMTPConfig"
+	ServerReportsSettingName = "serverTrafficReports"
+)
+
+var smtpConfigStore = newSysSettingStore[reportutils.SMTPConfig](SMTPSettingName, reportutils.NewSMTPConfig, nil)
+var serverReportStore = newSysSettingStore(ServerReportsSettingName, reportutils.NewStore, (*reportutils.Store).Clone)
+
+// LoadSMTPConfig 读取发信服务器设置
+// 返回的是复制后的对象，可以直接修改
+func LoadSMTPConfig() (*reportutils.SMTPConfig, error) {
+	config, err := smtpConfigStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	var configCopy = *config
+	return &configCopy, nil
+}
+
+// UpdateSMTPConfig 修改发信服务器设置
+func UpdateSMTPConfig(config *reportutils.SMTPConfig) error {
+	var configCopy = *config
+	return smtpConfigStore.Save(&configCopy)
+}
+
+// LoadServerReportStore 读取定时报表
+// 返回的对象为共享对象，不能直接修改，需要修改时请使用 UpdateServerReportStore()
+func LoadServerReportStore() (*reportutils.Store, error) {
+	return serverReportStore.Load()
+}
+
+// UpdateServerReportStore 修改定时报表
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func UpdateServerReportStore(f func(store *reportutils.Store) error) error {
+	return serverReportStore.Update(f)
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configloaders
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// sysSettingStore 以JSON格式保存在系统设置中的共享对象
+// Load() 返回的对象为共享对象，不能直接修改；Update() 修改的是复制后的对象，保存成功后才替换共享对象
+type sysSettingStore[T any] struct {
+	code     string
+	newValue func() *T
+	clone    func(value *T) *T // 为nil时只能使用 Save() 整体替换
+
+	shared *T
+	locker sync.Mutex
+}
+
+func newSysSettingStore[T any](code string, newValue func() *T, clone func(value *T) *T) *sysSettingStore[T] {
+	return &sysSettingStore[T]{
+		code:     code,
+		newValue: newValue,
+		clone:    clone,
+	}
+}
+
+// Load 读取共享对象
+func (this *sysSettingStore[T]) Load() (*T, error) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	return this.load()
+}
+
+// Update 修改对象
+// 修改函数 f 接收的是复制后的对象，返回错误时不会保存
+func (this *sysSettingStore[T]) Update(f func(value *T) error) error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	value, err := this.load()
+	if err != nil {
+		return err
+	}
+	var newValue = this.clone(value)
+	err = f(newValue)
+	if err != nil {
+		return err
+	}
+	return this.save(newValue)
+}
+
+// Save 保存整个对象
+func (this *sysSettingStore[T]) Save(value *T) error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	return this.save(value)
+}
+
+func (this *sysSettingStore[T]) load() (*T, error) {
+	if this.shared != nil {
+		return this.shared, nil
+	}
+	var value = this.newValue()
+	err := readSysSettingJSON(this.code, value)
+	if err != nil {
+		return nil, err
+	}
+	if this.clone != nil {
+		value = this.clone(value)
+	}
+	this.shared = value
+	return this.shared, nil
+}
+
+func (this *sysSettingStore[T]) save(value *T) error {
+	err := writeSysSettingJSON(this.code, value)
+	if err != nil {
+		return err
+	}
+	this.shared = value
+	return nil
+}
+
+// 读取JSON格式的系统设置
+func readSysSettingJSON(code string, ptr any) error {
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(rpcClient.Context(0), &pb.ReadSysSettingRequest{
+		Code: code,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.ValueJSON) > 0 {
+		return json.Unmarshal(resp.ValueJSON, ptr)
+	}
+	return nil
+}
+
+// 保存JSON格式的系统设置
+func writeSysSettingJSON(code string, value any) error {
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(rpcClient.Context(0), &pb.UpdateSysSettingRequest{
+		Code:      code,
+		ValueJSON: valueJSON,
+	})
+	return err
+}
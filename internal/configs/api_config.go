@@ -23,6 +23,10 @@ type APIConfig struct {
 	RPCEndpoints     []string `yaml:"rpc.endpoints,flow" json:"rpc.endpoints"`
 	RPCDisableUpdate bool     `yaml:"rpc.disableUpdate" json:"rpc.disableUpdate"`
 
+	// 按延迟划分的地址梯队，优先使用靠前梯队中的地址，其余梯队作为备用
+	// 由API节点同步任务自动生成，为空时所有地址同等对待
+	RPCEndpointTiers [][]string `yaml:"rpc.endpointTiers,omitempty" json:"rpc.endpointTiers"`
+
 	NodeId string `yaml:"nodeId"`
 	Secret string `yaml:"secret"`
 }
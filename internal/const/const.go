@@ -12,8 +12,9 @@ const (
 
 	EncryptMethod = "aes-256-cfb"
 
-	CookieSID      = "geadsid"
-	SessionAdminId = "adminId"
+	CookieSID              = "geadsid"
+	SessionAdminId         = "adminId"
+	SessionPasswordExpired = "@passwordExpired"
 
 	SystemdServiceName = "edge-admin"
 	UpdatesURL         = "https://goedge.cloud/api/boot/versions?os=${os}&arch=${arch}&version=${version}"
@@ -0,0 +1,20 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsclients
+
+import (
+	"github.com/iwind/TeaGo/maps"
+)
+
+// ProviderInterface DNS服务商接口
+// 管理平台中的服务商实现只用来在保存前检查连接和权限，记录的同步由API节点完成
+type ProviderInterface interface {
+	// Auth 认证并初始化参数
+	Auth(params maps.Map) error
+
+	// CheckConnectivity 检查是否能连接到DNS服务器
+	CheckConnectivity() error
+
+	// CheckPermission 检查是否有修改记录的权限
+	CheckPermission() error
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsclients
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/rands"
+	"github.com/miekg/dns"
+)
+
+const ProviderTypeRFC2136 = "rfc2136"
+
+// TSIG算法
+var RFC2136TSIGAlgorithms = []string{
+	"hmac-sha256",
+	"hmac-sha512",
+	"hmac-sha1",
+	"hmac-md5",
+}
+
+// RFC2136Provider 检查RFC 2136动态更新服务器的连接和权限
+type RFC2136Provider struct {
+	server  string // host:port
+	zone    string // 以点结尾
+	network string // udp|tcp
+	timeout time.Duration
+	keyName string // 以点结尾
+	keyAlgo string // 以点结尾
+	secret  string
+	hasTSIG bool
+}
+
+func NewRFC2136Provider() *RFC2136Provider {
+	return &RFC2136Provider{}
+}
+
+// Auth 认证
+// 参数：server, zone, network, tsigKeyName, tsigAlgorithm, tsigSecret, timeout（秒）
+func (this *RFC2136Provider) Auth(params maps.Map) error {
+	var server = strings.TrimSpace(params.GetString("server"))
+	if len(server) == 0 {
+		return errors.New("'server' should not be empty")
+	}
+	_, _, err := net.SplitHostPort(server)
+	if err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	this.server = server
+
+	var zone = strings.TrimSpace(params.GetString("zone"))
+	if len(zone) == 0 {
+		return errors.New("'zone' should not be empty")
+	}
+	this.zone = dns.Fqdn(strings.ToLower(zone))
+
+	this.network = params.GetString("network")
+	if this.network != "tcp" {
+		this.network = "udp"
+	}
+
+	var timeoutSeconds = params.GetInt("timeout")
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 5
+	}
+	this.timeout = time.Duration(timeoutSeconds) * time.Second
+
+	var keyName = strings.TrimSpace(params.GetString("tsigKeyName"))
+	var secret = strings.TrimSpace(params.GetString("tsigSecret"))
+	if len(keyName) > 0 || len(secret) > 0 {
+		if len(keyName) == 0 {
+			return errors.New("'tsigKeyName' should not be empty")
+		}
+		if len(secret) == 0 {
+			return errors.New("'tsigSecret' should not be empty")
+		}
+		_, err = base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return errors.New("'tsigSecret' should be a base64 encoded string")
+		}
+
+		var algo = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(params.GetString("tsigAlgorithm")), "."))
+		if len(algo) == 0 {
+			algo = "hmac-sha256"
+		}
+		switch algo {
+		case "hmac-sha256":
+			this.keyAlgo = dns.HmacSHA256
+		case "hmac-sha512":
+			this.keyAlgo = dns.HmacSHA512
+		case "hmac-sha1":
+			this.keyAlgo = dns.HmacSHA1
+		case "hmac-md5":
+			this.keyAlgo = dns.HmacMD5
+		default:
+			return errors.New("unsupported tsig algorithm '" + algo + "'")
+		}
+		this.keyName = dns.Fqdn(strings.ToLower(keyName))
+		this.secret = secret
+		this.hasTSIG = true
+	}
+
+	return nil
+}
+
+// CheckConnectivity 查询区域的SOA记录
+func (this *RFC2136Provider) CheckConnectivity() error {
+	var m = new(dns.Msg)
+	m.SetQuestion(this.zone, dns.TypeSOA)
+	m.RecursionDesired = false
+
+	r, err := this.exchange(m, false)
+	if err != nil {
+		return err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return errors.New("query SOA failed: " + dns.RcodeToString[r.Rcode])
+	}
+	for _, answer := range r.Answer {
+		_, ok := answer.(*dns.SOA)
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("the server is not authoritative for zone '" + this.zone + "'")
+}
+
+// CheckPermission 添加并删除一条临时TXT记录
+func (this *RFC2136Provider) CheckPermission() error {
+	rr, err := dns.NewRR("_goedge-check-" + strings.ToLower(rands.HexString(8)) + "." + this.zone + " 60 IN TXT " + strconv.Quote("goedge-check"))
+	if err != nil {
+		return err
+	}
+
+	var m = new(dns.Msg)
+	m.SetUpdate(this.zone)
+	m.Insert([]dns.RR{rr})
+	err = this.update(m)
+	if err != nil {
+		return errors.New("add record failed: " + err.Error())
+	}
+
+	m = new(dns.Msg)
+	m.SetUpdate(this.zone)
+	m.Remove([]dns.RR{rr})
+	err = this.update(m)
+	if err != nil {
+		return errors.New("delete record failed: " + err.Error())
+	}
+	return nil
+}
+
+func (this *RFC2136Provider) update(m *dns.Msg) error {
+	r, err := this.exchange(m, true)
+	if err != nil {
+		return err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return errors.New("update failed: " + dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
+
+func (this *RFC2136Provider) exchange(m *dns.Msg, sign bool) (*dns.Msg, error) {
+	var client = &dns.Client{
+		Net:     this.network,
+		Timeout: this.timeout,
+	}
+	if sign && this.hasTSIG {
+		client.TsigSecret = map[string]string{this.keyName: this.secret}
+		m.SetTsig(this.keyName, this.keyAlgo, 300, time.Now().Unix())
+	}
+	r, _, err := client.Exchange(m, this.server)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
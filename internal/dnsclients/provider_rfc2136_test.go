@@ -0,0 +1,135 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsclients_test
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/dnsclients"
+	"github.com/iwind/TeaGo/assert"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/miekg/dns"
+)
+
+const testTSIGKeyName = "goedge."
+const testTSIGSecret = "c2VjcmV0LWtleS1mb3ItdGVzdGluZy0xMjM0NTY3OA=="
+
+// 启动一个只在内存中保存记录的DNS服务器
+func startTestDNSServer(t *testing.T) (addr string, records map[string]dns.RR, stop func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("listen failed: " + err.Error())
+	}
+
+	records = map[string]dns.RR{}
+	var locker = &sync.Mutex{}
+
+	var mux = dns.NewServeMux()
+	mux.HandleFunc("example.com.", func(w dns.ResponseWriter, req *dns.Msg) {
+		var resp = new(dns.Msg)
+		resp.SetReply(req)
+
+		if req.Opcode == dns.OpcodeUpdate {
+			if req.IsTsig() == nil || w.TsigStatus() != nil {
+				resp.Rcode = dns.RcodeRefused
+			} else {
+				locker.Lock()
+				for _, rr := range req.Ns {
+					if rr.Header().Class == dns.ClassNONE {
+						delete(records, rr.Header().Name)
+					} else {
+						records[rr.Header().Name] = rr
+					}
+				}
+				locker.Unlock()
+			}
+			if req.IsTsig() != nil {
+				resp.SetTsig(testTSIGKeyName, dns.HmacSHA256, 300, int64(req.IsTsig().TimeSigned))
+			}
+			_ = w.WriteMsg(resp)
+			return
+		}
+
+		if len(req.Question) > 0 && req.Question[0].Qtype == dns.TypeSOA {
+			soa, _ := dns.NewRR("example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 3600 600 86400 60")
+			resp.Answer = append(resp.Answer, soa)
+		}
+		_ = w.WriteMsg(resp)
+	})
+
+	var server = &dns.Server{
+		PacketConn: conn,
+		Handler:    mux,
+		TsigSecret: map[string]string{testTSIGKeyName: testTSIGSecret},
+		MsgAcceptFunc: func(dh dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	var started = make(chan struct{})
+	server.NotifyStartedFunc = func() {
+		close(started)
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+
+	return conn.LocalAddr().String(), records, func() {
+		_ = server.Shutdown()
+	}
+}
+
+func TestRFC2136Provider_Auth(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var provider = dnsclients.NewRFC2136Provider()
+	a.IsNotNil(provider.Auth(maps.Map{}))
+	a.IsNotNil(provider.Auth(maps.Map{"server": "127.0.0.1"}))
+	a.IsNil(provider.Auth(maps.Map{"server": "127.0.0.1", "zone": "example.com"}))
+	a.IsNotNil(provider.Auth(maps.Map{"server": "127.0.0.1", "zone": "example.com", "tsigKeyName": "goedge"}))
+	a.IsNotNil(provider.Auth(maps.Map{"server": "127.0.0.1", "zone": "example.com", "tsigKeyName": "goedge", "tsigSecret": "not base64!"}))
+	a.IsNotNil(provider.Auth(maps.Map{"server": "127.0.0.1", "zone": "example.com", "tsigKeyName": "goedge", "tsigSecret": testTSIGSecret, "tsigAlgorithm": "hmac-unknown"}))
+	a.IsNil(provider.Auth(maps.Map{"server": "127.0.0.1", "zone": "example.com", "tsigKeyName": "goedge", "tsigSecret": testTSIGSecret}))
+}
+
+func TestRFC2136Provider_Check(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	addr, records, stop := startTestDNSServer(t)
+	defer stop()
+
+	var provider = dnsclients.NewRFC2136Provider()
+	err := provider.Auth(maps.Map{
+		"server":        addr,
+		"zone":          "example.com",
+		"tsigKeyName":   "goedge",
+		"tsigAlgorithm": "hmac-sha256",
+		"tsigSecret":    testTSIGSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.IsNil(provider.CheckConnectivity())
+	a.IsNil(provider.CheckPermission())
+	// 临时记录已经被删除
+	a.IsTrue(len(records) == 0)
+
+	// 没有TSIG时应该被拒绝
+	var noKeyProvider = dnsclients.NewRFC2136Provider()
+	a.IsNil(noKeyProvider.Auth(maps.Map{
+		"server": addr,
+		"zone":   "example.com",
+	}))
+	a.IsNil(noKeyProvider.CheckConnectivity())
+	a.IsNotNil(noKeyProvider.CheckPermission())
+}
+
+func TestFindProvider(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsNotNil(dnsclients.FindProvider(dnsclients.ProviderTypeRFC2136))
+	a.IsNil(dnsclients.FindProvider("unknown"))
+	a.IsTrue(len(dnsclients.AllProviderTypes()) > 0)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsclients
+
+import (
+	"sync"
+)
+
+// ProviderType 服务商类型定义
+type ProviderType struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	factory func() ProviderInterface
+}
+
+var providerTypes = []*ProviderType{}
+var providerTypesLocker = &sync.RWMutex{}
+
+func init() {
+	RegisterProvider(ProviderTypeRFC2136, "RFC 2136动态更新", "通过RFC 2136动态更新协议和TSIG密钥管理自建的BIND、PowerDNS等DNS服务器；管理平台只检查连接和权限，记录由API节点同步，需要API节点支持此类型。", func() ProviderInterface {
+		return NewRFC2136Provider()
+	})
+}
+
+// RegisterProvider 注册服务商类型
+// 如果代号已经存在，则覆盖原有定义
+func RegisterProvider(code string, name string, description string, factory func() ProviderInterface) {
+	providerTypesLocker.Lock()
+	defer providerTypesLocker.Unlock()
+
+	var providerType = &ProviderType{
+		Code:        code,
+		Name:        name,
+		Description: description,
+		factory:     factory,
+	}
+	for index, t := range providerTypes {
+		if t.Code == code {
+			providerTypes[index] = providerType
+			return
+		}
+	}
+	providerTypes = append(providerTypes, providerType)
+}
+
+// AllProviderTypes 所有注册的服务商类型
+func AllProviderTypes() []*ProviderType {
+	providerTypesLocker.RLock()
+	defer providerTypesLocker.RUnlock()
+
+	var result = []*ProviderType{}
+	result = append(result, providerTypes...)
+	return result
+}
+
+// FindProvider 根据代号创建服务商实例，找不到时返回nil
+func FindProvider(code string) ProviderInterface {
+	providerTypesLocker.RLock()
+	defer providerTypesLocker.RUnlock()
+
+	for _, t := range providerTypes {
+		if t.Code == code {
+			return t.factory()
+		}
+	}
+	return nil
+}
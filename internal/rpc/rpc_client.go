@@ -34,6 +34,7 @@ import (
 type RPCClient struct {
 	apiConfig *configs.APIConfig
 	conns     []*grpc.ClientConn
+	tiers     [][]*grpc.ClientConn // 按延迟划分的连接梯队
 
 	locker sync.RWMutex
 }
@@ -493,6 +494,7 @@ func (this *RPCClient) init() error {
 
 	// 重新连接
 	var conns = []*grpc.ClientConn{}
+	var endpointConnMap = map[string]*grpc.ClientConn{} // endpoint => conn
 	for _, endpoint := range this.apiConfig.RPCEndpoints {
 		u, err := url.Parse(endpoint)
 		if err != nil {
@@ -536,13 +538,41 @@ func (this *RPCClient) init() error {
 			return err
 		}
 		conns = append(conns, conn)
+		endpointConnMap[endpoint] = conn
 	}
 	if len(conns) == 0 {
 		return errors.New("[RPC]no available endpoints")
 	}
 
+	// 梯队，不在梯队中的连接放在最后
+	var tiers = [][]*grpc.ClientConn{}
+	var tieredConnMap = map[*grpc.ClientConn]bool{}
+	for _, tierEndpoints := range this.apiConfig.RPCEndpointTiers {
+		var tier = []*grpc.ClientConn{}
+		for _, endpoint := range tierEndpoints {
+			conn, ok := endpointConnMap[endpoint]
+			if ok && !tieredConnMap[conn] {
+				tier = append(tier, conn)
+				tieredConnMap[conn] = true
+			}
+		}
+		if len(tier) > 0 {
+			tiers = append(tiers, tier)
+		}
+	}
+	var leftConns = []*grpc.ClientConn{}
+	for _, conn := range conns {
+		if !tieredConnMap[conn] {
+			leftConns = append(leftConns, conn)
+		}
+	}
+	if len(leftConns) > 0 {
+		tiers = append(tiers, leftConns)
+	}
+
 	// 这里不需要加锁，因为会和pickConn冲突
 	this.conns = conns
+	this.tiers = tiers
 	return nil
 }
 
@@ -557,6 +587,23 @@ func (this *RPCClient) pickConn() *grpc.ClientConn {
 		if countConns == 1 {
 			return this.conns[0]
 		}
+
+		// 优先使用延迟低的梯队，梯队中的连接都不可用时才使用下一个梯队
+		if len(this.tiers) > 1 {
+			for _, tier := range this.tiers {
+				var availableConns = []*grpc.ClientConn{}
+				for _, conn := range tier {
+					var state = conn.GetState()
+					if state == connectivity.Ready || state == connectivity.Idle || state == connectivity.Connecting {
+						availableConns = append(availableConns, conn)
+					}
+				}
+				if len(availableConns) > 0 {
+					return this.randConn(availableConns)
+				}
+			}
+		}
+
 		for _, state := range []connectivity.State{
 			connectivity.Ready,
 			connectivity.Idle,
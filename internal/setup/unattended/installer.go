@@ -0,0 +1,481 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package unattended
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/setuputils"
+	adminserverutils "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/server/admin-server-utils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/systemconfigs"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/dbs"
+	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/maps"
+	stringutil "github.com/iwind/TeaGo/utils/string"
+	"github.com/iwind/gosock/pkg/gosock"
+	"gopkg.in/yaml.v3"
+)
+
+// Installer 无人值守安装
+// 先检查所有配置，然后依次安装API节点、设置管理员和监听端口；重复执行时会跳过已经完成的API节点安装
+type Installer struct {
+	config     *setuputils.Config
+	apiNodeDir string
+}
+
+// NewInstaller 获取新对象
+func NewInstaller(config *setuputils.Config) *Installer {
+	return &Installer{
+		config:     config,
+		apiNodeDir: Tea.Root + "/edge-api",
+	}
+}
+
+// Run 执行安装
+func (this *Installer) Run() error {
+	this.log("validating config ...")
+	err := this.config.Validate()
+	if err != nil {
+		return errors.New("invalid config: " + err.Error())
+	}
+
+	// 是否已经安装过
+	apiConfig, err := configs.LoadAPIConfig()
+	if err == nil {
+		var endpoint = this.config.APINode.Endpoint()
+		if !lists.ContainsString(apiConfig.RPCEndpoints, endpoint) {
+			return errors.New("already configured with api endpoints '" + strings.Join(apiConfig.RPCEndpoints, ", ") + "', not '" + endpoint + "', run 'reset' command first if you want to change it")
+		}
+		this.log("already configured, skip installing api node")
+
+		err = this.checkServer()
+		if err != nil {
+			return err
+		}
+		return this.configure(apiConfig)
+	}
+
+	// 安装前检查
+	switch this.config.APINode.Mode {
+	case setuputils.APINodeModeNew:
+		err = this.checkNewAPINode()
+	case setuputils.APINodeModeOld:
+		err = this.checkOldAPINode()
+	}
+	if err != nil {
+		return err
+	}
+	err = this.checkServer()
+	if err != nil {
+		return err
+	}
+
+	// 安装API节点
+	if this.config.APINode.Mode == setuputils.APINodeModeNew {
+		apiConfig, err = this.installNewAPINode()
+		if err != nil {
+			return err
+		}
+	} else {
+		apiConfig = this.oldAPIConfig()
+	}
+
+	err = this.configure(apiConfig)
+	if err != nil {
+		return err
+	}
+
+	// 最后写入API节点配置，表示安装完成
+	err = apiConfig.WriteFile(Tea.ConfigFile(configs.ConfigFileName))
+	if err != nil {
+		return errors.New("write api config failed: " + err.Error())
+	}
+
+	return nil
+}
+
+// 检查管理系统目录下的edge-api和数据库
+func (this *Installer) checkNewAPINode() error {
+	this.log("checking edge-api ...")
+	for _, dir := range []string{"edge-api", "edge-api/configs", "edge-api/bin"} {
+		var searchDir = Tea.Root + "/" + dir
+		_, err := os.Stat(searchDir)
+		if err != nil {
+			return errors.New("could not find '" + searchDir + "': " + err.Error())
+		}
+	}
+
+	this.log("checking database ...")
+	var dbConfig = this.config.DB
+	var dsn = url.QueryEscape(dbConfig.Username) + ":" + dbConfig.Password + "@tcp(" + dbConfig.Addr() + ")/"
+	db, err := dbs.NewInstanceFromConfig(&dbs.DBConfig{
+		Driver: "mysql",
+		Dsn:    dsn + dbConfig.Database,
+		Prefix: "",
+	})
+	if err != nil {
+		return errors.New("invalid database config: " + err.Error())
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	err = db.Raw().Ping()
+	if err != nil {
+		if !strings.Contains(err.Error(), "Error 1049") {
+			return errors.New("could not connect to database: " + err.Error())
+		}
+
+		// 数据库不存在时尝试创建
+		this.log("creating database '" + dbConfig.Database + "' ...")
+		rootDB, err := dbs.NewInstanceFromConfig(&dbs.DBConfig{
+			Driver: "mysql",
+			Dsn:    dsn,
+			Prefix: "",
+		})
+		if err != nil {
+			return errors.New("create database failed: " + err.Error())
+		}
+		_, err = rootDB.Exec("CREATE DATABASE `" + dbConfig.Database + "`")
+		_ = rootDB.Close()
+		if err != nil {
+			return errors.New("create database failed: " + err.Error())
+		}
+	}
+
+	// 检查权限
+	var testTable = "edgeTest1"
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS `" + testTable + "` (\n  `id` int(11) NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;")
+	if err != nil {
+		return errors.New("the database user could not create tables, please check CREATE privilege: " + err.Error())
+	}
+	_, err = db.Exec("ALTER TABLE `" + testTable + "` CHANGE `id` `id` int(11) NOT NULL AUTO_INCREMENT")
+	if err != nil {
+		return errors.New("the database user could not alter tables, please check ALTER privilege: " + err.Error())
+	}
+	_, _ = db.Exec("DROP TABLE `" + testTable + "`")
+
+	// 检查版本
+	one, err := db.FindOne("SELECT VERSION() AS v")
+	if err != nil {
+		return errors.New("check database version failed: " + err.Error())
+	}
+	if one == nil {
+		return errors.New("check database version failed: no version found")
+	}
+	var version = one.GetString("v")
+	if stringutil.VersionCompare(version, "5.7.8") < 0 {
+		return errors.New("database version should be greater than v5.7.8, current: v" + version)
+	}
+
+	return nil
+}
+
+// 检查已有的API节点
+func (this *Installer) checkOldAPINode() error {
+	this.log("checking api node '" + this.config.APINode.Endpoint() + "' ...")
+	client, err := rpc.NewRPCClient(this.oldAPIConfig(), false)
+	if err != nil {
+		return errors.New("invalid api node config: " + err.Error())
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	_, err = client.APINodeRPC().FindCurrentAPINodeVersion(client.APIContext(0), &pb.FindCurrentAPINodeVersionRequest{})
+	if err != nil {
+		return errors.New("could not connect to api node: " + err.Error())
+	}
+	return nil
+}
+
+// 检查HTTPS证书
+func (this *Installer) checkServer() error {
+	var serverConfig = this.config.Server
+	if serverConfig == nil || !serverConfig.HTTPS.On {
+		return nil
+	}
+
+	this.log("checking https cert ...")
+	for _, file := range []string{serverConfig.HTTPS.Cert, serverConfig.HTTPS.Key} {
+		_, err := os.Stat(this.serverFile(file))
+		if err != nil {
+			return errors.New("could not read https cert file '" + file + "': " + err.Error())
+		}
+	}
+	return nil
+}
+
+// 安装管理系统目录下的API节点
+func (this *Installer) installNewAPINode() (*configs.APIConfig, error) {
+	var apiNodeConfig = this.config.APINode
+
+	// 保存数据库配置
+	this.log("writing database config ...")
+	err := WriteAPIDBConfig(this.apiNodeDir, &configs.SimpleDBConfig{
+		User:     this.config.DB.Username,
+		Password: this.config.DB.Password,
+		Database: this.config.DB.Database,
+		Host:     this.config.DB.Addr(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 安装数据库表结构并写入数据
+	this.log("installing api node ...")
+	var resultMap = maps.Map{}
+	{
+		var cmd = exec.Command(this.apiNodeDir+"/bin/edge-api", "setup", "-api-node-protocol=http", "-api-node-host=\""+apiNodeConfig.Host+"\"", "-api-node-port=\""+strconv.Itoa(apiNodeConfig.Port)+"\"")
+		var output = bytes.NewBuffer(nil)
+		var stderr = bytes.NewBuffer(nil)
+		cmd.Stdout = output
+		cmd.Stderr = stderr
+		err = cmd.Run()
+		if err != nil {
+			return nil, errors.New("install api node failed: " + err.Error() + ": " + string(append(output.Bytes(), stderr.Bytes()...)))
+		}
+
+		err = json.Unmarshal(output.Bytes(), &resultMap)
+		if err != nil {
+			return nil, errors.New("invalid result from api node: " + err.Error() + " (" + output.String() + ")")
+		}
+		if !resultMap.GetBool("isOk") {
+			return nil, errors.New("install api node failed: " + resultMap.GetString("error"))
+		}
+
+		// 等数据完全写入
+		time.Sleep(1 * time.Second)
+	}
+
+	// 重启API节点
+	this.log("starting api node ...")
+	{
+		_ = exec.Command(this.apiNodeDir+"/bin/edge-api", "stop").Run()
+
+		var cmd = exec.Command(this.apiNodeDir+"/bin/edge-api", "start")
+		var stderr = bytes.NewBuffer(nil)
+		cmd.Stderr = stderr
+		err = cmd.Run()
+		if err != nil {
+			return nil, errors.New("start api node failed: " + err.Error() + ": " + stderr.String())
+		}
+
+		var apiNodeSock = gosock.NewTmpSock("edge-api")
+		var maxRetries = 10
+		for {
+			reply, err := apiNodeSock.SendTimeout(&gosock.Command{
+				Code: "starting",
+			}, 3*time.Second)
+			if err != nil {
+				if maxRetries < 0 {
+					return nil, errors.New("api node was not started, please check logs in '" + this.apiNodeDir + "/logs'")
+				}
+				maxRetries--
+			} else if !maps.NewMap(reply.Params).GetBool("isStarting") {
+				break
+			}
+			time.Sleep(3 * time.Second)
+		}
+	}
+
+	return &configs.APIConfig{
+		RPCEndpoints: []string{apiNodeConfig.Endpoint()},
+		NodeId:       resultMap.GetString("adminNodeId"),
+		Secret:       resultMap.GetString("adminNodeSecret"),
+	}, nil
+}
+
+// 设置管理员、访问日志保留天数和监听端口
+func (this *Installer) configure(apiConfig *configs.APIConfig) error {
+	client, err := rpc.NewRPCClient(apiConfig, false)
+	if err != nil {
+		return errors.New("connect to api node failed: " + err.Error())
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	var ctx context.Context
+	if this.config.APINode.Mode == setuputils.APINodeModeNew {
+		ctx = client.Context(0)
+	} else {
+		ctx = client.APIContext(0)
+	}
+
+	// 管理员
+	this.log("creating admin '" + this.config.Admin.Username + "' ...")
+	for i := 0; i < 3; i++ {
+		_, err = client.AdminRPC().CreateOrUpdateAdmin(ctx, &pb.CreateOrUpdateAdminRequest{
+			Username: this.config.Admin.Username,
+			Password: this.config.Admin.Password,
+		})
+		// 等待API节点启动完毕
+		if err == nil {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+	if err != nil {
+		return errors.New("create admin failed: " + err.Error())
+	}
+
+	// 访问日志保留天数
+	if this.config.DB != nil && this.config.DB.AccessLogKeepDays > 0 {
+		this.log("updating access log keep days ...")
+		err = this.updateAccessLogKeepDays(client, ctx, this.config.DB.AccessLogKeepDays)
+		if err != nil {
+			return errors.New("update access log keep days failed: " + err.Error())
+		}
+	}
+
+	// 监听端口
+	if this.config.Server != nil {
+		this.log("updating server config ...")
+		err = this.writeServerConfig()
+		if err != nil {
+			return errors.New("write server config failed: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// 只修改访问日志保留天数，保留其他数据库设置
+func (this *Installer) updateAccessLogKeepDays(client *rpc.RPCClient, ctx context.Context, days int) error {
+	resp, err := client.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: systemconfigs.SettingCodeDatabaseConfigSetting})
+	if err != nil {
+		return err
+	}
+	var config = systemconfigs.NewDatabaseConfig()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, config)
+		if err != nil {
+			return err
+		}
+	}
+	if config.ServerAccessLog.Clean.Days == days {
+		return nil
+	}
+	config.ServerAccessLog.Clean.Days = days
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = client.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      systemconfigs.SettingCodeDatabaseConfigSetting,
+		ValueJSON: configJSON,
+	})
+	return err
+}
+
+func (this *Installer) writeServerConfig() error {
+	serverConfig, err := adminserverutils.LoadServerConfig()
+	if err != nil {
+		return err
+	}
+
+	var config = this.config.Server
+	serverConfig.Http.On = config.HTTP.On
+	if config.HTTP.On {
+		serverConfig.Http.Listen = config.HTTP.Listen
+	}
+	serverConfig.Https.On = config.HTTPS.On
+	if config.HTTPS.On {
+		serverConfig.Https.Listen = config.HTTPS.Listen
+		serverConfig.Https.Cert = config.HTTPS.Cert
+		serverConfig.Https.Key = config.HTTPS.Key
+	}
+	return adminserverutils.WriteServerConfig(serverConfig)
+}
+
+func (this *Installer) oldAPIConfig() *configs.APIConfig {
+	return &configs.APIConfig{
+		RPCEndpoints: []string{this.config.APINode.Endpoint()},
+		NodeId:       this.config.APINode.NodeId,
+		Secret:       this.config.APINode.Secret,
+	}
+}
+
+// 证书路径可以是相对于管理系统目录的路径
+func (this *Installer) serverFile(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return path
+	}
+	return Tea.Root + "/" + path
+}
+
+func (this *Installer) log(message string) {
+	log.Println("[SETUP]" + message)
+}
+
+// WriteAPIDBConfig 写入API节点和管理系统的数据库配置文件，以及备份文件
+func WriteAPIDBConfig(apiNodeDir string, dbConfig *configs.SimpleDBConfig) error {
+	dbConfigData, err := yaml.Marshal(dbConfig)
+	if err != nil {
+		return fmt.Errorf("encode database config failed: %w", err)
+	}
+
+	err = os.WriteFile(apiNodeDir+"/configs/db.yaml", dbConfigData, 0666)
+	if err != nil {
+		return fmt.Errorf("write 'db.yaml' failed: %w", err)
+	}
+
+	err = dbConfig.GenerateOldConfig(apiNodeDir + "/configs/.db.yaml")
+	if err != nil {
+		return fmt.Errorf("write '.db.yaml' failed: %w", err)
+	}
+
+	// 生成备份文件
+	homeDir, _ := os.UserHomeDir()
+	var backupDirs = []string{"/etc/edge-api"}
+	if len(homeDir) > 0 {
+		backupDirs = append(backupDirs, homeDir+"/.edge-api")
+	}
+	writeBackupFiles(backupDirs, "db.yaml", dbConfigData)
+
+	err = os.WriteFile(Tea.ConfigFile("/api_db.yaml"), dbConfigData, 0666)
+	if err != nil {
+		return fmt.Errorf("write 'api_db.yaml' failed: %w", err)
+	}
+
+	// 生成备份文件
+	backupDirs = []string{"/etc/edge-admin"}
+	if len(homeDir) > 0 {
+		backupDirs = append(backupDirs, homeDir+"/.edge-admin")
+	}
+	writeBackupFiles(backupDirs, "api_db.yaml", dbConfigData)
+
+	return nil
+}
+
+// 写入备份文件，忽略所有错误
+func writeBackupFiles(backupDirs []string, filename string, data []byte) {
+	for _, backupDir := range backupDirs {
+		stat, err := os.Stat(backupDir)
+		if err == nil && stat.IsDir() {
+			_ = os.WriteFile(backupDir+"/"+filename, data, 0666)
+		} else if err != nil && os.IsNotExist(err) {
+			err = os.Mkdir(backupDir, 0777)
+			if err == nil {
+				_ = os.WriteFile(backupDir+"/"+filename, data, 0666)
+			}
+		}
+	}
+}
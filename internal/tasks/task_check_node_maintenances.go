@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/cluster/node/nodeutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewCheckNodeMaintenancesTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// CheckNodeMaintenancesTask 检查维护中的节点连接是否已经排空
+type CheckNodeMaintenancesTask struct {
+}
+
+func NewCheckNodeMaintenancesTask() *CheckNodeMaintenancesTask {
+	return &CheckNodeMaintenancesTask{}
+}
+
+func (this *CheckNodeMaintenancesTask) Start() {
+	ticker := time.NewTicker(10 * time.Second)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(5 * time.Second)
+	}
+	for range ticker.C {
+		err := this.Loop()
+		if err != nil {
+			logs.Println("[TASK][CHECK_NODE_MAINTENANCES]" + err.Error())
+		}
+	}
+}
+
+func (this *CheckNodeMaintenancesTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return nodeutils.CheckMaintenances(rpcClient)
+}
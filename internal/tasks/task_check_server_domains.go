@@ -0,0 +1,67 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewCheckServerDomainsTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// CheckServerDomainsTask 定时检查网站域名的接入情况
+type CheckServerDomainsTask struct {
+}
+
+func NewCheckServerDomainsTask() *CheckServerDomainsTask {
+	return &CheckServerDomainsTask{}
+}
+
+func (this *CheckServerDomainsTask) Start() {
+	// 启动后稍等一会儿再检查，避免影响启动
+	time.Sleep(5 * time.Minute)
+	err := this.Loop()
+	if err != nil {
+		logs.Println("[TASK][CHECK_SERVER_DOMAINS]" + err.Error())
+	}
+
+	ticker := time.NewTicker(6 * time.Hour)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(10 * time.Minute)
+	}
+	for range ticker.C {
+		err = this.Loop()
+		if err != nil {
+			logs.Println("[TASK][CHECK_SERVER_DOMAINS]" + err.Error())
+		}
+	}
+}
+
+func (this *CheckServerDomainsTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return serverutils.CheckAllServerDomains(rpcClient, 0)
+}
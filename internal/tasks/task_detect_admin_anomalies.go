@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/log/logutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewDetectAdminAnomaliesTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// DetectAdminAnomaliesTask 分析操作日志，发现管理员异常活动
+type DetectAdminAnomaliesTask struct {
+}
+
+func NewDetectAdminAnomaliesTask() *DetectAdminAnomaliesTask {
+	return &DetectAdminAnomaliesTask{}
+}
+
+func (this *DetectAdminAnomaliesTask) Start() {
+	ticker := time.NewTicker(1 * time.Minute)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(10 * time.Second)
+	}
+	for range ticker.C {
+		err := this.Loop()
+		if err != nil {
+			logs.Println("[TASK][DETECT_ADMIN_ANOMALIES]" + err.Error())
+		}
+	}
+}
+
+func (this *DetectAdminAnomaliesTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return logutils.DetectAnomalies(rpcClient)
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewResumeRolloutsTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// ResumeRolloutsTask 恢复执行中的滚动升级计划
+// 管理平台重启后，执行中的升级计划会在这里继续执行
+type ResumeRolloutsTask struct {
+}
+
+func NewResumeRolloutsTask() *ResumeRolloutsTask {
+	return &ResumeRolloutsTask{}
+}
+
+func (this *ResumeRolloutsTask) Start() {
+	ticker := time.NewTicker(1 * time.Minute)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(10 * time.Second)
+	}
+	for range ticker.C {
+		err := this.Loop()
+		if err != nil {
+			logs.Println("[TASK][RESUME_ROLLOUTS]" + err.Error())
+		}
+	}
+}
+
+func (this *ResumeRolloutsTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return clusterutils.ResumeRunningRollouts(rpcClient)
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewRunScheduledRunbooksTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// RunScheduledRunbooksTask 按计划执行运行手册
+type RunScheduledRunbooksTask struct {
+	isInitialized bool
+}
+
+func NewRunScheduledRunbooksTask() *RunScheduledRunbooksTask {
+	return &RunScheduledRunbooksTask{}
+}
+
+func (this *RunScheduledRunbooksTask) Start() {
+	ticker := time.NewTicker(1 * time.Minute)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(10 * time.Second)
+	}
+	for range ticker.C {
+		err := this.Loop()
+		if err != nil {
+			logs.Println("[TASK][RUN_SCHEDULED_RUNBOOKS]" + err.Error())
+		}
+	}
+}
+
+func (this *RunScheduledRunbooksTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+
+	// 启动后第一次执行时，结束上次中断的执行
+	if !this.isInitialized {
+		err = clusterutils.FinishInterruptedRunbookRuns(rpcClient)
+		if err != nil {
+			return err
+		}
+		this.isInitialized = true
+	}
+
+	return clusterutils.CheckScheduledRunbooks(rpcClient)
+}
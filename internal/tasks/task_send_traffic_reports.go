@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewSendTrafficReportsTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// SendTrafficReportsTask 按计划发送网站流量报表
+type SendTrafficReportsTask struct {
+}
+
+func NewSendTrafficReportsTask() *SendTrafficReportsTask {
+	return &SendTrafficReportsTask{}
+}
+
+func (this *SendTrafficReportsTask) Start() {
+	ticker := time.NewTicker(1 * time.Minute)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(10 * time.Second)
+	}
+	for range ticker.C {
+		err := this.Loop()
+		if err != nil {
+			logs.Println("[TASK][SEND_TRAFFIC_REPORTS]" + err.Error())
+		}
+	}
+}
+
+func (this *SendTrafficReportsTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return serverutils.CheckScheduledTrafficReports(rpcClient)
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package tasks
+
+import (
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/events"
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/settingutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+func init() {
+	events.On(events.EventStart, func() {
+		task := NewSnapshotDBTablesTask()
+		goman.New(func() {
+			task.Start()
+		})
+	})
+}
+
+// SnapshotDBTablesTask 每天记录数据表的大小和行数，用于分析数据增长
+type SnapshotDBTablesTask struct {
+}
+
+func NewSnapshotDBTablesTask() *SnapshotDBTablesTask {
+	return &SnapshotDBTablesTask{}
+}
+
+func (this *SnapshotDBTablesTask) Start() {
+	// 启动后稍等一会儿再检查，避免影响启动
+	time.Sleep(1 * time.Minute)
+	err := this.Loop()
+	if err != nil {
+		logs.Println("[TASK][SNAPSHOT_DB_TABLES]" + err.Error())
+	}
+
+	ticker := time.NewTicker(1 * time.Hour)
+	if Tea.IsTesting() {
+		// 快速测试
+		ticker = time.NewTicker(5 * time.Minute)
+	}
+	for range ticker.C {
+		err = this.Loop()
+		if err != nil {
+			logs.Println("[TASK][SNAPSHOT_DB_TABLES]" + err.Error())
+		}
+	}
+}
+
+func (this *SnapshotDBTablesTask) Loop() error {
+	// 如果还没有安装直接返回
+	if !setup.IsConfigured() || teaconst.IsRecoverMode {
+		return nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	return settingutils.TakeDBTableSnapshot(rpcClient.Context(0), rpcClient, false)
+}
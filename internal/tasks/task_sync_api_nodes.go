@@ -1,12 +1,8 @@
 package tasks
 
 import (
-	"context"
-	"crypto/tls"
-	"net/url"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
@@ -15,12 +11,11 @@ import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
 	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
 	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/apiendpointutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/lists"
 	"github.com/iwind/TeaGo/logs"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func init() {
@@ -41,13 +36,19 @@ func NewSyncAPINodesTask() *SyncAPINodesTask {
 }
 
 func (this *SyncAPINodesTask) Start() {
+	// 启动时先测试一次，尽快切换到延迟低的API节点
+	err := this.Loop()
+	if err != nil {
+		logs.Println("[TASK][SYNC_API_NODES]" + err.Error())
+	}
+
 	ticker := time.NewTicker(5 * time.Minute)
 	if Tea.IsTesting() {
 		// 快速测试
 		ticker = time.NewTicker(1 * time.Minute)
 	}
 	for range ticker.C {
-		err := this.Loop()
+		err = this.Loop()
 		if err != nil {
 			logs.Println("[TASK][SYNC_API_NODES]" + err.Error())
 		}
@@ -65,7 +66,7 @@ func (this *SyncAPINodesTask) Loop() error {
 		return err
 	}
 
-	// 是否禁止自动升级
+	// 是否禁止自动升级，禁止后使用手工固定的API地址
 	if config.RPCDisableUpdate {
 		return nil
 	}
@@ -85,22 +86,38 @@ func (this *SyncAPINodesTask) Loop() error {
 		if !node.IsOn {
 			continue
 		}
-		newEndpoints = append(newEndpoints, node.AccessAddrs...)
+		for _, addr := range node.AccessAddrs {
+			if !lists.ContainsString(newEndpoints, addr) {
+				newEndpoints = append(newEndpoints, addr)
+			}
+		}
 	}
-
-	// 和现有的对比
-	if this.isSame(newEndpoints, config.RPCEndpoints) {
+	if len(newEndpoints) == 0 {
 		return nil
 	}
 
+	// 测试每个地址的往返时间，并按延迟划分梯队
+	var results = apiendpointutils.ProbeAll(newEndpoints, apiendpointutils.DefaultSamples, apiendpointutils.DefaultTimeout)
+	var tiers = apiendpointutils.BuildTiers(results)
+	apiendpointutils.SaveSnapshot(&apiendpointutils.Snapshot{
+		Results:  results,
+		Tiers:    tiers,
+		ProbedAt: time.Now().Unix(),
+	})
+
 	// 测试是否有API节点可用
-	hasOk := this.testEndpoints(newEndpoints)
-	if !hasOk {
+	if !this.hasOk(results) {
+		return nil
+	}
+
+	// 和现有的对比，保留所有的地址，暂时无法连接的地址只是排在最后一个梯队
+	if this.isSame(newEndpoints, config.RPCEndpoints) && apiendpointutils.EqualTiers(tiers, config.RPCEndpointTiers) {
 		return nil
 	}
 
 	// 修改RPC对象配置
-	config.RPCEndpoints = newEndpoints
+	config.RPCEndpoints = apiendpointutils.FlattenTiers(tiers)
+	config.RPCEndpointTiers = tiers
 	err = rpcClient.UpdateConfig(config)
 	if err != nil {
 		return err
@@ -116,52 +133,19 @@ func (this *SyncAPINodesTask) Loop() error {
 }
 
 func (this *SyncAPINodesTask) isSame(endpoints1 []string, endpoints2 []string) bool {
+	// 复制后再排序，以免改变地址的优先顺序
+	endpoints1 = append([]string{}, endpoints1...)
+	endpoints2 = append([]string{}, endpoints2...)
 	sort.Strings(endpoints1)
 	sort.Strings(endpoints2)
 	return strings.Join(endpoints1, "&") == strings.Join(endpoints2, "&")
 }
 
-func (this *SyncAPINodesTask) testEndpoints(endpoints []string) bool {
-	if len(endpoints) == 0 {
-		return false
-	}
-
-	var wg = sync.WaitGroup{}
-	wg.Add(len(endpoints))
-
-	var ok = false
-
-	for _, endpoint := range endpoints {
-		go func(endpoint string) {
-			defer wg.Done()
-
-			u, err := url.Parse(endpoint)
-			if err != nil {
-				return
-			}
-
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer func() {
-				cancel()
-			}()
-			var conn *grpc.ClientConn
-
-			if u.Scheme == "http" {
-				conn, err = grpc.DialContext(ctx, u.Host, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
-			} else if u.Scheme == "https" {
-				conn, err = grpc.DialContext(ctx, u.Host, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
-					InsecureSkipVerify: true,
-				})), grpc.WithBlock())
-			}
-			if err != nil {
-				return
-			}
-			_ = conn.Close()
-
-			ok = true
-		}(endpoint)
+func (this *SyncAPINodesTask) hasOk(results []*apiendpointutils.ProbeResult) bool {
+	for _, result := range results {
+		if result.IsOk {
+			return true
+		}
 	}
-	wg.Wait()
-
-	return ok
+	return false
 }
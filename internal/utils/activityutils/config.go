@@ -0,0 +1,54 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import "errors"
+
+// Config 管理员异常活动检测配置
+type Config struct {
+	IsOn              bool `json:"isOn"`              // 是否启用
+	CheckNewCountry   bool `json:"checkNewCountry"`   // 是否检查从新的国家/地区登录
+	MassDeleteCount   int  `json:"massDeleteCount"`   // 时间窗口内删除操作达到此数量时报警，0表示不检查
+	MassDeleteMinutes int  `json:"massDeleteMinutes"` // 删除操作统计时间窗口
+	CheckOddHours     bool `json:"checkOddHours"`     // 是否检查非常规时间的操作
+	OddHourFrom       int  `json:"oddHourFrom"`       // 非常规时间开始（包含）
+	OddHourTo         int  `json:"oddHourTo"`         // 非常规时间结束（不包含）
+}
+
+// NewConfig 获取新对象
+func NewConfig() *Config {
+	return &Config{
+		IsOn:              false,
+		CheckNewCountry:   true,
+		MassDeleteCount:   20,
+		MassDeleteMinutes: 10,
+		CheckOddHours:     true,
+		OddHourFrom:       0,
+		OddHourTo:         6,
+	}
+}
+
+// Validate 校验配置
+func (this *Config) Validate() error {
+	if this.MassDeleteCount < 0 {
+		return errors.New("删除操作数量不能小于0")
+	}
+	if this.MassDeleteCount > 0 && (this.MassDeleteMinutes <= 0 || this.MassDeleteMinutes > 1440) {
+		return errors.New("删除操作统计时间需要在1到1440分钟之间")
+	}
+	if this.OddHourFrom < 0 || this.OddHourFrom > 23 || this.OddHourTo < 0 || this.OddHourTo > 24 {
+		return errors.New("非常规时间需要在0到24点之间")
+	}
+	if this.CheckOddHours && this.OddHourFrom == this.OddHourTo {
+		return errors.New("非常规时间的开始和结束不能相同")
+	}
+	return nil
+}
+
+// IsOddHour 判断某个小时是否为非常规时间，支持跨越零点的时间段
+func (this *Config) IsOddHour(hour int) bool {
+	if this.OddHourFrom < this.OddHourTo {
+		return hour >= this.OddHourFrom && hour < this.OddHourTo
+	}
+	return hour >= this.OddHourFrom || hour < this.OddHourTo
+}
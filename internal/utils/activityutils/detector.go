@@ -0,0 +1,110 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import (
+	"fmt"
+	"time"
+)
+
+// Detect 分析新的管理员活动，返回新发现的异常事件
+// events 需要按照日志ID从小到大排列，已经分析过的日志会被忽略
+func (this *State) Detect(config *Config, events []*Event, location *time.Location) []*Alert {
+	if location == nil {
+		location = time.Local
+	}
+
+	var alerts = []*Alert{}
+	for _, event := range events {
+		if event.LogId <= this.LastLogId {
+			continue
+		}
+		this.LastLogId = event.LogId
+
+		if event.AdminId <= 0 {
+			continue
+		}
+		var profile = this.profile(event.AdminId)
+		var alert = this.detectEvent(config, profile, event, location)
+		if alert != nil {
+			this.AddAlert(alert)
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// Learn 只学习管理员活动特征，不产生异常事件，用于第一次启用时建立基线
+func (this *State) Learn(events []*Event) {
+	for _, event := range events {
+		if event.LogId > this.LastLogId {
+			this.LastLogId = event.LogId
+		}
+		if event.AdminId <= 0 || !event.IsLogin() || len(event.Country) == 0 {
+			continue
+		}
+		var profile = this.profile(event.AdminId)
+		if _, ok := profile.Countries[event.Country]; !ok {
+			profile.Countries[event.Country] = event.CreatedAt
+		}
+	}
+}
+
+func (this *State) detectEvent(config *Config, profile *Profile, event *Event, location *time.Location) *Alert {
+	var newAlert = func(kind string, message string) *Alert {
+		return &Alert{
+			Kind:      kind,
+			AdminId:   event.AdminId,
+			AdminName: event.AdminName,
+			Message:   message,
+			IP:        event.IP,
+			LogId:     event.LogId,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	// 新的登录地区
+	if event.IsLogin() && len(event.Country) > 0 {
+		_, ok := profile.Countries[event.Country]
+		var isFirstLogin = len(profile.Countries) == 0
+		if !ok {
+			profile.Countries[event.Country] = event.CreatedAt
+		}
+		if !ok && !isFirstLogin && config.CheckNewCountry {
+			return newAlert(AlertKindNewCountry, fmt.Sprintf("管理员'%s'从新的国家/地区'%s'登录，IP：%s", event.AdminName, event.Country, event.IP))
+		}
+	}
+
+	// 大量删除
+	if event.IsDeletion() && config.MassDeleteCount > 0 {
+		var window = int64(config.MassDeleteMinutes) * 60
+		var deletions = []int64{}
+		for _, deletedAt := range profile.Deletions {
+			if deletedAt > event.CreatedAt-window {
+				deletions = append(deletions, deletedAt)
+			}
+		}
+		deletions = append(deletions, event.CreatedAt)
+		if len(deletions) > config.MassDeleteCount {
+			deletions = deletions[len(deletions)-config.MassDeleteCount:]
+		}
+		profile.Deletions = deletions
+
+		if len(deletions) >= config.MassDeleteCount && event.CreatedAt-profile.LastMassDeletionAt > window {
+			profile.LastMassDeletionAt = event.CreatedAt
+			return newAlert(AlertKindMassDeletion, fmt.Sprintf("管理员'%s'在%d分钟内执行了%d次删除操作，最近一次：%s", event.AdminName, config.MassDeleteMinutes, len(deletions), event.Description))
+		}
+	}
+
+	// 非常规时间操作，每个管理员每天只报告一次
+	if config.CheckOddHours {
+		var t = time.Unix(event.CreatedAt, 0).In(location)
+		var day = t.Format("20060102")
+		if config.IsOddHour(t.Hour()) && profile.LastOddHourDay != day {
+			profile.LastOddHourDay = day
+			return newAlert(AlertKindOddHour, fmt.Sprintf("管理员'%s'在非常规时间%s有操作：%s", event.AdminName, t.Format("15:04"), event.Description))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvent_Kinds(t *testing.T) {
+	for action, expected := range map[string]bool{
+		"/servers/server/delete":   true,
+		"/servers/deleteServers":   true,
+		"/servers/server/update":   false,
+		"/clusters/delete/":        true,
+		"/servers/groups/undelete": false,
+	} {
+		var event = &Event{Action: action}
+		if event.IsDeletion() != expected {
+			t.Fatal("action:", action, "expected:", expected)
+		}
+	}
+
+	if !(&Event{Action: "/"}).IsLogin() || (&Event{Action: "/servers"}).IsLogin() {
+		t.Fatal("login detection failed")
+	}
+}
+
+func TestConfig_IsOddHour(t *testing.T) {
+	var config = NewConfig()
+	config.OddHourFrom = 22
+	config.OddHourTo = 6
+	for hour, expected := range map[int]bool{21: false, 22: true, 23: true, 0: true, 5: true, 6: false, 12: false} {
+		if config.IsOddHour(hour) != expected {
+			t.Fatal("hour:", hour, "expected:", expected)
+		}
+	}
+
+	config.OddHourFrom = 1
+	config.OddHourTo = 5
+	if config.IsOddHour(0) || !config.IsOddHour(1) || config.IsOddHour(5) {
+		t.Fatal("odd hour check failed")
+	}
+}
+
+func TestState_Detect(t *testing.T) {
+	var location = time.UTC
+	var config = NewConfig()
+	config.MassDeleteCount = 3
+	config.MassDeleteMinutes = 10
+	config.OddHourFrom = 0
+	config.OddHourTo = 6
+
+	var base = time.Date(2024, 5, 1, 12, 0, 0, 0, location).Unix()
+	var state = NewState()
+
+	// 建立基线
+	state.Learn([]*Event{
+		{LogId: 1, AdminId: 1, AdminName: "admin", Action: "/", Country: "中国", CreatedAt: base},
+	})
+	if state.LastLogId != 1 || len(state.Profiles[1].Countries) != 1 {
+		t.Fatal("learn failed")
+	}
+
+	var alerts = state.Detect(config, []*Event{
+		{LogId: 1, AdminId: 1, AdminName: "admin", Action: "/", Country: "美国", CreatedAt: base}, // 已经分析过
+		{LogId: 2, AdminId: 1, AdminName: "admin", Action: "/", Country: "中国", CreatedAt: base + 10},
+		{LogId: 3, AdminId: 2, AdminName: "ops", Action: "/", Country: "日本", CreatedAt: base + 20}, // 第一次登录
+		{LogId: 4, AdminId: 1, AdminName: "admin", Action: "/", Country: "美国", CreatedAt: base + 30},
+	}, location)
+	if len(alerts) != 1 || alerts[0].Kind != AlertKindNewCountry || alerts[0].AdminId != 1 {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+
+	// 大量删除，同一个时间窗口内只报告一次
+	var events = []*Event{}
+	for i := 0; i < 6; i++ {
+		events = append(events, &Event{LogId: int64(10 + i), AdminId: 2, AdminName: "ops", Action: "/servers/server/delete", CreatedAt: base + 100 + int64(i)})
+	}
+	alerts = state.Detect(config, events, location)
+	if len(alerts) != 1 || alerts[0].Kind != AlertKindMassDeletion || alerts[0].LogId != 12 {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+
+	// 超出时间窗口的删除不计算在内
+	alerts = state.Detect(config, []*Event{
+		{LogId: 20, AdminId: 1, AdminName: "admin", Action: "/servers/server/delete", CreatedAt: base},
+		{LogId: 21, AdminId: 1, AdminName: "admin", Action: "/servers/server/delete", CreatedAt: base + 700},
+		{LogId: 22, AdminId: 1, AdminName: "admin", Action: "/servers/server/delete", CreatedAt: base + 1400},
+	}, location)
+	if len(alerts) != 0 {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+
+	// 非常规时间，每天只报告一次
+	var night = time.Date(2024, 5, 2, 3, 0, 0, 0, location).Unix()
+	alerts = state.Detect(config, []*Event{
+		{LogId: 30, AdminId: 1, AdminName: "admin", Action: "/servers/update", CreatedAt: night},
+		{LogId: 31, AdminId: 1, AdminName: "admin", Action: "/servers/update", CreatedAt: night + 60},
+		{LogId: 32, AdminId: 2, AdminName: "ops", Action: "/servers/update", CreatedAt: night + 60},
+	}, location)
+	if len(alerts) != 2 || alerts[0].Kind != AlertKindOddHour || alerts[1].AdminId != 2 {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+
+	if state.CountUnreadAlerts() != 4 || state.Alerts[0].LogId != 32 {
+		t.Fatal("unexpected alerts:", state.CountUnreadAlerts())
+	}
+	state.MarkAlertsRead([]int64{state.Alerts[0].Id})
+	if state.CountUnreadAlerts() != 3 {
+		t.Fatal("mark read failed")
+	}
+
+	var cloned = state.Clone()
+	cloned.MarkAlertsRead(nil)
+	if cloned.CountUnreadAlerts() != 0 || state.CountUnreadAlerts() != 3 {
+		t.Fatal("clone failed")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	var heatmap = NewHeatmap(time.UTC)
+	var base = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Unix()
+	heatmap.Add("网站", base+3600*2)
+	heatmap.Add("网站", base+3600*2+10)
+	heatmap.Add("节点", base+3600*5)
+
+	var rows = heatmap.Rows()
+	if len(rows) != 2 || rows[0].Module != "网站" || rows[0].Hours[2] != 2 || rows[0].Total != 2 || rows[1].Hours[5] != 1 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if heatmap.Max() != 2 {
+		t.Fatal("unexpected max:", heatmap.Max())
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import "strings"
+
+// 登录相关的请求路径
+var loginActions = []string{"/", "/index/otp", "/index/webauthn", "/login/sso/callback"}
+
+// Event 从操作日志中提取的管理员活动
+type Event struct {
+	LogId       int64
+	AdminId     int64
+	AdminName   string
+	Action      string // 请求路径
+	Description string
+	IP          string
+	Country     string // IP所在国家/地区，为空表示无法识别
+	CreatedAt   int64
+}
+
+// IsLogin 是否为登录操作
+func (this *Event) IsLogin() bool {
+	for _, action := range loginActions {
+		if this.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeletion 是否为删除操作
+func (this *Event) IsDeletion() bool {
+	var action = strings.TrimRight(this.Action, "/")
+	var index = strings.LastIndex(action, "/")
+	if index >= 0 {
+		action = action[index+1:]
+	}
+	return strings.HasPrefix(strings.ToLower(action), "delete")
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import (
+	"sort"
+	"time"
+)
+
+// HeatmapRow 热力图中的一行
+type HeatmapRow struct {
+	Module string
+	Hours  [24]int
+	Total  int
+}
+
+// Heatmap 按模块和小时统计的操作热力图
+type Heatmap struct {
+	rows     map[string]*HeatmapRow
+	max      int
+	location *time.Location
+}
+
+// NewHeatmap 获取新对象
+func NewHeatmap(location *time.Location) *Heatmap {
+	if location == nil {
+		location = time.Local
+	}
+	return &Heatmap{
+		rows:     map[string]*HeatmapRow{},
+		location: location,
+	}
+}
+
+// Add 添加一次操作
+func (this *Heatmap) Add(module string, createdAt int64) {
+	row, ok := this.rows[module]
+	if !ok {
+		row = &HeatmapRow{Module: module}
+		this.rows[module] = row
+	}
+	var hour = time.Unix(createdAt, 0).In(this.location).Hour()
+	row.Hours[hour]++
+	row.Total++
+	if row.Hours[hour] > this.max {
+		this.max = row.Hours[hour]
+	}
+}
+
+// Max 单个格子中的最大操作数
+func (this *Heatmap) Max() int {
+	return this.max
+}
+
+// Rows 按操作总数从多到少排列的所有行
+func (this *Heatmap) Rows() []*HeatmapRow {
+	var rows = []*HeatmapRow{}
+	for _, row := range this.rows {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total == rows[j].Total {
+			return rows[i].Module < rows[j].Module
+		}
+		return rows[i].Total > rows[j].Total
+	})
+	return rows
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activityutils
+
+import (
+	"encoding/json"
+)
+
+// MaxAlerts 最多保留的异常事件数量
+const MaxAlerts = 500
+
+// 异常事件类型
+const (
+	AlertKindNewCountry   = "newCountry"
+	AlertKindMassDeletion = "massDeletion"
+	AlertKindOddHour      = "oddHour"
+	AlertKindLoginLockout = "loginLockout"
+)
+
+// AlertKindName 异常事件类型名称
+func AlertKindName(kind string) string {
+	switch kind {
+	case AlertKindNewCountry:
+		return "新的登录地区"
+	case AlertKindMassDeletion:
+		return "大量删除"
+	case AlertKindOddHour:
+		return "非常规时间操作"
+	case AlertKindLoginLockout:
+		return "登录锁定"
+	}
+	return kind
+}
+
+// Alert 异常事件
+type Alert struct {
+	Id        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	AdminId   int64  `json:"adminId"`
+	AdminName string `json:"adminName"`
+	Message   string `json:"message"`
+	IP        string `json:"ip"`
+	LogId     int64  `json:"logId"` // 触发异常的操作日志ID
+	CreatedAt int64  `json:"createdAt"`
+	IsRead    bool   `json:"isRead"`
+}
+
+// Profile 管理员活动特征
+type Profile struct {
+	Countries          map[string]int64 `json:"countries"`          // 登录过的国家/地区 => 首次登录时间
+	Deletions          []int64          `json:"deletions"`          // 最近的删除操作时间
+	LastMassDeletionAt int64            `json:"lastMassDeletionAt"` // 上次报告大量删除的时间
+	LastOddHourDay     string           `json:"lastOddHourDay"`     // 上次报告非常规时间操作的日期
+}
+
+// State 异常检测状态
+type State struct {
+	LastLogId   int64              `json:"lastLogId"` // 已经分析过的最大日志ID
+	LastAlertId int64              `json:"lastAlertId"`
+	Profiles    map[int64]*Profile `json:"profiles"`
+	Alerts      []*Alert           `json:"alerts"` // 从新到旧排列
+}
+
+// NewState 获取新对象
+func NewState() *State {
+	return &State{
+		Profiles: map[int64]*Profile{},
+		Alerts:   []*Alert{},
+	}
+}
+
+// Clone 复制状态
+func (this *State) Clone() *State {
+	data, err := json.Marshal(this)
+	if err != nil {
+		return NewState()
+	}
+	var state = NewState()
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return NewState()
+	}
+	if state.Profiles == nil {
+		state.Profiles = map[int64]*Profile{}
+	}
+	if state.Alerts == nil {
+		state.Alerts = []*Alert{}
+	}
+	return state
+}
+
+// CountUnreadAlerts 计算未读的异常事件数量
+func (this *State) CountUnreadAlerts() int {
+	var count = 0
+	for _, alert := range this.Alerts {
+		if !alert.IsRead {
+			count++
+		}
+	}
+	return count
+}
+
+// MarkAlertsRead 设置异常事件为已读，不指定ID时表示全部已读
+func (this *State) MarkAlertsRead(alertIds []int64) {
+	for _, alert := range this.Alerts {
+		if len(alertIds) == 0 {
+			alert.IsRead = true
+			continue
+		}
+		for _, alertId := range alertIds {
+			if alert.Id == alertId {
+				alert.IsRead = true
+				break
+			}
+		}
+	}
+}
+
+// RemoveAdmin 删除管理员的活动特征
+func (this *State) RemoveAdmin(adminId int64) {
+	delete(this.Profiles, adminId)
+}
+
+func (this *State) profile(adminId int64) *Profile {
+	profile, ok := this.Profiles[adminId]
+	if !ok {
+		profile = &Profile{}
+		this.Profiles[adminId] = profile
+	}
+	if profile.Countries == nil {
+		profile.Countries = map[string]int64{}
+	}
+	return profile
+}
+
+// AddAlert 添加异常事件
+func (this *State) AddAlert(alert *Alert) {
+	this.LastAlertId++
+	alert.Id = this.LastAlertId
+
+	this.Alerts = append([]*Alert{alert}, this.Alerts...)
+	if len(this.Alerts) > MaxAlerts {
+		this.Alerts = this.Alerts[:MaxAlerts]
+	}
+}
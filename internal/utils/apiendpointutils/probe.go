@@ -0,0 +1,145 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package apiendpointutils
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	DefaultSamples = 3               // 每个地址测试的次数
+	DefaultTimeout = 5 * time.Second // 单次连接超时时间
+)
+
+// ProbeResult 单个API地址的测试结果
+type ProbeResult struct {
+	Endpoint string        `json:"endpoint"`
+	IsOk     bool          `json:"isOk"`
+	RTT      time.Duration `json:"rtt"` // 多次测试中的最小值
+	Error    string        `json:"error"`
+}
+
+// Probe 测试某个API地址的往返时间
+// 使用TCP连接建立时间作为RTT，多次测试取最小值，以减少偶发抖动的影响；
+// 最后还需要完成一次gRPC连接（https地址包括TLS握手），只能建立TCP连接的地址不认为可用
+func Probe(endpoint string, samples int, timeout time.Duration) *ProbeResult {
+	var result = &ProbeResult{
+		Endpoint: endpoint,
+	}
+
+	addr, scheme, err := endpointAddr(endpoint)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	for i := 0; i < samples; i++ {
+		var before = time.Now()
+		conn, dialErr := net.DialTimeout("tcp", addr, timeout)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+		var rtt = time.Since(before)
+		_ = conn.Close()
+
+		if !result.IsOk || rtt < result.RTT {
+			result.RTT = rtt
+		}
+		result.IsOk = true
+	}
+
+	if !result.IsOk {
+		if err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	err = dialGRPC(addr, scheme, timeout)
+	if err != nil {
+		result.IsOk = false
+		result.RTT = 0
+		result.Error = "grpc handshake failed: " + err.Error()
+	}
+	return result
+}
+
+// 使用和RPC客户端相同的方式建立gRPC连接，直到连接可用或者超时
+func dialGRPC(addr string, scheme string, timeout time.Duration) error {
+	var transportCredentials = insecure.NewCredentials()
+	if scheme == "https" {
+		transportCredentials = credentials.NewTLS(&tls.Config{
+			InsecureSkipVerify: true,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(transportCredentials), grpc.WithBlock(), grpc.WithReturnConnectionError())
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// ProbeAll 并发测试多个API地址，返回结果的顺序和参数中地址的顺序一致
+func ProbeAll(endpoints []string, samples int, timeout time.Duration) []*ProbeResult {
+	var results = make([]*ProbeResult, len(endpoints))
+
+	var wg = &sync.WaitGroup{}
+	wg.Add(len(endpoints))
+	for index, endpoint := range endpoints {
+		go func(index int, endpoint string) {
+			defer wg.Done()
+			results[index] = Probe(endpoint, samples, timeout)
+		}(index, endpoint)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// 从API地址中读取TCP地址和协议
+func endpointAddr(endpoint string) (addr string, scheme string, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", errors.New("parse endpoint failed: " + err.Error())
+	}
+	if len(u.Hostname()) == 0 {
+		return "", "", errors.New("invalid endpoint '" + endpoint + "'")
+	}
+
+	var port = u.Port()
+	switch u.Scheme {
+	case "http":
+		if len(port) == 0 {
+			port = "80"
+		}
+	case "https":
+		if len(port) == 0 {
+			port = "443"
+		}
+	default:
+		return "", "", errors.New("invalid scheme '" + u.Scheme + "'")
+	}
+	return net.JoinHostPort(u.Hostname(), port), u.Scheme, nil
+}
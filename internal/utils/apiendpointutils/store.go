@@ -0,0 +1,31 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package apiendpointutils
+
+import (
+	"sync"
+)
+
+// Snapshot 最近一次测试的结果
+type Snapshot struct {
+	Results  []*ProbeResult
+	Tiers    [][]string
+	ProbedAt int64
+}
+
+var lastSnapshot *Snapshot
+var locker = &sync.RWMutex{}
+
+// SaveSnapshot 保存最近一次测试结果
+func SaveSnapshot(snapshot *Snapshot) {
+	locker.Lock()
+	lastSnapshot = snapshot
+	locker.Unlock()
+}
+
+// LastSnapshot 读取最近一次测试结果，还没有测试时返回nil
+func LastSnapshot() *Snapshot {
+	locker.RLock()
+	defer locker.RUnlock()
+	return lastSnapshot
+}
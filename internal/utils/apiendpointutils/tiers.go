@@ -0,0 +1,82 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package apiendpointutils
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// 梯队划分规则：RTT不超过梯队中最小RTT的 TierRatio 倍，或者相差不超过 TierSlack 时，认为在同一个梯队
+const (
+	TierRatio = 1.5
+	TierSlack = 20 * time.Millisecond
+)
+
+// BuildTiers 根据测试结果将API地址分成多个梯队，越靠前的梯队延迟越低
+// 本次无法建立gRPC连接的地址放在最后一个梯队，只在前面的梯队都不可用时才使用；同一个梯队中的地址按字母排序，以保证结果稳定
+func BuildTiers(results []*ProbeResult) [][]string {
+	var okResults = []*ProbeResult{}
+	var failedEndpoints = []string{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if result.IsOk {
+			okResults = append(okResults, result)
+		} else {
+			failedEndpoints = append(failedEndpoints, result.Endpoint)
+		}
+	}
+	sort.SliceStable(okResults, func(i, j int) bool {
+		return okResults[i].RTT < okResults[j].RTT
+	})
+
+	var tiers = [][]string{}
+	var tier = []string{}
+	var baseRTT time.Duration
+	for _, result := range okResults {
+		if len(tier) > 0 && float64(result.RTT) > float64(baseRTT)*TierRatio && result.RTT-baseRTT > TierSlack {
+			sort.Strings(tier)
+			tiers = append(tiers, tier)
+			tier = []string{}
+		}
+		if len(tier) == 0 {
+			baseRTT = result.RTT
+		}
+		tier = append(tier, result.Endpoint)
+	}
+	if len(tier) > 0 {
+		sort.Strings(tier)
+		tiers = append(tiers, tier)
+	}
+	if len(failedEndpoints) > 0 {
+		sort.Strings(failedEndpoints)
+		tiers = append(tiers, failedEndpoints)
+	}
+
+	return tiers
+}
+
+// FlattenTiers 将梯队展开为地址列表
+func FlattenTiers(tiers [][]string) []string {
+	var result = []string{}
+	for _, tier := range tiers {
+		result = append(result, tier...)
+	}
+	return result
+}
+
+// EqualTiers 判断两组梯队是否一致
+func EqualTiers(tiers1 [][]string, tiers2 [][]string) bool {
+	if len(tiers1) != len(tiers2) {
+		return false
+	}
+	for index, tier := range tiers1 {
+		if strings.Join(tier, "&") != strings.Join(tiers2[index], "&") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package apiendpointutils_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/apiendpointutils"
+	"google.golang.org/grpc"
+)
+
+func TestBuildTiers(t *testing.T) {
+	var tiers = apiendpointutils.BuildTiers([]*apiendpointutils.ProbeResult{
+		{Endpoint: "http://frankfurt:8001", IsOk: true, RTT: 180 * time.Millisecond},
+		{Endpoint: "http://singapore-b:8001", IsOk: true, RTT: 12 * time.Millisecond},
+		{Endpoint: "http://singapore-a:8001", IsOk: true, RTT: 3 * time.Millisecond},
+		{Endpoint: "http://tokyo:8001", IsOk: true, RTT: 70 * time.Millisecond},
+		{Endpoint: "http://virginia:8001", IsOk: true, RTT: 230 * time.Millisecond},
+		{Endpoint: "http://down:8001", IsOk: false},
+	})
+	var expected = [][]string{
+		{"http://singapore-a:8001", "http://singapore-b:8001"},
+		{"http://tokyo:8001"},
+		{"http://frankfurt:8001", "http://virginia:8001"},
+		{"http://down:8001"},
+	}
+	if !apiendpointutils.EqualTiers(tiers, expected) {
+		t.Fatal("unexpected tiers:", tiers)
+	}
+
+	var endpoints = apiendpointutils.FlattenTiers(tiers)
+	if len(endpoints) != 6 || endpoints[0] != "http://singapore-a:8001" || endpoints[5] != "http://down:8001" {
+		t.Fatal("unexpected endpoints:", endpoints)
+	}
+}
+
+func TestBuildTiers_Empty(t *testing.T) {
+	if len(apiendpointutils.BuildTiers(nil)) != 0 {
+		t.Fatal("should be empty")
+	}
+}
+
+func TestBuildTiers_AllFailed(t *testing.T) {
+	var tiers = apiendpointutils.BuildTiers([]*apiendpointutils.ProbeResult{
+		{Endpoint: "http://b:8001", IsOk: false},
+		{Endpoint: "http://a:8001", IsOk: false},
+	})
+	if !apiendpointutils.EqualTiers(tiers, [][]string{{"http://a:8001", "http://b:8001"}}) {
+		t.Fatal("unexpected tiers:", tiers)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// gRPC服务
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grpcServer = grpc.NewServer()
+	go func() {
+		_ = grpcServer.Serve(grpcListener)
+	}()
+	defer grpcServer.Stop()
+
+	// 只能建立TCP连接的服务
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = tcpListener.Close()
+	}()
+	go func() {
+		for {
+			conn, acceptErr := tcpListener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	var results = apiendpointutils.ProbeAll([]string{
+		"http://" + grpcListener.Addr().String(),
+		"http://" + tcpListener.Addr().String(),
+		"ftp://127.0.0.1",
+	}, 2, time.Second)
+	if !results[0].IsOk || results[0].RTT <= 0 {
+		t.Fatal("should be ok:", results[0].Error)
+	}
+	if results[1].IsOk || len(results[1].Error) == 0 {
+		t.Fatal("should fail without grpc handshake")
+	}
+	if results[2].IsOk || len(results[2].Error) == 0 {
+		t.Fatal("should fail")
+	}
+}
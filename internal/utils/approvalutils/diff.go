@@ -0,0 +1,95 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package approvalutils
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// DiffItem 某个字段修改前后的值
+type DiffItem struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Diff 对比两个对象序列化为JSON后的差异，按字段路径排序
+func Diff(before any, after any) ([]*DiffItem, error) {
+	beforeMap, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = []*DiffItem{}
+	for path, beforeValue := range beforeMap {
+		afterValue, ok := afterMap[path]
+		if !ok || afterValue != beforeValue {
+			result = append(result, &DiffItem{
+				Path:   path,
+				Before: beforeValue,
+				After:  afterValue,
+			})
+		}
+	}
+	for path, afterValue := range afterMap {
+		_, ok := beforeMap[path]
+		if !ok {
+			result = append(result, &DiffItem{
+				Path:   path,
+				Before: "",
+				After:  afterValue,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// 将对象展开为 路径 => 值 的形式，数组作为一个整体
+func flatten(v any) (map[string]string, error) {
+	var result = map[string]string{}
+	if v == nil {
+		return result, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+	flattenValue("", value, result)
+	return result, nil
+}
+
+func flattenValue(prefix string, value any, result map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, subValue := range v {
+			var path = key
+			if len(prefix) > 0 {
+				path = prefix + "." + key
+			}
+			flattenValue(path, subValue, result)
+		}
+	case nil:
+		result[prefix] = "null"
+	case string:
+		result[prefix] = strconv.Quote(v)
+	default:
+		data, err := json.Marshal(v)
+		if err == nil {
+			result[prefix] = string(data)
+		}
+	}
+}
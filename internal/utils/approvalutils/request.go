@@ -0,0 +1,78 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package approvalutils
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// 变更申请状态
+const (
+	StatusPending   = "pending"   // 等待审批
+	StatusApproved  = "approved"  // 已批准，正在执行
+	StatusRejected  = "rejected"  // 已拒绝
+	StatusCancelled = "cancelled" // 申请人已撤回
+	StatusExecuted  = "executed"  // 已执行
+	StatusFailed    = "failed"    // 执行失败
+)
+
+var (
+	ErrRequestNotFound   = errors.New("change request not found")
+	ErrRequestNotPending = errors.New("change request is not pending")
+	ErrSelfReview        = errors.New("requester can not review own change request")
+	ErrNotRequester      = errors.New("only requester can cancel the change request")
+)
+
+// ChangeRequest 变更申请
+type ChangeRequest struct {
+	Id         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	Module     string          `json:"module"`     // 审批人需要有此模块的读写权限
+	ClusterIds []int64         `json:"clusterIds"` // 涉及的集群，用于检查审批人的资源范围
+	Title      string          `json:"title"`
+	Preview    []string        `json:"preview"` // 变更内容预览
+	Diff       []*DiffItem     `json:"diff"`    // 修改前后的对比
+	Payload    json.RawMessage `json:"payload"` // 执行变更需要的参数
+
+	RequesterId   int64  `json:"requesterId"`
+	RequesterName string `json:"requesterName"`
+	RequesterIP   string `json:"requesterIP"`
+	ReviewerId    int64  `json:"reviewerId"`
+	ReviewerName  string `json:"reviewerName"`
+	Reason        string `json:"reason"` // 审批意见
+	Error         string `json:"error"`  // 执行失败原因
+
+	Status     string `json:"status"`
+	CreatedAt  int64  `json:"createdAt"`
+	ReviewedAt int64  `json:"reviewedAt"`
+	ExecutedAt int64  `json:"executedAt"`
+}
+
+// IsFinished 是否已结束
+func (this *ChangeRequest) IsFinished() bool {
+	switch this.Status {
+	case StatusRejected, StatusCancelled, StatusExecuted, StatusFailed:
+		return true
+	}
+	return false
+}
+
+// StatusName 状态名称
+func StatusName(status string) string {
+	switch status {
+	case StatusPending:
+		return "等待审批"
+	case StatusApproved:
+		return "正在执行"
+	case StatusRejected:
+		return "已拒绝"
+	case StatusCancelled:
+		return "已撤回"
+	case StatusExecuted:
+		return "已执行"
+	case StatusFailed:
+		return "执行失败"
+	}
+	return status
+}
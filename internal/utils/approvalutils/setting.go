@@ -0,0 +1,58 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package approvalutils
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrSettingChanged = errors.New("the setting or object has been changed since the change request was submitted")
+
+// SettingChange 修改设置的变更参数
+// 同时保存提交申请时的设置，执行前如果设置已经被修改，则拒绝执行，以免覆盖其他人的修改
+// 也可以用于删除等操作：Before 保存提交申请时相关对象的状态，After 保存操作参数
+type SettingChange struct {
+	Code   string          `json:"code"`   // 设置代号
+	Before json.RawMessage `json:"before"` // 提交申请时的设置
+	After  json.RawMessage `json:"after"`  // 修改后的设置
+}
+
+// NewSettingChange 获取新对象
+func NewSettingChange(code string, before any, after any) (*SettingChange, error) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	return &SettingChange{
+		Code:   code,
+		Before: beforeJSON,
+		After:  afterJSON,
+	}, nil
+}
+
+// Diff 修改前后的对比
+func (this *SettingChange) Diff() ([]*DiffItem, error) {
+	return Diff(this.Before, this.After)
+}
+
+// Check 检查当前设置是否和提交申请时一致
+func (this *SettingChange) Check(current any) error {
+	diff, err := Diff(this.Before, current)
+	if err != nil {
+		return err
+	}
+	if len(diff) > 0 {
+		return ErrSettingChanged
+	}
+	return nil
+}
+
+// Decode 读取修改后的设置
+func (this *SettingChange) Decode(ptr any) error {
+	return json.Unmarshal(this.After, ptr)
+}
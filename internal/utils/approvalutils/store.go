@@ -0,0 +1,185 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package approvalutils
+
+import (
+	"sort"
+)
+
+// MaxFinishedRequests 最多保留的已结束申请数量，等待审批的申请不受限制
+const MaxFinishedRequests = 1000
+
+// Store 变更申请存储
+type Store struct {
+	RequiredKinds []string         `json:"requiredKinds"` // 需要审批的操作
+	Requests      []*ChangeRequest `json:"requests"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		RequiredKinds: []string{},
+		Requests:      []*ChangeRequest{},
+	}
+}
+
+// IsRequired 某个操作是否需要审批
+func (this *Store) IsRequired(kind string) bool {
+	for _, requiredKind := range this.RequiredKinds {
+		if requiredKind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 添加申请，返回申请ID
+func (this *Store) Add(request *ChangeRequest, now int64) int64 {
+	var maxId int64
+	for _, r := range this.Requests {
+		if r.Id > maxId {
+			maxId = r.Id
+		}
+	}
+	request.Id = maxId + 1
+	request.Status = StatusPending
+	request.CreatedAt = now
+	this.Requests = append(this.Requests, request)
+	this.trim()
+	return request.Id
+}
+
+// Find 查找申请
+func (this *Store) Find(requestId int64) *ChangeRequest {
+	for _, request := range this.Requests {
+		if request.Id == requestId {
+			return request
+		}
+	}
+	return nil
+}
+
+// Approve 批准申请，批准后状态为 StatusApproved，需要调用 Finish() 记录执行结果
+func (this *Store) Approve(requestId int64, reviewerId int64, reviewerName string, reason string, now int64) (*ChangeRequest, error) {
+	request, err := this.review(requestId, reviewerId)
+	if err != nil {
+		return nil, err
+	}
+	request.Status = StatusApproved
+	request.ReviewerId = reviewerId
+	request.ReviewerName = reviewerName
+	request.Reason = reason
+	request.ReviewedAt = now
+	return request, nil
+}
+
+// Reject 拒绝申请
+func (this *Store) Reject(requestId int64, reviewerId int64, reviewerName string, reason string, now int64) (*ChangeRequest, error) {
+	request, err := this.review(requestId, reviewerId)
+	if err != nil {
+		return nil, err
+	}
+	request.Status = StatusRejected
+	request.ReviewerId = reviewerId
+	request.ReviewerName = reviewerName
+	request.Reason = reason
+	request.ReviewedAt = now
+	return request, nil
+}
+
+// Cancel 申请人撤回申请
+func (this *Store) Cancel(requestId int64, requesterId int64, now int64) (*ChangeRequest, error) {
+	var request = this.Find(requestId)
+	if request == nil {
+		return nil, ErrRequestNotFound
+	}
+	if request.Status != StatusPending {
+		return nil, ErrRequestNotPending
+	}
+	if request.RequesterId != requesterId {
+		return nil, ErrNotRequester
+	}
+	request.Status = StatusCancelled
+	request.ReviewedAt = now
+	return request, nil
+}
+
+// Finish 记录执行结果
+func (this *Store) Finish(requestId int64, execErr error, now int64) {
+	var request = this.Find(requestId)
+	if request == nil || request.Status != StatusApproved {
+		return
+	}
+	if execErr != nil {
+		request.Status = StatusFailed
+		request.Error = execErr.Error()
+	} else {
+		request.Status = StatusExecuted
+	}
+	request.ExecutedAt = now
+	this.trim()
+}
+
+// CountPending 等待审批的申请数量
+func (this *Store) CountPending() int {
+	var count = 0
+	for _, request := range this.Requests {
+		if request.Status == StatusPending {
+			count++
+		}
+	}
+	return count
+}
+
+// List 按照ID倒序列出申请，status为空时列出所有申请
+func (this *Store) List(status string) []*ChangeRequest {
+	var result = []*ChangeRequest{}
+	for _, request := range this.Requests {
+		if len(status) == 0 || request.Status == status {
+			result = append(result, request)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id > result[j].Id
+	})
+	return result
+}
+
+func (this *Store) review(requestId int64, reviewerId int64) (*ChangeRequest, error) {
+	var request = this.Find(requestId)
+	if request == nil {
+		return nil, ErrRequestNotFound
+	}
+	if request.Status != StatusPending {
+		return nil, ErrRequestNotPending
+	}
+	if request.RequesterId == reviewerId {
+		return nil, ErrSelfReview
+	}
+	return request, nil
+}
+
+// 删除过多的已结束申请
+func (this *Store) trim() {
+	var countFinished = 0
+	for _, request := range this.Requests {
+		if request.IsFinished() {
+			countFinished++
+		}
+	}
+	if countFinished <= MaxFinishedRequests {
+		return
+	}
+
+	// Requests按ID递增，从最早的开始删除
+	var countDelete = countFinished - MaxFinishedRequests
+	var requests = []*ChangeRequest{}
+	for _, request := range this.Requests {
+		if countDelete > 0 && request.IsFinished() {
+			countDelete--
+			continue
+		}
+		requests = append(requests, request)
+	}
+	this.Requests = requests
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package approvalutils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+)
+
+func TestStore_Review(t *testing.T) {
+	var store = approvalutils.NewStore()
+	store.RequiredKinds = []string{"deleteServers"}
+	if !store.IsRequired("deleteServers") || store.IsRequired("deleteCluster") {
+		t.Fatal("unexpected required kinds")
+	}
+
+	var requestId = store.Add(&approvalutils.ChangeRequest{
+		Kind:        "deleteServers",
+		RequesterId: 1,
+	}, 100)
+	if store.CountPending() != 1 {
+		t.Fatal("should be pending")
+	}
+
+	// 不能审批自己的申请
+	_, err := store.Approve(requestId, 1, "a", "", 101)
+	if err != approvalutils.ErrSelfReview {
+		t.Fatal("expected ErrSelfReview, got", err)
+	}
+
+	request, err := store.Approve(requestId, 2, "b", "ok", 102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Status != approvalutils.StatusApproved || request.ReviewerId != 2 {
+		t.Fatal("unexpected request:", request.Status)
+	}
+
+	// 不能重复审批
+	_, err = store.Reject(requestId, 3, "c", "", 103)
+	if err != approvalutils.ErrRequestNotPending {
+		t.Fatal("expected ErrRequestNotPending, got", err)
+	}
+
+	store.Finish(requestId, errors.New("rpc error"), 104)
+	if request.Status != approvalutils.StatusFailed || request.Error != "rpc error" {
+		t.Fatal("unexpected request:", request.Status)
+	}
+}
+
+func TestStore_Cancel(t *testing.T) {
+	var store = approvalutils.NewStore()
+	var requestId = store.Add(&approvalutils.ChangeRequest{RequesterId: 1}, 100)
+	_, err := store.Cancel(requestId, 2, 101)
+	if err != approvalutils.ErrNotRequester {
+		t.Fatal("expected ErrNotRequester, got", err)
+	}
+	_, err = store.Cancel(requestId, 1, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.List(approvalutils.StatusCancelled)) != 1 {
+		t.Fatal("should be cancelled")
+	}
+}
+
+func TestStore_Trim(t *testing.T) {
+	var store = approvalutils.NewStore()
+	var pendingId = store.Add(&approvalutils.ChangeRequest{RequesterId: 1}, 1)
+	for i := 0; i < approvalutils.MaxFinishedRequests+10; i++ {
+		var requestId = store.Add(&approvalutils.ChangeRequest{RequesterId: 1}, 1)
+		_, _ = store.Reject(requestId, 2, "b", "", 2)
+	}
+	store.Add(&approvalutils.ChangeRequest{RequesterId: 1}, 3)
+	if store.Find(pendingId) == nil {
+		t.Fatal("pending request should be kept")
+	}
+	if len(store.Requests) > approvalutils.MaxFinishedRequests+2 {
+		t.Fatal("too many requests:", len(store.Requests))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff, err := approvalutils.Diff(map[string]any{
+		"isOn": true,
+		"name": "default",
+		"log":  map[string]any{"isOn": true, "days": 7},
+		"ips":  []string{"a"},
+	}, map[string]any{
+		"isOn": false,
+		"name": "default",
+		"log":  map[string]any{"isOn": true, "days": 30},
+		"ips":  []string{"a", "b"},
+		"new":  nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths = []string{}
+	for _, item := range diff {
+		paths = append(paths, item.Path)
+	}
+	if len(diff) != 4 || paths[0] != "ips" || paths[1] != "isOn" || paths[2] != "log.days" || paths[3] != "new" {
+		t.Fatal("unexpected diff:", paths)
+	}
+	if diff[1].Before != "true" || diff[1].After != "false" {
+		t.Fatal("unexpected values:", diff[1].Before, diff[1].After)
+	}
+}
+
+func TestSettingChange_Check(t *testing.T) {
+	type config struct {
+		IsOn bool     `json:"isOn"`
+		IPs  []string `json:"ips"`
+	}
+
+	change, err := approvalutils.NewSettingChange("security", &config{IsOn: true, IPs: []string{"a"}}, &config{IsOn: false, IPs: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := change.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 1 || diff[0].Path != "isOn" {
+		t.Fatal("unexpected diff:", diff)
+	}
+
+	// 提交后没有被修改
+	err = change.Check(&config{IsOn: true, IPs: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 提交后被其他人修改
+	err = change.Check(&config{IsOn: true, IPs: []string{"a", "b"}})
+	if !errors.Is(err, approvalutils.ErrSettingChanged) {
+		t.Fatal("expected ErrSettingChanged, got:", err)
+	}
+
+	var after = &config{}
+	err = change.Decode(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.IsOn || len(after.IPs) != 1 {
+		t.Fatal("unexpected setting:", after)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package boardutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iwind/TeaGo/rands"
+)
+
+// 时间范围
+const (
+	TimeRange24Hours = "24h"
+	TimeRange7Days   = "7d"
+	TimeRange15Days  = "15d"
+)
+
+// AllTimeRanges 所有的时间范围
+func AllTimeRanges() []map[string]string {
+	return []map[string]string{
+		{"code": TimeRange24Hours, "name": "最近24小时"},
+		{"code": TimeRange7Days, "name": "最近7天"},
+		{"code": TimeRange15Days, "name": "最近15天"},
+	}
+}
+
+// IsValidTimeRange 检查时间范围是否有效
+func IsValidTimeRange(timeRange string) bool {
+	for _, r := range AllTimeRanges() {
+		if r["code"] == timeRange {
+			return true
+		}
+	}
+	return false
+}
+
+// AllRefreshSeconds 可选的自动刷新间隔，0表示不自动刷新
+var AllRefreshSeconds = []int{0, 30, 60, 300, 600}
+
+// Board 自定义看板
+type Board struct {
+	Id             int64     `json:"id"`
+	Name           string    `json:"name"`
+	AdminId        int64     `json:"adminId"`        // 创建者
+	IsShared       bool      `json:"isShared"`       // 是否共享给其他管理员
+	SharedAdminIds []int64   `json:"sharedAdminIds"` // 共享给哪些管理员，为空表示所有管理员
+	TimeRange      string    `json:"timeRange"`      // 默认时间范围
+	RefreshSeconds int       `json:"refreshSeconds"` // 自动刷新间隔
+	Widgets        []*Widget `json:"widgets"`
+	CreatedAt      int64     `json:"createdAt"`
+	UpdatedAt      int64     `json:"updatedAt"`
+}
+
+// Validate 校验看板
+func (this *Board) Validate() error {
+	this.Name = strings.TrimSpace(this.Name)
+	if len(this.Name) == 0 {
+		return errors.New("请输入看板名称")
+	}
+	if len([]rune(this.Name)) > 50 {
+		return errors.New("看板名称不能超过50个字符")
+	}
+	if len(this.TimeRange) == 0 {
+		this.TimeRange = TimeRange24Hours
+	}
+	if !IsValidTimeRange(this.TimeRange) {
+		return errors.New("不支持的时间范围'" + this.TimeRange + "'")
+	}
+
+	var isValidRefresh = false
+	for _, seconds := range AllRefreshSeconds {
+		if seconds == this.RefreshSeconds {
+			isValidRefresh = true
+			break
+		}
+	}
+	if !isValidRefresh {
+		return errors.New("不支持的自动刷新间隔")
+	}
+
+	if !this.IsShared || this.SharedAdminIds == nil {
+		this.SharedAdminIds = []int64{}
+	}
+	if this.Widgets == nil {
+		this.Widgets = []*Widget{}
+	}
+	return nil
+}
+
+// CanView 检查管理员是否可以查看此看板
+func (this *Board) CanView(adminId int64) bool {
+	if this.AdminId == adminId {
+		return true
+	}
+	if !this.IsShared {
+		return false
+	}
+	if len(this.SharedAdminIds) == 0 {
+		return true
+	}
+	for _, sharedAdminId := range this.SharedAdminIds {
+		if sharedAdminId == adminId {
+			return true
+		}
+	}
+	return false
+}
+
+// CanEdit 检查管理员是否可以修改此看板，只有创建者才能修改
+func (this *Board) CanEdit(adminId int64) bool {
+	return this.AdminId == adminId
+}
+
+// FindWidget 查找组件
+func (this *Board) FindWidget(widgetId string) *Widget {
+	for _, widget := range this.Widgets {
+		if widget.Id == widgetId {
+			return widget
+		}
+	}
+	return nil
+}
+
+// AddWidget 添加组件
+func (this *Board) AddWidget(widget *Widget) error {
+	if len(this.Widgets) >= MaxWidgets {
+		return fmt.Errorf("每个看板最多只能添加%d个组件", MaxWidgets)
+	}
+	err := widget.Validate()
+	if err != nil {
+		return err
+	}
+	widget.Id = rands.HexString(16)
+	this.Widgets = append(this.Widgets, widget)
+	return nil
+}
+
+// RemoveWidget 删除组件
+func (this *Board) RemoveWidget(widgetId string) {
+	var widgets = []*Widget{}
+	for _, widget := range this.Widgets {
+		if widget.Id != widgetId {
+			widgets = append(widgets, widget)
+		}
+	}
+	this.Widgets = widgets
+}
+
+// MoveWidget 移动组件，delta 小于0表示向前移动，大于0表示向后移动
+func (this *Board) MoveWidget(widgetId string, delta int) {
+	for index, widget := range this.Widgets {
+		if widget.Id != widgetId {
+			continue
+		}
+		var newIndex = index + delta
+		if newIndex < 0 {
+			newIndex = 0
+		}
+		if newIndex >= len(this.Widgets) {
+			newIndex = len(this.Widgets) - 1
+		}
+		if newIndex == index {
+			return
+		}
+		var widgets = append([]*Widget{}, this.Widgets[:index]...)
+		widgets = append(widgets, this.Widgets[index+1:]...)
+		widgets = append(widgets[:newIndex], append([]*Widget{widget}, widgets[newIndex:]...)...)
+		this.Widgets = widgets
+		return
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package boardutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoard_Validate(t *testing.T) {
+	var board = &Board{Name: "  运维看板 "}
+	if err := board.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if board.Name != "运维看板" || board.TimeRange != TimeRange24Hours || board.Widgets == nil || board.SharedAdminIds == nil {
+		t.Fatalf("unexpected board: %+v", board)
+	}
+
+	for _, b := range []*Board{
+		{Name: ""},
+		{Name: "test", TimeRange: "1y"},
+		{Name: "test", RefreshSeconds: 7},
+	} {
+		if b.Validate() == nil {
+			t.Fatalf("expected error: %+v", b)
+		}
+	}
+}
+
+func TestBoard_CanView(t *testing.T) {
+	var board = &Board{AdminId: 1}
+	if !board.CanView(1) || board.CanView(2) || !board.CanEdit(1) || board.CanEdit(2) {
+		t.Fatal("private board check failed")
+	}
+
+	board.IsShared = true
+	if !board.CanView(2) || board.CanEdit(2) {
+		t.Fatal("shared board check failed")
+	}
+
+	board.SharedAdminIds = []int64{3}
+	if board.CanView(2) || !board.CanView(3) {
+		t.Fatal("shared admins check failed")
+	}
+}
+
+func TestBoard_Widgets(t *testing.T) {
+	var board = &Board{Name: "test"}
+	for _, kind := range []string{WidgetKindTrafficTrend, WidgetKindNodeStatus, WidgetKindTopDomains} {
+		err := board.AddWidget(&Widget{Kind: kind, Width: 9, MetricChartId: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if board.Widgets[0].Width != MaxWidgetWidth || board.Widgets[1].MetricChartId != 0 || len(board.Widgets[2].Id) == 0 {
+		t.Fatalf("unexpected widgets: %+v", board.Widgets)
+	}
+
+	if board.AddWidget(&Widget{Kind: "unknown"}) == nil {
+		t.Fatal("unknown kind should fail")
+	}
+	if board.AddWidget(&Widget{Kind: WidgetKindMetricChart}) == nil {
+		t.Fatal("metric chart without chart id should fail")
+	}
+
+	var kinds = func() string {
+		var s = ""
+		for _, widget := range board.Widgets {
+			s += widget.Kind + ","
+		}
+		return s
+	}
+
+	var lastId = board.Widgets[2].Id
+	board.MoveWidget(lastId, -1)
+	if kinds() != "trafficTrend,topDomains,nodeStatus," {
+		t.Fatal("move failed:", kinds())
+	}
+	board.MoveWidget(lastId, -10)
+	if kinds() != "topDomains,trafficTrend,nodeStatus," {
+		t.Fatal("move failed:", kinds())
+	}
+	board.MoveWidget(lastId, 10)
+	if kinds() != "trafficTrend,nodeStatus,topDomains," {
+		t.Fatal("move failed:", kinds())
+	}
+
+	board.RemoveWidget(lastId)
+	if kinds() != "trafficTrend,nodeStatus," || board.FindWidget(lastId) != nil {
+		t.Fatal("remove failed:", kinds())
+	}
+}
+
+func TestStore(t *testing.T) {
+	var store = NewStore()
+	for _, adminId := range []int64{1, 1, 2} {
+		err := store.AddBoard(&Board{Name: "board", AdminId: adminId})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.Boards[2].Id != 3 || store.FindBoard(2) == nil {
+		t.Fatal("add failed")
+	}
+
+	store.Boards[2].IsShared = true
+	store.Boards[2].SharedAdminIds = []int64{1}
+	if len(store.FindAdminBoards(1)) != 3 || len(store.FindAdminBoards(2)) != 1 || len(store.FindAdminBoards(3)) != 0 {
+		t.Fatal("find admin boards failed")
+	}
+
+	var cloned = store.Clone()
+	err := cloned.RemoveBoard(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cloned.Boards) != 2 || len(store.Boards) != 3 {
+		t.Fatal("clone failed")
+	}
+
+	// 删除管理员后，只共享给此管理员的看板不能变成共享给所有人
+	cloned.RemoveAdmin(1)
+	if len(cloned.Boards) != 1 || cloned.Boards[0].IsShared || cloned.Boards[0].CanView(3) {
+		t.Fatalf("remove admin failed: %+v", cloned.Boards[0])
+	}
+}
+
+func TestMetricTimeFrom(t *testing.T) {
+	var now = time.Date(2024, 5, 10, 12, 30, 0, 0, time.Local)
+	for _, c := range []struct {
+		timeRange  string
+		periodUnit string
+		expected   string
+	}{
+		{TimeRange24Hours, "minute", "202405091230"},
+		{TimeRange24Hours, "hour", "2024050912"},
+		{TimeRange7Days, "day", "20240504"},
+		{TimeRange15Days, "week", "202417"},
+		{TimeRange15Days, "month", "202404"},
+	} {
+		var from = MetricTimeFrom(c.timeRange, c.periodUnit, now)
+		if from != c.expected {
+			t.Fatal(c.timeRange, c.periodUnit, "expected", c.expected, "got", from)
+		}
+	}
+
+	dayFrom, dayTo := DayRange(TimeRange7Days, now)
+	if dayFrom != "20240504" || dayTo != "20240510" {
+		t.Fatal("unexpected day range:", dayFrom, dayTo)
+	}
+}
+
+func TestSumMetricStats(t *testing.T) {
+	var stats = SumMetricStats([]*MetricStat{
+		{Keys: []string{"a"}, Time: "20240501", Value: 100},
+		{Keys: []string{"a"}, Time: "20240509", Value: 1},
+		{Keys: []string{"b"}, Time: "20240509", Value: 2},
+		{Keys: []string{"a"}, Time: "20240509", Value: 3},
+	}, "20240504")
+	if len(stats) != 2 {
+		t.Fatal("unexpected stats:", len(stats))
+	}
+	if stats[0].Keys[0] != "a" || stats[0].Value != 4 || stats[0].Total != 6 || stats[1].Value != 2 || stats[1].Total != 6 {
+		t.Fatalf("unexpected stats: %+v %+v", stats[0], stats[1])
+	}
+}
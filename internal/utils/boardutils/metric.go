@@ -0,0 +1,93 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package boardutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DayRange 时间范围对应的开始和结束日期，格式为YYYYMMDD
+func DayRange(timeRange string, now time.Time) (dayFrom string, dayTo string) {
+	var days = 1
+	switch timeRange {
+	case TimeRange7Days:
+		days = 7
+	case TimeRange15Days:
+		days = 15
+	}
+	return now.AddDate(0, 0, -days+1).Format("20060102"), now.Format("20060102")
+}
+
+// MetricTimeFrom 时间范围内指标数据的开始时间，格式和指标的统计周期单位一致
+func MetricTimeFrom(timeRange string, periodUnit string, now time.Time) string {
+	var from time.Time
+	switch timeRange {
+	case TimeRange7Days:
+		from = now.AddDate(0, 0, -6)
+	case TimeRange15Days:
+		from = now.AddDate(0, 0, -14)
+	default:
+		from = now.Add(-24 * time.Hour)
+	}
+
+	switch periodUnit {
+	case "minute":
+		return from.Format("200601021504")
+	case "hour":
+		return from.Format("2006010215")
+	case "week":
+		year, week := from.ISOWeek()
+		return fmt.Sprintf("%d%02d", year, week)
+	case "month":
+		return from.Format("200601")
+	default:
+		return from.Format("20060102")
+	}
+}
+
+// MetricStat 指标数据
+type MetricStat struct {
+	Keys  []string
+	Time  string
+	Value float32
+	Total float32 // 同一时间所有数据的合计
+}
+
+// SumMetricStats 合并不同节点和网站的指标数据，只保留 timeFrom 之后的数据
+func SumMetricStats(stats []*MetricStat, timeFrom string) []*MetricStat {
+	var result = []*MetricStat{}
+	var statMap = map[string]*MetricStat{} // time + keys => stat
+	var totalMap = map[string]float32{}    // time => total
+	for _, stat := range stats {
+		if stat.Time < timeFrom {
+			continue
+		}
+		totalMap[stat.Time] += stat.Value
+
+		var key = stat.Time + "\n" + strings.Join(stat.Keys, "\n")
+		sumStat, ok := statMap[key]
+		if !ok {
+			sumStat = &MetricStat{
+				Keys: stat.Keys,
+				Time: stat.Time,
+			}
+			statMap[key] = sumStat
+			result = append(result, sumStat)
+		}
+		sumStat.Value += stat.Value
+	}
+
+	for _, stat := range result {
+		stat.Total = totalMap[stat.Time]
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Time != result[j].Time {
+			return result[i].Time < result[j].Time
+		}
+		return result[i].Value > result[j].Value
+	})
+	return result
+}
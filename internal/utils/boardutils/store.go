@@ -0,0 +1,119 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package boardutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxBoardsPerAdmin 每个管理员最多可以创建的看板数
+const MaxBoardsPerAdmin = 20
+
+// Store 所有的自定义看板
+type Store struct {
+	LastId int64    `json:"lastId"`
+	Boards []*Board `json:"boards"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Boards: []*Board{},
+	}
+}
+
+// Clone 复制对象
+func (this *Store) Clone() *Store {
+	data, err := json.Marshal(this)
+	if err != nil {
+		return NewStore()
+	}
+	var store = NewStore()
+	err = json.Unmarshal(data, store)
+	if err != nil {
+		return NewStore()
+	}
+	if store.Boards == nil {
+		store.Boards = []*Board{}
+	}
+	return store
+}
+
+// FindBoard 查找看板
+func (this *Store) FindBoard(boardId int64) *Board {
+	for _, board := range this.Boards {
+		if board.Id == boardId {
+			return board
+		}
+	}
+	return nil
+}
+
+// FindAdminBoards 查找管理员可以查看的看板，包括自己创建的和共享给自己的
+func (this *Store) FindAdminBoards(adminId int64) []*Board {
+	var result = []*Board{}
+	for _, board := range this.Boards {
+		if board.CanView(adminId) {
+			result = append(result, board)
+		}
+	}
+	return result
+}
+
+// AddBoard 添加看板
+func (this *Store) AddBoard(board *Board) error {
+	var count = 0
+	for _, existBoard := range this.Boards {
+		if existBoard.AdminId == board.AdminId {
+			count++
+		}
+	}
+	if count >= MaxBoardsPerAdmin {
+		return fmt.Errorf("每个管理员最多只能创建%d个看板", MaxBoardsPerAdmin)
+	}
+
+	err := board.Validate()
+	if err != nil {
+		return err
+	}
+	this.LastId++
+	board.Id = this.LastId
+	this.Boards = append(this.Boards, board)
+	return nil
+}
+
+// RemoveBoard 删除看板
+func (this *Store) RemoveBoard(boardId int64) error {
+	for index, board := range this.Boards {
+		if board.Id == boardId {
+			this.Boards = append(this.Boards[:index], this.Boards[index+1:]...)
+			return nil
+		}
+	}
+	return errors.New("board not found")
+}
+
+// RemoveAdmin 删除管理员创建的看板，并取消对此管理员的共享
+func (this *Store) RemoveAdmin(adminId int64) {
+	var boards = []*Board{}
+	for _, board := range this.Boards {
+		if board.AdminId == adminId {
+			continue
+		}
+		var sharedAdminIds = []int64{}
+		for _, sharedAdminId := range board.SharedAdminIds {
+			if sharedAdminId != adminId {
+				sharedAdminIds = append(sharedAdminIds, sharedAdminId)
+			}
+		}
+		// 原来只共享给此管理员时，不能变成共享给所有管理员
+		if len(board.SharedAdminIds) > 0 && len(sharedAdminIds) == 0 {
+			board.IsShared = false
+		}
+		board.SharedAdminIds = sharedAdminIds
+		boards = append(boards, board)
+	}
+	this.Boards = boards
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package boardutils
+
+import (
+	"errors"
+	"strings"
+)
+
+// 组件类型
+const (
+	WidgetKindTrafficTrend   = "trafficTrend"   // 流量趋势
+	WidgetKindRequestTrend   = "requestTrend"   // 请求数趋势
+	WidgetKindBandwidthTrend = "bandwidthTrend" // 平均带宽趋势
+	WidgetKindWAFTrend       = "wafTrend"       // WAF拦截趋势
+	WidgetKindTrafficStat    = "trafficStat"    // 流量统计数字
+	WidgetKindNodeStatus     = "nodeStatus"     // 节点状态
+	WidgetKindClusterHealth  = "clusterHealth"  // 集群健康状况
+	WidgetKindTopDomains     = "topDomains"     // 域名排行
+	WidgetKindMetricChart    = "metricChart"    // 指标图表
+)
+
+// MaxWidgetWidth 组件最大宽度，以四分之一行为单位
+const MaxWidgetWidth = 4
+
+// MaxWidgets 每个看板最多可以添加的组件数
+const MaxWidgets = 30
+
+// WidgetDefinition 组件类型定义
+type WidgetDefinition struct {
+	Kind         string
+	Name         string
+	Description  string
+	DefaultWidth int
+}
+
+// AllWidgetDefinitions 所有的组件类型
+func AllWidgetDefinitions() []*WidgetDefinition {
+	return []*WidgetDefinition{
+		{Kind: WidgetKindTrafficStat, Name: "流量统计", Description: "所选时间范围内的总流量、请求数、缓存命中率和拦截次数", DefaultWidth: 4},
+		{Kind: WidgetKindTrafficTrend, Name: "流量趋势", Description: "总流量、缓存流量和攻击流量的变化趋势", DefaultWidth: 2},
+		{Kind: WidgetKindRequestTrend, Name: "请求数趋势", Description: "总请求数和缓存请求数的变化趋势", DefaultWidth: 2},
+		{Kind: WidgetKindBandwidthTrend, Name: "平均带宽", Description: "根据流量计算的平均带宽变化趋势", DefaultWidth: 2},
+		{Kind: WidgetKindWAFTrend, Name: "WAF拦截", Description: "WAF拦截的请求数和流量变化趋势", DefaultWidth: 2},
+		{Kind: WidgetKindNodeStatus, Name: "节点状态", Description: "边缘节点、API节点和数据库节点的在线状态", DefaultWidth: 2},
+		{Kind: WidgetKindClusterHealth, Name: "集群健康状况", Description: "每个集群的在线节点比例", DefaultWidth: 2},
+		{Kind: WidgetKindTopDomains, Name: "域名排行", Description: "请求数最多的域名", DefaultWidth: 2},
+		{Kind: WidgetKindMetricChart, Name: "指标图表", Description: "从已启用的指标图表中选择一个", DefaultWidth: 2},
+	}
+}
+
+// FindWidgetDefinition 查找组件类型定义
+func FindWidgetDefinition(kind string) *WidgetDefinition {
+	for _, definition := range AllWidgetDefinitions() {
+		if definition.Kind == kind {
+			return definition
+		}
+	}
+	return nil
+}
+
+// Widget 看板组件
+type Widget struct {
+	Id            string `json:"id"`
+	Kind          string `json:"kind"`
+	Title         string `json:"title"`         // 标题，为空表示使用类型名称
+	Width         int    `json:"width"`         // 宽度，1-4，以四分之一行为单位
+	MetricChartId int64  `json:"metricChartId"` // 指标图表ID，只有指标图表组件才需要
+}
+
+// Validate 校验组件
+func (this *Widget) Validate() error {
+	var definition = FindWidgetDefinition(this.Kind)
+	if definition == nil {
+		return errors.New("不支持的组件类型'" + this.Kind + "'")
+	}
+	this.Title = strings.TrimSpace(this.Title)
+	if len([]rune(this.Title)) > 50 {
+		return errors.New("组件标题不能超过50个字符")
+	}
+	if this.Width <= 0 {
+		this.Width = definition.DefaultWidth
+	}
+	if this.Width > MaxWidgetWidth {
+		this.Width = MaxWidgetWidth
+	}
+	if this.Kind == WidgetKindMetricChart {
+		if this.MetricChartId <= 0 {
+			return errors.New("请选择要显示的指标图表")
+		}
+	} else {
+		this.MetricChartId = 0
+	}
+	return nil
+}
+
+// DisplayTitle 显示的标题
+func (this *Widget) DisplayTitle() string {
+	if len(this.Title) > 0 {
+		return this.Title
+	}
+	var definition = FindWidgetDefinition(this.Kind)
+	if definition != nil {
+		return definition.Name
+	}
+	return this.Kind
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configcodeutils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentVersion 当前文档格式版本
+// 版本2开始引用的路由规则、报头、WAF、重写规则和源站等对象都导出为完整内容
+const DocumentVersion = 2
+
+// ServerInfo 文档中记录的服务信息
+// 仅用于识别和校验，不会被应用
+type ServerInfo struct {
+	Id   int64  `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"`
+}
+
+// Document 服务配置文档
+type Document struct {
+	Version  int            `yaml:"version" json:"version"`
+	Server   *ServerInfo    `yaml:"server,omitempty" json:"server,omitempty"`
+	Sections map[string]any `yaml:"sections" json:"sections"`
+	Shared   map[string]any `yaml:"shared,omitempty" json:"shared,omitempty"` // 集群中共享的组件内容，仅用于审阅，不会被应用
+
+	// Revisions 导出时各分段内容的摘要，应用时用来判断分段在导出后是否已被他人修改
+	Revisions map[string]string `yaml:"revisions,omitempty" json:"revisions,omitempty"`
+}
+
+// NewDocument 获取新文档对象
+func NewDocument(serverId int64, serverName string) *Document {
+	return &Document{
+		Version: DocumentVersion,
+		Server: &ServerInfo{
+			Id:   serverId,
+			Name: serverName,
+		},
+		Sections: map[string]any{},
+	}
+}
+
+// DecodeYAML 从YAML中解析文档
+func DecodeYAML(data []byte) (*Document, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("empty document")
+	}
+
+	var doc = &Document{}
+	err := yaml.Unmarshal(data, doc)
+	if err != nil {
+		return nil, fmt.Errorf("decode yaml failed: %w", err)
+	}
+	if doc.Version <= 0 {
+		doc.Version = DocumentVersion
+	}
+	if doc.Version > DocumentVersion {
+		return nil, fmt.Errorf("unsupported document version '%d'", doc.Version)
+	}
+	if doc.Version < DocumentVersion {
+		return nil, fmt.Errorf("document version '%d' is outdated, please export it again", doc.Version)
+	}
+	if doc.Sections == nil {
+		doc.Sections = map[string]any{}
+	}
+
+	for code, value := range doc.Sections {
+		if FindSection(code) == nil {
+			return nil, fmt.Errorf("unknown section '%s'", code)
+		}
+		normalized, err := normalize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid section '%s': %w", code, err)
+		}
+		doc.Sections[code] = normalized
+	}
+
+	return doc, nil
+}
+
+// EncodeYAML 将文档编码为YAML
+func (this *Document) EncodeYAML() ([]byte, error) {
+	var buf = &bytes.Buffer{}
+	var encoder = yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(this)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SetSectionJSON 使用JSON数据设置某个分段
+func (this *Document) SetSectionJSON(code string, data []byte) error {
+	if FindSection(code) == nil {
+		return fmt.Errorf("unknown section '%s'", code)
+	}
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+
+	var value any
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return fmt.Errorf("decode section '%s' failed: %w", code, err)
+	}
+	normalized, err := normalize(value)
+	if err != nil {
+		return err
+	}
+	this.Sections[code] = normalized
+	return nil
+}
+
+// SectionJSON 读取某个分段的JSON数据
+func (this *Document) SectionJSON(code string) (data []byte, ok bool, err error) {
+	value, ok := this.Sections[code]
+	if !ok {
+		return nil, false, nil
+	}
+	data, err = json.Marshal(value)
+	return data, true, err
+}
+
+// HasSection 判断是否包含某个分段
+func (this *Document) HasSection(code string) bool {
+	_, ok := this.Sections[code]
+	return ok
+}
+
+// SectionCodes 文档中包含的分段代号，按预定义顺序排列
+func (this *Document) SectionCodes() []string {
+	var result = []string{}
+	for _, section := range AllSections() {
+		if this.HasSection(section.Code) {
+			result = append(result, section.Code)
+		}
+	}
+	return result
+}
+
+// SectionRevision 计算某个分段当前内容的摘要
+func (this *Document) SectionRevision(code string) (string, error) {
+	data, _, err := this.SectionJSON(code)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		data = []byte("null")
+	}
+	var sum = sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// SealRevisions 记录所有分段当前内容的摘要，在导出文档时调用
+func (this *Document) SealRevisions() error {
+	this.Revisions = map[string]string{}
+	for code := range this.Sections {
+		revision, err := this.SectionRevision(code)
+		if err != nil {
+			return err
+		}
+		this.Revisions[code] = revision
+	}
+	return nil
+}
+
+// SetShared 设置共享组件的内容
+func (this *Document) SetShared(code string, value any) error {
+	normalized, err := normalize(value)
+	if err != nil {
+		return err
+	}
+	if this.Shared == nil {
+		this.Shared = map[string]any{}
+	}
+	this.Shared[code] = normalized
+	return nil
+}
+
+// RemoveKeys 从通用结构中递归删除指定的键，返回新的结构
+func RemoveKeys(value any, keys ...string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		var result = map[string]any{}
+		for key, item := range v {
+			var found = false
+			for _, removingKey := range keys {
+				if key == removingKey {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			result[key] = RemoveKeys(item, keys...)
+		}
+		return result
+	case []any:
+		var result = make([]any, 0, len(v))
+		for _, item := range v {
+			result = append(result, RemoveKeys(item, keys...))
+		}
+		return result
+	}
+	return value
+}
+
+// 将任意值转换为JSON兼容的通用结构，用于比较和编码
+func normalize(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, string, float64, int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		i, err := v.Int64()
+		if err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		if f == float64(int64(f)) {
+			return int64(f), nil
+		}
+		return f, nil
+	case []any:
+		var result = make([]any, 0, len(v))
+		for _, item := range v {
+			n, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, n)
+		}
+		return result, nil
+	case map[string]any:
+		var result = map[string]any{}
+		for key, item := range v {
+			n, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = n
+		}
+		return result, nil
+	case map[any]any:
+		var result = map[string]any{}
+		for key, item := range v {
+			n, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprintf("%v", key)] = n
+		}
+		return result, nil
+	}
+
+	// 其他类型通过JSON转换
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return normalize(result)
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configcodeutils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+type ChangeOp = string
+
+const (
+	ChangeOpAdd    ChangeOp = "add"
+	ChangeOpRemove ChangeOp = "remove"
+	ChangeOpChange ChangeOp = "change"
+)
+
+type SectionAction = string
+
+const (
+	SectionActionNone     SectionAction = "none"     // 无变化
+	SectionActionUpdate   SectionAction = "update"   // 需要更新
+	SectionActionConflict SectionAction = "conflict" // 导出后当前配置已被修改，拒绝应用
+)
+
+// FieldChange 单个字段的变化
+type FieldChange struct {
+	Op       ChangeOp `json:"op"`
+	Path     string   `json:"path"`
+	OldValue any      `json:"oldValue"`
+	NewValue any      `json:"newValue"`
+}
+
+// SectionPlan 单个分段的计划
+type SectionPlan struct {
+	Code        string         `json:"code"`
+	Name        string         `json:"name"`
+	Action      SectionAction  `json:"action"`
+	Changes     []*FieldChange `json:"changes"`
+	DesiredJSON []byte         `json:"-"`
+}
+
+// Plan 应用计划
+type Plan struct {
+	Sections     []*SectionPlan `json:"sections"`
+	SourceServer *ServerInfo    `json:"sourceServer"` // 文档来源网站，和当前网站不同时表示从其他网站导入
+}
+
+// HasChanges 是否有需要应用的变化
+func (this *Plan) HasChanges() bool {
+	for _, section := range this.Sections {
+		if section.Action == SectionActionUpdate {
+			return true
+		}
+	}
+	return false
+}
+
+// ConflictSections 导出后已被修改的分段
+func (this *Plan) ConflictSections() []*SectionPlan {
+	var result = []*SectionPlan{}
+	for _, section := range this.Sections {
+		if section.Action == SectionActionConflict {
+			result = append(result, section)
+		}
+	}
+	return result
+}
+
+// CountChanges 计算有变化的分段数量
+func (this *Plan) CountChanges() int {
+	var count = 0
+	for _, section := range this.Sections {
+		if section.Action == SectionActionUpdate {
+			count++
+		}
+	}
+	return count
+}
+
+// BuildPlan 对比当前配置和期望配置，生成计划
+// 期望配置中未出现的分段保持不变；对于同一网站导出的文档，如果分段记录的摘要和当前内容不一致，
+// 说明导出后配置已被修改，该分段会被标记为冲突
+func BuildPlan(current *Document, desired *Document) (*Plan, error) {
+	var plan = &Plan{
+		Sections: []*SectionPlan{},
+	}
+	if desired.Server != nil && current.Server != nil && desired.Server.Id > 0 && desired.Server.Id != current.Server.Id {
+		plan.SourceServer = desired.Server
+	}
+	for _, code := range desired.SectionCodes() {
+		var desiredValue = desired.Sections[code]
+		var currentValue = current.Sections[code]
+
+		desiredJSON, err := json.Marshal(desiredValue)
+		if err != nil {
+			return nil, err
+		}
+
+		var sectionPlan = &SectionPlan{
+			Code:        code,
+			Name:        FindSectionName(code),
+			Action:      SectionActionNone,
+			Changes:     Diff("", currentValue, desiredValue),
+			DesiredJSON: desiredJSON,
+		}
+		if len(sectionPlan.Changes) > 0 {
+			sectionPlan.Action = SectionActionUpdate
+
+			expectedRevision, ok := desired.Revisions[code]
+			if ok && plan.SourceServer == nil {
+				currentRevision, err := current.SectionRevision(code)
+				if err != nil {
+					return nil, err
+				}
+				if currentRevision != expectedRevision {
+					sectionPlan.Action = SectionActionConflict
+				}
+			}
+		}
+		plan.Sections = append(plan.Sections, sectionPlan)
+	}
+	return plan, nil
+}
+
+// Diff 比较两个通用结构的差异
+func Diff(path string, oldValue any, newValue any) []*FieldChange {
+	var changes = []*FieldChange{}
+
+	if oldValue == nil && newValue == nil {
+		return changes
+	}
+	if oldValue == nil {
+		return append(changes, &FieldChange{Op: ChangeOpAdd, Path: path, NewValue: newValue})
+	}
+	if newValue == nil {
+		return append(changes, &FieldChange{Op: ChangeOpRemove, Path: path, OldValue: oldValue})
+	}
+
+	switch oldV := oldValue.(type) {
+	case map[string]any:
+		newV, ok := newValue.(map[string]any)
+		if !ok {
+			break
+		}
+		var keys = []string{}
+		for key := range oldV {
+			keys = append(keys, key)
+		}
+		for key := range newV {
+			_, exists := oldV[key]
+			if !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var childPath = key
+			if len(path) > 0 {
+				childPath = path + "." + key
+			}
+			oldChild, oldExists := oldV[key]
+			newChild, newExists := newV[key]
+			if !newExists {
+				if oldChild != nil {
+					changes = append(changes, &FieldChange{Op: ChangeOpRemove, Path: childPath, OldValue: oldChild})
+				}
+				continue
+			}
+			if !oldExists {
+				if newChild != nil {
+					changes = append(changes, &FieldChange{Op: ChangeOpAdd, Path: childPath, NewValue: newChild})
+				}
+				continue
+			}
+			changes = append(changes, Diff(childPath, oldChild, newChild)...)
+		}
+		return changes
+	case []any:
+		newV, ok := newValue.([]any)
+		if !ok {
+			break
+		}
+		for index := 0; index < len(oldV) || index < len(newV); index++ {
+			var childPath = path + "[" + strconv.Itoa(index) + "]"
+			if index >= len(newV) {
+				changes = append(changes, &FieldChange{Op: ChangeOpRemove, Path: childPath, OldValue: oldV[index]})
+				continue
+			}
+			if index >= len(oldV) {
+				changes = append(changes, &FieldChange{Op: ChangeOpAdd, Path: childPath, NewValue: newV[index]})
+				continue
+			}
+			changes = append(changes, Diff(childPath, oldV[index], newV[index])...)
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		changes = append(changes, &FieldChange{Op: ChangeOpChange, Path: path, OldValue: oldValue, NewValue: newValue})
+	}
+	return changes
+}
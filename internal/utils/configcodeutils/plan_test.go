@@ -0,0 +1,181 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configcodeutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestDocument_YAML(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var doc = configcodeutils.NewDocument(1, "example.com")
+	a.IsNil(doc.SetSectionJSON(configcodeutils.SectionRedirects, []byte(`[{"isOn":true,"beforeURL":"http://a.com","afterURL":"https://a.com","status":301}]`)))
+	a.IsNil(doc.SetSectionJSON(configcodeutils.SectionWAF, []byte(`{"isOn":true,"firewallPolicyId":1000000}`)))
+
+	data, err := doc.EncodeYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(string(data))
+
+	newDoc, err := configcodeutils.DecodeYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(newDoc.Server.Id == 1)
+
+	plan, err := configcodeutils.BuildPlan(doc, newDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsFalse(plan.HasChanges())
+	a.IsTrue(len(plan.Sections) == 2)
+
+	wafJSON, ok, err := newDoc.SectionJSON(configcodeutils.SectionWAF)
+	a.IsNil(err)
+	a.IsTrue(ok)
+	a.IsTrue(string(wafJSON) == `{"firewallPolicyId":1000000,"isOn":true}`)
+}
+
+func TestDocument_DecodeYAML_Unknown(t *testing.T) {
+	_, err := configcodeutils.DecodeYAML([]byte(`
+version: 2
+sections:
+  unknown: {}
+`))
+	if err == nil {
+		t.Fatal("should fail")
+	}
+	t.Log(err)
+}
+
+func TestBuildPlan(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var current = configcodeutils.NewDocument(1, "example.com")
+	a.IsNil(current.SetSectionJSON(configcodeutils.SectionCache, []byte(`{"isOn":false,"cacheRefs":[{"key":"a"}]}`)))
+	a.IsNil(current.SetSectionJSON(configcodeutils.SectionWAF, []byte(`{"isOn":true}`)))
+
+	desired, err := configcodeutils.DecodeYAML([]byte(`
+version: 2
+sections:
+  cache:
+    isOn: true
+    cacheRefs:
+      - key: a
+      - key: b
+  redirects:
+    - isOn: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := configcodeutils.BuildPlan(current, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(plan.CountChanges() == 2)
+	for _, section := range plan.Sections {
+		for _, change := range section.Changes {
+			t.Log(section.Code, change.Op, change.Path, change.OldValue, "=>", change.NewValue)
+		}
+	}
+
+	var cachePlan = plan.Sections[0]
+	a.IsTrue(cachePlan.Code == configcodeutils.SectionCache)
+	a.IsTrue(len(cachePlan.Changes) == 2)
+	a.IsTrue(cachePlan.Changes[0].Path == "cacheRefs[1]")
+	a.IsTrue(cachePlan.Changes[1].Path == "isOn")
+}
+
+func TestBuildPlan_Revisions(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var exported = configcodeutils.NewDocument(1, "example.com")
+	a.IsNil(exported.SetSectionJSON(configcodeutils.SectionCache, []byte(`{"isOn":false}`)))
+	a.IsNil(exported.SetSectionJSON(configcodeutils.SectionWAF, []byte(`{"isOn":false}`)))
+	a.IsNil(exported.SealRevisions())
+	data, err := exported.EncodeYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desired, err := configcodeutils.DecodeYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsNil(desired.SetSectionJSON(configcodeutils.SectionCache, []byte(`{"isOn":true}`)))
+
+	// 导出后其他人修改了waf
+	var current = configcodeutils.NewDocument(1, "example.com")
+	a.IsNil(current.SetSectionJSON(configcodeutils.SectionCache, []byte(`{"isOn":false}`)))
+	a.IsNil(current.SetSectionJSON(configcodeutils.SectionWAF, []byte(`{"isOn":true}`)))
+
+	plan, err := configcodeutils.BuildPlan(current, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(plan.CountChanges() == 1)
+	a.IsTrue(plan.Sections[0].Action == configcodeutils.SectionActionUpdate)
+	a.IsTrue(plan.Sections[1].Action == configcodeutils.SectionActionConflict)
+	a.IsTrue(len(plan.ConflictSections()) == 1)
+
+	// 从其他网站导入时不检查摘要
+	current.Server.Id = 2
+	plan, err = configcodeutils.BuildPlan(current, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(plan.CountChanges() == 2)
+	a.IsTrue(len(plan.ConflictSections()) == 0)
+}
+
+func TestDiff(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsTrue(len(configcodeutils.Diff("", nil, nil)) == 0)
+	a.IsTrue(len(configcodeutils.Diff("", int64(1), int64(1))) == 0)
+	a.IsTrue(len(configcodeutils.Diff("", int64(1), "1")) == 1)
+	a.IsTrue(len(configcodeutils.Diff("", map[string]any{"a": nil}, map[string]any{})) == 0)
+	a.IsTrue(len(configcodeutils.Diff("", []any{int64(1)}, []any{})) == 1)
+}
+
+func TestDocument_DecodeYAML_Outdated(t *testing.T) {
+	_, err := configcodeutils.DecodeYAML([]byte(`
+version: 1
+sections:
+  waf:
+    isOn: true
+    firewallPolicyId: 1
+`))
+	if err == nil {
+		t.Fatal("should fail")
+	}
+	t.Log(err)
+}
+
+func TestRemoveKeys(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var doc = configcodeutils.NewDocument(1, "example.com")
+	a.IsNil(doc.SetSectionJSON(configcodeutils.SectionWAF, []byte(`{"id":1,"isOn":true,"groups":[{"id":2,"code":"xss","sets":[{"id":3,"ruleRefs":[{"ruleId":4}]}]}]}`)))
+
+	var value = configcodeutils.RemoveKeys(doc.Sections[configcodeutils.SectionWAF], "id", "ruleRefs")
+	var changes = configcodeutils.Diff("", value, map[string]any{
+		"isOn": true,
+		"groups": []any{
+			map[string]any{
+				"code": "xss",
+				"sets": []any{map[string]any{}},
+			},
+		},
+	})
+	a.IsTrue(len(changes) == 0)
+
+	// 原来的结构不受影响
+	a.IsTrue(doc.Sections[configcodeutils.SectionWAF].(map[string]any)["id"] == int64(1))
+}
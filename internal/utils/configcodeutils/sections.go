@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package configcodeutils
+
+// 配置分段代号
+const (
+	SectionWeb             = "web"             // 静态分发
+	SectionHTTP            = "http"            // HTTP
+	SectionHTTPS           = "https"           // HTTPS
+	SectionReverseProxy    = "reverseProxy"    // 反向代理
+	SectionLocations       = "locations"       // 路由规则
+	SectionRequestHeaders  = "requestHeaders"  // 请求报头
+	SectionResponseHeaders = "responseHeaders" // 响应报头
+	SectionCache           = "cache"           // 缓存
+	SectionWAF             = "waf"             // WAF
+	SectionRedirects       = "redirects"       // URL跳转
+	SectionRewrites        = "rewrites"        // 重写规则
+)
+
+// SectionDefinition 分段定义
+type SectionDefinition struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// AllSections 所有支持的分段，顺序即为应用顺序
+func AllSections() []*SectionDefinition {
+	return []*SectionDefinition{
+		{Code: SectionHTTP, Name: "HTTP"},
+		{Code: SectionHTTPS, Name: "HTTPS"},
+		{Code: SectionReverseProxy, Name: "反向代理"},
+		{Code: SectionWeb, Name: "静态分发"},
+		{Code: SectionLocations, Name: "路由规则"},
+		{Code: SectionRequestHeaders, Name: "请求报头"},
+		{Code: SectionResponseHeaders, Name: "响应报头"},
+		{Code: SectionCache, Name: "缓存"},
+		{Code: SectionWAF, Name: "WAF"},
+		{Code: SectionRedirects, Name: "URL跳转"},
+		{Code: SectionRewrites, Name: "重写规则"},
+	}
+}
+
+// FindSection 根据代号查找分段定义
+func FindSection(code string) *SectionDefinition {
+	for _, section := range AllSections() {
+		if section.Code == code {
+			return section
+		}
+	}
+	return nil
+}
+
+// FindSectionName 根据代号查找分段名称
+func FindSectionName(code string) string {
+	var section = FindSection(code)
+	if section != nil {
+		return section.Name
+	}
+	return code
+}
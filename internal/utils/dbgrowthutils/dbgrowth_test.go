@@ -0,0 +1,80 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbgrowthutils_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbgrowthutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestMatchFamily(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeHTTPAccessLogs_20240501") == dbgrowthutils.FamilyAccessLogs)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeHTTPAccessLogs_20240501_0001") == dbgrowthutils.FamilyAccessLogs)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeNSAccessLogs_20240501") == dbgrowthutils.FamilyAccessLogs)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeServerBandwidthStats_3") == dbgrowthutils.FamilyServerBandwidthStats)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeServerDomainHourlyStats_12") == dbgrowthutils.FamilyServerDomainHourlyStats)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeNodeValues") == dbgrowthutils.FamilyNodeValues)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeHTTPCacheTaskKeys") == dbgrowthutils.FamilyHTTPCacheTasks)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeMetricSumStats_1") == dbgrowthutils.FamilyMetricStats)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeNodes") == dbgrowthutils.FamilyOther)
+	a.IsTrue(dbgrowthutils.MatchFamily("edgeHTTPAccessLogPolicies") == dbgrowthutils.FamilyOther)
+}
+
+func TestStore(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var store = dbgrowthutils.NewStore()
+	_, ok := store.DaysUntilFull()
+	a.IsFalse(ok)
+
+	var days = []string{"20240501", "20240502", "20240503", "20240504"}
+	for index, day := range days {
+		store.Add(dbgrowthutils.NewSnapshot(day, []*dbgrowthutils.TableInfo{
+			{Name: "edgeHTTPAccessLogs_" + day, Rows: 100, Bytes: 1000},
+			{Name: "edgeNodes", Rows: 10, Bytes: 100},
+			{Name: "edgeNodeValues", Rows: 10, Bytes: int64(1000 * (index + 1))},
+		}, 0))
+	}
+
+	// 同一天的快照会被替换
+	store.Add(dbgrowthutils.NewSnapshot("20240502", []*dbgrowthutils.TableInfo{
+		{Name: "edgeHTTPAccessLogs_20240502", Rows: 100, Bytes: 1000},
+		{Name: "edgeNodes", Rows: 10, Bytes: 100},
+		{Name: "edgeNodeValues", Rows: 10, Bytes: 2000},
+	}, 0))
+	a.IsTrue(len(store.Snapshots) == 4)
+	a.IsTrue(store.HasDay("20240503"))
+	a.IsFalse(store.HasDay("20240505"))
+
+	var latest = store.Latest()
+	a.IsTrue(latest.Day == "20240504")
+	a.IsTrue(latest.Bytes("") == 5100)
+	a.IsTrue(latest.FindFamily(dbgrowthutils.FamilyNodeValues).Bytes == 4000)
+	a.IsTrue(latest.Families[0].Code == dbgrowthutils.FamilyAccessLogs)
+
+	a.IsTrue(math.Abs(store.DailyGrowth(dbgrowthutils.FamilyNodeValues, 7)-1000) < 0.001)
+	a.IsTrue(math.Abs(store.DailyGrowth(dbgrowthutils.FamilyAccessLogs, 7)) < 0.001)
+	a.IsTrue(store.ProjectBytes(dbgrowthutils.FamilyNodeValues, 10) == 14000)
+
+	store.DiskCapacityBytes = 15100
+	days2, ok := store.DaysUntilFull()
+	a.IsTrue(ok)
+	a.IsTrue(days2 == 10)
+	_, ok = store.ShouldWarn()
+	a.IsTrue(ok)
+	store.WarningDays = 7
+	_, ok = store.ShouldWarn()
+	a.IsFalse(ok)
+}
+
+func TestProjectRetention(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsTrue(dbgrowthutils.ProjectRetention(3000, 0, 30, 7) == 700)
+	a.IsTrue(dbgrowthutils.ProjectRetention(3000, 100, 0, 7) == 700)
+	a.IsTrue(dbgrowthutils.ProjectRetention(3000, 0, 0, 7) == -1)
+	a.IsTrue(dbgrowthutils.ProjectRetention(3000, 100, 30, 0) == -1)
+}
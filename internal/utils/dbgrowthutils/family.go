@@ -0,0 +1,76 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbgrowthutils
+
+import "regexp"
+
+// 数据表分类代号
+const (
+	FamilyAccessLogs                   = "accessLogs"
+	FamilyServerBandwidthStats         = "serverBandwidthStats"
+	FamilyUserBandwidthStats           = "userBandwidthStats"
+	FamilyUserPlanBandwidthStats       = "userPlanBandwidthStats"
+	FamilyServerDailyStats             = "serverDailyStats"
+	FamilyServerDomainHourlyStats      = "serverDomainHourlyStats"
+	FamilyTrafficDailyStats            = "trafficDailyStats"
+	FamilyTrafficHourlyStats           = "trafficHourlyStats"
+	FamilyNodeClusterTrafficDailyStats = "nodeClusterTrafficDailyStats"
+	FamilyNodeTrafficDailyStats        = "nodeTrafficDailyStats"
+	FamilyNodeTrafficHourlyStats       = "nodeTrafficHourlyStats"
+	FamilyHTTPCacheTasks               = "httpCacheTasks"
+	FamilyNodeValues                   = "nodeValues"
+	FamilyMetricStats                  = "metricStats"
+	FamilyOther                        = "other"
+)
+
+// Family 数据表分类
+type Family struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	HasRetention bool   `json:"hasRetention"` // 是否可以设置保留天数
+
+	reg *regexp.Regexp
+}
+
+var allFamilies = []*Family{
+	{Code: FamilyAccessLogs, Name: "访问日志", HasRetention: true, reg: regexp.MustCompile(`^edge(HTTP|NS)AccessLogs_`)},
+	{Code: FamilyServerBandwidthStats, Name: "网站带宽统计", HasRetention: true, reg: regexp.MustCompile(`^edgeServerBandwidthStats(_\d+)?$`)},
+	{Code: FamilyUserBandwidthStats, Name: "用户带宽统计", HasRetention: true, reg: regexp.MustCompile(`^edgeUserBandwidthStats(_\d+)?$`)},
+	{Code: FamilyUserPlanBandwidthStats, Name: "用户套餐带宽统计", HasRetention: true, reg: regexp.MustCompile(`^edgeUserPlanBandwidthStats(_\d+)?$`)},
+	{Code: FamilyServerDailyStats, Name: "网站每日统计", HasRetention: true, reg: regexp.MustCompile(`^edgeServerDailyStats(_\d+)?$`)},
+	{Code: FamilyServerDomainHourlyStats, Name: "网站域名每小时统计", HasRetention: true, reg: regexp.MustCompile(`^edgeServerDomainHourlyStats(_\d+)?$`)},
+	{Code: FamilyTrafficDailyStats, Name: "总体每日流量统计", HasRetention: true, reg: regexp.MustCompile(`^edgeTrafficDailyStats$`)},
+	{Code: FamilyTrafficHourlyStats, Name: "总体每小时流量统计", HasRetention: true, reg: regexp.MustCompile(`^edgeTrafficHourlyStats$`)},
+	{Code: FamilyNodeClusterTrafficDailyStats, Name: "集群每日流量统计", HasRetention: true, reg: regexp.MustCompile(`^edgeNodeClusterTrafficDailyStats$`)},
+	{Code: FamilyNodeTrafficDailyStats, Name: "节点每日流量统计", HasRetention: true, reg: regexp.MustCompile(`^edgeNodeTrafficDailyStats$`)},
+	{Code: FamilyNodeTrafficHourlyStats, Name: "节点每小时流量统计", HasRetention: true, reg: regexp.MustCompile(`^edgeNodeTrafficHourlyStats$`)},
+	{Code: FamilyHTTPCacheTasks, Name: "缓存任务", HasRetention: true, reg: regexp.MustCompile(`^edgeHTTPCacheTask(s|Keys)$`)},
+	{Code: FamilyNodeValues, Name: "节点监控数据", reg: regexp.MustCompile(`^edgeNodeValues$`)},
+	{Code: FamilyMetricStats, Name: "指标统计", reg: regexp.MustCompile(`^edgeMetric(Stats|SumStats)(_\d+)?$`)},
+	{Code: FamilyOther, Name: "其他"},
+}
+
+// AllFamilies 所有数据表分类
+func AllFamilies() []*Family {
+	return allFamilies
+}
+
+// FindFamily 根据代号查找分类
+func FindFamily(code string) *Family {
+	for _, family := range allFamilies {
+		if family.Code == code {
+			return family
+		}
+	}
+	return nil
+}
+
+// MatchFamily 查找数据表所属的分类代号
+func MatchFamily(tableName string) string {
+	for _, family := range allFamilies {
+		if family.reg != nil && family.reg.MatchString(tableName) {
+			return family.Code
+		}
+	}
+	return FamilyOther
+}
@@ -0,0 +1,238 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbgrowthutils
+
+import (
+	"sort"
+	"time"
+)
+
+// 最多保留的快照天数
+const MaxSnapshots = 180
+
+// 计算增长速度时使用的天数
+const GrowthWindowDays = 7
+
+// TableInfo 数据表信息
+type TableInfo struct {
+	Name  string
+	Rows  int64
+	Bytes int64 // 数据和索引占用的空间
+}
+
+// FamilyStat 某个分类在某天的统计
+type FamilyStat struct {
+	Code        string `json:"code"`
+	CountTables int    `json:"countTables"`
+	Rows        int64  `json:"rows"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// Snapshot 每天的数据表快照
+type Snapshot struct {
+	Day       string        `json:"day"` // YYYYMMDD
+	CreatedAt int64         `json:"createdAt"`
+	Families  []*FamilyStat `json:"families"`
+}
+
+// NewSnapshot 根据数据表信息生成快照
+func NewSnapshot(day string, tables []*TableInfo, now int64) *Snapshot {
+	var statMap = map[string]*FamilyStat{}
+	for _, table := range tables {
+		var code = MatchFamily(table.Name)
+		stat, ok := statMap[code]
+		if !ok {
+			stat = &FamilyStat{Code: code}
+			statMap[code] = stat
+		}
+		stat.CountTables++
+		stat.Rows += table.Rows
+		stat.Bytes += table.Bytes
+	}
+
+	// 按照分类定义的顺序排列
+	var stats = []*FamilyStat{}
+	for _, family := range AllFamilies() {
+		stat, ok := statMap[family.Code]
+		if ok {
+			stats = append(stats, stat)
+		}
+	}
+	return &Snapshot{
+		Day:       day,
+		CreatedAt: now,
+		Families:  stats,
+	}
+}
+
+// FindFamily 查找分类统计
+func (this *Snapshot) FindFamily(code string) *FamilyStat {
+	for _, stat := range this.Families {
+		if stat.Code == code {
+			return stat
+		}
+	}
+	return nil
+}
+
+// Bytes 某个分类占用的空间，code为空表示所有分类
+func (this *Snapshot) Bytes(code string) int64 {
+	var total int64
+	for _, stat := range this.Families {
+		if len(code) == 0 || stat.Code == code {
+			total += stat.Bytes
+		}
+	}
+	return total
+}
+
+// Store 快照和容量设置
+type Store struct {
+	DiskCapacityBytes int64       `json:"diskCapacityBytes"` // 数据库所在磁盘的容量，0表示未设置
+	WarningDays       int         `json:"warningDays"`       // 预计在多少天内用满时提醒
+	Snapshots         []*Snapshot `json:"snapshots"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		WarningDays: 30,
+		Snapshots:   []*Snapshot{},
+	}
+}
+
+// HasDay 检查某天是否已经有快照
+func (this *Store) HasDay(day string) bool {
+	for _, snapshot := range this.Snapshots {
+		if snapshot.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 添加快照，同一天的快照会被替换
+func (this *Store) Add(snapshot *Snapshot) {
+	var snapshots = []*Snapshot{}
+	for _, s := range this.Snapshots {
+		if s.Day != snapshot.Day {
+			snapshots = append(snapshots, s)
+		}
+	}
+	snapshots = append(snapshots, snapshot)
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Day < snapshots[j].Day
+	})
+	if len(snapshots) > MaxSnapshots {
+		snapshots = snapshots[len(snapshots)-MaxSnapshots:]
+	}
+	this.Snapshots = snapshots
+}
+
+// Latest 最新的快照
+func (this *Store) Latest() *Snapshot {
+	if len(this.Snapshots) == 0 {
+		return nil
+	}
+	return this.Snapshots[len(this.Snapshots)-1]
+}
+
+// DailyGrowth 计算最近几天的平均每日增长字节数，code为空表示所有分类
+// 使用最小二乘法拟合，避免某一天的清理或者突增影响结果
+func (this *Store) DailyGrowth(code string, windowDays int) float64 {
+	var snapshots = this.Snapshots
+	if len(snapshots) > windowDays+1 {
+		snapshots = snapshots[len(snapshots)-windowDays-1:]
+	}
+	if len(snapshots) < 2 {
+		return 0
+	}
+
+	var xs = []float64{}
+	var ys = []float64{}
+	for _, snapshot := range snapshots {
+		day, err := time.Parse("20060102", snapshot.Day)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, float64(day.Unix())/86400)
+		ys = append(ys, float64(snapshot.Bytes(code)))
+	}
+	if len(xs) < 2 {
+		return 0
+	}
+
+	var n = float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	var denominator = n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// ProjectBytes 按照当前增长速度，预测若干天后占用的空间
+func (this *Store) ProjectBytes(code string, days int) int64 {
+	var latest = this.Latest()
+	if latest == nil {
+		return 0
+	}
+	var result = float64(latest.Bytes(code)) + this.DailyGrowth(code, GrowthWindowDays)*float64(days)
+	if result < 0 {
+		return 0
+	}
+	return int64(result)
+}
+
+// DaysUntilFull 按照当前增长速度，预计多少天后磁盘会被用满
+// 如果没有设置磁盘容量或者没有增长，则返回false
+func (this *Store) DaysUntilFull() (days int, ok bool) {
+	var latest = this.Latest()
+	if latest == nil || this.DiskCapacityBytes <= 0 {
+		return 0, false
+	}
+	var growth = this.DailyGrowth("", GrowthWindowDays)
+	var left = this.DiskCapacityBytes - latest.Bytes("")
+	if left <= 0 {
+		return 0, true
+	}
+	if growth <= 0 {
+		return 0, false
+	}
+	return int(float64(left) / growth), true
+}
+
+// ShouldWarn 是否需要提醒磁盘即将用满
+func (this *Store) ShouldWarn() (days int, ok bool) {
+	days, ok = this.DaysUntilFull()
+	if !ok {
+		return
+	}
+	var warningDays = this.WarningDays
+	if warningDays <= 0 {
+		warningDays = 30
+	}
+	return days, days <= warningDays
+}
+
+// ProjectRetention 预测修改保留天数后某个分类占用的空间
+// 如果当前已经设置了保留天数，认为每天的数据量相同，按比例计算；否则按照每日增长计算
+// 返回-1表示无法预测，比如不限制保留天数时
+func ProjectRetention(currentBytes int64, dailyGrowth float64, currentDays int, newDays int) int64 {
+	if newDays <= 0 {
+		return -1
+	}
+	if currentDays > 0 {
+		return currentBytes * int64(newDays) / int64(currentDays)
+	}
+	if dailyGrowth <= 0 {
+		return -1
+	}
+	return int64(dailyGrowth * float64(newDays))
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbmigrateutils
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+)
+
+// DBConfig 数据库连接信息
+type DBConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+}
+
+// ParseSimpleDBConfig 从API节点数据库配置中读取连接信息
+func ParseSimpleDBConfig(config *configs.SimpleDBConfig) *DBConfig {
+	host, portString, err := net.SplitHostPort(config.Host)
+	if err != nil {
+		host = strings.Trim(config.Host, "[]")
+		portString = "3306"
+	}
+	port, _ := strconv.Atoi(portString)
+	if port <= 0 {
+		port = 3306
+	}
+	return &DBConfig{
+		Host:     host,
+		Port:     port,
+		Username: config.User,
+		Password: config.Password,
+		Database: config.Database,
+	}
+}
+
+// Addr 主机地址和端口
+func (this *DBConfig) Addr() string {
+	return net.JoinHostPort(this.Host, strconv.Itoa(this.Port))
+}
+
+// DSN 连接字符串
+// 如果 withDatabase 为false，则不指定数据库，用于创建数据库
+func (this *DBConfig) DSN(withDatabase bool) string {
+	var database = ""
+	if withDatabase {
+		database = url.PathEscape(this.Database)
+	}
+	return url.QueryEscape(this.Username) + ":" + this.Password + "@tcp(" + this.Addr() + ")/" + database + "?charset=utf8mb4&timeout=30s"
+}
+
+// SimpleDBConfig 转换为API节点数据库配置
+func (this *DBConfig) SimpleDBConfig() *configs.SimpleDBConfig {
+	return &configs.SimpleDBConfig{
+		User:     this.Username,
+		Password: this.Password,
+		Database: this.Database,
+		Host:     this.Addr(),
+	}
+}
+
+// Equals 检查是否为同一个数据库
+func (this *DBConfig) Equals(other *DBConfig) bool {
+	if other == nil {
+		return false
+	}
+	return strings.EqualFold(this.Host, other.Host) && this.Port == other.Port && this.Database == other.Database
+}
+
+// Masked 隐藏密码后的连接信息，用于展示
+func (this *DBConfig) Masked() *DBConfig {
+	var config = *this
+	config.Password = strings.Repeat("*", len(this.Password))
+	return &config
+}
@@ -0,0 +1,288 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbmigrateutils
+
+import (
+	"errors"
+	"regexp"
+)
+
+// 迁移状态
+const (
+	StatusCopying    = "copying"    // 正在复制
+	StatusCopied     = "copied"     // 复制完成
+	StatusVerifying  = "verifying"  // 正在校验
+	StatusVerified   = "verified"   // 校验完成
+	StatusFailed     = "failed"     // 复制或校验失败
+	StatusSwitched   = "switched"   // 已切换到新数据库
+	StatusRolledBack = "rolledBack" // 已回滚到原数据库
+)
+
+// 数据表复制状态
+const (
+	TableStatusPending = "pending"
+	TableStatusCopying = "copying"
+	TableStatusCopied  = "copied"
+	TableStatusFailed  = "failed"
+	TableStatusSkipped = "skipped"
+)
+
+// 数据表校验结果
+const (
+	VerifyOk               = "ok"
+	VerifyCountMismatch    = "countMismatch"
+	VerifyChecksumMismatch = "checksumMismatch"
+	VerifyFailed           = "failed"
+)
+
+var ErrMigrationNotFound = errors.New("migration not found")
+
+var accessLogTableReg = regexp.MustCompile(`^edge(HTTP|NS)AccessLogs_\d+`)
+
+// IsAccessLogTable 检查是否为访问日志分表
+// 访问日志数据量通常很大，迁移时可以选择跳过
+func IsAccessLogTable(tableName string) bool {
+	return accessLogTableReg.MatchString(tableName)
+}
+
+// Options 迁移选项
+type Options struct {
+	SkipAccessLogs bool `json:"skipAccessLogs"` // 跳过访问日志分表
+	DropExisting   bool `json:"dropExisting"`   // 删除目标数据库中已存在的同名表
+}
+
+// Table 数据表迁移进度
+type Table struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	EstimatedRows int64  `json:"estimatedRows"` // 复制前估算的行数
+	CopiedRows    int64  `json:"copiedRows"`
+	Error         string `json:"error"`
+
+	VerifyStatus   string `json:"verifyStatus"`
+	SourceRows     int64  `json:"sourceRows"`
+	TargetRows     int64  `json:"targetRows"`
+	SourceChecksum string `json:"sourceChecksum"`
+	TargetChecksum string `json:"targetChecksum"`
+	VerifyError    string `json:"verifyError"`
+}
+
+// Verify 根据行数和校验和设置校验结果
+func (this *Table) Verify(sourceRows int64, targetRows int64, sourceChecksum string, targetChecksum string) {
+	this.SourceRows = sourceRows
+	this.TargetRows = targetRows
+	this.SourceChecksum = sourceChecksum
+	this.TargetChecksum = targetChecksum
+	this.VerifyError = ""
+	if sourceRows != targetRows {
+		this.VerifyStatus = VerifyCountMismatch
+		return
+	}
+	if sourceChecksum != targetChecksum {
+		this.VerifyStatus = VerifyChecksumMismatch
+		return
+	}
+	this.VerifyStatus = VerifyOk
+}
+
+// FileBackup 切换前的配置文件备份，用于回滚
+type FileBackup struct {
+	Path   string `json:"path"`
+	Data   []byte `json:"data"`
+	Exists bool   `json:"exists"` // 切换前文件是否存在
+}
+
+// Migration 一次数据库迁移
+type Migration struct {
+	Id           string        `json:"id"`
+	Status       string        `json:"status"`
+	Source       *DBConfig     `json:"source"`
+	Target       *DBConfig     `json:"target"`
+	Options      *Options      `json:"options"`
+	Tables       []*Table      `json:"tables"`
+	Error        string        `json:"error"`
+	Backups      []*FileBackup `json:"backups"`
+	CreatedAt    int64         `json:"createdAt"`
+	UpdatedAt    int64         `json:"updatedAt"`
+	SwitchedAt   int64         `json:"switchedAt"`
+	RolledBackAt int64         `json:"rolledBackAt"`
+}
+
+// NewMigration 创建迁移
+func NewMigration(id string, source *DBConfig, target *DBConfig, options *Options, tables []*Table, now int64) *Migration {
+	if options == nil {
+		options = &Options{}
+	}
+	for _, table := range tables {
+		if options.SkipAccessLogs && IsAccessLogTable(table.Name) {
+			table.Status = TableStatusSkipped
+		} else {
+			table.Status = TableStatusPending
+		}
+	}
+	return &Migration{
+		Id:        id,
+		Status:    StatusCopying,
+		Source:    source,
+		Target:    target,
+		Options:   options,
+		Tables:    tables,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// FindTable 查找数据表
+func (this *Migration) FindTable(name string) *Table {
+	for _, table := range this.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// CountTables 按复制状态统计数据表数量
+func (this *Migration) CountTables(status string) int {
+	var count = 0
+	for _, table := range this.Tables {
+		if table.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// CountVerified 按校验结果统计数据表数量
+func (this *Migration) CountVerified(verifyStatus string) int {
+	var count = 0
+	for _, table := range this.Tables {
+		if table.Status != TableStatusSkipped && table.VerifyStatus == verifyStatus {
+			count++
+		}
+	}
+	return count
+}
+
+// Progress 复制进度，0-100
+func (this *Migration) Progress() float64 {
+	var total = 0
+	var done float64 = 0
+	for _, table := range this.Tables {
+		if table.Status == TableStatusSkipped {
+			continue
+		}
+		total++
+		switch table.Status {
+		case TableStatusCopied, TableStatusFailed:
+			done++
+		case TableStatusCopying:
+			if table.EstimatedRows > 0 {
+				var ratio = float64(table.CopiedRows) / float64(table.EstimatedRows)
+				if ratio > 0.99 {
+					ratio = 0.99
+				}
+				done += ratio
+			}
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return done * 100 / float64(total)
+}
+
+// IsRunning 是否正在复制或校验
+func (this *Migration) IsRunning() bool {
+	return this.Status == StatusCopying || this.Status == StatusVerifying
+}
+
+// FinishCopying 结束复制
+func (this *Migration) FinishCopying(now int64) {
+	this.UpdatedAt = now
+	if this.CountTables(TableStatusFailed) > 0 {
+		this.Status = StatusFailed
+		this.Error = "部分数据表复制失败"
+		return
+	}
+	this.Status = StatusCopied
+	this.Error = ""
+}
+
+// FinishVerifying 结束校验
+func (this *Migration) FinishVerifying(now int64) {
+	this.UpdatedAt = now
+	if this.CountVerified(VerifyFailed) > 0 {
+		this.Status = StatusFailed
+		this.Error = "部分数据表校验失败"
+		return
+	}
+	this.Status = StatusVerified
+	this.Error = ""
+}
+
+// ResetForRecopy 如果有复制失败或者校验不一致的数据表，则将所有数据表重置为等待复制，返回失败或者不一致的数据表数量
+// 重新复制时需要复制所有数据表，以保证所有数据表来自同一个一致性快照
+func (this *Migration) ResetForRecopy(now int64) int {
+	var count = 0
+	for _, table := range this.Tables {
+		if table.Status == TableStatusSkipped {
+			continue
+		}
+		if table.Status == TableStatusFailed || (len(table.VerifyStatus) > 0 && table.VerifyStatus != VerifyOk) {
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+
+	for _, table := range this.Tables {
+		if table.Status == TableStatusSkipped {
+			continue
+		}
+		table.Status = TableStatusPending
+		table.CopiedRows = 0
+		table.Error = ""
+		table.VerifyStatus = ""
+		table.VerifyError = ""
+	}
+	this.Status = StatusCopying
+	this.Error = ""
+	this.UpdatedAt = now
+	return count
+}
+
+// CheckSwitch 检查是否可以切换到新数据库
+// 行数不一致时不允许切换；校验和不一致时（比如复制后又写入了数据）需要确认后强制切换
+func (this *Migration) CheckSwitch(force bool) error {
+	if this.Status != StatusVerified {
+		return errors.New("请先完成数据复制和校验")
+	}
+	if this.CountVerified(VerifyCountMismatch) > 0 {
+		return errors.New("有数据表的行数不一致，请重新复制不一致的数据表后再校验")
+	}
+	if this.CountVerified(VerifyChecksumMismatch) > 0 && !force {
+		return errors.New("有数据表的校验和不一致，请确认后强制切换")
+	}
+	return nil
+}
+
+// Switch 标记为已切换
+func (this *Migration) Switch(backups []*FileBackup, now int64) {
+	this.Backups = backups
+	this.Status = StatusSwitched
+	this.SwitchedAt = now
+	this.UpdatedAt = now
+}
+
+// RollBack 标记为已回滚
+func (this *Migration) RollBack(now int64) error {
+	if this.Status != StatusSwitched {
+		return errors.New("当前迁移还没有切换到新数据库")
+	}
+	this.Status = StatusRolledBack
+	this.RolledBackAt = now
+	this.UpdatedAt = now
+	return nil
+}
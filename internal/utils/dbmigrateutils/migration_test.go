@@ -0,0 +1,163 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbmigrateutils_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestParseSimpleDBConfig(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var config = dbmigrateutils.ParseSimpleDBConfig(&configs.SimpleDBConfig{
+		User:     "root",
+		Password: "123456",
+		Database: "edges",
+		Host:     "[::1]:3307",
+	})
+	a.IsTrue(config.Host == "::1")
+	a.IsTrue(config.Port == 3307)
+	a.IsTrue(config.Addr() == "[::1]:3307")
+	a.IsTrue(config.DSN(true) == "root:123456@tcp([::1]:3307)/edges?charset=utf8mb4&timeout=30s")
+	a.IsTrue(config.SimpleDBConfig().Host == "[::1]:3307")
+	a.IsTrue(config.Masked().Password == "******")
+	a.IsTrue(config.Password == "123456")
+
+	config = dbmigrateutils.ParseSimpleDBConfig(&configs.SimpleDBConfig{Host: "127.0.0.1"})
+	a.IsTrue(config.Port == 3306)
+}
+
+func TestIsAccessLogTable(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsTrue(dbmigrateutils.IsAccessLogTable("edgeHTTPAccessLogs_20240501"))
+	a.IsTrue(dbmigrateutils.IsAccessLogTable("edgeHTTPAccessLogs_20240501_0001"))
+	a.IsTrue(dbmigrateutils.IsAccessLogTable("edgeNSAccessLogs_20240501"))
+	a.IsFalse(dbmigrateutils.IsAccessLogTable("edgeHTTPAccessLogPolicies"))
+	a.IsFalse(dbmigrateutils.IsAccessLogTable("edgeNodes"))
+}
+
+func TestBatchSize(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	a.IsTrue(dbmigrateutils.BatchSize(10) == 500)
+	a.IsTrue(dbmigrateutils.BatchSize(200) == 300)
+	a.IsTrue(dbmigrateutils.BatchSize(100000) == 1)
+}
+
+func TestMigration(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var migration = dbmigrateutils.NewMigration("1", &dbmigrateutils.DBConfig{}, &dbmigrateutils.DBConfig{}, &dbmigrateutils.Options{SkipAccessLogs: true}, []*dbmigrateutils.Table{
+		{Name: "edgeNodes", EstimatedRows: 100},
+		{Name: "edgeServers", EstimatedRows: 10},
+		{Name: "edgeHTTPAccessLogs_20240501", EstimatedRows: 100000},
+	}, 100)
+	a.IsTrue(migration.CountTables(dbmigrateutils.TableStatusSkipped) == 1)
+	a.IsTrue(migration.Progress() == 0)
+
+	var table = migration.FindTable("edgeNodes")
+	table.Status = dbmigrateutils.TableStatusCopying
+	table.CopiedRows = 50
+	a.IsTrue(migration.Progress() == 25)
+	table.Status = dbmigrateutils.TableStatusCopied
+	migration.FindTable("edgeServers").Status = dbmigrateutils.TableStatusFailed
+	a.IsTrue(migration.Progress() == 100)
+
+	migration.FinishCopying(101)
+	a.IsTrue(migration.Status == dbmigrateutils.StatusFailed)
+	a.IsNotNil(migration.CheckSwitch(true))
+
+	a.IsTrue(migration.ResetForRecopy(102) == 1)
+	a.IsTrue(migration.Status == dbmigrateutils.StatusCopying)
+	a.IsTrue(migration.FindTable("edgeNodes").Status == dbmigrateutils.TableStatusPending)
+	a.IsTrue(migration.FindTable("edgeHTTPAccessLogs_20240501").Status == dbmigrateutils.TableStatusSkipped)
+	migration.FindTable("edgeNodes").Status = dbmigrateutils.TableStatusCopied
+	migration.FindTable("edgeServers").Status = dbmigrateutils.TableStatusCopied
+	migration.FinishCopying(103)
+	a.IsTrue(migration.Status == dbmigrateutils.StatusCopied)
+
+	// 校验
+	migration.FindTable("edgeNodes").Verify(100, 99, "1", "1")
+	migration.FindTable("edgeServers").Verify(10, 10, "1", "2")
+	migration.FinishVerifying(104)
+	a.IsTrue(migration.Status == dbmigrateutils.StatusVerified)
+	a.IsNotNil(migration.CheckSwitch(true))
+
+	migration.FindTable("edgeNodes").Verify(100, 100, "1", "1")
+	a.IsNotNil(migration.CheckSwitch(false))
+	a.IsNil(migration.CheckSwitch(true))
+
+	a.IsNotNil(migration.RollBack(105))
+	migration.Switch([]*dbmigrateutils.FileBackup{{Path: "a.yaml", Exists: true}}, 106)
+	a.IsNil(migration.RollBack(107))
+	a.IsTrue(migration.Status == dbmigrateutils.StatusRolledBack)
+}
+
+func TestRowHasher(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var sum = func(rows ...[]any) string {
+		var hasher = dbmigrateutils.NewRowHasher()
+		for _, row := range rows {
+			hasher.Add(row)
+		}
+		return hasher.Sum()
+	}
+
+	var row1 = []any{[]byte("1"), []byte("a"), nil}
+	var row2 = []any{[]byte("2"), []byte(""), []byte("b")}
+	a.IsTrue(sum(row1, row2) == sum(row1, row2))
+
+	// 行的顺序
+	a.IsTrue(sum(row1, row2) != sum(row2, row1))
+
+	// NULL和空字符串
+	a.IsTrue(sum([]any{nil}) != sum([]any{[]byte("")}))
+
+	// 字段之间的边界
+	a.IsTrue(sum([]any{[]byte("ab"), []byte("c")}) != sum([]any{[]byte("a"), []byte("bc")}))
+
+	var hasher = dbmigrateutils.NewRowHasher()
+	hasher.Add(row1)
+	hasher.Add(row2)
+	a.IsTrue(hasher.Count() == 2)
+}
+
+func TestMigrator_Interrupted(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var path = filepath.Join(t.TempDir(), "db_migration.json")
+	var migration = dbmigrateutils.NewMigration("1", &dbmigrateutils.DBConfig{}, &dbmigrateutils.DBConfig{}, nil, []*dbmigrateutils.Table{
+		{Name: "edgeNodes", Status: dbmigrateutils.TableStatusCopying},
+	}, 100)
+	migration.FindTable("edgeNodes").Status = dbmigrateutils.TableStatusCopying
+	data, err := json.Marshal(migration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var migrator = dbmigrateutils.NewMigrator(path)
+	current, err := migrator.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(current.Status == dbmigrateutils.StatusFailed)
+	a.IsTrue(current.FindTable("edgeNodes").Status == dbmigrateutils.TableStatusFailed)
+
+	a.IsNil(migrator.Clear())
+	current, err = migrator.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsNil(current)
+}
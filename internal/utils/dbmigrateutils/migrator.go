@@ -0,0 +1,511 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbmigrateutils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iwind/TeaGo/logs"
+	"github.com/iwind/TeaGo/rands"
+)
+
+// 复制过程中保存进度的间隔
+const saveInterval = 2 * time.Second
+
+// Migrator 数据库迁移执行器
+// 迁移过程中会切换API节点使用的数据库，所以迁移状态保存在本地文件中，而不是数据库中
+type Migrator struct {
+	path string
+
+	locker    sync.Mutex
+	migration *Migration
+	isLoaded  bool
+	isRunning bool
+	lastSave  time.Time
+}
+
+// NewMigrator 获取新对象
+func NewMigrator(path string) *Migrator {
+	return &Migrator{
+		path: path,
+	}
+}
+
+// Current 当前迁移，返回的是副本，如果没有迁移则返回nil
+func (this *Migrator) Current() (*Migration, error) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	err := this.load()
+	if err != nil {
+		return nil, err
+	}
+	if this.migration == nil {
+		return nil, nil
+	}
+	return this.clone()
+}
+
+// Start 开始新的迁移
+func (this *Migrator) Start(source *DBConfig, target *DBConfig, options *Options) error {
+	if source.Equals(target) {
+		return errors.New("目标数据库不能和当前数据库相同")
+	}
+	if options == nil {
+		options = &Options{}
+	}
+
+	this.locker.Lock()
+	err := this.load()
+	if err == nil {
+		err = this.checkCanStart()
+	}
+	this.locker.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = CheckTarget(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	sourceDB, err := Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = sourceDB.Close()
+	}()
+	tables, err := ListTables(ctx, sourceDB)
+	if err != nil {
+		return errors.New("读取当前数据库的数据表失败：" + err.Error())
+	}
+	if len(tables) == 0 {
+		return errors.New("当前数据库中没有数据表")
+	}
+
+	var migration = NewMigration(rands.HexString(16), source, target, options, tables, time.Now().Unix())
+
+	// 检查同名表
+	if !options.DropExisting {
+		targetDB, err := Open(target)
+		if err != nil {
+			return err
+		}
+		targetTables, err := ListTables(ctx, targetDB)
+		_ = targetDB.Close()
+		if err != nil {
+			return err
+		}
+		var conflictNames = []string{}
+		for _, targetTable := range targetTables {
+			var table = migration.FindTable(targetTable.Name)
+			if table != nil && table.Status != TableStatusSkipped {
+				conflictNames = append(conflictNames, table.Name)
+			}
+		}
+		if len(conflictNames) > 0 {
+			if len(conflictNames) > 5 {
+				conflictNames = append(conflictNames[:5], "...")
+			}
+			return errors.New("目标数据库中已经存在同名数据表：" + strings.Join(conflictNames, ", ") + "，请选择删除已存在的表或者更换目标数据库")
+		}
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	err = this.checkCanStart()
+	if err != nil {
+		return err
+	}
+	this.migration = migration
+	this.isRunning = true
+	err = this.save()
+	if err != nil {
+		this.isRunning = false
+		return err
+	}
+
+	go this.runCopy(options.DropExisting)
+	return nil
+}
+
+// Recopy 有复制失败或者校验不一致的数据表时，在新的一致性快照中重新复制所有数据表
+func (this *Migrator) Recopy() error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	err := this.load()
+	if err != nil {
+		return err
+	}
+	if this.migration == nil {
+		return ErrMigrationNotFound
+	}
+	if this.isRunning {
+		return errors.New("迁移正在执行中")
+	}
+	if this.migration.Status == StatusSwitched {
+		return errors.New("已经切换到新数据库，不能再复制数据")
+	}
+	if this.migration.ResetForRecopy(time.Now().Unix()) == 0 {
+		return errors.New("没有需要重新复制的数据表")
+	}
+	this.isRunning = true
+	err = this.save()
+	if err != nil {
+		this.isRunning = false
+		return err
+	}
+
+	// 重新复制时需要删除上次复制的表
+	go this.runCopy(true)
+	return nil
+}
+
+// Verify 校验数据
+func (this *Migrator) Verify() error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	err := this.load()
+	if err != nil {
+		return err
+	}
+	if this.migration == nil {
+		return ErrMigrationNotFound
+	}
+	if this.isRunning {
+		return errors.New("迁移正在执行中")
+	}
+	if this.migration.Status != StatusCopied && this.migration.Status != StatusVerified && !(this.migration.Status == StatusFailed && this.migration.CountTables(TableStatusFailed) == 0) {
+		return errors.New("请先完成数据复制")
+	}
+	this.migration.Status = StatusVerifying
+	this.migration.UpdatedAt = time.Now().Unix()
+	this.isRunning = true
+	err = this.save()
+	if err != nil {
+		this.isRunning = false
+		return err
+	}
+
+	go this.runVerify()
+	return nil
+}
+
+// Update 修改当前迁移并保存
+func (this *Migrator) Update(f func(migration *Migration) error) error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	err := this.load()
+	if err != nil {
+		return err
+	}
+	if this.migration == nil {
+		return ErrMigrationNotFound
+	}
+	if this.isRunning {
+		return errors.New("迁移正在执行中")
+	}
+	err = f(this.migration)
+	if err != nil {
+		return err
+	}
+	return this.save()
+}
+
+// Clear 清除当前迁移记录
+func (this *Migrator) Clear() error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	if this.isRunning {
+		return errors.New("迁移正在执行中")
+	}
+	this.migration = nil
+	this.isLoaded = true
+	err := os.Remove(this.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// 复制数据
+func (this *Migrator) runCopy(dropExisting bool) {
+	defer func() {
+		this.locker.Lock()
+		this.isRunning = false
+		this.locker.Unlock()
+	}()
+
+	this.locker.Lock()
+	var source = this.migration.Source
+	var target = this.migration.Target
+	var tableNames = []string{}
+	for _, table := range this.migration.Tables {
+		if table.Status == TableStatusPending {
+			tableNames = append(tableNames, table.Name)
+		}
+	}
+	this.locker.Unlock()
+
+	sourceDB, targetDB, err := this.openDBs(source, target)
+	if err != nil {
+		this.fail(err)
+		return
+	}
+	defer func() {
+		_ = sourceDB.Close()
+		_ = targetDB.Close()
+	}()
+
+	// 所有数据表都从同一个一致性快照中复制，以免复制过程中写入的数据导致表之间不一致
+	var ctx = context.Background()
+	snapshot, err := OpenSnapshot(ctx, sourceDB)
+	if err != nil {
+		this.fail(err)
+		return
+	}
+	defer func() {
+		_ = snapshot.Close()
+	}()
+
+	for _, tableName := range tableNames {
+		this.updateTable(tableName, true, func(table *Table) {
+			table.Status = TableStatusCopying
+			table.CopiedRows = 0
+		})
+
+		err = CopyTable(ctx, snapshot, targetDB, tableName, dropExisting, func(copiedRows int64) {
+			this.updateTable(tableName, false, func(table *Table) {
+				table.CopiedRows = copiedRows
+			})
+		})
+
+		this.updateTable(tableName, true, func(table *Table) {
+			if err != nil {
+				table.Status = TableStatusFailed
+				table.Error = err.Error()
+			} else {
+				table.Status = TableStatusCopied
+				table.Error = ""
+			}
+		})
+	}
+
+	this.locker.Lock()
+	this.migration.FinishCopying(time.Now().Unix())
+	err = this.save()
+	this.locker.Unlock()
+	if err != nil {
+		logs.Println("[DB_MIGRATION]save migration failed: " + err.Error())
+	}
+}
+
+// 校验数据
+func (this *Migrator) runVerify() {
+	defer func() {
+		this.locker.Lock()
+		this.isRunning = false
+		this.locker.Unlock()
+	}()
+
+	this.locker.Lock()
+	var source = this.migration.Source
+	var target = this.migration.Target
+	var tableNames = []string{}
+	for _, table := range this.migration.Tables {
+		if table.Status != TableStatusSkipped {
+			tableNames = append(tableNames, table.Name)
+		}
+	}
+	this.locker.Unlock()
+
+	sourceDB, targetDB, err := this.openDBs(source, target)
+	if err != nil {
+		this.fail(err)
+		return
+	}
+	defer func() {
+		_ = sourceDB.Close()
+		_ = targetDB.Close()
+	}()
+
+	// 源数据库的所有数据表在同一个一致性快照中校验
+	var ctx = context.Background()
+	snapshot, err := OpenSnapshot(ctx, sourceDB)
+	if err != nil {
+		this.fail(err)
+		return
+	}
+	defer func() {
+		_ = snapshot.Close()
+	}()
+
+	for _, tableName := range tableNames {
+		sourceRows, targetRows, sourceChecksum, targetChecksum, verifyErr := verifyTable(ctx, snapshot, targetDB, tableName)
+		this.updateTable(tableName, true, func(table *Table) {
+			if verifyErr != nil {
+				table.VerifyStatus = VerifyFailed
+				table.VerifyError = verifyErr.Error()
+				return
+			}
+			table.Verify(sourceRows, targetRows, sourceChecksum, targetChecksum)
+		})
+	}
+
+	this.locker.Lock()
+	this.migration.FinishVerifying(time.Now().Unix())
+	err = this.save()
+	this.locker.Unlock()
+	if err != nil {
+		logs.Println("[DB_MIGRATION]save migration failed: " + err.Error())
+	}
+}
+
+// 校验单个数据表
+func verifyTable(ctx context.Context, source Querier, targetDB *sql.DB, tableName string) (sourceRows int64, targetRows int64, sourceChecksum string, targetChecksum string, err error) {
+	sourceRows, sourceChecksum, err = ChecksumTable(ctx, source, tableName)
+	if err != nil {
+		return
+	}
+	targetRows, targetChecksum, err = ChecksumTable(ctx, targetDB, tableName)
+	return
+}
+
+func (this *Migrator) openDBs(source *DBConfig, target *DBConfig) (sourceDB *sql.DB, targetDB *sql.DB, err error) {
+	sourceDB, err = Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetDB, err = Open(target)
+	if err != nil {
+		_ = sourceDB.Close()
+		return nil, nil, err
+	}
+	return
+}
+
+// 修改数据表状态
+// 复制过程中的进度变化比较频繁，所以只有在 forceSave 为true或者超过保存间隔时才保存
+func (this *Migrator) updateTable(tableName string, forceSave bool, f func(table *Table)) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	var table = this.migration.FindTable(tableName)
+	if table == nil {
+		return
+	}
+	f(table)
+	this.migration.UpdatedAt = time.Now().Unix()
+
+	if !forceSave && time.Since(this.lastSave) < saveInterval {
+		return
+	}
+	err := this.save()
+	if err != nil {
+		logs.Println("[DB_MIGRATION]save migration failed: " + err.Error())
+	}
+}
+
+func (this *Migrator) fail(err error) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	this.migration.Status = StatusFailed
+	this.migration.Error = err.Error()
+	this.migration.UpdatedAt = time.Now().Unix()
+	saveErr := this.save()
+	if saveErr != nil {
+		logs.Println("[DB_MIGRATION]save migration failed: " + saveErr.Error())
+	}
+}
+
+func (this *Migrator) checkCanStart() error {
+	if this.isRunning {
+		return errors.New("迁移正在执行中")
+	}
+	if this.migration != nil && this.migration.Status == StatusSwitched {
+		return errors.New("当前已经切换到新数据库，如果要开始新的迁移，请先清除当前迁移记录")
+	}
+	return nil
+}
+
+// 从文件中读取迁移状态
+func (this *Migrator) load() error {
+	if this.isLoaded {
+		return nil
+	}
+
+	data, err := os.ReadFile(this.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			this.isLoaded = true
+			return nil
+		}
+		return err
+	}
+	var migration = &Migration{}
+	err = json.Unmarshal(data, migration)
+	if err != nil {
+		return err
+	}
+	this.migration = migration
+	this.isLoaded = true
+
+	// 上次执行时管理平台被中断
+	if migration.IsRunning() && !this.isRunning {
+		migration.Status = StatusFailed
+		migration.Error = "迁移被中断，请重新复制或者校验"
+		for _, table := range migration.Tables {
+			if table.Status == TableStatusCopying {
+				table.Status = TableStatusFailed
+				table.Error = "复制被中断"
+			}
+		}
+		return this.save()
+	}
+	return nil
+}
+
+// 保存迁移状态到文件
+func (this *Migrator) save() error {
+	if this.migration == nil {
+		return nil
+	}
+	data, err := json.Marshal(this.migration)
+	if err != nil {
+		return err
+	}
+	this.lastSave = time.Now()
+
+	// 文件中包含数据库密码，所以只允许当前用户读写
+	return os.WriteFile(this.path, data, 0600)
+}
+
+func (this *Migrator) clone() (*Migration, error) {
+	data, err := json.Marshal(this.migration)
+	if err != nil {
+		return nil, err
+	}
+	var migration = &Migration{}
+	err = json.Unmarshal(data, migration)
+	if err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
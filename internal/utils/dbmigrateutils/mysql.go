@@ -0,0 +1,467 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dbmigrateutils
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	stringutil "github.com/iwind/TeaGo/utils/string"
+)
+
+// MinMySQLVersion 支持的最低MySQL版本
+const MinMySQLVersion = "5.7.8"
+
+// 每批插入的最大占位符数量，MySQL限制为65535
+const maxPlaceholders = 60000
+
+// 每批插入的最大行数
+const maxBatchRows = 500
+
+// TargetInfo 目标数据库检查结果
+type TargetInfo struct {
+	Version        string   `json:"version"`
+	ExistingTables []string `json:"existingTables"`
+}
+
+// Open 打开数据库连接
+func Open(config *DBConfig) (*sql.DB, error) {
+	return sql.Open("mysql", config.DSN(true))
+}
+
+// CheckTarget 检查目标数据库是否可用
+// 如果数据库不存在则尝试创建，并检查当前用户是否有建表和修改表结构的权限
+func CheckTarget(ctx context.Context, config *DBConfig) (*TargetInfo, error) {
+	db, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		if !strings.Contains(err.Error(), "Error 1049") {
+			return nil, errors.New("无法连接到数据库：" + err.Error())
+		}
+
+		// 数据库不存在
+		err = createDatabase(ctx, config)
+		if err != nil {
+			return nil, errors.New("尝试创建数据库失败：" + err.Error())
+		}
+		err = db.PingContext(ctx)
+		if err != nil {
+			return nil, errors.New("无法连接到数据库：" + err.Error())
+		}
+	}
+
+	// 检查版本
+	var version string
+	err = db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version)
+	if err != nil {
+		return nil, errors.New("检查数据库版本时出错：" + err.Error())
+	}
+	if stringutil.VersionCompare(version, MinMySQLVersion) < 0 {
+		return nil, errors.New("数据库版本至少在v" + MinMySQLVersion + "以上，目标数据库使用的是v" + version)
+	}
+
+	// 检查权限
+	var testTable = "edgeMigrateTest"
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `"+testTable+"` (`id` int(11) NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	if err != nil {
+		return nil, errors.New("当前连接的用户无法创建新表，请检查CREATE权限设置：" + err.Error())
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE `"+testTable+"` CHANGE `id` `id` int(11) NOT NULL AUTO_INCREMENT")
+	if err != nil {
+		return nil, errors.New("当前连接的用户无法修改表结构，请检查ALTER权限设置：" + err.Error())
+	}
+	_, err = db.ExecContext(ctx, "DROP TABLE `"+testTable+"`")
+	if err != nil {
+		return nil, errors.New("当前连接的用户无法删除表，请检查DROP权限设置：" + err.Error())
+	}
+
+	tables, err := ListTables(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var tableNames = []string{}
+	for _, table := range tables {
+		tableNames = append(tableNames, table.Name)
+	}
+
+	return &TargetInfo{
+		Version:        version,
+		ExistingTables: tableNames,
+	}, nil
+}
+
+// Querier 可以执行查询的数据库对象，比如 *sql.DB 和 *Snapshot
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Snapshot 数据库的一致性快照
+// 在同一个快照中读取的所有数据表都处于同一个时间点，不受之后写入的数据影响
+type Snapshot struct {
+	conn *sql.Conn
+}
+
+// OpenSnapshot 在一个单独的连接中开启一致性快照，使用完后需要调用 Close()
+func OpenSnapshot(ctx context.Context, db *sql.DB) (*Snapshot, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.ExecContext(ctx, "SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT")
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.New("start consistent snapshot failed: " + err.Error())
+	}
+	return &Snapshot{conn: conn}, nil
+}
+
+// QueryContext 在快照中查询
+func (this *Snapshot) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return this.conn.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext 在快照中查询单行
+func (this *Snapshot) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return this.conn.QueryRowContext(ctx, query, args...)
+}
+
+// Close 结束快照
+func (this *Snapshot) Close() error {
+	_, _ = this.conn.ExecContext(context.Background(), "COMMIT")
+	return this.conn.Close()
+}
+
+// ListTables 列出数据库中的所有数据表，不包括视图
+func ListTables(ctx context.Context, db *sql.DB) ([]*Table, error) {
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_NAME, IFNULL(TABLE_ROWS, 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA=DATABASE() AND TABLE_TYPE='BASE TABLE' ORDER BY TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var result = []*Table{}
+	for rows.Next() {
+		var table = &Table{}
+		err = rows.Scan(&table.Name, &table.EstimatedRows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, table)
+	}
+	return result, rows.Err()
+}
+
+// CopyTable 复制数据表结构和数据
+// source 通常为一致性快照，以保证所有数据表都复制自同一个时间点；progress 在每批数据写入后调用，参数为已复制的行数
+func CopyTable(ctx context.Context, source Querier, targetDB *sql.DB, tableName string, dropExisting bool, progress func(copiedRows int64)) error {
+	var quotedName = quoteName(tableName)
+
+	// 表结构
+	var name, createSQL string
+	err := source.QueryRowContext(ctx, "SHOW CREATE TABLE "+quotedName).Scan(&name, &createSQL)
+	if err != nil {
+		return errors.New("read table structure failed: " + err.Error())
+	}
+
+	// 在同一个连接中执行，保证会话变量生效
+	targetConn, err := targetDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = targetConn.Close()
+	}()
+
+	// 保留值为0的自增ID，并且不检查外键
+	_, err = targetConn.ExecContext(ctx, "SET SESSION sql_mode='NO_AUTO_VALUE_ON_ZERO', SESSION foreign_key_checks=0, SESSION unique_checks=0")
+	if err != nil {
+		return err
+	}
+
+	if dropExisting {
+		_, err = targetConn.ExecContext(ctx, "DROP TABLE IF EXISTS "+quotedName)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = targetConn.ExecContext(ctx, createSQL)
+	if err != nil {
+		return errors.New("create table failed: " + err.Error())
+	}
+
+	// 数据
+	rows, err := source.QueryContext(ctx, "SELECT * FROM "+quotedName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	var quotedColumns = []string{}
+	for _, column := range columns {
+		quotedColumns = append(quotedColumns, quoteName(column))
+	}
+
+	// JSON字段需要以字符串形式写入，否则会被当作二进制数据而写入失败
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	var jsonColumns = map[int]bool{}
+	for index, columnType := range columnTypes {
+		if strings.ToUpper(columnType.DatabaseTypeName()) == "JSON" {
+			jsonColumns[index] = true
+		}
+	}
+
+	var batchRows = BatchSize(len(columns))
+	var rowPlaceholder = "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+
+	var copiedRows int64
+	var batchValues = []any{}
+	var countBatchRows = 0
+	var flush = func() error {
+		if countBatchRows == 0 {
+			return nil
+		}
+		var query = "INSERT INTO " + quotedName + " (" + strings.Join(quotedColumns, ",") + ") VALUES " + strings.TrimSuffix(strings.Repeat(rowPlaceholder+",", countBatchRows), ",")
+		_, err := targetConn.ExecContext(ctx, query, batchValues...)
+		if err != nil {
+			return err
+		}
+		copiedRows += int64(countBatchRows)
+		batchValues = []any{}
+		countBatchRows = 0
+		if progress != nil {
+			progress(copiedRows)
+		}
+		return nil
+	}
+
+	for rows.Next() {
+		var values = make([]any, len(columns))
+		var pointers = make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return err
+		}
+		for index := range jsonColumns {
+			data, ok := values[index].([]byte)
+			if ok {
+				values[index] = string(data)
+			}
+		}
+		batchValues = append(batchValues, values...)
+		countBatchRows++
+		if countBatchRows >= batchRows {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// ChecksumTable 统计数据表的行数，并按主键顺序计算所有字段的哈希值
+// 没有主键的表按所有字段排序；结果不依赖MySQL版本和行格式，可以用来对比不同服务器上的数据
+func ChecksumTable(ctx context.Context, db Querier, tableName string) (countRows int64, checksum string, err error) {
+	columns, err := listColumns(ctx, db, tableName)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(columns) == 0 {
+		return 0, "", errors.New("table '" + tableName + "' does not exist")
+	}
+	primaryColumns, err := listPrimaryColumns(ctx, db, tableName)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(primaryColumns) == 0 {
+		primaryColumns = columns
+	}
+
+	var quotedColumns = []string{}
+	for _, column := range columns {
+		quotedColumns = append(quotedColumns, quoteName(column))
+	}
+	var orders = []string{}
+	for _, column := range primaryColumns {
+		orders = append(orders, quoteName(column))
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT "+strings.Join(quotedColumns, ",")+" FROM "+quoteName(tableName)+" ORDER BY "+strings.Join(orders, ","))
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var hasher = NewRowHasher()
+	var values = make([]any, len(columns))
+	var pointers = make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return 0, "", err
+		}
+		hasher.Add(values)
+	}
+	err = rows.Err()
+	if err != nil {
+		return 0, "", err
+	}
+	return hasher.Count(), hasher.Sum(), nil
+}
+
+// RowHasher 按顺序计算多行数据的哈希值
+// 每个字段都带有长度前缀，NULL和空字符串、字段之间的边界都不会混淆
+type RowHasher struct {
+	h     hash.Hash
+	count int64
+}
+
+// NewRowHasher 获取新对象
+func NewRowHasher() *RowHasher {
+	return &RowHasher{
+		h: sha256.New(),
+	}
+}
+
+// Add 添加一行数据
+func (this *RowHasher) Add(values []any) {
+	var lengthBytes = make([]byte, 8)
+	for _, value := range values {
+		var data []byte
+		switch v := value.(type) {
+		case nil:
+			binary.BigEndian.PutUint64(lengthBytes, ^uint64(0))
+			this.h.Write(lengthBytes)
+			continue
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			data = []byte(fmt.Sprint(v))
+		}
+		binary.BigEndian.PutUint64(lengthBytes, uint64(len(data)))
+		this.h.Write(lengthBytes)
+		this.h.Write(data)
+	}
+	this.count++
+}
+
+// Count 已添加的行数
+func (this *RowHasher) Count() int64 {
+	return this.count
+}
+
+// Sum 哈希值
+func (this *RowHasher) Sum() string {
+	return hex.EncodeToString(this.h.Sum(nil))
+}
+
+// BatchSize 根据字段数量计算每批插入的行数
+func BatchSize(countColumns int) int {
+	if countColumns <= 0 {
+		return maxBatchRows
+	}
+	var size = maxPlaceholders / countColumns
+	if size > maxBatchRows {
+		size = maxBatchRows
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// 创建数据库
+func createDatabase(ctx context.Context, config *DBConfig) error {
+	db, err := sql.Open("mysql", config.DSN(false))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	_, err = db.ExecContext(ctx, "CREATE DATABASE "+quoteName(config.Database)+" DEFAULT CHARSET utf8mb4")
+	return err
+}
+
+// 数据表的所有字段，按定义顺序排列
+func listColumns(ctx context.Context, db Querier, tableName string) ([]string, error) {
+	return queryStrings(ctx, db, "SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? ORDER BY ORDINAL_POSITION", tableName)
+}
+
+// 数据表的主键字段
+func listPrimaryColumns(ctx context.Context, db Querier, tableName string) ([]string, error) {
+	return queryStrings(ctx, db, "SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? AND CONSTRAINT_NAME='PRIMARY' ORDER BY ORDINAL_POSITION", tableName)
+}
+
+func queryStrings(ctx context.Context, db Querier, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var result = []string{}
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, rows.Err()
+}
+
+// 对表名和字段名加引号
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsrecordutils
+
+import (
+	"sort"
+	"strings"
+)
+
+// RecordKind 记录来源
+type RecordKind = string
+
+const (
+	RecordKindNode   RecordKind = "node"   // 节点记录
+	RecordKindServer RecordKind = "server" // 网站CNAME记录
+	RecordKindExtra  RecordKind = "extra"  // 不在期望列表中的记录
+)
+
+// RecordStatus 对比结果
+type RecordStatus = string
+
+const (
+	RecordStatusOk      RecordStatus = "ok"      // 正常
+	RecordStatusMissing RecordStatus = "missing" // DNS服务商中缺少此记录
+	RecordStatusStale   RecordStatus = "stale"   // DNS服务商中有记录，但权威服务器返回的结果不一致
+	RecordStatusExtra   RecordStatus = "extra"   // 权威服务器返回了不应该存在的记录
+)
+
+// ExpectedRecord 期望的解析记录
+type ExpectedRecord struct {
+	Kind      RecordKind `json:"kind"`
+	TargetId  int64      `json:"targetId"`  // 节点ID或网站ID
+	IPAddrId  int64      `json:"ipAddrId"`  // 节点IP地址ID
+	Name      string     `json:"name"`      // 完整域名，不以点结尾
+	Type      string     `json:"type"`      // A、AAAA、CNAME
+	Route     string     `json:"route"`     // 线路代号
+	RouteName string     `json:"routeName"` // 线路名称
+	Value     string     `json:"value"`
+
+	// 是否为默认线路，只有默认线路的记录才能通过直接查询权威服务器来核对
+	IsDefaultRoute bool `json:"isDefaultRoute"`
+}
+
+// Key 记录唯一标识
+func (this *ExpectedRecord) Key() string {
+	return strings.Join([]string{this.Kind, this.Name, this.Type, this.Route, NormalizeValue(this.Type, this.Value)}, "|")
+}
+
+// RecordResult 单条记录的对比结果
+type RecordResult struct {
+	Key            string       `json:"key"`
+	Kind           RecordKind   `json:"kind"`
+	TargetId       int64        `json:"targetId"`
+	IPAddrId       int64        `json:"ipAddrId"`
+	Name           string       `json:"name"`
+	Type           string       `json:"type"`
+	Route          string       `json:"route"`
+	RouteName      string       `json:"routeName"`
+	Value          string       `json:"value"`
+	Status         RecordStatus `json:"status"`
+	ResolvedValues []string     `json:"resolvedValues"` // 权威服务器返回的值
+	IsVerified     bool         `json:"isVerified"`     // 是否通过权威服务器核对过
+}
+
+// Resolution 某个域名某种记录类型在权威服务器上的查询结果
+type Resolution struct {
+	Name   string
+	Type   string
+	Values []string
+	Err    error
+}
+
+// ResolutionKey 查询结果索引
+func ResolutionKey(name string, recordType string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "|" + strings.ToUpper(recordType)
+}
+
+// NormalizeValue 规范化记录值，方便比较
+func NormalizeValue(recordType string, value string) string {
+	value = strings.TrimSpace(value)
+	if strings.ToUpper(recordType) == "CNAME" {
+		value = strings.ToLower(strings.TrimSuffix(value, "."))
+	}
+	return value
+}
+
+// Reconcile 对比期望的记录、DNS服务商中的记录和权威服务器查询结果
+// providerExists 为DNS服务商中是否存在对应的记录，以 ExpectedRecord.Key() 为索引
+// resolutions 为权威服务器查询结果，以 ResolutionKey() 为索引
+func Reconcile(expectedRecords []*ExpectedRecord, providerExists map[string]bool, resolutions map[string]*Resolution) []*RecordResult {
+	var results = []*RecordResult{}
+
+	// 每个域名和类型期望的值
+	var expectedValuesMap = map[string]map[string]bool{} // resolution key => { value => true }
+	for _, record := range expectedRecords {
+		var resolutionKey = ResolutionKey(record.Name, record.Type)
+		values, ok := expectedValuesMap[resolutionKey]
+		if !ok {
+			values = map[string]bool{}
+			expectedValuesMap[resolutionKey] = values
+		}
+		values[NormalizeValue(record.Type, record.Value)] = true
+	}
+
+	for _, record := range expectedRecords {
+		var key = record.Key()
+		var result = &RecordResult{
+			Key:            key,
+			Kind:           record.Kind,
+			TargetId:       record.TargetId,
+			IPAddrId:       record.IPAddrId,
+			Name:           record.Name,
+			Type:           record.Type,
+			Route:          record.Route,
+			RouteName:      record.RouteName,
+			Value:          record.Value,
+			Status:         RecordStatusOk,
+			ResolvedValues: []string{},
+		}
+		results = append(results, result)
+
+		var resolution = resolutions[ResolutionKey(record.Name, record.Type)]
+		if resolution != nil && resolution.Err == nil {
+			result.ResolvedValues = resolution.Values
+		}
+
+		if !providerExists[key] {
+			result.Status = RecordStatusMissing
+			continue
+		}
+
+		// 只核对默认线路的记录，其他线路的结果和查询者所在地区有关
+		if !record.IsDefaultRoute || resolution == nil || resolution.Err != nil {
+			continue
+		}
+		result.IsVerified = true
+		if !containsValue(resolution.Values, record.Type, record.Value) {
+			result.Status = RecordStatusStale
+		}
+	}
+
+	// 多余的记录
+	var resolutionKeys = []string{}
+	for resolutionKey := range resolutions {
+		resolutionKeys = append(resolutionKeys, resolutionKey)
+	}
+	sort.Strings(resolutionKeys)
+	for _, resolutionKey := range resolutionKeys {
+		var resolution = resolutions[resolutionKey]
+		if resolution == nil || resolution.Err != nil {
+			continue
+		}
+		var expectedValues = expectedValuesMap[resolutionKey]
+		for _, value := range resolution.Values {
+			if expectedValues[NormalizeValue(resolution.Type, value)] {
+				continue
+			}
+			results = append(results, &RecordResult{
+				Key:            strings.Join([]string{RecordKindExtra, resolution.Name, resolution.Type, "", NormalizeValue(resolution.Type, value)}, "|"),
+				Kind:           RecordKindExtra,
+				Name:           resolution.Name,
+				Type:           resolution.Type,
+				Value:          value,
+				Status:         RecordStatusExtra,
+				ResolvedValues: resolution.Values,
+				IsVerified:     true,
+			})
+		}
+	}
+
+	return results
+}
+
+// CountStatus 统计各个状态的记录数量
+func CountStatus(results []*RecordResult) map[RecordStatus]int {
+	var m = map[RecordStatus]int{
+		RecordStatusOk:      0,
+		RecordStatusMissing: 0,
+		RecordStatusStale:   0,
+		RecordStatusExtra:   0,
+	}
+	for _, result := range results {
+		m[result.Status]++
+	}
+	return m
+}
+
+func containsValue(values []string, recordType string, value string) bool {
+	value = NormalizeValue(recordType, value)
+	for _, v := range values {
+		if NormalizeValue(recordType, v) == value {
+			return true
+		}
+	}
+	return false
+}
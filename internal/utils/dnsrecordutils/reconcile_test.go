@@ -0,0 +1,74 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsrecordutils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dnsrecordutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var records = []*dnsrecordutils.ExpectedRecord{
+		{Kind: dnsrecordutils.RecordKindNode, TargetId: 1, Name: "cdn.example.com", Type: "A", Value: "1.1.1.1", IsDefaultRoute: true},
+		{Kind: dnsrecordutils.RecordKindNode, TargetId: 2, Name: "cdn.example.com", Type: "A", Value: "2.2.2.2", IsDefaultRoute: true},
+		{Kind: dnsrecordutils.RecordKindNode, TargetId: 3, Name: "cdn.example.com", Type: "A", Route: "telecom", Value: "3.3.3.3"},
+		{Kind: dnsrecordutils.RecordKindServer, TargetId: 10, Name: "abc.example.com", Type: "CNAME", Value: "cdn.example.com", IsDefaultRoute: true},
+	}
+	var providerExists = map[string]bool{
+		records[0].Key(): true,
+		records[1].Key(): true,
+		records[3].Key(): true,
+	}
+	var resolutions = map[string]*dnsrecordutils.Resolution{
+		dnsrecordutils.ResolutionKey("cdn.example.com", "A"): {
+			Name:   "cdn.example.com",
+			Type:   "A",
+			Values: []string{"1.1.1.1", "4.4.4.4"},
+		},
+		dnsrecordutils.ResolutionKey("abc.example.com", "CNAME"): {
+			Name:   "abc.example.com",
+			Type:   "CNAME",
+			Values: []string{"CDN.example.com."},
+		},
+	}
+
+	var results = dnsrecordutils.Reconcile(records, providerExists, resolutions)
+	for _, result := range results {
+		t.Log(result.Kind, result.Name, result.Type, result.Route, result.Value, result.Status)
+	}
+	a.IsTrue(len(results) == 5)
+	a.IsTrue(results[0].Status == dnsrecordutils.RecordStatusOk)
+	a.IsTrue(results[0].IsVerified)
+	a.IsTrue(results[1].Status == dnsrecordutils.RecordStatusStale)
+	a.IsTrue(results[2].Status == dnsrecordutils.RecordStatusMissing)
+	a.IsTrue(results[3].Status == dnsrecordutils.RecordStatusOk)
+	a.IsTrue(results[4].Status == dnsrecordutils.RecordStatusExtra)
+	a.IsTrue(results[4].Value == "4.4.4.4")
+
+	var countMap = dnsrecordutils.CountStatus(results)
+	a.IsTrue(countMap[dnsrecordutils.RecordStatusOk] == 2)
+	a.IsTrue(countMap[dnsrecordutils.RecordStatusExtra] == 1)
+}
+
+func TestReconcile_ResolveError(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var records = []*dnsrecordutils.ExpectedRecord{
+		{Kind: dnsrecordutils.RecordKindNode, TargetId: 1, Name: "cdn.example.com", Type: "A", Value: "1.1.1.1", IsDefaultRoute: true},
+	}
+	var results = dnsrecordutils.Reconcile(records, map[string]bool{records[0].Key(): true}, map[string]*dnsrecordutils.Resolution{
+		dnsrecordutils.ResolutionKey("cdn.example.com", "A"): {
+			Name: "cdn.example.com",
+			Type: "A",
+			Err:  errors.New("timeout"),
+		},
+	})
+	a.IsTrue(len(results) == 1)
+	a.IsTrue(results[0].Status == dnsrecordutils.RecordStatusOk)
+	a.IsFalse(results[0].IsVerified)
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dnsrecordutils
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Resolver 直接向域名的权威服务器查询记录，避免受到递归服务器缓存的影响
+type Resolver struct {
+	client  *dns.Client
+	timeout time.Duration
+
+	nameservers []string // host:port
+}
+
+// NewResolver 获取新的查询器
+func NewResolver(timeout time.Duration) *Resolver {
+	return &Resolver{
+		client: &dns.Client{
+			Timeout: timeout,
+		},
+		timeout: timeout,
+	}
+}
+
+// InitNameservers 查找主域名的权威服务器
+func (this *Resolver) InitNameservers(domain string) error {
+	nsList, err := net.LookupNS(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return err
+	}
+	this.nameservers = []string{}
+	for _, ns := range nsList {
+		var host = strings.TrimSuffix(ns.Host, ".")
+		if len(host) == 0 {
+			continue
+		}
+		this.nameservers = append(this.nameservers, net.JoinHostPort(host, "53"))
+	}
+	if len(this.nameservers) == 0 {
+		return errors.New("can not find nameservers for '" + domain + "'")
+	}
+	return nil
+}
+
+// Nameservers 当前使用的权威服务器
+func (this *Resolver) Nameservers() []string {
+	return this.nameservers
+}
+
+// Resolve 查询记录，依次尝试各个权威服务器，直到有一个成功返回
+func (this *Resolver) Resolve(name string, recordType string) *Resolution {
+	var resolution = &Resolution{
+		Name:   strings.TrimSuffix(name, "."),
+		Type:   strings.ToUpper(recordType),
+		Values: []string{},
+	}
+
+	qType, ok := dns.StringToType[resolution.Type]
+	if !ok {
+		resolution.Err = errors.New("unsupported record type '" + recordType + "'")
+		return resolution
+	}
+	if len(this.nameservers) == 0 {
+		resolution.Err = errors.New("nameservers not initialized")
+		return resolution
+	}
+
+	var m = new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(resolution.Name), qType)
+	m.RecursionDesired = false
+
+	var lastErr error
+	for _, nameserver := range this.nameservers {
+		r, _, err := this.client.Exchange(m, nameserver)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+			lastErr = errors.New("nameserver '" + nameserver + "' returned '" + dns.RcodeToString[r.Rcode] + "'")
+			continue
+		}
+
+		for _, answer := range r.Answer {
+			if answer.Header().Rrtype != qType {
+				continue
+			}
+			switch rr := answer.(type) {
+			case *dns.A:
+				resolution.Values = append(resolution.Values, rr.A.String())
+			case *dns.AAAA:
+				resolution.Values = append(resolution.Values, rr.AAAA.String())
+			case *dns.CNAME:
+				resolution.Values = append(resolution.Values, strings.TrimSuffix(rr.Target, "."))
+			}
+		}
+		return resolution
+	}
+
+	resolution.Err = lastErr
+	return resolution
+}
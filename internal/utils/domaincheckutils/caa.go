@@ -0,0 +1,66 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package domaincheckutils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CAARecord CAA记录
+type CAARecord struct {
+	Flag  uint8
+	Tag   string
+	Value string
+}
+
+func (this *CAARecord) String() string {
+	return strconv.Itoa(int(this.Flag)) + " " + this.Tag + " \"" + this.Value + "\""
+}
+
+// Issuer 从记录值中读取CA标识，去除参数部分
+func (this *CAARecord) Issuer() string {
+	var value = this.Value
+	var index = strings.Index(value, ";")
+	if index >= 0 {
+		value = value[:index]
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// CAABlocksIssuers 判断CAA记录是否会阻止所有给定的CA签发证书
+// 参考 RFC 8659：泛域名优先使用issuewild，没有issuewild时使用issue；没有相关记录时表示不限制
+func CAABlocksIssuers(records []*CAARecord, isWildcard bool, issuers []string) bool {
+	var issueRecords = []*CAARecord{}
+	var issueWildRecords = []*CAARecord{}
+	for _, record := range records {
+		switch strings.ToLower(record.Tag) {
+		case "issue":
+			issueRecords = append(issueRecords, record)
+		case "issuewild":
+			issueWildRecords = append(issueWildRecords, record)
+		}
+	}
+
+	var effectiveRecords = issueRecords
+	if isWildcard && len(issueWildRecords) > 0 {
+		effectiveRecords = issueWildRecords
+	}
+	if len(effectiveRecords) == 0 {
+		return false
+	}
+
+	for _, record := range effectiveRecords {
+		var issuer = record.Issuer()
+		if len(issuer) == 0 {
+			// 空值表示不允许任何CA
+			continue
+		}
+		for _, allowedIssuer := range issuers {
+			if issuer == strings.ToLower(allowedIssuer) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package domaincheckutils
+
+import (
+	"strings"
+)
+
+// DefaultACMEIssuers 系统申请免费证书时使用的CA标识
+var DefaultACMEIssuers = []string{"letsencrypt.org", "zerossl.com", "buypass.com", "ssl.com", "pki.goog"}
+
+// CheckResult 单个域名的检查结果
+type CheckResult struct {
+	ServerName string `json:"serverName"`
+
+	ExpectedCNAME string `json:"expectedCNAME"`
+	CNAME         string `json:"cname"`
+	CNAMEOk       bool   `json:"cnameOk"`
+
+	IPs             []string `json:"ips"`
+	ResolvesToNodes bool     `json:"resolvesToNodes"`
+
+	CAARecords    []string `json:"caaRecords"`
+	CAABlocksACME bool     `json:"caaBlocksACME"`
+
+	Errors []string `json:"errors"`
+	IsOk   bool     `json:"isOk"`
+}
+
+// Checker 域名接入检查器
+type Checker struct {
+	ExpectedCNAME string   // 期望的CNAME，即集群DNS名称
+	NodeIPs       []string // 集群节点IP
+	ACMEIssuers   []string // 允许的CA标识
+
+	// 查询函数，为nil时跳过对应的检查
+	// CNAME和IP的查询由调用者设置，和其他地方一样使用utils中的查询函数
+	LookupCNAME func(domain string) (string, error)
+	LookupIPs   func(domain string) ([]string, error)
+	LookupCAA   func(domain string) ([]*CAARecord, error)
+}
+
+// NewChecker 获取新的检查器
+func NewChecker(expectedCNAME string, nodeIPs []string) *Checker {
+	return &Checker{
+		ExpectedCNAME: expectedCNAME,
+		NodeIPs:       nodeIPs,
+		ACMEIssuers:   DefaultACMEIssuers,
+		LookupCAA:     LookupCAA,
+	}
+}
+
+// Check 检查单个域名
+func (this *Checker) Check(serverName string) *CheckResult {
+	var result = &CheckResult{
+		ServerName:    serverName,
+		ExpectedCNAME: strings.TrimSuffix(this.ExpectedCNAME, "."),
+		IPs:           []string{},
+		CAARecords:    []string{},
+		Errors:        []string{},
+	}
+
+	// 泛域名使用一个随机的子域名检查解析
+	var lookupName = serverName
+	var isWildcard = strings.HasPrefix(serverName, "*.")
+	if isWildcard {
+		lookupName = "goedge-check" + strings.TrimPrefix(serverName, "*")
+	}
+
+	// CNAME
+	if len(result.ExpectedCNAME) > 0 && this.LookupCNAME != nil {
+		cname, err := this.LookupCNAME(lookupName)
+		if err != nil {
+			result.Errors = append(result.Errors, "查询CNAME失败："+err.Error())
+		} else {
+			result.CNAME = strings.TrimSuffix(cname, ".")
+			if len(result.CNAME) == 0 || strings.EqualFold(result.CNAME, lookupName) {
+				result.CNAME = ""
+				result.Errors = append(result.Errors, "没有设置CNAME记录，请设置为"+result.ExpectedCNAME)
+			} else if !strings.EqualFold(result.CNAME, result.ExpectedCNAME) {
+				result.Errors = append(result.Errors, "CNAME记录为"+result.CNAME+"，应该设置为"+result.ExpectedCNAME)
+			} else {
+				result.CNAMEOk = true
+			}
+		}
+	}
+
+	// IP
+	if this.LookupIPs != nil {
+		ips, err := this.LookupIPs(lookupName)
+		if err != nil {
+			result.Errors = append(result.Errors, "查询IP失败："+err.Error())
+		} else {
+			result.IPs = ips
+			for _, ip := range ips {
+				if containsString(this.NodeIPs, ip) {
+					result.ResolvesToNodes = true
+					break
+				}
+			}
+			if !result.ResolvesToNodes {
+				if len(ips) == 0 {
+					result.Errors = append(result.Errors, "域名没有解析到任何IP")
+				} else {
+					result.Errors = append(result.Errors, "域名没有解析到集群节点IP")
+				}
+			}
+		}
+	}
+
+	// CAA
+	if this.LookupCAA != nil {
+		records, err := this.LookupCAA(strings.TrimPrefix(serverName, "*."))
+		if err != nil {
+			result.Errors = append(result.Errors, "查询CAA记录失败："+err.Error())
+		} else {
+			for _, record := range records {
+				result.CAARecords = append(result.CAARecords, record.String())
+			}
+			if CAABlocksIssuers(records, isWildcard, this.ACMEIssuers) {
+				result.CAABlocksACME = true
+				result.Errors = append(result.Errors, "CAA记录不允许系统使用的CA签发证书，将无法自动申请免费证书")
+			}
+		}
+	}
+
+	result.IsOk = len(result.Errors) == 0
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
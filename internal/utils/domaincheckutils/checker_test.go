@@ -0,0 +1,90 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package domaincheckutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/domaincheckutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestCAABlocksIssuers(t *testing.T) {
+	var a = assert.NewAssertion(t)
+	var issuers = []string{"letsencrypt.org"}
+
+	a.IsFalse(domaincheckutils.CAABlocksIssuers(nil, false, issuers))
+	a.IsFalse(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "iodef", Value: "mailto:admin@example.com"},
+	}, false, issuers))
+	a.IsFalse(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "issue", Value: "digicert.com"},
+		{Tag: "issue", Value: "LetsEncrypt.org; validationmethods=dns-01"},
+	}, false, issuers))
+	a.IsTrue(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "issue", Value: "digicert.com"},
+	}, false, issuers))
+	a.IsTrue(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "issue", Value: ";"},
+	}, false, issuers))
+
+	// 泛域名
+	a.IsTrue(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "issue", Value: "letsencrypt.org"},
+		{Tag: "issuewild", Value: "digicert.com"},
+	}, true, issuers))
+	a.IsFalse(domaincheckutils.CAABlocksIssuers([]*domaincheckutils.CAARecord{
+		{Tag: "issue", Value: "letsencrypt.org"},
+		{Tag: "issuewild", Value: "digicert.com"},
+	}, false, issuers))
+}
+
+func TestChecker_Check(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var checker = domaincheckutils.NewChecker("abc.cdn.example.com.", []string{"1.1.1.1", "2.2.2.2"})
+	checker.LookupCNAME = func(domain string) (string, error) {
+		switch domain {
+		case "www.example.org", "goedge-check.example.org":
+			return "abc.cdn.example.com.", nil
+		case "bad.example.org":
+			return "other.example.net.", nil
+		}
+		return "", nil
+	}
+	checker.LookupIPs = func(domain string) ([]string, error) {
+		switch domain {
+		case "www.example.org", "goedge-check.example.org":
+			return []string{"2.2.2.2"}, nil
+		}
+		return []string{"3.3.3.3"}, nil
+	}
+	checker.LookupCAA = func(domain string) ([]*domaincheckutils.CAARecord, error) {
+		if domain == "example.org" {
+			return []*domaincheckutils.CAARecord{{Tag: "issuewild", Value: "digicert.com"}}, nil
+		}
+		return nil, nil
+	}
+
+	{
+		var result = checker.Check("www.example.org")
+		a.IsTrue(result.IsOk)
+		a.IsTrue(result.CNAMEOk)
+		a.IsTrue(result.ResolvesToNodes)
+	}
+	{
+		var result = checker.Check("bad.example.org")
+		t.Log(result.Errors)
+		a.IsFalse(result.IsOk)
+		a.IsFalse(result.CNAMEOk)
+		a.IsFalse(result.ResolvesToNodes)
+		a.IsTrue(len(result.Errors) == 2)
+	}
+	{
+		var result = checker.Check("*.example.org")
+		t.Log(result.Errors)
+		a.IsFalse(result.IsOk)
+		a.IsTrue(result.CNAMEOk)
+		a.IsTrue(result.CAABlocksACME)
+	}
+}
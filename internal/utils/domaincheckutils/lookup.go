@@ -0,0 +1,78 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package domaincheckutils
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+var lookupTimeout = 5 * time.Second
+
+// 公共DNS服务器，在读取不到系统设置时使用
+var publicDNSServers = []string{"8.8.8.8:53", "1.1.1.1:53"}
+
+// LookupCAA 查询CAA记录，当前域名没有记录时逐级向上查找
+func LookupCAA(domain string) ([]*CAARecord, error) {
+	var labels = dns.SplitDomainName(strings.TrimSuffix(domain, "."))
+	for i := 0; i < len(labels)-1; i++ {
+		var name = strings.Join(labels[i:], ".")
+		r, err := exchange(name, dns.TypeCAA)
+		if err != nil {
+			return nil, err
+		}
+		var records = []*CAARecord{}
+		for _, answer := range r.Answer {
+			caa, ok := answer.(*dns.CAA)
+			if ok {
+				records = append(records, &CAARecord{
+					Flag:  caa.Flag,
+					Tag:   caa.Tag,
+					Value: caa.Value,
+				})
+			}
+		}
+		if len(records) > 0 {
+			return records, nil
+		}
+	}
+	return []*CAARecord{}, nil
+}
+
+func exchange(domain string, qType uint16) (*dns.Msg, error) {
+	var m = new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), qType)
+	m.RecursionDesired = true
+
+	var servers = publicDNSServers
+	config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err == nil && len(config.Servers) > 0 {
+		servers = []string{}
+		for _, server := range config.Servers {
+			servers = append(servers, net.JoinHostPort(server, config.Port))
+		}
+	}
+
+	var client = &dns.Client{Timeout: lookupTimeout}
+	var lastErr error
+	for _, server := range servers {
+		r, _, err := client.Exchange(m, server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+			lastErr = errors.New("query '" + domain + "' failed: " + dns.RcodeToString[r.Rcode])
+			continue
+		}
+		return r, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no available dns servers")
+	}
+	return nil, lastErr
+}
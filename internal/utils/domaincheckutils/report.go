@@ -0,0 +1,64 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package domaincheckutils
+
+import (
+	"sync"
+)
+
+// ReportItem 定时检查中单个域名的结果
+// 读取网站信息失败时Result为nil，Error为失败原因
+type ReportItem struct {
+	ServerId    int64        `json:"serverId"`
+	ServerName  string       `json:"serverName"`
+	ClusterId   int64        `json:"clusterId"`
+	ClusterName string       `json:"clusterName"`
+	Result      *CheckResult `json:"result"`
+	Error       string       `json:"error"`
+}
+
+// Report 定时检查报告
+type Report struct {
+	CheckedAt    int64         `json:"checkedAt"`
+	IsChecking   bool          `json:"isChecking"`
+	CountDomains int           `json:"countDomains"`
+	Items        []*ReportItem `json:"items"` // 只保存有问题的域名
+}
+
+var sharedReport = &Report{
+	Items: []*ReportItem{},
+}
+var sharedReportLocker = &sync.RWMutex{}
+
+// SharedReport 读取最近一次的检查报告
+func SharedReport() *Report {
+	sharedReportLocker.RLock()
+	defer sharedReportLocker.RUnlock()
+
+	var report = *sharedReport
+	report.Items = append([]*ReportItem{}, sharedReport.Items...)
+	return &report
+}
+
+// SetChecking 设置是否正在检查
+// 返回false表示已经有检查正在进行
+func SetChecking(isChecking bool) bool {
+	sharedReportLocker.Lock()
+	defer sharedReportLocker.Unlock()
+
+	if isChecking && sharedReport.IsChecking {
+		return false
+	}
+	sharedReport.IsChecking = isChecking
+	return true
+}
+
+// UpdateReport 更新检查报告
+func UpdateReport(checkedAt int64, countDomains int, items []*ReportItem) {
+	sharedReportLocker.Lock()
+	defer sharedReportLocker.Unlock()
+
+	sharedReport.CheckedAt = checkedAt
+	sharedReport.CountDomains = countDomains
+	sharedReport.Items = items
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// SharedGroupCache 共享的分组缓存
+var SharedGroupCache = NewGroupCache()
+
+// Identity 认证通过的LDAP用户
+type Identity struct {
+	DN       string
+	Username string
+	Fullname string
+	Groups   []string // 分组DN
+}
+
+// Authenticate 使用用户名和密码认证
+// 用户不存在或者密码错误时返回 ErrInvalidCredentials，其他错误表示LDAP服务不可用或者配置错误
+func Authenticate(config *Config, cache *GroupCache, username string, password string) (*Identity, error) {
+	if len(username) == 0 || len(password) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := Dial(config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// 使用查询账号登录
+	err = bindServiceAccount(conn, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查找用户
+	var filter = strings.ReplaceAll(config.UserFilter, "{username}", EscapeFilter(username))
+	var attributes = []string{config.UsernameAttribute, config.FullnameAttribute, config.GroupAttribute}
+	entries, err := conn.Search(config.BaseDN, ScopeWholeSubtree, filter, attributes, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if len(entries) > 1 {
+		return nil, errors.New("found multiple ldap entries for user '" + username + "'")
+	}
+	var entry = entries[0]
+
+	// 校验密码
+	err = conn.Bind(entry.DN, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var identity = &Identity{
+		DN:       entry.DN,
+		Username: username,
+		Fullname: entry.Attr(config.FullnameAttribute),
+	}
+	if len(config.UsernameAttribute) > 0 && len(entry.Attr(config.UsernameAttribute)) > 0 {
+		identity.Username = entry.Attr(config.UsernameAttribute)
+	}
+
+	// 分组
+	var now = time.Now()
+	if cache != nil {
+		groups, ok := cache.Get(entry.DN, now)
+		if ok {
+			identity.Groups = groups
+			return identity, nil
+		}
+	}
+
+	var groups = []string{}
+	if len(config.GroupAttribute) > 0 {
+		groups = append(groups, entry.Attributes[strings.ToLower(config.GroupAttribute)]...)
+	}
+	if len(config.GroupBaseDN) > 0 && len(config.GroupFilter) > 0 {
+		// 分组查询使用查询账号
+		err = bindServiceAccount(conn, config)
+		if err != nil {
+			return nil, err
+		}
+		var groupFilter = strings.NewReplacer("{dn}", EscapeFilter(entry.DN), "{username}", EscapeFilter(username)).Replace(config.GroupFilter)
+		groupEntries, err := conn.Search(config.GroupBaseDN, ScopeWholeSubtree, groupFilter, []string{"cn"}, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, groupEntry := range groupEntries {
+			if !containsFold(groups, groupEntry.DN) {
+				groups = append(groups, groupEntry.DN)
+			}
+		}
+	}
+	identity.Groups = groups
+	if cache != nil {
+		cache.Put(entry.DN, groups, config.CacheTTLDuration(), now)
+	}
+
+	return identity, nil
+}
+
+func bindServiceAccount(conn *Conn, config *Config) error {
+	if len(config.BindDN) == 0 {
+		return nil
+	}
+	err := conn.Bind(config.BindDN, config.BindPassword)
+	if err != nil {
+		if err == ErrInvalidCredentials {
+			return errors.New("invalid bind dn or password")
+		}
+		return err
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
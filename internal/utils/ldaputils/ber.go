@@ -0,0 +1,232 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// BER编码中的类别
+const (
+	berClassUniversal   byte = 0x00
+	berClassApplication byte = 0x40
+	berClassContext     byte = 0x80
+)
+
+// 通用类型标签
+const (
+	berTagBoolean     = 0x01
+	berTagInteger     = 0x02
+	berTagOctetString = 0x04
+	berTagEnumerated  = 0x0a
+	berTagSequence    = 0x10
+	berTagSet         = 0x11
+)
+
+// 单个数据包最大长度
+const berMaxLength = 16 << 20
+
+// BER编码的数据包，只支持LDAP中用到的定长编码和小于31的标签
+type berPacket struct {
+	Class       byte
+	Constructed bool
+	Tag         int
+	Value       []byte       // 基本类型的值
+	Children    []*berPacket // 构造类型的子元素
+}
+
+func newBERConstructed(class byte, tag int, children ...*berPacket) *berPacket {
+	return &berPacket{
+		Class:       class,
+		Constructed: true,
+		Tag:         tag,
+		Children:    children,
+	}
+}
+
+func newBERPrimitive(class byte, tag int, value []byte) *berPacket {
+	return &berPacket{
+		Class: class,
+		Tag:   tag,
+		Value: value,
+	}
+}
+
+func newBERSequence(children ...*berPacket) *berPacket {
+	return newBERConstructed(berClassUniversal, berTagSequence, children...)
+}
+
+func newBERString(s string) *berPacket {
+	return newBERPrimitive(berClassUniversal, berTagOctetString, []byte(s))
+}
+
+func newBERInteger(tag int, i int64) *berPacket {
+	// 最小的补码表示
+	var b = []byte{}
+	for {
+		b = append([]byte{byte(i)}, b...)
+		if (i >= -128 && i < 128) || len(b) >= 8 {
+			break
+		}
+		i >>= 8
+	}
+	return newBERPrimitive(berClassUniversal, tag, b)
+}
+
+func newBERBoolean(b bool) *berPacket {
+	if b {
+		return newBERPrimitive(berClassUniversal, berTagBoolean, []byte{0xff})
+	}
+	return newBERPrimitive(berClassUniversal, berTagBoolean, []byte{0x00})
+}
+
+// Bytes 编码
+func (this *berPacket) Bytes() []byte {
+	var value = this.Value
+	if this.Constructed {
+		value = []byte{}
+		for _, child := range this.Children {
+			value = append(value, child.Bytes()...)
+		}
+	}
+
+	var identifier = this.Class | byte(this.Tag&0x1f)
+	if this.Constructed {
+		identifier |= 0x20
+	}
+	var result = []byte{identifier}
+
+	var length = len(value)
+	if length < 0x80 {
+		result = append(result, byte(length))
+	} else {
+		var lengthBytes = []byte{}
+		for length > 0 {
+			lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+			length >>= 8
+		}
+		result = append(result, 0x80|byte(len(lengthBytes)))
+		result = append(result, lengthBytes...)
+	}
+	return append(result, value...)
+}
+
+// Int 读取整数值
+func (this *berPacket) Int() int64 {
+	var i int64
+	for index, b := range this.Value {
+		if index == 0 && b&0x80 != 0 {
+			i = -1
+		}
+		i = i<<8 | int64(b)
+	}
+	return i
+}
+
+// String 读取字符串值
+func (this *berPacket) String() string {
+	return string(this.Value)
+}
+
+// 获取第N个子元素
+func (this *berPacket) child(index int) *berPacket {
+	if index < 0 || index >= len(this.Children) {
+		return nil
+	}
+	return this.Children[index]
+}
+
+// 从数据流中读取一个数据包
+func readBERPacket(reader *bufio.Reader) (*berPacket, error) {
+	var header = []byte{}
+	for len(header) < 2 || (header[1]&0x80 != 0 && len(header) < 2+int(header[1]&0x7f)) {
+		b, err := reader.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(header) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		header = append(header, b)
+		if len(header) == 2 && header[1]&0x80 != 0 && header[1]&0x7f > 4 {
+			return nil, errors.New("ber: length is too long")
+		}
+	}
+	identifier, length, _, err := parseBERHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var value = make([]byte, length)
+	_, err = io.ReadFull(reader, value)
+	if err != nil {
+		return nil, err
+	}
+	return parseBERPacket(identifier, value)
+}
+
+// 解析标识和长度，返回头部长度
+func parseBERHeader(data []byte) (identifier byte, length int, headerLength int, err error) {
+	if len(data) < 2 {
+		return 0, 0, 0, errors.New("ber: truncated packet")
+	}
+	identifier = data[0]
+	if identifier&0x1f == 0x1f {
+		return 0, 0, 0, errors.New("ber: multi-byte tags are not supported")
+	}
+	var lengthByte = data[1]
+	headerLength = 2
+	length = int(lengthByte)
+	if lengthByte&0x80 != 0 {
+		var count = int(lengthByte & 0x7f)
+		if count == 0 {
+			return 0, 0, 0, errors.New("ber: indefinite length is not supported")
+		}
+		if count > 4 {
+			return 0, 0, 0, errors.New("ber: length is too long")
+		}
+		if len(data) < 2+count {
+			return 0, 0, 0, errors.New("ber: truncated packet")
+		}
+		length = 0
+		for _, b := range data[2 : 2+count] {
+			length = length<<8 | int(b)
+		}
+		headerLength += count
+	}
+	if length < 0 || length > berMaxLength {
+		return 0, 0, 0, errors.New("ber: packet is too large")
+	}
+	return
+}
+
+func parseBERPacket(identifier byte, value []byte) (*berPacket, error) {
+	var packet = &berPacket{
+		Class:       identifier & 0xc0,
+		Constructed: identifier&0x20 != 0,
+		Tag:         int(identifier & 0x1f),
+	}
+	if !packet.Constructed {
+		packet.Value = value
+		return packet, nil
+	}
+
+	for len(value) > 0 {
+		childIdentifier, length, headerLength, err := parseBERHeader(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(value) < headerLength+length {
+			return nil, errors.New("ber: truncated packet")
+		}
+		child, err := parseBERPacket(childIdentifier, value[headerLength:headerLength+length])
+		if err != nil {
+			return nil, err
+		}
+		packet.Children = append(packet.Children, child)
+		value = value[headerLength+length:]
+	}
+	return packet, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type groupCacheItem struct {
+	groups    []string
+	expiresAt time.Time
+}
+
+// GroupCache 用户分组缓存，减少登录时对分组的查询
+type GroupCache struct {
+	items  map[string]*groupCacheItem // lower(dn) => item
+	locker sync.Mutex
+}
+
+// NewGroupCache 获取新对象
+func NewGroupCache() *GroupCache {
+	return &GroupCache{
+		items: map[string]*groupCacheItem{},
+	}
+}
+
+// Get 读取用户分组
+func (this *GroupCache) Get(dn string, now time.Time) (groups []string, ok bool) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	item, ok := this.items[strings.ToLower(dn)]
+	if !ok || !item.expiresAt.After(now) {
+		return nil, false
+	}
+	return item.groups, true
+}
+
+// Put 写入用户分组
+func (this *GroupCache) Put(dn string, groups []string, ttl time.Duration, now time.Time) {
+	if ttl <= 0 {
+		return
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	for key, item := range this.items {
+		if !item.expiresAt.After(now) {
+			delete(this.items, key)
+		}
+	}
+	this.items[strings.ToLower(dn)] = &groupCacheItem{
+		groups:    groups,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// Clear 清空缓存
+func (this *GroupCache) Clear() {
+	this.locker.Lock()
+	this.items = map[string]*groupCacheItem{}
+	this.locker.Unlock()
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 默认值
+const (
+	DefaultUserFilter        = "(&(objectClass=person)(uid={username}))"
+	DefaultFullnameAttribute = "cn"
+	DefaultGroupAttribute    = "memberOf"
+	DefaultCacheTTL          = 300
+	DefaultTimeout           = 10
+)
+
+// Config LDAP认证配置
+type Config struct {
+	IsOn               bool   `json:"isOn"`
+	URL                string `json:"url"`                // ldap://host:389 或 ldaps://host:636
+	StartTLS           bool   `json:"startTLS"`           // 使用ldap://时是否启用StartTLS
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"` // 不校验服务器证书
+	CACertificate      string `json:"caCertificate"`      // 自定义CA证书，PEM格式
+	Timeout            int    `json:"timeout"`            // 超时时间，单位秒
+
+	BindDN       string `json:"bindDN"` // 用于查询的账号，为空时使用匿名查询
+	BindPassword string `json:"bindPassword"`
+
+	BaseDN            string `json:"baseDN"`
+	UserFilter        string `json:"userFilter"`        // 查询用户的过滤器，{username}会被替换为用户名
+	UsernameAttribute string `json:"usernameAttribute"` // 为空时使用登录时输入的用户名
+	FullnameAttribute string `json:"fullnameAttribute"`
+
+	GroupAttribute string `json:"groupAttribute"` // 用户条目中的分组属性
+	GroupBaseDN    string `json:"groupBaseDN"`    // 分组查询的基础DN，为空时不单独查询分组
+	GroupFilter    string `json:"groupFilter"`    // 查询分组的过滤器，{dn}和{username}会被替换
+
+	GroupMappings []*GroupMapping `json:"groupMappings"`
+	CacheTTL      int             `json:"cacheTTL"` // 分组缓存时间，单位秒
+	AutoCreate    bool            `json:"autoCreate"`
+	LinkExisting  bool            `json:"linkExisting"` // 首次登录时关联同名的非超级管理员
+}
+
+// GroupMapping 分组对应的模块权限
+type GroupMapping struct {
+	Group       string   `json:"group"` // 分组DN或者CN
+	ModuleCodes []string `json:"moduleCodes"`
+}
+
+// NewConfig 获取新对象
+func NewConfig() *Config {
+	return &Config{
+		StartTLS:          true,
+		Timeout:           DefaultTimeout,
+		UserFilter:        DefaultUserFilter,
+		FullnameAttribute: DefaultFullnameAttribute,
+		GroupAttribute:    DefaultGroupAttribute,
+		GroupFilter:       "(&(objectClass=groupOfNames)(member={dn}))",
+		GroupMappings:     []*GroupMapping{},
+		CacheTTL:          DefaultCacheTTL,
+	}
+}
+
+// Clone 复制对象
+func (this *Config) Clone() *Config {
+	var config = *this
+	config.GroupMappings = []*GroupMapping{}
+	for _, mapping := range this.GroupMappings {
+		var newMapping = *mapping
+		newMapping.ModuleCodes = append([]string{}, mapping.ModuleCodes...)
+		config.GroupMappings = append(config.GroupMappings, &newMapping)
+	}
+	return &config
+}
+
+// Validate 校验配置
+func (this *Config) Validate() error {
+	if !this.IsOn {
+		return nil
+	}
+	u, err := url.Parse(this.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || len(u.Hostname()) == 0 {
+		return errors.New("invalid ldap url '" + this.URL + "'")
+	}
+	if len(this.BaseDN) == 0 {
+		return errors.New("base dn should not be empty")
+	}
+	if !strings.Contains(this.UserFilter, "{username}") {
+		return errors.New("user filter should contain '{username}'")
+	}
+	_, err = ParseFilter(strings.ReplaceAll(this.UserFilter, "{username}", "test"))
+	if err != nil {
+		return errors.New("invalid user filter: " + err.Error())
+	}
+	if len(this.GroupBaseDN) > 0 {
+		_, err = ParseFilter(strings.NewReplacer("{dn}", "test", "{username}", "test").Replace(this.GroupFilter))
+		if err != nil {
+			return errors.New("invalid group filter: " + err.Error())
+		}
+	}
+	if len(this.CACertificate) > 0 {
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(this.CACertificate)) {
+			return errors.New("invalid ca certificate")
+		}
+	}
+	for _, mapping := range this.GroupMappings {
+		if len(mapping.Group) == 0 {
+			return errors.New("group name should not be empty")
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration 超时时间
+func (this *Config) TimeoutDuration() time.Duration {
+	if this.Timeout <= 0 {
+		return DefaultTimeout * time.Second
+	}
+	return time.Duration(this.Timeout) * time.Second
+}
+
+// CacheTTLDuration 分组缓存时间
+func (this *Config) CacheTTLDuration() time.Duration {
+	if this.CacheTTL < 0 {
+		return 0
+	}
+	return time.Duration(this.CacheTTL) * time.Second
+}
+
+// MapModules 根据分组计算模块权限，matched 表示是否有分组匹配
+// 分组可以使用完整的DN或者第一个RDN的值（通常为CN）匹配，不区分大小写
+func (this *Config) MapModules(groups []string) (moduleCodes []string, matched bool) {
+	moduleCodes = []string{}
+	for _, mapping := range this.GroupMappings {
+		for _, group := range groups {
+			if !strings.EqualFold(mapping.Group, group) && !strings.EqualFold(mapping.Group, GroupName(group)) {
+				continue
+			}
+			matched = true
+			for _, code := range mapping.ModuleCodes {
+				if !containsString(moduleCodes, code) {
+					moduleCodes = append(moduleCodes, code)
+				}
+			}
+		}
+	}
+	return
+}
+
+// GroupName 从分组DN中读取第一个RDN的值，比如 cn=ops,ou=groups,dc=example,dc=com 中的 ops
+func GroupName(dn string) string {
+	var first = dn
+	var index = strings.IndexByte(dn, ',')
+	if index >= 0 {
+		first = dn[:index]
+	}
+	index = strings.IndexByte(first, '=')
+	if index < 0 {
+		return strings.TrimSpace(dn)
+	}
+	return strings.TrimSpace(first[index+1:])
+}
+
+func (this *Config) tlsConfig(serverName string) (*tls.Config, error) {
+	var tlsConfig = &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: this.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if len(this.CACertificate) > 0 {
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(this.CACertificate)) {
+			return nil, errors.New("invalid ca certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LDAP操作标签
+const (
+	opBindRequest         = 0
+	opBindResponse        = 1
+	opUnbindRequest       = 2
+	opSearchRequest       = 3
+	opSearchResultEntry   = 4
+	opSearchResultDone    = 5
+	opSearchResultRef     = 19
+	opExtendedRequest     = 23
+	opExtendedResponse    = 24
+	resultSuccess         = 0
+	resultInvalidCredents = 49
+)
+
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// 查询范围
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// ErrInvalidCredentials 用户名或密码错误
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ResultError LDAP服务器返回的错误
+type ResultError struct {
+	Code    int64
+	Message string
+}
+
+func (this *ResultError) Error() string {
+	return fmt.Sprintf("ldap result code %d: %s", this.Code, this.Message)
+}
+
+// Entry 查询结果中的一个条目
+type Entry struct {
+	DN         string
+	Attributes map[string][]string // 属性名均为小写
+}
+
+// Attr 读取属性的第一个值
+func (this *Entry) Attr(name string) string {
+	var values = this.Attributes[strings.ToLower(name)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Conn LDAP连接，同一时间只能执行一个操作
+type Conn struct {
+	rawConn   net.Conn
+	reader    *bufio.Reader
+	messageId int64
+	timeout   time.Duration
+}
+
+// Dial 连接LDAP服务器
+func Dial(config *Config) (*Conn, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, errors.New("invalid ldap url '" + config.URL + "'")
+	}
+	var host = u.Hostname()
+	var port = u.Port()
+	var useTLS bool
+	switch u.Scheme {
+	case "ldap":
+		if len(port) == 0 {
+			port = "389"
+		}
+	case "ldaps":
+		useTLS = true
+		if len(port) == 0 {
+			port = "636"
+		}
+	default:
+		return nil, errors.New("invalid ldap url scheme '" + u.Scheme + "'")
+	}
+
+	tlsConfig, err := config.tlsConfig(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeout = config.TimeoutDuration()
+	var dialer = &net.Dialer{Timeout: timeout}
+	var rawConn net.Conn
+	if useTLS {
+		rawConn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), tlsConfig)
+	} else {
+		rawConn, err = dialer.Dial("tcp", net.JoinHostPort(host, port))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conn = &Conn{
+		rawConn: rawConn,
+		reader:  bufio.NewReader(rawConn),
+		timeout: timeout,
+	}
+
+	if !useTLS && config.StartTLS {
+		err = conn.startTLS(tlsConfig)
+		if err != nil {
+			_ = rawConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// Bind 使用DN和密码认证
+// 空密码在LDAP中表示匿名认证，所以这里会直接返回 ErrInvalidCredentials
+func (this *Conn) Bind(dn string, password string) error {
+	if len(password) == 0 {
+		return ErrInvalidCredentials
+	}
+	var request = newBERConstructed(berClassApplication, opBindRequest,
+		newBERInteger(berTagInteger, 3),
+		newBERString(dn),
+		newBERPrimitive(berClassContext, 0, []byte(password)),
+	)
+	response, err := this.call(request, opBindResponse)
+	if err != nil {
+		return err
+	}
+	err = checkResult(response)
+	if err != nil {
+		resultErr, ok := err.(*ResultError)
+		if ok && resultErr.Code == resultInvalidCredents {
+			return ErrInvalidCredentials
+		}
+		return err
+	}
+	return nil
+}
+
+// Search 查询条目
+func (this *Conn) Search(baseDN string, scope int, filter string, attributes []string, sizeLimit int) ([]*Entry, error) {
+	filterPacket, err := ParseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	var attributesPacket = newBERSequence()
+	for _, attr := range attributes {
+		if len(attr) > 0 {
+			attributesPacket.Children = append(attributesPacket.Children, newBERString(attr))
+		}
+	}
+	var request = newBERConstructed(berClassApplication, opSearchRequest,
+		newBERString(baseDN),
+		newBERInteger(berTagEnumerated, int64(scope)),
+		newBERInteger(berTagEnumerated, 0), // neverDerefAliases
+		newBERInteger(berTagInteger, int64(sizeLimit)),
+		newBERInteger(berTagInteger, int64(this.timeout/time.Second)),
+		newBERBoolean(false),
+		filterPacket,
+		attributesPacket,
+	)
+
+	messageId, err := this.send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = []*Entry{}
+	for {
+		op, err := this.receive(messageId)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case op.Class == berClassApplication && op.Tag == opSearchResultEntry:
+			entry, err := parseEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case op.Class == berClassApplication && op.Tag == opSearchResultRef:
+			// 不跟随引用
+		case op.Class == berClassApplication && op.Tag == opSearchResultDone:
+			err = checkResult(op)
+			if err != nil {
+				return nil, err
+			}
+			return entries, nil
+		default:
+			return nil, fmt.Errorf("unexpected ldap operation %d", op.Tag)
+		}
+	}
+}
+
+// Close 断开连接
+func (this *Conn) Close() error {
+	_, _ = this.send(newBERPrimitive(berClassApplication, opUnbindRequest, nil))
+	return this.rawConn.Close()
+}
+
+func (this *Conn) startTLS(tlsConfig *tls.Config) error {
+	var request = newBERConstructed(berClassApplication, opExtendedRequest,
+		newBERPrimitive(berClassContext, 0, []byte(oidStartTLS)),
+	)
+	response, err := this.call(request, opExtendedResponse)
+	if err != nil {
+		return err
+	}
+	err = checkResult(response)
+	if err != nil {
+		return errors.New("start tls failed: " + err.Error())
+	}
+
+	var tlsConn = tls.Client(this.rawConn, tlsConfig)
+	_ = tlsConn.SetDeadline(time.Now().Add(this.timeout))
+	err = tlsConn.Handshake()
+	if err != nil {
+		return err
+	}
+	this.rawConn = tlsConn
+	this.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// 发送请求并等待单个响应
+func (this *Conn) call(request *berPacket, responseTag int) (*berPacket, error) {
+	messageId, err := this.send(request)
+	if err != nil {
+		return nil, err
+	}
+	op, err := this.receive(messageId)
+	if err != nil {
+		return nil, err
+	}
+	if op.Class != berClassApplication || op.Tag != responseTag {
+		return nil, fmt.Errorf("unexpected ldap operation %d", op.Tag)
+	}
+	return op, nil
+}
+
+func (this *Conn) send(op *berPacket) (messageId int64, err error) {
+	this.messageId++
+	messageId = this.messageId
+	var message = newBERSequence(newBERInteger(berTagInteger, messageId), op)
+	_ = this.rawConn.SetDeadline(time.Now().Add(this.timeout))
+	_, err = this.rawConn.Write(message.Bytes())
+	return
+}
+
+func (this *Conn) receive(messageId int64) (*berPacket, error) {
+	_ = this.rawConn.SetDeadline(time.Now().Add(this.timeout))
+	message, err := readBERPacket(this.reader)
+	if err != nil {
+		return nil, err
+	}
+	if !message.Constructed || len(message.Children) < 2 {
+		return nil, errors.New("invalid ldap message")
+	}
+	if message.Children[0].Int() != messageId {
+		return nil, errors.New("unexpected ldap message id")
+	}
+	return message.Children[1], nil
+}
+
+func checkResult(op *berPacket) error {
+	if len(op.Children) < 3 {
+		return errors.New("invalid ldap result")
+	}
+	var code = op.Children[0].Int()
+	if code == resultSuccess {
+		return nil
+	}
+	return &ResultError{
+		Code:    code,
+		Message: op.Children[2].String(),
+	}
+}
+
+func parseEntry(op *berPacket) (*Entry, error) {
+	if len(op.Children) < 2 {
+		return nil, errors.New("invalid search result entry")
+	}
+	var entry = &Entry{
+		DN:         op.Children[0].String(),
+		Attributes: map[string][]string{},
+	}
+	for _, attr := range op.Children[1].Children {
+		if len(attr.Children) < 2 {
+			continue
+		}
+		var name = strings.ToLower(attr.Children[0].String())
+		for _, value := range attr.Children[1].Children {
+			entry.Attributes[name] = append(entry.Attributes[name], value.String())
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// 过滤器中的标签
+const (
+	filterTagAnd            = 0
+	filterTagOr             = 1
+	filterTagNot            = 2
+	filterTagEqualityMatch  = 3
+	filterTagSubstrings     = 4
+	filterTagGreaterOrEqual = 5
+	filterTagLessOrEqual    = 6
+	filterTagPresent        = 7
+	filterTagApproxMatch    = 8
+)
+
+// EscapeFilter 转义过滤器中的值
+func EscapeFilter(value string) string {
+	var builder = strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		var c = value[i]
+		switch c {
+		case '*', '(', ')', '\\', 0:
+			builder.WriteString("\\" + hex.EncodeToString([]byte{c}))
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// ParseFilter 将RFC 4515格式的过滤器转换为BER编码
+func ParseFilter(filter string) (*berPacket, error) {
+	filter = strings.TrimSpace(filter)
+	if len(filter) == 0 {
+		return nil, errors.New("filter should not be empty")
+	}
+	if filter[0] != '(' {
+		filter = "(" + filter + ")"
+	}
+	packet, pos, err := parseFilter(filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pos != len(filter) {
+		return nil, errors.New("unexpected '" + filter[pos:] + "' in filter")
+	}
+	return packet, nil
+}
+
+func parseFilter(filter string, pos int, depth int) (*berPacket, int, error) {
+	if depth > 32 {
+		return nil, pos, errors.New("filter is nested too deeply")
+	}
+	if pos >= len(filter) || filter[pos] != '(' {
+		return nil, pos, errors.New("filter should start with '('")
+	}
+	pos++
+	if pos >= len(filter) {
+		return nil, pos, errors.New("unexpected end of filter")
+	}
+
+	switch filter[pos] {
+	case '&', '|':
+		var tag = filterTagAnd
+		if filter[pos] == '|' {
+			tag = filterTagOr
+		}
+		pos++
+		var packet = newBERConstructed(berClassContext, tag)
+		for pos < len(filter) && filter[pos] == '(' {
+			child, newPos, err := parseFilter(filter, pos, depth+1)
+			if err != nil {
+				return nil, newPos, err
+			}
+			packet.Children = append(packet.Children, child)
+			pos = newPos
+		}
+		if len(packet.Children) == 0 {
+			return nil, pos, errors.New("empty filter list")
+		}
+		if pos >= len(filter) || filter[pos] != ')' {
+			return nil, pos, errors.New("filter should end with ')'")
+		}
+		return packet, pos + 1, nil
+	case '!':
+		child, newPos, err := parseFilter(filter, pos+1, depth+1)
+		if err != nil {
+			return nil, newPos, err
+		}
+		if newPos >= len(filter) || filter[newPos] != ')' {
+			return nil, newPos, errors.New("filter should end with ')'")
+		}
+		return newBERConstructed(berClassContext, filterTagNot, child), newPos + 1, nil
+	}
+
+	// 单个条件
+	var end = strings.IndexByte(filter[pos:], ')')
+	if end < 0 {
+		return nil, pos, errors.New("filter should end with ')'")
+	}
+	var item = filter[pos : pos+end]
+	packet, err := parseFilterItem(item)
+	if err != nil {
+		return nil, pos, err
+	}
+	return packet, pos + end + 1, nil
+}
+
+func parseFilterItem(item string) (*berPacket, error) {
+	var index = strings.IndexByte(item, '=')
+	if index <= 0 {
+		return nil, errors.New("invalid filter item '" + item + "'")
+	}
+	var attr = item[:index]
+	var rawValue = item[index+1:]
+	var tag = filterTagEqualityMatch
+	switch attr[len(attr)-1] {
+	case '>':
+		tag = filterTagGreaterOrEqual
+		attr = attr[:len(attr)-1]
+	case '<':
+		tag = filterTagLessOrEqual
+		attr = attr[:len(attr)-1]
+	case '~':
+		tag = filterTagApproxMatch
+		attr = attr[:len(attr)-1]
+	case ':':
+		return nil, errors.New("extensible match is not supported")
+	}
+	if len(attr) == 0 {
+		return nil, errors.New("invalid filter item '" + item + "'")
+	}
+
+	if tag == filterTagEqualityMatch && rawValue == "*" {
+		return newBERPrimitive(berClassContext, filterTagPresent, []byte(attr)), nil
+	}
+
+	// 按照未转义的*分割
+	var pieces = []string{}
+	for _, rawPiece := range strings.Split(rawValue, "*") {
+		piece, err := unescapeFilterValue(rawPiece)
+		if err != nil {
+			return nil, err
+		}
+		pieces = append(pieces, piece)
+	}
+
+	if len(pieces) == 1 {
+		return newBERConstructed(berClassContext, tag, newBERString(attr), newBERString(pieces[0])), nil
+	}
+	if tag != filterTagEqualityMatch {
+		return nil, errors.New("invalid filter item '" + item + "'")
+	}
+
+	var substrings = newBERSequence()
+	for index, piece := range pieces {
+		if len(piece) == 0 {
+			continue
+		}
+		var pieceTag = 1 // any
+		if index == 0 {
+			pieceTag = 0 // initial
+		} else if index == len(pieces)-1 {
+			pieceTag = 2 // final
+		}
+		substrings.Children = append(substrings.Children, newBERPrimitive(berClassContext, pieceTag, []byte(piece)))
+	}
+	return newBERConstructed(berClassContext, filterTagSubstrings, newBERString(attr), substrings), nil
+}
+
+func unescapeFilterValue(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+	var result = []byte{}
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			result = append(result, value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", errors.New("invalid escape in filter value '" + value + "'")
+		}
+		b, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", errors.New("invalid escape in filter value '" + value + "'")
+		}
+		result = append(result, b[0])
+		i += 2
+	}
+	return string(result), nil
+}
@@ -0,0 +1,397 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 用于测试的简易LDAP服务
+type testServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	caPEM     string
+
+	locker    sync.Mutex
+	entries   []*Entry
+	passwords map[string]string // dn => password
+}
+
+func newTestServer(t *testing.T) *testServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template = &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server = &testServer{
+		listener: listener,
+		tlsConfig: &tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		},
+		caPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		entries: []*Entry{
+			{DN: "cn=reader,dc=example,dc=com", Attributes: map[string][]string{"objectclass": {"person"}, "uid": {"reader"}}},
+			{DN: "uid=alice,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectclass": {"person"}, "uid": {"alice"}, "cn": {"Alice Liu"}, "memberof": {"cn=ops,ou=groups,dc=example,dc=com"}}},
+			{DN: "uid=bob,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectclass": {"person"}, "uid": {"bob"}, "cn": {"Bob"}}},
+			{DN: "cn=dev,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectclass": {"groupOfNames"}, "cn": {"dev"}, "member": {"uid=alice,ou=people,dc=example,dc=com"}}},
+		},
+		passwords: map[string]string{
+			"cn=reader,dc=example,dc=com":           "reader-pass",
+			"uid=alice,ou=people,dc=example,dc=com": "alice-pass",
+			"uid=bob,ou=people,dc=example,dc=com":   "bob-pass",
+		},
+	}
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return server
+}
+
+func (this *testServer) URL() string {
+	return "ldap://" + this.listener.Addr().String()
+}
+
+func (this *testServer) serve() {
+	for {
+		conn, err := this.listener.Accept()
+		if err != nil {
+			return
+		}
+		go this.handle(conn)
+	}
+}
+
+func (this *testServer) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	var reader = bufio.NewReader(conn)
+	for {
+		message, err := readBERPacket(reader)
+		if err != nil {
+			return
+		}
+		var messageId = message.child(0).Int()
+		var op = message.child(1)
+		var reply = func(op *berPacket) {
+			_, _ = conn.Write(newBERSequence(newBERInteger(berTagInteger, messageId), op).Bytes())
+		}
+		var result = func(tag int, code int64) *berPacket {
+			return newBERConstructed(berClassApplication, tag, newBERInteger(berTagEnumerated, code), newBERString(""), newBERString(""))
+		}
+
+		switch op.Tag {
+		case opBindRequest:
+			var dn = op.child(1).String()
+			var password = op.child(2).String()
+			this.locker.Lock()
+			var expected, ok = this.passwords[dn]
+			this.locker.Unlock()
+			if ok && expected == password {
+				reply(result(opBindResponse, resultSuccess))
+			} else {
+				reply(result(opBindResponse, resultInvalidCredents))
+			}
+		case opUnbindRequest:
+			return
+		case opExtendedRequest:
+			if op.child(0).String() != oidStartTLS {
+				reply(result(opExtendedResponse, 2))
+				continue
+			}
+			reply(result(opExtendedResponse, resultSuccess))
+			var tlsConn = tls.Server(conn, this.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(tlsConn)
+		case opSearchRequest:
+			var baseDN = strings.ToLower(op.child(0).String())
+			this.locker.Lock()
+			for _, entry := range this.entries {
+				if !strings.HasSuffix(strings.ToLower(entry.DN), baseDN) || !matchTestFilter(op.child(6), entry) {
+					continue
+				}
+				var attrs = newBERSequence()
+				for name, values := range entry.Attributes {
+					var set = newBERConstructed(berClassUniversal, berTagSet)
+					for _, value := range values {
+						set.Children = append(set.Children, newBERString(value))
+					}
+					attrs.Children = append(attrs.Children, newBERSequence(newBERString(name), set))
+				}
+				reply(newBERConstructed(berClassApplication, opSearchResultEntry, newBERString(entry.DN), attrs))
+			}
+			this.locker.Unlock()
+			reply(result(opSearchResultDone, resultSuccess))
+		}
+	}
+}
+
+func matchTestFilter(filter *berPacket, entry *Entry) bool {
+	switch filter.Tag {
+	case filterTagAnd:
+		for _, child := range filter.Children {
+			if !matchTestFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case filterTagOr:
+		for _, child := range filter.Children {
+			if matchTestFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case filterTagNot:
+		return !matchTestFilter(filter.child(0), entry)
+	case filterTagPresent:
+		return len(entry.Attributes[strings.ToLower(filter.String())]) > 0
+	case filterTagEqualityMatch:
+		for _, value := range entry.Attributes[strings.ToLower(filter.child(0).String())] {
+			if strings.EqualFold(value, filter.child(1).String()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newTestConfig(server *testServer) *Config {
+	var config = NewConfig()
+	config.IsOn = true
+	config.URL = server.URL()
+	config.StartTLS = true
+	config.CACertificate = server.caPEM
+	config.BindDN = "cn=reader,dc=example,dc=com"
+	config.BindPassword = "reader-pass"
+	config.BaseDN = "ou=people,dc=example,dc=com"
+	config.GroupBaseDN = "ou=groups,dc=example,dc=com"
+	config.GroupMappings = []*GroupMapping{
+		{Group: "ops", ModuleCodes: []string{"servers", "dns"}},
+		{Group: "cn=dev,ou=groups,dc=example,dc=com", ModuleCodes: []string{"servers", "log"}},
+	}
+	return config
+}
+
+func TestAuthenticate(t *testing.T) {
+	var server = newTestServer(t)
+	var config = newTestConfig(server)
+	err := config.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cache = NewGroupCache()
+	identity, err := Authenticate(config, cache, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Fullname != "Alice Liu" || len(identity.Groups) != 2 {
+		t.Fatal("unexpected identity:", identity.Fullname, identity.Groups)
+	}
+	moduleCodes, matched := config.MapModules(identity.Groups)
+	if !matched || strings.Join(moduleCodes, ",") != "servers,dns,log" {
+		t.Fatal("unexpected modules:", moduleCodes, matched)
+	}
+
+	// 用户不属于任何分组
+	identity, err = Authenticate(config, cache, "bob", "bob-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, matched = config.MapModules(identity.Groups)
+	if matched {
+		t.Fatal("bob should not match any group")
+	}
+
+	for _, credentials := range [][2]string{
+		{"alice", "wrong"},
+		{"alice", ""},
+		{"nobody", "alice-pass"},
+		{"*", "alice-pass"},
+		{"alice)(uid=*", "alice-pass"},
+	} {
+		_, err = Authenticate(config, cache, credentials[0], credentials[1])
+		if err != ErrInvalidCredentials {
+			t.Fatal("expected invalid credentials for", credentials, "got:", err)
+		}
+	}
+}
+
+func TestAuthenticate_GroupCache(t *testing.T) {
+	var server = newTestServer(t)
+	var config = newTestConfig(server)
+	var cache = NewGroupCache()
+
+	_, err := Authenticate(config, cache, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改分组后，在缓存有效期内仍然使用缓存中的分组
+	server.locker.Lock()
+	server.entries[1].Attributes["memberof"] = nil
+	server.locker.Unlock()
+
+	identity, err := Authenticate(config, cache, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identity.Groups) != 2 {
+		t.Fatal("groups should be cached:", identity.Groups)
+	}
+
+	cache.Clear()
+	identity, err = Authenticate(config, cache, "alice", "alice-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identity.Groups) != 1 {
+		t.Fatal("groups should be reloaded:", identity.Groups)
+	}
+}
+
+func TestAuthenticate_Unavailable(t *testing.T) {
+	var server = newTestServer(t)
+	var config = newTestConfig(server)
+
+	// 查询账号错误
+	config.BindPassword = "wrong"
+	_, err := Authenticate(config, nil, "alice", "alice-pass")
+	if err == nil || err == ErrInvalidCredentials {
+		t.Fatal("expected service error, got:", err)
+	}
+
+	// 证书不受信任
+	config = newTestConfig(server)
+	config.CACertificate = ""
+	_, err = Authenticate(config, nil, "alice", "alice-pass")
+	if err == nil || err == ErrInvalidCredentials {
+		t.Fatal("expected tls error, got:", err)
+	}
+
+	// 服务不可用
+	config = newTestConfig(server)
+	_ = server.listener.Close()
+	_, err = Authenticate(config, nil, "alice", "alice-pass")
+	if err == nil || err == ErrInvalidCredentials {
+		t.Fatal("expected connection error, got:", err)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, filter := range []string{
+		"uid=alice",
+		"(&(objectClass=person)(uid=alice))",
+		"(|(cn=a*)(cn=*b*c)(!(cn=*)))",
+		"(cn>=a)",
+		`(cn=a\2ab\29)`,
+	} {
+		_, err := ParseFilter(filter)
+		if err != nil {
+			t.Fatal(filter, err)
+		}
+	}
+	for _, filter := range []string{
+		"",
+		"(uid=alice",
+		"(&)",
+		"(=alice)",
+		`(cn=a\2)`,
+		"(uid=alice))",
+	} {
+		_, err := ParseFilter(filter)
+		if err == nil {
+			t.Fatal("filter should be invalid:", filter)
+		}
+	}
+
+	packet, err := ParseFilter(`(cn=` + EscapeFilter("a*(b)") + `)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Tag != filterTagEqualityMatch || packet.child(1).String() != "a*(b)" {
+		t.Fatal("unexpected packet")
+	}
+}
+
+func TestGroupName(t *testing.T) {
+	if GroupName("cn=ops,ou=groups,dc=example,dc=com") != "ops" || GroupName("ops") != "ops" {
+		t.Fatal("unexpected group name")
+	}
+}
+
+func TestLinkStore(t *testing.T) {
+	var store = NewLinkStore()
+	store.Link("uid=alice,ou=People,dc=example,dc=com", 2)
+	store.Link("uid=bob,ou=People,dc=example,dc=com", 2)
+	if store.Find(" UID=alice,ou=people,dc=example,dc=com") != 2 || store.Find("uid=carol,ou=People,dc=example,dc=com") != 0 {
+		t.Fatal("find failed")
+	}
+
+	var cloned = store.Clone()
+	if !cloned.Unlink("uid=alice,ou=People,dc=example,dc=com") || cloned.Unlink("uid=alice,ou=People,dc=example,dc=com") {
+		t.Fatal("unlink failed")
+	}
+	if store.Find("uid=alice,ou=People,dc=example,dc=com") != 2 {
+		t.Fatal("clone should not share links")
+	}
+
+	store.RemoveAdmin(2)
+	if len(store.Links) != 0 {
+		t.Fatal("remove admin failed")
+	}
+}
+
+func TestConfig_Clone(t *testing.T) {
+	var config = NewConfig()
+	config.GroupMappings = []*GroupMapping{
+		{Group: "ops", ModuleCodes: []string{"node"}},
+	}
+	var cloned = config.Clone()
+	cloned.GroupMappings[0].ModuleCodes[0] = "server"
+	if config.GroupMappings[0].ModuleCodes[0] != "node" {
+		t.Fatal("clone should not share group mappings")
+	}
+
+	config.GroupMappings = nil
+	if config.Clone().GroupMappings == nil {
+		t.Fatal("group mappings should not be nil")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ldaputils
+
+import (
+	"strings"
+)
+
+// LinkStore LDAP用户和管理员的关联
+// 只有关联过的管理员才能通过LDAP登录，不会按用户名自动对应到已有的管理员
+type LinkStore struct {
+	Links map[string]int64 `json:"links"` // lower(dn) => adminId
+}
+
+// NewLinkStore 获取新对象
+func NewLinkStore() *LinkStore {
+	return &LinkStore{
+		Links: map[string]int64{},
+	}
+}
+
+// Clone 复制对象
+func (this *LinkStore) Clone() *LinkStore {
+	var store = NewLinkStore()
+	for dn, adminId := range this.Links {
+		store.Links[dn] = adminId
+	}
+	return store
+}
+
+// Find 查找用户关联的管理员ID
+func (this *LinkStore) Find(dn string) int64 {
+	return this.Links[normalizeDN(dn)]
+}
+
+// Link 关联用户和管理员，一个用户只能关联一个管理员
+func (this *LinkStore) Link(dn string, adminId int64) {
+	if this.Links == nil {
+		this.Links = map[string]int64{}
+	}
+	this.Links[normalizeDN(dn)] = adminId
+}
+
+// Unlink 取消用户的关联
+func (this *LinkStore) Unlink(dn string) bool {
+	dn = normalizeDN(dn)
+	_, ok := this.Links[dn]
+	if ok {
+		delete(this.Links, dn)
+	}
+	return ok
+}
+
+// RemoveAdmin 删除管理员的所有关联
+func (this *LinkStore) RemoveAdmin(adminId int64) {
+	for dn, linkedAdminId := range this.Links {
+		if linkedAdminId == adminId {
+			delete(this.Links, dn)
+		}
+	}
+}
+
+func normalizeDN(dn string) string {
+	return strings.ToLower(strings.TrimSpace(dn))
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package loginlimitutils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 验证码长度
+const CaptchaLength = 5
+
+// 验证码有效期
+const CaptchaLife = 5 * time.Minute
+
+// 最多同时保存的验证码数量
+const maxCaptchas = 10000
+
+// 数字字模，每个数字5x7
+var captchaDigits = [10][7]string{
+	{"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	{"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	{"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	{"11111", "00010", "00100", "00010", "00001", "10001", "01110"},
+	{"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	{"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	{"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	{"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	{"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	{"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+}
+
+type captchaItem struct {
+	code      string
+	expiresAt time.Time
+}
+
+// CaptchaStore 图片验证码存储
+type CaptchaStore struct {
+	items  map[string]*captchaItem // id => item
+	locker sync.Mutex
+}
+
+// NewCaptchaStore 获取新对象
+func NewCaptchaStore() *CaptchaStore {
+	return &CaptchaStore{
+		items: map[string]*captchaItem{},
+	}
+}
+
+// New 生成新的验证码，返回验证码ID
+func (this *CaptchaStore) New(now time.Time) string {
+	var idBytes = make([]byte, 16)
+	_, err := rand.Read(idBytes)
+	if err != nil {
+		panic(err)
+	}
+	var id = hex.EncodeToString(idBytes)
+
+	var code = strings.Builder{}
+	for i := 0; i < CaptchaLength; i++ {
+		code.WriteByte(byte('0' + randInt(10)))
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	if len(this.items) >= maxCaptchas {
+		for itemId, item := range this.items {
+			if !item.expiresAt.After(now) {
+				delete(this.items, itemId)
+			}
+		}
+
+		// 仍然太多时随机删除一部分，防止占用过多内存
+		for itemId := range this.items {
+			if len(this.items) < maxCaptchas {
+				break
+			}
+			delete(this.items, itemId)
+		}
+	}
+	this.items[id] = &captchaItem{
+		code:      code.String(),
+		expiresAt: now.Add(CaptchaLife),
+	}
+	return id
+}
+
+// Verify 校验验证码，每个验证码只能校验一次
+func (this *CaptchaStore) Verify(id string, code string, now time.Time) bool {
+	this.locker.Lock()
+	item, ok := this.items[id]
+	delete(this.items, id)
+	this.locker.Unlock()
+
+	if !ok || !item.expiresAt.After(now) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(item.code), []byte(strings.TrimSpace(code))) == 1
+}
+
+// Renew 更换验证码，只有旧的验证码仍然有效时才会生成新的
+func (this *CaptchaStore) Renew(id string, now time.Time) (newId string, ok bool) {
+	this.locker.Lock()
+	item, ok := this.items[id]
+	delete(this.items, id)
+	this.locker.Unlock()
+
+	if !ok || !item.expiresAt.After(now) {
+		return "", false
+	}
+	return this.New(now), true
+}
+
+// WriteImage 输出验证码图片
+func (this *CaptchaStore) WriteImage(writer io.Writer, id string, now time.Time) bool {
+	this.locker.Lock()
+	item, ok := this.items[id]
+	this.locker.Unlock()
+
+	if !ok || !item.expiresAt.After(now) {
+		return false
+	}
+	return png.Encode(writer, drawCaptcha(item.code)) == nil
+}
+
+// 绘制验证码图片
+func drawCaptcha(code string) image.Image {
+	const scale = 4
+	const charWidth = 5*scale + 8
+	var width = len(code)*charWidth + 16
+	var height = 7*scale + 16
+
+	var img = image.NewRGBA(image.Rect(0, 0, width, height))
+	var background = color.RGBA{R: 245, G: 245, B: 245, A: 255}
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, background)
+		}
+	}
+
+	// 字符
+	for index, char := range code {
+		var digit = captchaDigits[char-'0']
+		var offsetX = 8 + index*charWidth + randInt(5) - 2
+		var offsetY = 8 + randInt(7) - 3
+		var fg = color.RGBA{R: uint8(randInt(120)), G: uint8(randInt(120)), B: uint8(randInt(120)), A: 255}
+		for row, line := range digit {
+			for col, bit := range line {
+				if bit != '1' {
+					continue
+				}
+				for dx := 0; dx < scale; dx++ {
+					for dy := 0; dy < scale; dy++ {
+						img.Set(offsetX+col*scale+dx, offsetY+row*scale+dy, fg)
+					}
+				}
+			}
+		}
+	}
+
+	// 干扰点和干扰线
+	for i := 0; i < width*height/12; i++ {
+		var c = uint8(100 + randInt(120))
+		img.Set(randInt(width), randInt(height), color.RGBA{R: c, G: c, B: c, A: 255})
+	}
+	for i := 0; i < 4; i++ {
+		var y0, y1 = randInt(height), randInt(height)
+		var c = color.RGBA{R: uint8(randInt(160)), G: uint8(randInt(160)), B: uint8(randInt(160)), A: 255}
+		for x := 0; x < width; x++ {
+			img.Set(x, y0+(y1-y0)*x/width, c)
+		}
+	}
+	return img
+}
+
+func randInt(max int) int {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		panic(err)
+	}
+	return int(n.Int64())
+}
+
+// SharedCaptchaStore 共享的验证码存储
+var SharedCaptchaStore = NewCaptchaStore()
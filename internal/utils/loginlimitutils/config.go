@@ -0,0 +1,94 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package loginlimitutils
+
+import (
+	"errors"
+	"time"
+)
+
+// Config 登录保护配置
+type Config struct {
+	IsOn bool `json:"isOn"` // 是否启用
+
+	WindowSeconds int `json:"windowSeconds"` // 统计失败次数的滑动窗口
+
+	DelayAfter       int `json:"delayAfter"`       // 失败几次后开始要求等待
+	BaseDelaySeconds int `json:"baseDelaySeconds"` // 第一次等待的时间，之后每次翻倍
+	MaxDelaySeconds  int `json:"maxDelaySeconds"`  // 最长等待时间
+
+	UserLockAfter int `json:"userLockAfter"` // 同一个用户名失败几次后锁定
+	IPLockAfter   int `json:"ipLockAfter"`   // 同一个IP失败几次后锁定
+	LockSeconds   int `json:"lockSeconds"`   // 锁定时长
+
+	CaptchaAfter int `json:"captchaAfter"` // 失败几次后需要输入验证码，0表示不需要
+}
+
+// NewConfig 获取新对象
+func NewConfig() *Config {
+	return &Config{
+		IsOn:             true,
+		WindowSeconds:    900,
+		DelayAfter:       3,
+		BaseDelaySeconds: 1,
+		MaxDelaySeconds:  60,
+		UserLockAfter:    10,
+		IPLockAfter:      30,
+		LockSeconds:      900,
+		CaptchaAfter:     0,
+	}
+}
+
+// Validate 校验配置
+func (this *Config) Validate() error {
+	if this.WindowSeconds < 60 || this.WindowSeconds > 86400 {
+		return errors.New("统计窗口需要在60秒到86400秒之间")
+	}
+	if this.DelayAfter < 0 {
+		return errors.New("开始等待的失败次数不能小于0")
+	}
+	if this.BaseDelaySeconds < 0 || this.MaxDelaySeconds < this.BaseDelaySeconds {
+		return errors.New("最长等待时间不能小于第一次等待的时间")
+	}
+	if this.MaxDelaySeconds > 3600 {
+		return errors.New("最长等待时间不能超过3600秒")
+	}
+	if this.UserLockAfter < 0 || this.IPLockAfter < 0 {
+		return errors.New("锁定的失败次数不能小于0")
+	}
+	if (this.UserLockAfter > 0 || this.IPLockAfter > 0) && (this.LockSeconds < 60 || this.LockSeconds > 86400*7) {
+		return errors.New("锁定时长需要在60秒到7天之间")
+	}
+	if this.CaptchaAfter < 0 {
+		return errors.New("需要验证码的失败次数不能小于0")
+	}
+	return nil
+}
+
+func (this *Config) window() time.Duration {
+	return time.Duration(this.WindowSeconds) * time.Second
+}
+
+// 某类对象失败多少次后锁定
+func (this *Config) lockAfter(kind string) int {
+	if kind == KindIP {
+		return this.IPLockAfter
+	}
+	return this.UserLockAfter
+}
+
+// 计算失败count次后需要等待的时间
+func (this *Config) delay(count int) time.Duration {
+	if this.DelayAfter <= 0 || count < this.DelayAfter || this.BaseDelaySeconds <= 0 {
+		return 0
+	}
+	var delay = time.Duration(this.BaseDelaySeconds) * time.Second
+	var maxDelay = time.Duration(this.MaxDelaySeconds) * time.Second
+	for i := this.DelayAfter; i < count; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,342 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package loginlimitutils
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// 锁定对象类型
+const (
+	KindIP       = "ip"
+	KindUsername = "username"
+)
+
+// 每个对象最多保留的失败记录数
+const maxFailuresPerKey = 1000
+
+// 预留的登录尝试有效期，超过此时间仍未调用 Fail() 或者 Succeed() 时自动释放
+const reservationLife = 30 * time.Second
+
+type counter struct {
+	failures      []time.Time
+	reservations  []time.Time // 正在进行中的登录尝试
+	nextAllowedAt time.Time
+	lockedAt      time.Time
+	lockedUntil   time.Time
+}
+
+// 清理过期的失败记录
+func (this *counter) prune(now time.Time, window time.Duration) {
+	if !this.lockedUntil.IsZero() && !this.lockedUntil.After(now) {
+		// 锁定到期后重新计数
+		this.failures = nil
+		this.lockedAt = time.Time{}
+		this.lockedUntil = time.Time{}
+	}
+	var index = 0
+	for index < len(this.failures) && !this.failures[index].After(now.Add(-window)) {
+		index++
+	}
+	if index > 0 {
+		this.failures = this.failures[index:]
+	}
+
+	index = 0
+	for index < len(this.reservations) && !this.reservations[index].After(now.Add(-reservationLife)) {
+		index++
+	}
+	if index > 0 {
+		this.reservations = this.reservations[index:]
+	}
+}
+
+// 释放一次预留的登录尝试
+func (this *counter) release() {
+	if len(this.reservations) > 0 {
+		this.reservations = this.reservations[1:]
+	}
+}
+
+// 失败次数，包括正在进行中的登录尝试
+func (this *counter) count() int {
+	return len(this.failures) + len(this.reservations)
+}
+
+func (this *counter) isLocked(now time.Time) bool {
+	return this.lockedUntil.After(now)
+}
+
+// Lockout 锁定信息
+type Lockout struct {
+	Kind        string    // 类型：ip、username
+	Value       string    // IP或者用户名
+	Failures    int       // 窗口内失败次数
+	LockedAt    time.Time // 锁定时间
+	LockedUntil time.Time // 解锁时间
+}
+
+// Decision 登录前的检查结果
+type Decision struct {
+	Lockout        *Lockout      // 不为空表示已被锁定
+	RetryAfter     time.Duration // 需要等待的时间
+	RequireCaptcha bool          // 是否需要输入验证码
+}
+
+// Limiter 登录失败限制器，分别按IP和用户名计数
+type Limiter struct {
+	counters    map[string]*counter // kind@value => counter
+	lastCleanAt time.Time
+	locker      sync.Mutex
+}
+
+// NewLimiter 获取新对象
+func NewLimiter() *Limiter {
+	return &Limiter{
+		counters: map[string]*counter{},
+	}
+}
+
+// Check 登录前检查，username 为空时只检查IP
+// 允许登录时会预留一次尝试，把它当作可能的失败计算之后请求的等待时间，防止并发请求绕过限制；
+// 预留的尝试需要在登录结束后调用 Fail() 或者 Succeed() 释放
+func (this *Limiter) Check(config *Config, ip string, username string, now time.Time) *Decision {
+	if config == nil || !config.IsOn {
+		return &Decision{}
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	var decision = this.check(config, ip, username, now)
+	if decision.Lockout != nil || decision.RetryAfter > 0 {
+		return decision
+	}
+
+	for _, key := range this.keys(ip, username) {
+		var c = this.findOrCreateCounter(key)
+		c.reservations = append(c.reservations, now)
+		var nextAllowedAt = now.Add(config.delay(c.count()))
+		if nextAllowedAt.After(c.nextAllowedAt) {
+			c.nextAllowedAt = nextAllowedAt
+		}
+	}
+	return decision
+}
+
+// RequireCaptcha 检查是否需要输入验证码，不会预留登录尝试
+func (this *Limiter) RequireCaptcha(config *Config, ip string, username string, now time.Time) bool {
+	if config == nil || !config.IsOn {
+		return false
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	return this.check(config, ip, username, now).RequireCaptcha
+}
+
+// Fail 记录一次失败，返回因此次失败而新锁定的对象
+func (this *Limiter) Fail(config *Config, ip string, username string, now time.Time) (lockouts []*Lockout) {
+	if config == nil || !config.IsOn {
+		return
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	this.clean(config, now)
+
+	for _, key := range this.keys(ip, username) {
+		var c = this.findOrCreateCounter(key)
+		c.prune(now, config.window())
+		c.release()
+		if c.isLocked(now) {
+			continue
+		}
+		c.failures = append(c.failures, now)
+		if len(c.failures) > maxFailuresPerKey {
+			c.failures = c.failures[len(c.failures)-maxFailuresPerKey:]
+		}
+
+		var lockAfter = config.lockAfter(key.kind)
+		if lockAfter > 0 && len(c.failures) >= lockAfter {
+			c.lockedAt = now
+			c.lockedUntil = now.Add(time.Duration(config.LockSeconds) * time.Second)
+			lockouts = append(lockouts, key.lockout(c))
+			continue
+		}
+		c.nextAllowedAt = now.Add(config.delay(c.count()))
+	}
+	return
+}
+
+// Succeed 登录成功后清除用户名的失败记录，并释放IP预留的登录尝试
+// IP的失败记录保留，防止使用一个有效账号重置IP计数
+func (this *Limiter) Succeed(config *Config, ip string, username string) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	username = normalizeUsername(username)
+	if len(username) > 0 {
+		delete(this.counters, (&limiterKey{kind: KindUsername, value: username}).id())
+	}
+	this.release(config, ip, "")
+}
+
+// Release 没有完成的登录尝试（比如验证码错误）释放预留，不计为失败
+func (this *Limiter) Release(config *Config, ip string, username string) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	this.release(config, ip, username)
+}
+
+// FindLockouts 列出当前所有锁定的对象
+func (this *Limiter) FindLockouts(now time.Time) []*Lockout {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	var result = []*Lockout{}
+	for id, c := range this.counters {
+		if !c.isLocked(now) {
+			continue
+		}
+		result = append(result, parseLimiterKey(id).lockout(c))
+	}
+	return result
+}
+
+// Clear 解除锁定并清除失败记录
+func (this *Limiter) Clear(kind string, value string) bool {
+	var key = &limiterKey{kind: kind, value: value}
+	if kind == KindUsername {
+		key.value = normalizeUsername(value)
+	}
+
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	_, ok := this.counters[key.id()]
+	if ok {
+		delete(this.counters, key.id())
+	}
+	return ok
+}
+
+func (this *Limiter) check(config *Config, ip string, username string, now time.Time) *Decision {
+	var decision = &Decision{}
+	for _, key := range this.keys(ip, username) {
+		c, ok := this.counters[key.id()]
+		if !ok {
+			continue
+		}
+		c.prune(now, config.window())
+		if c.isLocked(now) {
+			decision.Lockout = key.lockout(c)
+			return decision
+		}
+		if c.nextAllowedAt.After(now) {
+			var retryAfter = c.nextAllowedAt.Sub(now)
+			if retryAfter > decision.RetryAfter {
+				decision.RetryAfter = retryAfter
+			}
+		}
+
+		// 正在进行中的尝试都失败后就会锁定时，需要等待它们结束
+		var lockAfter = config.lockAfter(key.kind)
+		if lockAfter > 0 && len(c.reservations) > 0 && c.count() >= lockAfter && decision.RetryAfter < time.Second {
+			decision.RetryAfter = time.Second
+		}
+
+		if config.CaptchaAfter > 0 && len(c.failures) >= config.CaptchaAfter {
+			decision.RequireCaptcha = true
+		}
+	}
+	return decision
+}
+
+func (this *Limiter) release(config *Config, ip string, username string) {
+	for _, key := range this.keys(ip, username) {
+		c, ok := this.counters[key.id()]
+		if !ok {
+			continue
+		}
+		c.release()
+
+		// 按照实际的失败次数重新计算等待时间
+		c.nextAllowedAt = time.Time{}
+		if len(c.failures) > 0 && config != nil {
+			c.nextAllowedAt = c.failures[len(c.failures)-1].Add(config.delay(c.count()))
+		}
+	}
+}
+
+func (this *Limiter) findOrCreateCounter(key *limiterKey) *counter {
+	c, ok := this.counters[key.id()]
+	if !ok {
+		c = &counter{}
+		this.counters[key.id()] = c
+	}
+	return c
+}
+
+func (this *Limiter) keys(ip string, username string) []*limiterKey {
+	var result = []*limiterKey{}
+	if len(ip) > 0 {
+		result = append(result, &limiterKey{kind: KindIP, value: ip})
+	}
+	username = normalizeUsername(username)
+	if len(username) > 0 {
+		result = append(result, &limiterKey{kind: KindUsername, value: username})
+	}
+	return result
+}
+
+// 每分钟最多清理一次不再需要的计数器
+func (this *Limiter) clean(config *Config, now time.Time) {
+	if now.Sub(this.lastCleanAt) < time.Minute {
+		return
+	}
+	this.lastCleanAt = now
+
+	for id, c := range this.counters {
+		c.prune(now, config.window())
+		if len(c.failures) == 0 && len(c.reservations) == 0 && !c.isLocked(now) && !c.nextAllowedAt.After(now) {
+			delete(this.counters, id)
+		}
+	}
+}
+
+type limiterKey struct {
+	kind  string
+	value string
+}
+
+func parseLimiterKey(id string) *limiterKey {
+	kind, value, _ := strings.Cut(id, "@")
+	return &limiterKey{kind: kind, value: value}
+}
+
+func (this *limiterKey) id() string {
+	return this.kind + "@" + this.value
+}
+
+func (this *limiterKey) lockout(c *counter) *Lockout {
+	return &Lockout{
+		Kind:        this.kind,
+		Value:       this.value,
+		Failures:    len(c.failures),
+		LockedAt:    c.lockedAt,
+		LockedUntil: c.lockedUntil,
+	}
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// SharedLimiter 共享的登录限制器
+var SharedLimiter = NewLimiter()
@@ -0,0 +1,217 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package loginlimitutils
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig_Delay(t *testing.T) {
+	var config = NewConfig()
+	config.DelayAfter = 3
+	config.BaseDelaySeconds = 1
+	config.MaxDelaySeconds = 10
+
+	for count, expected := range map[int]time.Duration{
+		1: 0,
+		2: 0,
+		3: 1 * time.Second,
+		4: 2 * time.Second,
+		5: 4 * time.Second,
+		6: 8 * time.Second,
+		7: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		if config.delay(count) != expected {
+			t.Fatal("count:", count, "expected:", expected, "got:", config.delay(count))
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var config = NewConfig()
+	config.DelayAfter = 2
+	config.BaseDelaySeconds = 1
+	config.MaxDelaySeconds = 4
+	config.UserLockAfter = 4
+	config.IPLockAfter = 6
+	config.LockSeconds = 600
+	config.CaptchaAfter = 3
+
+	var limiter = NewLimiter()
+	var now = time.Now()
+
+	// 第一次失败不需要等待
+	limiter.Fail(config, "1.2.3.4", "admin", now)
+	var decision = limiter.Check(config, "1.2.3.4", "Admin", now)
+	if decision.Lockout != nil || decision.RetryAfter > 0 || decision.RequireCaptcha {
+		t.Fatal("unexpected decision after first failure")
+	}
+
+	// 第二次失败后需要等待
+	limiter.Fail(config, "1.2.3.4", "admin", now)
+	decision = limiter.Check(config, "1.2.3.4", "admin", now)
+	if decision.RetryAfter != time.Second {
+		t.Fatal("expected 1s delay, got:", decision.RetryAfter)
+	}
+
+	// 第三次失败后需要验证码
+	now = now.Add(time.Second)
+	limiter.Fail(config, "1.2.3.4", "admin", now)
+	decision = limiter.Check(config, "5.6.7.8", "admin", now)
+	if !decision.RequireCaptcha || decision.RetryAfter != 2*time.Second {
+		t.Fatal("unexpected decision:", decision.RequireCaptcha, decision.RetryAfter)
+	}
+
+	// 第四次失败后锁定用户名
+	now = now.Add(2 * time.Second)
+	var lockouts = limiter.Fail(config, "1.2.3.4", "admin", now)
+	if len(lockouts) != 1 || lockouts[0].Kind != KindUsername || lockouts[0].Value != "admin" {
+		t.Fatal("expected username lockout")
+	}
+	decision = limiter.Check(config, "5.6.7.8", "ADMIN", now)
+	if decision.Lockout == nil {
+		t.Fatal("expected username to be locked")
+	}
+	if len(limiter.FindLockouts(now)) != 1 {
+		t.Fatal("expected 1 lockout")
+	}
+
+	// 锁定期间其他用户名从同一个IP继续失败，IP也会被锁定
+	limiter.Fail(config, "1.2.3.4", "other", now)
+	lockouts = limiter.Fail(config, "1.2.3.4", "other2", now)
+	if len(lockouts) != 1 || lockouts[0].Kind != KindIP {
+		t.Fatal("expected ip lockout")
+	}
+
+	// 手工解锁
+	if !limiter.Clear(KindUsername, "Admin") {
+		t.Fatal("expected lockout to be cleared")
+	}
+	decision = limiter.Check(config, "5.6.7.8", "admin", now)
+	if decision.Lockout != nil {
+		t.Fatal("username should not be locked")
+	}
+
+	// 锁定到期
+	decision = limiter.Check(config, "1.2.3.4", "", now.Add(601*time.Second))
+	if decision.Lockout != nil {
+		t.Fatal("ip lockout should expire")
+	}
+
+	// 登录成功后清除用户名计数
+	limiter.Fail(config, "9.9.9.9", "user", now)
+	limiter.Fail(config, "9.9.9.9", "user", now)
+	limiter.Succeed(config, "", "user")
+	decision = limiter.Check(config, "", "user", now)
+	if decision.RetryAfter > 0 {
+		t.Fatal("username counter should be reset")
+	}
+
+	// 滑动窗口外的失败不再计数
+	decision = limiter.Check(config, "9.9.9.9", "", now.Add(time.Duration(config.WindowSeconds+1)*time.Second))
+	if decision.RetryAfter > 0 || decision.RequireCaptcha {
+		t.Fatal("failures outside of window should be ignored")
+	}
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	var config = NewConfig()
+	config.DelayAfter = 2
+	config.BaseDelaySeconds = 1
+	config.MaxDelaySeconds = 4
+	config.UserLockAfter = 3
+	config.IPLockAfter = 0
+
+	var limiter = NewLimiter()
+	var now = time.Now()
+
+	// 同时发起的请求不能都通过检查
+	var countAllowed = 0
+	var wg = sync.WaitGroup{}
+	var locker = sync.Mutex{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var decision = limiter.Check(config, "1.2.3.4", "admin", now)
+			if decision.Lockout == nil && decision.RetryAfter == 0 {
+				locker.Lock()
+				countAllowed++
+				locker.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if countAllowed != config.DelayAfter {
+		t.Fatal("expected", config.DelayAfter, "concurrent attempts, got:", countAllowed)
+	}
+
+	// 所有尝试都失败后按照失败次数等待
+	limiter.Fail(config, "1.2.3.4", "admin", now)
+	limiter.Fail(config, "1.2.3.4", "admin", now)
+	if limiter.Check(config, "1.2.3.4", "admin", now).RetryAfter != time.Second {
+		t.Fatal("expected 1s delay")
+	}
+
+	// 正在进行中的尝试失败后就会锁定时，其他请求需要等待
+	now = now.Add(time.Second)
+	if limiter.Check(config, "5.6.7.8", "admin", now).RetryAfter != 0 {
+		t.Fatal("attempt should be allowed after delay")
+	}
+	if limiter.Check(config, "9.9.9.9", "admin", now).RetryAfter == 0 {
+		t.Fatal("attempt should wait for pending attempt")
+	}
+
+	// 预留的尝试过期后自动释放
+	if limiter.Check(config, "9.9.9.9", "admin", now.Add(reservationLife+time.Second)).RetryAfter != 0 {
+		t.Fatal("expired reservation should be released")
+	}
+
+	// 成功后释放IP预留的尝试
+	limiter.Succeed(config, "1.2.3.4", "admin")
+	if limiter.Check(config, "1.2.3.4", "other", now).RetryAfter != 0 {
+		t.Fatal("ip reservation should be released")
+	}
+}
+
+func TestCaptchaStore(t *testing.T) {
+	var store = NewCaptchaStore()
+	var now = time.Now()
+	var id = store.New(now)
+	var code = store.items[id].code
+	if len(code) != CaptchaLength {
+		t.Fatal("invalid code:", code)
+	}
+
+	var buf = &bytes.Buffer{}
+	if !store.WriteImage(buf, id, now) || buf.Len() == 0 {
+		t.Fatal("expected captcha image")
+	}
+
+	if store.Verify(id, code, now.Add(CaptchaLife+time.Second)) {
+		t.Fatal("expired captcha should fail")
+	}
+
+	id = store.New(now)
+	code = store.items[id].code
+	if !store.Verify(id, code, now) {
+		t.Fatal("captcha should pass")
+	}
+	if store.Verify(id, code, now) {
+		t.Fatal("captcha should be used only once")
+	}
+
+	id = store.New(now)
+	newId, ok := store.Renew(id, now)
+	if !ok || newId == id {
+		t.Fatal("expected new captcha")
+	}
+	_, ok = store.Renew(id, now)
+	if ok {
+		t.Fatal("old captcha should be invalid")
+	}
+}
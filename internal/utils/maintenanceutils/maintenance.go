@@ -0,0 +1,212 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package maintenanceutils
+
+import (
+	"errors"
+	"strconv"
+)
+
+// 维护状态
+const (
+	StatusDraining = "draining" // 已从DNS中摘除，等待连接排空
+	StatusReady    = "ready"    // 连接已排空，可以开始维护
+	StatusRestored = "restored" // 已恢复
+)
+
+// 事件级别
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var (
+	ErrMaintenanceNotFound = errors.New("maintenance not found")
+	ErrMaintenanceExists   = errors.New("node is already in maintenance")
+)
+
+// Event 审计事件
+type Event struct {
+	CreatedAt int64  `json:"createdAt"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+// Maintenance 节点维护记录
+type Maintenance struct {
+	NodeId          int64    `json:"nodeId"`
+	ClusterId       int64    `json:"clusterId"`
+	AdminId         int64    `json:"adminId"`
+	Status          string   `json:"status"`
+	Description     string   `json:"description"`
+	MaxConnections  int64    `json:"maxConnections"` // 连接数不超过此值时认为已排空
+	TimeoutSeconds  int64    `json:"timeoutSeconds"` // 排空超时时间
+	ConnectionCount int64    `json:"connectionCount"`
+	IsTimeout       bool     `json:"isTimeout"` // 是否因为超时才结束排空
+	StartedAt       int64    `json:"startedAt"`
+	DrainedAt       int64    `json:"drainedAt"`
+	RestoredAt      int64    `json:"restoredAt"`
+	Events          []*Event `json:"events"`
+}
+
+// NewMaintenance 获取新对象
+func NewMaintenance(nodeId int64, clusterId int64, maxConnections int64, timeoutSeconds int64, now int64) *Maintenance {
+	if maxConnections < 0 {
+		maxConnections = 0
+	}
+	var m = &Maintenance{
+		NodeId:          nodeId,
+		ClusterId:       clusterId,
+		Status:          StatusDraining,
+		MaxConnections:  maxConnections,
+		TimeoutSeconds:  timeoutSeconds,
+		ConnectionCount: -1,
+		StartedAt:       now,
+		Events:          []*Event{},
+	}
+	m.AddEvent(LevelInfo, "进入维护模式，节点已从DNS中摘除，等待连接排空", now)
+	return m
+}
+
+// AddEvent 添加审计事件
+func (this *Maintenance) AddEvent(level string, message string, now int64) {
+	this.Events = append(this.Events, &Event{
+		CreatedAt: now,
+		Level:     level,
+		Message:   message,
+	})
+}
+
+// IsActive 是否处于维护中
+func (this *Maintenance) IsActive() bool {
+	return this.Status != StatusRestored
+}
+
+// CheckDrain 根据节点上报的连接数检查是否已经排空
+// statusUpdatedAt 为节点状态的上报时间，早于进入维护的时间的状态不可信，需要等待下一次上报
+// 返回状态是否有变化
+func (this *Maintenance) CheckDrain(connectionCount int64, statusUpdatedAt int64, now int64) bool {
+	if this.Status != StatusDraining {
+		return false
+	}
+
+	if statusUpdatedAt >= this.StartedAt {
+		this.ConnectionCount = connectionCount
+		if connectionCount <= this.MaxConnections {
+			this.Status = StatusReady
+			this.DrainedAt = now
+			this.AddEvent(LevelInfo, "连接已排空（当前连接数："+strconv.FormatInt(connectionCount, 10)+"），可以开始维护", now)
+			return true
+		}
+	}
+
+	if this.TimeoutSeconds > 0 && now-this.StartedAt >= this.TimeoutSeconds {
+		this.Status = StatusReady
+		this.DrainedAt = now
+		this.IsTimeout = true
+		var countString = "未知"
+		if this.ConnectionCount >= 0 {
+			countString = strconv.FormatInt(this.ConnectionCount, 10)
+		}
+		this.AddEvent(LevelWarn, "等待连接排空超时（当前连接数："+countString+"），可以开始维护", now)
+		return true
+	}
+
+	return false
+}
+
+// MarkOffline 节点已离线，直接认为已排空
+func (this *Maintenance) MarkOffline(now int64) bool {
+	if this.Status != StatusDraining {
+		return false
+	}
+	this.Status = StatusReady
+	this.DrainedAt = now
+	this.ConnectionCount = 0
+	this.AddEvent(LevelInfo, "节点已离线，可以开始维护", now)
+	return true
+}
+
+// Restore 退出维护模式
+func (this *Maintenance) Restore(now int64) {
+	this.Status = StatusRestored
+	this.RestoredAt = now
+	this.AddEvent(LevelInfo, "退出维护模式，节点已重新加入DNS", now)
+}
+
+// Store 节点维护记录列表
+type Store struct {
+	Items []*Maintenance `json:"items"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Items: []*Maintenance{},
+	}
+}
+
+// Add 添加维护记录
+func (this *Store) Add(m *Maintenance) error {
+	if this.FindActive(m.NodeId) != nil {
+		return ErrMaintenanceExists
+	}
+	this.Items = append(this.Items, m)
+	return nil
+}
+
+// FindActive 查找节点当前的维护记录
+func (this *Store) FindActive(nodeId int64) *Maintenance {
+	for _, item := range this.Items {
+		if item.NodeId == nodeId && item.IsActive() {
+			return item
+		}
+	}
+	return nil
+}
+
+// FindLatest 查找节点最近一次的维护记录
+func (this *Store) FindLatest(nodeId int64) *Maintenance {
+	var result *Maintenance
+	for _, item := range this.Items {
+		if item.NodeId == nodeId && (result == nil || item.StartedAt >= result.StartedAt) {
+			result = item
+		}
+	}
+	return result
+}
+
+// FindAllActive 查找所有维护中的记录
+func (this *Store) FindAllActive() []*Maintenance {
+	var result = []*Maintenance{}
+	for _, item := range this.Items {
+		if item.IsActive() {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// FindClusterActive 查找集群中所有维护中的记录
+func (this *Store) FindClusterActive(clusterId int64) []*Maintenance {
+	var result = []*Maintenance{}
+	for _, item := range this.Items {
+		if item.ClusterId == clusterId && item.IsActive() {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// CleanRestored 清理恢复时间超过keepSeconds的记录
+func (this *Store) CleanRestored(now int64, keepSeconds int64) {
+	var items = []*Maintenance{}
+	for _, item := range this.Items {
+		if !item.IsActive() && item.RestoredAt < now-keepSeconds {
+			continue
+		}
+		items = append(items, item)
+	}
+	this.Items = items
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package maintenanceutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/maintenanceutils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestMaintenance_CheckDrain(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var m = maintenanceutils.NewMaintenance(1, 2, 10, 600, 100)
+	a.IsTrue(m.Status == maintenanceutils.StatusDraining)
+
+	// 进入维护之前上报的状态不可信
+	a.IsFalse(m.CheckDrain(0, 90, 110))
+	a.IsTrue(m.Status == maintenanceutils.StatusDraining)
+
+	a.IsFalse(m.CheckDrain(100, 120, 130))
+	a.IsTrue(m.ConnectionCount == 100)
+
+	a.IsTrue(m.CheckDrain(10, 140, 150))
+	a.IsTrue(m.Status == maintenanceutils.StatusReady)
+	a.IsFalse(m.IsTimeout)
+	a.IsTrue(m.DrainedAt == 150)
+
+	// 已经排空后不再变化
+	a.IsFalse(m.CheckDrain(1000, 160, 170))
+	a.IsTrue(m.ConnectionCount == 10)
+
+	m.Restore(200)
+	a.IsFalse(m.IsActive())
+	a.IsTrue(len(m.Events) == 3)
+}
+
+func TestMaintenance_Timeout(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var m = maintenanceutils.NewMaintenance(1, 2, 0, 60, 100)
+	a.IsFalse(m.CheckDrain(5, 120, 130))
+	a.IsTrue(m.CheckDrain(5, 150, 160))
+	a.IsTrue(m.IsTimeout)
+	a.IsTrue(m.Status == maintenanceutils.StatusReady)
+	t.Log(m.Events[len(m.Events)-1].Message)
+}
+
+func TestMaintenance_Offline(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var m = maintenanceutils.NewMaintenance(1, 2, 0, 60, 100)
+	a.IsTrue(m.MarkOffline(110))
+	a.IsTrue(m.Status == maintenanceutils.StatusReady)
+	a.IsFalse(m.MarkOffline(120))
+}
+
+func TestStore(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var store = maintenanceutils.NewStore()
+	a.IsNil(store.Add(maintenanceutils.NewMaintenance(1, 1, 0, 60, 100)))
+	a.IsTrue(store.Add(maintenanceutils.NewMaintenance(1, 1, 0, 60, 110)) == maintenanceutils.ErrMaintenanceExists)
+	a.IsNil(store.Add(maintenanceutils.NewMaintenance(2, 1, 0, 60, 100)))
+	a.IsTrue(len(store.FindClusterActive(1)) == 2)
+
+	store.FindActive(1).Restore(200)
+	a.IsNil(store.FindActive(1))
+	a.IsNotNil(store.FindLatest(1))
+	a.IsTrue(len(store.FindAllActive()) == 1)
+
+	a.IsNil(store.Add(maintenanceutils.NewMaintenance(1, 1, 0, 60, 300)))
+	a.IsTrue(store.FindLatest(1).StartedAt == 300)
+
+	store.CleanRestored(1000, 100)
+	a.IsTrue(len(store.Items) == 2)
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package passwordpolicyutils
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// 常见的已泄露密码，来自公开的泄露密码排行
+//
+//go:embed breached_passwords.txt
+var breachedPasswordsData string
+
+var breachedPasswords = func() map[string]bool {
+	var result = map[string]bool{}
+	for _, line := range strings.Split(breachedPasswordsData, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			result[strings.ToLower(line)] = true
+		}
+	}
+	return result
+}()
+
+// IsBreached 检查是否为常见的已泄露密码，不区分大小写
+func IsBreached(password string) bool {
+	return breachedPasswords[strings.ToLower(password)]
+}
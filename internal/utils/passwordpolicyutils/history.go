@@ -0,0 +1,161 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package passwordpolicyutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/iwind/TeaGo/types"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// 最多保留的历史密码数量
+const MaxHistoryCount = 24
+
+// 计算历史密码摘要时的迭代次数
+const hashIterations = 20000
+
+// 账号类型
+const (
+	KindAdmin = "admin"
+	KindUser  = "user"
+)
+
+// Record 单个账号的密码记录
+type Record struct {
+	Hashes    []string `json:"hashes"`    // 最近使用过的密码摘要，最新的在前
+	ChangedAt int64    `json:"changedAt"` // 最后修改密码的时间
+}
+
+// History 所有账号的密码记录
+type History struct {
+	Records map[string]*Record `json:"records"` // kind:id => record
+}
+
+// NewHistory 获取新对象
+func NewHistory() *History {
+	return &History{
+		Records: map[string]*Record{},
+	}
+}
+
+// Key 生成账号的键
+func Key(kind string, id int64) string {
+	return kind + ":" + types.String(id)
+}
+
+// Clone 复制对象
+func (this *History) Clone() *History {
+	var history = NewHistory()
+	for key, record := range this.Records {
+		history.Records[key] = &Record{
+			Hashes:    append([]string{}, record.Hashes...),
+			ChangedAt: record.ChangedAt,
+		}
+	}
+	return history
+}
+
+// IsReused 检查密码是否和最近count次使用过的密码相同
+func (this *History) IsReused(key string, password string, count int) bool {
+	record, ok := this.Records[key]
+	if !ok || count <= 0 {
+		return false
+	}
+	for index, hash := range record.Hashes {
+		if index >= count {
+			break
+		}
+		if verifyHash(hash, password) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 记录新密码，最多保留keep个历史密码
+func (this *History) Add(key string, password string, keep int, now time.Time) {
+	if this.Records == nil {
+		this.Records = map[string]*Record{}
+	}
+	record, ok := this.Records[key]
+	if !ok {
+		record = &Record{}
+		this.Records[key] = record
+	}
+	record.ChangedAt = now.Unix()
+
+	if keep <= 0 {
+		record.Hashes = nil
+		return
+	}
+	record.Hashes = append([]string{makeHash(password)}, record.Hashes...)
+	if len(record.Hashes) > keep {
+		record.Hashes = record.Hashes[:keep]
+	}
+}
+
+// Touch 账号没有记录时以当前时间作为最后修改密码的时间，返回是否新建了记录
+func (this *History) Touch(key string, now time.Time) bool {
+	if this.Records == nil {
+		this.Records = map[string]*Record{}
+	}
+	_, ok := this.Records[key]
+	if ok {
+		return false
+	}
+	this.Records[key] = &Record{
+		ChangedAt: now.Unix(),
+	}
+	return true
+}
+
+// IsExpired 检查密码是否已经超过最长使用天数
+func (this *History) IsExpired(key string, maxAgeDays int, now time.Time) bool {
+	if maxAgeDays <= 0 {
+		return false
+	}
+	record, ok := this.Records[key]
+	if !ok || record.ChangedAt <= 0 {
+		return false
+	}
+	return now.Unix()-record.ChangedAt >= int64(maxAgeDays)*86400
+}
+
+// Remove 删除账号的记录
+func (this *History) Remove(key string) {
+	delete(this.Records, key)
+}
+
+// 计算密码摘要，格式为 salt:hash
+func makeHash(password string) string {
+	var salt = make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		panic(err)
+	}
+	var hash = pbkdf2.Key([]byte(password), salt, hashIterations, 32, sha256.New)
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(hash)
+}
+
+func verifyHash(saltAndHash string, password string) bool {
+	saltHex, hashHex, ok := strings.Cut(saltAndHash, ":")
+	if !ok {
+		return false
+	}
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(hashHex)
+	if err != nil {
+		return false
+	}
+	var hash = pbkdf2.Key([]byte(password), salt, hashIterations, 32, sha256.New)
+	return subtle.ConstantTimeCompare(hash, expected) == 1
+}
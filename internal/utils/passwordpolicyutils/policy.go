@@ -0,0 +1,101 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package passwordpolicyutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Policy 密码策略
+type Policy struct {
+	IsOn           bool `json:"isOn"`           // 是否启用
+	MinLength      int  `json:"minLength"`      // 最小长度
+	MinCharClasses int  `json:"minCharClasses"` // 至少包含几类字符：大写字母、小写字母、数字、特殊字符
+	CheckBreached  bool `json:"checkBreached"`  // 是否检查常见的已泄露密码
+	HistoryCount   int  `json:"historyCount"`   // 不能和最近几次使用过的密码相同，0表示不检查
+	MaxAgeDays     int  `json:"maxAgeDays"`     // 密码最长使用天数，超过后下次登录时必须修改，0表示不限制
+	ApplyToUsers   bool `json:"applyToUsers"`   // 是否同时适用于平台用户
+}
+
+// NewPolicy 获取新对象
+func NewPolicy() *Policy {
+	return &Policy{
+		IsOn:           false,
+		MinLength:      8,
+		MinCharClasses: 3,
+		CheckBreached:  true,
+		HistoryCount:   5,
+		MaxAgeDays:     0,
+		ApplyToUsers:   true,
+	}
+}
+
+// Validate 校验策略
+func (this *Policy) Validate() error {
+	if this.MinLength < 1 || this.MinLength > 64 {
+		return errors.New("密码最小长度需要在1到64之间")
+	}
+	if this.MinCharClasses < 0 || this.MinCharClasses > 4 {
+		return errors.New("字符类别数需要在0到4之间")
+	}
+	if this.HistoryCount < 0 || this.HistoryCount > MaxHistoryCount {
+		return fmt.Errorf("历史密码数量需要在0到%d之间", MaxHistoryCount)
+	}
+	if this.MaxAgeDays < 0 || this.MaxAgeDays > 3650 {
+		return errors.New("密码最长使用天数需要在0到3650之间")
+	}
+	return nil
+}
+
+// Check 检查密码是否符合策略，不检查历史密码
+func (this *Policy) Check(password string, username string) error {
+	if !this.IsOn {
+		return nil
+	}
+
+	if len([]rune(password)) < this.MinLength {
+		return fmt.Errorf("密码长度不能少于%d个字符", this.MinLength)
+	}
+
+	if this.MinCharClasses > 0 && CountCharClasses(password) < this.MinCharClasses {
+		return fmt.Errorf("密码至少需要包含大写字母、小写字母、数字、特殊字符中的%d类", this.MinCharClasses)
+	}
+
+	if len(username) > 0 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("密码中不能包含用户名")
+	}
+
+	if this.CheckBreached && IsBreached(password) {
+		return errors.New("此密码属于常见的已泄露密码，请换一个")
+	}
+
+	return nil
+}
+
+// CountCharClasses 计算密码中包含的字符类别数
+func CountCharClasses(password string) int {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	var count = 0
+	for _, b := range []bool{hasUpper, hasLower, hasDigit, hasSymbol} {
+		if b {
+			count++
+		}
+	}
+	return count
+}
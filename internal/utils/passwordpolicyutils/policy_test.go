@@ -0,0 +1,69 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package passwordpolicyutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	var policy = NewPolicy()
+	policy.IsOn = true
+
+	for password, ok := range map[string]bool{
+		"short1A":          false, // 长度不够
+		"alllowercase":     false, // 字符类别不够
+		"Password123":      false, // 已泄露
+		"P@ssw0rd":         false, // 已泄露
+		"Admin-Edge-2024":  false, // 包含用户名
+		"Blue-Horse-Cloud": true,
+		"k9#Tmq2vLx":       true,
+	} {
+		var err = policy.Check(password, "admin")
+		if ok && err != nil {
+			t.Fatal(password, "should pass:", err)
+		}
+		if !ok && err == nil {
+			t.Fatal(password, "should fail")
+		}
+	}
+
+	policy.IsOn = false
+	if policy.Check("1", "admin") != nil {
+		t.Fatal("disabled policy should not check anything")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var history = NewHistory()
+	var key = Key(KindAdmin, 1)
+	var now = time.Now()
+
+	for _, password := range []string{"first-Pass1", "second-Pass2", "third-Pass3"} {
+		history.Add(key, password, 2, now)
+	}
+	if history.IsReused(key, "first-Pass1", 2) {
+		t.Fatal("first password should have been dropped")
+	}
+	if !history.IsReused(key, "second-Pass2", 2) || !history.IsReused(key, "third-Pass3", 2) {
+		t.Fatal("recent passwords should be found")
+	}
+	if history.IsReused(key, "second-Pass2", 1) {
+		t.Fatal("only the latest password should be checked")
+	}
+	if history.IsReused(Key(KindUser, 1), "third-Pass3", 2) {
+		t.Fatal("history should be separated by kind")
+	}
+
+	// 过期
+	if history.IsExpired(key, 30, now.Add(29*24*time.Hour)) {
+		t.Fatal("password should not be expired")
+	}
+	if !history.IsExpired(key, 30, now.Add(30*24*time.Hour)) {
+		t.Fatal("password should be expired")
+	}
+	if !history.Touch(Key(KindAdmin, 2), now) || history.Touch(Key(KindAdmin, 2), now) {
+		t.Fatal("touch should create record only once")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provisionutils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultInstallDir 默认安装目录
+const DefaultInstallDir = "/usr/local/goedge"
+
+// BootstrapScriptPath 通过cloud-init写入的脚本路径
+const BootstrapScriptPath = "/root/goedge-bootstrap.sh"
+
+// BootstrapParams 生成安装脚本的参数
+type BootstrapParams struct {
+	AdminURL        string   // 管理平台访问地址，用来下载安装包和注册节点
+	Token           string   // 注册令牌
+	ClusterUniqueId string   // 集群ID
+	ClusterSecret   string   // 集群密钥，为空时不生成自动注册的备用配置
+	Endpoints       []string // API节点地址
+	InstallDir      string   // 安装目录
+}
+
+func (this *BootstrapParams) validate() error {
+	if len(this.AdminURL) == 0 {
+		return errors.New("'AdminURL' should not be empty")
+	}
+	if len(this.Token) == 0 {
+		return errors.New("'Token' should not be empty")
+	}
+	if len(this.ClusterUniqueId) == 0 {
+		return errors.New("'ClusterUniqueId' should not be empty")
+	}
+	if len(this.Endpoints) == 0 {
+		return errors.New("'Endpoints' should not be empty")
+	}
+	return nil
+}
+
+var bootstrapTemplate = template.Must(template.New("bootstrap").Funcs(template.FuncMap{
+	"quote": ShellQuote,
+	"yamlList": func(values []string) string {
+		var pieces = []string{}
+		for _, value := range values {
+			pieces = append(pieces, `"`+strings.ReplaceAll(value, `"`, `\"`)+`"`)
+		}
+		return "[ " + strings.Join(pieces, ", ") + " ]"
+	},
+}).Parse(`#!/usr/bin/env bash
+# GoEdge节点自动安装脚本，由管理平台生成
+
+set -e
+
+ADMIN_URL={{quote .AdminURL}}
+TOKEN={{quote .Token}}
+CLUSTER_ID={{quote .ClusterUniqueId}}
+INSTALL_DIR={{quote .InstallDir}}
+
+case "$(uname -m)" in
+	x86_64|amd64) ARCH="amd64" ;;
+	aarch64|arm64) ARCH="arm64" ;;
+	mips64) ARCH="mips64" ;;
+	mips64el|mips64le) ARCH="mips64le" ;;
+	*) echo "unsupported arch: $(uname -m)"; exit 1 ;;
+esac
+
+NODE_NAME="$(hostname)"
+NODE_IP="$(hostname -I 2>/dev/null | awk '{print $1}')"
+
+# 下载并解压安装包
+mkdir -p "${INSTALL_DIR}"
+curl -fsSL --retry 5 -o /tmp/edge-node.zip "${ADMIN_URL}/provision/installer?token=${TOKEN}&os=linux&arch=${ARCH}"
+unzip -o -q /tmp/edge-node.zip -d "${INSTALL_DIR}"
+rm -f /tmp/edge-node.zip
+CONFIG_DIR="${INSTALL_DIR}/edge-node/configs"
+mkdir -p "${CONFIG_DIR}"
+
+# 使用令牌注册节点
+if curl -fsSL --retry 5 -o "${CONFIG_DIR}/api_node.yaml.tmp" -X POST \
+	--data-urlencode "token=${TOKEN}" \
+	--data-urlencode "clusterId=${CLUSTER_ID}" \
+	--data-urlencode "name=${NODE_NAME}" \
+	--data-urlencode "ip=${NODE_IP}" \
+	"${ADMIN_URL}/provision/register"; then
+	mv "${CONFIG_DIR}/api_node.yaml.tmp" "${CONFIG_DIR}/api_node.yaml"
+{{- if .ClusterSecret}}
+else
+	# 注册失败时使用集群密钥自动注册
+	rm -f "${CONFIG_DIR}/api_node.yaml.tmp"
+	cat > "${CONFIG_DIR}/api_cluster.yaml" <<'GOEDGE_EOF'
+rpc.endpoints: {{yamlList .Endpoints}}
+clusterId: "{{.ClusterUniqueId}}"
+secret: "{{.ClusterSecret}}"
+GOEDGE_EOF
+{{- else}}
+else
+	rm -f "${CONFIG_DIR}/api_node.yaml.tmp"
+	echo "register node failed"
+	exit 1
+{{- end}}
+fi
+
+# 启动
+chmod +x "${INSTALL_DIR}/edge-node/bin/edge-node"
+"${INSTALL_DIR}/edge-node/bin/edge-node" start
+`))
+
+// BuildShellScript 生成Shell安装脚本
+func BuildShellScript(params *BootstrapParams) (string, error) {
+	err := params.validate()
+	if err != nil {
+		return "", err
+	}
+	if len(params.InstallDir) == 0 {
+		params.InstallDir = DefaultInstallDir
+	}
+	params.AdminURL = strings.TrimRight(params.AdminURL, "/")
+
+	var buf = &bytes.Buffer{}
+	err = bootstrapTemplate.Execute(buf, params)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type cloudInitFile struct {
+	Path        string `yaml:"path"`
+	Permissions string `yaml:"permissions"`
+	Owner       string `yaml:"owner"`
+	Content     string `yaml:"content"`
+}
+
+type cloudInitConfig struct {
+	Packages   []string        `yaml:"packages"`
+	WriteFiles []cloudInitFile `yaml:"write_files"`
+	RunCmd     [][]string      `yaml:"runcmd"`
+}
+
+// BuildCloudInit 生成cloud-init的user-data
+func BuildCloudInit(params *BootstrapParams) (string, error) {
+	script, err := BuildShellScript(params)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(&cloudInitConfig{
+		Packages: []string{"curl", "unzip"},
+		WriteFiles: []cloudInitFile{
+			{
+				Path:        BootstrapScriptPath,
+				Permissions: "0700",
+				Owner:       "root:root",
+				Content:     script,
+			},
+		},
+		RunCmd: [][]string{
+			{"bash", BootstrapScriptPath},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return "#cloud-config\n" + string(data), nil
+}
+
+// ShellQuote 使用单引号包裹Shell参数
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provisionutils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/provisionutils"
+	"github.com/iwind/TeaGo/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTokenStore(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var store = provisionutils.NewTokenStore()
+	plainToken, err := store.Create(&provisionutils.Token{
+		ClusterId: 1,
+		MaxUses:   2,
+		ExpiresAt: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(plainToken)
+	a.IsTrue(len(store.Tokens) == 1)
+	a.IsTrue(store.Tokens[0].Hash != plainToken)
+
+	_, err = store.Check("abc", 10)
+	a.IsTrue(err == provisionutils.ErrTokenNotFound)
+
+	_, err = store.Check(plainToken, 10)
+	a.IsNil(err)
+	a.IsTrue(store.Tokens[0].Uses == 0)
+
+	_, err = store.Consume(plainToken, 10)
+	a.IsNil(err)
+	_, err = store.Consume(plainToken, 10)
+	a.IsNil(err)
+	_, err = store.Consume(plainToken, 10)
+	a.IsTrue(err == provisionutils.ErrTokenUsedUp)
+
+	plainToken2, err := store.Create(&provisionutils.Token{ClusterId: 2, ExpiresAt: 100})
+	a.IsNil(err)
+	_, err = store.Consume(plainToken2, 101)
+	a.IsTrue(err == provisionutils.ErrTokenExpired)
+	a.IsTrue(len(store.FindClusterTokens(2)) == 1)
+
+	store.CleanExpired(150, 10)
+	a.IsTrue(len(store.Tokens) == 0)
+}
+
+func TestBuildCloudInit(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var params = &provisionutils.BootstrapParams{
+		AdminURL:        "http://192.168.1.100:7788/",
+		Token:           "abc'def",
+		ClusterUniqueId: "cluster-1",
+		ClusterSecret:   "secret-1",
+		Endpoints:       []string{"http://192.168.1.100:8001"},
+	}
+	script, err := provisionutils.BuildShellScript(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(script)
+	a.IsTrue(strings.Contains(script, `TOKEN='abc'\''def'`))
+	a.IsTrue(strings.Contains(script, `ADMIN_URL='http://192.168.1.100:7788'`))
+	a.IsTrue(strings.Contains(script, `rpc.endpoints: [ "http://192.168.1.100:8001" ]`))
+	a.IsTrue(strings.Contains(script, provisionutils.DefaultInstallDir))
+
+	userData, err := provisionutils.BuildCloudInit(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(userData)
+	a.IsTrue(strings.HasPrefix(userData, "#cloud-config\n"))
+
+	var config = map[string]any{}
+	err = yaml.Unmarshal([]byte(userData), &config)
+	a.IsNil(err)
+	var files = config["write_files"].([]any)
+	a.IsTrue(files[0].(map[string]any)["content"] == script)
+
+	// 不内嵌集群密钥
+	params.ClusterSecret = ""
+	script, err = provisionutils.BuildShellScript(params)
+	a.IsNil(err)
+	a.IsFalse(strings.Contains(script, "api_cluster.yaml"))
+
+	// 缺少参数
+	_, err = provisionutils.BuildShellScript(&provisionutils.BootstrapParams{})
+	a.IsNotNil(err)
+}
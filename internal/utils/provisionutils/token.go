@@ -0,0 +1,158 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provisionutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/iwind/TeaGo/rands"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenExpired  = errors.New("token expired")
+	ErrTokenUsedUp   = errors.New("token has been used up")
+)
+
+// Token 节点注册令牌
+// 只保存令牌的摘要，令牌明文只在创建时显示一次
+type Token struct {
+	Id          string  `json:"id"`
+	Hash        string  `json:"hash"`
+	ClusterId   int64   `json:"clusterId"`
+	GroupId     int64   `json:"groupId"`
+	RegionId    int64   `json:"regionId"`
+	MaxUses     int     `json:"maxUses"`
+	Uses        int     `json:"uses"`
+	ExpiresAt   int64   `json:"expiresAt"`
+	CreatedAt   int64   `json:"createdAt"`
+	Description string  `json:"description"`
+	NodeIds     []int64 `json:"nodeIds"` // 已注册的节点
+}
+
+// IsValid 检查令牌是否可用
+func (this *Token) IsValid(now int64) error {
+	if this.ExpiresAt > 0 && this.ExpiresAt < now {
+		return ErrTokenExpired
+	}
+	if this.MaxUses > 0 && this.Uses >= this.MaxUses {
+		return ErrTokenUsedUp
+	}
+	return nil
+}
+
+// TokenStore 令牌列表
+type TokenStore struct {
+	Tokens []*Token `json:"tokens"`
+}
+
+// NewTokenStore 获取新对象
+func NewTokenStore() *TokenStore {
+	return &TokenStore{
+		Tokens: []*Token{},
+	}
+}
+
+// Create 创建新的令牌，返回令牌明文
+func (this *TokenStore) Create(token *Token) (string, error) {
+	plainToken, err := newPlainToken()
+	if err != nil {
+		return "", err
+	}
+	token.Id = rands.HexString(16)
+	token.Hash = HashToken(plainToken)
+	if token.NodeIds == nil {
+		token.NodeIds = []int64{}
+	}
+	this.Tokens = append(this.Tokens, token)
+	return plainToken, nil
+}
+
+// Check 检查令牌是否可用，但不消耗使用次数
+func (this *TokenStore) Check(plainToken string, now int64) (*Token, error) {
+	var token = this.findWithPlainToken(plainToken)
+	if token == nil {
+		return nil, ErrTokenNotFound
+	}
+	err := token.IsValid(now)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Consume 使用令牌
+func (this *TokenStore) Consume(plainToken string, now int64) (*Token, error) {
+	token, err := this.Check(plainToken, now)
+	if err != nil {
+		return nil, err
+	}
+	token.Uses++
+	return token, nil
+}
+
+// Delete 删除令牌
+func (this *TokenStore) Delete(tokenId string) {
+	var tokens = []*Token{}
+	for _, token := range this.Tokens {
+		if token.Id != tokenId {
+			tokens = append(tokens, token)
+		}
+	}
+	this.Tokens = tokens
+}
+
+// FindClusterTokens 查找某个集群的令牌
+func (this *TokenStore) FindClusterTokens(clusterId int64) []*Token {
+	var result = []*Token{}
+	for _, token := range this.Tokens {
+		if token.ClusterId == clusterId {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// CleanExpired 清除过期超过 keepSeconds 秒的令牌
+func (this *TokenStore) CleanExpired(now int64, keepSeconds int64) {
+	var tokens = []*Token{}
+	for _, token := range this.Tokens {
+		if token.ExpiresAt > 0 && token.ExpiresAt+keepSeconds < now {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	this.Tokens = tokens
+}
+
+func (this *TokenStore) findWithPlainToken(plainToken string) *Token {
+	if len(plainToken) == 0 {
+		return nil
+	}
+	var hash = HashToken(plainToken)
+	for _, token := range this.Tokens {
+		if token.Hash == hash {
+			return token
+		}
+	}
+	return nil
+}
+
+// HashToken 计算令牌摘要
+func HashToken(plainToken string) string {
+	var sum = sha256.Sum256([]byte(plainToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// 生成令牌明文，令牌用于认证，所以需要使用 crypto/rand
+func newPlainToken() (string, error) {
+	var data = make([]byte, 24)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
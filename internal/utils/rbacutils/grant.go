@@ -0,0 +1,88 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rbacutils
+
+// Grant 管理员通过角色获得的授权，多个角色的授权取并集
+type Grant struct {
+	roles []*Role
+}
+
+// AllowModule 是否可以访问某个模块
+func (this *Grant) AllowModule(moduleCode string) bool {
+	for _, role := range this.roles {
+		if len(role.Permission(moduleCode)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ModuleCodes 可以访问的模块，按角色中的顺序排列
+func (this *Grant) ModuleCodes() []string {
+	var result = []string{}
+	var codeMap = map[string]bool{}
+	for _, role := range this.roles {
+		for _, module := range role.Modules {
+			if !codeMap[module.Code] {
+				codeMap[module.Code] = true
+				result = append(result, module.Code)
+			}
+		}
+	}
+	return result
+}
+
+// Allow 检查是否可以使用某个权限访问资源
+// 有资源范围限制的角色：涉及具体资源时需要每个资源都在范围之内；不涉及具体资源时（比如列表页）不允许访问，
+// 需要由动作实现 ScopeFilterAction 按照 ReadScope() 过滤数据；涉及无法确定所属范围的资源时不允许写
+func (this *Grant) Allow(moduleCode string, permission string, resource *Resource) bool {
+	for _, role := range this.roles {
+		var rolePermission = role.Permission(moduleCode)
+		if len(rolePermission) == 0 {
+			continue
+		}
+		if permission == PermissionWrite && rolePermission != PermissionWrite {
+			continue
+		}
+		if role.Scope.IsEmpty() {
+			return true
+		}
+		if permission == PermissionWrite && !resource.IsResolved() {
+			continue
+		}
+		if role.Scope.Match(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadScope 可以读取某个模块中的哪些资源，多个角色的范围取并集
+// 没有读权限时返回nil，有不限制范围的角色时返回空范围
+func (this *Grant) ReadScope(moduleCode string) *Scope {
+	var result *Scope
+	for _, role := range this.roles {
+		if len(role.Permission(moduleCode)) == 0 {
+			continue
+		}
+		if role.Scope.IsEmpty() {
+			return &Scope{}
+		}
+		if result == nil {
+			result = &Scope{}
+		}
+		result.ClusterIds = appendUnique(result.ClusterIds, role.Scope.ClusterIds)
+		result.ServerGroupIds = appendUnique(result.ServerGroupIds, role.Scope.ServerGroupIds)
+		result.UserIds = appendUnique(result.UserIds, role.Scope.UserIds)
+	}
+	return result
+}
+
+func appendUnique(ids []int64, newIds []int64) []int64 {
+	for _, id := range newIds {
+		if !containsAny(ids, []int64{id}) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rbacutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+)
+
+func TestGrant_Allow(t *testing.T) {
+	var policy = rbacutils.NewPolicy()
+
+	// NOC：所有集群只读
+	_, err := policy.AddRole(&rbacutils.Role{
+		Name: "NOC",
+		Modules: []*rbacutils.ModulePermission{
+			{Code: "node", Permission: rbacutils.PermissionRead},
+			{Code: "server", Permission: rbacutils.PermissionRead},
+		},
+		AdminIds: []int64{1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 区域团队：只管理自己的集群
+	_, err = policy.AddRole(&rbacutils.Role{
+		Name: "Region",
+		Modules: []*rbacutils.ModulePermission{
+			{Code: "node", Permission: rbacutils.PermissionWrite},
+		},
+		Scope:    &rbacutils.Scope{ClusterIds: []int64{10}},
+		AdminIds: []int64{2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cluster10 = rbacutils.NewClusterResource(10)
+	var cluster11 = rbacutils.NewClusterResource(11)
+
+	// NOC
+	{
+		var grant = policy.FindAdminGrant(1)
+		if grant == nil {
+			t.Fatal("grant should not be nil")
+		}
+		if !grant.AllowModule("node") || grant.AllowModule("dns") {
+			t.Fatal("unexpected modules")
+		}
+		if !grant.Allow("node", rbacutils.PermissionRead, cluster11) {
+			t.Fatal("should allow read")
+		}
+		if grant.Allow("node", rbacutils.PermissionWrite, cluster10) {
+			t.Fatal("should deny write")
+		}
+	}
+
+	// NOC + Region
+	{
+		var grant = policy.FindAdminGrant(2)
+		if !grant.Allow("node", rbacutils.PermissionWrite, cluster10) {
+			t.Fatal("should allow write to own cluster")
+		}
+		if grant.Allow("node", rbacutils.PermissionWrite, cluster11) {
+			t.Fatal("should deny write to other cluster")
+		}
+		if !grant.Allow("node", rbacutils.PermissionRead, cluster11) {
+			t.Fatal("should allow read to other cluster")
+		}
+		if grant.Allow("node", rbacutils.PermissionWrite, nil) {
+			t.Fatal("should deny write without resource")
+		}
+		if grant.Allow("server", rbacutils.PermissionWrite, cluster10) {
+			t.Fatal("should deny write to servers")
+		}
+		if !grant.Allow("node", rbacutils.PermissionRead, nil) {
+			t.Fatal("should allow list pages with an unlimited role")
+		}
+	}
+
+	// 没有角色
+	if policy.FindAdminGrant(3) != nil {
+		t.Fatal("grant should be nil")
+	}
+
+	// 删除管理员
+	policy.RemoveAdmin(2)
+	if len(policy.FindAdminRoles(2)) != 0 {
+		t.Fatal("roles should be empty")
+	}
+}
+
+func TestGrant_Allow_Spoofing(t *testing.T) {
+	var policy = rbacutils.NewPolicy()
+	_, err := policy.AddRole(&rbacutils.Role{
+		Name: "Region",
+		Modules: []*rbacutils.ModulePermission{
+			{Code: "server", Permission: rbacutils.PermissionWrite},
+		},
+		Scope:    &rbacutils.Scope{ClusterIds: []int64{10}},
+		AdminIds: []int64{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grant = policy.FindAdminGrant(1)
+
+	// 客户端传入范围内的 clusterId，但是网站实际属于其他集群
+	var spoofed = rbacutils.NewClusterResource(10)
+	spoofed.AddObject(&rbacutils.Object{ClusterIds: []int64{11}})
+	if grant.Allow("server", rbacutils.PermissionWrite, spoofed) {
+		t.Fatal("should deny write to server in other cluster")
+	}
+	if grant.Allow("server", rbacutils.PermissionRead, spoofed) {
+		t.Fatal("should deny read of server in other cluster")
+	}
+
+	// 找不到所属集群的网站
+	var unknown = rbacutils.NewClusterResource(10)
+	unknown.AddObject(&rbacutils.Object{})
+	if grant.Allow("server", rbacutils.PermissionWrite, unknown) {
+		t.Fatal("should deny write to unknown server")
+	}
+
+	// 网站属于范围内的集群
+	var own = rbacutils.NewClusterResource(10)
+	own.AddObject(&rbacutils.Object{ClusterIds: []int64{10}, ServerGroupIds: []int64{1}})
+	if !grant.Allow("server", rbacutils.PermissionWrite, own) {
+		t.Fatal("should allow write to own server")
+	}
+
+	// 涉及无法确定所属范围的对象
+	own.AddUnresolved("locationId")
+	if grant.Allow("server", rbacutils.PermissionWrite, own) {
+		t.Fatal("should deny write with unresolved objects")
+	}
+	if !grant.Allow("server", rbacutils.PermissionRead, own) {
+		t.Fatal("should allow read with unresolved objects")
+	}
+	var unresolved = &rbacutils.Resource{}
+	unresolved.AddUnresolved("policyId")
+	if grant.Allow("server", rbacutils.PermissionWrite, unresolved) {
+		t.Fatal("should deny write with only unresolved objects")
+	}
+}
+
+func TestGrant_ReadScope(t *testing.T) {
+	var policy = rbacutils.NewPolicy()
+	for _, role := range []*rbacutils.Role{
+		{
+			Name:     "Region A",
+			Modules:  []*rbacutils.ModulePermission{{Code: "server", Permission: rbacutils.PermissionWrite}},
+			Scope:    &rbacutils.Scope{ClusterIds: []int64{10}},
+			AdminIds: []int64{1, 2},
+		},
+		{
+			Name:     "Region B",
+			Modules:  []*rbacutils.ModulePermission{{Code: "server", Permission: rbacutils.PermissionRead}},
+			Scope:    &rbacutils.Scope{ClusterIds: []int64{10, 11}, UserIds: []int64{5}},
+			AdminIds: []int64{1},
+		},
+		{
+			Name:     "NOC",
+			Modules:  []*rbacutils.ModulePermission{{Code: "server", Permission: rbacutils.PermissionRead}},
+			AdminIds: []int64{2},
+		},
+	} {
+		_, err := policy.AddRole(role)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 有资源范围限制时，列表页等不涉及具体对象的请求不能直接访问，只能按照范围过滤
+	var grant = policy.FindAdminGrant(1)
+	if grant.Allow("server", rbacutils.PermissionRead, nil) || grant.Allow("server", rbacutils.PermissionRead, &rbacutils.Resource{}) {
+		t.Fatal("should deny list pages with scoped roles")
+	}
+	var scope = grant.ReadScope("server")
+	if scope == nil || len(scope.ClusterIds) != 2 || len(scope.UserIds) != 1 {
+		t.Fatalf("unexpected scope: %+v", scope)
+	}
+	if grant.ReadScope("node") != nil {
+		t.Fatal("should be nil without permission")
+	}
+
+	// 有不限制范围的角色
+	grant = policy.FindAdminGrant(2)
+	if !grant.ReadScope("server").IsEmpty() {
+		t.Fatal("scope should be empty")
+	}
+}
+
+func TestRole_Validate(t *testing.T) {
+	var policy = rbacutils.NewPolicy()
+	_, err := policy.AddRole(&rbacutils.Role{Name: "a"})
+	if err == nil {
+		t.Fatal("should fail without modules")
+	}
+	_, err = policy.AddRole(&rbacutils.Role{
+		Name:    "a",
+		Modules: []*rbacutils.ModulePermission{{Code: "node", Permission: "admin"}},
+	})
+	if err == nil {
+		t.Fatal("should fail with invalid permission")
+	}
+	err = policy.UpdateRole(&rbacutils.Role{
+		Id:      100,
+		Name:    "a",
+		Modules: []*rbacutils.ModulePermission{{Code: "node", Permission: rbacutils.PermissionRead}},
+	})
+	if err != rbacutils.ErrRoleNotFound {
+		t.Fatal("should be not found")
+	}
+}
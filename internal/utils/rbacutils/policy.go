@@ -0,0 +1,136 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rbacutils
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+// Policy 所有角色定义
+type Policy struct {
+	Roles []*Role `json:"roles"`
+}
+
+// NewPolicy 获取新对象
+func NewPolicy() *Policy {
+	return &Policy{
+		Roles: []*Role{},
+	}
+}
+
+// Clone 复制对象，用于修改前避免影响共享对象
+func (this *Policy) Clone() *Policy {
+	var policy = NewPolicy()
+	for _, role := range this.Roles {
+		var newRole = *role
+		newRole.Modules = []*ModulePermission{}
+		for _, module := range role.Modules {
+			var newModule = *module
+			newRole.Modules = append(newRole.Modules, &newModule)
+		}
+		if role.Scope != nil {
+			newRole.Scope = &Scope{
+				ClusterIds:     append([]int64{}, role.Scope.ClusterIds...),
+				ServerGroupIds: append([]int64{}, role.Scope.ServerGroupIds...),
+				UserIds:        append([]int64{}, role.Scope.UserIds...),
+			}
+		}
+		newRole.AdminIds = append([]int64{}, role.AdminIds...)
+		policy.Roles = append(policy.Roles, &newRole)
+	}
+	return policy
+}
+
+// FindRole 查找角色
+func (this *Policy) FindRole(roleId int64) *Role {
+	for _, role := range this.Roles {
+		if role.Id == roleId {
+			return role
+		}
+	}
+	return nil
+}
+
+// AddRole 添加角色，并返回新的角色ID
+func (this *Policy) AddRole(role *Role) (int64, error) {
+	err := role.Validate()
+	if err != nil {
+		return 0, err
+	}
+
+	var maxId int64
+	for _, r := range this.Roles {
+		if r.Id > maxId {
+			maxId = r.Id
+		}
+	}
+	role.Id = maxId + 1
+	role.CreatedAt = time.Now().Unix()
+	role.UpdatedAt = role.CreatedAt
+	this.Roles = append(this.Roles, role)
+	return role.Id, nil
+}
+
+// UpdateRole 修改角色
+func (this *Policy) UpdateRole(role *Role) error {
+	err := role.Validate()
+	if err != nil {
+		return err
+	}
+	for index, r := range this.Roles {
+		if r.Id == role.Id {
+			role.CreatedAt = r.CreatedAt
+			role.UpdatedAt = time.Now().Unix()
+			this.Roles[index] = role
+			return nil
+		}
+	}
+	return ErrRoleNotFound
+}
+
+// DeleteRole 删除角色
+func (this *Policy) DeleteRole(roleId int64) {
+	var roles = []*Role{}
+	for _, role := range this.Roles {
+		if role.Id != roleId {
+			roles = append(roles, role)
+		}
+	}
+	this.Roles = roles
+}
+
+// RemoveAdmin 从所有角色中移除某个管理员，比如管理员被删除时
+func (this *Policy) RemoveAdmin(adminId int64) {
+	for _, role := range this.Roles {
+		var adminIds = []int64{}
+		for _, id := range role.AdminIds {
+			if id != adminId {
+				adminIds = append(adminIds, id)
+			}
+		}
+		role.AdminIds = adminIds
+	}
+}
+
+// FindAdminRoles 查找分配给某个管理员的所有角色
+func (this *Policy) FindAdminRoles(adminId int64) []*Role {
+	var result = []*Role{}
+	for _, role := range this.Roles {
+		if role.HasAdmin(adminId) {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
+// FindAdminGrant 计算某个管理员的授权，没有分配角色时返回nil
+func (this *Policy) FindAdminGrant(adminId int64) *Grant {
+	var roles = this.FindAdminRoles(adminId)
+	if len(roles) == 0 {
+		return nil
+	}
+	return &Grant{roles: roles}
+}
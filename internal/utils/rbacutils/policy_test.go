@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rbacutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+)
+
+func TestPolicy_Clone(t *testing.T) {
+	var policy = rbacutils.NewPolicy()
+	roleId, err := policy.AddRole(&rbacutils.Role{
+		Name: "Region",
+		Modules: []*rbacutils.ModulePermission{
+			{Code: "node", Permission: rbacutils.PermissionWrite},
+		},
+		Scope:    &rbacutils.Scope{ClusterIds: []int64{1}},
+		AdminIds: []int64{3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cloned = policy.Clone()
+	var clonedRole = cloned.FindRole(roleId)
+	if clonedRole == nil || clonedRole.Name != "Region" {
+		t.Fatal("role should be cloned")
+	}
+	clonedRole.Modules[0].Permission = rbacutils.PermissionRead
+	clonedRole.Scope.ClusterIds[0] = 2
+	clonedRole.AdminIds[0] = 4
+	cloned.DeleteRole(roleId)
+
+	var role = policy.FindRole(roleId)
+	if role == nil {
+		t.Fatal("clone should not share roles")
+	}
+	if role.Modules[0].Permission != rbacutils.PermissionWrite || role.Scope.ClusterIds[0] != 1 || role.AdminIds[0] != 3 {
+		t.Fatal("clone should not share role fields")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rbacutils
+
+import (
+	"errors"
+	"strings"
+)
+
+// 权限
+const (
+	PermissionRead  = "read"  // 只读
+	PermissionWrite = "write" // 读写
+)
+
+// PermissionAction 可以在动作中实现此接口，明确声明动作需要的权限
+// 没有实现时GET请求需要只读权限，其他请求根据动作名称判断
+type PermissionAction interface {
+	RBACPermission() string
+}
+
+// ScopeFilterAction 不涉及具体对象的动作（比如列表页）可以实现此接口，按照资源范围自行过滤数据
+// 有资源范围限制的角色只能访问实现了此接口的此类动作
+type ScopeFilterAction interface {
+	RBACFilterScope(scope *Scope)
+}
+
+// ModulePermission 某个模块的权限
+type ModulePermission struct {
+	Code       string `json:"code"`
+	Permission string `json:"permission"`
+}
+
+// Role 角色定义，可以分配给多个管理员
+type Role struct {
+	Id          int64               `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Modules     []*ModulePermission `json:"modules"`
+	Scope       *Scope              `json:"scope"`    // 资源范围，为空表示不限制
+	AdminIds    []int64             `json:"adminIds"` // 分配的管理员
+	CreatedAt   int64               `json:"createdAt"`
+	UpdatedAt   int64               `json:"updatedAt"`
+}
+
+// Validate 检查角色定义
+func (this *Role) Validate() error {
+	this.Name = strings.TrimSpace(this.Name)
+	if len(this.Name) == 0 {
+		return errors.New("role name should not be empty")
+	}
+	var codeMap = map[string]bool{}
+	for _, module := range this.Modules {
+		if len(module.Code) == 0 {
+			return errors.New("module code should not be empty")
+		}
+		if codeMap[module.Code] {
+			return errors.New("duplicate module '" + module.Code + "'")
+		}
+		codeMap[module.Code] = true
+		if module.Permission != PermissionRead && module.Permission != PermissionWrite {
+			return errors.New("invalid permission '" + module.Permission + "' for module '" + module.Code + "'")
+		}
+	}
+	if len(this.Modules) == 0 {
+		return errors.New("role should contain at least one module")
+	}
+	return nil
+}
+
+// Permission 某个模块的权限，没有权限时返回空
+func (this *Role) Permission(moduleCode string) string {
+	for _, module := range this.Modules {
+		if module.Code == moduleCode {
+			return module.Permission
+		}
+	}
+	return ""
+}
+
+// HasAdmin 是否已分配给某个管理员
+func (this *Role) HasAdmin(adminId int64) bool {
+	for _, id := range this.AdminIds {
+		if id == adminId {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope 资源范围，多个条件之间为“或”的关系
+type Scope struct {
+	ClusterIds     []int64 `json:"clusterIds"`
+	ServerGroupIds []int64 `json:"serverGroupIds"`
+	UserIds        []int64 `json:"userIds"`
+}
+
+// IsEmpty 是否没有限制
+func (this *Scope) IsEmpty() bool {
+	return this == nil || (len(this.ClusterIds) == 0 && len(this.ServerGroupIds) == 0 && len(this.UserIds) == 0)
+}
+
+// Match 判断资源是否在范围之内，涉及的每个对象都需要在范围之内
+func (this *Scope) Match(resource *Resource) bool {
+	if this.IsEmpty() {
+		return true
+	}
+	if !resource.HasObjects() {
+		return false
+	}
+	for _, object := range resource.Objects {
+		if !this.matchObject(object) {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *Scope) matchObject(object *Object) bool {
+	return containsAny(this.ClusterIds, object.ClusterIds) ||
+		containsAny(this.ServerGroupIds, object.ServerGroupIds) ||
+		containsAny(this.UserIds, object.UserIds)
+}
+
+// Object 请求涉及的某个对象，以及对象实际所属的集群、网站分组和用户
+type Object struct {
+	ClusterIds     []int64
+	ServerGroupIds []int64
+	UserIds        []int64
+}
+
+// Resource 当前请求涉及的资源
+type Resource struct {
+	Objects    []*Object
+	Unresolved []string // 无法确定所属范围的参数，比如 locationId、policyId 等
+}
+
+// NewClusterResource 涉及某些集群的资源
+func NewClusterResource(clusterIds ...int64) *Resource {
+	var resource = &Resource{}
+	for _, clusterId := range clusterIds {
+		resource.AddObject(&Object{ClusterIds: []int64{clusterId}})
+	}
+	return resource
+}
+
+// AddObject 添加涉及的对象
+func (this *Resource) AddObject(object *Object) {
+	this.Objects = append(this.Objects, object)
+}
+
+// AddUnresolved 添加无法确定所属范围的参数
+func (this *Resource) AddUnresolved(param string) {
+	this.Unresolved = append(this.Unresolved, param)
+}
+
+// HasObjects 是否涉及具体的对象，列表页等不涉及具体对象
+func (this *Resource) HasObjects() bool {
+	return this != nil && len(this.Objects) > 0
+}
+
+// IsResolved 涉及的对象是否都已经确定所属范围
+func (this *Resource) IsResolved() bool {
+	return this == nil || len(this.Unresolved) == 0
+}
+
+func containsAny(ids1 []int64, ids2 []int64) bool {
+	for _, id1 := range ids1 {
+		for _, id2 := range ids2 {
+			if id1 == id2 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
+)
+
+// 邮件客户端大多不支持外部样式，所以样式直接写在元素上
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes": numberutils.FormatBytes,
+	"count": func(count int64) string {
+		return numberutils.FormatFloat(count, 0)
+	},
+	"day":   FormatDay,
+	"month": FormatMonth,
+	"ratio": func(stat *DailyStat) string {
+		return numberutils.FormatFloat2(stat.CachedRatio()) + "%"
+	},
+	"rankData": func(name string, period string, items []*RankItem) *rankTemplateData {
+		return &rankTemplateData{Name: name, Period: period, Items: items}
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"/><title>{{.Title}}</title></head>
+<body style="margin: 0; padding: 1em; font-family: Arial, Helvetica, sans-serif; font-size: 14px; color: #333">
+<h2 style="margin: 0 0 0.4em 0">{{.Title}}</h2>
+<p style="color: #888; margin: 0 0 1.5em 0">统计周期：{{day .DayFrom}} 至 {{day .DayTo}} &nbsp; 生成时间：{{.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+
+{{$sum := .Sum}}
+<table cellpadding="8" cellspacing="0" style="border-collapse: collapse; margin-bottom: 2em">
+	<tr>
+		<td style="border: 1px solid #ddd; background: #f8f8f8">总流量</td><td style="border: 1px solid #ddd"><strong>{{bytes $sum.Bytes}}</strong></td>
+		<td style="border: 1px solid #ddd; background: #f8f8f8">总请求数</td><td style="border: 1px solid #ddd"><strong>{{count $sum.CountRequests}}</strong></td>
+	</tr>
+	<tr>
+		<td style="border: 1px solid #ddd; background: #f8f8f8">缓存命中率</td><td style="border: 1px solid #ddd"><strong>{{ratio $sum}}</strong></td>
+		<td style="border: 1px solid #ddd; background: #f8f8f8">WAF拦截</td><td style="border: 1px solid #ddd"><strong>{{count $sum.CountWAFBlocks}}</strong></td>
+	</tr>
+</table>
+
+{{range .Servers}}
+{{$serverSum := .Sum}}
+<h3 style="margin: 1.5em 0 0.6em 0; padding-bottom: 0.4em; border-bottom: 2px solid #2185d0">{{.ServerName}}</h3>
+<p style="margin: 0 0 0.8em 0">流量：{{bytes $serverSum.Bytes}} &nbsp; 请求数：{{count $serverSum.CountRequests}} &nbsp; 缓存命中率：{{ratio $serverSum}} &nbsp; WAF拦截：{{count $serverSum.CountWAFBlocks}}</p>
+
+{{if .DailyStats}}
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse; margin-bottom: 1em; width: 100%">
+	<tr style="background: #f8f8f8">
+		<th style="border: 1px solid #ddd; text-align: left">日期</th>
+		<th style="border: 1px solid #ddd; text-align: right">流量</th>
+		<th style="border: 1px solid #ddd; text-align: right">缓存流量</th>
+		<th style="border: 1px solid #ddd; text-align: right">请求数</th>
+		<th style="border: 1px solid #ddd; text-align: right">缓存请求数</th>
+		<th style="border: 1px solid #ddd; text-align: right">WAF拦截</th>
+		<th style="border: 1px solid #ddd; text-align: right">WAF验证码</th>
+	</tr>
+	{{range .DailyStats}}
+	<tr>
+		<td style="border: 1px solid #ddd">{{day .Day}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{bytes .Bytes}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{bytes .CachedBytes}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{count .CountRequests}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{count .CountCachedRequests}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{count .CountWAFBlocks}}</td>
+		<td style="border: 1px solid #ddd; text-align: right">{{count .CountWAFCaptchas}}</td>
+	</tr>
+	{{end}}
+</table>
+{{else}}
+<p style="color: #888">统计周期内暂无流量数据。</p>
+{{end}}
+
+<table cellpadding="0" cellspacing="0" style="width: 100%; margin-bottom: 1em">
+	<tr>
+		{{template "rank" rankData "地区" $.RankPeriod .Countries}}
+		{{template "rank" rankData "运营商" $.RankPeriod .Providers}}
+	</tr>
+	<tr>
+		{{template "rank" rankData "操作系统" $.RankPeriod .Systems}}
+		{{template "rank" rankData "浏览器" $.RankPeriod .Browsers}}
+	</tr>
+	<tr>
+		{{template "rank" rankData "WAF规则分组" $.WAFRankPeriod .WAFGroups}}
+		<td></td>
+	</tr>
+</table>
+{{end}}
+
+<p style="color: #888; margin-top: 2em">{{if not .IsRankPeriodMatched}}地区、运营商、终端排行为 {{month .Month}} 月的累计数据，和统计周期不一致；{{end}}WAF规则分组排行为截至 {{day .DayTo}} 的WAF看板统计；详细数据请查看附件。</p>
+</body>
+</html>
+
+{{define "rank"}}
+<td style="width: 50%; vertical-align: top; padding: 0 1em 1em 0">
+	<h4 style="margin: 0.6em 0">{{.Name}}排行 <span style="color: #888; font-weight: normal; font-size: 12px">{{.Period}}</span></h4>
+	{{if .Items}}
+	<table cellpadding="4" cellspacing="0" style="border-collapse: collapse; width: 100%">
+		{{range .Items}}
+		<tr><td style="border-bottom: 1px solid #eee">{{.Name}}</td><td style="border-bottom: 1px solid #eee; text-align: right">{{count .Count}}</td></tr>
+		{{end}}
+	</table>
+	{{else}}
+	<p style="color: #888; margin: 0">暂无数据</p>
+	{{end}}
+</td>
+{{end}}`))
+
+type rankTemplateData struct {
+	Name   string
+	Period string
+	Items  []*RankItem
+}
+
+// RenderHTML 生成HTML格式的报表
+func RenderHTML(report *Report) (string, error) {
+	var buf = &bytes.Buffer{}
+	err := htmlTemplate.Execute(buf, report)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/iwind/TeaGo/rands"
+)
+
+// Attachment 邮件附件
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Mail 邮件内容
+type Mail struct {
+	To          []string
+	Subject     string
+	HTML        string
+	Attachments []*Attachment
+}
+
+// Bytes 生成MIME格式的邮件内容
+func (this *Mail) Bytes(fromEmail string, fromName string, now time.Time) ([]byte, error) {
+	var buf = &bytes.Buffer{}
+	var boundary = "----=_Part_" + rands.HexString(16)
+
+	var from = (&mail.Address{Name: fromName, Address: fromEmail}).String()
+	var toList = []string{}
+	for _, to := range this.To {
+		toList = append(toList, (&mail.Address{Address: to}).String())
+	}
+
+	this.writeHeader(buf, "From", from)
+	this.writeHeader(buf, "To", strings.Join(toList, ", "))
+	this.writeHeader(buf, "Subject", mime.BEncoding.Encode("UTF-8", this.Subject))
+	this.writeHeader(buf, "Date", now.Format(time.RFC1123Z))
+	this.writeHeader(buf, "MIME-Version", "1.0")
+	this.writeHeader(buf, "Content-Type", "multipart/mixed; boundary=\""+boundary+"\"")
+	buf.WriteString("\r\n")
+
+	// 正文
+	buf.WriteString("--" + boundary + "\r\n")
+	this.writeHeader(buf, "Content-Type", "text/html; charset=UTF-8")
+	this.writeHeader(buf, "Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	this.writeBase64(buf, []byte(this.HTML))
+
+	// 附件
+	for _, attachment := range this.Attachments {
+		var contentType = attachment.ContentType
+		if len(contentType) == 0 {
+			contentType = "application/octet-stream"
+		}
+		var name = mime.BEncoding.Encode("UTF-8", attachment.Name)
+		buf.WriteString("--" + boundary + "\r\n")
+		this.writeHeader(buf, "Content-Type", contentType+"; name=\""+name+"\"")
+		this.writeHeader(buf, "Content-Disposition", "attachment; filename=\""+name+"\"")
+		this.writeHeader(buf, "Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		this.writeBase64(buf, attachment.Data)
+	}
+
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func (this *Mail) writeHeader(buf *bytes.Buffer, name string, value string) {
+	// 防止在头部中注入换行
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+// 按每行76个字符写入Base64编码后的内容
+func (this *Mail) writeBase64(buf *bytes.Buffer, data []byte) {
+	var encoded = base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	if len(encoded) > 0 {
+		buf.WriteString(encoded + "\r\n")
+	}
+}
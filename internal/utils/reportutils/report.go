@@ -0,0 +1,123 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"time"
+)
+
+// DailyStat 每日统计
+type DailyStat struct {
+	Day                 string // YYYYMMDD
+	Bytes               int64
+	CachedBytes         int64
+	CountRequests       int64
+	CountCachedRequests int64
+	CountWAFBlocks      int64
+	CountWAFCaptchas    int64
+	CountWAFLogs        int64
+}
+
+// Add 累加统计数据
+func (this *DailyStat) Add(stat *DailyStat) {
+	this.Bytes += stat.Bytes
+	this.CachedBytes += stat.CachedBytes
+	this.CountRequests += stat.CountRequests
+	this.CountCachedRequests += stat.CountCachedRequests
+	this.CountWAFBlocks += stat.CountWAFBlocks
+	this.CountWAFCaptchas += stat.CountWAFCaptchas
+	this.CountWAFLogs += stat.CountWAFLogs
+}
+
+// CachedRatio 缓存命中率，百分比
+func (this *DailyStat) CachedRatio() float64 {
+	if this.Bytes <= 0 {
+		return 0
+	}
+	return float64(this.CachedBytes*10000/this.Bytes) / 100
+}
+
+// FormatDay 格式化日期
+func (this *DailyStat) FormatDay() string {
+	return FormatDay(this.Day)
+}
+
+// RankItem 排行中的一项
+type RankItem struct {
+	Name  string
+	Count int64
+}
+
+// ServerReport 单个网站的统计数据
+type ServerReport struct {
+	ServerId   int64
+	ServerName string
+	DailyStats []*DailyStat // 按日期正序排列
+
+	Countries []*RankItem // 地区排行
+	Providers []*RankItem // 运营商排行
+	Systems   []*RankItem // 操作系统排行
+	Browsers  []*RankItem // 浏览器排行
+	WAFGroups []*RankItem // WAF规则分组拦截排行
+}
+
+// Sum 统计周期内的合计
+func (this *ServerReport) Sum() *DailyStat {
+	var sum = &DailyStat{}
+	for _, stat := range this.DailyStats {
+		sum.Add(stat)
+	}
+	return sum
+}
+
+// Report 报表
+type Report struct {
+	Title       string
+	DayFrom     string // YYYYMMDD
+	DayTo       string // YYYYMMDD
+	Month       string // 排行数据所在月份 YYYYMM，排行数据为该月从1日开始的累计数据，不一定和统计周期一致
+	GeneratedAt time.Time
+	Servers     []*ServerReport
+}
+
+// Sum 所有网站的合计
+func (this *Report) Sum() *DailyStat {
+	var sum = &DailyStat{}
+	for _, server := range this.Servers {
+		sum.Add(server.Sum())
+	}
+	return sum
+}
+
+// RankPeriod 排行数据的统计范围
+func (this *Report) RankPeriod() string {
+	return FormatMonth(this.Month) + " 月累计"
+}
+
+// WAFRankPeriod WAF规则分组排行的统计范围
+func (this *Report) WAFRankPeriod() string {
+	return "截至 " + FormatDay(this.DayTo)
+}
+
+// IsRankPeriodMatched 排行数据的统计范围是否和统计周期一致，即统计周期从月初开始且没有跨月
+func (this *Report) IsRankPeriodMatched() bool {
+	return len(this.DayTo) == 8 &&
+		this.DayFrom == this.Month+"01" &&
+		this.DayTo[:6] == this.Month
+}
+
+// FormatDay 将 YYYYMMDD 格式化为 YYYY-MM-DD
+func FormatDay(day string) string {
+	if len(day) != 8 {
+		return day
+	}
+	return day[:4] + "-" + day[4:6] + "-" + day[6:]
+}
+
+// FormatMonth 将 YYYYMM 格式化为 YYYY-MM
+func FormatMonth(month string) string {
+	if len(month) != 6 {
+		return month
+	}
+	return month[:4] + "-" + month[4:]
+}
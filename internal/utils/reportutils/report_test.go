@@ -0,0 +1,152 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+func testReport() *Report {
+	return &Report{
+		Title:       "网站流量周报",
+		DayFrom:     "20240506",
+		DayTo:       "20240512",
+		Month:       "202405",
+		GeneratedAt: time.Now(),
+		Servers: []*ServerReport{
+			{
+				ServerId:   1,
+				ServerName: "example.com<script>",
+				DailyStats: []*DailyStat{
+					{Day: "20240506", Bytes: 1024, CachedBytes: 512, CountRequests: 1000, CountCachedRequests: 400, CountWAFBlocks: 3},
+					{Day: "20240507", Bytes: 1024, CachedBytes: 0, CountRequests: 2000, CountCachedRequests: 0, CountWAFBlocks: 2},
+				},
+				Countries: []*RankItem{{Name: "中国", Count: 2500}},
+			},
+		},
+	}
+}
+
+func TestReport_Sum(t *testing.T) {
+	var sum = testReport().Sum()
+	if sum.Bytes != 2048 || sum.CountRequests != 3000 || sum.CountWAFBlocks != 5 {
+		t.Fatal("unexpected sum:", sum)
+	}
+	if sum.CachedRatio() != 25 {
+		t.Fatal("unexpected cached ratio:", sum.CachedRatio())
+	}
+}
+
+func TestReport_IsRankPeriodMatched(t *testing.T) {
+	var report = testReport()
+	if report.IsRankPeriodMatched() {
+		t.Fatal("weekly report should not match monthly rank data")
+	}
+	html, err := RenderHTML(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "2024-05 月累计") || !strings.Contains(html, "和统计周期不一致") {
+		t.Fatal("html should label rank period")
+	}
+
+	report.DayFrom = "20240501"
+	report.DayTo = "20240531"
+	if !report.IsRankPeriodMatched() {
+		t.Fatal("monthly report should match monthly rank data")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	html, err := RenderHTML(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"网站流量周报", "2024-05-06", "example.com&lt;script&gt;", "3, 000", "25%", "中国"} {
+		if !strings.Contains(html, s) {
+			t.Fatal("html should contain '" + s + "'")
+		}
+	}
+}
+
+func TestRenderXLSX(t *testing.T) {
+	data, err := RenderXLSX(testReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb, err := xlsx.OpenBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, ok := wb.Sheet["每日统计"]
+	if !ok {
+		t.Fatal("sheet not found")
+	}
+	if sheet.MaxRow != 3 {
+		t.Fatal("unexpected rows:", sheet.MaxRow)
+	}
+}
+
+func TestMail_Bytes(t *testing.T) {
+	var m = &Mail{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "流量报表\r\nBcc: evil@example.com",
+		HTML:    "<p>你好</p>",
+		Attachments: []*Attachment{
+			{Name: "报表.xlsx", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Data: []byte("xlsx")},
+		},
+	}
+	data, err := m.Bytes("noreply@example.com", "CDN", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Header.Get("Bcc")) > 0 {
+		t.Fatal("header injection")
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(subject, "流量报表") {
+		t.Fatal("unexpected subject:", subject)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reader = multipart.NewReader(msg.Body, params["boundary"])
+	var parts = []string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, string(body))
+	}
+	if len(parts) != 2 || parts[0] != "<p>你好</p>" || parts[1] != "xlsx" {
+		t.Fatal("unexpected parts:", parts)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 报表周期
+const (
+	PeriodWeekly  = "weekly"  // 周报，统计上周一到上周日
+	PeriodMonthly = "monthly" // 月报，统计上个月
+)
+
+// 报表对象类型
+const (
+	TargetServer = "server" // 指定网站
+	TargetUser   = "user"   // 指定用户的所有网站
+	TargetGroup  = "group"  // 指定分组的所有网站
+)
+
+// MaxEmails 每个报表最多的收件人数量
+const MaxEmails = 20
+
+// MaxServersPerReport 每份报表最多包含的网站数量
+const MaxServersPerReport = 100
+
+// PeriodName 周期名称
+func PeriodName(period string) string {
+	switch period {
+	case PeriodWeekly:
+		return "周报"
+	case PeriodMonthly:
+		return "月报"
+	}
+	return period
+}
+
+// TargetName 对象类型名称
+func TargetName(targetType string) string {
+	switch targetType {
+	case TargetServer:
+		return "网站"
+	case TargetUser:
+		return "用户"
+	case TargetGroup:
+		return "网站分组"
+	}
+	return targetType
+}
+
+// Schedule 定时报表
+type Schedule struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	IsOn       bool     `json:"isOn"`
+	Period     string   `json:"period"`
+	WeekDay    int      `json:"weekDay"`  // 周报发送日，1-7 表示周一到周日
+	MonthDay   int      `json:"monthDay"` // 月报发送日，1-28
+	Hour       int      `json:"hour"`     // 发送时间，0-23
+	TargetType string   `json:"targetType"`
+	TargetIds  []int64  `json:"targetIds"`
+	Emails     []string `json:"emails"`
+	AdminId    int64    `json:"adminId"` // 创建者
+	CreatedAt  int64    `json:"createdAt"`
+	LastRunAt  int64    `json:"lastRunAt"`
+	LastError  string   `json:"lastError"` // 最后一次发送失败的原因
+	NextRunAt  int64    `json:"nextRunAt"`
+}
+
+// Validate 校验报表设置，邮箱格式需要调用者另外校验
+func (this *Schedule) Validate() error {
+	this.Name = strings.TrimSpace(this.Name)
+	if len(this.Name) == 0 {
+		return errors.New("请输入报表名称")
+	}
+	if len([]rune(this.Name)) > 100 {
+		return errors.New("报表名称不能超过100个字符")
+	}
+
+	switch this.Period {
+	case PeriodWeekly:
+		if this.WeekDay < 1 || this.WeekDay > 7 {
+			return errors.New("请选择正确的发送日")
+		}
+	case PeriodMonthly:
+		if this.MonthDay < 1 || this.MonthDay > 28 {
+			return errors.New("月报发送日只能是1到28日")
+		}
+	default:
+		return errors.New("不支持的报表周期'" + this.Period + "'")
+	}
+	if this.Hour < 0 || this.Hour > 23 {
+		return errors.New("请选择正确的发送时间")
+	}
+
+	switch this.TargetType {
+	case TargetServer, TargetUser, TargetGroup:
+	default:
+		return errors.New("不支持的报表对象'" + this.TargetType + "'")
+	}
+	if len(this.TargetIds) == 0 {
+		return errors.New("请选择要统计的" + TargetName(this.TargetType))
+	}
+
+	if len(this.Emails) == 0 {
+		return errors.New("请输入收件人邮箱")
+	}
+	if len(this.Emails) > MaxEmails {
+		return fmt.Errorf("收件人不能超过%d个", MaxEmails)
+	}
+	return nil
+}
+
+// NextTime 计算下次发送时间，返回0表示不需要发送
+func (this *Schedule) NextTime(from time.Time) int64 {
+	if !this.IsOn {
+		return 0
+	}
+
+	var next time.Time
+	switch this.Period {
+	case PeriodWeekly:
+		var weekDay = int(from.Weekday())
+		if weekDay == 0 {
+			weekDay = 7
+		}
+		next = time.Date(from.Year(), from.Month(), from.Day()+this.WeekDay-weekDay, this.Hour, 0, 0, 0, from.Location())
+		if !next.After(from) {
+			next = next.AddDate(0, 0, 7)
+		}
+	case PeriodMonthly:
+		next = time.Date(from.Year(), from.Month(), this.MonthDay, this.Hour, 0, 0, 0, from.Location())
+		if !next.After(from) {
+			next = next.AddDate(0, 1, 0)
+		}
+	default:
+		return 0
+	}
+	return next.Unix()
+}
+
+// ResetNextRunAt 重新计算下次发送时间
+func (this *Schedule) ResetNextRunAt(from time.Time) {
+	this.NextRunAt = this.NextTime(from)
+}
+
+// ShouldRun 检查是否到了发送时间
+func (this *Schedule) ShouldRun(now int64) bool {
+	return this.IsOn && this.NextRunAt > 0 && this.NextRunAt <= now
+}
+
+// Range 计算在某个时间发送时报表的统计日期范围
+// 周报统计上一个完整的自然周，月报统计上一个完整的自然月
+func (this *Schedule) Range(runAt time.Time) (dayFrom time.Time, dayTo time.Time) {
+	var today = time.Date(runAt.Year(), runAt.Month(), runAt.Day(), 0, 0, 0, 0, runAt.Location())
+	switch this.Period {
+	case PeriodMonthly:
+		var firstDay = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return firstDay.AddDate(0, -1, 0), firstDay.AddDate(0, 0, -1)
+	default:
+		var weekDay = int(today.Weekday())
+		if weekDay == 0 {
+			weekDay = 7
+		}
+		var monday = today.AddDate(0, 0, 1-weekDay)
+		return monday.AddDate(0, 0, -7), monday.AddDate(0, 0, -1)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_NextTime(t *testing.T) {
+	// 2024-05-15 是周三
+	var from = time.Date(2024, 5, 15, 10, 30, 0, 0, time.Local)
+
+	var weekly = &Schedule{IsOn: true, Period: PeriodWeekly, WeekDay: 1, Hour: 8}
+	if time.Unix(weekly.NextTime(from), 0).Format("2006-01-02 15:04") != "2024-05-20 08:00" {
+		t.Fatal("unexpected weekly next time:", time.Unix(weekly.NextTime(from), 0))
+	}
+
+	weekly.WeekDay = 3
+	weekly.Hour = 11
+	if time.Unix(weekly.NextTime(from), 0).Format("2006-01-02 15:04") != "2024-05-15 11:00" {
+		t.Fatal("unexpected weekly next time:", time.Unix(weekly.NextTime(from), 0))
+	}
+
+	weekly.WeekDay = 7
+	if time.Unix(weekly.NextTime(from), 0).Format("2006-01-02 15:04") != "2024-05-19 11:00" {
+		t.Fatal("unexpected weekly next time:", time.Unix(weekly.NextTime(from), 0))
+	}
+
+	var monthly = &Schedule{IsOn: true, Period: PeriodMonthly, MonthDay: 1, Hour: 9}
+	if time.Unix(monthly.NextTime(from), 0).Format("2006-01-02 15:04") != "2024-06-01 09:00" {
+		t.Fatal("unexpected monthly next time:", time.Unix(monthly.NextTime(from), 0))
+	}
+
+	monthly.IsOn = false
+	if monthly.NextTime(from) != 0 {
+		t.Fatal("disabled schedule should not run")
+	}
+}
+
+func TestSchedule_Range(t *testing.T) {
+	var runAt = time.Date(2024, 3, 6, 8, 0, 0, 0, time.Local)
+
+	dayFrom, dayTo := (&Schedule{Period: PeriodWeekly}).Range(runAt)
+	if dayFrom.Format("20060102") != "20240226" || dayTo.Format("20060102") != "20240303" {
+		t.Fatal("unexpected weekly range:", dayFrom, dayTo)
+	}
+
+	dayFrom, dayTo = (&Schedule{Period: PeriodMonthly}).Range(runAt)
+	if dayFrom.Format("20060102") != "20240201" || dayTo.Format("20060102") != "20240229" {
+		t.Fatal("unexpected monthly range:", dayFrom, dayTo)
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	var schedule = &Schedule{
+		Name:       " test ",
+		Period:     PeriodMonthly,
+		MonthDay:   29,
+		TargetType: TargetServer,
+		TargetIds:  []int64{1},
+		Emails:     []string{"a@example.com"},
+	}
+	if schedule.Validate() == nil {
+		t.Fatal("month day 29 should be invalid")
+	}
+	schedule.MonthDay = 1
+	err := schedule.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Name != "test" {
+		t.Fatal("name should be trimmed")
+	}
+
+	schedule.TargetIds = nil
+	if schedule.Validate() == nil {
+		t.Fatal("targets should not be empty")
+	}
+}
+
+func TestStore_FindDueSchedules(t *testing.T) {
+	var store = NewStore()
+	var now = time.Now()
+	store.Add(&Schedule{Id: "a", IsOn: true, NextRunAt: now.Unix() - 1})
+	store.Add(&Schedule{Id: "b", IsOn: true, NextRunAt: now.Unix() + 60})
+	store.Add(&Schedule{Id: "c", IsOn: false, NextRunAt: now.Unix() - 1})
+
+	var due = store.Clone().FindDueSchedules(now.Unix())
+	if len(due) != 1 || due[0].Id != "a" {
+		t.Fatal("unexpected due schedules:", due)
+	}
+
+	store.Delete("a")
+	if store.Find("a") != nil {
+		t.Fatal("schedule should be deleted")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP连接加密方式
+const (
+	SMTPSecurityNone     = "none"
+	SMTPSecuritySTARTTLS = "starttls"
+	SMTPSecuritySSL      = "ssl"
+)
+
+// SMTPConfig 发信服务器设置
+type SMTPConfig struct {
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	Security  string `json:"security"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	FromEmail string `json:"fromEmail"`
+	FromName  string `json:"fromName"`
+}
+
+// NewSMTPConfig 获取新对象
+func NewSMTPConfig() *SMTPConfig {
+	return &SMTPConfig{
+		Port:     465,
+		Security: SMTPSecuritySSL,
+	}
+}
+
+// IsConfigured 是否已设置
+func (this *SMTPConfig) IsConfigured() bool {
+	return len(this.Host) > 0 && len(this.FromEmail) > 0
+}
+
+// Validate 校验设置，发信人邮箱格式需要调用者另外校验
+func (this *SMTPConfig) Validate() error {
+	this.Host = strings.TrimSpace(this.Host)
+	if len(this.Host) == 0 {
+		return errors.New("请输入SMTP服务器地址")
+	}
+	if this.Port <= 0 || this.Port > 65535 {
+		return errors.New("请输入正确的SMTP端口")
+	}
+	switch this.Security {
+	case SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecuritySSL:
+	default:
+		return errors.New("不支持的加密方式'" + this.Security + "'")
+	}
+	this.FromEmail = strings.TrimSpace(this.FromEmail)
+	if len(this.FromEmail) == 0 {
+		return errors.New("请输入发信人邮箱")
+	}
+	return nil
+}
+
+// SendMail 发送邮件
+func SendMail(config *SMTPConfig, mail *Mail) error {
+	if config == nil || !config.IsConfigured() {
+		return errors.New("尚未设置发信服务器")
+	}
+	if len(mail.To) == 0 {
+		return errors.New("收件人不能为空")
+	}
+
+	message, err := mail.Bytes(config.FromEmail, config.FromName, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var addr = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	var tlsConfig = &tls.Config{ServerName: config.Host}
+	var timeout = 30 * time.Second
+
+	var conn net.Conn
+	if config.Security == SMTPSecuritySSL {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, timeout)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Minute))
+
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if config.Security == SMTPSecuritySTARTTLS {
+		err = client.StartTLS(tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(config.Username) > 0 {
+		err = client.Auth(smtp.PlainAuth("", config.Username, config.Password, config.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(config.FromEmail)
+	if err != nil {
+		return err
+	}
+	for _, to := range mail.To {
+		err = client.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(message)
+	if err != nil {
+		_ = writer.Close()
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"encoding/json"
+)
+
+// Store 定时报表列表
+type Store struct {
+	Schedules []*Schedule `json:"schedules"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Schedules: []*Schedule{},
+	}
+}
+
+// Clone 复制对象
+func (this *Store) Clone() *Store {
+	var newStore = NewStore()
+	data, err := json.Marshal(this)
+	if err != nil {
+		return newStore
+	}
+	_ = json.Unmarshal(data, newStore)
+	if newStore.Schedules == nil {
+		newStore.Schedules = []*Schedule{}
+	}
+	return newStore
+}
+
+// Find 查找报表
+func (this *Store) Find(scheduleId string) *Schedule {
+	for _, schedule := range this.Schedules {
+		if schedule.Id == scheduleId {
+			return schedule
+		}
+	}
+	return nil
+}
+
+// Add 添加报表
+func (this *Store) Add(schedule *Schedule) {
+	this.Schedules = append(this.Schedules, schedule)
+}
+
+// Delete 删除报表
+func (this *Store) Delete(scheduleId string) {
+	var schedules = []*Schedule{}
+	for _, schedule := range this.Schedules {
+		if schedule.Id != scheduleId {
+			schedules = append(schedules, schedule)
+		}
+	}
+	this.Schedules = schedules
+}
+
+// FindDueSchedules 查找到了发送时间的报表
+func (this *Store) FindDueSchedules(now int64) []*Schedule {
+	var result = []*Schedule{}
+	for _, schedule := range this.Schedules {
+		if schedule.ShouldRun(now) {
+			result = append(result, schedule)
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reportutils
+
+import (
+	"bytes"
+
+	"github.com/tealeg/xlsx/v3"
+)
+
+// RenderXLSX 生成Excel格式的报表
+func RenderXLSX(report *Report) ([]byte, error) {
+	var wb = xlsx.NewFile()
+
+	// 汇总
+	{
+		sheet, err := wb.AddSheet("汇总")
+		if err != nil {
+			return nil, err
+		}
+		addXLSXRow(sheet, "网站ID", "网站", "流量（字节）", "缓存流量（字节）", "缓存命中率（%）", "请求数", "缓存请求数", "WAF拦截", "WAF验证码", "WAF记录")
+		for _, server := range report.Servers {
+			var sum = server.Sum()
+			var row = sheet.AddRow()
+			row.AddCell().SetInt64(server.ServerId)
+			row.AddCell().SetString(server.ServerName)
+			addXLSXStatCells(row, sum)
+		}
+	}
+
+	// 每日统计
+	{
+		sheet, err := wb.AddSheet("每日统计")
+		if err != nil {
+			return nil, err
+		}
+		addXLSXRow(sheet, "网站ID", "网站", "日期", "流量（字节）", "缓存流量（字节）", "缓存命中率（%）", "请求数", "缓存请求数", "WAF拦截", "WAF验证码", "WAF记录")
+		for _, server := range report.Servers {
+			for _, stat := range server.DailyStats {
+				var row = sheet.AddRow()
+				row.AddCell().SetInt64(server.ServerId)
+				row.AddCell().SetString(server.ServerName)
+				row.AddCell().SetString(stat.FormatDay())
+				addXLSXStatCells(row, stat)
+			}
+		}
+	}
+
+	// 排行
+	for _, rank := range []struct {
+		name   string
+		period string
+		items  func(server *ServerReport) []*RankItem
+	}{
+		{name: "地区", period: report.RankPeriod(), items: func(server *ServerReport) []*RankItem { return server.Countries }},
+		{name: "运营商", period: report.RankPeriod(), items: func(server *ServerReport) []*RankItem { return server.Providers }},
+		{name: "操作系统", period: report.RankPeriod(), items: func(server *ServerReport) []*RankItem { return server.Systems }},
+		{name: "浏览器", period: report.RankPeriod(), items: func(server *ServerReport) []*RankItem { return server.Browsers }},
+		{name: "WAF规则分组", period: report.WAFRankPeriod(), items: func(server *ServerReport) []*RankItem { return server.WAFGroups }},
+	} {
+		sheet, err := wb.AddSheet(rank.name)
+		if err != nil {
+			return nil, err
+		}
+		addXLSXRow(sheet, "网站ID", "网站", rank.name, "数量", "统计范围")
+		for _, server := range report.Servers {
+			for _, item := range rank.items(server) {
+				var row = sheet.AddRow()
+				row.AddCell().SetInt64(server.ServerId)
+				row.AddCell().SetString(server.ServerName)
+				row.AddCell().SetString(item.Name)
+				row.AddCell().SetInt64(item.Count)
+				row.AddCell().SetString(rank.period)
+			}
+		}
+	}
+
+	var buf = &bytes.Buffer{}
+	err := wb.Write(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addXLSXRow(sheet *xlsx.Sheet, values ...string) {
+	var row = sheet.AddRow()
+	row.SetHeight(25)
+	for _, value := range values {
+		row.AddCell().SetString(value)
+	}
+}
+
+func addXLSXStatCells(row *xlsx.Row, stat *DailyStat) {
+	row.AddCell().SetInt64(stat.Bytes)
+	row.AddCell().SetInt64(stat.CachedBytes)
+	row.AddCell().SetFloat(stat.CachedRatio())
+	row.AddCell().SetInt64(stat.CountRequests)
+	row.AddCell().SetInt64(stat.CountCachedRequests)
+	row.AddCell().SetInt64(stat.CountWAFBlocks)
+	row.AddCell().SetInt64(stat.CountWAFCaptchas)
+	row.AddCell().SetInt64(stat.CountWAFLogs)
+}
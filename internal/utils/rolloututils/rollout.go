@@ -0,0 +1,334 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rolloututils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 升级计划状态
+const (
+	StatusRunning   = "running"   // 执行中
+	StatusHalted    = "halted"    // 已暂停（自动或手动）
+	StatusCancelled = "cancelled" // 已取消
+	StatusFinished  = "finished"  // 已完成
+)
+
+// 节点升级状态
+const (
+	NodeStatusPending   = "pending"   // 等待升级
+	NodeStatusUpgrading = "upgrading" // 升级中
+	NodeStatusSoaking   = "soaking"   // 观察中
+	NodeStatusOk        = "ok"        // 升级成功
+	NodeStatusFailed    = "failed"    // 升级失败
+	NodeStatusSkipped   = "skipped"   // 已跳过
+)
+
+// 事件级别
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var (
+	ErrRolloutNotFound = errors.New("rollout not found")
+	ErrRolloutExists   = errors.New("rollout already exists")
+)
+
+// Config 升级计划配置
+type Config struct {
+	CanaryCount           int     `json:"canaryCount"`           // 金丝雀节点数量
+	CanaryNodeIds         []int64 `json:"canaryNodeIds"`         // 指定的金丝雀节点
+	CanarySoakSeconds     int64   `json:"canarySoakSeconds"`     // 金丝雀节点观察时间
+	BatchSize             int     `json:"batchSize"`             // 每批并发升级的节点数
+	BatchSoakSeconds      int64   `json:"batchSoakSeconds"`      // 每批升级后的观察时间
+	MaxErrorRate          float64 `json:"maxErrorRate"`          // 允许的最大失败比例（百分比），超过后自动暂停
+	MaxFailedNodes        int     `json:"maxFailedNodes"`        // 允许失败的节点数量，未超过时不自动暂停
+	UpgradeTimeoutSeconds int64   `json:"upgradeTimeoutSeconds"` // 单个节点升级超时时间
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		CanaryCount:           1,
+		CanarySoakSeconds:     600,
+		BatchSize:             5,
+		BatchSoakSeconds:      120,
+		MaxErrorRate:          10,
+		UpgradeTimeoutSeconds: 1800,
+	}
+}
+
+// Event 审计事件
+type Event struct {
+	CreatedAt int64  `json:"createdAt"`
+	NodeId    int64  `json:"nodeId"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+// Node 升级计划中的节点
+type Node struct {
+	Id         int64    `json:"id"`
+	Name       string   `json:"name"`
+	OldVersion string   `json:"oldVersion"`
+	NewVersion string   `json:"newVersion"`
+	Batch      int      `json:"batch"` // 批次，0表示金丝雀批次
+	IsCanary   bool     `json:"isCanary"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error"`
+	StartedAt  int64    `json:"startedAt"`
+	FinishedAt int64    `json:"finishedAt"`
+	Events     []*Event `json:"events"`
+}
+
+// Rollout 滚动升级计划
+type Rollout struct {
+	Id           string   `json:"id"`
+	ClusterId    int64    `json:"clusterId"`
+	AdminId      int64    `json:"adminId"`
+	Config       *Config  `json:"config"`
+	Status       string   `json:"status"`
+	CurrentBatch int      `json:"currentBatch"`
+	HaltReason   string   `json:"haltReason"`
+	CreatedAt    int64    `json:"createdAt"`
+	FinishedAt   int64    `json:"finishedAt"`
+	Nodes        []*Node  `json:"nodes"`
+	Events       []*Event `json:"events"`
+}
+
+// NewRollout 根据待升级节点生成升级计划
+// 指定的金丝雀节点优先，不足时按节点顺序补足，其余节点按每批并发数分批
+func NewRollout(id string, clusterId int64, config *Config, nodes []*Node, now int64) *Rollout {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	if config.CanaryCount < 0 {
+		config.CanaryCount = 0
+	}
+
+	var canaryMap = map[int64]bool{}
+	for _, nodeId := range config.CanaryNodeIds {
+		canaryMap[nodeId] = true
+	}
+
+	var canaries = []*Node{}
+	var others = []*Node{}
+	for _, node := range nodes {
+		if canaryMap[node.Id] {
+			canaries = append(canaries, node)
+		} else {
+			others = append(others, node)
+		}
+	}
+	for len(canaries) < config.CanaryCount && len(others) > 0 {
+		canaries = append(canaries, others[0])
+		others = others[1:]
+	}
+
+	var rollout = &Rollout{
+		Id:        id,
+		ClusterId: clusterId,
+		Config:    config,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		Nodes:     []*Node{},
+		Events:    []*Event{},
+	}
+	for _, node := range canaries {
+		node.Batch = 0
+		node.IsCanary = true
+		node.Status = NodeStatusPending
+		rollout.Nodes = append(rollout.Nodes, node)
+	}
+	for index, node := range others {
+		node.Batch = index/config.BatchSize + 1
+		node.IsCanary = false
+		node.Status = NodeStatusPending
+		rollout.Nodes = append(rollout.Nodes, node)
+	}
+
+	// 没有金丝雀节点时从第一批开始
+	if len(canaries) == 0 {
+		rollout.CurrentBatch = 1
+	}
+
+	rollout.AddEvent(0, LevelInfo, fmt.Sprintf("创建升级计划：%d个金丝雀节点，%d个普通节点，每批%d个", len(canaries), len(others), config.BatchSize), now)
+	return rollout
+}
+
+// CountBatches 批次数量（包括金丝雀批次）
+func (this *Rollout) CountBatches() int {
+	var maxBatch = 0
+	for _, node := range this.Nodes {
+		if node.Batch > maxBatch {
+			maxBatch = node.Batch
+		}
+	}
+	return maxBatch + 1
+}
+
+// FindNode 查找节点
+func (this *Rollout) FindNode(nodeId int64) *Node {
+	for _, node := range this.Nodes {
+		if node.Id == nodeId {
+			return node
+		}
+	}
+	return nil
+}
+
+// BatchNodes 某个批次中的所有节点
+func (this *Rollout) BatchNodes(batch int) []*Node {
+	var result = []*Node{}
+	for _, node := range this.Nodes {
+		if node.Batch == batch {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// NextBatch 查找下一个有待升级节点的批次，没有时返回-1
+func (this *Rollout) NextBatch() int {
+	var batches = this.CountBatches()
+	for batch := this.CurrentBatch; batch < batches; batch++ {
+		for _, node := range this.BatchNodes(batch) {
+			if node.Status == NodeStatusPending {
+				return batch
+			}
+		}
+	}
+	return -1
+}
+
+// AddEvent 添加审计事件，nodeId大于0时同时记录到节点
+func (this *Rollout) AddEvent(nodeId int64, level string, message string, now int64) {
+	var event = &Event{
+		CreatedAt: now,
+		NodeId:    nodeId,
+		Level:     level,
+		Message:   message,
+	}
+	this.Events = append(this.Events, event)
+	if nodeId > 0 {
+		var node = this.FindNode(nodeId)
+		if node != nil {
+			node.Events = append(node.Events, event)
+		}
+	}
+}
+
+// ErrorRate 已结束节点中的失败比例（百分比）
+func (this *Rollout) ErrorRate() float64 {
+	var countOk = 0
+	var countFailed = 0
+	for _, node := range this.Nodes {
+		switch node.Status {
+		case NodeStatusOk:
+			countOk++
+		case NodeStatusFailed:
+			countFailed++
+		}
+	}
+	if countOk+countFailed == 0 {
+		return 0
+	}
+	return float64(countFailed) * 100 / float64(countOk+countFailed)
+}
+
+// ShouldHalt 检查是否需要自动暂停
+// 失败的节点数量不超过允许的数量时继续升级；超过后金丝雀节点失败或者失败比例超过阈值时暂停
+func (this *Rollout) ShouldHalt() (shouldHalt bool, reason string) {
+	if this.CountNodes(NodeStatusFailed) <= this.Config.MaxFailedNodes {
+		return false, ""
+	}
+
+	for _, node := range this.Nodes {
+		if node.IsCanary && node.Status == NodeStatusFailed {
+			return true, "金丝雀节点'" + node.Name + "'升级失败：" + node.Error
+		}
+	}
+
+	var errorRate = this.ErrorRate()
+	if errorRate > 0 && errorRate > this.Config.MaxErrorRate {
+		return true, fmt.Sprintf("失败比例%.2f%%超过阈值%.2f%%", errorRate, this.Config.MaxErrorRate)
+	}
+	return false, ""
+}
+
+// Halt 暂停
+func (this *Rollout) Halt(reason string, now int64) {
+	if this.Status != StatusRunning {
+		return
+	}
+	this.Status = StatusHalted
+	this.HaltReason = reason
+	this.AddEvent(0, LevelError, "升级已暂停："+reason, now)
+}
+
+// Resume 继续执行
+// skipFailed为true时跳过失败的节点，否则将失败的节点重新加入升级队列
+func (this *Rollout) Resume(skipFailed bool, now int64) error {
+	if this.Status != StatusHalted {
+		return errors.New("only halted rollout can be resumed")
+	}
+	for _, node := range this.Nodes {
+		if node.Status != NodeStatusFailed {
+			continue
+		}
+		if skipFailed {
+			node.Status = NodeStatusSkipped
+			this.AddEvent(node.Id, LevelWarn, "跳过失败的节点", now)
+		} else {
+			node.Status = NodeStatusPending
+			node.Error = ""
+			if node.Batch < this.CurrentBatch {
+				this.CurrentBatch = node.Batch
+			}
+			this.AddEvent(node.Id, LevelInfo, "重新加入升级队列", now)
+		}
+	}
+	this.Status = StatusRunning
+	this.HaltReason = ""
+	this.AddEvent(0, LevelInfo, "继续升级", now)
+	return nil
+}
+
+// Cancel 取消
+func (this *Rollout) Cancel(now int64) {
+	if this.IsDone() {
+		return
+	}
+	this.Status = StatusCancelled
+	this.FinishedAt = now
+	this.AddEvent(0, LevelWarn, "升级已取消", now)
+}
+
+// Finish 完成
+func (this *Rollout) Finish(now int64) {
+	this.Status = StatusFinished
+	this.FinishedAt = now
+	this.AddEvent(0, LevelInfo, "升级已完成", now)
+}
+
+// IsDone 是否已结束
+func (this *Rollout) IsDone() bool {
+	return this.Status == StatusFinished || this.Status == StatusCancelled
+}
+
+// CountNodes 按状态统计节点数量
+func (this *Rollout) CountNodes(status string) int {
+	var count = 0
+	for _, node := range this.Nodes {
+		if node.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rolloututils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/iwind/TeaGo/assert"
+	"github.com/iwind/TeaGo/rands"
+)
+
+func testNodes(count int) []*rolloututils.Node {
+	var nodes = []*rolloututils.Node{}
+	for i := 1; i <= count; i++ {
+		nodes = append(nodes, &rolloututils.Node{
+			Id:   int64(i),
+			Name: "node" + string(rune('0'+i)),
+		})
+	}
+	return nodes
+}
+
+func TestNewRollout(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var rollout = rolloututils.NewRollout("a", 1, &rolloututils.Config{
+		CanaryCount:   2,
+		CanaryNodeIds: []int64{5},
+		BatchSize:     2,
+		MaxErrorRate:  30,
+	}, testNodes(7), 100)
+	a.IsTrue(rollout.Status == rolloututils.StatusRunning)
+	a.IsTrue(rollout.CountBatches() == 4)
+
+	var canaries = rollout.BatchNodes(0)
+	a.IsTrue(len(canaries) == 2)
+	a.IsTrue(canaries[0].Id == 5)
+	a.IsTrue(canaries[1].Id == 1)
+	a.IsTrue(canaries[0].IsCanary)
+
+	a.IsTrue(len(rollout.BatchNodes(1)) == 2)
+	a.IsTrue(len(rollout.BatchNodes(3)) == 1)
+	a.IsTrue(rollout.NextBatch() == 0)
+}
+
+func TestNewRollout_NoCanary(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var rollout = rolloututils.NewRollout("a", 1, &rolloututils.Config{
+		CanaryCount: 0,
+		BatchSize:   3,
+	}, testNodes(3), 100)
+	a.IsTrue(rollout.CountBatches() == 2)
+	a.IsTrue(rollout.NextBatch() == 1)
+}
+
+func TestRollout_Halt(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var rollout = rolloututils.NewRollout("a", 1, &rolloututils.Config{
+		CanaryCount:  1,
+		BatchSize:    2,
+		MaxErrorRate: 30,
+	}, testNodes(5), 100)
+
+	// canary failed
+	var canary = rollout.BatchNodes(0)[0]
+	canary.Status = rolloututils.NodeStatusFailed
+	canary.Error = "timeout"
+	shouldHalt, reason := rollout.ShouldHalt()
+	a.IsTrue(shouldHalt)
+	t.Log(reason)
+
+	rollout.Halt(reason, 101)
+	a.IsTrue(rollout.Status == rolloututils.StatusHalted)
+	a.IsNil(rollout.Resume(false, 102))
+	a.IsTrue(canary.Status == rolloututils.NodeStatusPending)
+	a.IsTrue(rollout.Status == rolloututils.StatusRunning)
+	a.IsTrue(len(canary.Events) == 1)
+
+	// error rate
+	canary.Status = rolloututils.NodeStatusOk
+	rollout.CurrentBatch = 1
+	var batch = rollout.BatchNodes(1)
+	batch[0].Status = rolloututils.NodeStatusOk
+	batch[1].Status = rolloututils.NodeStatusOk
+	shouldHalt, _ = rollout.ShouldHalt()
+	a.IsFalse(shouldHalt)
+	a.IsTrue(rollout.NextBatch() == 2)
+
+	rollout.BatchNodes(2)[0].Status = rolloututils.NodeStatusFailed
+	a.IsTrue(rollout.ErrorRate() == 25)
+	shouldHalt, _ = rollout.ShouldHalt()
+	a.IsFalse(shouldHalt)
+
+	rollout.BatchNodes(2)[1].Status = rolloututils.NodeStatusFailed
+	shouldHalt, reason = rollout.ShouldHalt()
+	a.IsTrue(shouldHalt)
+	t.Log(reason)
+
+	rollout.Halt(reason, 103)
+	a.IsNil(rollout.Resume(true, 104))
+	a.IsTrue(rollout.CountNodes(rolloututils.NodeStatusSkipped) == 2)
+	a.IsTrue(rollout.ErrorRate() == 0)
+	a.IsTrue(rollout.NextBatch() == -1)
+}
+
+func TestStore(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var store = rolloututils.NewStore()
+	for i := 0; i < 5; i++ {
+		var rollout = rolloututils.NewRollout(rands.HexString(16), 1, nil, testNodes(2), int64(100+i))
+		if i < 4 {
+			rollout.Finish(int64(200 + i))
+		}
+		store.Add(rollout)
+	}
+	a.IsNotNil(store.FindActiveClusterRollout(1))
+	a.IsNil(store.FindActiveClusterRollout(2))
+	a.IsTrue(len(store.FindRunningRollouts()) == 1)
+
+	store.CleanFinished(2)
+	var rollouts = store.FindClusterRollouts(1)
+	a.IsTrue(len(rollouts) == 3)
+	a.IsTrue(rollouts[0].CreatedAt == 104)
+	a.IsTrue(rollouts[2].CreatedAt == 102)
+}
+
+func TestRollout_Halt_MaxFailedNodes(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var rollout = rolloututils.NewRollout("a", 1, &rolloututils.Config{
+		CanaryCount:    1,
+		BatchSize:      2,
+		MaxErrorRate:   30,
+		MaxFailedNodes: 2,
+	}, testNodes(5), 100)
+
+	// 允许范围内的失败不暂停
+	var canary = rollout.BatchNodes(0)[0]
+	canary.Status = rolloututils.NodeStatusFailed
+	shouldHalt, _ := rollout.ShouldHalt()
+	a.IsFalse(shouldHalt)
+
+	rollout.BatchNodes(1)[0].Status = rolloututils.NodeStatusFailed
+	shouldHalt, _ = rollout.ShouldHalt()
+	a.IsFalse(shouldHalt)
+	a.IsTrue(rollout.NextBatch() == 1)
+
+	// 超过允许的数量
+	rollout.BatchNodes(1)[1].Status = rolloututils.NodeStatusFailed
+	shouldHalt, reason := rollout.ShouldHalt()
+	a.IsTrue(shouldHalt)
+	t.Log(reason)
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package rolloututils
+
+import (
+	"sort"
+)
+
+// Store 升级计划列表
+type Store struct {
+	Rollouts []*Rollout `json:"rollouts"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Rollouts: []*Rollout{},
+	}
+}
+
+// Add 添加升级计划
+func (this *Store) Add(rollout *Rollout) {
+	this.Rollouts = append(this.Rollouts, rollout)
+}
+
+// Find 查找升级计划
+func (this *Store) Find(rolloutId string) *Rollout {
+	for _, rollout := range this.Rollouts {
+		if rollout.Id == rolloutId {
+			return rollout
+		}
+	}
+	return nil
+}
+
+// FindActiveClusterRollout 查找集群正在执行或暂停中的升级计划
+func (this *Store) FindActiveClusterRollout(clusterId int64) *Rollout {
+	for _, rollout := range this.Rollouts {
+		if rollout.ClusterId == clusterId && !rollout.IsDone() {
+			return rollout
+		}
+	}
+	return nil
+}
+
+// FindClusterRollouts 查找集群的所有升级计划，按创建时间倒序排列
+func (this *Store) FindClusterRollouts(clusterId int64) []*Rollout {
+	var result = []*Rollout{}
+	for _, rollout := range this.Rollouts {
+		if rollout.ClusterId == clusterId {
+			result = append(result, rollout)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt > result[j].CreatedAt
+	})
+	return result
+}
+
+// FindRunningRollouts 查找所有执行中的升级计划
+func (this *Store) FindRunningRollouts() []*Rollout {
+	var result = []*Rollout{}
+	for _, rollout := range this.Rollouts {
+		if rollout.Status == StatusRunning {
+			result = append(result, rollout)
+		}
+	}
+	return result
+}
+
+// CleanFinished 每个集群只保留最近的若干个已结束的升级计划
+func (this *Store) CleanFinished(keepPerCluster int) {
+	var countMap = map[int64]int{} // clusterId => count
+	var rollouts = []*Rollout{}
+	var sortedRollouts = append([]*Rollout{}, this.Rollouts...)
+	sort.Slice(sortedRollouts, func(i, j int) bool {
+		return sortedRollouts[i].CreatedAt > sortedRollouts[j].CreatedAt
+	})
+	for _, rollout := range sortedRollouts {
+		if rollout.IsDone() {
+			countMap[rollout.ClusterId]++
+			if countMap[rollout.ClusterId] > keepPerCluster {
+				continue
+			}
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	this.Rollouts = rollouts
+}
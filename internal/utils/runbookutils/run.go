@@ -0,0 +1,173 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbookutils
+
+// 执行状态
+const (
+	RunStatusRunning  = "running"
+	RunStatusFinished = "finished"
+)
+
+// 单元格状态
+const (
+	CellPending = "pending" // 等待执行
+	CellOk      = "ok"      // 成功
+	CellFailed  = "failed"  // 失败
+	CellSkipped = "skipped" // 因为前面的步骤失败而跳过
+)
+
+// 触发方式
+const (
+	TriggerManual   = "manual"
+	TriggerSchedule = "schedule"
+	TriggerRerun    = "rerun"
+)
+
+// RunNode 执行的节点
+type RunNode struct {
+	Id        int64  `json:"id"`
+	Name      string `json:"name"`
+	ClusterId int64  `json:"clusterId"`
+}
+
+// Cell 某个步骤在某个节点上的执行结果
+type Cell struct {
+	StepIndex int    `json:"stepIndex"`
+	NodeId    int64  `json:"nodeId"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// Run 运行手册的一次执行
+type Run struct {
+	Id          string     `json:"id"`
+	RunbookId   string     `json:"runbookId"`
+	RunbookName string     `json:"runbookName"`
+	Trigger     string     `json:"trigger"`
+	AdminId     int64      `json:"adminId"`
+	Status      string     `json:"status"`
+	CreatedAt   int64      `json:"createdAt"`
+	FinishedAt  int64      `json:"finishedAt"`
+	Steps       []*Step    `json:"steps"` // 执行时的步骤快照
+	Nodes       []*RunNode `json:"nodes"`
+	Cells       []*Cell    `json:"cells"`
+}
+
+// NewRun 创建新的执行
+func NewRun(id string, runbook *Runbook, nodes []*RunNode, trigger string, now int64) *Run {
+	var run = &Run{
+		Id:          id,
+		RunbookId:   runbook.Id,
+		RunbookName: runbook.Name,
+		Trigger:     trigger,
+		Status:      RunStatusRunning,
+		CreatedAt:   now,
+		Steps:       runbook.Steps,
+		Nodes:       nodes,
+		Cells:       []*Cell{},
+	}
+	for stepIndex := range runbook.Steps {
+		for _, node := range nodes {
+			run.Cells = append(run.Cells, &Cell{
+				StepIndex: stepIndex,
+				NodeId:    node.Id,
+				Status:    CellPending,
+			})
+		}
+	}
+	return run
+}
+
+// FindCell 查找单元格
+func (this *Run) FindCell(stepIndex int, nodeId int64) *Cell {
+	for _, cell := range this.Cells {
+		if cell.StepIndex == stepIndex && cell.NodeId == nodeId {
+			return cell
+		}
+	}
+	return nil
+}
+
+// PrepareStep 准备执行某个步骤，返回需要执行此步骤的节点
+// 前面步骤失败且没有设置失败后继续的节点，会被标记为跳过
+func (this *Run) PrepareStep(stepIndex int, now int64) []int64 {
+	var nodeIds = []int64{}
+	for _, node := range this.Nodes {
+		var cell = this.FindCell(stepIndex, node.Id)
+		if cell == nil || cell.Status != CellPending {
+			continue
+		}
+		if this.isBlocked(stepIndex, node.Id) {
+			cell.Status = CellSkipped
+			cell.Message = "前面的步骤执行失败"
+			cell.UpdatedAt = now
+			continue
+		}
+		nodeIds = append(nodeIds, node.Id)
+	}
+	return nodeIds
+}
+
+// SetResult 设置执行结果
+func (this *Run) SetResult(stepIndex int, nodeId int64, isOk bool, message string, now int64) {
+	var cell = this.FindCell(stepIndex, nodeId)
+	if cell == nil {
+		return
+	}
+	if isOk {
+		cell.Status = CellOk
+	} else {
+		cell.Status = CellFailed
+	}
+	cell.Message = message
+	cell.UpdatedAt = now
+}
+
+// ResetFailed 将失败和跳过的单元格重置为等待执行，用于重新执行失败的步骤
+func (this *Run) ResetFailed() int {
+	var count = 0
+	for _, cell := range this.Cells {
+		if cell.Status == CellFailed || cell.Status == CellSkipped {
+			cell.Status = CellPending
+			cell.Message = ""
+			count++
+		}
+	}
+	if count > 0 {
+		this.Status = RunStatusRunning
+		this.FinishedAt = 0
+	}
+	return count
+}
+
+// CountCells 按状态统计单元格数量
+func (this *Run) CountCells(status string) int {
+	var count = 0
+	for _, cell := range this.Cells {
+		if cell.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Finish 结束执行
+func (this *Run) Finish(now int64) {
+	this.Status = RunStatusFinished
+	this.FinishedAt = now
+}
+
+// 检查节点在某个步骤之前是否有阻断执行的失败
+func (this *Run) isBlocked(stepIndex int, nodeId int64) bool {
+	for i := 0; i < stepIndex && i < len(this.Steps); i++ {
+		var cell = this.FindCell(i, nodeId)
+		if cell == nil {
+			continue
+		}
+		if (cell.Status == CellFailed || cell.Status == CellSkipped) && !this.Steps[i].ContinueOnError {
+			return true
+		}
+	}
+	return false
+}
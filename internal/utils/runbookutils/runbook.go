@@ -0,0 +1,237 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbookutils
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/iwind/TeaGo/types"
+)
+
+// 步骤类型，和节点支持的消息代号对应
+const (
+	StepCheckSystemdService = "checkSystemdService" // 检查systemd服务
+	StepCheckLocalFirewall  = "checkLocalFirewall"  // 检查本地防火墙
+	StepCleanCache          = "cleanCache"          // 清理缓存
+	StepStatCache           = "statCache"           // 统计缓存
+	StepNewNodeTask         = "newNodeTask"         // 通知节点同步任务
+)
+
+// 执行目标类型
+const (
+	TargetCluster = "cluster"
+	TargetGroup   = "group"
+	TargetRegion  = "region"
+)
+
+// 计划类型
+const (
+	ScheduleInterval = "interval" // 按固定间隔执行
+	ScheduleDaily    = "daily"    // 每天固定时间执行
+)
+
+var (
+	ErrRunbookNotFound = errors.New("runbook not found")
+	ErrRunNotFound     = errors.New("run not found")
+)
+
+// StepDefinition 步骤类型定义
+type StepDefinition struct {
+	Code                string `json:"code"`
+	Name                string `json:"name"`
+	RequireCachePolicy  bool   `json:"requireCachePolicy"`
+	RequireFirewallName bool   `json:"requireFirewallName"`
+}
+
+// AllStepDefinitions 所有支持的步骤类型
+func AllStepDefinitions() []*StepDefinition {
+	return []*StepDefinition{
+		{
+			Code: StepCheckSystemdService,
+			Name: "检查systemd服务",
+		},
+		{
+			Code:                StepCheckLocalFirewall,
+			Name:                "检查本地防火墙",
+			RequireFirewallName: true,
+		},
+		{
+			Code:               StepCleanCache,
+			Name:               "清理缓存",
+			RequireCachePolicy: true,
+		},
+		{
+			Code:               StepStatCache,
+			Name:               "统计缓存",
+			RequireCachePolicy: true,
+		},
+		{
+			Code: StepNewNodeTask,
+			Name: "通知节点同步任务",
+		},
+	}
+}
+
+// FindStepDefinition 查找步骤类型定义
+func FindStepDefinition(code string) *StepDefinition {
+	for _, def := range AllStepDefinitions() {
+		if def.Code == code {
+			return def
+		}
+	}
+	return nil
+}
+
+// Step 运行手册步骤
+type Step struct {
+	Code            string `json:"code"`
+	CachePolicyId   int64  `json:"cachePolicyId"`   // 缓存相关步骤使用的缓存策略
+	FirewallName    string `json:"firewallName"`    // 防火墙名称，比如nftables
+	TimeoutSeconds  int32  `json:"timeoutSeconds"`  // 超时时间
+	ContinueOnError bool   `json:"continueOnError"` // 失败后是否继续执行此节点的后续步骤
+}
+
+// Validate 校验步骤
+func (this *Step) Validate() error {
+	var def = FindStepDefinition(this.Code)
+	if def == nil {
+		return errors.New("unsupported step '" + this.Code + "'")
+	}
+	if def.RequireCachePolicy && this.CachePolicyId <= 0 {
+		return errors.New("step '" + def.Name + "' requires a cache policy")
+	}
+	if def.RequireFirewallName && len(this.FirewallName) == 0 {
+		return errors.New("step '" + def.Name + "' requires a firewall name")
+	}
+	return nil
+}
+
+// Target 执行目标
+type Target struct {
+	Type      string `json:"type"`
+	ClusterId int64  `json:"clusterId"`
+	GroupId   int64  `json:"groupId"`
+	RegionId  int64  `json:"regionId"`
+}
+
+// Schedule 执行计划
+type Schedule struct {
+	IsOn            bool   `json:"isOn"`
+	Type            string `json:"type"`
+	IntervalMinutes int    `json:"intervalMinutes"`
+	DailyTime       string `json:"dailyTime"` // HH:mm
+}
+
+var dailyTimeReg = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+
+// Validate 校验执行计划
+func (this *Schedule) Validate() error {
+	if !this.IsOn {
+		return nil
+	}
+	switch this.Type {
+	case ScheduleInterval:
+		if this.IntervalMinutes <= 0 {
+			return errors.New("interval minutes should be greater than 0")
+		}
+	case ScheduleDaily:
+		var matches = dailyTimeReg.FindStringSubmatch(this.DailyTime)
+		if len(matches) == 0 || types.Int(matches[1]) > 23 || types.Int(matches[2]) > 59 {
+			return errors.New("invalid daily time '" + this.DailyTime + "'")
+		}
+	default:
+		return errors.New("invalid schedule type '" + this.Type + "'")
+	}
+	return nil
+}
+
+// NextTime 计算下次执行时间，返回0表示不需要执行
+func (this *Schedule) NextTime(from time.Time) int64 {
+	if !this.IsOn {
+		return 0
+	}
+	switch this.Type {
+	case ScheduleInterval:
+		if this.IntervalMinutes <= 0 {
+			return 0
+		}
+		return from.Add(time.Duration(this.IntervalMinutes) * time.Minute).Unix()
+	case ScheduleDaily:
+		var matches = dailyTimeReg.FindStringSubmatch(this.DailyTime)
+		if len(matches) == 0 {
+			return 0
+		}
+		var next = time.Date(from.Year(), from.Month(), from.Day(), types.Int(matches[1]), types.Int(matches[2]), 0, 0, from.Location())
+		if !next.After(from) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next.Unix()
+	}
+	return 0
+}
+
+// Runbook 运行手册
+type Runbook struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Steps       []*Step   `json:"steps"`
+	Target      *Target   `json:"target"`
+	Schedule    *Schedule `json:"schedule"`
+	IsOn        bool      `json:"isOn"`
+	CreatedAt   int64     `json:"createdAt"`
+	LastRunAt   int64     `json:"lastRunAt"`
+	NextRunAt   int64     `json:"nextRunAt"`
+}
+
+// Validate 校验运行手册
+func (this *Runbook) Validate() error {
+	if len(this.Steps) == 0 {
+		return errors.New("runbook should have at least one step")
+	}
+	for _, step := range this.Steps {
+		err := step.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	if this.Target == nil {
+		return errors.New("runbook target should not be empty")
+	}
+	switch this.Target.Type {
+	case TargetCluster:
+		if this.Target.ClusterId <= 0 {
+			return errors.New("target cluster should not be empty")
+		}
+	case TargetGroup:
+		if this.Target.ClusterId <= 0 || this.Target.GroupId <= 0 {
+			return errors.New("target group should not be empty")
+		}
+	case TargetRegion:
+		if this.Target.RegionId <= 0 {
+			return errors.New("target region should not be empty")
+		}
+	default:
+		return errors.New("invalid target type '" + this.Target.Type + "'")
+	}
+	if this.Schedule != nil {
+		return this.Schedule.Validate()
+	}
+	return nil
+}
+
+// ResetNextRunAt 重新计算下次执行时间
+func (this *Runbook) ResetNextRunAt(from time.Time) {
+	if !this.IsOn || this.Schedule == nil {
+		this.NextRunAt = 0
+		return
+	}
+	this.NextRunAt = this.Schedule.NextTime(from)
+}
+
+// ShouldRun 检查是否到了计划执行时间
+func (this *Runbook) ShouldRun(now int64) bool {
+	return this.IsOn && this.NextRunAt > 0 && this.NextRunAt <= now
+}
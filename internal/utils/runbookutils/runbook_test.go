@@ -0,0 +1,135 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbookutils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/iwind/TeaGo/assert"
+	"github.com/iwind/TeaGo/rands"
+)
+
+func TestRunbook_Validate(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var runbook = &runbookutils.Runbook{
+		Steps: []*runbookutils.Step{
+			{Code: runbookutils.StepCheckSystemdService},
+		},
+		Target: &runbookutils.Target{Type: runbookutils.TargetCluster, ClusterId: 1},
+	}
+	a.IsNil(runbook.Validate())
+
+	runbook.Steps = append(runbook.Steps, &runbookutils.Step{Code: runbookutils.StepCleanCache})
+	a.IsNotNil(runbook.Validate())
+	runbook.Steps[1].CachePolicyId = 1
+	a.IsNil(runbook.Validate())
+
+	runbook.Steps = append(runbook.Steps, &runbookutils.Step{Code: "abc"})
+	a.IsNotNil(runbook.Validate())
+	runbook.Steps = runbook.Steps[:2]
+
+	runbook.Target = &runbookutils.Target{Type: runbookutils.TargetGroup, ClusterId: 1}
+	a.IsNotNil(runbook.Validate())
+
+	runbook.Target = &runbookutils.Target{Type: runbookutils.TargetRegion, RegionId: 2}
+	a.IsNil(runbook.Validate())
+
+	runbook.Schedule = &runbookutils.Schedule{IsOn: true, Type: runbookutils.ScheduleDaily, DailyTime: "25:00"}
+	a.IsNotNil(runbook.Validate())
+	runbook.Schedule.DailyTime = "3:30"
+	a.IsNil(runbook.Validate())
+}
+
+func TestSchedule_NextTime(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var from = time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+	var schedule = &runbookutils.Schedule{IsOn: true, Type: runbookutils.ScheduleInterval, IntervalMinutes: 30}
+	a.IsTrue(schedule.NextTime(from) == from.Unix()+1800)
+
+	schedule = &runbookutils.Schedule{IsOn: true, Type: runbookutils.ScheduleDaily, DailyTime: "12:30"}
+	a.IsTrue(schedule.NextTime(from) == time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local).Unix())
+
+	schedule.DailyTime = "10:00"
+	a.IsTrue(schedule.NextTime(from) == time.Date(2024, 5, 2, 10, 0, 0, 0, time.Local).Unix())
+
+	schedule.IsOn = false
+	a.IsTrue(schedule.NextTime(from) == 0)
+
+	var runbook = &runbookutils.Runbook{
+		IsOn:     true,
+		Schedule: &runbookutils.Schedule{IsOn: true, Type: runbookutils.ScheduleInterval, IntervalMinutes: 1},
+	}
+	runbook.ResetNextRunAt(from)
+	a.IsFalse(runbook.ShouldRun(from.Unix()))
+	a.IsTrue(runbook.ShouldRun(from.Unix() + 60))
+}
+
+func TestRun(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var runbook = &runbookutils.Runbook{
+		Id: "a",
+		Steps: []*runbookutils.Step{
+			{Code: runbookutils.StepCheckSystemdService},
+			{Code: runbookutils.StepNewNodeTask, ContinueOnError: true},
+			{Code: runbookutils.StepCheckLocalFirewall, FirewallName: "nftables"},
+		},
+	}
+	var run = runbookutils.NewRun("r", runbook, []*runbookutils.RunNode{{Id: 1}, {Id: 2}, {Id: 3}}, runbookutils.TriggerManual, 100)
+	a.IsTrue(len(run.Cells) == 9)
+
+	var nodeIds = run.PrepareStep(0, 100)
+	a.IsTrue(len(nodeIds) == 3)
+	run.SetResult(0, 1, true, "", 101)
+	run.SetResult(0, 2, false, "service not found", 101)
+	run.SetResult(0, 3, true, "", 101)
+
+	// node 2 is blocked by step 0
+	nodeIds = run.PrepareStep(1, 102)
+	a.IsTrue(len(nodeIds) == 2)
+	run.SetResult(1, 1, true, "", 103)
+	run.SetResult(1, 3, false, "timeout", 103)
+
+	// step 1 failure does not block
+	nodeIds = run.PrepareStep(2, 104)
+	a.IsTrue(len(nodeIds) == 2)
+	run.SetResult(2, 1, true, "", 105)
+	run.SetResult(2, 3, true, "", 105)
+	run.Finish(106)
+
+	a.IsTrue(run.CountCells(runbookutils.CellOk) == 5)
+	a.IsTrue(run.CountCells(runbookutils.CellFailed) == 2)
+	a.IsTrue(run.CountCells(runbookutils.CellSkipped) == 2)
+
+	// rerun failed
+	a.IsTrue(run.ResetFailed() == 4)
+	a.IsTrue(run.Status == runbookutils.RunStatusRunning)
+	a.IsTrue(len(run.PrepareStep(0, 110)) == 1)
+	run.SetResult(0, 2, true, "", 111)
+	a.IsTrue(len(run.PrepareStep(1, 112)) == 2)
+	a.IsTrue(len(run.PrepareStep(2, 113)) == 1)
+}
+
+func TestRunStore_Clean(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var store = runbookutils.NewRunStore()
+	var runbook = &runbookutils.Runbook{Id: "a"}
+	for i := 0; i < 5; i++ {
+		var run = runbookutils.NewRun(rands.HexString(16), runbook, nil, runbookutils.TriggerManual, int64(100+i))
+		if i > 0 {
+			run.Finish(int64(200 + i))
+		}
+		store.Add(run)
+	}
+	store.Clean(2)
+	var runs = store.FindRunbookRuns("a")
+	a.IsTrue(len(runs) == 3)
+	a.IsTrue(runs[0].CreatedAt == 104)
+	a.IsTrue(runs[2].Status == runbookutils.RunStatusRunning)
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbookutils
+
+import (
+	"sort"
+)
+
+// Store 运行手册列表
+type Store struct {
+	Runbooks []*Runbook `json:"runbooks"`
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Runbooks: []*Runbook{},
+	}
+}
+
+// Find 查找运行手册
+func (this *Store) Find(runbookId string) *Runbook {
+	for _, runbook := range this.Runbooks {
+		if runbook.Id == runbookId {
+			return runbook
+		}
+	}
+	return nil
+}
+
+// Add 添加运行手册
+func (this *Store) Add(runbook *Runbook) {
+	this.Runbooks = append(this.Runbooks, runbook)
+}
+
+// Delete 删除运行手册
+func (this *Store) Delete(runbookId string) {
+	var runbooks = []*Runbook{}
+	for _, runbook := range this.Runbooks {
+		if runbook.Id != runbookId {
+			runbooks = append(runbooks, runbook)
+		}
+	}
+	this.Runbooks = runbooks
+}
+
+// FindDueRunbooks 查找到了计划执行时间的运行手册
+func (this *Store) FindDueRunbooks(now int64) []*Runbook {
+	var result = []*Runbook{}
+	for _, runbook := range this.Runbooks {
+		if runbook.ShouldRun(now) {
+			result = append(result, runbook)
+		}
+	}
+	return result
+}
+
+// RunStore 执行记录列表
+type RunStore struct {
+	Runs []*Run `json:"runs"`
+}
+
+// NewRunStore 获取新对象
+func NewRunStore() *RunStore {
+	return &RunStore{
+		Runs: []*Run{},
+	}
+}
+
+// Add 添加执行记录
+func (this *RunStore) Add(run *Run) {
+	this.Runs = append(this.Runs, run)
+}
+
+// Find 查找执行记录
+func (this *RunStore) Find(runId string) *Run {
+	for _, run := range this.Runs {
+		if run.Id == runId {
+			return run
+		}
+	}
+	return nil
+}
+
+// FindRunbookRuns 查找运行手册的执行记录，按时间倒序排列
+func (this *RunStore) FindRunbookRuns(runbookId string) []*Run {
+	var result = []*Run{}
+	for _, run := range this.Runs {
+		if run.RunbookId == runbookId {
+			result = append(result, run)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt > result[j].CreatedAt
+	})
+	return result
+}
+
+// DeleteRunbookRuns 删除运行手册的所有执行记录
+func (this *RunStore) DeleteRunbookRuns(runbookId string) {
+	var runs = []*Run{}
+	for _, run := range this.Runs {
+		if run.RunbookId != runbookId {
+			runs = append(runs, run)
+		}
+	}
+	this.Runs = runs
+}
+
+// Clean 每个运行手册只保留最近的若干条执行记录
+func (this *RunStore) Clean(keepPerRunbook int) {
+	var countMap = map[string]int{} // runbookId => count
+	var sortedRuns = append([]*Run{}, this.Runs...)
+	sort.Slice(sortedRuns, func(i, j int) bool {
+		return sortedRuns[i].CreatedAt > sortedRuns[j].CreatedAt
+	})
+	var runs = []*Run{}
+	for _, run := range sortedRuns {
+		countMap[run.RunbookId]++
+		if countMap[run.RunbookId] > keepPerRunbook && run.Status != RunStatusRunning {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	this.Runs = runs
+}
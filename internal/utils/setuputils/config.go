@@ -0,0 +1,286 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package setuputils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// API节点模式
+const (
+	APINodeModeNew = "new" // 使用管理系统目录下的edge-api安装新的API节点
+	APINodeModeOld = "old" // 连接已有的API节点
+)
+
+var (
+	hostPieceReg = regexp.MustCompile(`^[\w-]+$`)
+	digitReg     = regexp.MustCompile(`^\d+$`)
+	dbHostReg    = regexp.MustCompile(`^[\w.-]+$`)
+	dbNameReg    = regexp.MustCompile(`^[\w.-]+$`)
+	adminReg     = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+// Config 无人值守安装配置，和安装向导中的各个步骤对应
+type Config struct {
+	APINode *APINodeConfig `yaml:"apiNode"`
+	DB      *DBConfig      `yaml:"db"`
+	Admin   *AdminConfig   `yaml:"admin"`
+	Server  *ServerConfig  `yaml:"server"` // 管理系统HTTP/HTTPS端口，不填则保持server.yaml不变
+}
+
+// APINodeConfig API节点配置
+type APINodeConfig struct {
+	Mode string `yaml:"mode"`
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+
+	// 以下只在连接已有API节点时使用
+	Protocol string `yaml:"protocol"`
+	NodeId   string `yaml:"nodeId"`
+	Secret   string `yaml:"secret"`
+}
+
+// Endpoint 访问地址
+func (this *APINodeConfig) Endpoint() string {
+	var protocol = this.Protocol
+	if this.Mode == APINodeModeNew || len(protocol) == 0 {
+		protocol = "http"
+	}
+	return protocol + "://" + net.JoinHostPort(this.Host, strconv.Itoa(this.Port))
+}
+
+// DBConfig 数据库配置，只在安装新的API节点时使用
+type DBConfig struct {
+	Host              string `yaml:"host"`
+	Port              int    `yaml:"port"`
+	Database          string `yaml:"database"`
+	Username          string `yaml:"username"`
+	Password          string `yaml:"password"`
+	AccessLogKeepDays int    `yaml:"accessLogKeepDays"`
+}
+
+// Addr 数据库地址
+func (this *DBConfig) Addr() string {
+	return net.JoinHostPort(this.Host, strconv.Itoa(this.Port))
+}
+
+// AdminConfig 管理员配置
+type AdminConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// ServerConfig 管理系统监听配置
+type ServerConfig struct {
+	HTTP struct {
+		On     bool     `yaml:"on"`
+		Listen []string `yaml:"listen"`
+	} `yaml:"http"`
+	HTTPS struct {
+		On     bool     `yaml:"on"`
+		Listen []string `yaml:"listen"`
+		Cert   string   `yaml:"cert"`
+		Key    string   `yaml:"key"`
+	} `yaml:"https"`
+}
+
+// LoadConfig 从文件中加载配置
+// 配置中可以使用 ${VAR} 引用环境变量，方便在容器中传入密码等敏感信息
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig([]byte(os.ExpandEnv(string(data))))
+}
+
+// ParseConfig 分析配置内容
+func ParseConfig(data []byte) (*Config, error) {
+	var config = &Config{}
+	err := yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate 检查配置格式，不会连接API节点或数据库
+func (this *Config) Validate() error {
+	// API节点
+	if this.APINode == nil {
+		return errors.New("apiNode: required")
+	}
+	err := this.APINode.validate()
+	if err != nil {
+		return err
+	}
+
+	// 数据库
+	if this.APINode.Mode == APINodeModeNew {
+		if this.DB == nil {
+			return errors.New("db: required when apiNode.mode is '" + APINodeModeNew + "'")
+		}
+		err = this.DB.validate()
+		if err != nil {
+			return err
+		}
+	} else if this.DB != nil && this.DB.AccessLogKeepDays < 0 {
+		return errors.New("db.accessLogKeepDays: should not be negative")
+	}
+
+	// 管理员
+	if this.Admin == nil {
+		return errors.New("admin: required")
+	}
+	err = this.Admin.validate()
+	if err != nil {
+		return err
+	}
+
+	// 监听端口
+	if this.Server != nil {
+		err = this.Server.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *APINodeConfig) validate() error {
+	switch this.Mode {
+	case APINodeModeNew:
+		if this.Port < 1024 || this.Port > 65534 {
+			return errors.New("apiNode.port: should be between 1024 and 65534")
+		}
+		if len(this.Host) == 0 {
+			return errors.New("apiNode.host: required")
+		}
+		if net.ParseIP(this.Host) == nil {
+			// 检查是否为域名
+			var isIP = true
+			for _, piece := range strings.Split(this.Host, ".") {
+				if !hostPieceReg.MatchString(piece) {
+					return errors.New("apiNode.host: invalid host '" + this.Host + "'")
+				}
+				if !digitReg.MatchString(piece) {
+					isIP = false
+				}
+			}
+			if isIP {
+				return errors.New("apiNode.host: invalid host '" + this.Host + "'")
+			}
+		}
+	case APINodeModeOld:
+		if len(this.Host) == 0 {
+			return errors.New("apiNode.host: required")
+		}
+		if this.Port <= 0 || this.Port > 65535 {
+			return errors.New("apiNode.port: invalid port")
+		}
+		if len(this.Protocol) > 0 && this.Protocol != "http" && this.Protocol != "https" {
+			return errors.New("apiNode.protocol: should be 'http' or 'https'")
+		}
+		this.NodeId = strings.Trim(this.NodeId, "\"' ")
+		this.Secret = strings.Trim(this.Secret, "\"' ")
+		if len(this.NodeId) == 0 {
+			return errors.New("apiNode.nodeId: required")
+		}
+		if len(this.Secret) == 0 {
+			return errors.New("apiNode.secret: required")
+		}
+	default:
+		return errors.New("apiNode.mode: should be '" + APINodeModeNew + "' or '" + APINodeModeOld + "'")
+	}
+	return nil
+}
+
+func (this *DBConfig) validate() error {
+	if len(this.Host) == 0 {
+		return errors.New("db.host: required")
+	}
+	if net.ParseIP(this.Host) == nil && !dbHostReg.MatchString(this.Host) {
+		return errors.New("db.host: invalid host '" + this.Host + "'")
+	}
+	if this.Port <= 0 || this.Port > 65535 {
+		return errors.New("db.port: invalid port")
+	}
+	if !dbNameReg.MatchString(this.Database) {
+		return errors.New("db.database: required and should only contain letters, digits, '_', '.' or '-'")
+	}
+	if !dbNameReg.MatchString(this.Username) {
+		return errors.New("db.username: required and should only contain letters, digits, '_', '.' or '-'")
+	}
+	if this.AccessLogKeepDays < 0 {
+		return errors.New("db.accessLogKeepDays: should not be negative")
+	}
+	return nil
+}
+
+func (this *AdminConfig) validate() error {
+	if !adminReg.MatchString(this.Username) {
+		return errors.New("admin.username: required and should only contain letters, digits or '_'")
+	}
+	if !adminReg.MatchString(this.Password) {
+		return errors.New("admin.password: required and should only contain letters, digits or '_'")
+	}
+	return nil
+}
+
+func (this *ServerConfig) validate() error {
+	if !this.HTTP.On && !this.HTTPS.On {
+		return errors.New("server: at least one of http and https should be on")
+	}
+
+	var listenMap = map[string]string{} // port => field
+	var checkListen = func(field string, listenList []string) error {
+		if len(listenList) == 0 {
+			return errors.New(field + ": required")
+		}
+		for _, listen := range listenList {
+			_, port, err := net.SplitHostPort(listen)
+			if err != nil {
+				return fmt.Errorf("%s: invalid address '%s': %w", field, listen, err)
+			}
+			portInt, err := strconv.Atoi(port)
+			if err != nil || portInt <= 0 || portInt > 65535 {
+				return errors.New(field + ": invalid port in '" + listen + "'")
+			}
+			otherField, ok := listenMap[port]
+			if ok && otherField != field {
+				return errors.New(field + ": port " + port + " is already used in " + otherField)
+			}
+			listenMap[port] = field
+		}
+		return nil
+	}
+
+	if this.HTTP.On {
+		err := checkListen("server.http.listen", this.HTTP.Listen)
+		if err != nil {
+			return err
+		}
+	}
+	if this.HTTPS.On {
+		err := checkListen("server.https.listen", this.HTTPS.Listen)
+		if err != nil {
+			return err
+		}
+		if len(this.HTTPS.Cert) == 0 {
+			return errors.New("server.https.cert: required")
+		}
+		if len(this.HTTPS.Key) == 0 {
+			return errors.New("server.https.key: required")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package setuputils_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/setuputils"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	config, err := setuputils.ParseConfig([]byte(`
+apiNode:
+  mode: new
+  host: 127.0.0.1
+  port: 8001
+db:
+  host: 127.0.0.1
+  port: 3306
+  database: edges
+  username: root
+  password: "123456"
+  accessLogKeepDays: 7
+admin:
+  username: admin
+  password: admin123
+server:
+  http:
+    on: true
+    listen: [ ":7788" ]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsNil(config.Validate())
+	a.IsTrue(config.APINode.Endpoint() == "http://127.0.0.1:8001")
+	a.IsTrue(config.DB.Addr() == "127.0.0.1:3306")
+
+	// invalid port
+	config.APINode.Port = 80
+	a.IsNotNil(config.Validate())
+	config.APINode.Port = 8001
+
+	// db is required for new api node
+	var db = config.DB
+	config.DB = nil
+	a.IsNotNil(config.Validate())
+	config.DB = db
+
+	// admin password
+	config.Admin.Password = "abc#"
+	a.IsNotNil(config.Validate())
+	config.Admin.Password = "abc"
+
+	// https
+	config.Server.HTTPS.On = true
+	config.Server.HTTPS.Listen = []string{":7788"}
+	a.IsNotNil(config.Validate())
+	config.Server.HTTPS.Listen = []string{":443"}
+	a.IsNotNil(config.Validate()) // cert is required
+	config.Server.HTTPS.Cert = "a.pem"
+	config.Server.HTTPS.Key = "a.key"
+	a.IsNil(config.Validate())
+}
+
+func TestConfig_Validate_Old(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	config, err := setuputils.ParseConfig([]byte(`
+apiNode:
+  mode: old
+  protocol: https
+  host: "::1"
+  port: 8003
+  nodeId: "'abc'"
+  secret: def
+admin:
+  username: admin
+  password: admin123
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsNil(config.Validate())
+	a.IsTrue(config.APINode.NodeId == "abc")
+	a.IsTrue(config.APINode.Endpoint() == "https://[::1]:8003")
+
+	config.APINode.Mode = "other"
+	a.IsNotNil(config.Validate())
+}
+
+func TestLoadConfig_Env(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var path = t.TempDir() + "/setup.yaml"
+	err := os.WriteFile(path, []byte(`
+admin:
+  username: admin
+  password: ${EDGE_SETUP_TEST_PASSWORD}
+`), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDGE_SETUP_TEST_PASSWORD", "secret123")
+
+	config, err := setuputils.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(config.Admin.Password == "secret123")
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 单点登录协议
+const (
+	ProtocolOIDC = "oidc"
+	ProtocolSAML = "saml"
+)
+
+// 单点登录相关地址
+const (
+	CallbackPath = "/login/sso/callback" // OIDC回调地址和SAML断言消费地址
+	MetadataPath = "/login/sso/metadata" // SAML服务提供方元数据地址
+)
+
+// Config 单点登录配置
+type Config struct {
+	IsOn     bool   `json:"isOn"`
+	Protocol string `json:"protocol"` // ProtocolOIDC 或 ProtocolSAML
+	Name     string `json:"name"`     // 登录按钮上显示的名称
+	BaseURL  string `json:"baseURL"`  // 管理系统访问地址，用于生成回调地址，为空时根据请求自动生成
+
+	OIDC *OIDCConfig `json:"oidc"`
+	SAML *SAMLConfig `json:"saml"`
+
+	GroupMappings        []*GroupMapping `json:"groupMappings"`        // 分组和模块权限对应关系
+	AutoCreate           bool            `json:"autoCreate"`           // 首次登录时自动创建管理员
+	LinkExisting         bool            `json:"linkExisting"`         // 允许关联同名的本地管理员
+	DisableLocalPassword bool            `json:"disableLocalPassword"` // 非超级管理员禁止使用本地密码登录
+}
+
+// OIDCConfig OpenID Connect配置
+type OIDCConfig struct {
+	Issuer        string   `json:"issuer"`
+	ClientId      string   `json:"clientId"`
+	ClientSecret  string   `json:"clientSecret"`
+	Scopes        []string `json:"scopes"`
+	UsernameClaim string   `json:"usernameClaim"` // 默认为 preferred_username
+	GroupsClaim   string   `json:"groupsClaim"`   // 默认为 groups
+}
+
+// SAMLConfig SAML 2.0配置
+type SAMLConfig struct {
+	IdPEntityId       string `json:"idpEntityId"`
+	IdPSSOURL         string `json:"idpSSOURL"`
+	IdPCertificate    string `json:"idpCertificate"` // PEM格式
+	SPEntityId        string `json:"spEntityId"`     // 为空时使用元数据地址
+	UsernameAttribute string `json:"usernameAttribute"`
+	FullnameAttribute string `json:"fullnameAttribute"`
+	GroupsAttribute   string `json:"groupsAttribute"`
+}
+
+// GroupMapping 身份提供商中的分组对应的管理员模块
+type GroupMapping struct {
+	Group       string   `json:"group"`
+	ModuleCodes []string `json:"moduleCodes"`
+}
+
+// NewConfig 获取新对象
+func NewConfig() *Config {
+	return &Config{
+		Protocol: ProtocolOIDC,
+		OIDC: &OIDCConfig{
+			Scopes: []string{"openid", "profile", "email"},
+		},
+		SAML:          &SAMLConfig{},
+		GroupMappings: []*GroupMapping{},
+	}
+}
+
+// Clone 复制对象，并补充为空的子配置
+func (this *Config) Clone() *Config {
+	var config = *this
+	if this.OIDC != nil {
+		var oidc = *this.OIDC
+		oidc.Scopes = append([]string{}, this.OIDC.Scopes...)
+		config.OIDC = &oidc
+	} else {
+		config.OIDC = &OIDCConfig{}
+	}
+	if this.SAML != nil {
+		var saml = *this.SAML
+		config.SAML = &saml
+	} else {
+		config.SAML = &SAMLConfig{}
+	}
+	config.GroupMappings = []*GroupMapping{}
+	for _, mapping := range this.GroupMappings {
+		var newMapping = *mapping
+		newMapping.ModuleCodes = append([]string{}, mapping.ModuleCodes...)
+		config.GroupMappings = append(config.GroupMappings, &newMapping)
+	}
+	return &config
+}
+
+// Validate 校验配置
+func (this *Config) Validate() error {
+	if !this.IsOn {
+		return nil
+	}
+	if len(this.BaseURL) > 0 {
+		u, err := url.Parse(this.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return errors.New("invalid base url '" + this.BaseURL + "'")
+		}
+	}
+	switch this.Protocol {
+	case ProtocolOIDC:
+		if this.OIDC == nil || len(this.OIDC.Issuer) == 0 {
+			return errors.New("oidc issuer should not be empty")
+		}
+		if len(this.OIDC.ClientId) == 0 {
+			return errors.New("oidc client id should not be empty")
+		}
+	case ProtocolSAML:
+		if this.SAML == nil || len(this.SAML.IdPSSOURL) == 0 {
+			return errors.New("saml idp sso url should not be empty")
+		}
+		_, err := ParseCertificate(this.SAML.IdPCertificate)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid protocol '" + this.Protocol + "'")
+	}
+	for _, mapping := range this.GroupMappings {
+		if len(mapping.Group) == 0 {
+			return errors.New("group name should not be empty")
+		}
+	}
+	return nil
+}
+
+// ComposeBaseURL 管理系统访问地址，未设置时根据当前请求生成
+func (this *Config) ComposeBaseURL(req *http.Request) string {
+	if len(this.BaseURL) > 0 {
+		return strings.TrimSuffix(this.BaseURL, "/")
+	}
+	var scheme = "http"
+	if req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host
+}
+
+// UsernameClaimOrDefault 用户名字段
+func (this *OIDCConfig) UsernameClaimOrDefault() string {
+	if len(this.UsernameClaim) > 0 {
+		return this.UsernameClaim
+	}
+	return "preferred_username"
+}
+
+// GroupsClaimOrDefault 分组字段
+func (this *OIDCConfig) GroupsClaimOrDefault() string {
+	if len(this.GroupsClaim) > 0 {
+		return this.GroupsClaim
+	}
+	return "groups"
+}
+
+// MapModules 根据分组计算模块权限，matched 表示是否有分组匹配
+func (this *Config) MapModules(groups []string) (moduleCodes []string, matched bool) {
+	moduleCodes = []string{}
+	for _, mapping := range this.GroupMappings {
+		for _, group := range groups {
+			if !strings.EqualFold(mapping.Group, group) {
+				continue
+			}
+			matched = true
+			for _, code := range mapping.ModuleCodes {
+				if !containsString(moduleCodes, code) {
+					moduleCodes = append(moduleCodes, code)
+				}
+			}
+		}
+	}
+	return
+}
+
+// CheckLinkExisting 检查能否把用户关联到同名的本地管理员
+// 超级管理员不能自动关联，防止身份提供商中的同名用户获得超级管理员权限
+func (this *Config) CheckLinkExisting(username string, isSuper bool) error {
+	if isSuper {
+		return errors.New("不能自动关联超级管理员'" + username + "'")
+	}
+	if !this.LinkExisting {
+		return errors.New("已存在用户名为'" + username + "'的管理员，但不允许关联已有的管理员")
+	}
+	return nil
+}
+
+// Identity 身份提供商返回的用户信息
+type Identity struct {
+	Subject  string
+	Username string
+	Fullname string
+	Email    string
+	Groups   []string
+}
+
+// NormalizeUsername 转换为管理员用户名允许的格式（英文、数字或下划线）
+func NormalizeUsername(username string) string {
+	var builder = strings.Builder{}
+	for _, r := range username {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune('_')
+		}
+	}
+	return builder.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils_test
+
+import (
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+)
+
+func TestConfig_CheckLinkExisting(t *testing.T) {
+	var config = ssoutils.NewConfig()
+	if config.CheckLinkExisting("alice", false) == nil {
+		t.Fatal("should not link when LinkExisting is off")
+	}
+
+	config.LinkExisting = true
+	if err := config.CheckLinkExisting("alice", false); err != nil {
+		t.Fatal(err)
+	}
+	if config.CheckLinkExisting("admin", true) == nil {
+		t.Fatal("should never link a super admin")
+	}
+}
+
+func TestConfig_Clone(t *testing.T) {
+	var config = &ssoutils.Config{
+		Protocol: ssoutils.ProtocolOIDC,
+		OIDC:     &ssoutils.OIDCConfig{Scopes: []string{"openid"}},
+		GroupMappings: []*ssoutils.GroupMapping{
+			{Group: "ops", ModuleCodes: []string{"node"}},
+		},
+	}
+	var cloned = config.Clone()
+	if cloned.SAML == nil {
+		t.Fatal("empty SAML config should be filled")
+	}
+	cloned.OIDC.Scopes[0] = "email"
+	cloned.GroupMappings[0].ModuleCodes[0] = "server"
+	if config.OIDC.Scopes[0] != "openid" || config.GroupMappings[0].ModuleCodes[0] != "node" {
+		t.Fatal("clone should not share fields")
+	}
+}
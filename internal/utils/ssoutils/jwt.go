@@ -0,0 +1,194 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// JSONWebKey JWKS中的一个公钥
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JSONWebKeySet JWKS
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
+
+// PublicKey 转换为公钥
+func (this *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch this.Kty {
+	case "RSA":
+		nBytes, err := base64.RawURLEncoding.DecodeString(this.N)
+		if err != nil {
+			return nil, err
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(this.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(nBytes),
+			E: int(new(big.Int).SetBytes(eBytes).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch this.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported curve '" + this.Crv + "'")
+		}
+		xBytes, err := base64.RawURLEncoding.DecodeString(this.X)
+		if err != nil {
+			return nil, err
+		}
+		yBytes, err := base64.RawURLEncoding.DecodeString(this.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(xBytes),
+			Y:     new(big.Int).SetBytes(yBytes),
+		}, nil
+	}
+	return nil, errors.New("unsupported key type '" + this.Kty + "'")
+}
+
+// 根据kid查找公钥，kid为空并且只有一个公钥时使用此公钥
+func (this *JSONWebKeySet) find(kid string) *JSONWebKey {
+	for _, key := range this.Keys {
+		if key.Use == "enc" {
+			continue
+		}
+		if key.Kid == kid {
+			return key
+		}
+	}
+	if len(kid) == 0 && len(this.Keys) == 1 {
+		return this.Keys[0]
+	}
+	return nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyJWT 校验JWT签名并返回其中的声明
+func VerifyJWT(token string, keySet *JSONWebKeySet) (map[string]any, error) {
+	var pieces = strings.Split(token, ".")
+	if len(pieces) != 3 {
+		return nil, errors.New("invalid jwt format")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(pieces[0])
+	if err != nil {
+		return nil, errors.New("invalid jwt header: " + err.Error())
+	}
+	var header = &jwtHeader{}
+	err = json.Unmarshal(headerJSON, header)
+	if err != nil {
+		return nil, errors.New("invalid jwt header: " + err.Error())
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(pieces[2])
+	if err != nil {
+		return nil, errors.New("invalid jwt signature: " + err.Error())
+	}
+
+	var key = keySet.find(header.Kid)
+	if key == nil {
+		return nil, errors.New("can not find key '" + header.Kid + "' in jwks")
+	}
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var signed = []byte(pieces[0] + "." + pieces[1])
+	err = verifySignature(header.Alg, publicKey, signed, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(pieces[1])
+	if err != nil {
+		return nil, errors.New("invalid jwt payload: " + err.Error())
+	}
+	var claims = map[string]any{}
+	err = json.Unmarshal(payloadJSON, &claims)
+	if err != nil {
+		return nil, errors.New("invalid jwt payload: " + err.Error())
+	}
+	return claims, nil
+}
+
+func verifySignature(alg string, publicKey crypto.PublicKey, signed []byte, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		// 不支持none和HMAC等算法
+		return errors.New("unsupported jwt algorithm '" + alg + "'")
+	}
+	var hasher = hash.New()
+	hasher.Write(signed)
+	var digest = hasher.Sum(nil)
+
+	switch alg[0] {
+	case 'R', 'P':
+		rsaKey, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm '" + alg + "'")
+		}
+		if alg[0] == 'P' {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	case 'E':
+		ecKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm '" + alg + "'")
+		}
+		var size = len(signature) / 2
+		if size == 0 || len(signature)%2 != 0 {
+			return errors.New("invalid ecdsa signature")
+		}
+		var r = new(big.Int).SetBytes(signature[:size])
+		var s = new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("invalid ecdsa signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported jwt algorithm '" + alg + "'")
+}
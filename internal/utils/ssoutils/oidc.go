@@ -0,0 +1,212 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 允许的时钟误差
+const clockSkew = 2 * time.Minute
+
+// OIDCProvider OpenID Connect身份提供商
+type OIDCProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	config     *OIDCConfig
+	httpClient *http.Client
+}
+
+// DiscoverOIDCProvider 通过 .well-known/openid-configuration 读取身份提供商信息
+func DiscoverOIDCProvider(ctx context.Context, httpClient *http.Client, config *OIDCConfig) (*OIDCProvider, error) {
+	var discoveryURL = strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	var provider = &OIDCProvider{}
+	err := getJSON(ctx, httpClient, discoveryURL, provider)
+	if err != nil {
+		return nil, fmt.Errorf("discover oidc provider failed: %w", err)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != strings.TrimSuffix(config.Issuer, "/") {
+		return nil, errors.New("issuer in discovery document '" + provider.Issuer + "' does not match '" + config.Issuer + "'")
+	}
+	if len(provider.AuthorizationEndpoint) == 0 || len(provider.TokenEndpoint) == 0 || len(provider.JWKSURI) == 0 {
+		return nil, errors.New("invalid discovery document")
+	}
+	provider.config = config
+	provider.httpClient = httpClient
+	return provider, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供商的授权地址
+func (this *OIDCProvider) AuthCodeURL(redirectURI string, state *State) string {
+	var scopes = this.config.Scopes
+	if !containsString(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	var query = url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", this.config.ClientId)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state.Value)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", CodeChallenge(state.CodeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	var endpoint = this.AuthorizationEndpoint
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+	return endpoint + "?" + query.Encode()
+}
+
+// Exchange 使用授权码换取ID Token，并校验后返回用户信息
+func (this *OIDCProvider) Exchange(ctx context.Context, code string, redirectURI string, state *State) (*Identity, error) {
+	var form = url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", this.config.ClientId)
+	form.Set("code_verifier", state.CodeVerifier)
+	if len(this.config.ClientSecret) > 0 {
+		form.Set("client_secret", this.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, this.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := this.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp = struct {
+		IdToken string `json:"id_token"`
+	}{}
+	err = json.Unmarshal(body, &tokenResp)
+	if err != nil {
+		return nil, fmt.Errorf("decode token response failed: %w", err)
+	}
+	if len(tokenResp.IdToken) == 0 {
+		return nil, errors.New("no id_token in token response")
+	}
+
+	var keySet = &JSONWebKeySet{}
+	err = getJSON(ctx, this.httpClient, this.JWKSURI, keySet)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks failed: %w", err)
+	}
+	return this.VerifyIDToken(tokenResp.IdToken, keySet, state.Nonce, time.Now())
+}
+
+// VerifyIDToken 校验ID Token
+func (this *OIDCProvider) VerifyIDToken(idToken string, keySet *JSONWebKeySet, nonce string, now time.Time) (*Identity, error) {
+	claims, err := VerifyJWT(idToken, keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimString(claims, "iss") != this.Issuer {
+		return nil, errors.New("invalid issuer '" + claimString(claims, "iss") + "'")
+	}
+	if !containsString(claimStrings(claims, "aud"), this.config.ClientId) {
+		return nil, errors.New("id token is not issued for this client")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Unix(int64(exp), 0).Add(clockSkew).Before(now) {
+		return nil, errors.New("id token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && time.Unix(int64(nbf), 0).Add(-clockSkew).After(now) {
+		return nil, errors.New("id token is not valid yet")
+	}
+	if claimString(claims, "nonce") != nonce {
+		return nil, errors.New("invalid nonce")
+	}
+
+	var identity = &Identity{
+		Subject:  claimString(claims, "sub"),
+		Username: claimString(claims, this.config.UsernameClaimOrDefault()),
+		Fullname: claimString(claims, "name"),
+		Email:    claimString(claims, "email"),
+		Groups:   claimStrings(claims, this.config.GroupsClaimOrDefault()),
+	}
+	if len(identity.Subject) == 0 {
+		return nil, errors.New("no subject in id token")
+	}
+	if len(identity.Username) == 0 {
+		identity.Username = identity.Email
+	}
+	if len(identity.Username) == 0 {
+		return nil, errors.New("no username claim '" + this.config.UsernameClaimOrDefault() + "' in id token")
+	}
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("'%s' returned status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func claimString(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// 读取字符串数组，也支持单个字符串或以逗号分隔的字符串
+func claimStrings(claims map[string]any, name string) []string {
+	var result = []string{}
+	switch v := claims[name].(type) {
+	case string:
+		for _, piece := range strings.Split(v, ",") {
+			piece = strings.TrimSpace(piece)
+			if len(piece) > 0 {
+				result = append(result, piece)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+)
+
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]any) string {
+	headerJSON, _ := json.Marshal(map[string]any{"alg": "RS256", "kid": "k1"})
+	claimsJSON, _ := json.Marshal(claims)
+	var signed = base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	var digest = sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serverURL string
+	var nonce = "n-123"
+	var verifier = ssoutils.NewCodeVerifier()
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 serverURL,
+			"authorization_endpoint": serverURL + "/authorize",
+			"token_endpoint":         serverURL + "/token",
+			"jwks_uri":               serverURL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		// 校验PKCE
+		if r.PostForm.Get("code") != "c1" || r.PostForm.Get("code_verifier") != verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id_token": signTestJWT(t, key, map[string]any{
+				"iss":                serverURL,
+				"aud":                "admin",
+				"sub":                "u1",
+				"exp":                time.Now().Add(time.Hour).Unix(),
+				"nonce":              nonce,
+				"preferred_username": "alice",
+				"groups":             []string{"ops", "dev"},
+			}),
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{
+				{
+					"kid": "k1",
+					"kty": "RSA",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+
+	var config = &ssoutils.OIDCConfig{
+		Issuer:   serverURL,
+		ClientId: "admin",
+		Scopes:   []string{"profile"},
+	}
+	provider, err := ssoutils.DiscoverOIDCProvider(context.Background(), server.Client(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state = &ssoutils.State{Value: "s1", Nonce: nonce, CodeVerifier: verifier}
+	authURL, err := url.Parse(provider.AuthCodeURL("https://admin.example.com/login/sso/callback", state))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var query = authURL.Query()
+	if query.Get("code_challenge") != ssoutils.CodeChallenge(verifier) || query.Get("code_challenge_method") != "S256" {
+		t.Fatal("invalid pkce parameters:", authURL.String())
+	}
+	if !strings.HasPrefix(query.Get("scope"), "openid") {
+		t.Fatal("openid scope should be added:", query.Get("scope"))
+	}
+
+	identity, err := provider.Exchange(context.Background(), "c1", "https://admin.example.com/login/sso/callback", state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" || len(identity.Groups) != 2 {
+		t.Fatal("unexpected identity:", identity.Username, identity.Groups)
+	}
+
+	// nonce不一致
+	state.Nonce = "other"
+	_, err = provider.Exchange(context.Background(), "c1", "https://admin.example.com/login/sso/callback", state)
+	if err == nil {
+		t.Fatal("nonce mismatch should fail")
+	}
+}
+
+func TestVerifyJWT_Tampered(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keySet = &ssoutils.JSONWebKeySet{Keys: []*ssoutils.JSONWebKey{{
+		Kid: "k1",
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	var token = signTestJWT(t, key, map[string]any{"sub": "u1"})
+	_, err = ssoutils.VerifyJWT(token, keySet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pieces = strings.Split(token, ".")
+	pieces[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+	_, err = ssoutils.VerifyJWT(strings.Join(pieces, "."), keySet)
+	if err == nil {
+		t.Fatal("tampered token should fail")
+	}
+
+	// 不允许none算法
+	var noneToken = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`)) + "." + pieces[1] + "."
+	_, err = ssoutils.VerifyJWT(noneToken, keySet)
+	if err == nil {
+		t.Fatal("none algorithm should fail")
+	}
+}
+
+func TestConfig_MapModules(t *testing.T) {
+	var config = ssoutils.NewConfig()
+	config.GroupMappings = []*ssoutils.GroupMapping{
+		{Group: "ops", ModuleCodes: []string{"node", "server"}},
+		{Group: "dev", ModuleCodes: []string{"server", "log"}},
+	}
+	moduleCodes, matched := config.MapModules([]string{"OPS", "dev", "other"})
+	if !matched || strings.Join(moduleCodes, ",") != "node,server,log" {
+		t.Fatal("unexpected modules:", moduleCodes)
+	}
+	_, matched = config.MapModules([]string{"other"})
+	if matched {
+		t.Fatal("should not match")
+	}
+	if ssoutils.NormalizeUsername("alice.w@example.com") != "alice_w_example_com" {
+		t.Fatal("unexpected username")
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	nsSAMLProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsSAMLAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsSAMLMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+
+	samlStatusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBindingPOST   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlTimeFormat    = "2006-01-02T15:04:05Z"
+)
+
+// SAMLServiceProvider SAML服务提供方（即当前管理系统）
+type SAMLServiceProvider struct {
+	Config   *SAMLConfig
+	EntityId string // 服务提供方ID
+	ACSURL   string // 断言消费地址
+}
+
+// NewSAMLServiceProvider 获取新对象
+func NewSAMLServiceProvider(config *SAMLConfig, metadataURL string, acsURL string) *SAMLServiceProvider {
+	var entityId = config.SPEntityId
+	if len(entityId) == 0 {
+		entityId = metadataURL
+	}
+	return &SAMLServiceProvider{
+		Config:   config,
+		EntityId: entityId,
+		ACSURL:   acsURL,
+	}
+}
+
+// AuthnRequestURL 生成使用HTTP-Redirect方式发送认证请求的地址
+func (this *SAMLServiceProvider) AuthnRequestURL(requestId string, relayState string, now time.Time) (string, error) {
+	var buf = &bytes.Buffer{}
+	buf.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + nsSAMLProtocol + `" xmlns:saml="` + nsSAMLAssertion + `"`)
+	buf.WriteString(` ID="` + escapeAttr(requestId) + `" Version="2.0" IssueInstant="` + now.UTC().Format(samlTimeFormat) + `"`)
+	buf.WriteString(` Destination="` + escapeAttr(this.Config.IdPSSOURL) + `"`)
+	buf.WriteString(` ProtocolBinding="` + samlBindingPOST + `" AssertionConsumerServiceURL="` + escapeAttr(this.ACSURL) + `">`)
+	buf.WriteString(`<saml:Issuer>` + escapeText(this.EntityId) + `</saml:Issuer>`)
+	buf.WriteString(`<samlp:NameIDPolicy Format="urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified" AllowCreate="true"/>`)
+	buf.WriteString(`</samlp:AuthnRequest>`)
+
+	var deflated = &bytes.Buffer{}
+	writer, err := flate.NewWriter(deflated, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	_, err = writer.Write(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	var query = url.Values{}
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if len(relayState) > 0 {
+		query.Set("RelayState", relayState)
+	}
+	var ssoURL = this.Config.IdPSSOURL
+	if strings.Contains(ssoURL, "?") {
+		return ssoURL + "&" + query.Encode(), nil
+	}
+	return ssoURL + "?" + query.Encode(), nil
+}
+
+// Metadata 服务提供方元数据
+func (this *SAMLServiceProvider) Metadata() []byte {
+	var buf = &bytes.Buffer{}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<md:EntityDescriptor xmlns:md="` + nsSAMLMetadata + `" entityID="` + escapeAttr(this.EntityId) + `">`)
+	buf.WriteString(`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + nsSAMLProtocol + `">`)
+	buf.WriteString(`<md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified</md:NameIDFormat>`)
+	buf.WriteString(`<md:AssertionConsumerService Binding="` + samlBindingPOST + `" Location="` + escapeAttr(this.ACSURL) + `" index="0" isDefault="true"/>`)
+	buf.WriteString(`</md:SPSSODescriptor>`)
+	buf.WriteString(`</md:EntityDescriptor>`)
+	return buf.Bytes()
+}
+
+// ParseResponse 解析并校验身份提供商通过HTTP-POST发送的SAMLResponse
+// 返回用户信息和响应对应的请求ID，调用者需要检查请求ID是否由自己发出
+func (this *SAMLServiceProvider) ParseResponse(samlResponse string, now time.Time) (identity *Identity, inResponseTo string, err error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(samlResponse), ""))
+	if err != nil {
+		return nil, "", errors.New("invalid SAMLResponse encoding")
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if !root.is(nsSAMLProtocol, "Response") {
+		return nil, "", errors.New("SAMLResponse should be a Response element")
+	}
+
+	// 状态
+	var status = root.child(nsSAMLProtocol, "Status")
+	if status == nil || status.child(nsSAMLProtocol, "StatusCode") == nil {
+		return nil, "", errors.New("no status in SAMLResponse")
+	}
+	var statusCode = status.child(nsSAMLProtocol, "StatusCode").attr("Value")
+	if statusCode != samlStatusSuccess {
+		return nil, "", errors.New("identity provider returned status '" + statusCode + "'")
+	}
+
+	var destination = root.attr("Destination")
+	if len(destination) > 0 && destination != this.ACSURL {
+		return nil, "", errors.New("invalid destination '" + destination + "'")
+	}
+
+	if len(root.children(nsSAMLAssertion, "EncryptedAssertion")) > 0 {
+		return nil, "", errors.New("encrypted assertions are not supported")
+	}
+	var assertions = root.children(nsSAMLAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, "", errors.New("SAMLResponse should contain exactly one assertion")
+	}
+	var assertion = assertions[0]
+
+	cert, err := ParseCertificate(this.Config.IdPCertificate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 整个响应或者断言需要有签名，后续只读取已经校验过的节点中的数据
+	var verified = false
+	var responseSigned = false
+	if signature := root.child(nsDSig, "Signature"); signature != nil {
+		_, err = verifyEnvelopedSignature(signature, cert)
+		if err != nil {
+			return nil, "", errors.New("verify response signature failed: " + err.Error())
+		}
+		verified = true
+		responseSigned = true
+	}
+	if signature := assertion.child(nsDSig, "Signature"); signature != nil {
+		_, err = verifyEnvelopedSignature(signature, cert)
+		if err != nil {
+			return nil, "", errors.New("verify assertion signature failed: " + err.Error())
+		}
+		verified = true
+	}
+	if !verified {
+		return nil, "", errors.New("SAMLResponse is not signed")
+	}
+
+	// 签发者
+	var issuer = assertion.child(nsSAMLAssertion, "Issuer")
+	if len(this.Config.IdPEntityId) > 0 && (issuer == nil || strings.TrimSpace(issuer.text()) != this.Config.IdPEntityId) {
+		return nil, "", errors.New("invalid assertion issuer")
+	}
+
+	// 有效期和受众
+	var conditions = assertion.child(nsSAMLAssertion, "Conditions")
+	if conditions != nil {
+		err = checkTimeRange(conditions.attr("NotBefore"), conditions.attr("NotOnOrAfter"), now)
+		if err != nil {
+			return nil, "", err
+		}
+		var restrictions = conditions.children(nsSAMLAssertion, "AudienceRestriction")
+		for _, restriction := range restrictions {
+			var matched = false
+			for _, audience := range restriction.children(nsSAMLAssertion, "Audience") {
+				if strings.TrimSpace(audience.text()) == this.EntityId {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, "", errors.New("assertion is not issued for '" + this.EntityId + "'")
+			}
+		}
+	}
+
+	// 主体
+	var subject = assertion.child(nsSAMLAssertion, "Subject")
+	if subject == nil {
+		return nil, "", errors.New("no subject in assertion")
+	}
+	var nameId = subject.child(nsSAMLAssertion, "NameID")
+	if nameId == nil || len(strings.TrimSpace(nameId.text())) == 0 {
+		return nil, "", errors.New("no NameID in assertion")
+	}
+	// 只有断言有签名时，不能信任响应节点上的InResponseTo
+	if responseSigned {
+		inResponseTo = root.attr("InResponseTo")
+	}
+	for _, confirmation := range subject.children(nsSAMLAssertion, "SubjectConfirmation") {
+		var confirmationData = confirmation.child(nsSAMLAssertion, "SubjectConfirmationData")
+		if confirmationData == nil {
+			continue
+		}
+		err = checkTimeRange(confirmationData.attr("NotBefore"), confirmationData.attr("NotOnOrAfter"), now)
+		if err != nil {
+			return nil, "", err
+		}
+		var recipient = confirmationData.attr("Recipient")
+		if len(recipient) > 0 && recipient != this.ACSURL {
+			return nil, "", errors.New("invalid recipient '" + recipient + "'")
+		}
+		var confirmationInResponseTo = confirmationData.attr("InResponseTo")
+		if len(confirmationInResponseTo) > 0 {
+			if len(inResponseTo) > 0 && inResponseTo != confirmationInResponseTo {
+				return nil, "", errors.New("InResponseTo mismatch")
+			}
+			inResponseTo = confirmationInResponseTo
+		}
+	}
+
+	// 属性
+	var attributes = map[string][]string{}
+	for _, statement := range assertion.children(nsSAMLAssertion, "AttributeStatement") {
+		for _, attribute := range statement.children(nsSAMLAssertion, "Attribute") {
+			var values = []string{}
+			for _, value := range attribute.children(nsSAMLAssertion, "AttributeValue") {
+				values = append(values, strings.TrimSpace(value.text()))
+			}
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if len(name) > 0 {
+					attributes[name] = append(attributes[name], values...)
+				}
+			}
+		}
+	}
+	var firstAttribute = func(name string) string {
+		if len(name) == 0 || len(attributes[name]) == 0 {
+			return ""
+		}
+		return attributes[name][0]
+	}
+
+	identity = &Identity{
+		Subject:  strings.TrimSpace(nameId.text()),
+		Username: firstAttribute(this.Config.UsernameAttribute),
+		Fullname: firstAttribute(this.Config.FullnameAttribute),
+		Groups:   []string{},
+	}
+	if len(identity.Username) == 0 {
+		identity.Username = identity.Subject
+	}
+	if len(this.Config.GroupsAttribute) > 0 {
+		identity.Groups = append(identity.Groups, attributes[this.Config.GroupsAttribute]...)
+	}
+	return identity, inResponseTo, nil
+}
+
+func checkTimeRange(notBefore string, notOnOrAfter string, now time.Time) error {
+	if len(notBefore) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, notBefore)
+		if err != nil {
+			return errors.New("invalid NotBefore '" + notBefore + "'")
+		}
+		if now.Add(clockSkew).Before(t) {
+			return errors.New("assertion is not valid yet")
+		}
+	}
+	if len(notOnOrAfter) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, notOnOrAfter)
+		if err != nil {
+			return errors.New("invalid NotOnOrAfter '" + notOnOrAfter + "'")
+		}
+		if !now.Add(-clockSkew).Before(t) {
+			return errors.New("assertion has expired")
+		}
+	}
+	return nil
+}
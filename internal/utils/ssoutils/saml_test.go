@@ -0,0 +1,170 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalize(t *testing.T) {
+	root, err := parseXMLTree([]byte(`<a:root xmlns:a="urn:a" xmlns:b="urn:b" a:y="2" z="1"><b:child/><c xmlns="urn:c">t&amp;&lt;</c><a:d   b:x="v&quot;"/></a:root>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result = string(canonicalize(root, nil, nil))
+	var expected = `<a:root xmlns:a="urn:a" z="1" a:y="2"><b:child xmlns:b="urn:b"></b:child><c xmlns="urn:c">t&amp;&lt;</c><a:d xmlns:b="urn:b" b:x="v&quot;"></a:d></a:root>`
+	if result != expected {
+		t.Fatal("unexpected result:\n" + result + "\n" + expected)
+	}
+
+	// 子集中需要输出上级声明的命名空间
+	var child = root.Children[1].(*xmlNode)
+	result = string(canonicalize(child, nil, nil))
+	if result != `<c xmlns="urn:c">t&amp;&lt;</c>` {
+		t.Fatal("unexpected result:", result)
+	}
+	var d = root.Children[2].(*xmlNode)
+	result = string(canonicalize(d, nil, nil))
+	if result != `<a:d xmlns:a="urn:a" xmlns:b="urn:b" b:x="v&quot;"></a:d>` {
+		t.Fatal("unexpected result:", result)
+	}
+}
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template = &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// 生成断言带有签名的响应
+func newTestSAMLResponse(t *testing.T, key *rsa.PrivateKey, username string, now time.Time) string {
+	var assertion = `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1" Version="2.0" IssueInstant="` + now.UTC().Format(samlTimeFormat) + `">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` +
+		`<saml:Subject><saml:NameID>` + username + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData InResponseTo="_r1" Recipient="https://admin.example.com/login/sso/acs" NotOnOrAfter="` + now.Add(5*time.Minute).UTC().Format(samlTimeFormat) + `"/></saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + now.Add(-time.Minute).UTC().Format(samlTimeFormat) + `" NotOnOrAfter="` + now.Add(5*time.Minute).UTC().Format(samlTimeFormat) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>https://admin.example.com/login/sso/metadata</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AttributeStatement><saml:Attribute Name="groups"><saml:AttributeValue>ops</saml:AttributeValue><saml:AttributeValue>dev</saml:AttributeValue></saml:Attribute></saml:AttributeStatement>` +
+		`</saml:Assertion>`
+
+	assertionNode, err := parseXMLTree([]byte(assertion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digest = sha256.Sum256(canonicalize(assertionNode, nil, nil))
+	var signedInfo = `<ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>` +
+		`<ds:Reference URI="#_a1"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/></ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue></ds:Reference></ds:SignedInfo>`
+	var signature = `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` + signedInfo + `<ds:SignatureValue>SIGNATURE</ds:SignatureValue></ds:Signature>`
+	assertion = strings.Replace(assertion, `</saml:Issuer>`, `</saml:Issuer>`+signature, 1)
+
+	// 对SignedInfo签名
+	signedAssertion, err := parseXMLTree([]byte(assertion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signedInfoNode = signedAssertion.child(nsDSig, "Signature").child(nsDSig, "SignedInfo")
+	var signedInfoDigest = sha256.Sum256(canonicalize(signedInfoNode, nil, nil))
+	signatureValue, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, signedInfoDigest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion = strings.Replace(assertion, "SIGNATURE", base64.StdEncoding.EncodeToString(signatureValue), 1)
+
+	var response = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_resp1" Version="2.0" InResponseTo="_r1" Destination="https://admin.example.com/login/sso/acs">` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` + assertion + `</samlp:Response>`
+	return response
+}
+
+func TestSAMLServiceProvider_ParseResponse(t *testing.T) {
+	key, certPEM := newTestCertificate(t)
+	var sp = NewSAMLServiceProvider(&SAMLConfig{
+		IdPEntityId:     "https://idp.example.com",
+		IdPSSOURL:       "https://idp.example.com/sso",
+		IdPCertificate:  certPEM,
+		GroupsAttribute: "groups",
+	}, "https://admin.example.com/login/sso/metadata", "https://admin.example.com/login/sso/acs")
+
+	var now = time.Now()
+	var response = newTestSAMLResponse(t, key, "alice", now)
+	identity, inResponseTo, err := sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(response)), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" || inResponseTo != "_r1" || strings.Join(identity.Groups, ",") != "ops,dev" {
+		t.Fatal("unexpected identity:", identity.Username, inResponseTo, identity.Groups)
+	}
+
+	// 篡改用户名
+	var tampered = strings.Replace(response, "<saml:NameID>alice<", "<saml:NameID>admin<", 1)
+	_, _, err = sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(tampered)), now)
+	if err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Fatal("tampered response should fail, got:", err)
+	}
+
+	// 过期
+	_, _, err = sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(response)), now.Add(time.Hour))
+	if err == nil {
+		t.Fatal("expired response should fail")
+	}
+
+	// 其他证书
+	_, otherCertPEM := newTestCertificate(t)
+	sp.Config.IdPCertificate = otherCertPEM
+	_, _, err = sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(response)), now)
+	if err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatal("signature from other key should fail, got:", err)
+	}
+}
+
+func TestSAMLServiceProvider_Unsigned(t *testing.T) {
+	_, certPEM := newTestCertificate(t)
+	var sp = NewSAMLServiceProvider(&SAMLConfig{
+		IdPSSOURL:      "https://idp.example.com/sso",
+		IdPCertificate: certPEM,
+	}, "https://admin.example.com/login/sso/metadata", "https://admin.example.com/login/sso/acs")
+	var response = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_resp1"><samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1"><saml:Subject><saml:NameID>admin</saml:NameID></saml:Subject></saml:Assertion></samlp:Response>`
+	_, _, err := sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(response)), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatal("unsigned response should fail, got:", err)
+	}
+}
+
+func TestSAMLServiceProvider_AuthnRequestURL(t *testing.T) {
+	var sp = NewSAMLServiceProvider(&SAMLConfig{IdPSSOURL: "https://idp.example.com/sso?tenant=1"}, "https://admin.example.com/login/sso/metadata", "https://admin.example.com/login/sso/acs")
+	u, err := sp.AuthnRequestURL("_r1", "relay", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u, "https://idp.example.com/sso?tenant=1&") || !strings.Contains(u, "SAMLRequest=") {
+		t.Fatal("unexpected url:", u)
+	}
+	if !strings.Contains(string(sp.Metadata()), `entityID="https://admin.example.com/login/sso/metadata"`) {
+		t.Fatal("unexpected metadata")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// StateTTL 登录流程的有效期
+const StateTTL = 10 * time.Minute
+
+// State 一次登录流程的状态
+type State struct {
+	Value        string // OIDC中的state，SAML中的请求ID
+	Nonce        string
+	CodeVerifier string
+	Redirect     string // 登录成功后跳转的地址
+	expiresAt    time.Time
+}
+
+// StateStore 保存尚未完成的登录流程
+type StateStore struct {
+	states map[string]*State
+	locker sync.Mutex
+}
+
+// NewStateStore 获取新对象
+func NewStateStore() *StateStore {
+	return &StateStore{
+		states: map[string]*State{},
+	}
+}
+
+// Put 保存状态
+func (this *StateStore) Put(state *State) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	var now = time.Now()
+	for key, s := range this.states {
+		if s.expiresAt.Before(now) {
+			delete(this.states, key)
+		}
+	}
+	state.expiresAt = now.Add(StateTTL)
+	this.states[state.Value] = state
+}
+
+// Take 取出状态，每个状态只能使用一次
+func (this *StateStore) Take(value string) (state *State, ok bool) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	state, ok = this.states[value]
+	if !ok {
+		return nil, false
+	}
+	delete(this.states, value)
+	if state.expiresAt.Before(time.Now()) {
+		return nil, false
+	}
+	return state, true
+}
+
+// NewNonce 生成state和nonce使用的随机值
+func NewNonce() string {
+	var b = make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewCodeVerifier 生成PKCE中的code_verifier
+func NewCodeVerifier() string {
+	var b = make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CodeChallenge 根据code_verifier计算S256方式的code_challenge
+func CodeChallenge(verifier string) string {
+	var sum = sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,439 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package ssoutils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// 只实现了SAML中常用的XML签名方式：enveloped-signature + 排他型规范化（exc-c14n）
+
+const (
+	nsXML   = "http://www.w3.org/XML/1998/namespace"
+	nsDSig  = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcCN = "http://www.w3.org/2001/10/xml-exc-c14n#"
+
+	algEnvelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+var digestMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":        crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256":       crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmlenc#sha512":       crypto.SHA512,
+}
+
+var signatureMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1":          crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384":   crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+type xmlAttr struct {
+	Prefix string
+	Local  string
+	Value  string
+}
+
+// 保留了命名空间前缀的XML节点，用于规范化
+type xmlNode struct {
+	Prefix   string
+	Local    string
+	Attrs    []*xmlAttr
+	NS       map[string]string // 在当前节点上声明的命名空间：prefix => uri
+	Children []any             // *xmlNode 或 string
+	Parent   *xmlNode
+}
+
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	var decoder = xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var current *xmlNode
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var node = &xmlNode{
+				Prefix: t.Name.Space,
+				Local:  t.Name.Local,
+				NS:     map[string]string{},
+				Parent: current,
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					node.NS[attr.Name.Local] = attr.Value
+				} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					node.NS[""] = attr.Value
+				} else {
+					node.Attrs = append(node.Attrs, &xmlAttr{
+						Prefix: attr.Name.Space,
+						Local:  attr.Name.Local,
+						Value:  attr.Value,
+					})
+				}
+			}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("xml: multiple root elements")
+				}
+				root = node
+			} else {
+				current.Children = append(current.Children, node)
+			}
+			current = node
+		case xml.EndElement:
+			if current == nil || current.Prefix != t.Name.Space || current.Local != t.Name.Local {
+				return nil, errors.New("xml: unexpected end element '" + t.Name.Local + "'")
+			}
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				current.Children = append(current.Children, string(t))
+			}
+		case xml.Directive:
+			// 不允许DTD，防止实体注入
+			return nil, errors.New("xml: directives are not allowed")
+		}
+	}
+	if root == nil {
+		return nil, errors.New("xml: no root element")
+	}
+	if current != nil {
+		return nil, errors.New("xml: unexpected end of document")
+	}
+	return root, nil
+}
+
+// 查找前缀对应的命名空间
+func (this *xmlNode) lookupNS(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for node := this; node != nil; node = node.Parent {
+		uri, ok := node.NS[prefix]
+		if ok {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+// Namespace 当前节点的命名空间
+func (this *xmlNode) Namespace() string {
+	uri, _ := this.lookupNS(this.Prefix)
+	return uri
+}
+
+func (this *xmlNode) is(namespace string, local string) bool {
+	return this.Local == local && this.Namespace() == namespace
+}
+
+func (this *xmlNode) attr(local string) string {
+	for _, attr := range this.Attrs {
+		if attr.Prefix == "" && attr.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (this *xmlNode) children(namespace string, local string) []*xmlNode {
+	var result = []*xmlNode{}
+	for _, child := range this.Children {
+		node, ok := child.(*xmlNode)
+		if ok && node.is(namespace, local) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func (this *xmlNode) child(namespace string, local string) *xmlNode {
+	var nodes = this.children(namespace, local)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+func (this *xmlNode) text() string {
+	var builder = strings.Builder{}
+	for _, child := range this.Children {
+		switch c := child.(type) {
+		case string:
+			builder.WriteString(c)
+		case *xmlNode:
+			builder.WriteString(c.text())
+		}
+	}
+	return builder.String()
+}
+
+// 排他型规范化，exclude 为需要排除的子节点（通常是签名节点）
+func canonicalize(node *xmlNode, inclusivePrefixes []string, exclude *xmlNode) []byte {
+	var buf = &bytes.Buffer{}
+	writeCanonical(buf, node, map[string]string{}, inclusivePrefixes, exclude)
+	return buf.Bytes()
+}
+
+func writeCanonical(buf *bytes.Buffer, node *xmlNode, rendered map[string]string, inclusivePrefixes []string, exclude *xmlNode) {
+	// 需要输出的命名空间
+	var prefixes = []string{node.Prefix}
+	for _, attr := range node.Attrs {
+		if len(attr.Prefix) > 0 && attr.Prefix != "xml" && !containsString(prefixes, attr.Prefix) {
+			prefixes = append(prefixes, attr.Prefix)
+		}
+	}
+	for _, prefix := range inclusivePrefixes {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		if _, ok := node.lookupNS(prefix); ok && !containsString(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	var newRendered = map[string]string{}
+	for prefix, uri := range rendered {
+		newRendered[prefix] = uri
+	}
+
+	buf.WriteString("<")
+	buf.WriteString(qualifiedName(node.Prefix, node.Local))
+	for _, prefix := range prefixes {
+		uri, _ := node.lookupNS(prefix)
+		renderedURI, hasRendered := rendered[prefix]
+		if prefix == "" && len(uri) == 0 {
+			// 只有在上级输出了非空的默认命名空间时才需要输出 xmlns=""
+			if !hasRendered || len(renderedURI) == 0 {
+				continue
+			}
+		} else if hasRendered && renderedURI == uri {
+			continue
+		}
+		if prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		buf.WriteString(escapeAttr(uri))
+		buf.WriteString(`"`)
+		newRendered[prefix] = uri
+	}
+
+	// 属性按照命名空间和名称排序
+	var attrs = append([]*xmlAttr{}, node.Attrs...)
+	sort.SliceStable(attrs, func(i, j int) bool {
+		var ns1, ns2 string
+		if len(attrs[i].Prefix) > 0 {
+			ns1, _ = node.lookupNS(attrs[i].Prefix)
+		}
+		if len(attrs[j].Prefix) > 0 {
+			ns2, _ = node.lookupNS(attrs[j].Prefix)
+		}
+		if ns1 != ns2 {
+			return ns1 < ns2
+		}
+		return attrs[i].Local < attrs[j].Local
+	})
+	for _, attr := range attrs {
+		buf.WriteString(" " + qualifiedName(attr.Prefix, attr.Local) + `="`)
+		buf.WriteString(escapeAttr(attr.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+
+	for _, child := range node.Children {
+		switch c := child.(type) {
+		case string:
+			buf.WriteString(escapeText(c))
+		case *xmlNode:
+			if c == exclude {
+				continue
+			}
+			writeCanonical(buf, c, newRendered, inclusivePrefixes, exclude)
+		}
+	}
+
+	buf.WriteString("</" + qualifiedName(node.Prefix, node.Local) + ">")
+}
+
+func qualifiedName(prefix string, local string) string {
+	if len(prefix) == 0 {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+// 读取规范化方法中的 InclusiveNamespaces PrefixList
+func inclusivePrefixList(method *xmlNode) []string {
+	var inclusive = method.child(nsExcCN, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+	return strings.Fields(inclusive.attr("PrefixList"))
+}
+
+// 校验签名节点，返回被签名的节点（即签名节点的父节点）
+func verifyEnvelopedSignature(signature *xmlNode, cert *x509.Certificate) (*xmlNode, error) {
+	var signedElement = signature.Parent
+	if signedElement == nil {
+		return nil, errors.New("signature has no parent element")
+	}
+
+	var signedInfo = signature.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return nil, errors.New("no SignedInfo in signature")
+	}
+
+	// 规范化方法
+	var c14nMethod = signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != nsExcCN {
+		return nil, errors.New("unsupported canonicalization method")
+	}
+
+	// 签名方法
+	var signatureMethod = signedInfo.child(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return nil, errors.New("no SignatureMethod in signature")
+	}
+	var signatureAlgorithm = signatureMethod.attr("Algorithm")
+	signatureHash, ok := signatureMethods[signatureAlgorithm]
+	if !ok {
+		return nil, errors.New("unsupported signature method '" + signatureAlgorithm + "'")
+	}
+
+	// 引用只能是签名节点的父节点，防止签名包装攻击
+	var references = signedInfo.children(nsDSig, "Reference")
+	if len(references) != 1 {
+		return nil, errors.New("signature should have exactly one reference")
+	}
+	var reference = references[0]
+	var id = signedElement.attr("ID")
+	if len(id) == 0 || reference.attr("URI") != "#"+id {
+		return nil, errors.New("signature reference does not point to the parent element")
+	}
+
+	var inclusivePrefixes []string
+	var transforms = reference.child(nsDSig, "Transforms")
+	if transforms != nil {
+		for _, transform := range transforms.children(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algEnvelopedSignature:
+			case nsExcCN:
+				inclusivePrefixes = inclusivePrefixList(transform)
+			default:
+				return nil, errors.New("unsupported transform '" + transform.attr("Algorithm") + "'")
+			}
+		}
+	}
+
+	// 校验摘要
+	var digestMethod = reference.child(nsDSig, "DigestMethod")
+	var digestValue = reference.child(nsDSig, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return nil, errors.New("no digest in signature reference")
+	}
+	digestHash, ok := digestMethods[digestMethod.attr("Algorithm")]
+	if !ok {
+		return nil, errors.New("unsupported digest method '" + digestMethod.attr("Algorithm") + "'")
+	}
+	expectedDigest, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(digestValue.text()), ""))
+	if err != nil {
+		return nil, errors.New("invalid digest value")
+	}
+	var hasher = digestHash.New()
+	hasher.Write(canonicalize(signedElement, inclusivePrefixes, signature))
+	if !bytes.Equal(hasher.Sum(nil), expectedDigest) {
+		return nil, errors.New("digest mismatch")
+	}
+
+	// 校验签名
+	var signatureValue = signature.child(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return nil, errors.New("no SignatureValue in signature")
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(signatureValue.text()), ""))
+	if err != nil {
+		return nil, errors.New("invalid signature value")
+	}
+	hasher = signatureHash.New()
+	hasher.Write(canonicalize(signedInfo, inclusivePrefixList(c14nMethod), nil))
+	var digest = hasher.Sum(nil)
+
+	switch publicKey := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(publicKey, signatureHash, digest, signatureBytes)
+		if err != nil {
+			return nil, errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		var size = len(signatureBytes) / 2
+		if size == 0 || len(signatureBytes)%2 != 0 ||
+			!ecdsa.Verify(publicKey, digest, new(big.Int).SetBytes(signatureBytes[:size]), new(big.Int).SetBytes(signatureBytes[size:])) {
+			return nil, errors.New("invalid signature")
+		}
+	default:
+		return nil, errors.New("unsupported certificate key type")
+	}
+
+	return signedElement, nil
+}
+
+// ParseCertificate 解析PEM格式的证书，也支持不带PEM头的Base64内容
+func ParseCertificate(certData string) (*x509.Certificate, error) {
+	certData = strings.TrimSpace(certData)
+	if len(certData) == 0 {
+		return nil, errors.New("certificate should not be empty")
+	}
+	var der []byte
+	block, _ := pem.Decode([]byte(certData))
+	if block != nil {
+		der = block.Bytes
+	} else {
+		var err error
+		der, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(certData), ""))
+		if err != nil {
+			return nil, errors.New("invalid certificate format")
+		}
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.New("invalid certificate: " + err.Error())
+	}
+	return cert, nil
+}
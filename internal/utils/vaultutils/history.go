@@ -0,0 +1,83 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils
+
+import (
+	"sort"
+)
+
+// RetiredCredential 已经退役的认证信息
+type RetiredCredential struct {
+	Version       int    `json:"version"`
+	Method        string `json:"method"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`      // 已加密
+	PrivateKey    string `json:"privateKey"`    // 已加密
+	Passphrase    string `json:"passphrase"`    // 已加密
+	AuthorizedKey string `json:"authorizedKey"` // 私钥对应的公钥
+	RetiredAt     int64  `json:"retiredAt"`
+}
+
+// GrantHistory 单个认证的轮换记录
+type GrantHistory struct {
+	GrantId   int64                `json:"grantId"`
+	Version   int                  `json:"version"`
+	RotatedAt int64                `json:"rotatedAt"`
+	Retired   []*RetiredCredential `json:"retired"`
+}
+
+// Latest 最近一次退役的认证信息
+func (this *GrantHistory) Latest() *RetiredCredential {
+	if len(this.Retired) == 0 {
+		return nil
+	}
+	return this.Retired[len(this.Retired)-1]
+}
+
+// History 所有认证的轮换记录
+type History struct {
+	Grants []*GrantHistory `json:"grants"`
+}
+
+// NewHistory 获取新对象
+func NewHistory() *History {
+	return &History{
+		Grants: []*GrantHistory{},
+	}
+}
+
+// Find 查找某个认证的记录
+func (this *History) Find(grantId int64) *GrantHistory {
+	for _, grant := range this.Grants {
+		if grant.GrantId == grantId {
+			return grant
+		}
+	}
+	return nil
+}
+
+// Retire 记录退役的认证信息，并返回新的版本号
+// 最多保留 maxRetired 个历史版本
+func (this *History) Retire(grantId int64, credential *RetiredCredential, rotatedAt int64, maxRetired int) int {
+	var grant = this.Find(grantId)
+	if grant == nil {
+		grant = &GrantHistory{
+			GrantId: grantId,
+			Version: 1,
+		}
+		this.Grants = append(this.Grants, grant)
+		sort.Slice(this.Grants, func(i, j int) bool {
+			return this.Grants[i].GrantId < this.Grants[j].GrantId
+		})
+	}
+
+	credential.Version = grant.Version
+	credential.RetiredAt = rotatedAt
+	grant.Retired = append(grant.Retired, credential)
+	if maxRetired > 0 && len(grant.Retired) > maxRetired {
+		grant.Retired = grant.Retired[len(grant.Retired)-maxRetired:]
+	}
+	grant.Version++
+	grant.RotatedAt = rotatedAt
+	return grant.Version
+}
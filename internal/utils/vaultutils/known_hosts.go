@@ -0,0 +1,110 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KnownHost 记录的主机公钥
+type KnownHost struct {
+	Key        string `json:"key"` // authorized_keys格式
+	RecordedAt int64  `json:"recordedAt"`
+}
+
+// HostKeyMismatchError 主机公钥和记录的不一致
+type HostKeyMismatchError struct {
+	Addr     string
+	Expected string // 记录的公钥指纹
+	Actual   string // 当前的公钥指纹
+}
+
+func (this *HostKeyMismatchError) Error() string {
+	return "host key of '" + this.Addr + "' has changed, expected '" + this.Expected + "', got '" + this.Actual + "'"
+}
+
+// KnownHosts 节点的SSH主机公钥
+// 首次连接时记录主机公钥，之后连接时必须和记录的一致
+type KnownHosts struct {
+	Hosts map[string]*KnownHost `json:"hosts"` // host:port => 公钥
+
+	isChanged bool
+	locker    sync.Mutex
+}
+
+// NewKnownHosts 获取新对象
+func NewKnownHosts() *KnownHosts {
+	return &KnownHosts{
+		Hosts: map[string]*KnownHost{},
+	}
+}
+
+// HostKeyCallback 用于ssh.ClientConfig的主机公钥检查函数
+func (this *KnownHosts) HostKeyCallback() ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		return this.Check(hostname, key)
+	}
+}
+
+// Check 检查主机公钥，没有记录时记录当前公钥
+func (this *KnownHosts) Check(addr string, key ssh.PublicKey) error {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	if this.Hosts == nil {
+		this.Hosts = map[string]*KnownHost{}
+	}
+
+	var keyData = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	var knownHost = this.Hosts[addr]
+	if knownHost == nil {
+		this.Hosts[addr] = &KnownHost{
+			Key:        keyData,
+			RecordedAt: time.Now().Unix(),
+		}
+		this.isChanged = true
+		return nil
+	}
+
+	knownKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(knownHost.Key))
+	if err != nil {
+		return &HostKeyMismatchError{
+			Addr:     addr,
+			Expected: "invalid key",
+			Actual:   ssh.FingerprintSHA256(key),
+		}
+	}
+	if knownKey.Type() != key.Type() || !bytes.Equal(knownKey.Marshal(), key.Marshal()) {
+		return &HostKeyMismatchError{
+			Addr:     addr,
+			Expected: ssh.FingerprintSHA256(knownKey),
+			Actual:   ssh.FingerprintSHA256(key),
+		}
+	}
+	return nil
+}
+
+// Forget 删除某个主机的记录，下次连接时重新记录
+func (this *KnownHosts) Forget(addr string) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	_, ok := this.Hosts[addr]
+	if ok {
+		delete(this.Hosts, addr)
+		this.isChanged = true
+	}
+}
+
+// IsChanged 是否有新记录或者删除的记录
+func (this *KnownHosts) IsChanged() bool {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+	return this.isChanged
+}
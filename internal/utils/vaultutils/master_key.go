@@ -0,0 +1,71 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/iwind/TeaGo/Tea"
+)
+
+// MasterKeyFileName 主密钥文件，只保存在管理平台所在服务器上
+const MasterKeyFileName = "vault.key"
+
+var sharedVault *Vault
+var sharedVaultLocker = &sync.Mutex{}
+
+// SharedVault 使用配置目录下的主密钥构造Vault，如果主密钥不存在则自动生成
+func SharedVault() (*Vault, error) {
+	sharedVaultLocker.Lock()
+	defer sharedVaultLocker.Unlock()
+
+	if sharedVault != nil {
+		return sharedVault, nil
+	}
+
+	key, err := LoadMasterKey(Tea.ConfigFile(MasterKeyFileName), true)
+	if err != nil {
+		return nil, err
+	}
+	vault, err := NewVault(key)
+	if err != nil {
+		return nil, err
+	}
+	sharedVault = vault
+	return vault, nil
+}
+
+// LoadMasterKey 从文件中读取主密钥
+func LoadMasterKey(path string, autoCreate bool) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) || !autoCreate {
+			return nil, err
+		}
+
+		var key = make([]byte, MasterKeySize)
+		_, err = rand.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600)
+		if err != nil {
+			return nil, errors.New("write master key file failed: " + err.Error())
+		}
+		return key, nil
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.New("invalid master key file '" + path + "': " + err.Error())
+	}
+	if len(key) != MasterKeySize {
+		return nil, errors.New("invalid master key file '" + path + "': invalid key size")
+	}
+	return key, nil
+}
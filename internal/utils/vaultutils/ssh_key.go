@@ -0,0 +1,102 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// GenerateSSHKeyPair 生成新的ed25519密钥对
+// 返回PEM格式的私钥和authorized_keys格式的公钥
+func GenerateSSHKeyPair(comment string) (privateKey string, authorizedKey string, err error) {
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	block, err := ssh.MarshalPrivateKey(key, comment)
+	if err != nil {
+		return "", "", err
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	authorizedKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	if len(comment) > 0 {
+		authorizedKey += " " + comment
+	}
+
+	return string(pem.EncodeToMemory(block)), authorizedKey, nil
+}
+
+// AuthorizedKeyFromPrivateKey 从私钥中读取authorized_keys格式的公钥
+func AuthorizedKeyFromPrivateKey(privateKey string, passphrase string) (string, error) {
+	var signer ssh.Signer
+	var err error
+	if len(passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), nil
+}
+
+// AddAuthorizedKeyCommand 生成添加公钥到authorized_keys的命令
+func AddAuthorizedKeyCommand(authorizedKey string) (string, error) {
+	line, err := checkAuthorizedKey(authorizedKey)
+	if err != nil {
+		return "", err
+	}
+	var body = authorizedKeyBody(line)
+	return "mkdir -p ~/.ssh && chmod 700 ~/.ssh && touch ~/.ssh/authorized_keys && chmod 600 ~/.ssh/authorized_keys && " +
+		"(grep -qF " + shellQuote(body) + " ~/.ssh/authorized_keys || echo " + shellQuote(line) + " >> ~/.ssh/authorized_keys)", nil
+}
+
+// RemoveAuthorizedKeyCommand 生成从authorized_keys中删除公钥的命令
+func RemoveAuthorizedKeyCommand(authorizedKey string) (string, error) {
+	line, err := checkAuthorizedKey(authorizedKey)
+	if err != nil {
+		return "", err
+	}
+	var body = authorizedKeyBody(line)
+	return "test ! -f ~/.ssh/authorized_keys || " +
+		"(grep -vF " + shellQuote(body) + " ~/.ssh/authorized_keys > ~/.ssh/authorized_keys.tmp; " +
+		"cat ~/.ssh/authorized_keys.tmp > ~/.ssh/authorized_keys && rm -f ~/.ssh/authorized_keys.tmp)", nil
+}
+
+// 检查公钥格式，并去除多余的空白
+func checkAuthorizedKey(authorizedKey string) (string, error) {
+	authorizedKey = strings.TrimSpace(authorizedKey)
+	if strings.ContainsAny(authorizedKey, "\r\n") {
+		return "", errors.New("invalid authorized key: multiple lines")
+	}
+	_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", errors.New("invalid authorized key: " + err.Error())
+	}
+	return authorizedKey, nil
+}
+
+// 公钥的类型和内容部分，不包含注释
+func authorizedKeyBody(line string) string {
+	var pieces = strings.Fields(line)
+	if len(pieces) >= 2 {
+		return pieces[0] + " " + pieces[1]
+	}
+	return line
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// SealedPrefix 加密后内容的前缀
+const SealedPrefix = "vault:v1:"
+
+const MasterKeySize = 32
+
+// Vault 使用主密钥加密和解密敏感信息
+type Vault struct {
+	aead cipher.AEAD
+}
+
+// NewVault 获取新对象
+func NewVault(masterKey []byte) (*Vault, error) {
+	if len(masterKey) != MasterKeySize {
+		return nil, errors.New("invalid master key size")
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{aead: aead}, nil
+}
+
+// Seal 加密
+// 空字符串不加密
+func (this *Vault) Seal(plaintext string) (string, error) {
+	if len(plaintext) == 0 || IsSealed(plaintext) {
+		return plaintext, nil
+	}
+
+	var nonce = make([]byte, this.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	var data = this.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return SealedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Open 解密
+// 非加密的内容原样返回
+func (this *Vault) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return sealed, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, SealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	var nonceSize = this.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("invalid sealed data")
+	}
+	plaintext, err := this.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("decrypt failed: " + err.Error())
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed 判断内容是否已加密
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, SealedPrefix)
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package vaultutils_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"github.com/iwind/TeaGo/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestVault_Seal(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	vault, err := vaultutils.NewVault(bytes.Repeat([]byte{'a'}, vaultutils.MasterKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := vault.Seal("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(sealed)
+	a.IsTrue(vaultutils.IsSealed(sealed))
+
+	// 重复加密
+	sealed2, err := vault.Seal(sealed)
+	a.IsNil(err)
+	a.IsTrue(sealed2 == sealed)
+
+	plaintext, err := vault.Open(sealed)
+	a.IsNil(err)
+	a.IsTrue(plaintext == "123456")
+
+	// 空字符串和明文
+	empty, err := vault.Seal("")
+	a.IsNil(err)
+	a.IsTrue(empty == "")
+	plaintext, err = vault.Open("abc")
+	a.IsNil(err)
+	a.IsTrue(plaintext == "abc")
+
+	// 错误的密钥
+	otherVault, err := vaultutils.NewVault(bytes.Repeat([]byte{'b'}, vaultutils.MasterKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = otherVault.Open(sealed)
+	a.IsNotNil(err)
+
+	_, err = vaultutils.NewVault([]byte("short"))
+	a.IsNotNil(err)
+}
+
+func TestLoadMasterKey(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var path = filepath.Join(t.TempDir(), "vault.key")
+	_, err := vaultutils.LoadMasterKey(path, false)
+	a.IsNotNil(err)
+
+	key, err := vaultutils.LoadMasterKey(path, true)
+	a.IsNil(err)
+	a.IsTrue(len(key) == vaultutils.MasterKeySize)
+
+	stat, err := os.Stat(path)
+	a.IsNil(err)
+	a.IsTrue(stat.Mode().Perm() == 0600)
+
+	key2, err := vaultutils.LoadMasterKey(path, true)
+	a.IsNil(err)
+	a.IsTrue(bytes.Equal(key, key2))
+}
+
+func TestGenerateSSHKeyPair(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	privateKey, authorizedKey, err := vaultutils.GenerateSSHKeyPair("goedge-grant-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(authorizedKey)
+	a.IsTrue(strings.HasPrefix(authorizedKey, "ssh-ed25519 "))
+	a.IsTrue(strings.HasSuffix(authorizedKey, " goedge-grant-1"))
+
+	authorizedKey2, err := vaultutils.AuthorizedKeyFromPrivateKey(privateKey, "")
+	a.IsNil(err)
+	a.IsTrue(strings.HasPrefix(authorizedKey, authorizedKey2))
+
+	addCommand, err := vaultutils.AddAuthorizedKeyCommand(authorizedKey)
+	a.IsNil(err)
+	t.Log(addCommand)
+
+	removeCommand, err := vaultutils.RemoveAuthorizedKeyCommand(authorizedKey)
+	a.IsNil(err)
+	a.IsTrue(strings.Contains(removeCommand, authorizedKey2))
+
+	_, err = vaultutils.AddAuthorizedKeyCommand(authorizedKey + "\nssh-rsa abc")
+	a.IsNotNil(err)
+}
+
+func TestHistory_Retire(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var history = vaultutils.NewHistory()
+	a.IsTrue(history.Find(1) == nil)
+
+	for i := 0; i < 5; i++ {
+		history.Retire(1, &vaultutils.RetiredCredential{Method: "user"}, int64(i), 3)
+	}
+	var version = history.Retire(2, &vaultutils.RetiredCredential{Method: "privateKey"}, 10, 3)
+	a.IsTrue(version == 2)
+
+	var grant = history.Find(1)
+	a.IsNotNil(grant)
+	a.IsTrue(grant.Version == 6)
+	a.IsTrue(len(grant.Retired) == 3)
+	a.IsTrue(grant.Latest().Version == 5)
+	a.IsTrue(grant.RotatedAt == 4)
+}
+
+func TestKnownHosts_Check(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var newPublicKey = func() ssh.PublicKey {
+		_, authorizedKey, err := vaultutils.GenerateSSHKeyPair("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	var key1 = newPublicKey()
+	var key2 = newPublicKey()
+
+	var knownHosts = vaultutils.NewKnownHosts()
+	a.IsFalse(knownHosts.IsChanged())
+
+	// 首次连接时记录
+	a.IsNil(knownHosts.Check("192.168.1.100:22", key1))
+	a.IsTrue(knownHosts.IsChanged())
+	a.IsNil(knownHosts.Check("192.168.1.100:22", key1))
+
+	// 公钥改变
+	err := knownHosts.Check("192.168.1.100:22", key2)
+	a.IsNotNil(err)
+	var mismatchErr *vaultutils.HostKeyMismatchError
+	a.IsTrue(errors.As(err, &mismatchErr))
+	t.Log(err)
+
+	// 其他主机不受影响
+	a.IsNil(knownHosts.Check("192.168.1.101:22", key2))
+
+	// 删除记录后重新记录
+	knownHosts.Forget("192.168.1.100:22")
+	a.IsNil(knownHosts.Check("192.168.1.100:22", key2))
+}
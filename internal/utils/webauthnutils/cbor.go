@@ -0,0 +1,156 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// 最大嵌套层级
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("cbor: truncated data")
+
+// 解码CBOR数据，只支持WebAuthn中用到的类型
+// 整数解码为int64，字节串为[]byte，文本为string，数组为[]any，映射为map[any]any
+// 返回解码后的值和剩余的数据
+func decodeCBOR(data []byte) (value any, rest []byte, err error) {
+	return decodeCBORValue(data, 0)
+}
+
+func decodeCBORValue(data []byte, depth int) (value any, rest []byte, err error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("cbor: nested too deeply")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+	var majorType = data[0] >> 5
+	var info = data[0] & 0x1f
+	data = data[1:]
+
+	// 简单值和浮点数
+	if majorType == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		case 25:
+			if len(data) < 2 {
+				return nil, nil, errCBORTruncated
+			}
+			return nil, data[2:], nil // 不需要半精度浮点数的值
+		case 26:
+			if len(data) < 4 {
+				return nil, nil, errCBORTruncated
+			}
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), data[4:], nil
+		case 27:
+			if len(data) < 8 {
+				return nil, nil, errCBORTruncated
+			}
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), data[8:], nil
+		}
+		return nil, nil, errors.New("cbor: unsupported simple value")
+	}
+
+	// 读取参数
+	var argument uint64
+	switch {
+	case info < 24:
+		argument = uint64(info)
+	case info == 24:
+		if len(data) < 1 {
+			return nil, nil, errCBORTruncated
+		}
+		argument = uint64(data[0])
+		data = data[1:]
+	case info == 25:
+		if len(data) < 2 {
+			return nil, nil, errCBORTruncated
+		}
+		argument = uint64(binary.BigEndian.Uint16(data))
+		data = data[2:]
+	case info == 26:
+		if len(data) < 4 {
+			return nil, nil, errCBORTruncated
+		}
+		argument = uint64(binary.BigEndian.Uint32(data))
+		data = data[4:]
+	case info == 27:
+		if len(data) < 8 {
+			return nil, nil, errCBORTruncated
+		}
+		argument = binary.BigEndian.Uint64(data)
+		data = data[8:]
+	default:
+		return nil, nil, errors.New("cbor: indefinite length is not supported")
+	}
+
+	switch majorType {
+	case 0: // 正整数
+		if argument > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(argument), data, nil
+	case 1: // 负整数
+		if argument > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(argument), data, nil
+	case 2, 3: // 字节串和文本
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		var b = data[:argument]
+		if majorType == 3 {
+			return string(b), data[argument:], nil
+		}
+		return append([]byte{}, b...), data[argument:], nil
+	case 4: // 数组
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		var list = make([]any, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			var item any
+			item, data, err = decodeCBORValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, item)
+		}
+		return list, data, nil
+	case 5: // 映射
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+		var m = map[any]any{}
+		for i := uint64(0); i < argument; i++ {
+			var key, item any
+			key, data, err = decodeCBORValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errors.New("cbor: unsupported map key type")
+			}
+			item, data, err = decodeCBORValue(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = item
+		}
+		return m, data, nil
+	case 6: // 标签，忽略标签本身
+		return decodeCBORValue(data, depth+1)
+	}
+	return nil, nil, errors.New("cbor: unsupported major type")
+}
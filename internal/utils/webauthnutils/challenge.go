@@ -0,0 +1,94 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"sync"
+	"time"
+)
+
+// 登录挑战有效期
+const ChallengeLife = 5 * time.Minute
+
+// 单个挑战最多可以尝试的次数
+const maxChallengeAttempts = 5
+
+// LoginChallenge 登录时的挑战信息
+type LoginChallenge struct {
+	Token     string
+	AdminId   int64 // 为0表示无密码登录
+	Challenge string
+	Remember  bool
+
+	attempts  int
+	expiresAt time.Time
+}
+
+// ChallengeStore 登录挑战存储
+type ChallengeStore struct {
+	items  map[string]*LoginChallenge // token => challenge
+	locker sync.Mutex
+}
+
+// NewChallengeStore 获取新对象
+func NewChallengeStore() *ChallengeStore {
+	return &ChallengeStore{
+		items: map[string]*LoginChallenge{},
+	}
+}
+
+// Put 保存挑战
+func (this *ChallengeStore) Put(challenge *LoginChallenge, now time.Time) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	for token, item := range this.items {
+		if !item.expiresAt.After(now) {
+			delete(this.items, token)
+		}
+	}
+	challenge.expiresAt = now.Add(ChallengeLife)
+	this.items[challenge.Token] = challenge
+}
+
+// Get 读取挑战
+func (this *ChallengeStore) Get(token string, now time.Time) *LoginChallenge {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	item, ok := this.items[token]
+	if !ok {
+		return nil
+	}
+	if !item.expiresAt.After(now) {
+		delete(this.items, token)
+		return nil
+	}
+	var c = *item
+	return &c
+}
+
+// Fail 记录一次失败的尝试，超过最大次数后挑战失效
+func (this *ChallengeStore) Fail(token string) {
+	this.locker.Lock()
+	defer this.locker.Unlock()
+
+	item, ok := this.items[token]
+	if !ok {
+		return
+	}
+	item.attempts++
+	if item.attempts >= maxChallengeAttempts {
+		delete(this.items, token)
+	}
+}
+
+// Delete 删除挑战
+func (this *ChallengeStore) Delete(token string) {
+	this.locker.Lock()
+	delete(this.items, token)
+	this.locker.Unlock()
+}
+
+// SharedChallengeStore 共享的挑战存储
+var SharedChallengeStore = NewChallengeStore()
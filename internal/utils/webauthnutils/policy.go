@@ -0,0 +1,15 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+// Policy 认证器相关的安全策略
+type Policy struct {
+	RequireForSuperAdmins bool   `json:"requireForSuperAdmins"` // 超级管理员必须使用认证器登录
+	AllowPasswordless     bool   `json:"allowPasswordless"`     // 允许不输入密码直接使用认证器登录
+	RPId                  string `json:"rpId"`                  // 依赖方ID，为空时使用访问的域名
+}
+
+// NewPolicy 获取新对象
+func NewPolicy() *Policy {
+	return &Policy{}
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// NewRelyingParty 根据当前请求构造依赖方信息
+// rpId 为策略中设置的依赖方ID，只有当前访问的域名和它相同或者为它的子域名时才生效
+func NewRelyingParty(req *http.Request, rpId string) *RelyingParty {
+	var scheme = "http"
+	if req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+
+	var host = req.Host
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	hostname = strings.Trim(strings.ToLower(hostname), "[]")
+
+	rpId = strings.ToLower(strings.TrimSpace(rpId))
+	if len(rpId) == 0 || (hostname != rpId && !strings.HasSuffix(hostname, "."+rpId)) {
+		rpId = hostname
+	}
+
+	return &RelyingParty{
+		Id:     rpId,
+		Origin: scheme + "://" + host,
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// 每个管理员最多可以注册的认证器数量
+const MaxCredentialsPerAdmin = 20
+
+// AdminCredentials 单个管理员的认证器和恢复码
+type AdminCredentials struct {
+	Credentials   []*Credential `json:"credentials"`
+	RecoveryCodes []string      `json:"recoveryCodes"` // 恢复码的SHA256值
+}
+
+// Store 所有管理员的认证器信息
+type Store struct {
+	Admins map[int64]*AdminCredentials `json:"admins"` // adminId => credentials
+}
+
+// NewStore 获取新对象
+func NewStore() *Store {
+	return &Store{
+		Admins: map[int64]*AdminCredentials{},
+	}
+}
+
+// Clone 复制对象，用于修改前避免影响共享对象
+func (this *Store) Clone() *Store {
+	var store = NewStore()
+	for adminId, admin := range this.Admins {
+		var newAdmin = &AdminCredentials{
+			RecoveryCodes: append([]string{}, admin.RecoveryCodes...),
+		}
+		for _, credential := range admin.Credentials {
+			var c = *credential
+			newAdmin.Credentials = append(newAdmin.Credentials, &c)
+		}
+		store.Admins[adminId] = newAdmin
+	}
+	return store
+}
+
+// FindAdminCredentials 查找某个管理员的所有认证器
+func (this *Store) FindAdminCredentials(adminId int64) []*Credential {
+	admin, ok := this.Admins[adminId]
+	if !ok {
+		return nil
+	}
+	return admin.Credentials
+}
+
+// HasCredentials 判断管理员是否已注册认证器
+func (this *Store) HasCredentials(adminId int64) bool {
+	return len(this.FindAdminCredentials(adminId)) > 0
+}
+
+// CountRecoveryCodes 计算剩余的恢复码数量
+func (this *Store) CountRecoveryCodes(adminId int64) int {
+	admin, ok := this.Admins[adminId]
+	if !ok {
+		return 0
+	}
+	return len(admin.RecoveryCodes)
+}
+
+// FindCredential 根据凭据ID查找认证器
+func (this *Store) FindCredential(credentialId string) (adminId int64, credential *Credential) {
+	for adminId, admin := range this.Admins {
+		for _, c := range admin.Credentials {
+			if c.Id == credentialId {
+				return adminId, c
+			}
+		}
+	}
+	return 0, nil
+}
+
+// AddCredential 添加认证器
+func (this *Store) AddCredential(adminId int64, credential *Credential) error {
+	existAdminId, _ := this.FindCredential(credential.Id)
+	if existAdminId > 0 {
+		return errors.New("the authenticator has already been registered")
+	}
+	var admin = this.findOrCreateAdmin(adminId)
+	if len(admin.Credentials) >= MaxCredentialsPerAdmin {
+		return errors.New("too many authenticators")
+	}
+	admin.Credentials = append(admin.Credentials, credential)
+	return nil
+}
+
+// RenameCredential 修改认证器名称
+func (this *Store) RenameCredential(adminId int64, credentialId string, name string) bool {
+	for _, c := range this.FindAdminCredentials(adminId) {
+		if c.Id == credentialId {
+			c.Name = name
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveCredential 删除认证器
+func (this *Store) RemoveCredential(adminId int64, credentialId string) bool {
+	admin, ok := this.Admins[adminId]
+	if !ok {
+		return false
+	}
+	for index, c := range admin.Credentials {
+		if c.Id == credentialId {
+			admin.Credentials = append(admin.Credentials[:index], admin.Credentials[index+1:]...)
+
+			// 没有认证器时同时删除恢复码
+			if len(admin.Credentials) == 0 {
+				delete(this.Admins, adminId)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveAdmin 删除管理员所有的认证器和恢复码
+func (this *Store) RemoveAdmin(adminId int64) {
+	delete(this.Admins, adminId)
+}
+
+// UpdateSignCount 登录成功后更新签名计数
+func (this *Store) UpdateSignCount(adminId int64, credentialId string, signCount uint32) {
+	for _, c := range this.FindAdminCredentials(adminId) {
+		if c.Id == credentialId {
+			c.SignCount = signCount
+			c.LastUsedAt = time.Now().Unix()
+			return
+		}
+	}
+}
+
+// ResetRecoveryCodes 重新生成恢复码，返回明文，明文只会显示一次
+func (this *Store) ResetRecoveryCodes(adminId int64) []string {
+	var admin = this.findOrCreateAdmin(adminId)
+	var codes = []string{}
+	admin.RecoveryCodes = []string{}
+	for i := 0; i < RecoveryCodeCount; i++ {
+		var code = newRecoveryCode()
+		codes = append(codes, code)
+		admin.RecoveryCodes = append(admin.RecoveryCodes, hashRecoveryCode(code))
+	}
+	return codes
+}
+
+// UseRecoveryCode 使用恢复码，每个恢复码只能使用一次
+func (this *Store) UseRecoveryCode(adminId int64, code string) bool {
+	admin, ok := this.Admins[adminId]
+	if !ok {
+		return false
+	}
+	code = normalizeRecoveryCode(code)
+	if len(code) == 0 {
+		return false
+	}
+	var hash = hashRecoveryCode(code)
+	var foundIndex = -1
+	for index, h := range admin.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			foundIndex = index
+		}
+	}
+	if foundIndex < 0 {
+		return false
+	}
+	admin.RecoveryCodes = append(admin.RecoveryCodes[:foundIndex], admin.RecoveryCodes[foundIndex+1:]...)
+	return true
+}
+
+func (this *Store) findOrCreateAdmin(adminId int64) *AdminCredentials {
+	if this.Admins == nil {
+		this.Admins = map[int64]*AdminCredentials{}
+	}
+	admin, ok := this.Admins[adminId]
+	if !ok {
+		admin = &AdminCredentials{
+			Credentials:   []*Credential{},
+			RecoveryCodes: []string{},
+		}
+		this.Admins[adminId] = admin
+	}
+	return admin
+}
+
+// 生成恢复码，格式为 xxxxx-xxxxx
+func newRecoveryCode() string {
+	var b = make([]byte, 5)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	var s = hex.EncodeToString(b)
+	return s[:5] + "-" + s[5:]
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') {
+			return r
+		}
+		return -1
+	}, code)
+}
+
+func hashRecoveryCode(code string) string {
+	var sum = sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 认证器数据中的标志位
+const (
+	flagUserPresent       = 0x01
+	flagUserVerified      = 0x04
+	flagAttestedCredData  = 0x40
+	flagExtensionDataIncl = 0x80
+)
+
+// 凭据ID最大长度
+const maxCredentialIdLength = 1023
+
+// RelyingParty 依赖方（即当前管理系统）
+type RelyingParty struct {
+	Id     string // 域名
+	Origin string // 比如 https://admin.example.com
+}
+
+// Credential 已注册的认证器凭据
+type Credential struct {
+	Id         string `json:"id"` // base64url编码
+	Name       string `json:"name"`
+	PublicKey  []byte `json:"publicKey"` // COSE格式
+	SignCount  uint32 `json:"signCount"`
+	CreatedAt  int64  `json:"createdAt"`
+	LastUsedAt int64  `json:"lastUsedAt"`
+}
+
+// NewChallenge 生成随机挑战值，返回base64url编码的字符串
+func NewChallenge() string {
+	var b = make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+type authenticatorData struct {
+	RPIdHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	CredentialId []byte
+	PublicKey    []byte
+}
+
+// VerifyRegistration 校验注册认证器时返回的数据
+// clientDataJSON 和 attestationObject 为浏览器返回的原始数据
+// 当前不校验认证声明（attestation），认证器的来源由管理员自行确认
+func VerifyRegistration(rp *RelyingParty, challenge string, clientDataJSON []byte, attestationObject []byte, requireUserVerification bool) (*Credential, error) {
+	err := verifyClientData(rp, "webauthn.create", challenge, clientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	value, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, errors.New("invalid attestation object: " + err.Error())
+	}
+	attestation, ok := value.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid attestation object")
+	}
+	authDataBytes, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("no authData in attestation object")
+	}
+	authData, err := parseAuthenticatorData(authDataBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyAuthenticatorData(rp, authData, requireUserVerification)
+	if err != nil {
+		return nil, err
+	}
+	if len(authData.CredentialId) == 0 || len(authData.PublicKey) == 0 {
+		return nil, errors.New("no attested credential data")
+	}
+	_, _, _, err = parseCOSEKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		Id:        base64.RawURLEncoding.EncodeToString(authData.CredentialId),
+		PublicKey: authData.PublicKey,
+		SignCount: authData.SignCount,
+		CreatedAt: time.Now().Unix(),
+	}, nil
+}
+
+// VerifyAssertion 校验登录时返回的数据，成功后返回新的签名计数
+func VerifyAssertion(rp *RelyingParty, challenge string, credential *Credential, clientDataJSON []byte, authDataBytes []byte, signature []byte, requireUserVerification bool) (signCount uint32, err error) {
+	err = verifyClientData(rp, "webauthn.get", challenge, clientDataJSON)
+	if err != nil {
+		return 0, err
+	}
+	authData, err := parseAuthenticatorData(authDataBytes)
+	if err != nil {
+		return 0, err
+	}
+	err = verifyAuthenticatorData(rp, authData, requireUserVerification)
+	if err != nil {
+		return 0, err
+	}
+
+	alg, publicKey, _, err := parseCOSEKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	var clientDataHash = sha256.Sum256(clientDataJSON)
+	var signed = append(append([]byte{}, authDataBytes...), clientDataHash[:]...)
+	err = verifyCOSESignature(alg, publicKey, signed, signature)
+	if err != nil {
+		return 0, err
+	}
+
+	// 签名计数没有增加，可能是认证器被复制
+	if (authData.SignCount > 0 || credential.SignCount > 0) && authData.SignCount <= credential.SignCount {
+		return 0, errors.New("signature counter did not increase, the authenticator may be cloned")
+	}
+	return authData.SignCount, nil
+}
+
+func verifyClientData(rp *RelyingParty, expectedType string, challenge string, clientDataJSON []byte) error {
+	var data = &clientData{}
+	err := json.Unmarshal(clientDataJSON, data)
+	if err != nil {
+		return errors.New("invalid client data: " + err.Error())
+	}
+	if data.Type != expectedType {
+		return errors.New("invalid client data type '" + data.Type + "'")
+	}
+	if len(challenge) == 0 || subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return errors.New("challenge mismatch")
+	}
+	if data.Origin != rp.Origin {
+		return errors.New("invalid origin '" + data.Origin + "'")
+	}
+	if data.CrossOrigin {
+		return errors.New("cross origin request is not allowed")
+	}
+	return nil
+}
+
+func verifyAuthenticatorData(rp *RelyingParty, authData *authenticatorData, requireUserVerification bool) error {
+	var rpIdHash = sha256.Sum256([]byte(rp.Id))
+	if !bytes.Equal(authData.RPIdHash, rpIdHash[:]) {
+		return errors.New("rp id mismatch")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return errors.New("user is not present")
+	}
+	if requireUserVerification && authData.Flags&flagUserVerified == 0 {
+		return errors.New("user is not verified")
+	}
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	var authData = &authenticatorData{
+		RPIdHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	var rest = data[37:]
+
+	if authData.Flags&flagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		var idLength = int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > maxCredentialIdLength || len(rest) < idLength {
+			return nil, errors.New("invalid credential id")
+		}
+		authData.CredentialId = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, _, keyRest, err := parseCOSEKey(rest)
+		if err != nil {
+			return nil, err
+		}
+		authData.PublicKey = rest[:len(rest)-len(keyRest)]
+		rest = keyRest
+	}
+	if authData.Flags&flagExtensionDataIncl != 0 {
+		var err error
+		_, rest, err = decodeCBOR(rest)
+		if err != nil {
+			return nil, errors.New("invalid extension data: " + err.Error())
+		}
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("unexpected trailing data in authenticator data")
+	}
+	return authData, nil
+}
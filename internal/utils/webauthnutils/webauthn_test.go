@@ -0,0 +1,229 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package webauthnutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+// 用于测试的简易CBOR编码，只支持测试中用到的类型
+func encodeCBOR(value any) []byte {
+	var header = func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 256:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			var b = []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		}
+	}
+	switch v := value.(type) {
+	case int:
+		if v >= 0 {
+			return header(0, uint64(v))
+		}
+		return header(1, uint64(-1-v))
+	case []byte:
+		return append(header(2, uint64(len(v))), v...)
+	case string:
+		return append(header(3, uint64(len(v))), v...)
+	case map[any]any:
+		var keys = []any{}
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return string(encodeCBOR(keys[i])) < string(encodeCBOR(keys[j]))
+		})
+		var result = header(5, uint64(len(v)))
+		for _, k := range keys {
+			result = append(result, encodeCBOR(k)...)
+			result = append(result, encodeCBOR(v[k])...)
+		}
+		return result
+	}
+	panic("unsupported type")
+}
+
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	signCount    uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{
+		key:          key,
+		credentialId: []byte("credential-1"),
+	}
+}
+
+func (this *testAuthenticator) coseKey() []byte {
+	var pad = func(b []byte) []byte {
+		return append(make([]byte, 32-len(b)), b...)
+	}
+	return encodeCBOR(map[any]any{
+		1:  coseKeyTypeEC2,
+		3:  AlgES256,
+		-1: 1,
+		-2: pad(this.key.X.Bytes()),
+		-3: pad(this.key.Y.Bytes()),
+	})
+}
+
+func (this *testAuthenticator) authData(rpId string, flags byte, attested bool) []byte {
+	var hash = sha256.Sum256([]byte(rpId))
+	var data = append([]byte{}, hash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, this.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // aaguid
+		data = binary.BigEndian.AppendUint16(data, uint16(len(this.credentialId)))
+		data = append(data, this.credentialId...)
+		data = append(data, this.coseKey()...)
+	}
+	return data
+}
+
+func testClientData(typ string, challenge string, origin string) []byte {
+	data, _ := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": challenge,
+		"origin":    origin,
+	})
+	return data
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	var rp = &RelyingParty{Id: "admin.example.com", Origin: "https://admin.example.com"}
+	var authenticator = newTestAuthenticator(t)
+
+	// 注册
+	var challenge = NewChallenge()
+	var attestation = encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": authenticator.authData(rp.Id, flagUserPresent|flagUserVerified|flagAttestedCredData, true),
+	})
+	credential, err := VerifyRegistration(rp, challenge, testClientData("webauthn.create", challenge, rp.Origin), attestation, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.Id != base64.RawURLEncoding.EncodeToString(authenticator.credentialId) {
+		t.Fatal("unexpected credential id:", credential.Id)
+	}
+
+	// 挑战值不匹配
+	_, err = VerifyRegistration(rp, NewChallenge(), testClientData("webauthn.create", challenge, rp.Origin), attestation, true)
+	if err == nil {
+		t.Fatal("expected challenge mismatch")
+	}
+
+	// 来源不匹配
+	_, err = VerifyRegistration(rp, challenge, testClientData("webauthn.create", challenge, "https://evil.example.com"), attestation, true)
+	if err == nil {
+		t.Fatal("expected origin mismatch")
+	}
+
+	// 登录
+	var sign = func(authData []byte, clientData []byte) []byte {
+		var clientDataHash = sha256.Sum256(clientData)
+		var digest = sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+		signature, err := ecdsa.SignASN1(rand.Reader, authenticator.key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+
+	challenge = NewChallenge()
+	authenticator.signCount = 1
+	var authData = authenticator.authData(rp.Id, flagUserPresent, false)
+	var clientData = testClientData("webauthn.get", challenge, rp.Origin)
+	signCount, err := VerifyAssertion(rp, challenge, credential, clientData, authData, sign(authData, clientData), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signCount != 1 {
+		t.Fatal("unexpected sign count:", signCount)
+	}
+	credential.SignCount = signCount
+
+	// 需要用户验证
+	_, err = VerifyAssertion(rp, challenge, &Credential{PublicKey: credential.PublicKey}, clientData, authData, sign(authData, clientData), true)
+	if err == nil {
+		t.Fatal("expected user verification error")
+	}
+
+	// 签名计数没有增加
+	_, err = VerifyAssertion(rp, challenge, credential, clientData, authData, sign(authData, clientData), false)
+	if err == nil {
+		t.Fatal("expected sign count error")
+	}
+
+	// 签名被篡改
+	authenticator.signCount = 2
+	authData = authenticator.authData(rp.Id, flagUserPresent, false)
+	var signature = sign(authData, clientData)
+	authData[32] |= flagUserVerified
+	_, err = VerifyAssertion(rp, challenge, credential, clientData, authData, signature, false)
+	if err == nil {
+		t.Fatal("expected signature error")
+	}
+
+	// 其他域名
+	authData = authenticator.authData("example.org", flagUserPresent, false)
+	_, err = VerifyAssertion(rp, challenge, credential, clientData, authData, sign(authData, clientData), false)
+	if err == nil {
+		t.Fatal("expected rp id mismatch")
+	}
+}
+
+func TestStore_RecoveryCodes(t *testing.T) {
+	var store = NewStore()
+	err := store.AddCredential(1, &Credential{Id: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.AddCredential(2, &Credential{Id: "a"}) == nil {
+		t.Fatal("expected duplicate credential error")
+	}
+
+	var codes = store.ResetRecoveryCodes(1)
+	if len(codes) != RecoveryCodeCount || store.CountRecoveryCodes(1) != RecoveryCodeCount {
+		t.Fatal("unexpected recovery codes")
+	}
+	if store.UseRecoveryCode(2, codes[0]) {
+		t.Fatal("recovery code should belong to admin 1")
+	}
+	if !store.UseRecoveryCode(1, " "+codes[0]+" ") {
+		t.Fatal("recovery code should be accepted")
+	}
+	if store.UseRecoveryCode(1, codes[0]) {
+		t.Fatal("recovery code should be used only once")
+	}
+	if store.CountRecoveryCodes(1) != RecoveryCodeCount-1 {
+		t.Fatal("unexpected recovery code count")
+	}
+
+	var clone = store.Clone()
+	clone.RemoveCredential(1, "a")
+	if clone.HasCredentials(1) || !store.HasCredentials(1) {
+		t.Fatal("clone should not affect the original store")
+	}
+}
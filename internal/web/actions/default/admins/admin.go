@@ -67,6 +67,21 @@ func (this *AdminAction) RunGet(params struct {
 	}
 	this.Data["modules"] = moduleMaps
 
+	// 角色
+	policy, err := configloaders.LoadAdminRBACPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var roleMaps = []maps.Map{}
+	for _, role := range policy.FindAdminRoles(admin.Id) {
+		roleMaps = append(roleMaps, maps.Map{
+			"id":   role.Id,
+			"name": role.Name,
+		})
+	}
+	this.Data["roles"] = roleMaps
+
 	// OTP
 	this.Data["otp"] = nil
 	if admin.OtpLogin != nil && admin.OtpLogin.IsOn {
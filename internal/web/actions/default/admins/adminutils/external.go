@@ -0,0 +1,107 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package adminutils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/systemconfigs"
+)
+
+// CreateExternalAdmin 为单点登录、LDAP等外部身份源中的用户创建管理员
+// 创建的管理员使用随机密码，不能通过本地密码登录
+func CreateExternalAdmin(p *actionutils.ParentAction, username string, fullname string, moduleCodes []string) (*pb.Admin, error) {
+	if len(fullname) == 0 {
+		fullname = username
+	}
+	modulesJSON, err := encodeModules(moduleCodes)
+	if err != nil {
+		return nil, err
+	}
+	createResp, err := p.RPC().AdminRPC().CreateAdmin(p.AdminContext(), &pb.CreateAdminRequest{
+		Username:    username,
+		Password:    randomPassword(),
+		Fullname:    fullname,
+		ModulesJSON: modulesJSON,
+		IsSuper:     false,
+		CanLogin:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	adminResp, err := p.RPC().AdminRPC().FindEnabledAdmin(p.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: createResp.AdminId})
+	if err != nil {
+		return nil, err
+	}
+	if adminResp.Admin == nil {
+		return nil, errors.New("can not find created admin")
+	}
+
+	err = configloaders.NotifyAdminModuleMappingChange()
+	if err != nil {
+		return nil, err
+	}
+	return adminResp.Admin, nil
+}
+
+// SyncAdminModules 将管理员的模块权限同步为外部身份源中分组对应的权限
+func SyncAdminModules(p *actionutils.ParentAction, admin *pb.Admin, moduleCodes []string) error {
+	var oldCodes = []string{}
+	for _, module := range admin.Modules {
+		oldCodes = append(oldCodes, module.Code)
+	}
+	var newCodes = append([]string{}, moduleCodes...)
+	sort.Strings(oldCodes)
+	sort.Strings(newCodes)
+	if strings.Join(oldCodes, ",") == strings.Join(newCodes, ",") {
+		return nil
+	}
+
+	modulesJSON, err := encodeModules(moduleCodes)
+	if err != nil {
+		return err
+	}
+	_, err = p.RPC().AdminRPC().UpdateAdmin(p.AdminContext(), &pb.UpdateAdminRequest{
+		AdminId:     admin.Id,
+		Username:    admin.Username,
+		Password:    "",
+		Fullname:    admin.Fullname,
+		ModulesJSON: modulesJSON,
+		IsSuper:     admin.IsSuper,
+		IsOn:        admin.IsOn,
+		CanLogin:    admin.CanLogin,
+	})
+	if err != nil {
+		return err
+	}
+	return configloaders.NotifyAdminModuleMappingChange()
+}
+
+// 生成外部身份源管理员使用的随机密码，需要使用 crypto/rand 以免被猜测
+func randomPassword() string {
+	var b = make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func encodeModules(moduleCodes []string) ([]byte, error) {
+	var modules = []*systemconfigs.AdminModule{}
+	for _, code := range moduleCodes {
+		modules = append(modules, &systemconfigs.AdminModule{
+			Code:     code,
+			AllowAll: true,
+		})
+	}
+	return json.Marshal(modules)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -45,6 +46,19 @@ func (this *CreatePopupAction) RunPost(params struct {
 		Field("fullname", params.Fullname).
 		Require("请输入系统用户全名")
 
+	// 要求超级管理员必须使用安全密钥时，新创建的超级管理员还没有安全密钥，无法登录
+	if params.IsSuper {
+		policy, err := configloaders.LoadAdminWebAuthnPolicy()
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if policy.RequireForSuperAdmins {
+			this.Fail("已开启“超级管理员必须使用安全密钥”，请先创建普通管理员并注册安全密钥，再设置为超级管理员")
+			return
+		}
+	}
+
 	params.Must.
 		Field("username", params.Username).
 		Require("请输入登录用户名").
@@ -71,6 +85,13 @@ func (this *CreatePopupAction) RunPost(params struct {
 		this.FailField("pass2", "两次输入的密码不一致")
 	}
 
+	// 密码策略
+	err = configloaders.CheckPassword(passwordpolicyutils.KindAdmin, 0, params.Username, params.Pass1)
+	if err != nil {
+		this.FailField("pass1", err.Error())
+		return
+	}
+
 	modules := []*systemconfigs.AdminModule{}
 	for _, code := range params.ModuleCodes {
 		modules = append(modules, &systemconfigs.AdminModule{
@@ -98,6 +119,13 @@ func (this *CreatePopupAction) RunPost(params struct {
 		return
 	}
 
+	// 记录密码
+	err = configloaders.RecordPasswordChange(passwordpolicyutils.KindAdmin, createResp.AdminId, params.Pass1)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
 	// OTP
 	if params.OtpOn {
 		_, err = this.RPC().LoginRPC().UpdateLogin(this.AdminContext(), &pb.UpdateLoginRequest{Login: &pb.Login{
@@ -2,6 +2,9 @@ package admins
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -22,6 +25,36 @@ func (this *DeleteAction) RunPost(params struct {
 		return
 	}
 
+	// 从角色中移除
+	err = configloaders.UpdateAdminRBACPolicy(func(policy *rbacutils.Policy) error {
+		policy.RemoveAdmin(params.AdminId)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 删除自定义看板
+	err = configloaders.UpdateAdminBoardStore(func(store *boardutils.Store) error {
+		store.RemoveAdmin(params.AdminId)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 删除LDAP关联
+	err = configloaders.UpdateAdminLDAPLinks(func(store *ldaputils.LinkStore) error {
+		store.RemoveAdmin(params.AdminId)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
 	// 通知更改
 	err = configloaders.NotifyAdminModuleMappingChange()
 	if err != nil {
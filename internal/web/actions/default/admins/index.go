@@ -1,6 +1,9 @@
 package admins
 
 import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/maps"
@@ -12,7 +15,7 @@ type IndexAction struct {
 }
 
 func (this *IndexAction) Init() {
-	this.Nav("", "", "")
+	this.Nav("", "", "index")
 }
 
 func (this *IndexAction) RunGet(params struct {
@@ -21,6 +24,7 @@ func (this *IndexAction) RunGet(params struct {
 }) {
 	this.Data["keyword"] = params.Keyword
 	this.Data["hasWeakPassword"] = params.HasWeakPassword
+	this.Data["countLockouts"] = len(loginlimitutils.SharedLimiter.FindLockouts(time.Now()))
 
 	countResp, err := this.RPC().AdminRPC().CountAllEnabledAdmins(this.AdminContext(), &pb.CountAllEnabledAdminsRequest{
 		Keyword:         params.Keyword,
@@ -3,6 +3,8 @@ package admins
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/admins/accesskeys"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/admins/lockouts"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/admins/roles"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
 	"github.com/iwind/TeaGo"
 )
@@ -28,7 +30,17 @@ func init() {
 			Post("/delete", new(accesskeys.DeleteAction)).
 			Post("/updateIsOn", new(accesskeys.UpdateIsOnAction)).
 
+			// 角色
+			Prefix("/admins/roles").
+			Get("", new(roles.IndexAction)).
+			GetPost("/createPopup", new(roles.CreatePopupAction)).
+			GetPost("/updatePopup", new(roles.UpdatePopupAction)).
+			Post("/delete", new(roles.DeleteAction)).
 
+			// 登录锁定
+			Prefix("/admins/lockouts").
+			Get("", new(lockouts.IndexAction)).
+			Post("/clear", new(lockouts.ClearAction)).
 			EndAll()
 	})
 }
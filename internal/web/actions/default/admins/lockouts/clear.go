@@ -0,0 +1,28 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package lockouts
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// ClearAction 解除登录锁定
+type ClearAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ClearAction) RunPost(params struct {
+	Kind  string
+	Value string
+}) {
+	defer this.CreateLogInfo("解除登录锁定 %s '%s'", params.Kind, params.Value)
+
+	if params.Kind != loginlimitutils.KindIP && params.Kind != loginlimitutils.KindUsername {
+		this.Fail("错误的锁定类型")
+		return
+	}
+	loginlimitutils.SharedLimiter.Clear(params.Kind, params.Value)
+
+	this.Success()
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package lockouts
+
+import (
+	"sort"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// IndexAction 登录锁定列表
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "lockout")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	config, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["limitIsOn"] = config.IsOn
+
+	var lockouts = loginlimitutils.SharedLimiter.FindLockouts(time.Now())
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].LockedAt.After(lockouts[j].LockedAt)
+	})
+
+	var lockoutMaps = []maps.Map{}
+	for _, lockout := range lockouts {
+		lockoutMaps = append(lockoutMaps, maps.Map{
+			"kind":        lockout.Kind,
+			"value":       lockout.Value,
+			"failures":    lockout.Failures,
+			"lockedTime":  timeutil.Format("Y-m-d H:i:s", lockout.LockedAt),
+			"lockedUntil": timeutil.Format("Y-m-d H:i:s", lockout.LockedUntil),
+		})
+	}
+	this.Data["lockouts"] = lockoutMaps
+
+	this.Show()
+}
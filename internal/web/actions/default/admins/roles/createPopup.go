@@ -0,0 +1,65 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package roles
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+type CreatePopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CreatePopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *CreatePopupAction) RunGet(params struct{}) {
+	err := initFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *CreatePopupAction) RunPost(params struct {
+	Name           string
+	Description    string
+	ModulesJSON    []byte
+	ClusterIds     []int64
+	ServerGroupIds []int64
+	UserIds        string
+	AdminIds       []int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入角色名称")
+
+	role, err := newRoleFromForm(params.Name, params.Description, params.ModulesJSON, params.ClusterIds, params.ServerGroupIds, params.UserIds, params.AdminIds)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	var roleId int64
+	err = configloaders.UpdateAdminRBACPolicy(func(policy *rbacutils.Policy) error {
+		roleId, err = policy.AddRole(role)
+		return err
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	defer this.CreateLogInfo("创建管理员角色 %d", roleId)
+
+	this.Success()
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package roles
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+)
+
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "role")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	policy, err := configloaders.LoadAdminRBACPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	err = initFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var roleMaps = []maps.Map{}
+	for _, role := range policy.Roles {
+		roleMaps = append(roleMaps, roleMap(role))
+	}
+	this.Data["roles"] = roleMaps
+
+	this.Show()
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package roles
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+type UpdatePopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UpdatePopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *UpdatePopupAction) RunGet(params struct {
+	RoleId int64
+}) {
+	policy, err := configloaders.LoadAdminRBACPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var role = policy.FindRole(params.RoleId)
+	if role == nil {
+		this.NotFound("role", params.RoleId)
+		return
+	}
+	this.Data["role"] = roleMap(role)
+
+	err = initFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *UpdatePopupAction) RunPost(params struct {
+	RoleId         int64
+	Name           string
+	Description    string
+	ModulesJSON    []byte
+	ClusterIds     []int64
+	ServerGroupIds []int64
+	UserIds        string
+	AdminIds       []int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改管理员角色 %d", params.RoleId)
+
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入角色名称")
+
+	role, err := newRoleFromForm(params.Name, params.Description, params.ModulesJSON, params.ClusterIds, params.ServerGroupIds, params.UserIds, params.AdminIds)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+	role.Id = params.RoleId
+
+	err = configloaders.UpdateAdminRBACPolicy(func(policy *rbacutils.Policy) error {
+		return policy.UpdateRole(role)
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
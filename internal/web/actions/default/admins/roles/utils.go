@@ -0,0 +1,164 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package roles
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+var idSplitReg = regexp.MustCompile(`[\s,，]+`)
+
+// 初始化角色表单中的选项
+func initFormOptions(parent *actionutils.ParentAction) error {
+	parent.Data["modules"] = configloaders.AllModuleMaps(parent.LangCode())
+
+	// 集群
+	clustersResp, err := parent.RPC().NodeClusterRPC().FindAllEnabledNodeClusters(parent.AdminContext(), &pb.FindAllEnabledNodeClustersRequest{})
+	if err != nil {
+		return err
+	}
+	var clusterMaps = []maps.Map{}
+	for _, cluster := range clustersResp.NodeClusters {
+		clusterMaps = append(clusterMaps, maps.Map{
+			"id":   cluster.Id,
+			"name": cluster.Name,
+		})
+	}
+	parent.Data["clusters"] = clusterMaps
+
+	// 网站分组
+	groupsResp, err := parent.RPC().ServerGroupRPC().FindAllEnabledServerGroups(parent.AdminContext(), &pb.FindAllEnabledServerGroupsRequest{})
+	if err != nil {
+		return err
+	}
+	var groupMaps = []maps.Map{}
+	for _, group := range groupsResp.ServerGroups {
+		groupMaps = append(groupMaps, maps.Map{
+			"id":   group.Id,
+			"name": group.Name,
+		})
+	}
+	parent.Data["serverGroups"] = groupMaps
+
+	// 管理员，超级管理员自动拥有所有权限，不需要分配角色
+	countResp, err := parent.RPC().AdminRPC().CountAllEnabledAdmins(parent.AdminContext(), &pb.CountAllEnabledAdminsRequest{})
+	if err != nil {
+		return err
+	}
+	var adminMaps = []maps.Map{}
+	if countResp.Count > 0 {
+		adminsResp, err := parent.RPC().AdminRPC().ListEnabledAdmins(parent.AdminContext(), &pb.ListEnabledAdminsRequest{
+			Offset: 0,
+			Size:   countResp.Count,
+		})
+		if err != nil {
+			return err
+		}
+		for _, admin := range adminsResp.Admins {
+			if admin.IsSuper {
+				continue
+			}
+			adminMaps = append(adminMaps, maps.Map{
+				"id":       admin.Id,
+				"fullname": admin.Fullname,
+				"username": admin.Username,
+			})
+		}
+	}
+	parent.Data["admins"] = adminMaps
+
+	return nil
+}
+
+// 从表单中构造角色
+func newRoleFromForm(name string, description string, modulesJSON []byte, clusterIds []int64, serverGroupIds []int64, userIdsString string, adminIds []int64) (*rbacutils.Role, error) {
+	var modules = []*rbacutils.ModulePermission{}
+	if len(modulesJSON) > 0 {
+		err := json.Unmarshal(modulesJSON, &modules)
+		if err != nil {
+			return nil, errors.New("解析模块权限失败：" + err.Error())
+		}
+	}
+	if len(modules) == 0 {
+		return nil, errors.New("请至少选择一个模块的权限")
+	}
+
+	var userIds = []int64{}
+	for _, piece := range idSplitReg.Split(userIdsString, -1) {
+		if len(piece) == 0 {
+			continue
+		}
+		userId, err := strconv.ParseInt(piece, 10, 64)
+		if err != nil || userId <= 0 {
+			return nil, errors.New("用户ID '" + piece + "' 格式错误")
+		}
+		userIds = append(userIds, userId)
+	}
+
+	var role = &rbacutils.Role{
+		Name:        name,
+		Description: description,
+		Modules:     modules,
+		Scope: &rbacutils.Scope{
+			ClusterIds:     clusterIds,
+			ServerGroupIds: serverGroupIds,
+			UserIds:        userIds,
+		},
+		AdminIds: adminIds,
+	}
+	err := role.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// 角色信息
+func roleMap(role *rbacutils.Role) maps.Map {
+	var modules = role.Modules
+	if modules == nil {
+		modules = []*rbacutils.ModulePermission{}
+	}
+	var scope = role.Scope
+	if scope == nil {
+		scope = &rbacutils.Scope{}
+	}
+	var userIdsString = ""
+	for index, userId := range scope.UserIds {
+		if index > 0 {
+			userIdsString += ", "
+		}
+		userIdsString += strconv.FormatInt(userId, 10)
+	}
+	var adminIds = role.AdminIds
+	if adminIds == nil {
+		adminIds = []int64{}
+	}
+	return maps.Map{
+		"id":             role.Id,
+		"name":           role.Name,
+		"description":    role.Description,
+		"modules":        modules,
+		"clusterIds":     nonNilIds(scope.ClusterIds),
+		"serverGroupIds": nonNilIds(scope.ServerGroupIds),
+		"userIds":        nonNilIds(scope.UserIds),
+		"userIdsString":  userIdsString,
+		"adminIds":       adminIds,
+	}
+}
+
+func nonNilIds(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
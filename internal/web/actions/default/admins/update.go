@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -102,6 +103,24 @@ func (this *UpdateAction) RunPost(params struct {
 		Field("fullname", params.Fullname).
 		Require("请输入系统用户全名")
 
+	// 要求超级管理员必须使用安全密钥时，只能把已经注册安全密钥的管理员设置为超级管理员
+	if params.IsSuper {
+		policy, err := configloaders.LoadAdminWebAuthnPolicy()
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		store, err := configloaders.LoadAdminWebAuthnStore()
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if policy.RequireForSuperAdmins && !store.HasCredentials(params.AdminId) {
+			this.Fail("已开启“超级管理员必须使用安全密钥”，此管理员尚未注册安全密钥，不能设置为超级管理员")
+			return
+		}
+	}
+
 	params.Must.
 		Field("username", params.Username).
 		Require("请输入登录用户名").
@@ -128,6 +147,13 @@ func (this *UpdateAction) RunPost(params struct {
 		if params.Pass1 != params.Pass2 {
 			this.FailField("pass2", "两次输入的密码不一致")
 		}
+
+		// 密码策略
+		err = configloaders.CheckPassword(passwordpolicyutils.KindAdmin, params.AdminId, params.Username, params.Pass1)
+		if err != nil {
+			this.FailField("pass1", err.Error())
+			return
+		}
 	}
 
 	modules := []*systemconfigs.AdminModule{}
@@ -159,6 +185,15 @@ func (this *UpdateAction) RunPost(params struct {
 		return
 	}
 
+	// 记录密码
+	if len(params.Pass1) > 0 {
+		err = configloaders.RecordPasswordChange(passwordpolicyutils.KindAdmin, params.AdminId, params.Pass1)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+	}
+
 	// 修改OTP
 	otpLoginResp, err := this.RPC().LoginRPC().FindEnabledLogin(this.AdminContext(), &pb.FindEnabledLoginRequest{
 		AdminId: params.AdminId,
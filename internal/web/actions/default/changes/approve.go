@@ -0,0 +1,46 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+)
+
+// ApproveAction 批准并执行变更申请
+type ApproveAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ApproveAction) RunPost(params struct {
+	RequestId int64
+	Reason    string
+}) {
+	defer this.CreateLogInfo("批准变更申请 %d", params.RequestId)
+
+	var adminId = this.AdminId()
+	err := changeutils.UpdateStore(func(store *approvalutils.Store) error {
+		err := checkReview(store, params.RequestId, adminId)
+		if err != nil {
+			return err
+		}
+		_, err = store.Approve(params.RequestId, adminId, configloaders.FindAdminFullname(adminId), params.Reason, time.Now().Unix())
+		return err
+	})
+	if err != nil {
+		failReview(this.Parent(), err)
+		return
+	}
+
+	err = changeutils.Execute(params.RequestId)
+	if err != nil {
+		this.Fail("已批准，但是执行变更失败：" + err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+)
+
+// CancelAction 撤回自己提交的变更申请
+type CancelAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CancelAction) RunPost(params struct {
+	RequestId int64
+}) {
+	defer this.CreateLogInfo("撤回变更申请 %d", params.RequestId)
+
+	err := changeutils.UpdateStore(func(store *approvalutils.Store) error {
+		_, err := store.Cancel(params.RequestId, this.AdminId(), time.Now().Unix())
+		return err
+	})
+	if err != nil {
+		switch err {
+		case approvalutils.ErrRequestNotFound:
+			this.Fail("找不到要撤回的变更申请")
+		case approvalutils.ErrRequestNotPending:
+			this.Fail("此变更申请已经被处理，不能撤回")
+		case approvalutils.ErrNotRequester:
+			this.Fail("只能撤回自己提交的变更申请")
+		default:
+			this.ErrorPage(err)
+		}
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changeutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+)
+
+// 需要审批的操作
+const (
+	KindDeleteServers        = "deleteServers"        // 删除网站
+	KindDeleteCluster        = "deleteCluster"        // 删除集群
+	KindDisableWAFPolicy     = "disableWAFPolicy"     // 停用WAF策略
+	KindDeleteWAFPolicy      = "deleteWAFPolicy"      // 删除WAF策略
+	KindUpdateSecurityConfig = "updateSecurityConfig" // 修改安全设置
+
+	KindUpdateApprovalSettings = "updateApprovalSettings" // 修改需要审批的操作，只要有需要审批的操作，就需要审批
+)
+
+// KindDefinition 操作定义
+type KindDefinition struct {
+	Code        string
+	Name        string
+	Description string
+	Module      configloaders.AdminModuleCode // 审批人需要有此模块的读写权限
+}
+
+// FindAllKinds 所有可以设置为需要审批的操作
+func FindAllKinds() []*KindDefinition {
+	return []*KindDefinition{
+		{
+			Code:        KindDeleteServers,
+			Name:        "删除网站",
+			Description: "在网站列表中批量删除网站，或者在网站设置中删除单个网站。",
+			Module:      configloaders.AdminModuleCodeServer,
+		},
+		{
+			Code:        KindDeleteCluster,
+			Name:        "删除集群",
+			Description: "删除边缘节点集群。",
+			Module:      configloaders.AdminModuleCodeNode,
+		},
+		{
+			Code:        KindDisableWAFPolicy,
+			Name:        "停用WAF策略",
+			Description: "将启用中的WAF策略修改为停用状态，其他修改不需要审批。",
+			Module:      configloaders.AdminModuleCodeServer,
+		},
+		{
+			Code:        KindDeleteWAFPolicy,
+			Name:        "删除WAF策略",
+			Description: "删除没有被集群引用的WAF策略。",
+			Module:      configloaders.AdminModuleCodeServer,
+		},
+		{
+			Code:        KindUpdateSecurityConfig,
+			Name:        "修改安全设置",
+			Description: "修改管理系统的安全设置，比如允许访问的IP、地区和域名，以及多因素认证、登录保护、密码策略、单点登录和LDAP认证等。",
+			Module:      configloaders.AdminModuleCodeSetting,
+		},
+	}
+}
+
+// 修改需要审批的操作，不能单独设置
+var approvalSettingsKind = &KindDefinition{
+	Code:        KindUpdateApprovalSettings,
+	Name:        "修改需要审批的操作",
+	Description: "有需要审批的操作时，修改需要审批的操作也需要其他管理员审批。",
+	Module:      configloaders.AdminModuleCodeSetting,
+}
+
+// FindKind 查找操作定义
+func FindKind(kind string) *KindDefinition {
+	if kind == KindUpdateApprovalSettings {
+		return approvalSettingsKind
+	}
+	for _, definition := range FindAllKinds() {
+		if definition.Code == kind {
+			return definition
+		}
+	}
+	return nil
+}
+
+// FindKindName 查找操作名称
+func FindKindName(kind string) string {
+	var definition = FindKind(kind)
+	if definition != nil {
+		return definition.Name
+	}
+	return kind
+}
+
+// Executor 审批通过后执行变更，ctx 为申请人的上下文
+type Executor func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error
+
+var executorMap = map[string]Executor{} // kind => Executor
+var executorLocker = &sync.RWMutex{}
+
+// RegisterExecutor 注册某个操作的执行函数，通常在操作所在包的 init() 中调用
+func RegisterExecutor(kind string, executor Executor) {
+	executorLocker.Lock()
+	executorMap[kind] = executor
+	executorLocker.Unlock()
+}
+
+func findExecutor(kind string) Executor {
+	executorLocker.RLock()
+	defer executorLocker.RUnlock()
+	return executorMap[kind]
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changeutils
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// PreviewServers 生成删除网站的预览，同时返回网站所属的集群
+func PreviewServers(ctx context.Context, rpcClient *rpc.RPCClient, serverIds []int64) (preview []string, clusterIds []int64, err error) {
+	preview = []string{}
+	clusterIds = []int64{}
+	for _, serverId := range serverIds {
+		serverResp, err := rpcClient.ServerRPC().FindEnabledServer(ctx, &pb.FindEnabledServerRequest{
+			ServerId:       serverId,
+			IgnoreSSLCerts: true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		var server = serverResp.Server
+		if server == nil {
+			continue
+		}
+		var line = "网站：" + server.Name + "（ID：" + strconv.FormatInt(server.Id, 10) + "）"
+		if server.NodeCluster != nil {
+			line += "，集群：" + server.NodeCluster.Name
+			if !lists.ContainsInt64(clusterIds, server.NodeCluster.Id) {
+				clusterIds = append(clusterIds, server.NodeCluster.Id)
+			}
+		}
+		preview = append(preview, line)
+	}
+	return
+}
+
+// SnapshotServers 网站当前的状态，提交删除申请时保存，执行前对比，网站被修改或者已删除时拒绝执行
+func SnapshotServers(ctx context.Context, rpcClient *rpc.RPCClient, serverIds []int64) ([]maps.Map, error) {
+	var result = []maps.Map{}
+	for _, serverId := range serverIds {
+		serverResp, err := rpcClient.ServerRPC().FindEnabledServer(ctx, &pb.FindEnabledServerRequest{
+			ServerId:       serverId,
+			IgnoreSSLCerts: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var server = serverResp.Server
+		if server == nil {
+			result = append(result, maps.Map{"id": serverId, "exists": false})
+			continue
+		}
+		var clusterId int64
+		if server.NodeCluster != nil {
+			clusterId = server.NodeCluster.Id
+		}
+		result = append(result, maps.Map{
+			"id":          server.Id,
+			"exists":      true,
+			"name":        server.Name,
+			"isOn":        server.IsOn,
+			"serverNames": string(server.ServerNamesJSON),
+			"clusterId":   clusterId,
+			"userId":      server.UserId,
+		})
+	}
+	return result, nil
+}
+
+// SnapshotCluster 集群当前的状态，提交删除申请时保存，执行前对比，集群被修改或者节点数变化时拒绝执行
+func SnapshotCluster(ctx context.Context, rpcClient *rpc.RPCClient, clusterId int64) (maps.Map, error) {
+	clusterResp, err := rpcClient.NodeClusterRPC().FindEnabledNodeCluster(ctx, &pb.FindEnabledNodeClusterRequest{NodeClusterId: clusterId})
+	if err != nil {
+		return nil, err
+	}
+	var cluster = clusterResp.NodeCluster
+	if cluster == nil {
+		return maps.Map{"id": clusterId, "exists": false}, nil
+	}
+	countNodesResp, err := rpcClient.NodeRPC().CountAllEnabledNodesMatch(ctx, &pb.CountAllEnabledNodesMatchRequest{NodeClusterId: clusterId})
+	if err != nil {
+		return nil, err
+	}
+	return maps.Map{
+		"id":         cluster.Id,
+		"exists":     true,
+		"name":       cluster.Name,
+		"countNodes": countNodesResp.Count,
+	}, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changeutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// SettingDefinition 需要审批的设置的读取和保存方法，用于审批通过后执行变更
+type SettingDefinition struct {
+	Title  string
+	Load   func() (any, error)
+	Update func(change *approvalutils.SettingChange) error
+
+	// Mask 可选，返回用于显示修改前后对比的值，用来隐藏密码等敏感信息
+	Mask func(oldValue any, newValue any) (any, any)
+}
+
+var settingDefinitionMap = map[string]*SettingDefinition{} // code => *SettingDefinition
+var settingDefinitionLocker = &sync.RWMutex{}
+
+// RegisterSetting 注册需要审批的设置，通常在设置所在包的 init() 中调用
+func RegisterSetting(code string, definition *SettingDefinition) {
+	settingDefinitionLocker.Lock()
+	settingDefinitionMap[code] = definition
+	settingDefinitionLocker.Unlock()
+}
+
+func findSetting(code string) *SettingDefinition {
+	settingDefinitionLocker.RLock()
+	defer settingDefinitionLocker.RUnlock()
+	return settingDefinitionMap[code]
+}
+
+func init() {
+	RegisterExecutor(KindUpdateSecurityConfig, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var definition = findSetting(change.Code)
+		if definition == nil {
+			return errors.New("invalid setting code '" + change.Code + "'")
+		}
+
+		// 只有在提交申请后设置没有被修改的情况下才执行，以免覆盖其他修改
+		current, err := definition.Load()
+		if err != nil {
+			return err
+		}
+		err = change.Check(current)
+		if err != nil {
+			return err
+		}
+		return definition.Update(change)
+	})
+}
+
+// SubmitSettingChange 检查修改安全设置是否需要审批，需要审批时提交变更申请
+// 返回 true 表示已经提交变更申请或者输出了错误信息，调用者不需要继续修改设置
+func SubmitSettingChange(parent *actionutils.ParentAction, code string, oldValue any, newValue any) bool {
+	isRequired, err := IsRequired(KindUpdateSecurityConfig)
+	if err != nil {
+		parent.ErrorPage(err)
+		return true
+	}
+	if !isRequired {
+		return false
+	}
+
+	var definition = findSetting(code)
+	if definition == nil {
+		parent.ErrorPage(errors.New("invalid setting code '" + code + "'"))
+		return true
+	}
+
+	change, err := approvalutils.NewSettingChange(code, oldValue, newValue)
+	if err != nil {
+		parent.ErrorPage(err)
+		return true
+	}
+	var diffOld, diffNew = oldValue, newValue
+	if definition.Mask != nil {
+		diffOld, diffNew = definition.Mask(oldValue, newValue)
+	}
+	diff, err := approvalutils.Diff(diffOld, diffNew)
+	if err != nil {
+		parent.ErrorPage(err)
+		return true
+	}
+	if len(diff) == 0 {
+		parent.Fail("设置没有任何修改，无需提交审批")
+		return true
+	}
+
+	_, err = Submit(parent, &approvalutils.ChangeRequest{
+		Kind:  KindUpdateSecurityConfig,
+		Title: definition.Title,
+		Diff:  diff,
+	}, change)
+	if err != nil {
+		parent.ErrorPage(err)
+		return true
+	}
+
+	parent.Success()
+	return true
+}
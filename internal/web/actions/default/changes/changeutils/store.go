@@ -0,0 +1,66 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changeutils
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// SettingCodeChangeRequests 变更申请在系统设置中的代号
+const SettingCodeChangeRequests = "adminChangeRequests"
+
+var storeLocker = &sync.Mutex{}
+
+// LoadStore 读取变更申请
+func LoadStore() (*approvalutils.Store, error) {
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(rpcClient.Context(0), &pb.ReadSysSettingRequest{Code: SettingCodeChangeRequests})
+	if err != nil {
+		return nil, err
+	}
+	var store = approvalutils.NewStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateStore 修改变更申请
+func UpdateStore(f func(store *approvalutils.Store) error) error {
+	storeLocker.Lock()
+	defer storeLocker.Unlock()
+
+	store, err := LoadStore()
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(rpcClient.Context(0), &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeChangeRequests,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changeutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/langs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/iwind/TeaGo/logs"
+)
+
+// IsRequired 检查某个操作是否需要审批
+func IsRequired(kind string) (bool, error) {
+	store, err := LoadStore()
+	if err != nil {
+		return false, err
+	}
+	return store.IsRequired(kind), nil
+}
+
+// Submit 提交变更申请，调用者需要先使用 IsRequired() 检查操作是否需要审批
+func Submit(parent *actionutils.ParentAction, request *approvalutils.ChangeRequest, payload any) (requestId int64, err error) {
+	var definition = FindKind(request.Kind)
+	if definition == nil {
+		return 0, errors.New("invalid change request kind '" + request.Kind + "'")
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var adminId = parent.AdminId()
+	request.Module = definition.Module
+	request.Payload = payloadJSON
+	request.RequesterId = adminId
+	request.RequesterName = configloaders.FindAdminFullname(adminId)
+	request.RequesterIP = loginutils.RemoteIP(&parent.ActionObject)
+	if len(request.Title) == 0 {
+		request.Title = definition.Name
+	}
+
+	err = UpdateStore(func(store *approvalutils.Store) error {
+		requestId = store.Add(request, time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	parent.Data["changeRequestId"] = requestId
+	parent.CreateLogInfo("提交变更申请 %d：%s", requestId, request.Title)
+
+	return requestId, nil
+}
+
+// Execute 以申请人的身份执行已批准的变更申请，并记录执行结果
+func Execute(requestId int64) error {
+	store, err := LoadStore()
+	if err != nil {
+		return err
+	}
+	var request = store.Find(requestId)
+	if request == nil {
+		return approvalutils.ErrRequestNotFound
+	}
+	if request.Status != approvalutils.StatusApproved {
+		return errors.New("change request has not been approved")
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return err
+	}
+
+	var execErr error
+	var executor = findExecutor(request.Kind)
+	if executor == nil {
+		execErr = errors.New("can not find executor for '" + request.Kind + "'")
+	} else {
+		execErr = executor(rpcClient.Context(request.RequesterId), rpcClient, request.Payload)
+	}
+
+	err = UpdateStore(func(store *approvalutils.Store) error {
+		store.Finish(requestId, execErr, time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		logs.Println("[CHANGES]save change request result failed: " + err.Error())
+	}
+
+	// 以申请人的身份记录执行日志
+	var level = oplogs.LevelInfo
+	var messageCode langs.MessageCode = "执行变更申请 %d：%s（审批人：%s）"
+	var args = []any{request.Id, request.Title, request.ReviewerName}
+	var desc = fmt.Sprintf(string(messageCode), args...)
+	if execErr != nil {
+		level = oplogs.LevelError
+		desc += " 失败：" + execErr.Error()
+	}
+	logErr := dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(request.RequesterId), level, "/changes/approve", desc, request.RequesterIP, messageCode, args)
+	if logErr != nil {
+		utils.PrintError(logErr)
+	}
+
+	return execErr
+}
+
+// CanReview 检查管理员是否可以审批某个变更申请
+// 审批人不能是申请人，并且需要有申请所属模块的读写权限，如果角色设置了资源范围，涉及的集群需要在范围内
+func CanReview(adminId int64, request *approvalutils.ChangeRequest) bool {
+	if adminId == request.RequesterId {
+		return false
+	}
+	if !configloaders.AllowModule(adminId, request.Module) {
+		return false
+	}
+	var grant = configloaders.FindAdminGrant(adminId)
+	if grant == nil {
+		return true
+	}
+	return grant.Allow(request.Module, rbacutils.PermissionWrite, rbacutils.NewClusterResource(request.ClusterIds...))
+}
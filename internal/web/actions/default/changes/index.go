@@ -0,0 +1,62 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "index")
+}
+
+func (this *IndexAction) RunGet(params struct {
+	Status string
+}) {
+	this.Data["status"] = params.Status
+
+	store, err := changeutils.LoadStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var adminId = this.AdminId()
+	var requests = store.List(params.Status)
+	var page = this.NewPage(int64(len(requests)))
+	this.Data["page"] = page.AsHTML()
+
+	var requestMaps = []maps.Map{}
+	for index, request := range requests {
+		if int64(index) < page.Offset {
+			continue
+		}
+		if int64(len(requestMaps)) >= page.Size {
+			break
+		}
+		requestMaps = append(requestMaps, maps.Map{
+			"id":            request.Id,
+			"title":         request.Title,
+			"kindName":      changeutils.FindKindName(request.Kind),
+			"requesterName": request.RequesterName,
+			"reviewerName":  request.ReviewerName,
+			"status":        request.Status,
+			"statusName":    approvalutils.StatusName(request.Status),
+			"createdTime":   timeutil.FormatTime("Y-m-d H:i:s", request.CreatedAt),
+			"isMine":        request.RequesterId == adminId,
+			"canReview":     request.Status == approvalutils.StatusPending && changeutils.CanReview(adminId, request),
+		})
+	}
+	this.Data["requests"] = requestMaps
+	this.Data["countPending"] = store.CountPending()
+
+	this.Show()
+}
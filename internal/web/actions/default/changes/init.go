@@ -0,0 +1,32 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
+	"github.com/iwind/TeaGo"
+)
+
+func init() {
+	TeaGo.BeforeStart(func(server *TeaGo.Server) {
+		server.
+			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeCommon)).
+			Data("teaMenu", "changes").
+			Prefix("/changes").
+			Get("", new(IndexAction)).
+			Get("/request", new(RequestAction)).
+			Post("/approve", new(ApproveAction)).
+			Post("/reject", new(RejectAction)).
+			Post("/cancel", new(CancelAction)).
+			EndAll()
+
+		// 修改需要审批的操作需要系统设置权限
+		server.
+			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeSetting)).
+			Data("teaMenu", "changes").
+			Prefix("/changes").
+			GetPost("/settings", new(SettingsAction)).
+			EndAll()
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// RejectAction 拒绝变更申请
+type RejectAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RejectAction) RunPost(params struct {
+	RequestId int64
+	Reason    string
+
+	Must *actions.Must
+}) {
+	defer this.CreateLogInfo("拒绝变更申请 %d", params.RequestId)
+
+	params.Must.
+		Field("reason", params.Reason).
+		Require("请输入拒绝的原因")
+
+	var adminId = this.AdminId()
+	err := changeutils.UpdateStore(func(store *approvalutils.Store) error {
+		err := checkReview(store, params.RequestId, adminId)
+		if err != nil {
+			return err
+		}
+		_, err = store.Reject(params.RequestId, adminId, configloaders.FindAdminFullname(adminId), params.Reason, time.Now().Unix())
+		return err
+	})
+	if err != nil {
+		failReview(this.Parent(), err)
+		return
+	}
+
+	this.Success()
+}
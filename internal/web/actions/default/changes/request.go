@@ -0,0 +1,74 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+type RequestAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RequestAction) Init() {
+	this.Nav("", "", "index")
+}
+
+func (this *RequestAction) RunGet(params struct {
+	RequestId int64
+}) {
+	store, err := changeutils.LoadStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var request = store.Find(params.RequestId)
+	if request == nil {
+		this.NotFound("changeRequest", params.RequestId)
+		return
+	}
+
+	var formatTime = func(timestamp int64) string {
+		if timestamp <= 0 {
+			return ""
+		}
+		return timeutil.FormatTime("Y-m-d H:i:s", timestamp)
+	}
+
+	var diff = request.Diff
+	if diff == nil {
+		diff = []*approvalutils.DiffItem{}
+	}
+	var preview = request.Preview
+	if preview == nil {
+		preview = []string{}
+	}
+
+	var adminId = this.AdminId()
+	var isPending = request.Status == approvalutils.StatusPending
+	this.Data["request"] = maps.Map{
+		"id":            request.Id,
+		"title":         request.Title,
+		"kindName":      changeutils.FindKindName(request.Kind),
+		"preview":       preview,
+		"diff":          diff,
+		"requesterName": request.RequesterName,
+		"requesterIP":   request.RequesterIP,
+		"reviewerName":  request.ReviewerName,
+		"reason":        request.Reason,
+		"error":         request.Error,
+		"status":        request.Status,
+		"statusName":    approvalutils.StatusName(request.Status),
+		"createdTime":   formatTime(request.CreatedAt),
+		"reviewedTime":  formatTime(request.ReviewedAt),
+		"executedTime":  formatTime(request.ExecutedAt),
+		"canReview":     isPending && changeutils.CanReview(adminId, request),
+		"canCancel":     isPending && request.RequesterId == adminId,
+	}
+
+	this.Show()
+}
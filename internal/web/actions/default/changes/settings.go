@@ -0,0 +1,140 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/maps"
+)
+
+func init() {
+	changeutils.RegisterExecutor(changeutils.KindUpdateApprovalSettings, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var kindCodes = []string{}
+		err = change.Decode(&kindCodes)
+		if err != nil {
+			return err
+		}
+		return changeutils.UpdateStore(func(store *approvalutils.Store) error {
+			// 提交申请后设置已被修改时不再执行
+			err := change.Check(store.RequiredKinds)
+			if err != nil {
+				return err
+			}
+			store.RequiredKinds = kindCodes
+			return nil
+		})
+	})
+}
+
+// SettingsAction 设置需要审批的操作
+type SettingsAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SettingsAction) Init() {
+	this.Nav("", "", "settings")
+}
+
+func (this *SettingsAction) RunGet(params struct{}) {
+	store, err := changeutils.LoadStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var kindMaps = []maps.Map{}
+	for _, kind := range changeutils.FindAllKinds() {
+		kindMaps = append(kindMaps, maps.Map{
+			"code":        kind.Code,
+			"name":        kind.Name,
+			"description": kind.Description,
+			"isRequired":  store.IsRequired(kind.Code),
+		})
+	}
+	this.Data["kinds"] = kindMaps
+	this.Data["requireApproval"] = len(store.RequiredKinds) > 0
+
+	this.Show()
+}
+
+func (this *SettingsAction) RunPost(params struct {
+	KindCodes []string
+
+	CSRF *actionutils.CSRF
+}) {
+	// 按照定义的顺序保存，方便对比
+	var kindCodes = []string{}
+	for _, kind := range changeutils.FindAllKinds() {
+		if lists.ContainsString(params.KindCodes, kind.Code) {
+			kindCodes = append(kindCodes, kind.Code)
+		}
+	}
+
+	store, err := changeutils.LoadStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 已经有需要审批的操作时，修改设置也需要其他管理员审批，防止一个管理员直接关闭审批
+	if len(store.RequiredKinds) > 0 {
+		change, err := approvalutils.NewSettingChange(changeutils.KindUpdateApprovalSettings, store.RequiredKinds, kindCodes)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		diff, err := approvalutils.Diff(this.kindNameMap(store.RequiredKinds), this.kindNameMap(kindCodes))
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if len(diff) == 0 {
+			this.Fail("需要审批的操作没有任何修改，无需提交审批")
+			return
+		}
+		_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+			Kind: changeutils.KindUpdateApprovalSettings,
+			Diff: diff,
+		}, change)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.Success()
+		return
+	}
+
+	defer this.CreateLogInfo("修改需要审批的操作")
+
+	err = changeutils.UpdateStore(func(store *approvalutils.Store) error {
+		store.RequiredKinds = kindCodes
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
+
+// 操作名称 => 是否需要审批，用于显示修改前后的对比
+func (this *SettingsAction) kindNameMap(kindCodes []string) map[string]bool {
+	var result = map[string]bool{}
+	for _, kind := range changeutils.FindAllKinds() {
+		result[kind.Name] = lists.ContainsString(kindCodes, kind.Code)
+	}
+	return result
+}
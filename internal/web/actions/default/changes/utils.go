@@ -0,0 +1,47 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package changes
+
+import (
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+)
+
+var errNoReviewPermission = errors.New("no permission to review the change request")
+
+// 检查管理员是否可以审批
+func checkReview(store *approvalutils.Store, requestId int64, adminId int64) error {
+	var request = store.Find(requestId)
+	if request == nil {
+		return approvalutils.ErrRequestNotFound
+	}
+	if request.Status != approvalutils.StatusPending {
+		return approvalutils.ErrRequestNotPending
+	}
+	if request.RequesterId == adminId {
+		return approvalutils.ErrSelfReview
+	}
+	if !changeutils.CanReview(adminId, request) {
+		return errNoReviewPermission
+	}
+	return nil
+}
+
+// 审批失败时的提示
+func failReview(action *actionutils.ParentAction, err error) {
+	switch err {
+	case approvalutils.ErrRequestNotFound:
+		action.Fail("找不到要审批的变更申请")
+	case approvalutils.ErrRequestNotPending:
+		action.Fail("此变更申请已经被处理，不能重复审批")
+	case approvalutils.ErrSelfReview:
+		action.Fail("不能审批自己提交的变更申请，需要由其他管理员审批")
+	case errNoReviewPermission:
+		action.Fail("权限不足：当前管理员没有审批此变更申请的权限")
+	default:
+		action.ErrorPage(err)
+	}
+}
@@ -4,6 +4,7 @@ package cluster
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/actions"
@@ -68,6 +69,11 @@ func (this *CreateNodeInstallAction) RunPost(params struct {
 	}
 
 	// 开始安装
+	err = grantutils.CheckNodeGrantUsable(this.AdminContext(), this.RPC(), params.NodeId)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
 	_, err = this.RPC().NodeRPC().InstallNode(this.AdminContext(), &pb.InstallNodeRequest{NodeId: params.NodeId})
 	if err != nil {
 		this.Fail("安装失败：" + err.Error())
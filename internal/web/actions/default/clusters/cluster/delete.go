@@ -1,11 +1,56 @@
 package cluster
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/types"
 )
 
+func init() {
+	changeutils.RegisterExecutor(changeutils.KindDeleteCluster, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var clusterId int64
+		err = change.Decode(&clusterId)
+		if err != nil {
+			return err
+		}
+
+		// 提交申请后集群已被修改时不再执行
+		snapshot, err := changeutils.SnapshotCluster(ctx, rpcClient, clusterId)
+		if err != nil {
+			return err
+		}
+		err = change.Check(snapshot)
+		if err != nil {
+			return err
+		}
+
+		// 审批期间可能有新的服务使用此集群
+		countResp, err := rpcClient.ServerRPC().CountAllEnabledServersWithNodeClusterId(ctx, &pb.CountAllEnabledServersWithNodeClusterIdRequest{NodeClusterId: clusterId})
+		if err != nil {
+			return err
+		}
+		if countResp.Count > 0 {
+			return errors.New("there are servers using the cluster")
+		}
+
+		_, err = rpcClient.NodeClusterRPC().DeleteNodeCluster(ctx, &pb.DeleteNodeClusterRequest{NodeClusterId: clusterId})
+		return err
+	})
+}
+
 type DeleteAction struct {
 	actionutils.ParentAction
 }
@@ -32,6 +77,51 @@ func (this *DeleteAction) RunPost(params struct {
 		this.Fail("有代理服务正在使用此集群，请修改这些代理服务后再删除")
 	}
 
+	// 是否需要审批
+	isRequired, err := changeutils.IsRequired(changeutils.KindDeleteCluster)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if isRequired {
+		clusterResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeCluster(this.AdminContext(), &pb.FindEnabledNodeClusterRequest{NodeClusterId: params.ClusterId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		var cluster = clusterResp.NodeCluster
+		if cluster == nil {
+			this.NotFound("nodeCluster", params.ClusterId)
+			return
+		}
+		snapshot, err := changeutils.SnapshotCluster(this.AdminContext(), this.RPC(), params.ClusterId)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		change, err := approvalutils.NewSettingChange(changeutils.KindDeleteCluster, snapshot, params.ClusterId)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+
+		_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+			Kind:  changeutils.KindDeleteCluster,
+			Title: "删除集群 " + cluster.Name,
+			Preview: []string{
+				"集群：" + cluster.Name + "（ID：" + types.String(cluster.Id) + "）",
+				"集群中的节点数：" + snapshot.GetString("countNodes"),
+			},
+			ClusterIds: []int64{params.ClusterId},
+		}, change)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.Success()
+		return
+	}
+
 	// 删除
 	_, err = this.RPC().NodeClusterRPC().DeleteNodeCluster(this.AdminContext(), &pb.DeleteNodeClusterRequest{NodeClusterId: params.ClusterId})
 	if err != nil {
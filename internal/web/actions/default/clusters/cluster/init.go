@@ -37,6 +37,13 @@ func init() {
 			GetPost("/installManual", new(InstallManualAction)).
 			Post("/suggestLoginPorts", new(SuggestLoginPortsAction)).
 			Get("/downloadInstaller", new(DownloadInstallerAction)).
+			GetPost("/provision", new(ProvisionAction)).
+			Post("/provision/delete", new(ProvisionDeleteAction)).
+			GetPost("/rollout", new(RolloutAction)).
+			GetPost("/rollout/detail", new(RolloutDetailAction)).
+			Post("/rollout/halt", new(RolloutHaltAction)).
+			Post("/rollout/resume", new(RolloutResumeAction)).
+			Post("/rollout/cancel", new(RolloutCancelAction)).
 
 			// 节点相关
 			Prefix("/clusters/cluster/node").
@@ -51,6 +58,8 @@ func init() {
 			Post("/uninstall", new(node.UninstallAction)).
 			Post("/up", new(node.UpAction)).
 			Post("/updateIsOn", new(node.UpdateIsOnAction)).
+			GetPost("/maintenance", new(node.MaintenanceAction)).
+			Post("/maintenance/exit", new(node.MaintenanceExitAction)).
 			Get("/detail", new(node.DetailAction)).
 			GetPost("/updateDNSPopup", new(node.UpdateDNSPopupAction)).
 			Post("/syncDomain", new(node.SyncDomainAction)).
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/maps"
 )
@@ -34,36 +35,11 @@ func (this *InstallNodesAction) RunGet(params struct {
 
 	cluster := clusterResp.NodeCluster
 
-	clusterAPINodesResp, err := this.RPC().NodeClusterRPC().FindAPINodesWithNodeCluster(this.AdminContext(), &pb.FindAPINodesWithNodeClusterRequest{NodeClusterId: params.ClusterId})
+	apiNodeAddrs, err := clusterutils.FindClusterAPIEndpoints(this.AdminContext(), this.RPC(), params.ClusterId)
 	if err != nil {
 		this.ErrorPage(err)
 		return
 	}
-	if err != nil {
-		this.ErrorPage(err)
-		return
-	}
-	apiNodeAddrs := []string{}
-	if clusterAPINodesResp.UseAllAPINodes {
-		apiNodesResp, err := this.RPC().APINodeRPC().FindAllEnabledAPINodes(this.AdminContext(), &pb.FindAllEnabledAPINodesRequest{})
-		if err != nil {
-			this.ErrorPage(err)
-			return
-		}
-		for _, apiNode := range apiNodesResp.ApiNodes {
-			if !apiNode.IsOn {
-				continue
-			}
-			apiNodeAddrs = append(apiNodeAddrs, apiNode.AccessAddrs...)
-		}
-	} else {
-		for _, apiNode := range clusterAPINodesResp.ApiNodes {
-			if !apiNode.IsOn {
-				continue
-			}
-			apiNodeAddrs = append(apiNodeAddrs, apiNode.AccessAddrs...)
-		}
-	}
 
 	this.Data["cluster"] = maps.Map{
 		"uniqueId":  cluster.UniqueId,
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/actions"
@@ -74,7 +75,13 @@ func (this *InstallRemoteAction) RunPost(params struct {
 
 	Must *actions.Must
 }) {
-	_, err := this.RPC().NodeRPC().InstallNode(this.AdminContext(), &pb.InstallNodeRequest{NodeId: params.NodeId})
+	err := grantutils.CheckNodeGrantUsable(this.AdminContext(), this.RPC(), params.NodeId)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	_, err = this.RPC().NodeRPC().InstallNode(this.AdminContext(), &pb.InstallNodeRequest{NodeId: params.NodeId})
 	if err != nil {
 		this.ErrorPage(err)
 		return
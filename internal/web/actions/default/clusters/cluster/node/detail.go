@@ -7,6 +7,7 @@ import (
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/cluster/node/nodeutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/nodes/ipAddresses/ipaddressutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/iputils"
@@ -345,6 +346,19 @@ func (this *DetailAction) RunGet(params struct {
 		}
 	}
 
+	// 维护模式
+	var maintenanceStatus = ""
+	maintenanceStore, err := nodeutils.LoadMaintenances(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var maintenance = maintenanceStore.FindActive(node.Id)
+	if maintenance != nil {
+		maintenanceStatus = maintenance.Status
+	}
+	this.Data["maintenanceStatus"] = maintenanceStatus
+
 	this.Data["node"] = maps.Map{
 		"id":                 node.Id,
 		"name":               node.Name,
@@ -0,0 +1,95 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package node
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/maintenanceutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/cluster/node/nodeutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// MaintenanceAction 节点维护模式
+type MaintenanceAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MaintenanceAction) Init() {
+	this.Nav("", "node", "maintenance")
+	this.SecondMenu("nodes")
+}
+
+func (this *MaintenanceAction) RunGet(params struct {
+	NodeId int64
+}) {
+	// 初始化节点信息（用于菜单）
+	_, err := nodeutils.InitNodeInfo(this.Parent(), params.NodeId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	store, err := nodeutils.LoadMaintenances(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var maintenanceMap maps.Map
+	var m = store.FindLatest(params.NodeId)
+	if m != nil {
+		var eventMaps = []maps.Map{}
+		for _, event := range m.Events {
+			eventMaps = append(eventMaps, maps.Map{
+				"level":       event.Level,
+				"message":     event.Message,
+				"createdTime": timeutil.FormatTime("Y-m-d H:i:s", event.CreatedAt),
+			})
+		}
+		maintenanceMap = maps.Map{
+			"status":          m.Status,
+			"isActive":        m.IsActive(),
+			"description":     m.Description,
+			"maxConnections":  m.MaxConnections,
+			"timeoutMinutes":  m.TimeoutSeconds / 60,
+			"connectionCount": m.ConnectionCount,
+			"isTimeout":       m.IsTimeout,
+			"startedTime":     timeutil.FormatTime("Y-m-d H:i:s", m.StartedAt),
+			"events":          eventMaps,
+		}
+	}
+	this.Data["maintenance"] = maintenanceMap
+
+	this.Show()
+}
+
+func (this *MaintenanceAction) RunPost(params struct {
+	NodeId         int64
+	MaxConnections int64
+	TimeoutMinutes int64
+	Description    string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("节点 %d 进入维护模式", params.NodeId)
+
+	params.Must.
+		Field("maxConnections", params.MaxConnections).
+		Gte(0, "连接数阈值不能小于0").
+		Field("timeoutMinutes", params.TimeoutMinutes).
+		Gt(0, "排空超时时间需要大于0")
+
+	err := nodeutils.EnterMaintenance(this.AdminContext(), this.RPC(), this.AdminId(), params.NodeId, params.MaxConnections, params.TimeoutMinutes*60, params.Description)
+	if err != nil {
+		if err == maintenanceutils.ErrMaintenanceExists {
+			this.Fail("节点已经处于维护模式")
+			return
+		}
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package node
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/maintenanceutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/cluster/node/nodeutils"
+)
+
+// MaintenanceExitAction 节点退出维护模式
+type MaintenanceExitAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MaintenanceExitAction) RunPost(params struct {
+	NodeId int64
+	Force  bool
+}) {
+	defer this.CreateLogInfo("节点 %d 退出维护模式", params.NodeId)
+
+	err := nodeutils.ExitMaintenance(this.AdminContext(), this.RPC(), params.NodeId, params.Force)
+	if err != nil {
+		if err == maintenanceutils.ErrMaintenanceNotFound {
+			this.Fail("节点当前不在维护模式")
+			return
+		}
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
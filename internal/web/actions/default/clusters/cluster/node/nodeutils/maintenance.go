@@ -0,0 +1,371 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package nodeutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/maintenanceutils"
+	nodesutils "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/nodes/nodeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/nodeconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// SettingCodeNodeMaintenances 节点维护记录在系统设置中的代号
+const SettingCodeNodeMaintenances = "adminNodeMaintenances"
+
+// 已恢复的维护记录保留时间
+const maintenanceKeepSeconds = 30 * 86400
+
+// MessageCodeDrainConnections 通知节点停止接受新的长连接并回复当前连接数
+const MessageCodeDrainConnections = "drainConnections"
+
+// DrainConnectionsMessage 排空消息
+type DrainConnectionsMessage struct {
+	MaxConnections int64 `json:"maxConnections"`
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+}
+
+// 节点对排空消息的回复
+type drainConnectionsReply struct {
+	ConnectionCount int64 `json:"connectionCount"`
+}
+
+var maintenancesLocker = &sync.Mutex{}
+
+// LoadMaintenances 读取所有节点维护记录
+func LoadMaintenances(ctx context.Context, rpcClient *rpc.RPCClient) (*maintenanceutils.Store, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeNodeMaintenances})
+	if err != nil {
+		return nil, err
+	}
+	var store = maintenanceutils.NewStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateMaintenances 修改节点维护记录
+func UpdateMaintenances(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *maintenanceutils.Store) error) error {
+	return updateMaintenancesIfChanged(ctx, rpcClient, func(store *maintenanceutils.Store) (bool, error) {
+		return true, f(store)
+	})
+}
+
+// 修改节点维护记录，f返回false时不保存
+func updateMaintenancesIfChanged(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *maintenanceutils.Store) (changed bool, err error)) error {
+	maintenancesLocker.Lock()
+	defer maintenancesLocker.Unlock()
+
+	store, err := LoadMaintenances(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	changed, err := f(store)
+	if err != nil || !changed {
+		return err
+	}
+	store.CleanRestored(time.Now().Unix(), maintenanceKeepSeconds)
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeNodeMaintenances,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// EnterMaintenance 节点进入维护模式
+// 先将节点设置为下线，并同步集群DNS，使节点从所有线路的解析中摘除，然后等待连接排空
+func EnterMaintenance(ctx context.Context, rpcClient *rpc.RPCClient, adminId int64, nodeId int64, maxConnections int64, timeoutSeconds int64, description string) error {
+	nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+	if err != nil {
+		return err
+	}
+	var node = nodeResp.Node
+	if node == nil {
+		return errors.New("node not found")
+	}
+	var clusterId int64
+	if node.NodeCluster != nil {
+		clusterId = node.NodeCluster.Id
+	}
+
+	var m = maintenanceutils.NewMaintenance(nodeId, clusterId, maxConnections, timeoutSeconds, time.Now().Unix())
+	m.AdminId = adminId
+	m.Description = description
+
+	err = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+		return store.Add(m)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = rpcClient.NodeRPC().UpdateNodeUp(ctx, &pb.UpdateNodeUpRequest{
+		NodeId: nodeId,
+		IsUp:   false,
+	})
+	if err != nil {
+		// 回滚
+		_ = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+			store.Items = removeMaintenance(store.Items, m)
+			return nil
+		})
+		return err
+	}
+
+	syncClusterDNSWithEvent(ctx, rpcClient, nodeId, clusterId)
+
+	// 通知节点开始排空，节点的回复在定时检查中读取
+	_, _ = sendDrainMessage(ctx, m)
+	return nil
+}
+
+// ExitMaintenance 节点退出维护模式
+// 节点需要在线才能恢复，force为true时跳过在线检查
+func ExitMaintenance(ctx context.Context, rpcClient *rpc.RPCClient, nodeId int64, force bool) error {
+	store, err := LoadMaintenances(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	var m = store.FindActive(nodeId)
+	if m == nil {
+		return maintenanceutils.ErrMaintenanceNotFound
+	}
+
+	if !force {
+		nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+		if err != nil {
+			return err
+		}
+		if nodeResp.Node == nil {
+			return errors.New("node not found")
+		}
+		status, err := decodeNodeStatus(nodeResp.Node)
+		if err != nil {
+			return err
+		}
+		if !status.IsActive {
+			return errors.New("节点当前不在线，请先启动节点")
+		}
+	}
+
+	_, err = rpcClient.NodeRPC().UpdateNodeUp(ctx, &pb.UpdateNodeUpRequest{
+		NodeId: nodeId,
+		IsUp:   true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+		var m = store.FindActive(nodeId)
+		if m != nil {
+			m.Restore(time.Now().Unix())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	syncClusterDNSWithEvent(ctx, rpcClient, nodeId, m.ClusterId)
+	return nil
+}
+
+// CheckMaintenances 检查所有维护中的节点
+// 等待排空的节点通过排空消息读取连接数，判断是否已经排空，只在状态变化时保存；同时防止维护中的节点被健康检查等自动上线
+func CheckMaintenances(rpcClient *rpc.RPCClient) error {
+	var ctx = rpcClient.Context(0)
+	store, err := LoadMaintenances(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	var items = store.FindAllActive()
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		var nodeId = item.NodeId
+		nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+		if err != nil {
+			return err
+		}
+		var node = nodeResp.Node
+		if node == nil {
+			// 节点已被删除
+			err = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+				var m = store.FindActive(nodeId)
+				if m != nil {
+					var now = time.Now().Unix()
+					m.AddEvent(maintenanceutils.LevelWarn, "节点已被删除", now)
+					m.Restore(now)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 维护中的节点不能被自动上线
+		if node.IsUp {
+			_, err = rpcClient.NodeRPC().UpdateNodeUp(ctx, &pb.UpdateNodeUpRequest{
+				NodeId: nodeId,
+				IsUp:   false,
+			})
+			if err != nil {
+				return err
+			}
+			err = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+				var m = store.FindActive(nodeId)
+				if m != nil {
+					m.AddEvent(maintenanceutils.LevelWarn, "节点在维护期间被重新上线，已再次下线", time.Now().Unix())
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if item.Status != maintenanceutils.StatusDraining {
+			continue
+		}
+
+		status, err := decodeNodeStatus(node)
+		if err != nil {
+			return err
+		}
+		var connectionCount = int64(status.ConnectionCount)
+		var statusUpdatedAt = status.UpdatedAt
+		if status.IsActive {
+			// 优先使用节点对排空消息的回复，不支持此消息的节点使用上报的连接数
+			count, ok := sendDrainMessage(ctx, item)
+			if ok {
+				connectionCount = count
+				statusUpdatedAt = time.Now().Unix()
+			}
+		}
+
+		// 只在状态变化时保存
+		err = updateMaintenancesIfChanged(ctx, rpcClient, func(store *maintenanceutils.Store) (bool, error) {
+			var m = store.FindActive(nodeId)
+			if m == nil {
+				return false, nil
+			}
+			var now = time.Now().Unix()
+			if !status.IsActive {
+				return m.MarkOffline(now), nil
+			}
+			return m.CheckDrain(connectionCount, statusUpdatedAt, now), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 向节点发送排空消息，并从回复中读取当前连接数
+func sendDrainMessage(ctx context.Context, m *maintenanceutils.Maintenance) (connectionCount int64, ok bool) {
+	results, err := nodesutils.SendMessageToNodeIds(ctx, []int64{m.NodeId}, MessageCodeDrainConnections, &DrainConnectionsMessage{
+		MaxConnections: m.MaxConnections,
+		TimeoutSeconds: m.TimeoutSeconds,
+	}, 5)
+	if err != nil || len(results) == 0 || !results[0].IsOK {
+		return 0, false
+	}
+	var reply = &drainConnectionsReply{ConnectionCount: -1}
+	err = json.Unmarshal([]byte(results[0].Message), reply)
+	if err != nil || reply.ConnectionCount < 0 {
+		return 0, false
+	}
+	return reply.ConnectionCount, true
+}
+
+// 同步集群DNS，并将结果记录到维护记录中
+func syncClusterDNSWithEvent(ctx context.Context, rpcClient *rpc.RPCClient, nodeId int64, clusterId int64) {
+	var level = maintenanceutils.LevelInfo
+	var message = "已同步集群DNS"
+	hasDomain, err := syncClusterDNS(ctx, rpcClient, clusterId)
+	if err != nil {
+		level = maintenanceutils.LevelError
+		message = "同步集群DNS失败：" + err.Error()
+	} else if !hasDomain {
+		level = maintenanceutils.LevelWarn
+		message = "集群尚未设置DNS域名，没有需要同步的解析记录"
+	}
+
+	_ = UpdateMaintenances(ctx, rpcClient, func(store *maintenanceutils.Store) error {
+		var m = store.FindLatest(nodeId)
+		if m != nil {
+			m.AddEvent(level, message, time.Now().Unix())
+		}
+		return nil
+	})
+}
+
+// 同步集群DNS
+func syncClusterDNS(ctx context.Context, rpcClient *rpc.RPCClient, clusterId int64) (hasDomain bool, err error) {
+	if clusterId <= 0 {
+		return false, nil
+	}
+	dnsResp, err := rpcClient.NodeClusterRPC().FindEnabledNodeClusterDNS(ctx, &pb.FindEnabledNodeClusterDNSRequest{NodeClusterId: clusterId})
+	if err != nil {
+		return false, err
+	}
+	if dnsResp.Domain == nil || dnsResp.Domain.Id <= 0 {
+		return false, nil
+	}
+	syncResp, err := rpcClient.DNSDomainRPC().SyncDNSDomainData(ctx, &pb.SyncDNSDomainDataRequest{
+		DnsDomainId:   dnsResp.Domain.Id,
+		NodeClusterId: clusterId,
+	})
+	if err != nil {
+		return true, err
+	}
+	if !syncResp.IsOk {
+		return true, errors.New(syncResp.Error)
+	}
+	return true, nil
+}
+
+func decodeNodeStatus(node *pb.Node) (*nodeconfigs.NodeStatus, error) {
+	var status = &nodeconfigs.NodeStatus{}
+	if len(node.StatusJSON) > 0 {
+		err := json.Unmarshal(node.StatusJSON, status)
+		if err != nil {
+			return nil, err
+		}
+	}
+	status.IsActive = status.IsActive && time.Now().Unix()-status.UpdatedAt <= 60 // N秒之内认为活跃
+	return status, nil
+}
+
+func removeMaintenance(items []*maintenanceutils.Maintenance, m *maintenanceutils.Maintenance) []*maintenanceutils.Maintenance {
+	var result = []*maintenanceutils.Maintenance{}
+	for _, item := range items {
+		if item.NodeId == m.NodeId && item.StartedAt == m.StartedAt {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
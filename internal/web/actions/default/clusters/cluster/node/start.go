@@ -2,6 +2,7 @@ package node
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 )
@@ -13,6 +14,12 @@ type StartAction struct {
 func (this *StartAction) RunPost(params struct {
 	NodeId int64
 }) {
+	err := grantutils.CheckNodeGrantUsable(this.AdminContext(), this.RPC(), params.NodeId)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
 	resp, err := this.RPC().NodeRPC().StartNode(this.AdminContext(), &pb.StartNodeRequest{NodeId: params.NodeId})
 	if err != nil {
 		this.ErrorPage(err)
@@ -0,0 +1,169 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/provisionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// ProvisionAction 通过cloud-init或Shell脚本自动安装节点
+type ProvisionAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ProvisionAction) Init() {
+	this.Nav("", "node", "install")
+	this.SecondMenu("nodes")
+}
+
+func (this *ProvisionAction) RunGet(params struct {
+	ClusterId int64
+}) {
+	this.Data["leftMenuItems"] = LeftMenuItemsForInstall(this.AdminContext(), params.ClusterId, "provision", this.LangCode())
+
+	// 默认的管理平台地址
+	var scheme = "http"
+	if this.Request.TLS != nil {
+		scheme = "https"
+	}
+	this.Data["adminURL"] = scheme + "://" + this.Request.Host
+
+	store, err := clusterutils.LoadProvisionTokens(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var now = time.Now().Unix()
+	var tokenMaps = []maps.Map{}
+	for _, token := range store.FindClusterTokens(params.ClusterId) {
+		var expiresTime = ""
+		if token.ExpiresAt > 0 {
+			expiresTime = timeutil.FormatTime("Y-m-d H:i:s", token.ExpiresAt)
+		}
+		tokenMaps = append(tokenMaps, maps.Map{
+			"id":          token.Id,
+			"maxUses":     token.MaxUses,
+			"uses":        token.Uses,
+			"createdTime": timeutil.FormatTime("Y-m-d H:i:s", token.CreatedAt),
+			"expiresTime": expiresTime,
+			"description": token.Description,
+			"isValid":     token.IsValid(now) == nil,
+			"nodeIds":     token.NodeIds,
+		})
+	}
+	this.Data["tokens"] = tokenMaps
+
+	this.Show()
+}
+
+func (this *ProvisionAction) RunPost(params struct {
+	ClusterId   int64
+	GroupId     int64
+	RegionId    int64
+	MaxUses     int
+	ExpiresDays int
+	Description string
+	AdminURL    string
+	InstallDir  string
+	EmbedSecret bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("为集群 %d 创建节点注册令牌", params.ClusterId)
+
+	params.Must.
+		Field("adminURL", params.AdminURL).
+		Require("请输入管理平台访问地址").
+		Match(`^(?i)https?://`, "管理平台访问地址需要以http://或https://开头").
+		Field("maxUses", params.MaxUses).
+		Gte(0, "可使用次数不能小于0").
+		Field("expiresDays", params.ExpiresDays).
+		Gte(0, "有效期不能小于0")
+
+	clusterResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeCluster(this.AdminContext(), &pb.FindEnabledNodeClusterRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var cluster = clusterResp.NodeCluster
+	if cluster == nil {
+		this.NotFound("nodeCluster", params.ClusterId)
+		return
+	}
+
+	endpoints, err := clusterutils.FindClusterAPIEndpoints(this.AdminContext(), this.RPC(), params.ClusterId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if len(endpoints) == 0 {
+		this.Fail("当前集群没有可用的API节点地址，请先设置API节点的访问地址")
+		return
+	}
+
+	var installDir = strings.TrimSpace(params.InstallDir)
+	if len(installDir) == 0 {
+		installDir = cluster.InstallDir
+	}
+
+	// 创建令牌
+	var now = time.Now().Unix()
+	var token = &provisionutils.Token{
+		ClusterId:   params.ClusterId,
+		GroupId:     params.GroupId,
+		RegionId:    params.RegionId,
+		MaxUses:     params.MaxUses,
+		CreatedAt:   now,
+		Description: params.Description,
+	}
+	if params.ExpiresDays > 0 {
+		token.ExpiresAt = now + int64(params.ExpiresDays)*86400
+	}
+	var plainToken string
+	err = clusterutils.UpdateProvisionTokens(this.AdminContext(), this.RPC(), func(store *provisionutils.TokenStore) error {
+		plainToken, err = store.Create(token)
+		return err
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 生成脚本
+	var bootstrapParams = &provisionutils.BootstrapParams{
+		AdminURL:        params.AdminURL,
+		Token:           plainToken,
+		ClusterUniqueId: cluster.UniqueId,
+		Endpoints:       endpoints,
+		InstallDir:      installDir,
+	}
+	if params.EmbedSecret {
+		bootstrapParams.ClusterSecret = cluster.Secret
+	}
+	script, err := provisionutils.BuildShellScript(bootstrapParams)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	userData, err := provisionutils.BuildCloudInit(bootstrapParams)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Data["token"] = plainToken
+	this.Data["script"] = script
+	this.Data["userData"] = userData
+
+	this.Success()
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/provisionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// ProvisionDeleteAction 删除节点注册令牌
+type ProvisionDeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ProvisionDeleteAction) RunPost(params struct {
+	ClusterId int64
+	TokenId   string
+}) {
+	defer this.CreateLogInfo("删除集群 %d 的节点注册令牌 %s", params.ClusterId, params.TokenId)
+
+	err := clusterutils.UpdateProvisionTokens(this.AdminContext(), this.RPC(), func(store *provisionutils.TokenStore) error {
+		for _, token := range store.FindClusterTokens(params.ClusterId) {
+			if token.Id == params.TokenId {
+				store.Delete(token.Id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/rands"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// RolloutAction 滚动升级
+type RolloutAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RolloutAction) Init() {
+	this.Nav("", "node", "install")
+	this.SecondMenu("nodes")
+}
+
+func (this *RolloutAction) RunGet(params struct {
+	ClusterId int64
+}) {
+	this.Data["leftMenuItems"] = LeftMenuItemsForInstall(this.AdminContext(), params.ClusterId, "rollout", this.LangCode())
+
+	// 待升级节点
+	resp, err := this.RPC().NodeRPC().FindAllUpgradeNodesWithNodeClusterId(this.AdminContext(), &pb.FindAllUpgradeNodesWithNodeClusterIdRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var nodeMaps = []maps.Map{}
+	for _, node := range resp.Nodes {
+		nodeMaps = append(nodeMaps, maps.Map{
+			"id":         node.Node.Id,
+			"name":       node.Node.Name,
+			"oldVersion": node.OldVersion,
+			"newVersion": node.NewVersion,
+		})
+	}
+	this.Data["nodes"] = nodeMaps
+
+	// 升级计划
+	store, err := clusterutils.LoadRollouts(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var rolloutMaps = []maps.Map{}
+	for _, rollout := range store.FindClusterRollouts(params.ClusterId) {
+		rolloutMaps = append(rolloutMaps, rolloutSummaryMap(rollout))
+	}
+	this.Data["rollouts"] = rolloutMaps
+
+	var activeRolloutId = ""
+	var activeRollout = store.FindActiveClusterRollout(params.ClusterId)
+	if activeRollout != nil {
+		activeRolloutId = activeRollout.Id
+	}
+	this.Data["activeRolloutId"] = activeRolloutId
+
+	this.Data["config"] = rolloututils.DefaultConfig()
+
+	this.Show()
+}
+
+func (this *RolloutAction) RunPost(params struct {
+	ClusterId             int64
+	CanaryCount           int
+	CanaryNodeIds         []int64
+	CanarySoakMinutes     int64
+	BatchSize             int
+	BatchSoakMinutes      int64
+	MaxErrorRate          float64
+	MaxFailedNodes        int
+	UpgradeTimeoutMinutes int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("为集群 %d 创建滚动升级计划", params.ClusterId)
+
+	params.Must.
+		Field("canaryCount", params.CanaryCount).
+		Gte(0, "金丝雀节点数量不能小于0").
+		Field("batchSize", params.BatchSize).
+		Gt(0, "每批节点数需要大于0").
+		Field("maxErrorRate", params.MaxErrorRate).
+		Gte(0, "失败比例阈值不能小于0").
+		Lte(100, "失败比例阈值不能大于100").
+		Field("maxFailedNodes", params.MaxFailedNodes).
+		Gte(0, "允许失败的节点数不能小于0").
+		Field("upgradeTimeoutMinutes", params.UpgradeTimeoutMinutes).
+		Gt(0, "升级超时时间需要大于0")
+
+	if params.CanarySoakMinutes < 0 || params.BatchSoakMinutes < 0 {
+		this.Fail("观察时间不能小于0")
+		return
+	}
+
+	resp, err := this.RPC().NodeRPC().FindAllUpgradeNodesWithNodeClusterId(this.AdminContext(), &pb.FindAllUpgradeNodesWithNodeClusterIdRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if len(resp.Nodes) == 0 {
+		this.Fail("当前集群没有需要升级的节点")
+		return
+	}
+	var nodes = []*rolloututils.Node{}
+	for _, node := range resp.Nodes {
+		nodes = append(nodes, &rolloututils.Node{
+			Id:         node.Node.Id,
+			Name:       node.Node.Name,
+			OldVersion: node.OldVersion,
+			NewVersion: node.NewVersion,
+		})
+	}
+
+	var rollout = rolloututils.NewRollout(rands.HexString(16), params.ClusterId, &rolloututils.Config{
+		CanaryCount:           params.CanaryCount,
+		CanaryNodeIds:         params.CanaryNodeIds,
+		CanarySoakSeconds:     params.CanarySoakMinutes * 60,
+		BatchSize:             params.BatchSize,
+		BatchSoakSeconds:      params.BatchSoakMinutes * 60,
+		MaxErrorRate:          params.MaxErrorRate,
+		MaxFailedNodes:        params.MaxFailedNodes,
+		UpgradeTimeoutSeconds: params.UpgradeTimeoutMinutes * 60,
+	}, nodes, time.Now().Unix())
+	rollout.AdminId = this.AdminId()
+
+	err = clusterutils.UpdateRollouts(this.AdminContext(), this.RPC(), func(store *rolloututils.Store) error {
+		if store.FindActiveClusterRollout(params.ClusterId) != nil {
+			return rolloututils.ErrRolloutExists
+		}
+		store.Add(rollout)
+		return nil
+	})
+	if err != nil {
+		if err == rolloututils.ErrRolloutExists {
+			this.Fail("当前集群已经有未结束的升级计划，请先结束或取消")
+			return
+		}
+		this.ErrorPage(err)
+		return
+	}
+
+	clusterutils.StartRollout(this.RPC(), rollout.Id)
+
+	this.Data["rolloutId"] = rollout.Id
+	this.Success()
+}
+
+// 升级计划概要信息
+func rolloutSummaryMap(rollout *rolloututils.Rollout) maps.Map {
+	var finishedTime = ""
+	if rollout.FinishedAt > 0 {
+		finishedTime = timeutil.FormatTime("Y-m-d H:i:s", rollout.FinishedAt)
+	}
+	return maps.Map{
+		"id":           rollout.Id,
+		"status":       rollout.Status,
+		"statusName":   rolloutStatusName(rollout.Status),
+		"haltReason":   rollout.HaltReason,
+		"createdTime":  timeutil.FormatTime("Y-m-d H:i:s", rollout.CreatedAt),
+		"finishedTime": finishedTime,
+		"countNodes":   len(rollout.Nodes),
+		"countOk":      rollout.CountNodes(rolloututils.NodeStatusOk),
+		"countFailed":  rollout.CountNodes(rolloututils.NodeStatusFailed),
+		"countSkipped": rollout.CountNodes(rolloututils.NodeStatusSkipped),
+		"currentBatch": rollout.CurrentBatch,
+		"countBatches": rollout.CountBatches(),
+		"errorRate":    rollout.ErrorRate(),
+	}
+}
+
+func rolloutStatusName(status string) string {
+	switch status {
+	case rolloututils.StatusRunning:
+		return "执行中"
+	case rolloututils.StatusHalted:
+		return "已暂停"
+	case rolloututils.StatusCancelled:
+		return "已取消"
+	case rolloututils.StatusFinished:
+		return "已完成"
+	}
+	return status
+}
+
+func rolloutNodeStatusName(status string) string {
+	switch status {
+	case rolloututils.NodeStatusPending:
+		return "等待升级"
+	case rolloututils.NodeStatusUpgrading:
+		return "升级中"
+	case rolloututils.NodeStatusSoaking:
+		return "观察中"
+	case rolloututils.NodeStatusOk:
+		return "成功"
+	case rolloututils.NodeStatusFailed:
+		return "失败"
+	case rolloututils.NodeStatusSkipped:
+		return "已跳过"
+	}
+	return status
+}
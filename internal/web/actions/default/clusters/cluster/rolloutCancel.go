@@ -0,0 +1,37 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// RolloutCancelAction 取消滚动升级
+type RolloutCancelAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RolloutCancelAction) RunPost(params struct {
+	ClusterId int64
+	RolloutId string
+}) {
+	defer this.CreateLogInfo("取消集群 %d 的滚动升级计划 %s", params.ClusterId, params.RolloutId)
+
+	err := clusterutils.UpdateRollout(this.AdminContext(), this.RPC(), params.RolloutId, func(rollout *rolloututils.Rollout) error {
+		if rollout.ClusterId != params.ClusterId {
+			return rolloututils.ErrRolloutNotFound
+		}
+		rollout.Cancel(time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
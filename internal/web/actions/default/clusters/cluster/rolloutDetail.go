@@ -0,0 +1,119 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// RolloutDetailAction 滚动升级进度
+type RolloutDetailAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RolloutDetailAction) Init() {
+	this.Nav("", "node", "install")
+	this.SecondMenu("nodes")
+}
+
+func (this *RolloutDetailAction) RunGet(params struct {
+	ClusterId int64
+	RolloutId string
+}) {
+	this.Data["leftMenuItems"] = LeftMenuItemsForInstall(this.AdminContext(), params.ClusterId, "rollout", this.LangCode())
+
+	rolloutMap, ok := this.findRolloutMap(params.ClusterId, params.RolloutId)
+	if !ok {
+		return
+	}
+	this.Data["rollout"] = rolloutMap
+
+	this.Show()
+}
+
+// RunPost 用于刷新进度
+func (this *RolloutDetailAction) RunPost(params struct {
+	ClusterId int64
+	RolloutId string
+}) {
+	rolloutMap, ok := this.findRolloutMap(params.ClusterId, params.RolloutId)
+	if !ok {
+		return
+	}
+	this.Data["rollout"] = rolloutMap
+
+	this.Success()
+}
+
+func (this *RolloutDetailAction) findRolloutMap(clusterId int64, rolloutId string) (maps.Map, bool) {
+	store, err := clusterutils.LoadRollouts(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return nil, false
+	}
+	var rollout = store.Find(rolloutId)
+	if rollout == nil || rollout.ClusterId != clusterId {
+		this.NotFound("rollout", 0)
+		return nil, false
+	}
+
+	var rolloutMap = rolloutSummaryMap(rollout)
+	rolloutMap["config"] = maps.Map{
+		"canaryCount":           rollout.Config.CanaryCount,
+		"canarySoakMinutes":     rollout.Config.CanarySoakSeconds / 60,
+		"batchSize":             rollout.Config.BatchSize,
+		"batchSoakMinutes":      rollout.Config.BatchSoakSeconds / 60,
+		"maxErrorRate":          rollout.Config.MaxErrorRate,
+		"maxFailedNodes":        rollout.Config.MaxFailedNodes,
+		"upgradeTimeoutMinutes": rollout.Config.UpgradeTimeoutSeconds / 60,
+	}
+
+	// 按批次列出节点
+	var batchMaps = []maps.Map{}
+	for batch := 0; batch < rollout.CountBatches(); batch++ {
+		var nodeMaps = []maps.Map{}
+		for _, node := range rollout.BatchNodes(batch) {
+			nodeMaps = append(nodeMaps, maps.Map{
+				"id":         node.Id,
+				"name":       node.Name,
+				"oldVersion": node.OldVersion,
+				"newVersion": node.NewVersion,
+				"isCanary":   node.IsCanary,
+				"status":     node.Status,
+				"statusName": rolloutNodeStatusName(node.Status),
+				"error":      node.Error,
+				"events":     rolloutEventMaps(node.Events),
+			})
+		}
+		if len(nodeMaps) == 0 {
+			continue
+		}
+		batchMaps = append(batchMaps, maps.Map{
+			"batch":     batch,
+			"isCanary":  batch == 0,
+			"isCurrent": batch == rollout.CurrentBatch && !rollout.IsDone(),
+			"nodes":     nodeMaps,
+		})
+	}
+	rolloutMap["batches"] = batchMaps
+	rolloutMap["events"] = rolloutEventMaps(rollout.Events)
+
+	return rolloutMap, true
+}
+
+func rolloutEventMaps(events []*rolloututils.Event) []maps.Map {
+	var result = []maps.Map{}
+	for _, event := range events {
+		result = append(result, maps.Map{
+			"nodeId":      event.NodeId,
+			"level":       event.Level,
+			"message":     event.Message,
+			"createdTime": timeutil.FormatTime("Y-m-d H:i:s", event.CreatedAt),
+		})
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"errors"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// RolloutHaltAction 手动暂停滚动升级
+// 正在升级的节点会继续完成，之后不再升级新的节点
+type RolloutHaltAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RolloutHaltAction) RunPost(params struct {
+	ClusterId int64
+	RolloutId string
+}) {
+	defer this.CreateLogInfo("暂停集群 %d 的滚动升级计划 %s", params.ClusterId, params.RolloutId)
+
+	err := clusterutils.UpdateRollout(this.AdminContext(), this.RPC(), params.RolloutId, func(rollout *rolloututils.Rollout) error {
+		if rollout.ClusterId != params.ClusterId {
+			return rolloututils.ErrRolloutNotFound
+		}
+		if rollout.Status != rolloututils.StatusRunning {
+			return errors.New("升级计划当前不在执行中")
+		}
+		rollout.Halt("管理员手动暂停", time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// RolloutResumeAction 继续执行暂停的滚动升级
+type RolloutResumeAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RolloutResumeAction) RunPost(params struct {
+	ClusterId  int64
+	RolloutId  string
+	SkipFailed bool
+}) {
+	defer this.CreateLogInfo("继续执行集群 %d 的滚动升级计划 %s", params.ClusterId, params.RolloutId)
+
+	err := clusterutils.UpdateRollout(this.AdminContext(), this.RPC(), params.RolloutId, func(rollout *rolloututils.Rollout) error {
+		if rollout.ClusterId != params.ClusterId {
+			return rolloututils.ErrRolloutNotFound
+		}
+		return rollout.Resume(params.SkipFailed, time.Now().Unix())
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	clusterutils.StartRollout(this.RPC(), params.RolloutId)
+
+	this.Success()
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dns
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dnsrecordutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/iputils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// ReconcileAction 对比期望的解析记录和实际的解析记录
+type ReconcileAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ReconcileAction) Init() {
+	this.Nav("", "setting", "reconcile")
+	this.SecondMenu("dns")
+}
+
+func (this *ReconcileAction) RunGet(params struct {
+	ClusterId int64
+}) {
+	clusterResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeCluster(this.AdminContext(), &pb.FindEnabledNodeClusterRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var cluster = clusterResp.NodeCluster
+	if cluster == nil {
+		this.NotFound("nodeCluster", params.ClusterId)
+		return
+	}
+	this.Data["cluster"] = maps.Map{
+		"id":   cluster.Id,
+		"name": cluster.Name,
+	}
+
+	dnsResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeClusterDNS(this.AdminContext(), &pb.FindEnabledNodeClusterDNSRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var domainName = ""
+	if dnsResp.Domain != nil {
+		domainName = dnsResp.Domain.Name
+	}
+	var defaultRoute = dnsResp.DefaultRoute
+
+	this.Data["domainName"] = domainName
+	this.Data["dnsName"] = dnsResp.Name
+	this.Data["nameservers"] = []string{}
+	this.Data["resolveMode"] = ""
+	this.Data["results"] = []*dnsrecordutils.RecordResult{}
+	this.Data["countMap"] = dnsrecordutils.CountStatus(nil)
+
+	if cluster.DnsDomainId <= 0 || len(domainName) == 0 || len(dnsResp.Name) == 0 {
+		this.Show()
+		return
+	}
+	var clusterDNSName = dnsResp.Name + "." + domainName
+
+	// 期望的记录
+	var expectedRecords = []*dnsrecordutils.ExpectedRecord{}
+	nodesResp, err := this.RPC().NodeRPC().FindAllEnabledNodesDNSWithNodeClusterId(this.AdminContext(), &pb.FindAllEnabledNodesDNSWithNodeClusterIdRequest{
+		NodeClusterId: params.ClusterId,
+		IsInstalled:   true,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	for _, node := range nodesResp.Nodes {
+		if len(node.IpAddr) == 0 || node.IsOffline || node.IsBackupForCluster || node.IsBackupForGroup {
+			continue
+		}
+		var recordType = "A"
+		if iputils.IsIPv6(node.IpAddr) {
+			recordType = "AAAA"
+		}
+
+		var routes = node.Routes
+		if len(routes) == 0 {
+			if len(defaultRoute) == 0 {
+				continue
+			}
+			routes = []*pb.DNSRoute{{Name: "", Code: defaultRoute}}
+		}
+		for _, route := range routes {
+			expectedRecords = append(expectedRecords, &dnsrecordutils.ExpectedRecord{
+				Kind:           dnsrecordutils.RecordKindNode,
+				TargetId:       node.Id,
+				IPAddrId:       node.NodeIPAddressId,
+				Name:           clusterDNSName,
+				Type:           recordType,
+				Route:          route.Code,
+				RouteName:      route.Name,
+				Value:          node.IpAddr,
+				IsDefaultRoute: route.Code == defaultRoute,
+			})
+		}
+	}
+
+	serversResp, err := this.RPC().ServerRPC().FindAllEnabledServersDNSWithNodeClusterId(this.AdminContext(), &pb.FindAllEnabledServersDNSWithNodeClusterIdRequest{NodeClusterId: params.ClusterId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	for _, server := range serversResp.Servers {
+		if len(server.DnsName) == 0 {
+			continue
+		}
+		expectedRecords = append(expectedRecords, &dnsrecordutils.ExpectedRecord{
+			Kind:           dnsrecordutils.RecordKindServer,
+			TargetId:       server.Id,
+			Name:           server.DnsName + "." + domainName,
+			Type:           "CNAME",
+			Value:          clusterDNSName,
+			IsDefaultRoute: true,
+		})
+	}
+
+	// DNS服务商中的记录
+	var providerExists = map[string]bool{}
+	for _, record := range expectedRecords {
+		var name = dnsResp.Name
+		var route = record.Route
+		if record.Kind == dnsrecordutils.RecordKindServer {
+			name = strings.TrimSuffix(record.Name, "."+domainName)
+		}
+		existResp, err := this.RPC().DNSDomainRPC().ExistDNSDomainRecord(this.AdminContext(), &pb.ExistDNSDomainRecordRequest{
+			DnsDomainId: cluster.DnsDomainId,
+			Name:        name,
+			Type:        record.Type,
+			Route:       route,
+			Value:       record.Value,
+		})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		providerExists[record.Key()] = existResp.IsOk
+	}
+
+	// 独立查询实际的解析结果
+	var resolutions = map[string]*dnsrecordutils.Resolution{}
+	var resolver = dnsrecordutils.NewResolver(5 * time.Second)
+	err = resolver.InitNameservers(domainName)
+	if err == nil {
+		this.Data["resolveMode"] = "authoritative"
+		this.Data["nameservers"] = resolver.Nameservers()
+	} else {
+		// 找不到权威服务器时使用系统的DNS服务器查询
+		this.Data["resolveMode"] = "recursive"
+		this.Data["resolveError"] = err.Error()
+	}
+	for _, record := range expectedRecords {
+		var resolutionKey = dnsrecordutils.ResolutionKey(record.Name, record.Type)
+		_, ok := resolutions[resolutionKey]
+		if ok {
+			continue
+		}
+		if len(resolver.Nameservers()) > 0 {
+			resolutions[resolutionKey] = resolver.Resolve(record.Name, record.Type)
+		} else {
+			resolutions[resolutionKey] = this.resolveRecursive(record.Name, record.Type)
+		}
+	}
+
+	var results = dnsrecordutils.Reconcile(expectedRecords, providerExists, resolutions)
+	this.Data["results"] = results
+	this.Data["countMap"] = dnsrecordutils.CountStatus(results)
+
+	this.Show()
+}
+
+// 通过系统DNS服务器查询
+func (this *ReconcileAction) resolveRecursive(name string, recordType string) *dnsrecordutils.Resolution {
+	var resolution = &dnsrecordutils.Resolution{
+		Name:   name,
+		Type:   recordType,
+		Values: []string{},
+	}
+	switch recordType {
+	case "CNAME":
+		cname, err := utils.LookupCNAME(name)
+		if err != nil {
+			resolution.Err = err
+			return resolution
+		}
+		if len(cname) > 0 {
+			resolution.Values = append(resolution.Values, strings.TrimSuffix(cname, "."))
+		}
+	default:
+		ips, err := utils.LookupIPWithTimeout(name, 5000)
+		if err != nil {
+			resolution.Err = err
+			return resolution
+		}
+		for _, ip := range ips {
+			if (recordType == "AAAA") == iputils.IsIPv6(ip) {
+				resolution.Values = append(resolution.Values, ip)
+			}
+		}
+	}
+	return resolution
+}
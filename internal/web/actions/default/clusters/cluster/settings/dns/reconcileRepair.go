@@ -0,0 +1,113 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package dns
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dnsrecordutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/types"
+)
+
+// ReconcileRepairAction 修复选中的解析记录
+type ReconcileRepairAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ReconcileRepairAction) RunPost(params struct {
+	ClusterId int64
+	Items     []string // kind:targetId:ipAddrId
+}) {
+	defer this.CreateLogInfo("修复集群 %d 的DNS解析记录", params.ClusterId)
+
+	if len(params.Items) == 0 {
+		this.Fail("请选择要修复的记录")
+		return
+	}
+
+	var shouldSyncCluster = false
+	var repairedNodeKeys = map[string]bool{}
+	for _, item := range params.Items {
+		var pieces = strings.Split(item, ":")
+		switch pieces[0] {
+		case dnsrecordutils.RecordKindNode:
+			if len(pieces) != 3 {
+				continue
+			}
+
+			// 同一个节点IP只需要修复一次
+			if repairedNodeKeys[item] {
+				continue
+			}
+			repairedNodeKeys[item] = true
+
+			var nodeId = types.Int64(pieces[1])
+			var ipAddrId = types.Int64(pieces[2])
+			dnsInfoResp, err := this.RPC().NodeRPC().FindEnabledNodeDNS(this.AdminContext(), &pb.FindEnabledNodeDNSRequest{
+				NodeId:        nodeId,
+				NodeClusterId: params.ClusterId,
+				NodeIPAddrId:  ipAddrId,
+			})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			var dnsInfo = dnsInfoResp.Node
+			if dnsInfo == nil || len(dnsInfo.IpAddr) == 0 || dnsInfo.DnsDomainId <= 0 {
+				continue
+			}
+			var routeCodes = []string{}
+			for _, route := range dnsInfo.Routes {
+				routeCodes = append(routeCodes, route.Code)
+			}
+
+			// 重新保存节点DNS设置，API节点会为此节点生成新的DNS任务
+			_, err = this.RPC().NodeRPC().UpdateNodeDNS(this.AdminContext(), &pb.UpdateNodeDNSRequest{
+				NodeId:          nodeId,
+				IpAddr:          dnsInfo.IpAddr,
+				NodeIPAddressId: dnsInfo.NodeIPAddressId,
+				DnsDomainId:     dnsInfo.DnsDomainId,
+				Routes:          routeCodes,
+			})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+		case dnsrecordutils.RecordKindServer, dnsrecordutils.RecordKindExtra:
+			// 网站记录和多余的记录只能通过同步整个集群来修复
+			shouldSyncCluster = true
+		}
+	}
+
+	if shouldSyncCluster {
+		dnsResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeClusterDNS(this.AdminContext(), &pb.FindEnabledNodeClusterDNSRequest{NodeClusterId: params.ClusterId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if dnsResp.Domain == nil || dnsResp.Domain.Id <= 0 {
+			this.Fail("此集群尚未设置域名")
+			return
+		}
+		syncResp, err := this.RPC().DNSDomainRPC().SyncDNSDomainData(this.AdminContext(), &pb.SyncDNSDomainDataRequest{
+			DnsDomainId:   dnsResp.Domain.Id,
+			NodeClusterId: params.ClusterId,
+		})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if syncResp.ShouldFix {
+			this.Fail("请先修改解析记录页面中红色标记的问题")
+			return
+		}
+		if !syncResp.IsOk {
+			this.Fail(syncResp.Error)
+			return
+		}
+	}
+
+	this.Success()
+}
@@ -41,6 +41,8 @@ func init() {
 			Prefix("/clusters/cluster/settings/dns").
 			GetPost("", new(dns.IndexAction)).
 			Get("/records", new(dns.RecordsAction)).
+			Get("/reconcile", new(dns.ReconcileAction)).
+			Post("/reconcile/repair", new(dns.ReconcileRepairAction)).
 			Post("/randomName", new(dns.RandomNameAction)).
 
 			// 系统服务设置
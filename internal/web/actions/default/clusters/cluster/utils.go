@@ -42,6 +42,11 @@ func LeftMenuItemsForInstall(ctx context.Context, clusterId int64, selectedItem
 			"url":      "/clusters/cluster/installNodes?clusterId=" + numberutils.FormatInt64(clusterId),
 			"isActive": selectedItem == "register",
 		},
+		{
+			"name":     "云主机自动安装",
+			"url":      "/clusters/cluster/provision?clusterId=" + numberutils.FormatInt64(clusterId),
+			"isActive": selectedItem == "provision",
+		},
 		{
 			"name":     langs.Message(langCode, codes.NodeMenu_InstallRemote, countNotInstalled),
 			"url":      "/clusters/cluster/installRemote?clusterId=" + numberutils.FormatInt64(clusterId),
@@ -52,5 +57,10 @@ func LeftMenuItemsForInstall(ctx context.Context, clusterId int64, selectedItem
 			"url":      "/clusters/cluster/upgradeRemote?clusterId=" + numberutils.FormatInt64(clusterId),
 			"isActive": selectedItem == "upgrade",
 		},
+		{
+			"name":     "滚动升级",
+			"url":      "/clusters/cluster/rollout?clusterId=" + numberutils.FormatInt64(clusterId),
+			"isActive": selectedItem == "rollout",
+		},
 	}
 }
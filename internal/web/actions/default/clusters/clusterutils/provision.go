@@ -0,0 +1,90 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clusterutils
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/provisionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// SettingCodeProvisionTokens 节点注册令牌在系统设置中的代号
+const SettingCodeProvisionTokens = "adminProvisionTokens"
+
+// 过期的令牌保留时间
+const provisionTokenKeepSeconds = 7 * 86400
+
+var provisionTokensLocker = &sync.Mutex{}
+
+// LoadProvisionTokens 读取所有节点注册令牌
+func LoadProvisionTokens(ctx context.Context, rpcClient *rpc.RPCClient) (*provisionutils.TokenStore, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeProvisionTokens})
+	if err != nil {
+		return nil, err
+	}
+	var store = provisionutils.NewTokenStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateProvisionTokens 修改节点注册令牌
+// 读取、修改、保存在同一个锁内完成，避免并发注册时丢失使用次数
+func UpdateProvisionTokens(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *provisionutils.TokenStore) error) error {
+	provisionTokensLocker.Lock()
+	defer provisionTokensLocker.Unlock()
+
+	store, err := LoadProvisionTokens(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+	store.CleanExpired(time.Now().Unix(), provisionTokenKeepSeconds)
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeProvisionTokens,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// FindClusterAPIEndpoints 查找集群节点可以使用的API节点地址
+func FindClusterAPIEndpoints(ctx context.Context, rpcClient *rpc.RPCClient, clusterId int64) ([]string, error) {
+	clusterAPINodesResp, err := rpcClient.NodeClusterRPC().FindAPINodesWithNodeCluster(ctx, &pb.FindAPINodesWithNodeClusterRequest{NodeClusterId: clusterId})
+	if err != nil {
+		return nil, err
+	}
+	var apiNodes = clusterAPINodesResp.ApiNodes
+	if clusterAPINodesResp.UseAllAPINodes {
+		apiNodesResp, err := rpcClient.APINodeRPC().FindAllEnabledAPINodes(ctx, &pb.FindAllEnabledAPINodesRequest{})
+		if err != nil {
+			return nil, err
+		}
+		apiNodes = apiNodesResp.ApiNodes
+	}
+
+	var endpoints = []string{}
+	for _, apiNode := range apiNodes {
+		if !apiNode.IsOn {
+			continue
+		}
+		endpoints = append(endpoints, apiNode.AccessAddrs...)
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,437 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clusterutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rolloututils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/nodeconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/logs"
+)
+
+// SettingCodeUpgradeRollouts 滚动升级计划在系统设置中的代号
+const SettingCodeUpgradeRollouts = "adminUpgradeRollouts"
+
+// 每个集群保留的已结束升级计划数量
+const rolloutKeepPerCluster = 10
+
+var rolloutsLocker = &sync.Mutex{}
+
+var runningRolloutMap = map[string]bool{} // rolloutId => true
+var runningRolloutLocker = &sync.Mutex{}
+
+// LoadRollouts 读取所有滚动升级计划
+func LoadRollouts(ctx context.Context, rpcClient *rpc.RPCClient) (*rolloututils.Store, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeUpgradeRollouts})
+	if err != nil {
+		return nil, err
+	}
+	var store = rolloututils.NewStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateRollouts 修改滚动升级计划
+func UpdateRollouts(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *rolloututils.Store) error) error {
+	rolloutsLocker.Lock()
+	defer rolloutsLocker.Unlock()
+
+	store, err := LoadRollouts(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+	store.CleanFinished(rolloutKeepPerCluster)
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeUpgradeRollouts,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// UpdateRollout 修改单个滚动升级计划
+func UpdateRollout(ctx context.Context, rpcClient *rpc.RPCClient, rolloutId string, f func(rollout *rolloututils.Rollout) error) error {
+	return UpdateRollouts(ctx, rpcClient, func(store *rolloututils.Store) error {
+		var rollout = store.Find(rolloutId)
+		if rollout == nil {
+			return rolloututils.ErrRolloutNotFound
+		}
+		return f(rollout)
+	})
+}
+
+// StartRollout 在后台执行滚动升级计划
+func StartRollout(rpcClient *rpc.RPCClient, rolloutId string) {
+	runningRolloutLocker.Lock()
+	if runningRolloutMap[rolloutId] {
+		runningRolloutLocker.Unlock()
+		return
+	}
+	runningRolloutMap[rolloutId] = true
+	runningRolloutLocker.Unlock()
+
+	goman.New(func() {
+		defer func() {
+			runningRolloutLocker.Lock()
+			delete(runningRolloutMap, rolloutId)
+			runningRolloutLocker.Unlock()
+		}()
+
+		var runner = &rolloutRunner{
+			rpcClient: rpcClient,
+			rolloutId: rolloutId,
+		}
+		err := runner.Run()
+		if err != nil {
+			logs.Println("[ROLLOUT]run rollout '" + rolloutId + "' failed: " + err.Error())
+		}
+	})
+}
+
+// ResumeRunningRollouts 恢复所有执行中的升级计划，用于管理平台重启后继续执行
+func ResumeRunningRollouts(rpcClient *rpc.RPCClient) error {
+	store, err := LoadRollouts(rpcClient.Context(0), rpcClient)
+	if err != nil {
+		return err
+	}
+	for _, rollout := range store.FindRunningRollouts() {
+		StartRollout(rpcClient, rollout.Id)
+	}
+	return nil
+}
+
+// 滚动升级执行器
+type rolloutRunner struct {
+	rpcClient *rpc.RPCClient
+	rolloutId string
+	adminId   int64
+}
+
+func (this *rolloutRunner) Run() error {
+	// 上次执行被中断（比如管理平台重启）时，未完成的节点需要重新升级
+	err := this.update(func(rollout *rolloututils.Rollout) error {
+		var now = time.Now().Unix()
+		for _, node := range rollout.Nodes {
+			if node.Status == rolloututils.NodeStatusUpgrading || node.Status == rolloututils.NodeStatusSoaking {
+				node.Status = rolloututils.NodeStatusPending
+				rollout.AddEvent(node.Id, rolloututils.LevelWarn, "升级过程被中断，重新加入升级队列", now)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		var batch = -1
+		var nodes = []*rolloututils.Node{}
+		var isCanary = false
+		var config *rolloututils.Config
+		var clusterId int64
+		err := this.update(func(rollout *rolloututils.Rollout) error {
+			if rollout.Status != rolloututils.StatusRunning {
+				return nil
+			}
+			this.adminId = rollout.AdminId
+			config = rollout.Config
+			clusterId = rollout.ClusterId
+
+			batch = rollout.NextBatch()
+			if batch < 0 {
+				rollout.Finish(time.Now().Unix())
+				return nil
+			}
+			rollout.CurrentBatch = batch
+			for _, node := range rollout.BatchNodes(batch) {
+				if node.Status == rolloututils.NodeStatusPending {
+					nodes = append(nodes, node)
+				}
+			}
+			isCanary = batch == 0
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if batch < 0 || len(nodes) == 0 {
+			return nil
+		}
+
+		// 升级本批次节点
+		var wg = &sync.WaitGroup{}
+		wg.Add(len(nodes))
+		for _, node := range nodes {
+			var nodeId = node.Id
+			goman.New(func() {
+				defer wg.Done()
+				this.upgradeNode(nodeId, config.UpgradeTimeoutSeconds)
+			})
+		}
+		wg.Wait()
+
+		// 观察
+		var soakSeconds = config.BatchSoakSeconds
+		if isCanary {
+			soakSeconds = config.CanarySoakSeconds
+		}
+		err = this.soak(clusterId, batch, soakSeconds)
+		if err != nil {
+			return err
+		}
+
+		// 检查是否需要暂停
+		var isHalted = false
+		err = this.update(func(rollout *rolloututils.Rollout) error {
+			if rollout.Status != rolloututils.StatusRunning {
+				isHalted = true
+				return nil
+			}
+			var now = time.Now().Unix()
+			for _, node := range rollout.BatchNodes(batch) {
+				if node.Status == rolloututils.NodeStatusSoaking {
+					node.Status = rolloututils.NodeStatusOk
+					node.FinishedAt = now
+					rollout.AddEvent(node.Id, rolloututils.LevelInfo, "观察期结束，升级成功", now)
+				}
+			}
+			shouldHalt, reason := rollout.ShouldHalt()
+			if shouldHalt {
+				rollout.Halt(reason, now)
+				isHalted = true
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if isHalted {
+			return nil
+		}
+	}
+}
+
+// 升级单个节点并等待升级结束
+func (this *rolloutRunner) upgradeNode(nodeId int64, timeoutSeconds int64) {
+	var startedAt = time.Now()
+	err := this.update(func(rollout *rolloututils.Rollout) error {
+		var node = rollout.FindNode(nodeId)
+		if node == nil {
+			return errors.New("node not found")
+		}
+		node.Status = rolloututils.NodeStatusUpgrading
+		node.StartedAt = startedAt.Unix()
+		rollout.AddEvent(nodeId, rolloututils.LevelInfo, "开始升级", startedAt.Unix())
+		return nil
+	})
+	if err != nil {
+		logs.Println("[ROLLOUT]" + err.Error())
+		return
+	}
+
+	err = grantutils.CheckNodeGrantUsable(this.rpcClient.Context(this.adminId), this.rpcClient, nodeId)
+	if err != nil {
+		this.failNode(nodeId, err.Error())
+		return
+	}
+	_, err = this.rpcClient.NodeRPC().UpgradeNode(this.rpcClient.Context(this.adminId), &pb.UpgradeNodeRequest{NodeId: nodeId})
+	if err != nil {
+		this.failNode(nodeId, "调用升级接口失败："+err.Error())
+		return
+	}
+
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = rolloututils.DefaultConfig().UpgradeTimeoutSeconds
+	}
+	var interval = 5 * time.Second
+	if Tea.IsTesting() {
+		interval = 1 * time.Second
+	}
+	for {
+		time.Sleep(interval)
+
+		if time.Since(startedAt) > time.Duration(timeoutSeconds)*time.Second {
+			this.failNode(nodeId, "升级超时")
+			return
+		}
+
+		statusResp, err := this.rpcClient.NodeRPC().FindNodeInstallStatus(this.rpcClient.Context(this.adminId), &pb.FindNodeInstallStatusRequest{NodeId: nodeId})
+		if err != nil {
+			logs.Println("[ROLLOUT]find install status failed: " + err.Error())
+			continue
+		}
+		var installStatus = statusResp.InstallStatus
+		if installStatus == nil || !installStatus.IsFinished {
+			continue
+		}
+		if !installStatus.IsOk {
+			var errString = installStatus.Error
+			if len(errString) == 0 {
+				errString = installStatus.ErrorCode
+			}
+			this.failNode(nodeId, "升级失败："+errString)
+			return
+		}
+
+		_ = this.update(func(rollout *rolloututils.Rollout) error {
+			var node = rollout.FindNode(nodeId)
+			if node != nil {
+				node.Status = rolloututils.NodeStatusSoaking
+				rollout.AddEvent(nodeId, rolloututils.LevelInfo, "升级程序执行完成，进入观察期", time.Now().Unix())
+			}
+			return nil
+		})
+		return
+	}
+}
+
+// 观察本批次节点的运行状态和健康检查结果
+func (this *rolloutRunner) soak(clusterId int64, batch int, soakSeconds int64) error {
+	var interval = 30 * time.Second
+	if Tea.IsTesting() {
+		interval = 5 * time.Second
+	}
+	var deadline = time.Now().Add(time.Duration(soakSeconds) * time.Second)
+	for {
+		var nodeIds = []int64{}
+		var isRunning = true
+		err := this.update(func(rollout *rolloututils.Rollout) error {
+			isRunning = rollout.Status == rolloututils.StatusRunning
+			for _, node := range rollout.BatchNodes(batch) {
+				if node.Status == rolloututils.NodeStatusSoaking {
+					nodeIds = append(nodeIds, node.Id)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !isRunning || len(nodeIds) == 0 {
+			return nil
+		}
+
+		var failedMap = this.checkNodes(clusterId, nodeIds)
+		if len(failedMap) > 0 {
+			err = this.update(func(rollout *rolloututils.Rollout) error {
+				var now = time.Now().Unix()
+				for nodeId, reason := range failedMap {
+					var node = rollout.FindNode(nodeId)
+					if node == nil || node.Status != rolloututils.NodeStatusSoaking {
+						continue
+					}
+					node.Status = rolloututils.NodeStatusFailed
+					node.Error = reason
+					node.FinishedAt = now
+					rollout.AddEvent(nodeId, rolloututils.LevelError, "观察期检查失败："+reason, now)
+				}
+
+				// 观察期内失败过多时立即暂停，不用等到观察期结束
+				shouldHalt, haltReason := rollout.ShouldHalt()
+				if shouldHalt {
+					rollout.Halt(haltReason, now)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// 检查节点状态，返回失败的节点和原因
+func (this *rolloutRunner) checkNodes(clusterId int64, nodeIds []int64) map[int64]string {
+	var ctx = this.rpcClient.Context(this.adminId)
+	var failedMap = map[int64]string{}
+	for _, nodeId := range nodeIds {
+		nodeResp, err := this.rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+		if err != nil {
+			logs.Println("[ROLLOUT]find node failed: " + err.Error())
+			continue
+		}
+		var node = nodeResp.Node
+		if node == nil {
+			failedMap[nodeId] = "节点已被删除"
+			continue
+		}
+		var status = &nodeconfigs.NodeStatus{}
+		if len(node.StatusJSON) > 0 {
+			err = json.Unmarshal(node.StatusJSON, status)
+			if err != nil {
+				failedMap[nodeId] = "无法解析节点状态：" + err.Error()
+				continue
+			}
+		}
+		status.IsActive = status.IsActive && time.Now().Unix()-status.UpdatedAt <= 60 // N秒之内认为活跃
+		if !status.IsActive {
+			failedMap[nodeId] = "节点未在线"
+			continue
+		}
+	}
+
+	// 集群健康检查
+	healthResp, err := this.rpcClient.NodeClusterRPC().ExecuteNodeClusterHealthCheck(ctx, &pb.ExecuteNodeClusterHealthCheckRequest{NodeClusterId: clusterId})
+	if err != nil {
+		logs.Println("[ROLLOUT]execute health check failed: " + err.Error())
+		return failedMap
+	}
+	for _, result := range healthResp.Results {
+		if result.Node == nil || result.IsOk {
+			continue
+		}
+		for _, nodeId := range nodeIds {
+			_, ok := failedMap[nodeId]
+			if result.Node.Id == nodeId && !ok {
+				failedMap[nodeId] = "健康检查失败（" + result.NodeAddr + "）：" + result.Error
+			}
+		}
+	}
+	return failedMap
+}
+
+func (this *rolloutRunner) failNode(nodeId int64, reason string) {
+	_ = this.update(func(rollout *rolloututils.Rollout) error {
+		var node = rollout.FindNode(nodeId)
+		if node != nil {
+			var now = time.Now().Unix()
+			node.Status = rolloututils.NodeStatusFailed
+			node.Error = reason
+			node.FinishedAt = now
+			rollout.AddEvent(nodeId, rolloututils.LevelError, reason, now)
+		}
+		return nil
+	})
+}
+
+func (this *rolloutRunner) update(f func(rollout *rolloututils.Rollout) error) error {
+	return UpdateRollout(this.rpcClient.Context(this.adminId), this.rpcClient, this.rolloutId, f)
+}
@@ -0,0 +1,392 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clusterutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/nodes/nodeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/messageconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/logs"
+	"github.com/iwind/TeaGo/rands"
+)
+
+// SettingCodeRunbooks 运行手册在系统设置中的代号
+const SettingCodeRunbooks = "adminRunbooks"
+
+// SettingCodeRunbookRuns 运行手册执行记录在系统设置中的代号
+const SettingCodeRunbookRuns = "adminRunbookRuns"
+
+// 每个运行手册保留的执行记录数量
+const runbookRunsKeep = 20
+
+// 默认的步骤超时时间
+const runbookDefaultStepTimeout = 10
+
+var runbooksLocker = &sync.Mutex{}
+var runbookRunsLocker = &sync.Mutex{}
+
+// LoadRunbooks 读取所有运行手册
+func LoadRunbooks(ctx context.Context, rpcClient *rpc.RPCClient) (*runbookutils.Store, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeRunbooks})
+	if err != nil {
+		return nil, err
+	}
+	var store = runbookutils.NewStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateRunbooks 修改运行手册
+func UpdateRunbooks(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *runbookutils.Store) error) error {
+	runbooksLocker.Lock()
+	defer runbooksLocker.Unlock()
+
+	store, err := LoadRunbooks(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeRunbooks,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// LoadRunbookRuns 读取所有执行记录
+func LoadRunbookRuns(ctx context.Context, rpcClient *rpc.RPCClient) (*runbookutils.RunStore, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeRunbookRuns})
+	if err != nil {
+		return nil, err
+	}
+	var store = runbookutils.NewRunStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateRunbookRuns 修改执行记录
+func UpdateRunbookRuns(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *runbookutils.RunStore) error) error {
+	runbookRunsLocker.Lock()
+	defer runbookRunsLocker.Unlock()
+
+	store, err := LoadRunbookRuns(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+	store.Clean(runbookRunsKeep)
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeRunbookRuns,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// FindRunbookTargetNodes 查找运行手册的目标节点
+func FindRunbookTargetNodes(ctx context.Context, rpcClient *rpc.RPCClient, target *runbookutils.Target) ([]*runbookutils.RunNode, error) {
+	if target == nil {
+		return nil, errors.New("target should not be nil")
+	}
+	var req = &pb.ListEnabledNodesMatchRequest{
+		Offset: 0,
+		Size:   100000,
+	}
+	switch target.Type {
+	case runbookutils.TargetCluster:
+		req.NodeClusterId = target.ClusterId
+	case runbookutils.TargetGroup:
+		req.NodeClusterId = target.ClusterId
+		req.NodeGroupId = target.GroupId
+	case runbookutils.TargetRegion:
+		req.NodeClusterId = target.ClusterId // 可以为0，表示所有集群
+		req.NodeRegionId = target.RegionId
+	default:
+		return nil, errors.New("invalid target type '" + target.Type + "'")
+	}
+	nodesResp, err := rpcClient.NodeRPC().ListEnabledNodesMatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var result = []*runbookutils.RunNode{}
+	for _, node := range nodesResp.Nodes {
+		var clusterId int64
+		if node.NodeCluster != nil {
+			clusterId = node.NodeCluster.Id
+		}
+		result = append(result, &runbookutils.RunNode{
+			Id:        node.Id,
+			Name:      node.Name,
+			ClusterId: clusterId,
+		})
+	}
+	return result, nil
+}
+
+// StartRunbook 开始执行运行手册，返回执行记录ID
+func StartRunbook(rpcClient *rpc.RPCClient, adminId int64, runbookId string, trigger string) (string, error) {
+	var ctx = rpcClient.Context(adminId)
+	store, err := LoadRunbooks(ctx, rpcClient)
+	if err != nil {
+		return "", err
+	}
+	var runbook = store.Find(runbookId)
+	if runbook == nil {
+		return "", runbookutils.ErrRunbookNotFound
+	}
+
+	nodes, err := FindRunbookTargetNodes(ctx, rpcClient, runbook.Target)
+	if err != nil {
+		return "", err
+	}
+
+	var now = time.Now()
+	var run = runbookutils.NewRun(rands.HexString(16), runbook, nodes, trigger, now.Unix())
+	run.AdminId = adminId
+	err = UpdateRunbookRuns(ctx, rpcClient, func(store *runbookutils.RunStore) error {
+		store.Add(run)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = UpdateRunbooks(ctx, rpcClient, func(store *runbookutils.Store) error {
+		var runbook = store.Find(runbookId)
+		if runbook != nil {
+			runbook.LastRunAt = now.Unix()
+			runbook.ResetNextRunAt(now)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	goman.New(func() {
+		executeRunbookRun(rpcClient, run.Id)
+	})
+
+	return run.Id, nil
+}
+
+// RerunFailedRunbookSteps 重新执行失败的步骤
+func RerunFailedRunbookSteps(rpcClient *rpc.RPCClient, adminId int64, runId string) error {
+	var ctx = rpcClient.Context(adminId)
+	var count = 0
+	err := UpdateRunbookRuns(ctx, rpcClient, func(store *runbookutils.RunStore) error {
+		var run = store.Find(runId)
+		if run == nil {
+			return runbookutils.ErrRunNotFound
+		}
+		if run.Status == runbookutils.RunStatusRunning {
+			return errors.New("the run is still running")
+		}
+		count = run.ResetFailed()
+		run.AdminId = adminId
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("there is no failed step to rerun")
+	}
+
+	goman.New(func() {
+		executeRunbookRun(rpcClient, runId)
+	})
+	return nil
+}
+
+// CheckScheduledRunbooks 执行到了计划时间的运行手册
+func CheckScheduledRunbooks(rpcClient *rpc.RPCClient) error {
+	store, err := LoadRunbooks(rpcClient.Context(0), rpcClient)
+	if err != nil {
+		return err
+	}
+	for _, runbook := range store.FindDueRunbooks(time.Now().Unix()) {
+		_, err = StartRunbook(rpcClient, 0, runbook.Id, runbookutils.TriggerSchedule)
+		if err != nil {
+			logs.Println("[RUNBOOK]run '" + runbook.Name + "' failed: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// FinishInterruptedRunbookRuns 结束因为管理平台重启而中断的执行
+func FinishInterruptedRunbookRuns(rpcClient *rpc.RPCClient) error {
+	return UpdateRunbookRuns(rpcClient.Context(0), rpcClient, func(store *runbookutils.RunStore) error {
+		var now = time.Now().Unix()
+		for _, run := range store.Runs {
+			if run.Status != runbookutils.RunStatusRunning {
+				continue
+			}
+			for _, cell := range run.Cells {
+				if cell.Status == runbookutils.CellPending {
+					cell.Status = runbookutils.CellFailed
+					cell.Message = "执行被中断"
+					cell.UpdatedAt = now
+				}
+			}
+			run.Finish(now)
+		}
+		return nil
+	})
+}
+
+// 按步骤顺序执行
+func executeRunbookRun(rpcClient *rpc.RPCClient, runId string) {
+	var adminId int64
+	var steps []*runbookutils.Step
+	{
+		store, err := LoadRunbookRuns(rpcClient.Context(0), rpcClient)
+		if err != nil {
+			logs.Println("[RUNBOOK]" + err.Error())
+			return
+		}
+		var run = store.Find(runId)
+		if run == nil {
+			return
+		}
+		adminId = run.AdminId
+		steps = run.Steps
+	}
+
+	for stepIndex, step := range steps {
+		// 每个步骤都重新生成上下文，避免长时间执行时上下文过期
+		var ctx = rpcClient.Context(adminId)
+
+		var nodeIds []int64
+		err := UpdateRunbookRuns(ctx, rpcClient, func(store *runbookutils.RunStore) error {
+			var run = store.Find(runId)
+			if run == nil {
+				return runbookutils.ErrRunNotFound
+			}
+			nodeIds = run.PrepareStep(stepIndex, time.Now().Unix())
+			return nil
+		})
+		if err != nil {
+			logs.Println("[RUNBOOK]" + err.Error())
+			return
+		}
+		if len(nodeIds) == 0 {
+			continue
+		}
+
+		var resultMap = map[int64]*nodeutils.MessageResult{}
+		code, msg, sendErr := buildRunbookMessage(ctx, rpcClient, step)
+		if sendErr == nil {
+			var timeout = step.TimeoutSeconds
+			if timeout <= 0 {
+				timeout = runbookDefaultStepTimeout
+			}
+			var results []*nodeutils.MessageResult
+			results, sendErr = nodeutils.SendMessageToNodeIds(ctx, nodeIds, code, msg, timeout)
+			for _, result := range results {
+				resultMap[result.NodeId] = result
+			}
+		}
+
+		err = UpdateRunbookRuns(ctx, rpcClient, func(store *runbookutils.RunStore) error {
+			var run = store.Find(runId)
+			if run == nil {
+				return runbookutils.ErrRunNotFound
+			}
+			var now = time.Now().Unix()
+			for _, nodeId := range nodeIds {
+				if sendErr != nil {
+					run.SetResult(stepIndex, nodeId, false, sendErr.Error(), now)
+					continue
+				}
+				result, ok := resultMap[nodeId]
+				if !ok {
+					run.SetResult(stepIndex, nodeId, false, "节点没有返回结果", now)
+					continue
+				}
+				run.SetResult(stepIndex, nodeId, result.IsOK, result.Message, now)
+			}
+			return nil
+		})
+		if err != nil {
+			logs.Println("[RUNBOOK]" + err.Error())
+			return
+		}
+	}
+
+	err := UpdateRunbookRuns(rpcClient.Context(adminId), rpcClient, func(store *runbookutils.RunStore) error {
+		var run = store.Find(runId)
+		if run != nil {
+			run.Finish(time.Now().Unix())
+		}
+		return nil
+	})
+	if err != nil {
+		logs.Println("[RUNBOOK]" + err.Error())
+	}
+}
+
+// 根据步骤构造发送给节点的消息
+func buildRunbookMessage(ctx context.Context, rpcClient *rpc.RPCClient, step *runbookutils.Step) (code string, msg any, err error) {
+	switch step.Code {
+	case runbookutils.StepCheckSystemdService:
+		return messageconfigs.MessageCodeCheckSystemdService, &messageconfigs.CheckSystemdServiceMessage{}, nil
+	case runbookutils.StepCheckLocalFirewall:
+		return messageconfigs.MessageCodeCheckLocalFirewall, &messageconfigs.CheckLocalFirewallMessage{
+			Name: step.FirewallName,
+		}, nil
+	case runbookutils.StepNewNodeTask:
+		return messageconfigs.MessageCodeNewNodeTask, &messageconfigs.NewNodeTaskMessage{}, nil
+	case runbookutils.StepCleanCache, runbookutils.StepStatCache:
+		cachePolicyResp, err := rpcClient.HTTPCachePolicyRPC().FindEnabledHTTPCachePolicyConfig(ctx, &pb.FindEnabledHTTPCachePolicyConfigRequest{HttpCachePolicyId: step.CachePolicyId})
+		if err != nil {
+			return "", nil, err
+		}
+		if len(cachePolicyResp.HttpCachePolicyJSON) == 0 {
+			return "", nil, errors.New("找不到缓存策略")
+		}
+		if step.Code == runbookutils.StepCleanCache {
+			return messageconfigs.MessageCodeCleanCache, &messageconfigs.CleanCacheMessage{
+				CachePolicyJSON: cachePolicyResp.HttpCachePolicyJSON,
+			}, nil
+		}
+		return messageconfigs.MessageCodeStatCache, &messageconfigs.StatCacheMessage{
+			CachePolicyJSON: cachePolicyResp.HttpCachePolicyJSON,
+		}, nil
+	}
+	return "", nil, errors.New("unsupported step '" + step.Code + "'")
+}
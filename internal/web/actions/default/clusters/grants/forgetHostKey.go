@@ -0,0 +1,40 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grants
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+)
+
+// ForgetHostKeyAction 删除记录的节点主机公钥，下次连接时重新记录
+type ForgetHostKeyAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ForgetHostKeyAction) RunPost(params struct {
+	Host string
+	Port int32
+
+	CSRF *actionutils.CSRF
+}) {
+	var addr = net.JoinHostPort(params.Host, strconv.Itoa(int(params.Port)))
+	defer this.CreateLogInfo("删除节点 %s 记录的SSH主机公钥", addr)
+
+	knownHosts, err := grantutils.LoadKnownHosts(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	knownHosts.Forget(addr)
+	err = grantutils.SaveKnownHosts(this.AdminContext(), this.RPC(), knownHosts)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -51,6 +51,7 @@ func (this *GrantAction) RunGet(params struct {
 		"passphrase":  strings.Repeat("*", len(grant.Passphrase)),
 		"description": grant.Description,
 		"su":          grant.Su,
+		"isSealed":    grantutils.IsGrantSealed(grant),
 	}
 
 	// 使用此认证的集群
@@ -0,0 +1,47 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grantutils
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// SettingCodeGrantKnownHosts 管理平台直接登录节点时记录的主机公钥在系统设置中的代号
+const SettingCodeGrantKnownHosts = "adminGrantKnownHosts"
+
+// LoadKnownHosts 读取记录的主机公钥
+func LoadKnownHosts(ctx context.Context, rpcClient *rpc.RPCClient) (*vaultutils.KnownHosts, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeGrantKnownHosts})
+	if err != nil {
+		return nil, err
+	}
+	var knownHosts = vaultutils.NewKnownHosts()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, knownHosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return knownHosts, nil
+}
+
+// SaveKnownHosts 保存记录的主机公钥，没有变化时不保存
+func SaveKnownHosts(ctx context.Context, rpcClient *rpc.RPCClient, knownHosts *vaultutils.KnownHosts) error {
+	if !knownHosts.IsChanged() {
+		return nil
+	}
+	knownHostsJSON, err := json.Marshal(knownHosts)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeGrantKnownHosts,
+		ValueJSON: knownHostsJSON,
+	})
+	return err
+}
@@ -0,0 +1,263 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grantutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// RotateNodeResult 单个节点的轮换结果
+type RotateNodeResult struct {
+	Target       *NodeTarget `json:"target"`
+	IsPushed     bool        `json:"isPushed"`     // 是否已写入新公钥
+	IsTested     bool        `json:"isTested"`     // 是否已通过新密钥登录测试
+	IsOldRetired bool        `json:"isOldRetired"` // 旧公钥是否已删除
+	Error        string      `json:"error"`
+
+	IsHostKeyChanged bool `json:"isHostKeyChanged"` // 主机公钥是否和记录的不一致
+}
+
+// RotateResult 轮换结果
+type RotateResult struct {
+	IsOk          bool                `json:"isOk"`
+	Version       int                 `json:"version"`
+	AuthorizedKey string              `json:"authorizedKey"`
+	Message       string              `json:"message"`
+	Nodes         []*RotateNodeResult `json:"nodes"`
+}
+
+// RotateGrant 轮换认证的密钥
+// 1. 生成新的密钥对，使用当前认证登录所有节点并写入新公钥
+// 2. 在管理平台中直接使用新密钥登录所有节点测试
+// 3. 全部成功后先将旧认证加密存入轮换记录，再修改认证；任何一步失败都从节点上删除新公钥，认证保持不变
+// 4. 从所有节点上删除旧公钥
+func RotateGrant(ctx context.Context, rpcClient *rpc.RPCClient, grantId int64) (*RotateResult, error) {
+	secret, err := FindGrantSecret(ctx, rpcClient, grantId)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := FindGrantNodeTargets(ctx, rpcClient, grantId)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("没有设置了SSH地址的节点使用此认证")
+	}
+
+	knownHosts, err := LoadKnownHosts(ctx, rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = SaveKnownHosts(ctx, rpcClient, knownHosts)
+	}()
+
+	history, err := LoadGrantHistory(ctx, rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	var version = 1
+	var grantHistory = history.Find(grantId)
+	if grantHistory != nil {
+		version = grantHistory.Version
+	}
+
+	privateKey, authorizedKey, err := vaultutils.GenerateSSHKeyPair("goedge-grant-" + strconv.FormatInt(grantId, 10) + "-v" + strconv.Itoa(version+1))
+	if err != nil {
+		return nil, err
+	}
+	addCommand, err := vaultutils.AddAuthorizedKeyCommand(authorizedKey)
+	if err != nil {
+		return nil, err
+	}
+	removeNewCommand, err := vaultutils.RemoveAuthorizedKeyCommand(authorizedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var newSecret = &GrantSecret{
+		Id:          secret.Id,
+		Name:        secret.Name,
+		Method:      "privateKey",
+		Username:    secret.Username,
+		PrivateKey:  privateKey,
+		Description: secret.Description,
+		Su:          secret.Su,
+	}
+
+	var result = &RotateResult{
+		AuthorizedKey: authorizedKey,
+		Nodes:         []*RotateNodeResult{},
+	}
+
+	// 写入新公钥并测试
+	var hasErrors = false
+	for _, target := range targets {
+		var nodeResult = &RotateNodeResult{Target: target}
+		result.Nodes = append(result.Nodes, nodeResult)
+
+		_, err = RunSSHCommand(knownHosts, secret, target.Host, target.Port, addCommand)
+		if err != nil {
+			nodeResult.Error = "写入新公钥失败：" + err.Error()
+			nodeResult.IsHostKeyChanged = isHostKeyMismatch(err)
+			hasErrors = true
+			continue
+		}
+		nodeResult.IsPushed = true
+
+		err = TestGrantSecret(knownHosts, newSecret, target.Host, target.Port)
+		if err != nil {
+			nodeResult.Error = "使用新密钥登录失败：" + err.Error()
+			hasErrors = true
+			continue
+		}
+		nodeResult.IsTested = true
+	}
+
+	// 从节点上删除已写入的新公钥
+	var rollback = func(message string) *RotateResult {
+		for _, nodeResult := range result.Nodes {
+			if !nodeResult.IsPushed {
+				continue
+			}
+			_, err := RunSSHCommand(knownHosts, secret, nodeResult.Target.Host, nodeResult.Target.Port, removeNewCommand)
+			if err != nil {
+				nodeResult.Error += "；删除新公钥失败：" + err.Error()
+			} else {
+				nodeResult.IsPushed = false
+			}
+		}
+		result.Message = message
+		return result
+	}
+
+	if hasErrors {
+		return rollback("部分节点轮换失败，认证保持不变"), nil
+	}
+
+	// 先记录旧认证，再修改认证，避免修改认证后旧认证丢失
+	oldHistoryJSON, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+	var rotatedAt = time.Now().Unix()
+	result.Version, err = RetireGrantSecret(history, secret, rotatedAt)
+	if err != nil {
+		return rollback("加密旧认证失败，认证保持不变：" + err.Error()), nil
+	}
+	err = SaveGrantHistory(ctx, rpcClient, history)
+	if err != nil {
+		return rollback("保存轮换记录失败，认证保持不变：" + err.Error()), nil
+	}
+	err = SaveGrantSecret(ctx, rpcClient, newSecret, secret.IsSealed)
+	if err != nil {
+		// 恢复轮换记录
+		_, _ = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+			Code:      SettingCodeGrantVault,
+			ValueJSON: oldHistoryJSON,
+		})
+		result.Version = 0
+		return rollback("修改认证失败，认证保持不变：" + err.Error()), nil
+	}
+	result.IsOk = true
+
+	// 删除旧公钥
+	if secret.Method != "privateKey" {
+		result.Message = "旧认证为密码方式，无法自动停用，请在节点上修改或禁用密码登录"
+		return result, nil
+	}
+	oldAuthorizedKey, err := vaultutils.AuthorizedKeyFromPrivateKey(secret.PrivateKey, secret.Passphrase)
+	if err != nil {
+		result.Message = "无法解析旧私钥，请手动删除节点上的旧公钥：" + err.Error()
+		return result, nil
+	}
+	removeOldCommand, err := vaultutils.RemoveAuthorizedKeyCommand(oldAuthorizedKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeResult := range result.Nodes {
+		_, err = RunSSHCommand(knownHosts, newSecret, nodeResult.Target.Host, nodeResult.Target.Port, removeOldCommand)
+		if err != nil {
+			nodeResult.Error = "删除旧公钥失败：" + err.Error()
+			continue
+		}
+		nodeResult.IsOldRetired = true
+	}
+	return result, nil
+}
+
+// OldCredentialResult 节点是否仍然接受旧认证
+type OldCredentialResult struct {
+	Target     *NodeTarget `json:"target"`
+	Version    int         `json:"version"`
+	IsAccepted bool        `json:"isAccepted"`
+	IsChecked  bool        `json:"isChecked"` // 是否完成检查，连接失败或主机公钥不一致时无法判断
+	TestError  string      `json:"testError"`
+	RetiredAt  int64       `json:"retiredAt"`
+}
+
+// CheckOldCredentials 检查节点是否仍然接受最近一次退役的认证
+func CheckOldCredentials(ctx context.Context, rpcClient *rpc.RPCClient, grantId int64) ([]*OldCredentialResult, error) {
+	history, err := LoadGrantHistory(ctx, rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	var grantHistory = history.Find(grantId)
+	var results = []*OldCredentialResult{}
+	if grantHistory == nil || grantHistory.Latest() == nil {
+		return results, nil
+	}
+	var credential = grantHistory.Latest()
+
+	secret, err := FindGrantSecret(ctx, rpcClient, grantId)
+	if err != nil {
+		return nil, err
+	}
+	oldSecret, err := OpenRetiredCredential(secret, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := FindGrantNodeTargets(ctx, rpcClient, grantId)
+	if err != nil {
+		return nil, err
+	}
+
+	knownHosts, err := LoadKnownHosts(ctx, rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = SaveKnownHosts(ctx, rpcClient, knownHosts)
+	}()
+
+	for _, target := range targets {
+		var testError = ""
+		err = TestGrantSecret(knownHosts, oldSecret, target.Host, target.Port)
+		if err != nil {
+			testError = err.Error()
+		}
+		results = append(results, &OldCredentialResult{
+			Target:     target,
+			Version:    credential.Version,
+			IsAccepted: err == nil,
+			IsChecked:  err == nil || IsSSHAuthError(err),
+			TestError:  testError,
+			RetiredAt:  credential.RetiredAt,
+		})
+	}
+	return results, nil
+}
+
+func isHostKeyMismatch(err error) bool {
+	var mismatchErr *vaultutils.HostKeyMismatchError
+	return errors.As(err, &mismatchErr)
+}
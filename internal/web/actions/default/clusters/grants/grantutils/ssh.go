@@ -0,0 +1,101 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grantutils
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"golang.org/x/crypto/ssh"
+)
+
+// RunSSHCommand 使用认证信息登录节点并以登录用户身份执行命令
+func RunSSHCommand(knownHosts *vaultutils.KnownHosts, secret *GrantSecret, host string, port int32, command string) (string, error) {
+	client, err := dialSSH(knownHosts, secret, host, port)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	var stdout = &bytes.Buffer{}
+	var stderr = &bytes.Buffer{}
+	session.Stdout = stdout
+	session.Stderr = stderr
+	err = session.Run(command)
+	if err != nil {
+		if stderr.Len() > 0 {
+			return stdout.String(), errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
+
+// TestGrantSecret 在管理平台中直接通过SSH测试认证信息能否登录节点
+// 用来测试新的或已退役的认证信息，认证信息不会发送到API节点
+func TestGrantSecret(knownHosts *vaultutils.KnownHosts, secret *GrantSecret, host string, port int32) error {
+	client, err := dialSSH(knownHosts, secret, host, port)
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
+// IsSSHAuthError 判断是否为认证失败，而不是连接失败或主机公钥不一致
+func IsSSHAuthError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unable to authenticate")
+}
+
+func dialSSH(knownHosts *vaultutils.KnownHosts, secret *GrantSecret, host string, port int32) (*ssh.Client, error) {
+	if knownHosts == nil {
+		return nil, errors.New("'knownHosts' should not be nil")
+	}
+
+	var authMethods = []ssh.AuthMethod{}
+	switch secret.Method {
+	case "user":
+		authMethods = append(authMethods, ssh.Password(secret.Password), ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) (answers []string, err error) {
+			answers = make([]string, len(questions))
+			for i := range questions {
+				answers[i] = secret.Password
+			}
+			return answers, nil
+		}))
+	case "privateKey":
+		var signer ssh.Signer
+		var err error
+		if len(secret.Passphrase) > 0 {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(secret.PrivateKey), []byte(secret.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(secret.PrivateKey))
+		}
+		if err != nil {
+			return nil, errors.New("parse private key failed: " + err.Error())
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	default:
+		return nil, errors.New("invalid grant method '" + secret.Method + "'")
+	}
+
+	return ssh.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))), &ssh.ClientConfig{
+		User:            secret.Username,
+		Auth:            authMethods,
+		HostKeyCallback: knownHosts.HostKeyCallback(),
+		Timeout:         10 * time.Second,
+	})
+}
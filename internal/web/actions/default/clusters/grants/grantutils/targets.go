@@ -0,0 +1,70 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grantutils
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// NodeTarget 使用某个认证登录的节点
+type NodeTarget struct {
+	NodeId      int64  `json:"nodeId"`
+	NodeName    string `json:"nodeName"`
+	ClusterId   int64  `json:"clusterId"`
+	ClusterName string `json:"clusterName"`
+	Host        string `json:"host"`
+	Port        int32  `json:"port"`
+}
+
+// FindGrantNodeTargets 查找使用某个认证的所有节点及其SSH地址
+// 没有设置SSH地址的节点会被忽略
+func FindGrantNodeTargets(ctx context.Context, rpcClient *rpc.RPCClient, grantId int64) ([]*NodeTarget, error) {
+	nodesResp, err := rpcClient.NodeRPC().FindAllEnabledNodesWithNodeGrantId(ctx, &pb.FindAllEnabledNodesWithNodeGrantIdRequest{NodeGrantId: grantId})
+	if err != nil {
+		return nil, err
+	}
+
+	var targets = []*NodeTarget{}
+	for _, node := range nodesResp.Nodes {
+		nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: node.Id})
+		if err != nil {
+			return nil, err
+		}
+		var fullNode = nodeResp.Node
+		if fullNode == nil || fullNode.NodeLogin == nil || len(fullNode.NodeLogin.Params) == 0 {
+			continue
+		}
+
+		var loginParams = maps.Map{}
+		err = json.Unmarshal(fullNode.NodeLogin.Params, &loginParams)
+		if err != nil {
+			return nil, err
+		}
+		var host = loginParams.GetString("host")
+		if len(host) == 0 {
+			continue
+		}
+		var port = loginParams.GetInt32("port")
+		if port <= 0 {
+			port = 22
+		}
+
+		var target = &NodeTarget{
+			NodeId:   node.Id,
+			NodeName: node.Name,
+			Host:     host,
+			Port:     port,
+		}
+		if node.NodeCluster != nil {
+			target.ClusterId = node.NodeCluster.Id
+			target.ClusterName = node.NodeCluster.Name
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
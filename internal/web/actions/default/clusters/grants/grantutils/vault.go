@@ -0,0 +1,250 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grantutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// SettingCodeGrantVault 认证轮换记录在系统设置中的代号，其中的认证信息均已加密
+const SettingCodeGrantVault = "adminGrantVault"
+
+// 每个认证最多保留的退役版本
+const maxRetiredCredentials = 5
+
+// GrantSecret 解密后的认证信息
+type GrantSecret struct {
+	Id          int64
+	Name        string
+	Method      string
+	Username    string
+	Password    string
+	PrivateKey  string
+	Passphrase  string
+	Description string
+	Su          bool
+
+	IsSealed bool // 在API节点中是否以加密方式保存
+}
+
+// FindGrantSecret 读取认证信息，如果已加密则自动解密
+func FindGrantSecret(ctx context.Context, rpcClient *rpc.RPCClient, grantId int64) (*GrantSecret, error) {
+	grantResp, err := rpcClient.NodeGrantRPC().FindEnabledNodeGrant(ctx, &pb.FindEnabledNodeGrantRequest{NodeGrantId: grantId})
+	if err != nil {
+		return nil, err
+	}
+	var grant = grantResp.NodeGrant
+	if grant == nil {
+		return nil, errors.New("can not find grant with id '" + strconv.FormatInt(grantId, 10) + "'")
+	}
+
+	var secret = &GrantSecret{
+		Id:          grant.Id,
+		Name:        grant.Name,
+		Method:      grant.Method,
+		Username:    grant.Username,
+		Description: grant.Description,
+		Su:          grant.Su,
+		IsSealed:    IsGrantSealed(grant),
+	}
+	if !secret.IsSealed {
+		secret.Password = grant.Password
+		secret.PrivateKey = grant.PrivateKey
+		secret.Passphrase = grant.Passphrase
+		return secret, nil
+	}
+
+	vault, err := vaultutils.SharedVault()
+	if err != nil {
+		return nil, err
+	}
+	secret.Password, err = vault.Open(grant.Password)
+	if err != nil {
+		return nil, err
+	}
+	secret.PrivateKey, err = vault.Open(grant.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	secret.Passphrase, err = vault.Open(grant.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// CheckNodeGrantUsable 检查API节点能否使用节点的SSH认证
+// 已加密的认证只能在管理平台中解密，API节点无法用来安装、升级、启停节点
+func CheckNodeGrantUsable(ctx context.Context, rpcClient *rpc.RPCClient, nodeId int64) error {
+	nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+	if err != nil {
+		return err
+	}
+	var node = nodeResp.Node
+	if node == nil || node.NodeLogin == nil || len(node.NodeLogin.Params) == 0 {
+		return nil
+	}
+	var loginParams = maps.Map{}
+	err = json.Unmarshal(node.NodeLogin.Params, &loginParams)
+	if err != nil {
+		return err
+	}
+	var grantId = loginParams.GetInt64("grantId")
+	if grantId <= 0 {
+		return nil
+	}
+	grantResp, err := rpcClient.NodeGrantRPC().FindEnabledNodeGrant(ctx, &pb.FindEnabledNodeGrantRequest{NodeGrantId: grantId})
+	if err != nil {
+		return err
+	}
+	if grantResp.NodeGrant != nil && IsGrantSealed(grantResp.NodeGrant) {
+		return errors.New("节点使用的SSH认证\"" + grantResp.NodeGrant.Name + "\"已加密保存，API节点无法使用，请先在认证详情中解密后再操作")
+	}
+	return nil
+}
+
+// IsGrantSealed 判断认证信息是否已加密
+func IsGrantSealed(grant *pb.NodeGrant) bool {
+	return vaultutils.IsSealed(grant.Password) || vaultutils.IsSealed(grant.PrivateKey) || vaultutils.IsSealed(grant.Passphrase)
+}
+
+// SaveGrantSecret 保存认证信息
+// 如果 seal 为true，则使用主密钥加密后再保存
+func SaveGrantSecret(ctx context.Context, rpcClient *rpc.RPCClient, secret *GrantSecret, seal bool) error {
+	var password = secret.Password
+	var privateKey = secret.PrivateKey
+	var passphrase = secret.Passphrase
+	if seal {
+		vault, err := vaultutils.SharedVault()
+		if err != nil {
+			return err
+		}
+		password, err = vault.Seal(password)
+		if err != nil {
+			return err
+		}
+		privateKey, err = vault.Seal(privateKey)
+		if err != nil {
+			return err
+		}
+		passphrase, err = vault.Seal(passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := rpcClient.NodeGrantRPC().UpdateNodeGrant(ctx, &pb.UpdateNodeGrantRequest{
+		NodeGrantId: secret.Id,
+		Name:        secret.Name,
+		Method:      secret.Method,
+		Username:    secret.Username,
+		Password:    password,
+		PrivateKey:  privateKey,
+		Passphrase:  passphrase,
+		Description: secret.Description,
+		Su:          secret.Su,
+		NodeId:      0,
+	})
+	if err != nil {
+		return err
+	}
+	secret.IsSealed = seal
+	return nil
+}
+
+// LoadGrantHistory 读取认证轮换记录
+func LoadGrantHistory(ctx context.Context, rpcClient *rpc.RPCClient) (*vaultutils.History, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeGrantVault})
+	if err != nil {
+		return nil, err
+	}
+	var history = vaultutils.NewHistory()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, history)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// SaveGrantHistory 保存认证轮换记录
+func SaveGrantHistory(ctx context.Context, rpcClient *rpc.RPCClient, history *vaultutils.History) error {
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeGrantVault,
+		ValueJSON: historyJSON,
+	})
+	return err
+}
+
+// RetireGrantSecret 加密并记录退役的认证信息
+func RetireGrantSecret(history *vaultutils.History, secret *GrantSecret, rotatedAt int64) (int, error) {
+	vault, err := vaultutils.SharedVault()
+	if err != nil {
+		return 0, err
+	}
+
+	var credential = &vaultutils.RetiredCredential{
+		Method:   secret.Method,
+		Username: secret.Username,
+	}
+	credential.Password, err = vault.Seal(secret.Password)
+	if err != nil {
+		return 0, err
+	}
+	credential.PrivateKey, err = vault.Seal(secret.PrivateKey)
+	if err != nil {
+		return 0, err
+	}
+	credential.Passphrase, err = vault.Seal(secret.Passphrase)
+	if err != nil {
+		return 0, err
+	}
+	if len(secret.PrivateKey) > 0 {
+		authorizedKey, err := vaultutils.AuthorizedKeyFromPrivateKey(secret.PrivateKey, secret.Passphrase)
+		if err == nil {
+			credential.AuthorizedKey = authorizedKey
+		}
+	}
+	return history.Retire(secret.Id, credential, rotatedAt, maxRetiredCredentials), nil
+}
+
+// OpenRetiredCredential 解密退役的认证信息
+func OpenRetiredCredential(grant *GrantSecret, credential *vaultutils.RetiredCredential) (*GrantSecret, error) {
+	vault, err := vaultutils.SharedVault()
+	if err != nil {
+		return nil, err
+	}
+	var secret = &GrantSecret{
+		Id:       grant.Id,
+		Name:     grant.Name,
+		Method:   credential.Method,
+		Username: credential.Username,
+		Su:       grant.Su,
+	}
+	secret.Password, err = vault.Open(credential.Password)
+	if err != nil {
+		return nil, err
+	}
+	secret.PrivateKey, err = vault.Open(credential.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	secret.Passphrase, err = vault.Open(credential.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -26,6 +26,13 @@ func init() {
 			GetPost("/createPopup", new(CreatePopupAction)).
 			GetPost("/updatePopup", new(UpdatePopupAction)).
 			GetPost("/test", new(TestAction)).
+
+			// 加密和轮换
+			Post("/seal", new(SealAction)).
+			Post("/unseal", new(UnsealAction)).
+			GetPost("/rotate", new(RotateAction)).
+			Post("/oldCredentials", new(OldCredentialsAction)).
+			Post("/forgetHostKey", new(ForgetHostKeyAction)).
 			EndAll()
 	})
 }
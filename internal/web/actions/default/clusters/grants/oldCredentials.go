@@ -0,0 +1,34 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grants
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+)
+
+// OldCredentialsAction 检查哪些节点仍然接受已退役的认证
+type OldCredentialsAction struct {
+	actionutils.ParentAction
+}
+
+func (this *OldCredentialsAction) RunPost(params struct {
+	GrantId int64
+}) {
+	results, err := grantutils.CheckOldCredentials(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var countAccepted = 0
+	for _, result := range results {
+		if result.IsAccepted {
+			countAccepted++
+		}
+	}
+	this.Data["results"] = results
+	this.Data["countAccepted"] = countAccepted
+
+	this.Success()
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grants
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/vaultutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// RotateAction 轮换认证密钥
+type RotateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RotateAction) Init() {
+	this.Nav("", "", "rotate")
+}
+
+func (this *RotateAction) RunGet(params struct {
+	GrantId int64
+}) {
+	secret, err := grantutils.FindGrantSecret(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["grant"] = maps.Map{
+		"id":         secret.Id,
+		"name":       secret.Name,
+		"method":     secret.Method,
+		"methodName": grantutils.FindGrantMethodName(secret.Method, this.LangCode()),
+		"username":   secret.Username,
+		"isSealed":   secret.IsSealed,
+	}
+
+	targets, err := grantutils.FindGrantNodeTargets(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["nodes"] = targets
+
+	// 轮换记录
+	history, err := grantutils.LoadGrantHistory(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var retiredMaps = []maps.Map{}
+	var version = 1
+	var rotatedTime = ""
+	var grantHistory = history.Find(params.GrantId)
+	if grantHistory != nil {
+		version = grantHistory.Version
+		rotatedTime = timeutil.FormatTime("Y-m-d H:i:s", grantHistory.RotatedAt)
+		for i := len(grantHistory.Retired) - 1; i >= 0; i-- {
+			var credential = grantHistory.Retired[i]
+			retiredMaps = append(retiredMaps, maps.Map{
+				"version":       credential.Version,
+				"methodName":    grantutils.FindGrantMethodName(credential.Method, this.LangCode()),
+				"username":      credential.Username,
+				"authorizedKey": credential.AuthorizedKey,
+				"retiredTime":   timeutil.FormatTime("Y-m-d H:i:s", credential.RetiredAt),
+			})
+		}
+	}
+	this.Data["version"] = version
+	this.Data["rotatedTime"] = rotatedTime
+	this.Data["retiredCredentials"] = retiredMaps
+	this.Data["masterKeyFile"] = vaultutils.MasterKeyFileName
+
+	this.Show()
+}
+
+func (this *RotateAction) RunPost(params struct {
+	GrantId int64
+
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("轮换SSH认证 %d 的密钥", params.GrantId)
+
+	result, err := grantutils.RotateGrant(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.Fail("轮换失败：" + err.Error())
+		return
+	}
+	this.Data["result"] = result
+
+	this.Success()
+}
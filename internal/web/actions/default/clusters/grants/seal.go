@@ -0,0 +1,37 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grants
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+)
+
+// SealAction 使用主密钥加密认证信息
+type SealAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SealAction) RunPost(params struct {
+	GrantId int64
+}) {
+	defer this.CreateLogInfo("加密SSH认证 %d", params.GrantId)
+
+	secret, err := grantutils.FindGrantSecret(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if secret.IsSealed {
+		this.Success()
+		return
+	}
+
+	err = grantutils.SaveGrantSecret(this.AdminContext(), this.RPC(), secret, true)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -64,6 +64,30 @@ func (this *TestAction) RunPost(params struct {
 		Gt(0, "请输入正确的端口号").
 		Lt(65535, "请输入正确的端口号")
 
+	// 已加密的认证API节点无法使用，在管理平台中直接测试
+	secret, err := grantutils.FindGrantSecret(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if secret.IsSealed {
+		knownHosts, err := grantutils.LoadKnownHosts(this.AdminContext(), this.RPC())
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		err = grantutils.TestGrantSecret(knownHosts, secret, params.Host, params.Port)
+		_ = grantutils.SaveKnownHosts(this.AdminContext(), this.RPC(), knownHosts)
+		this.Data["isOk"] = err == nil
+		if err != nil {
+			this.Data["error"] = err.Error()
+		} else {
+			this.Data["error"] = ""
+		}
+		this.Success()
+		return
+	}
+
 	resp, err := this.RPC().NodeGrantRPC().TestNodeGrant(this.AdminContext(), &pb.TestNodeGrantRequest{
 		NodeGrantId: params.GrantId,
 		Host:        params.Host,
@@ -0,0 +1,37 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package grants
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants/grantutils"
+)
+
+// UnsealAction 解密认证信息，以便API节点可以使用此认证远程安装或升级节点
+type UnsealAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UnsealAction) RunPost(params struct {
+	GrantId int64
+}) {
+	defer this.CreateLogInfo("解密SSH认证 %d", params.GrantId)
+
+	secret, err := grantutils.FindGrantSecret(this.AdminContext(), this.RPC(), params.GrantId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if !secret.IsSealed {
+		this.Success()
+		return
+	}
+
+	err = grantutils.SaveGrantSecret(this.AdminContext(), this.RPC(), secret, false)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
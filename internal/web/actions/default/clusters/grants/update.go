@@ -57,6 +57,7 @@ func (this *UpdateAction) RunGet(params struct {
 		"passphrase":  grant.Passphrase,
 		"description": grant.Description,
 		"su":          grant.Su,
+		"isSealed":    grantutils.IsGrantSealed(grant),
 	}
 
 	this.Show()
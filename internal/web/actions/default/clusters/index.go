@@ -1,22 +1,31 @@
 package clusters
 
 import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/configutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/nodeconfigs"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/maps"
 	"github.com/iwind/TeaGo/types"
+	"strings"
 )
 
 type IndexAction struct {
 	actionutils.ParentAction
+
+	scope *rbacutils.Scope
 }
 
 func (this *IndexAction) Init() {
 	this.Nav("", "cluster", "index")
 }
 
+// RBACFilterScope 有资源范围限制的角色只能看到范围内的集群
+func (this *IndexAction) RBACFilterScope(scope *rbacutils.Scope) {
+	this.scope = scope
+}
+
 func (this *IndexAction) RunGet(params struct {
 	Keyword    string
 	SearchType string
@@ -26,6 +35,11 @@ func (this *IndexAction) RunGet(params struct {
 	this.Data["searchType"] = params.SearchType
 	this.Data["isSearching"] = isSearching
 
+	if !this.scope.IsEmpty() {
+		this.showScopedClusters(params.Keyword)
+		return
+	}
+
 	// 集群总数
 	totalClustersResp, err := this.RPC().NodeClusterRPC().CountAllEnabledNodeClusters(this.AdminContext(), &pb.CountAllEnabledNodeClustersRequest{})
 	if err != nil {
@@ -85,67 +99,12 @@ func (this *IndexAction) RunGet(params struct {
 			return
 		}
 		for _, cluster := range clustersResp.NodeClusters {
-			// 全部节点数量
-			countNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{NodeClusterId: cluster.Id})
-			if err != nil {
-				this.ErrorPage(err)
-				return
-			}
-
-			// 在线节点
-			countActiveNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{
-				NodeClusterId: cluster.Id,
-				ActiveState:   types.Int32(configutils.BoolStateYes),
-			})
+			clusterMap, err := this.clusterMap(cluster)
 			if err != nil {
 				this.ErrorPage(err)
 				return
 			}
-
-			// 需要升级的节点
-			countUpgradeNodesResp, err := this.RPC().NodeRPC().CountAllUpgradeNodesWithNodeClusterId(this.AdminContext(), &pb.CountAllUpgradeNodesWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
-			if err != nil {
-				this.ErrorPage(err)
-				return
-			}
-
-			// DNS
-			dnsDomainName := ""
-			if cluster.DnsDomainId > 0 {
-				dnsInfoResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeClusterDNS(this.AdminContext(), &pb.FindEnabledNodeClusterDNSRequest{NodeClusterId: cluster.Id})
-				if err != nil {
-					this.ErrorPage(err)
-					return
-				}
-				if dnsInfoResp.Domain != nil {
-					dnsDomainName = dnsInfoResp.Domain.Name
-				}
-			}
-
-			// 服务数
-			countServersResp, err := this.RPC().ServerRPC().CountAllEnabledServersWithNodeClusterId(this.AdminContext(), &pb.CountAllEnabledServersWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
-			if err != nil {
-				this.ErrorPage(err)
-			}
-
-			if cluster.TimeZone == nodeconfigs.DefaultTimeZoneLocation {
-				cluster.TimeZone = ""
-			}
-
-			clusterMaps = append(clusterMaps, maps.Map{
-				"id":                cluster.Id,
-				"name":              cluster.Name,
-				"installDir":        cluster.InstallDir,
-				"countAllNodes":     countNodesResp.Count,
-				"countActiveNodes":  countActiveNodesResp.Count,
-				"countUpgradeNodes": countUpgradeNodesResp.Count,
-				"dnsDomainId":       cluster.DnsDomainId,
-				"dnsName":           cluster.DnsName,
-				"dnsDomainName":     dnsDomainName,
-				"countServers":      countServersResp.Count,
-				"timeZone":          cluster.TimeZone,
-				"isPinned":          cluster.IsPinned,
-			})
+			clusterMaps = append(clusterMaps, clusterMap)
 		}
 	}
 	this.Data["clusters"] = clusterMaps
@@ -165,3 +124,106 @@ func (this *IndexAction) RunGet(params struct {
 
 	this.Show()
 }
+
+// 只显示资源范围内的集群
+func (this *IndexAction) showScopedClusters(keyword string) {
+	var clusterMaps = []maps.Map{}
+	for _, clusterId := range this.scope.ClusterIds {
+		clusterResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeCluster(this.AdminContext(), &pb.FindEnabledNodeClusterRequest{NodeClusterId: clusterId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		var cluster = clusterResp.NodeCluster
+		if cluster == nil {
+			continue
+		}
+		if len(keyword) > 0 && !strings.Contains(strings.ToLower(cluster.Name), strings.ToLower(keyword)) {
+			continue
+		}
+
+		clusterMap, err := this.clusterMap(cluster)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		clusterMaps = append(clusterMaps, clusterMap)
+	}
+
+	var totalNodes int64
+	for _, clusterMap := range clusterMaps {
+		totalNodes += clusterMap.GetInt64("countAllNodes")
+	}
+
+	this.Data["totalNodeClusters"] = len(clusterMaps)
+	this.Data["totalNodes"] = totalNodes
+	this.Data["latestClusters"] = []maps.Map{}
+	this.Data["countClusters"] = len(clusterMaps)
+	this.Data["page"] = ""
+	this.Data["clusters"] = clusterMaps
+	this.Data["nodes"] = []maps.Map{}
+	this.Data["countNodes"] = 0
+
+	this.Show()
+}
+
+// 集群列表中的集群信息
+func (this *IndexAction) clusterMap(cluster *pb.NodeCluster) (maps.Map, error) {
+	// 全部节点数量
+	countNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{NodeClusterId: cluster.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	// 在线节点
+	countActiveNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{
+		NodeClusterId: cluster.Id,
+		ActiveState:   types.Int32(configutils.BoolStateYes),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 需要升级的节点
+	countUpgradeNodesResp, err := this.RPC().NodeRPC().CountAllUpgradeNodesWithNodeClusterId(this.AdminContext(), &pb.CountAllUpgradeNodesWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	// DNS
+	dnsDomainName := ""
+	if cluster.DnsDomainId > 0 {
+		dnsInfoResp, err := this.RPC().NodeClusterRPC().FindEnabledNodeClusterDNS(this.AdminContext(), &pb.FindEnabledNodeClusterDNSRequest{NodeClusterId: cluster.Id})
+		if err != nil {
+			return nil, err
+		}
+		if dnsInfoResp.Domain != nil {
+			dnsDomainName = dnsInfoResp.Domain.Name
+		}
+	}
+
+	// 服务数
+	countServersResp, err := this.RPC().ServerRPC().CountAllEnabledServersWithNodeClusterId(this.AdminContext(), &pb.CountAllEnabledServersWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.TimeZone == nodeconfigs.DefaultTimeZoneLocation {
+		cluster.TimeZone = ""
+	}
+
+	return maps.Map{
+		"id":                cluster.Id,
+		"name":              cluster.Name,
+		"installDir":        cluster.InstallDir,
+		"countAllNodes":     countNodesResp.Count,
+		"countActiveNodes":  countActiveNodesResp.Count,
+		"countUpgradeNodes": countUpgradeNodesResp.Count,
+		"dnsDomainId":       cluster.DnsDomainId,
+		"dnsName":           cluster.DnsName,
+		"dnsDomainName":     dnsDomainName,
+		"countServers":      countServersResp.Count,
+		"timeZone":          cluster.TimeZone,
+		"isPinned":          cluster.IsPinned,
+	}, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/rands"
+)
+
+// CreateAction 创建运行手册
+type CreateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CreateAction) Init() {
+	this.Nav("", "runbook", "create")
+}
+
+func (this *CreateAction) RunGet(params struct{}) {
+	err := loadFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *CreateAction) RunPost(params struct {
+	Name        string
+	Description string
+	StepsJSON   []byte
+
+	TargetType      string
+	TargetClusterId int64
+	TargetGroupId   int64
+	TargetRegionId  int64
+
+	ScheduleIsOn            bool
+	ScheduleType            string
+	ScheduleIntervalMinutes int
+	ScheduleDailyTime       string
+
+	IsOn bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var now = time.Now()
+	var runbook = &runbookutils.Runbook{
+		Id:        rands.HexString(16),
+		CreatedAt: now.Unix(),
+	}
+	defer this.CreateLogInfo("创建运行手册 %s", runbook.Id)
+
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入运行手册名称")
+
+	var form = &runbookForm{
+		Name:                    params.Name,
+		Description:             params.Description,
+		StepsJSON:               params.StepsJSON,
+		TargetType:              params.TargetType,
+		TargetClusterId:         params.TargetClusterId,
+		TargetGroupId:           params.TargetGroupId,
+		TargetRegionId:          params.TargetRegionId,
+		ScheduleIsOn:            params.ScheduleIsOn,
+		ScheduleType:            params.ScheduleType,
+		ScheduleIntervalMinutes: params.ScheduleIntervalMinutes,
+		ScheduleDailyTime:       params.ScheduleDailyTime,
+		IsOn:                    params.IsOn,
+	}
+	err := form.apply(runbook)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+	runbook.ResetNextRunAt(now)
+
+	err = clusterutils.UpdateRunbooks(this.AdminContext(), this.RPC(), func(store *runbookutils.Store) error {
+		store.Add(runbook)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
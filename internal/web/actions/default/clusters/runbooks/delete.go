@@ -0,0 +1,40 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// DeleteAction 删除运行手册
+type DeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DeleteAction) RunPost(params struct {
+	RunbookId string
+}) {
+	defer this.CreateLogInfo("删除运行手册 %s", params.RunbookId)
+
+	err := clusterutils.UpdateRunbooks(this.AdminContext(), this.RPC(), func(store *runbookutils.Store) error {
+		store.Delete(params.RunbookId)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	err = clusterutils.UpdateRunbookRuns(this.AdminContext(), this.RPC(), func(store *runbookutils.RunStore) error {
+		store.DeleteRunbookRuns(params.RunbookId)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// IndexAction 运行手册列表
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "runbook", "index")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	store, err := clusterutils.LoadRunbooks(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var runbookMaps = []maps.Map{}
+	for _, runbook := range store.Runbooks {
+		targetName, err := findTargetName(this.Parent(), runbook.Target)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+
+		var lastRunTime = ""
+		if runbook.LastRunAt > 0 {
+			lastRunTime = timeutil.FormatTime("Y-m-d H:i:s", runbook.LastRunAt)
+		}
+		var nextRunTime = ""
+		if runbook.NextRunAt > 0 {
+			nextRunTime = timeutil.FormatTime("Y-m-d H:i:s", runbook.NextRunAt)
+		}
+
+		runbookMaps = append(runbookMaps, maps.Map{
+			"id":           runbook.Id,
+			"name":         runbook.Name,
+			"description":  runbook.Description,
+			"isOn":         runbook.IsOn,
+			"countSteps":   len(runbook.Steps),
+			"targetName":   targetName,
+			"scheduleName": scheduleName(runbook.Schedule),
+			"lastRunTime":  lastRunTime,
+			"nextRunTime":  nextRunTime,
+		})
+	}
+	this.Data["runbooks"] = runbookMaps
+
+	this.Show()
+}
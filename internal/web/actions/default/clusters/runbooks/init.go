@@ -0,0 +1,30 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
+	"github.com/iwind/TeaGo"
+)
+
+func init() {
+	TeaGo.BeforeStart(func(server *TeaGo.Server) {
+		server.
+			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeNode)).
+			Helper(clusterutils.NewClustersHelper()).
+			Data("teaMenu", "clusters").
+			Data("teaSubMenu", "runbook").
+			Prefix("/clusters/runbooks").
+			Get("", new(IndexAction)).
+			GetPost("/create", new(CreateAction)).
+			GetPost("/update", new(UpdateAction)).
+			Post("/delete", new(DeleteAction)).
+			Get("/runbook", new(RunbookAction)).
+			Post("/run", new(RunAction)).
+			GetPost("/runDetail", new(RunDetailAction)).
+			Post("/rerun", new(RerunAction)).
+			EndAll()
+	})
+}
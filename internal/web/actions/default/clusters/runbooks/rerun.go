@@ -0,0 +1,27 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// RerunAction 重新执行失败的步骤
+type RerunAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RerunAction) RunPost(params struct {
+	RunId string
+}) {
+	defer this.CreateLogInfo("重新执行运行手册记录 %s 中失败的步骤", params.RunId)
+
+	err := clusterutils.RerunFailedRunbookSteps(this.RPC(), this.AdminId(), params.RunId)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+)
+
+// RunAction 立即执行运行手册
+type RunAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RunAction) RunPost(params struct {
+	RunbookId string
+}) {
+	defer this.CreateLogInfo("手动执行运行手册 %s", params.RunbookId)
+
+	runId, err := clusterutils.StartRunbook(this.RPC(), this.AdminId(), params.RunbookId, runbookutils.TriggerManual)
+	if err != nil {
+		if err == runbookutils.ErrRunbookNotFound {
+			this.NotFound("runbook", 0)
+			return
+		}
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Data["runId"] = runId
+	this.Success()
+}
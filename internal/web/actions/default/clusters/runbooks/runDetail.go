@@ -0,0 +1,113 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// RunDetailAction 执行结果，按节点和步骤展示
+type RunDetailAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RunDetailAction) Init() {
+	this.Nav("", "runbook", "index")
+}
+
+func (this *RunDetailAction) RunGet(params struct {
+	RunId string
+}) {
+	runMap, ok := this.findRunMap(params.RunId)
+	if !ok {
+		return
+	}
+	this.Data["run"] = runMap
+
+	this.Show()
+}
+
+// RunPost 用于刷新执行进度
+func (this *RunDetailAction) RunPost(params struct {
+	RunId string
+}) {
+	runMap, ok := this.findRunMap(params.RunId)
+	if !ok {
+		return
+	}
+	this.Data["run"] = runMap
+
+	this.Success()
+}
+
+func (this *RunDetailAction) findRunMap(runId string) (maps.Map, bool) {
+	store, err := clusterutils.LoadRunbookRuns(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return nil, false
+	}
+	var run = store.Find(runId)
+	if run == nil {
+		this.NotFound("runbookRun", 0)
+		return nil, false
+	}
+
+	var stepMaps = []maps.Map{}
+	for _, step := range run.Steps {
+		stepMaps = append(stepMaps, maps.Map{
+			"name":            stepName(step),
+			"continueOnError": step.ContinueOnError,
+		})
+	}
+
+	// 每个节点一行，每个步骤一列
+	var nodeMaps = []maps.Map{}
+	for _, node := range run.Nodes {
+		var cellMaps = []maps.Map{}
+		for stepIndex := range run.Steps {
+			var cell = run.FindCell(stepIndex, node.Id)
+			if cell == nil {
+				cellMaps = append(cellMaps, maps.Map{
+					"status":  runbookutils.CellSkipped,
+					"message": "",
+				})
+				continue
+			}
+			cellMaps = append(cellMaps, maps.Map{
+				"status":  cell.Status,
+				"message": cell.Message,
+			})
+		}
+		nodeMaps = append(nodeMaps, maps.Map{
+			"id":        node.Id,
+			"name":      node.Name,
+			"clusterId": node.ClusterId,
+			"cells":     cellMaps,
+		})
+	}
+
+	var finishedTime = ""
+	if run.FinishedAt > 0 {
+		finishedTime = timeutil.FormatTime("Y-m-d H:i:s", run.FinishedAt)
+	}
+
+	return maps.Map{
+		"id":           run.Id,
+		"runbookId":    run.RunbookId,
+		"runbookName":  run.RunbookName,
+		"triggerName":  triggerName(run.Trigger),
+		"isRunning":    run.Status == runbookutils.RunStatusRunning,
+		"createdTime":  timeutil.FormatTime("Y-m-d H:i:s", run.CreatedAt),
+		"finishedTime": finishedTime,
+		"steps":        stepMaps,
+		"nodes":        nodeMaps,
+		"countOk":      run.CountCells(runbookutils.CellOk),
+		"countFailed":  run.CountCells(runbookutils.CellFailed),
+		"countSkipped": run.CountCells(runbookutils.CellSkipped),
+		"countPending": run.CountCells(runbookutils.CellPending),
+	}, true
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// RunbookAction 运行手册详情和执行记录
+type RunbookAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RunbookAction) Init() {
+	this.Nav("", "runbook", "index")
+}
+
+func (this *RunbookAction) RunGet(params struct {
+	RunbookId string
+}) {
+	store, err := clusterutils.LoadRunbooks(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var runbook = store.Find(params.RunbookId)
+	if runbook == nil {
+		this.NotFound("runbook", 0)
+		return
+	}
+
+	targetName, err := findTargetName(this.Parent(), runbook.Target)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var stepMaps = []maps.Map{}
+	for _, step := range runbook.Steps {
+		stepMaps = append(stepMaps, maps.Map{
+			"name":            stepName(step),
+			"continueOnError": step.ContinueOnError,
+			"timeoutSeconds":  step.TimeoutSeconds,
+		})
+	}
+
+	var nextRunTime = ""
+	if runbook.NextRunAt > 0 {
+		nextRunTime = timeutil.FormatTime("Y-m-d H:i:s", runbook.NextRunAt)
+	}
+
+	this.Data["runbook"] = maps.Map{
+		"id":           runbook.Id,
+		"name":         runbook.Name,
+		"description":  runbook.Description,
+		"isOn":         runbook.IsOn,
+		"steps":        stepMaps,
+		"targetName":   targetName,
+		"scheduleName": scheduleName(runbook.Schedule),
+		"nextRunTime":  nextRunTime,
+	}
+
+	// 执行记录
+	runStore, err := clusterutils.LoadRunbookRuns(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var runMaps = []maps.Map{}
+	for _, run := range runStore.FindRunbookRuns(runbook.Id) {
+		var finishedTime = ""
+		if run.FinishedAt > 0 {
+			finishedTime = timeutil.FormatTime("Y-m-d H:i:s", run.FinishedAt)
+		}
+		runMaps = append(runMaps, maps.Map{
+			"id":           run.Id,
+			"triggerName":  triggerName(run.Trigger),
+			"isRunning":    run.Status == runbookutils.RunStatusRunning,
+			"createdTime":  timeutil.FormatTime("Y-m-d H:i:s", run.CreatedAt),
+			"finishedTime": finishedTime,
+			"countNodes":   len(run.Nodes),
+			"countOk":      run.CountCells(runbookutils.CellOk),
+			"countFailed":  run.CountCells(runbookutils.CellFailed),
+			"countSkipped": run.CountCells(runbookutils.CellSkipped),
+		})
+	}
+	this.Data["runs"] = runMaps
+
+	this.Show()
+}
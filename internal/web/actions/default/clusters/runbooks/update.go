@@ -0,0 +1,134 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// UpdateAction 修改运行手册
+type UpdateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UpdateAction) Init() {
+	this.Nav("", "runbook", "index")
+}
+
+func (this *UpdateAction) RunGet(params struct {
+	RunbookId string
+}) {
+	store, err := clusterutils.LoadRunbooks(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var runbook = store.Find(params.RunbookId)
+	if runbook == nil {
+		this.NotFound("runbook", 0)
+		return
+	}
+
+	var target = runbook.Target
+	if target == nil {
+		target = &runbookutils.Target{Type: runbookutils.TargetCluster}
+	}
+	var schedule = runbook.Schedule
+	if schedule == nil {
+		schedule = &runbookutils.Schedule{Type: runbookutils.ScheduleDaily}
+	}
+	this.Data["runbook"] = maps.Map{
+		"id":          runbook.Id,
+		"name":        runbook.Name,
+		"description": runbook.Description,
+		"steps":       runbook.Steps,
+		"target":      target,
+		"schedule":    schedule,
+		"isOn":        runbook.IsOn,
+	}
+
+	err = loadFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *UpdateAction) RunPost(params struct {
+	RunbookId   string
+	Name        string
+	Description string
+	StepsJSON   []byte
+
+	TargetType      string
+	TargetClusterId int64
+	TargetGroupId   int64
+	TargetRegionId  int64
+
+	ScheduleIsOn            bool
+	ScheduleType            string
+	ScheduleIntervalMinutes int
+	ScheduleDailyTime       string
+
+	IsOn bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改运行手册 %s", params.RunbookId)
+
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入运行手册名称")
+
+	var form = &runbookForm{
+		Name:                    params.Name,
+		Description:             params.Description,
+		StepsJSON:               params.StepsJSON,
+		TargetType:              params.TargetType,
+		TargetClusterId:         params.TargetClusterId,
+		TargetGroupId:           params.TargetGroupId,
+		TargetRegionId:          params.TargetRegionId,
+		ScheduleIsOn:            params.ScheduleIsOn,
+		ScheduleType:            params.ScheduleType,
+		ScheduleIntervalMinutes: params.ScheduleIntervalMinutes,
+		ScheduleDailyTime:       params.ScheduleDailyTime,
+		IsOn:                    params.IsOn,
+	}
+
+	var formErr error
+	err := clusterutils.UpdateRunbooks(this.AdminContext(), this.RPC(), func(store *runbookutils.Store) error {
+		var runbook = store.Find(params.RunbookId)
+		if runbook == nil {
+			return runbookutils.ErrRunbookNotFound
+		}
+		formErr = form.apply(runbook)
+		if formErr != nil {
+			return formErr
+		}
+		runbook.ResetNextRunAt(time.Now())
+		return nil
+	})
+	if formErr != nil {
+		this.Fail(formErr.Error())
+		return
+	}
+	if err != nil {
+		if err == runbookutils.ErrRunbookNotFound {
+			this.NotFound("runbook", 0)
+			return
+		}
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
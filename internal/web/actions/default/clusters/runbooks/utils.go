@@ -0,0 +1,234 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package runbooks
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/runbookutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
+)
+
+// 运行手册表单提交的参数
+type runbookForm struct {
+	Name        string
+	Description string
+	StepsJSON   []byte
+
+	TargetType      string
+	TargetClusterId int64
+	TargetGroupId   int64
+	TargetRegionId  int64
+
+	ScheduleIsOn            bool
+	ScheduleType            string
+	ScheduleIntervalMinutes int
+	ScheduleDailyTime       string
+
+	IsOn bool
+}
+
+// 将表单转换为运行手册
+func (this *runbookForm) apply(runbook *runbookutils.Runbook) error {
+	var steps = []*runbookutils.Step{}
+	if len(this.StepsJSON) > 0 {
+		err := json.Unmarshal(this.StepsJSON, &steps)
+		if err != nil {
+			return errors.New("解析步骤失败：" + err.Error())
+		}
+	}
+	for _, step := range steps {
+		step.FirewallName = strings.TrimSpace(step.FirewallName)
+	}
+
+	var target = &runbookutils.Target{Type: this.TargetType}
+	switch this.TargetType {
+	case runbookutils.TargetCluster:
+		target.ClusterId = this.TargetClusterId
+	case runbookutils.TargetGroup:
+		target.ClusterId = this.TargetClusterId
+		target.GroupId = this.TargetGroupId
+	case runbookutils.TargetRegion:
+		target.ClusterId = this.TargetClusterId
+		target.RegionId = this.TargetRegionId
+	}
+
+	runbook.Name = this.Name
+	runbook.Description = this.Description
+	runbook.Steps = steps
+	runbook.Target = target
+	runbook.Schedule = &runbookutils.Schedule{
+		IsOn:            this.ScheduleIsOn,
+		Type:            this.ScheduleType,
+		IntervalMinutes: this.ScheduleIntervalMinutes,
+		DailyTime:       strings.TrimSpace(this.ScheduleDailyTime),
+	}
+	runbook.IsOn = this.IsOn
+
+	return runbook.Validate()
+}
+
+// 读取表单需要的选项
+func loadFormOptions(parent *actionutils.ParentAction) error {
+	// 集群和分组
+	clustersResp, err := parent.RPC().NodeClusterRPC().FindAllEnabledNodeClusters(parent.AdminContext(), &pb.FindAllEnabledNodeClustersRequest{})
+	if err != nil {
+		return err
+	}
+	var clusterMaps = []maps.Map{}
+	for _, cluster := range clustersResp.NodeClusters {
+		groupsResp, err := parent.RPC().NodeGroupRPC().FindAllEnabledNodeGroupsWithNodeClusterId(parent.AdminContext(), &pb.FindAllEnabledNodeGroupsWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
+		if err != nil {
+			return err
+		}
+		var groupMaps = []maps.Map{}
+		for _, group := range groupsResp.NodeGroups {
+			groupMaps = append(groupMaps, maps.Map{
+				"id":   group.Id,
+				"name": group.Name,
+			})
+		}
+		clusterMaps = append(clusterMaps, maps.Map{
+			"id":     cluster.Id,
+			"name":   cluster.Name,
+			"groups": groupMaps,
+		})
+	}
+	parent.Data["clusters"] = clusterMaps
+
+	// 区域
+	regionsResp, err := parent.RPC().NodeRegionRPC().FindAllEnabledNodeRegions(parent.AdminContext(), &pb.FindAllEnabledNodeRegionsRequest{})
+	if err != nil {
+		return err
+	}
+	var regionMaps = []maps.Map{}
+	for _, region := range regionsResp.NodeRegions {
+		regionMaps = append(regionMaps, maps.Map{
+			"id":   region.Id,
+			"name": region.Name,
+		})
+	}
+	parent.Data["regions"] = regionMaps
+
+	// 缓存策略
+	cachePoliciesResp, err := parent.RPC().HTTPCachePolicyRPC().ListEnabledHTTPCachePolicies(parent.AdminContext(), &pb.ListEnabledHTTPCachePoliciesRequest{
+		Offset: 0,
+		Size:   1000,
+	})
+	if err != nil {
+		return err
+	}
+	var cachePolicies = []*serverconfigs.HTTPCachePolicy{}
+	if len(cachePoliciesResp.HttpCachePoliciesJSON) > 0 {
+		err = json.Unmarshal(cachePoliciesResp.HttpCachePoliciesJSON, &cachePolicies)
+		if err != nil {
+			return err
+		}
+	}
+	var cachePolicyMaps = []maps.Map{}
+	for _, cachePolicy := range cachePolicies {
+		cachePolicyMaps = append(cachePolicyMaps, maps.Map{
+			"id":   cachePolicy.Id,
+			"name": cachePolicy.Name,
+		})
+	}
+	parent.Data["cachePolicies"] = cachePolicyMaps
+
+	parent.Data["stepDefinitions"] = runbookutils.AllStepDefinitions()
+
+	return nil
+}
+
+// 执行目标的名称
+func findTargetName(parent *actionutils.ParentAction, target *runbookutils.Target) (string, error) {
+	if target == nil {
+		return "", nil
+	}
+
+	var clusterName = ""
+	if target.ClusterId > 0 {
+		clusterResp, err := parent.RPC().NodeClusterRPC().FindEnabledNodeCluster(parent.AdminContext(), &pb.FindEnabledNodeClusterRequest{NodeClusterId: target.ClusterId})
+		if err != nil {
+			return "", err
+		}
+		if clusterResp.NodeCluster == nil {
+			clusterName = "[已删除集群]"
+		} else {
+			clusterName = clusterResp.NodeCluster.Name
+		}
+	}
+
+	switch target.Type {
+	case runbookutils.TargetCluster:
+		return "集群：" + clusterName, nil
+	case runbookutils.TargetGroup:
+		groupResp, err := parent.RPC().NodeGroupRPC().FindEnabledNodeGroup(parent.AdminContext(), &pb.FindEnabledNodeGroupRequest{NodeGroupId: target.GroupId})
+		if err != nil {
+			return "", err
+		}
+		var groupName = "[已删除分组]"
+		if groupResp.NodeGroup != nil {
+			groupName = groupResp.NodeGroup.Name
+		}
+		return "分组：" + clusterName + " / " + groupName, nil
+	case runbookutils.TargetRegion:
+		regionResp, err := parent.RPC().NodeRegionRPC().FindEnabledNodeRegion(parent.AdminContext(), &pb.FindEnabledNodeRegionRequest{NodeRegionId: target.RegionId})
+		if err != nil {
+			return "", err
+		}
+		var regionName = "[已删除区域]"
+		if regionResp.NodeRegion != nil {
+			regionName = regionResp.NodeRegion.Name
+		}
+		if len(clusterName) > 0 {
+			return "区域：" + regionName + "（" + clusterName + "）", nil
+		}
+		return "区域：" + regionName, nil
+	}
+	return "", nil
+}
+
+// 执行计划的名称
+func scheduleName(schedule *runbookutils.Schedule) string {
+	if schedule == nil || !schedule.IsOn {
+		return "手动执行"
+	}
+	switch schedule.Type {
+	case runbookutils.ScheduleInterval:
+		return "每" + types.String(schedule.IntervalMinutes) + "分钟"
+	case runbookutils.ScheduleDaily:
+		return "每天" + schedule.DailyTime
+	}
+	return ""
+}
+
+// 触发方式名称
+func triggerName(trigger string) string {
+	switch trigger {
+	case runbookutils.TriggerManual:
+		return "手动执行"
+	case runbookutils.TriggerSchedule:
+		return "计划执行"
+	case runbookutils.TriggerRerun:
+		return "重新执行"
+	}
+	return trigger
+}
+
+// 步骤名称
+func stepName(step *runbookutils.Step) string {
+	var def = runbookutils.FindStepDefinition(step.Code)
+	if def == nil {
+		return step.Code
+	}
+	if len(step.FirewallName) > 0 && def.RequireFirewallName {
+		return def.Name + "（" + step.FirewallName + "）"
+	}
+	return def.Name
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// BoardAction 查看自定义看板
+type BoardAction struct {
+	actionutils.ParentAction
+}
+
+func (this *BoardAction) Init() {
+	this.Nav("", "", "custom")
+}
+
+func (this *BoardAction) RunGet(params struct {
+	BoardId   int64
+	TimeRange string
+}) {
+	board, err := findViewableBoard(this.Parent(), params.BoardId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if board == nil {
+		this.NotFound("board", params.BoardId)
+		return
+	}
+
+	var timeRange = board.TimeRange
+	if boardutils.IsValidTimeRange(params.TimeRange) {
+		timeRange = params.TimeRange
+	}
+
+	var widgetMaps = []maps.Map{}
+	for _, widget := range board.Widgets {
+		widgetMaps = append(widgetMaps, maps.Map{
+			"id":            widget.Id,
+			"kind":          widget.Kind,
+			"title":         widget.DisplayTitle(),
+			"width":         widget.Width,
+			"metricChartId": widget.MetricChartId,
+		})
+	}
+
+	this.Data["board"] = maps.Map{
+		"id":             board.Id,
+		"name":           board.Name,
+		"isOwner":        board.CanEdit(this.AdminId()),
+		"ownerName":      configloaders.FindAdminFullname(board.AdminId),
+		"refreshSeconds": board.RefreshSeconds,
+		"widgets":        widgetMaps,
+	}
+	this.Data["timeRange"] = timeRange
+	this.Data["timeRanges"] = boardutils.AllTimeRanges()
+	this.Data["refreshSeconds"] = boardutils.AllRefreshSeconds
+
+	this.Show()
+}
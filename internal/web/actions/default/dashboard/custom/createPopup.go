@@ -0,0 +1,74 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// CreatePopupAction 创建自定义看板
+type CreatePopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CreatePopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *CreatePopupAction) RunGet(params struct{}) {
+	err := initBoardOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *CreatePopupAction) RunPost(params struct {
+	Name           string
+	TimeRange      string
+	RefreshSeconds int
+	IsShared       bool
+	ShareScope     string
+	SharedAdminIds []int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var board = &boardutils.Board{
+		Name:           params.Name,
+		AdminId:        this.AdminId(),
+		IsShared:       params.IsShared,
+		TimeRange:      params.TimeRange,
+		RefreshSeconds: params.RefreshSeconds,
+		Widgets:        []*boardutils.Widget{},
+		CreatedAt:      time.Now().Unix(),
+		UpdatedAt:      time.Now().Unix(),
+	}
+	if params.IsShared && params.ShareScope == "admins" {
+		if len(params.SharedAdminIds) == 0 {
+			this.Fail("请选择要共享的管理员")
+			return
+		}
+		board.SharedAdminIds = params.SharedAdminIds
+	}
+
+	err := configloaders.UpdateAdminBoardStore(func(store *boardutils.Store) error {
+		return store.AddBoard(board)
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	defer this.CreateLogInfo("创建自定义看板 %d", board.Id)
+
+	this.Data["boardId"] = board.Id
+	this.Success()
+}
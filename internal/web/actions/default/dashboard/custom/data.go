@@ -0,0 +1,278 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/configutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
+)
+
+// DataAction 读取看板组件数据
+type DataAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DataAction) RunPost(params struct {
+	BoardId   int64
+	TimeRange string
+}) {
+	board, err := findViewableBoard(this.Parent(), params.BoardId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if board == nil {
+		this.NotFound("board", params.BoardId)
+		return
+	}
+
+	var timeRange = params.TimeRange
+	if !boardutils.IsValidTimeRange(timeRange) {
+		timeRange = board.TimeRange
+	}
+
+	// 看板中用到的组件类型
+	var kinds = map[string]bool{}
+	for _, widget := range board.Widgets {
+		kinds[widget.Kind] = true
+	}
+
+	resp, err := this.RPC().AdminRPC().ComposeAdminDashboard(this.AdminContext(), &pb.ComposeAdminDashboardRequest{
+		ApiVersion: teaconst.APINodeVersion,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 流量统计
+	var statMaps = []maps.Map{}
+	var summary = maps.Map{}
+	{
+		var totalBytes, totalCachedBytes, totalAttackBytes int64
+		var totalRequests, totalCachedRequests, totalAttackRequests int64
+
+		var addStat = func(statMap maps.Map, bytes int64, cachedBytes int64, attackBytes int64, countRequests int64, countCachedRequests int64, countAttackRequests int64, seconds int64) {
+			statMap["bytes"] = bytes
+			statMap["cachedBytes"] = cachedBytes
+			statMap["attackBytes"] = attackBytes
+			statMap["countRequests"] = countRequests
+			statMap["countCachedRequests"] = countCachedRequests
+			statMap["countAttackRequests"] = countAttackRequests
+			statMap["bandwidthBits"] = bytes * 8 / seconds
+			statMaps = append(statMaps, statMap)
+
+			totalBytes += bytes
+			totalCachedBytes += cachedBytes
+			totalAttackBytes += attackBytes
+			totalRequests += countRequests
+			totalCachedRequests += countCachedRequests
+			totalAttackRequests += countAttackRequests
+		}
+
+		if timeRange == boardutils.TimeRange24Hours {
+			for _, stat := range resp.HourlyTrafficStats {
+				addStat(maps.Map{
+					"label": stat.Hour[8:] + "时",
+					"day":   stat.Hour[4:6] + "月" + stat.Hour[6:8] + "日",
+					"hour":  stat.Hour[8:],
+				}, stat.Bytes, stat.CachedBytes, stat.AttackBytes, stat.CountRequests, stat.CountCachedRequests, stat.CountAttackRequests, 3600)
+			}
+		} else {
+			dayFrom, dayTo := boardutils.DayRange(timeRange, time.Now())
+			dailyResp, err := this.RPC().TrafficDailyStatRPC().FindTrafficDailyStatWithDayRange(this.AdminContext(), &pb.FindTrafficDailyStatWithDayRangeRequest{
+				DayFrom: dayFrom,
+				DayTo:   dayTo,
+			})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			for _, stat := range dailyResp.Stats {
+				addStat(maps.Map{
+					"label": stat.Day[4:6] + "月" + stat.Day[6:] + "日",
+					"day":   stat.Day[4:6] + "月" + stat.Day[6:] + "日",
+					"hour":  "",
+				}, stat.Bytes, stat.CachedBytes, stat.AttackBytes, stat.CountRequests, stat.CountCachedRequests, stat.CountAttackRequests, 86400)
+			}
+		}
+
+		summary = maps.Map{
+			"bytes":               totalBytes,
+			"cachedBytes":         totalCachedBytes,
+			"attackBytes":         totalAttackBytes,
+			"countRequests":       totalRequests,
+			"countCachedRequests": totalCachedRequests,
+			"countAttackRequests": totalAttackRequests,
+		}
+	}
+	this.Data["stats"] = statMaps
+	this.Data["summary"] = summary
+
+	// 节点状态
+	this.Data["nodes"] = maps.Map{
+		"countNodeClusters":    resp.CountNodeClusters,
+		"countNodes":           resp.CountNodes,
+		"countOfflineNodes":    resp.CountOfflineNodes,
+		"countAPINodes":        resp.CountAPINodes,
+		"countOfflineAPINodes": resp.CountOfflineAPINodes,
+		"countDBNodes":         resp.CountDBNodes,
+		"canGoNodes":           configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeNode),
+	}
+
+	// 集群健康状况
+	var clusterMaps = []maps.Map{}
+	if kinds[boardutils.WidgetKindClusterHealth] && configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeNode) {
+		clustersResp, err := this.RPC().NodeClusterRPC().FindAllEnabledNodeClusters(this.AdminContext(), &pb.FindAllEnabledNodeClustersRequest{})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		for _, cluster := range clustersResp.NodeClusters {
+			countNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{NodeClusterId: cluster.Id})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			countActiveNodesResp, err := this.RPC().NodeRPC().CountAllEnabledNodesMatch(this.AdminContext(), &pb.CountAllEnabledNodesMatchRequest{
+				NodeClusterId: cluster.Id,
+				ActiveState:   types.Int32(configutils.BoolStateYes),
+			})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			clusterMaps = append(clusterMaps, maps.Map{
+				"id":               cluster.Id,
+				"name":             cluster.Name,
+				"countNodes":       countNodesResp.Count,
+				"countActiveNodes": countActiveNodesResp.Count,
+			})
+		}
+	}
+	this.Data["clusters"] = clusterMaps
+
+	// 域名排行
+	var domainMaps = []maps.Map{}
+	for _, stat := range resp.TopDomainStats {
+		domainMaps = append(domainMaps, maps.Map{
+			"serverId":      stat.ServerId,
+			"domain":        stat.Domain,
+			"countRequests": stat.CountRequests,
+			"bytes":         stat.Bytes,
+		})
+	}
+	this.Data["topDomainStats"] = domainMaps
+
+	// 指标图表
+	var chartMaps = maps.Map{}
+	for _, widget := range board.Widgets {
+		if widget.Kind != boardutils.WidgetKindMetricChart || widget.MetricChartId <= 0 {
+			continue
+		}
+		var chartIdString = types.String(widget.MetricChartId)
+		if chartMaps.Has(chartIdString) {
+			continue
+		}
+		chartMap, err := findMetricChartData(this.Parent(), widget.MetricChartId, timeRange)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if chartMap != nil {
+			chartMaps[chartIdString] = chartMap
+		}
+	}
+	this.Data["metricCharts"] = chartMaps
+
+	this.Success()
+}
+
+// 每次读取的指标数据条数
+const metricStatsPageSize = 1000
+
+// 最多读取的指标数据条数
+const maxMetricStats = 20 * metricStatsPageSize
+
+// 读取某个指标图表在时间范围内的数据
+func findMetricChartData(parent *actionutils.ParentAction, chartId int64, timeRange string) (maps.Map, error) {
+	chartResp, err := parent.RPC().MetricChartRPC().FindEnabledMetricChart(parent.AdminContext(), &pb.FindEnabledMetricChartRequest{MetricChartId: chartId})
+	if err != nil {
+		return nil, err
+	}
+	var chart = chartResp.MetricChart
+	if chart == nil || chart.MetricItem == nil {
+		return nil, nil
+	}
+
+	itemResp, err := parent.RPC().MetricItemRPC().FindEnabledMetricItem(parent.AdminContext(), &pb.FindEnabledMetricItemRequest{MetricItemId: chart.MetricItem.Id})
+	if err != nil {
+		return nil, err
+	}
+	var item = itemResp.MetricItem
+	if item == nil {
+		return nil, nil
+	}
+
+	var stats = []*boardutils.MetricStat{}
+	for offset := int64(0); offset < maxMetricStats; offset += metricStatsPageSize {
+		statsResp, err := parent.RPC().MetricStatRPC().ListMetricStats(parent.AdminContext(), &pb.ListMetricStatsRequest{
+			MetricItemId: item.Id,
+			Offset:       offset,
+			Size:         metricStatsPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range statsResp.MetricStats {
+			stats = append(stats, &boardutils.MetricStat{
+				Keys:  stat.Keys,
+				Time:  stat.Time,
+				Value: stat.Value,
+			})
+		}
+		if len(statsResp.MetricStats) < metricStatsPageSize {
+			break
+		}
+	}
+
+	var metricStatMaps = []maps.Map{}
+	for _, stat := range boardutils.SumMetricStats(stats, boardutils.MetricTimeFrom(timeRange, item.PeriodUnit, time.Now())) {
+		metricStatMaps = append(metricStatMaps, maps.Map{
+			"keys":  stat.Keys,
+			"time":  stat.Time,
+			"value": stat.Value,
+			"total": stat.Total,
+		})
+	}
+
+	return maps.Map{
+		"chart": maps.Map{
+			"id":       chart.Id,
+			"name":     chart.Name,
+			"widthDiv": chart.WidthDiv,
+			"isOn":     chart.IsOn,
+			"maxItems": chart.MaxItems,
+			"type":     chart.Type,
+		},
+		"item": maps.Map{
+			"id":            item.Id,
+			"name":          item.Name,
+			"period":        item.Period,
+			"periodUnit":    item.PeriodUnit,
+			"valueType":     serverconfigs.FindMetricValueType(item.Category, item.Value),
+			"valueTypeName": serverconfigs.FindMetricValueName(item.Category, item.Value),
+			"keys":          item.Keys,
+		},
+		"stats": metricStatMaps,
+	}, nil
+}
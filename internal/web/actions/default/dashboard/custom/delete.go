@@ -0,0 +1,34 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// DeleteAction 删除自定义看板
+type DeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DeleteAction) RunPost(params struct {
+	BoardId int64
+}) {
+	defer this.CreateLogInfo("删除自定义看板 %d", params.BoardId)
+
+	err := configloaders.UpdateAdminBoardStore(func(store *boardutils.Store) error {
+		var board = store.FindBoard(params.BoardId)
+		if board == nil || !board.CanEdit(this.AdminId()) {
+			return errBoardNotFound
+		}
+		return store.RemoveBoard(params.BoardId)
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// IndexAction 自定义看板列表
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "custom")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	store, err := configloaders.LoadAdminBoardStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var boardMaps = []maps.Map{}
+	for _, board := range store.FindAdminBoards(this.AdminId()) {
+		boardMaps = append(boardMaps, maps.Map{
+			"id":           board.Id,
+			"name":         board.Name,
+			"isShared":     board.IsShared,
+			"isOwner":      board.CanEdit(this.AdminId()),
+			"ownerName":    configloaders.FindAdminFullname(board.AdminId),
+			"countWidgets": len(board.Widgets),
+			"updatedTime":  timeutil.FormatTime("Y-m-d H:i:s", board.UpdatedAt),
+		})
+	}
+	this.Data["boards"] = boardMaps
+
+	this.Show()
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// UpdatePopupAction 修改自定义看板
+type UpdatePopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UpdatePopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *UpdatePopupAction) RunGet(params struct {
+	BoardId int64
+}) {
+	board, err := findViewableBoard(this.Parent(), params.BoardId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if board == nil || !board.CanEdit(this.AdminId()) {
+		this.NotFound("board", params.BoardId)
+		return
+	}
+
+	var shareScope = "all"
+	if len(board.SharedAdminIds) > 0 {
+		shareScope = "admins"
+	}
+	this.Data["board"] = maps.Map{
+		"id":             board.Id,
+		"name":           board.Name,
+		"timeRange":      board.TimeRange,
+		"refreshSeconds": board.RefreshSeconds,
+		"isShared":       board.IsShared,
+		"shareScope":     shareScope,
+		"sharedAdminIds": board.SharedAdminIds,
+	}
+
+	err = initBoardOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *UpdatePopupAction) RunPost(params struct {
+	BoardId        int64
+	Name           string
+	TimeRange      string
+	RefreshSeconds int
+	IsShared       bool
+	ShareScope     string
+	SharedAdminIds []int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改自定义看板 %d", params.BoardId)
+
+	if params.IsShared && params.ShareScope == "admins" && len(params.SharedAdminIds) == 0 {
+		this.Fail("请选择要共享的管理员")
+		return
+	}
+
+	err := updateOwnBoard(this.Parent(), params.BoardId, func(board *boardutils.Board) error {
+		board.Name = params.Name
+		board.TimeRange = params.TimeRange
+		board.RefreshSeconds = params.RefreshSeconds
+		board.IsShared = params.IsShared
+		board.SharedAdminIds = nil
+		if params.IsShared && params.ShareScope == "admins" {
+			board.SharedAdminIds = params.SharedAdminIds
+		}
+		board.UpdatedAt = time.Now().Unix()
+		return board.Validate()
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+var errBoardNotFound = errors.New("看板不存在或者没有权限修改")
+
+// 查找当前管理员可以查看的看板
+func findViewableBoard(parent *actionutils.ParentAction, boardId int64) (*boardutils.Board, error) {
+	store, err := configloaders.LoadAdminBoardStore()
+	if err != nil {
+		return nil, err
+	}
+	var board = store.FindBoard(boardId)
+	if board == nil || !board.CanView(parent.AdminId()) {
+		return nil, nil
+	}
+	return board, nil
+}
+
+// 修改当前管理员创建的看板
+func updateOwnBoard(parent *actionutils.ParentAction, boardId int64, f func(board *boardutils.Board) error) error {
+	return configloaders.UpdateAdminBoardStore(func(store *boardutils.Store) error {
+		var board = store.FindBoard(boardId)
+		if board == nil || !board.CanEdit(parent.AdminId()) {
+			return errBoardNotFound
+		}
+		return f(board)
+	})
+}
+
+// 初始化看板表单中的选项
+func initBoardOptions(parent *actionutils.ParentAction) error {
+	parent.Data["timeRanges"] = boardutils.AllTimeRanges()
+	parent.Data["refreshSeconds"] = boardutils.AllRefreshSeconds
+
+	// 可以共享的管理员
+	adminsResp, err := parent.RPC().AdminRPC().ListEnabledAdmins(parent.AdminContext(), &pb.ListEnabledAdminsRequest{
+		Offset: 0,
+		Size:   1000,
+	})
+	if err != nil {
+		return err
+	}
+	var adminMaps = []maps.Map{}
+	for _, admin := range adminsResp.Admins {
+		if admin.Id == parent.AdminId() {
+			continue
+		}
+		adminMaps = append(adminMaps, maps.Map{
+			"id":       admin.Id,
+			"fullname": admin.Fullname,
+			"username": admin.Username,
+		})
+	}
+	parent.Data["admins"] = adminMaps
+	return nil
+}
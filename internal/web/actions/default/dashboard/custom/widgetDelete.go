@@ -0,0 +1,34 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// WidgetDeleteAction 删除看板组件
+type WidgetDeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WidgetDeleteAction) RunPost(params struct {
+	BoardId  int64
+	WidgetId string
+}) {
+	defer this.CreateLogInfo("删除自定义看板 %d 中的组件", params.BoardId)
+
+	err := updateOwnBoard(this.Parent(), params.BoardId, func(board *boardutils.Board) error {
+		board.RemoveWidget(params.WidgetId)
+		board.UpdatedAt = time.Now().Unix()
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// WidgetMoveAction 调整看板组件顺序
+type WidgetMoveAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WidgetMoveAction) RunPost(params struct {
+	BoardId  int64
+	WidgetId string
+	Delta    int
+}) {
+	defer this.CreateLogInfo("调整自定义看板 %d 中的组件顺序", params.BoardId)
+
+	err := updateOwnBoard(this.Parent(), params.BoardId, func(board *boardutils.Board) error {
+		board.MoveWidget(params.WidgetId, params.Delta)
+		board.UpdatedAt = time.Now().Unix()
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package custom
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/boardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// WidgetPopupAction 添加或修改看板组件
+type WidgetPopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WidgetPopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *WidgetPopupAction) RunGet(params struct {
+	BoardId  int64
+	WidgetId string
+}) {
+	board, err := findViewableBoard(this.Parent(), params.BoardId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if board == nil || !board.CanEdit(this.AdminId()) {
+		this.NotFound("board", params.BoardId)
+		return
+	}
+	this.Data["boardId"] = board.Id
+
+	// 组件类型
+	var definitionMaps = []maps.Map{}
+	for _, definition := range boardutils.AllWidgetDefinitions() {
+		definitionMaps = append(definitionMaps, maps.Map{
+			"kind":         definition.Kind,
+			"name":         definition.Name,
+			"description":  definition.Description,
+			"defaultWidth": definition.DefaultWidth,
+		})
+	}
+	this.Data["definitions"] = definitionMaps
+
+	// 当前组件
+	var widgetMap = maps.Map{
+		"id":            "",
+		"kind":          boardutils.WidgetKindTrafficTrend,
+		"title":         "",
+		"width":         0,
+		"metricChartId": 0,
+	}
+	if len(params.WidgetId) > 0 {
+		var widget = board.FindWidget(params.WidgetId)
+		if widget == nil {
+			this.ErrorPage(errBoardNotFound)
+			return
+		}
+		widgetMap = maps.Map{
+			"id":            widget.Id,
+			"kind":          widget.Kind,
+			"title":         widget.Title,
+			"width":         widget.Width,
+			"metricChartId": widget.MetricChartId,
+		}
+	}
+	this.Data["widget"] = widgetMap
+
+	// 可选的指标图表
+	countResp, err := this.RPC().MetricChartRPC().CountEnabledMetricCharts(this.AdminContext(), &pb.CountEnabledMetricChartsRequest{})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	chartsResp, err := this.RPC().MetricChartRPC().ListEnabledMetricCharts(this.AdminContext(), &pb.ListEnabledMetricChartsRequest{
+		Offset: 0,
+		Size:   countResp.Count,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var chartMaps = []maps.Map{}
+	for _, chart := range chartsResp.MetricCharts {
+		if !chart.IsOn {
+			continue
+		}
+		var itemName = ""
+		if chart.MetricItem != nil {
+			itemName = chart.MetricItem.Name
+		}
+		chartMaps = append(chartMaps, maps.Map{
+			"id":       chart.Id,
+			"name":     chart.Name,
+			"itemName": itemName,
+		})
+	}
+	this.Data["metricCharts"] = chartMaps
+
+	this.Show()
+}
+
+func (this *WidgetPopupAction) RunPost(params struct {
+	BoardId       int64
+	WidgetId      string
+	Kind          string
+	Title         string
+	Width         int
+	MetricChartId int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改自定义看板 %d 中的组件", params.BoardId)
+
+	err := updateOwnBoard(this.Parent(), params.BoardId, func(board *boardutils.Board) error {
+		board.UpdatedAt = time.Now().Unix()
+
+		if len(params.WidgetId) == 0 {
+			return board.AddWidget(&boardutils.Widget{
+				Kind:          params.Kind,
+				Title:         params.Title,
+				Width:         params.Width,
+				MetricChartId: params.MetricChartId,
+			})
+		}
+
+		var widget = board.FindWidget(params.WidgetId)
+		if widget == nil {
+			return errBoardNotFound
+		}
+		var newWidget = &boardutils.Widget{
+			Id:            widget.Id,
+			Kind:          params.Kind,
+			Title:         params.Title,
+			Width:         params.Width,
+			MetricChartId: params.MetricChartId,
+		}
+		err := newWidget.Validate()
+		if err != nil {
+			return err
+		}
+		*widget = *newWidget
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
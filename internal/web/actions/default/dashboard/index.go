@@ -10,6 +10,7 @@ import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/dashboard/dashboardutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/settingutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -22,7 +23,7 @@ type IndexAction struct {
 }
 
 func (this *IndexAction) Init() {
-	this.Nav("", "", "")
+	this.Nav("", "", "index")
 }
 
 func (this *IndexAction) RunGet(params struct{}) {
@@ -246,5 +247,19 @@ func (this *IndexAction) RunPost(params struct{}) {
 	}
 	this.Data["countWeakAdmins"] = countWeakAdminsResp.Count
 
+	// 数据库磁盘容量提示
+	this.Data["dbDiskWarningDays"] = -1
+	if configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeSetting) {
+		growthStore, err := settingutils.LoadDBGrowthStore(this.AdminContext(), this.RPC())
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		days, shouldWarn := growthStore.ShouldWarn()
+		if shouldWarn {
+			this.Data["dbDiskWarningDays"] = days
+		}
+	}
+
 	this.Success()
 }
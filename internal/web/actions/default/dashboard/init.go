@@ -2,6 +2,7 @@ package dashboard
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/dashboard/custom"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
 	"github.com/iwind/TeaGo"
 )
@@ -13,6 +14,18 @@ func init() {
 			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeCommon)).
 			GetPost("", new(IndexAction)).
 			Post("/restartLocalAPINode", new(RestartLocalAPINodeAction)).
+
+			// 自定义看板
+			Prefix("/dashboard/custom").
+			Get("", new(custom.IndexAction)).
+			GetPost("/createPopup", new(custom.CreatePopupAction)).
+			GetPost("/updatePopup", new(custom.UpdatePopupAction)).
+			Post("/delete", new(custom.DeleteAction)).
+			Get("/board", new(custom.BoardAction)).
+			Post("/data", new(custom.DataAction)).
+			GetPost("/widgetPopup", new(custom.WidgetPopupAction)).
+			Post("/widgetDelete", new(custom.WidgetDeleteAction)).
+			Post("/widgetMove", new(custom.WidgetMoveAction)).
 			EndAll()
 	})
 }
@@ -36,6 +36,7 @@ func init() {
 			Post("/delete", new(providers.DeleteAction)).
 			Get("/provider", new(providers.ProviderAction)).
 			Post("/syncDomains", new(providers.SyncDomainsAction)).
+			Post("/test", new(providers.TestAction)).
 			EndData().
 
 			// 域名
@@ -5,6 +5,7 @@
 package providers
 
 import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/dnsclients"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -36,7 +37,7 @@ func (this *CreatePopupAction) RunGet(params struct{}) {
 			"description": t.Description,
 		})
 	}
-	this.Data["types"] = typeMaps
+	this.Data["types"] = mergeLocalProviderTypes(typeMaps)
 
 	// 自动生成CustomHTTP私钥
 	this.Data["paramCustomHTTPSecret"] = rands.HexString(32)
@@ -78,6 +79,14 @@ func (this *CreatePopupAction) RunPost(params struct {
 	ParamCustomHTTPURL    string
 	ParamCustomHTTPSecret string
 
+	// RFC 2136
+	ParamRFC2136Server        string
+	ParamRFC2136Zone          string
+	ParamRFC2136Network       string
+	ParamRFC2136TSIGKeyName   string
+	ParamRFC2136TSIGAlgorithm string
+	ParamRFC2136TSIGSecret    string
+
 	// EdgeDNS API
 	ParamEdgeDNSAPIRole            string
 	ParamEdgeDNSAPIHost            string
@@ -175,6 +184,8 @@ func (this *CreatePopupAction) RunPost(params struct {
 			Require("请输入私钥")
 		apiParams["url"] = params.ParamCustomHTTPURL
 		apiParams["secret"] = params.ParamCustomHTTPSecret
+	case dnsclients.ProviderTypeRFC2136:
+		apiParams = buildRFC2136Params(params.Must, params.ParamRFC2136Server, params.ParamRFC2136Zone, params.ParamRFC2136Network, params.ParamRFC2136TSIGKeyName, params.ParamRFC2136TSIGAlgorithm, params.ParamRFC2136TSIGSecret)
 	case "dnsla":
 		params.Must.
 			Field("paramDNSLaAPIId", params.ParamDNSLaAPIId).
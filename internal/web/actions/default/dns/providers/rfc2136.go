@@ -0,0 +1,57 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package providers
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/dnsclients"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// 合并在管理平台中实现的服务商类型
+func mergeLocalProviderTypes(typeMaps []maps.Map) []maps.Map {
+	for _, providerType := range dnsclients.AllProviderTypes() {
+		var exists = false
+		for _, typeMap := range typeMaps {
+			if typeMap.GetString("code") == providerType.Code {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+		typeMaps = append(typeMaps, maps.Map{
+			"name":        providerType.Name,
+			"code":        providerType.Code,
+			"description": providerType.Description,
+		})
+	}
+	return typeMaps
+}
+
+// 检查并构造RFC 2136参数
+func buildRFC2136Params(must *actions.Must, server string, zone string, network string, tsigKeyName string, tsigAlgorithm string, tsigSecret string) maps.Map {
+	must.
+		Field("paramRFC2136Server", server).
+		Require("请输入DNS服务器地址").
+		Field("paramRFC2136Zone", zone).
+		Require("请输入区域名称")
+
+	if len(tsigKeyName) > 0 {
+		must.
+			Field("paramRFC2136TSIGSecret", tsigSecret).
+			Require("请输入TSIG密钥")
+	}
+
+	return maps.Map{
+		"server":        strings.TrimSpace(server),
+		"zone":          strings.TrimSpace(zone),
+		"network":       network,
+		"tsigKeyName":   strings.TrimSpace(tsigKeyName),
+		"tsigAlgorithm": tsigAlgorithm,
+		"tsigSecret":    strings.TrimSpace(tsigSecret),
+	}
+}
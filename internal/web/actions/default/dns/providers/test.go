@@ -0,0 +1,92 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/dnsclients"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// TestAction 测试服务商的连接和权限
+type TestAction struct {
+	actionutils.ParentAction
+}
+
+func (this *TestAction) RunPost(params struct {
+	ProviderId int64
+	Type       string
+
+	// RFC 2136
+	ParamRFC2136Server        string
+	ParamRFC2136Zone          string
+	ParamRFC2136Network       string
+	ParamRFC2136TSIGKeyName   string
+	ParamRFC2136TSIGAlgorithm string
+	ParamRFC2136TSIGSecret    string
+
+	Must *actions.Must
+}) {
+	var provider = dnsclients.FindProvider(params.Type)
+	if provider == nil {
+		this.Fail("此服务商暂时不支持在这里测试")
+		return
+	}
+
+	var apiParams maps.Map
+	switch params.Type {
+	case dnsclients.ProviderTypeRFC2136:
+		apiParams = buildRFC2136Params(params.Must, params.ParamRFC2136Server, params.ParamRFC2136Zone, params.ParamRFC2136Network, params.ParamRFC2136TSIGKeyName, params.ParamRFC2136TSIGAlgorithm, params.ParamRFC2136TSIGSecret)
+
+		// 修改时密钥可能是被隐藏的，需要读取原有密钥
+		if params.ProviderId > 0 && strings.Contains(apiParams.GetString("tsigSecret"), "*") {
+			providerResp, err := this.RPC().DNSProviderRPC().FindEnabledDNSProvider(this.AdminContext(), &pb.FindEnabledDNSProviderRequest{
+				DnsProviderId: params.ProviderId,
+				MaskParams:    false,
+			})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			if providerResp.DnsProvider != nil && len(providerResp.DnsProvider.ApiParamsJSON) > 0 {
+				var oldParams = maps.Map{}
+				err = json.Unmarshal(providerResp.DnsProvider.ApiParamsJSON, &oldParams)
+				if err != nil {
+					this.ErrorPage(err)
+					return
+				}
+				apiParams["tsigSecret"] = oldParams.GetString("tsigSecret")
+			}
+		}
+	}
+
+	err := provider.Auth(apiParams)
+	if err != nil {
+		this.Fail("参数错误：" + err.Error())
+		return
+	}
+
+	var connectivityError = ""
+	var permissionError = ""
+	err = provider.CheckConnectivity()
+	if err != nil {
+		connectivityError = err.Error()
+	} else {
+		err = provider.CheckPermission()
+		if err != nil {
+			permissionError = err.Error()
+		}
+	}
+
+	this.Data["connectivityOk"] = len(connectivityError) == 0
+	this.Data["connectivityError"] = connectivityError
+	this.Data["permissionOk"] = len(connectivityError) == 0 && len(permissionError) == 0
+	this.Data["permissionError"] = permissionError
+
+	this.Success()
+}
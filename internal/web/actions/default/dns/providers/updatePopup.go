@@ -5,6 +5,7 @@ package providers
 import (
 	"encoding/json"
 
+	"github.com/TeaOSLab/EdgeAdmin/internal/dnsclients"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -69,7 +70,7 @@ func (this *UpdatePopupAction) RunGet(params struct {
 			"description": t.Description,
 		})
 	}
-	this.Data["types"] = typeMaps
+	this.Data["types"] = mergeLocalProviderTypes(typeMaps)
 
 	// EdgeDNS集群列表
 	this.Data["nsClusters"] = []maps.Map{}
@@ -110,6 +111,14 @@ func (this *UpdatePopupAction) RunPost(params struct {
 	ParamCustomHTTPURL    string
 	ParamCustomHTTPSecret string
 
+	// RFC 2136
+	ParamRFC2136Server        string
+	ParamRFC2136Zone          string
+	ParamRFC2136Network       string
+	ParamRFC2136TSIGKeyName   string
+	ParamRFC2136TSIGAlgorithm string
+	ParamRFC2136TSIGSecret    string
+
 	// EdgeDNS API
 	ParamEdgeDNSAPIHost            string
 	ParamEdgeDNSAPIRole            string
@@ -205,6 +214,8 @@ func (this *UpdatePopupAction) RunPost(params struct {
 			Require("请输入私钥")
 		apiParams["url"] = params.ParamCustomHTTPURL
 		apiParams["secret"] = params.ParamCustomHTTPSecret
+	case dnsclients.ProviderTypeRFC2136:
+		apiParams = buildRFC2136Params(params.Must, params.ParamRFC2136Server, params.ParamRFC2136Zone, params.ParamRFC2136Network, params.ParamRFC2136TSIGKeyName, params.ParamRFC2136TSIGAlgorithm, params.ParamRFC2136TSIGSecret)
 	default:
 		this.Fail("暂时不支持此服务商'" + params.Type + "'")
 	}
@@ -0,0 +1,50 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package index
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/types"
+)
+
+// CaptchaAction 登录验证码图片
+type CaptchaAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CaptchaAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *CaptchaAction) RunGet(params struct {
+	Id string
+}) {
+	var buf = &bytes.Buffer{}
+	if !loginlimitutils.SharedCaptchaStore.WriteImage(buf, params.Id, time.Now()) {
+		this.ResponseWriter.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	this.AddHeader("Content-Type", "image/png")
+	this.AddHeader("Content-Length", types.String(buf.Len()))
+	this.AddHeader("Cache-Control", "no-store")
+	_, _ = this.Write(buf.Bytes())
+}
+
+// RunPost 更换验证码
+func (this *CaptchaAction) RunPost(params struct {
+	Id string
+}) {
+	newId, ok := loginlimitutils.SharedCaptchaStore.Renew(params.Id, time.Now())
+	if !ok {
+		this.Fail("验证码已过期，请刷新页面后重试")
+		return
+	}
+	this.Data["captchaId"] = newId
+	this.Success()
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/TeaOSLab/EdgeCommon/pkg/systemconfigs"
 	"github.com/iwind/TeaGo/actions"
 	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/maps"
 	"github.com/iwind/TeaGo/rands"
 	"github.com/iwind/TeaGo/types"
 	stringutil "github.com/iwind/TeaGo/utils/string"
@@ -121,6 +122,45 @@ func (this *IndexAction) RunGet(params struct {
 		this.Data["rememberLogin"] = securityConfig.AllowRememberLogin
 	}
 
+	// LDAP认证需要使用原始密码
+	ldapConfig, err := configloaders.LoadAdminLDAPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["ldapIsOn"] = ldapConfig.IsOn
+
+	// 单点登录
+	ssoConfig, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var ssoName = ssoConfig.Name
+	if len(ssoName) == 0 {
+		ssoName = "单点登录"
+	}
+	this.Data["sso"] = maps.Map{
+		"isOn": ssoConfig.IsOn,
+		"name": ssoName,
+	}
+
+	// 使用安全密钥无密码登录
+	webAuthnPolicy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["webAuthnPasswordless"] = webAuthnPolicy.AllowPasswordless
+
+	// 登录保护
+	limitConfig, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["captchaId"] = this.findCaptchaId(limitConfig)
+
 	// 删除Cookie
 	loginutils.UnsetCookie(this.Object())
 
@@ -142,11 +182,14 @@ func (this *IndexAction) RunGet(params struct {
 
 // RunPost 提交
 func (this *IndexAction) RunPost(params struct {
-	Token    string
-	Username string
-	Password string
-	OtpCode  string
-	Remember bool
+	Token       string
+	Username    string
+	Password    string
+	RawPassword string // 启用LDAP时使用的原始密码
+	OtpCode     string
+	Remember    bool
+	CaptchaId   string
+	CaptchaCode string
 
 	Must *actions.Must
 	Auth *helpers.UserShouldAuth
@@ -185,60 +228,132 @@ func (this *IndexAction) RunPost(params struct {
 		this.Fail("服务器出了点小问题：" + err.Error())
 		return
 	}
-	resp, err := rpcClient.AdminRPC().LoginAdmin(rpcClient.Context(0), &pb.LoginAdminRequest{
-		Username: params.Username,
-		Password: params.Password,
-	})
 
+	// 登录保护
+	limitConfig, err := configloaders.LoadAdminLoginLimitConfig()
 	if err != nil {
-		err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(0), oplogs.LevelError, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogSystemError, err.Error()), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogSystemError, []any{err.Error()})
-		if err != nil {
-			utils.PrintError(err)
-		}
-
-		actionutils.Fail(this, err)
+		this.Fail("服务器出了点小问题：" + err.Error())
 		return
 	}
-
-	if !resp.IsOk {
-		err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(0), oplogs.LevelWarn, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogFailed, params.Username), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogFailed, []any{params.Username})
-		if err != nil {
-			utils.PrintError(err)
-		}
-
-		this.Fail("请输入正确的用户名密码")
+	var currentIP = loginutils.RemoteIP(&this.ActionObject)
+	var attempt = startLoginAttempt(&this.ParentAction, limitConfig, currentIP, params.Username, true, params.CaptchaId, params.CaptchaCode)
+	if attempt == nil {
 		return
 	}
-	var adminId = resp.AdminId
+	defer attempt.Release()
 
-	// 检查是否支持OTP
-	checkOTPResp, err := this.RPC().AdminRPC().CheckAdminOTPWithUsername(this.AdminContext(), &pb.CheckAdminOTPWithUsernameRequest{Username: params.Username})
+	// LDAP认证
+	ldapConfig, err := configloaders.LoadAdminLDAPConfig()
 	if err != nil {
-		this.ErrorPage(err)
+		this.Fail("服务器出了点小问题：" + err.Error())
 		return
 	}
-	var requireOTP = checkOTPResp.RequireOTP
-	this.Data["requireOTP"] = requireOTP
-	if requireOTP {
-		this.Data["remember"] = params.Remember
+	var adminId int64
+	var username = params.Username
+	if ldapConfig.IsOn {
+		admin, denyMessage := this.loginLDAP(ldapConfig, params.Username, params.RawPassword)
+		if len(denyMessage) > 0 {
+			attempt.Fail()
+			this.Fail(denyMessage)
+			return
+		}
+		if admin != nil {
+			adminId = admin.Id
+			username = admin.Username
+		}
+	}
 
-		var sid = this.Session().Sid
-		this.Data["sid"] = sid
-		_, err = this.RPC().LoginSessionRPC().WriteLoginSessionValue(this.AdminContext(), &pb.WriteLoginSessionValueRequest{
-			Sid:   sid + "_otp",
-			Key:   "adminId",
-			Value: types.String(adminId),
+	// 本地密码
+	if adminId == 0 {
+		resp, err := rpcClient.AdminRPC().LoginAdmin(rpcClient.Context(0), &pb.LoginAdminRequest{
+			Username: params.Username,
+			Password: params.Password,
 		})
+
+		if err != nil {
+			err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(0), oplogs.LevelError, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogSystemError, err.Error()), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogSystemError, []any{err.Error()})
+			if err != nil {
+				utils.PrintError(err)
+			}
+
+			actionutils.Fail(this, err)
+			return
+		}
+
+		if !resp.IsOk {
+			err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(0), oplogs.LevelWarn, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogFailed, params.Username), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogFailed, []any{params.Username})
+			if err != nil {
+				utils.PrintError(err)
+			}
+
+			attempt.Fail()
+			this.Fail("请输入正确的用户名密码")
+			return
+		}
+		adminId = resp.AdminId
+
+		// 启用LDAP或者单点登录后，只有超级管理员可以使用本地密码登录，以便在LDAP或身份提供商不可用时维护系统
+		ssoConfig, err := configloaders.LoadAdminSSOConfig()
 		if err != nil {
 			this.ErrorPage(err)
 			return
 		}
+		if ldapConfig.IsOn || (ssoConfig.IsOn && ssoConfig.DisableLocalPassword) {
+			adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			if adminResp.Admin == nil || !adminResp.Admin.IsSuper {
+				attempt.Fail()
+				if ldapConfig.IsOn {
+					this.Fail("请输入正确的用户名密码")
+				} else {
+					this.Fail("已启用单点登录，请使用单点登录")
+				}
+				return
+			}
+		}
+
+		// 检查密码是否已过期，过期的密码需要在登录后立即修改
+		passwordExpired, err := configloaders.IsAdminPasswordExpired(adminId)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if passwordExpired {
+			this.Session().Write(teaconst.SessionPasswordExpired, "1")
+		}
+	}
+
+	// 第二步验证，需要继续验证时只释放预留的登录尝试，在第二步验证通过后才清除失败记录
+	secondFactor, denyMessage, err := loginutils.PrepareSecondFactor(this.AdminContext(), rpcClient, adminId, this.Session().Sid, params.Remember)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if len(denyMessage) > 0 {
+		this.Fail(denyMessage)
+		return
+	}
+	if len(secondFactor.WebAuthnToken) > 0 {
+		this.Data["requireWebAuthn"] = true
+		this.Data["webAuthnToken"] = secondFactor.WebAuthnToken
+		this.Success()
+		return
+	}
+	this.Data["requireOTP"] = len(secondFactor.OTPSid) > 0
+	if len(secondFactor.OTPSid) > 0 {
+		this.Data["remember"] = params.Remember
+		this.Data["sid"] = secondFactor.OTPSid
 		this.Success()
 		return
 	}
 
+	// 所有验证都已通过，清除用户名的失败记录
+	attempt.Succeed("")
+
 	// 写入SESSION
-	var currentIP = loginutils.RemoteIP(&this.ActionObject)
 	var localSid = rands.HexString(32)
 	this.Data["localSid"] = localSid
 	this.Data["ip"] = currentIP
@@ -255,7 +370,7 @@ func (this *IndexAction) RunPost(params struct {
 	}
 
 	// 记录日志
-	err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(adminId), oplogs.LevelInfo, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogSuccess, params.Username), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogSuccess, []any{params.Username})
+	err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(adminId), oplogs.LevelInfo, this.Request.URL.Path, langs.DefaultMessage(codes.AdminLogin_LogSuccess, username), loginutils.RemoteIP(&this.ActionObject), codes.AdminLogin_LogSuccess, []any{username})
 	if err != nil {
 		this.ErrorPage(err)
 		return
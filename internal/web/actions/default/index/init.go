@@ -10,6 +10,8 @@ func init() {
 			Prefix("").
 			GetPost("/", new(IndexAction)).
 			GetPost("/index/otp", new(OtpAction)).
+			GetPost("/index/webauthn", new(WebauthnAction)).
+			GetPost("/index/captcha", new(CaptchaAction)).
 			GetPost("/initPassword", new(InitPasswordAction)).
 			EndAll()
 	})
@@ -5,6 +5,8 @@ package index
 import (
 	"net/http"
 
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/TeaOSLab/EdgeCommon/pkg/systemconfigs"
@@ -72,6 +74,13 @@ func (this *InitPasswordAction) RunPost(params struct {
 	}
 	var adminId = adminResp.Admin.Id
 
+	// 密码策略
+	err = configloaders.CheckPassword(passwordpolicyutils.KindAdmin, adminId, params.Username, params.Password)
+	if err != nil {
+		this.FailField("password", err.Error())
+		return
+	}
+
 	// 修改密码
 	_, err = this.RPC().AdminRPC().UpdateAdminLogin(this.AdminContext(), &pb.UpdateAdminLoginRequest{
 		AdminId:  adminId,
@@ -82,6 +91,11 @@ func (this *InitPasswordAction) RunPost(params struct {
 		this.ErrorPage(err)
 		return
 	}
+	err = configloaders.RecordPasswordChange(passwordpolicyutils.KindAdmin, adminId, params.Password)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
 
 	// 修改为初始化完成
 	_, err = this.RPC().SysSettingRPC().UpdateSysSetting(this.AdminContext(), &pb.UpdateSysSettingRequest{
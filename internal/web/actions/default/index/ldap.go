@@ -0,0 +1,119 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package index
+
+import (
+	"fmt"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/admins/adminutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/langs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// 通过LDAP认证管理员
+// 认证通过时返回对应的管理员；denyMessage 不为空表示认证通过但是不允许登录；
+// 其他情况（密码错误或者LDAP服务不可用）返回nil，由调用者继续尝试本地密码
+func (this *IndexAction) loginLDAP(config *ldaputils.Config, username string, password string) (admin *pb.Admin, denyMessage string) {
+	identity, err := ldaputils.Authenticate(config, ldaputils.SharedGroupCache, username, password)
+	if err != nil {
+		if err != ldaputils.ErrInvalidCredentials {
+			this.logLDAP(oplogs.LevelError, "LDAP认证失败：%s", err.Error())
+		}
+		return nil, ""
+	}
+
+	admin, err = this.findLDAPAdmin(config, identity)
+	if err != nil {
+		this.logLDAP(oplogs.LevelWarn, "LDAP用户'%s'登录失败：%s", identity.Username, err.Error())
+		return nil, err.Error()
+	}
+	return admin, ""
+}
+
+// 查找或者创建LDAP用户对应的管理员，并同步模块权限
+// 管理员和LDAP用户按DN关联；没有关联时只会在允许的情况下关联同名的非超级管理员，超级管理员需要手动关联
+func (this *IndexAction) findLDAPAdmin(config *ldaputils.Config, identity *ldaputils.Identity) (*pb.Admin, error) {
+	moduleCodes, matched := config.MapModules(identity.Groups)
+
+	links, err := configloaders.LoadAdminLDAPLinks()
+	if err != nil {
+		return nil, err
+	}
+	var admin *pb.Admin
+	var adminId = links.Find(identity.DN)
+	if adminId > 0 {
+		adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+		if err != nil {
+			return nil, err
+		}
+		admin = adminResp.Admin
+	}
+
+	// 首次登录
+	if admin == nil {
+		var username = ssoutils.NormalizeUsername(identity.Username)
+		existResp, err := this.RPC().AdminRPC().FindAdminWithUsername(this.AdminContext(), &pb.FindAdminWithUsernameRequest{Username: username})
+		if err != nil {
+			return nil, err
+		}
+		if existResp.Admin != nil {
+			if existResp.Admin.IsSuper {
+				return nil, fmt.Errorf("不能自动关联超级管理员'%s'，请在LDAP设置中手动关联", existResp.Admin.Username)
+			}
+			if !config.LinkExisting {
+				return nil, fmt.Errorf("已存在用户名为'%s'的管理员，但不允许关联已有的管理员，请在LDAP设置中手动关联", username)
+			}
+			admin = existResp.Admin
+		} else {
+			if !config.AutoCreate {
+				return nil, fmt.Errorf("找不到用户'%s'对应的管理员", identity.Username)
+			}
+			if len(config.GroupMappings) > 0 && !matched {
+				return nil, fmt.Errorf("用户'%s'所在的分组没有任何权限", identity.Username)
+			}
+			admin, err = adminutils.CreateExternalAdmin(this.Parent(), username, identity.Fullname, moduleCodes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var newAdminId = admin.Id
+		err = configloaders.UpdateAdminLDAPLinks(func(store *ldaputils.LinkStore) error {
+			store.Link(identity.DN, newAdminId)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !admin.IsOn || !admin.CanLogin {
+		return nil, fmt.Errorf("管理员'%s'已被禁用或者不允许登录", admin.Username)
+	}
+
+	// 超级管理员不受分组影响
+	if !admin.IsSuper && len(config.GroupMappings) > 0 {
+		if !matched {
+			return nil, fmt.Errorf("用户'%s'所在的分组没有任何权限", identity.Username)
+		}
+		err = adminutils.SyncAdminModules(this.Parent(), admin, moduleCodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return admin, nil
+}
+
+func (this *IndexAction) logLDAP(level string, messageCode langs.MessageCode, args ...any) {
+	err := dao.SharedLogDAO.CreateAdminLog(this.AdminContext(), level, this.Request.URL.Path, fmt.Sprintf(string(messageCode), args...), loginutils.RemoteIP(&this.ActionObject), messageCode, args)
+	if err != nil {
+		utils.PrintError(err)
+	}
+}
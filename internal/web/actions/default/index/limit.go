@@ -0,0 +1,141 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package index
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/langs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// 一次登录尝试，Check() 通过后会在限制器中预留一次尝试，每个出口都需要记为失败、成功或者释放
+type loginAttempt struct {
+	action   *actionutils.ParentAction
+	config   *loginlimitutils.Config
+	ip       string
+	username string
+	isDone   bool
+}
+
+// 登录前检查失败次数限制，不允许登录时直接返回错误，返回的尝试需要在结束时调用 Release()
+// captcha 为false时不检查验证码，用于输入密码之后的第二步验证
+func startLoginAttempt(action *actionutils.ParentAction, config *loginlimitutils.Config, ip string, username string, captcha bool, captchaId string, captchaCode string) *loginAttempt {
+	var now = time.Now()
+	var decision = loginlimitutils.SharedLimiter.Check(config, ip, username, now)
+	if decision.Lockout != nil {
+		var minutes = int(math.Ceil(decision.Lockout.LockedUntil.Sub(now).Minutes()))
+		action.Fail(fmt.Sprintf("登录失败次数过多，已被暂时锁定，请在%d分钟后重试或者联系管理员解锁", minutes))
+		return nil
+	}
+	if decision.RetryAfter > 0 {
+		var seconds = int(math.Ceil(decision.RetryAfter.Seconds()))
+		action.Fail(fmt.Sprintf("登录失败次数过多，请在%d秒后重试", seconds))
+		return nil
+	}
+	if captcha && decision.RequireCaptcha {
+		if len(captchaCode) == 0 || !loginlimitutils.SharedCaptchaStore.Verify(captchaId, captchaCode, now) {
+			loginlimitutils.SharedLimiter.Release(config, ip, username)
+			action.Data["captchaId"] = loginlimitutils.SharedCaptchaStore.New(now)
+			action.Fail("请输入正确的验证码")
+			return nil
+		}
+	}
+	return &loginAttempt{
+		action:   action,
+		config:   config,
+		ip:       ip,
+		username: username,
+	}
+}
+
+// Fail 记录一次登录失败，并在锁定时通知管理员
+func (this *loginAttempt) Fail() {
+	if this.isDone {
+		return
+	}
+	this.isDone = true
+
+	var now = time.Now()
+	var lockouts = loginlimitutils.SharedLimiter.Fail(this.config, this.ip, this.username, now)
+	for _, lockout := range lockouts {
+		var kindName = "IP"
+		if lockout.Kind == loginlimitutils.KindUsername {
+			kindName = "用户名"
+		}
+
+		var messageCode langs.MessageCode = "登录失败次数过多，%s '%s' 已被锁定到 %s，可以在“系统用户 - 登录锁定”中解锁"
+		var args = []any{kindName, lockout.Value, timeutil.Format("Y-m-d H:i:s", lockout.LockedUntil)}
+		var message = fmt.Sprintf(string(messageCode), args...)
+		err := dao.SharedLogDAO.CreateAdminLog(this.action.AdminContext(), oplogs.LevelError, this.action.Request.URL.Path, message, this.ip, messageCode, args)
+		if err != nil {
+			utils.PrintError(err)
+		}
+
+		// 在管理员异常活动中提醒
+		var adminName = ""
+		if lockout.Kind == loginlimitutils.KindUsername {
+			adminName = lockout.Value
+		}
+		err = configloaders.UpdateAdminActivityState(func(state *activityutils.State) error {
+			state.AddAlert(&activityutils.Alert{
+				Kind:      activityutils.AlertKindLoginLockout,
+				AdminName: adminName,
+				Message:   message,
+				IP:        this.ip,
+				CreatedAt: now.Unix(),
+			})
+			return nil
+		})
+		if err != nil {
+			utils.PrintError(err)
+		}
+	}
+
+	// 下次登录需要验证码
+	if loginlimitutils.SharedLimiter.RequireCaptcha(this.config, this.ip, this.username, now) {
+		this.action.Data["captchaId"] = loginlimitutils.SharedCaptchaStore.New(now)
+	}
+}
+
+// Succeed 完成所有验证之后清除用户名的失败记录
+// username 为验证后确定的用户名，用于无密码登录等开始时不知道用户名的情况
+func (this *loginAttempt) Succeed(username string) {
+	if this.isDone {
+		return
+	}
+	this.isDone = true
+
+	if len(username) == 0 {
+		username = this.username
+	}
+	loginlimitutils.SharedLimiter.Succeed(this.config, this.ip, username)
+}
+
+// Release 释放没有记为失败或者成功的预留，比如服务器错误或者需要继续第二步验证，不计为失败
+func (this *loginAttempt) Release() {
+	if this.isDone {
+		return
+	}
+	this.isDone = true
+	loginlimitutils.SharedLimiter.Release(this.config, this.ip, this.username)
+}
+
+// 登录页面是否需要显示验证码
+func (this *IndexAction) findCaptchaId(config *loginlimitutils.Config) string {
+	var now = time.Now()
+	if loginlimitutils.SharedLimiter.RequireCaptcha(config, loginutils.RemoteIP(&this.ActionObject), "", now) {
+		return loginlimitutils.SharedCaptchaStore.New(now)
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package loginutils
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/rands"
+	"github.com/iwind/TeaGo/types"
+)
+
+var errInvalidSid = errors.New("invalid session id")
+
+// SecondFactor 通过密码、LDAP、单点登录或登录票据验证后，还需要进行的第二步验证
+type SecondFactor struct {
+	WebAuthnToken string // 不为空表示需要使用安全密钥验证
+	OTPSid        string // 不为空表示需要使用OTP动态密码验证
+	Remember      bool
+}
+
+// IsRequired 是否需要第二步验证
+func (this *SecondFactor) IsRequired() bool {
+	return len(this.WebAuthnToken) > 0 || len(this.OTPSid) > 0
+}
+
+// URL 第二步验证页面的地址，from 为验证通过后跳转的地址
+func (this *SecondFactor) URL(from string) string {
+	if len(this.WebAuthnToken) > 0 {
+		return "/index/webauthn?token=" + this.WebAuthnToken + "&from=" + url.QueryEscape(from)
+	}
+	if len(this.OTPSid) > 0 {
+		var remember = "0"
+		if this.Remember {
+			remember = "1"
+		}
+		return "/index/otp?sid=" + this.OTPSid + "&remember=" + remember + "&from=" + url.QueryEscape(from)
+	}
+	return from
+}
+
+// PrepareSecondFactor 检查管理员登录是否需要第二步验证，所有的登录方式都需要经过此检查
+// 安全密钥（WebAuthn）优先于OTP；sid 为当前会话ID，用来保存OTP验证的状态；denyMessage 不为空表示不允许登录
+func PrepareSecondFactor(ctx context.Context, rpcClient *rpc.RPCClient, adminId int64, sid string, remember bool) (factor *SecondFactor, denyMessage string, err error) {
+	factor = &SecondFactor{Remember: remember}
+
+	adminResp, err := rpcClient.AdminRPC().FindEnabledAdmin(ctx, &pb.FindEnabledAdminRequest{AdminId: adminId})
+	if err != nil {
+		return nil, "", err
+	}
+	var admin = adminResp.Admin
+	if admin == nil {
+		return nil, "管理员不存在或者已被禁用", nil
+	}
+
+	// 安全密钥
+	store, err := configloaders.LoadAdminWebAuthnStore()
+	if err != nil {
+		return nil, "", err
+	}
+	if store.HasCredentials(adminId) {
+		var challenge = &webauthnutils.LoginChallenge{
+			Token:     rands.HexString(32),
+			AdminId:   adminId,
+			Challenge: webauthnutils.NewChallenge(),
+			Remember:  remember,
+		}
+		webauthnutils.SharedChallengeStore.Put(challenge, time.Now())
+		factor.WebAuthnToken = challenge.Token
+		return factor, "", nil
+	}
+
+	// 超级管理员是否必须使用安全密钥
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		return nil, "", err
+	}
+	if policy.RequireForSuperAdmins && admin.IsSuper {
+		return nil, "超级管理员必须使用安全密钥登录，当前账号尚未注册安全密钥，请联系其他超级管理员协助处理", nil
+	}
+
+	// OTP
+	checkOTPResp, err := rpcClient.AdminRPC().CheckAdminOTPWithUsername(ctx, &pb.CheckAdminOTPWithUsernameRequest{Username: admin.Username})
+	if err != nil {
+		return nil, "", err
+	}
+	if checkOTPResp.RequireOTP {
+		if len(sid) == 0 {
+			return nil, "", errInvalidSid
+		}
+		_, err = rpcClient.LoginSessionRPC().WriteLoginSessionValue(ctx, &pb.WriteLoginSessionValueRequest{
+			Sid:   sid + "_otp",
+			Key:   "adminId",
+			Value: types.String(adminId),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		factor.OTPSid = sid
+	}
+	return factor, "", nil
+}
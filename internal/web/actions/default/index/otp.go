@@ -110,6 +110,28 @@ func (this *OtpAction) RunPost(params struct {
 	}
 	var adminId = session.AdminId
 
+	// 登录保护，和输入密码共用失败次数限制
+	adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if adminResp.Admin == nil {
+		this.Fail("参数错误，请重新登录（003）")
+		return
+	}
+	limitConfig, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var currentIP = loginutils.RemoteIP(&this.ActionObject)
+	var attempt = startLoginAttempt(&this.ParentAction, limitConfig, currentIP, adminResp.Admin.Username, false, "", "")
+	if attempt == nil {
+		return
+	}
+	defer attempt.Release()
+
 	// 检查OTP
 	otpLoginResp, err := this.RPC().LoginRPC().FindEnabledLogin(this.AdminContext(), &pb.FindEnabledLoginRequest{
 		AdminId: adminId,
@@ -128,15 +150,17 @@ func (this *OtpAction) RunPost(params struct {
 		}
 		var secret = loginParams.GetString("secret")
 		if gotp.NewDefaultTOTP(secret).Now() != params.OtpCode {
+			attempt.Fail()
 			this.FailField("otpCode", "请输入正确的OTP动态密码")
 			return
 		}
 	}
+	attempt.Succeed("")
 
 	// 写入SESSION
 	var localSid = rands.HexString(32)
 	this.Data["localSid"] = localSid
-	this.Data["ip"] = loginutils.RemoteIP(&this.ActionObject)
+	this.Data["ip"] = currentIP
 	params.Auth.StoreAdmin(adminId, params.Remember, localSid)
 
 	// 删除OTP SESSION
@@ -0,0 +1,297 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package index
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
+	"github.com/TeaOSLab/EdgeCommon/pkg/langs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/rands"
+)
+
+var errInvalidRecoveryCode = errors.New("invalid recovery code")
+
+// WebauthnAction 使用安全密钥（WebAuthn）验证身份
+type WebauthnAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WebauthnAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *WebauthnAction) RunGet(params struct {
+	Token        string
+	From         string
+	Passwordless bool
+}) {
+	// 检查系统是否已经配置过
+	if !setup.IsConfigured() {
+		this.RedirectURL("/setup")
+		return
+	}
+
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	store, err := configloaders.LoadAdminWebAuthnStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var challenge *webauthnutils.LoginChallenge
+	if params.Passwordless {
+		if !policy.AllowPasswordless {
+			this.RedirectURL("/?from=" + params.From)
+			return
+		}
+		challenge = &webauthnutils.LoginChallenge{
+			Token:     rands.HexString(32),
+			Challenge: webauthnutils.NewChallenge(),
+		}
+		webauthnutils.SharedChallengeStore.Put(challenge, time.Now())
+	} else {
+		challenge = webauthnutils.SharedChallengeStore.Get(params.Token, time.Now())
+		if challenge == nil {
+			this.RedirectURL("/?from=" + params.From)
+			return
+		}
+	}
+
+	// 已注册的认证器
+	var credentialIds = []string{}
+	if challenge.AdminId > 0 {
+		for _, credential := range store.FindAdminCredentials(challenge.AdminId) {
+			credentialIds = append(credentialIds, credential.Id)
+		}
+	}
+
+	var rp = webauthnutils.NewRelyingParty(this.Request, policy.RPId)
+	this.Data["token"] = challenge.Token
+	this.Data["challenge"] = challenge.Challenge
+	this.Data["rpId"] = rp.Id
+	this.Data["credentialIds"] = credentialIds
+	this.Data["passwordless"] = challenge.AdminId == 0
+	this.Data["hasRecoveryCodes"] = challenge.AdminId > 0 && store.CountRecoveryCodes(challenge.AdminId) > 0
+	this.Data["from"] = params.From
+	this.Data["isUser"] = false
+	this.Data["menu"] = "signIn"
+
+	uiConfig, err := configloaders.LoadAdminUIConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["systemName"] = uiConfig.AdminSystemName
+	this.Data["showVersion"] = uiConfig.ShowVersion
+	if len(uiConfig.Version) > 0 {
+		this.Data["version"] = uiConfig.Version
+	} else {
+		this.Data["version"] = teaconst.Version
+	}
+	this.Data["faviconFileId"] = uiConfig.FaviconFileId
+
+	this.Show()
+}
+
+func (this *WebauthnAction) RunPost(params struct {
+	Token             string
+	CredentialId      string
+	ClientDataJSON    string
+	AuthenticatorData string
+	Signature         string
+	RecoveryCode      string
+
+	Auth *helpers.UserShouldAuth
+}) {
+	var challenge = webauthnutils.SharedChallengeStore.Get(params.Token, time.Now())
+	if challenge == nil {
+		this.Fail("登录已过期，请重新登录")
+		return
+	}
+
+	// 登录保护，和输入密码共用失败次数限制；无密码登录时在验证之前不知道用户名，只限制IP
+	var username = ""
+	if challenge.AdminId > 0 {
+		challengeAdminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: challenge.AdminId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if challengeAdminResp.Admin != nil {
+			username = challengeAdminResp.Admin.Username
+		}
+	}
+	limitConfig, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var attempt = startLoginAttempt(&this.ParentAction, limitConfig, loginutils.RemoteIP(&this.ActionObject), username, false, "", "")
+	if attempt == nil {
+		return
+	}
+	defer attempt.Release()
+
+	var adminId int64
+	var isRecovery = false
+	if len(params.RecoveryCode) > 0 {
+		// 恢复码只能在输入密码之后使用
+		if challenge.AdminId <= 0 {
+			this.Fail("请先输入用户名和密码")
+			return
+		}
+		err := configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+			if !store.UseRecoveryCode(challenge.AdminId, params.RecoveryCode) {
+				return errInvalidRecoveryCode
+			}
+			return nil
+		})
+		if err != nil {
+			if err == errInvalidRecoveryCode {
+				webauthnutils.SharedChallengeStore.Fail(challenge.Token)
+				attempt.Fail()
+				this.logWebAuthn(challenge.AdminId, oplogs.LevelWarn, "管理员使用恢复码登录失败：恢复码错误或已被使用")
+				this.FailField("recoveryCode", "恢复码错误或已被使用")
+				return
+			}
+			this.ErrorPage(err)
+			return
+		}
+		adminId = challenge.AdminId
+		isRecovery = true
+	} else {
+		adminId = this.verifyAssertion(challenge, attempt, params.CredentialId, params.ClientDataJSON, params.AuthenticatorData, params.Signature)
+		if adminId <= 0 {
+			return
+		}
+	}
+
+	// 再次检查管理员状态
+	adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var admin = adminResp.Admin
+	if admin == nil || !admin.IsOn || !admin.CanLogin {
+		this.Fail("当前管理员已被禁用或者不允许登录")
+		return
+	}
+
+	webauthnutils.SharedChallengeStore.Delete(challenge.Token)
+	attempt.Succeed(admin.Username)
+
+	// 写入SESSION
+	var currentIP = loginutils.RemoteIP(&this.ActionObject)
+	var localSid = rands.HexString(32)
+	this.Data["localSid"] = localSid
+	this.Data["ip"] = currentIP
+	params.Auth.StoreAdmin(adminId, challenge.Remember, localSid)
+
+	// 清理老的SESSION
+	_, err = this.RPC().LoginSessionRPC().ClearOldLoginSessions(this.AdminContext(), &pb.ClearOldLoginSessionsRequest{
+		Sid: this.Session().Sid,
+		Ip:  currentIP,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 记录日志
+	if isRecovery {
+		this.logWebAuthn(adminId, oplogs.LevelWarn, "管理员'%s'使用恢复码登录成功", admin.Username)
+	} else {
+		this.logWebAuthn(adminId, oplogs.LevelInfo, "管理员'%s'使用安全密钥登录成功", admin.Username)
+	}
+
+	this.Success()
+}
+
+// 校验认证器返回的数据，成功时返回对应的管理员ID
+func (this *WebauthnAction) verifyAssertion(challenge *webauthnutils.LoginChallenge, attempt *loginAttempt, credentialId string, clientDataJSONString string, authDataString string, signatureString string) (adminId int64) {
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return 0
+	}
+	store, err := configloaders.LoadAdminWebAuthnStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return 0
+	}
+
+	adminId, credential := store.FindCredential(credentialId)
+	if credential == nil || (challenge.AdminId > 0 && adminId != challenge.AdminId) {
+		webauthnutils.SharedChallengeStore.Fail(challenge.Token)
+		attempt.Fail()
+		this.logWebAuthn(challenge.AdminId, oplogs.LevelWarn, "安全密钥登录失败：找不到对应的安全密钥")
+		this.Fail("此安全密钥尚未注册，请换一个再试")
+		return 0
+	}
+
+	clientDataJSON, err1 := decodeBase64URL(clientDataJSONString)
+	authData, err2 := decodeBase64URL(authDataString)
+	signature, err3 := decodeBase64URL(signatureString)
+	if err1 != nil || err2 != nil || err3 != nil {
+		this.Fail("参数错误，请重试")
+		return 0
+	}
+
+	// 无密码登录时，必须在认证器上验证用户身份（PIN或者生物识别）
+	var rp = webauthnutils.NewRelyingParty(this.Request, policy.RPId)
+	signCount, err := webauthnutils.VerifyAssertion(rp, challenge.Challenge, credential, clientDataJSON, authData, signature, challenge.AdminId == 0)
+	if err != nil {
+		webauthnutils.SharedChallengeStore.Fail(challenge.Token)
+		attempt.Fail()
+		this.logWebAuthn(adminId, oplogs.LevelWarn, "安全密钥'%s'校验失败：%s", credential.Name, err.Error())
+		this.Fail("安全密钥校验失败，请重试")
+		return 0
+	}
+
+	err = configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+		store.UpdateSignCount(adminId, credential.Id, signCount)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return 0
+	}
+	return adminId
+}
+
+func (this *WebauthnAction) logWebAuthn(adminId int64, level string, messageCode langs.MessageCode, args ...any) {
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		utils.PrintError(err)
+		return
+	}
+	err = dao.SharedLogDAO.CreateAdminLog(rpcClient.Context(adminId), level, this.Request.URL.Path, fmt.Sprintf(string(messageCode), args...), loginutils.RemoteIP(&this.ActionObject), messageCode, args)
+	if err != nil {
+		utils.PrintError(err)
+	}
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
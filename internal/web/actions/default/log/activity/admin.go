@@ -0,0 +1,125 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activity
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/log/logutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/iplibrary"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// 时间线中最多显示的操作数量
+const maxTimelineLogs = 1000
+
+// AdminAction 管理员活动时间线
+type AdminAction struct {
+	actionutils.ParentAction
+}
+
+func (this *AdminAction) Init() {
+	this.Nav("", "", "activity")
+}
+
+func (this *AdminAction) RunGet(params struct {
+	AdminId int64
+	DayFrom string
+	DayTo   string
+}) {
+	logConfig, err := configloaders.LoadLogConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["logConfig"] = logConfig
+
+	adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: params.AdminId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var admin = adminResp.Admin
+	if admin == nil {
+		this.NotFound("admin", params.AdminId)
+		return
+	}
+	this.Data["admin"] = maps.Map{
+		"id":       admin.Id,
+		"username": admin.Username,
+		"fullname": admin.Fullname,
+	}
+
+	// 默认最近7天
+	if len(params.DayFrom) == 0 && len(params.DayTo) == 0 {
+		params.DayFrom = timeutil.Format("Y-m-d", time.Now().AddDate(0, 0, -6))
+		params.DayTo = timeutil.Format("Y-m-d")
+	}
+	this.Data["dayFrom"] = params.DayFrom
+	this.Data["dayTo"] = params.DayTo
+
+	logs, isTruncated, err := logutils.ListAdminLogs(this.AdminContext(), this.RPC(), params.DayFrom, params.DayTo, 0, maxAnalyzeLogs)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 按天分组
+	var dayMaps = []maps.Map{}
+	var lastDay = ""
+	var dayLogMaps = []maps.Map{}
+	var countLogs = 0
+	for _, log := range logs {
+		if log.AdminId != params.AdminId {
+			continue
+		}
+		if countLogs >= maxTimelineLogs {
+			isTruncated = true
+			break
+		}
+		countLogs++
+
+		var day = timeutil.FormatTime("Y-m-d", log.CreatedAt)
+		if day != lastDay {
+			if len(dayLogMaps) > 0 {
+				dayMaps = append(dayMaps, maps.Map{
+					"day":  lastDay,
+					"logs": dayLogMaps,
+				})
+			}
+			lastDay = day
+			dayLogMaps = []maps.Map{}
+		}
+
+		var regionName = ""
+		var ipRegion = iplibrary.LookupIP(log.Ip)
+		if ipRegion != nil && ipRegion.IsOk() {
+			regionName = ipRegion.Summary()
+		}
+
+		dayLogMaps = append(dayLogMaps, maps.Map{
+			"id":          log.Id,
+			"time":        timeutil.FormatTime("H:i:s", log.CreatedAt),
+			"description": log.Description,
+			"level":       log.Level,
+			"ip":          log.Ip,
+			"region":      regionName,
+			"action":      log.Action,
+		})
+	}
+	if len(dayLogMaps) > 0 {
+		dayMaps = append(dayMaps, maps.Map{
+			"day":  lastDay,
+			"logs": dayLogMaps,
+		})
+	}
+	this.Data["days"] = dayMaps
+	this.Data["countLogs"] = countLogs
+	this.Data["isTruncated"] = isTruncated
+
+	this.Show()
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package activity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/log/logutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// 分析时最多读取的日志数量
+const maxAnalyzeLogs = 10000
+
+// IndexAction 管理员活动分析
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "activity")
+}
+
+func (this *IndexAction) RunGet(params struct {
+	DayFrom string
+	DayTo   string
+}) {
+	logConfig, err := configloaders.LoadLogConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["logConfig"] = logConfig
+
+	// 默认最近7天
+	if len(params.DayFrom) == 0 && len(params.DayTo) == 0 {
+		params.DayFrom = timeutil.Format("Y-m-d", time.Now().AddDate(0, 0, -6))
+		params.DayTo = timeutil.Format("Y-m-d")
+	}
+	this.Data["dayFrom"] = params.DayFrom
+	this.Data["dayTo"] = params.DayTo
+
+	logs, isTruncated, err := logutils.ListAdminLogs(this.AdminContext(), this.RPC(), params.DayFrom, params.DayTo, 0, maxAnalyzeLogs)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["isTruncated"] = isTruncated
+	this.Data["maxLogs"] = maxAnalyzeLogs
+	this.Data["countLogs"] = len(logs)
+
+	type adminStat struct {
+		adminId   int64
+		name      string
+		count     int
+		lastAt    int64
+		countries []string
+	}
+	var adminStats = map[int64]*adminStat{}
+
+	var moduleMaps = configloaders.AllModuleMaps(this.LangCode())
+	var heatmap = activityutils.NewHeatmap(nil)
+	for _, log := range logs {
+		var event = logutils.NewEvent(log)
+		heatmap.Add(logutils.FindModuleName(event, moduleMaps), event.CreatedAt)
+
+		stat, ok := adminStats[event.AdminId]
+		if !ok {
+			stat = &adminStat{
+				adminId: event.AdminId,
+				name:    event.AdminName,
+			}
+			adminStats[event.AdminId] = stat
+		}
+		stat.count++
+		if event.CreatedAt > stat.lastAt {
+			stat.lastAt = event.CreatedAt
+		}
+		if event.IsLogin() && len(event.Country) > 0 {
+			var exists = false
+			for _, country := range stat.countries {
+				if country == event.Country {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				stat.countries = append(stat.countries, event.Country)
+			}
+		}
+	}
+
+	// 热力图
+	var max = heatmap.Max()
+	var rowMaps = []maps.Map{}
+	for _, row := range heatmap.Rows() {
+		var hourMaps = []maps.Map{}
+		for hour, count := range row.Hours {
+			var level = 0
+			if count > 0 && max > 0 {
+				level = count*4/max + 1
+				if level > 4 {
+					level = 4
+				}
+			}
+			hourMaps = append(hourMaps, maps.Map{
+				"hour":  hour,
+				"count": count,
+				"level": level,
+			})
+		}
+		rowMaps = append(rowMaps, maps.Map{
+			"module": row.Module,
+			"hours":  hourMaps,
+			"total":  row.Total,
+		})
+	}
+	this.Data["heatmapRows"] = rowMaps
+
+	// 管理员
+	var stats = []*adminStat{}
+	for _, stat := range adminStats {
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].count > stats[j].count
+	})
+	var adminMaps = []maps.Map{}
+	for _, stat := range stats {
+		var countries = stat.countries
+		if countries == nil {
+			countries = []string{}
+		}
+		adminMaps = append(adminMaps, maps.Map{
+			"id":        stat.adminId,
+			"name":      stat.name,
+			"count":     stat.count,
+			"lastTime":  timeutil.FormatTime("Y-m-d H:i:s", stat.lastAt),
+			"countries": countries,
+		})
+	}
+	this.Data["admins"] = adminMaps
+
+	this.Show()
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package anomalies
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// IndexAction 管理员异常活动列表
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "", "anomalies")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	logConfig, err := configloaders.LoadLogConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["logConfig"] = logConfig
+
+	config, err := configloaders.LoadAdminActivityConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["isOn"] = config.IsOn
+
+	state, err := configloaders.LoadAdminActivityState()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["countUnread"] = state.CountUnreadAlerts()
+
+	var page = this.NewPage(int64(len(state.Alerts)))
+	this.Data["page"] = page.AsHTML()
+
+	var alertMaps = []maps.Map{}
+	for index, alert := range state.Alerts {
+		if int64(index) < page.Offset {
+			continue
+		}
+		if int64(index) >= page.Offset+page.Size {
+			break
+		}
+		alertMaps = append(alertMaps, maps.Map{
+			"id":          alert.Id,
+			"kind":        alert.Kind,
+			"kindName":    activityutils.AlertKindName(alert.Kind),
+			"adminId":     alert.AdminId,
+			"adminName":   alert.AdminName,
+			"message":     alert.Message,
+			"ip":          alert.IP,
+			"isRead":      alert.IsRead,
+			"createdTime": timeutil.FormatTime("Y-m-d H:i:s", alert.CreatedAt),
+		})
+	}
+	this.Data["alerts"] = alertMaps
+
+	this.Show()
+}
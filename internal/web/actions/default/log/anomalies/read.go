@@ -0,0 +1,29 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package anomalies
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// ReadAction 设置异常活动为已读
+type ReadAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ReadAction) RunPost(params struct {
+	AlertIds []int64 // 为空表示全部已读
+}) {
+	err := configloaders.UpdateAdminActivityState(func(state *activityutils.State) error {
+		state.MarkAlertsRead(params.AlertIds)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package anomalies
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// SettingsAction 异常活动检测设置
+type SettingsAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SettingsAction) Init() {
+	this.Nav("", "", "anomalies")
+}
+
+func (this *SettingsAction) RunGet(params struct{}) {
+	logConfig, err := configloaders.LoadLogConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["logConfig"] = logConfig
+
+	config, err := configloaders.LoadAdminActivityConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["config"] = config
+
+	this.Show()
+}
+
+func (this *SettingsAction) RunPost(params struct {
+	IsOn              bool
+	CheckNewCountry   bool
+	MassDeleteCount   int
+	MassDeleteMinutes int
+	CheckOddHours     bool
+	OddHourFrom       int
+	OddHourTo         int
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改管理员异常活动检测设置")
+
+	var config = activityutils.NewConfig()
+	config.IsOn = params.IsOn
+	config.CheckNewCountry = params.CheckNewCountry
+	config.MassDeleteCount = params.MassDeleteCount
+	config.MassDeleteMinutes = params.MassDeleteMinutes
+	config.CheckOddHours = params.CheckOddHours
+	config.OddHourFrom = params.OddHourFrom
+	config.OddHourTo = params.OddHourTo
+
+	err := configloaders.UpdateAdminActivityConfig(config)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
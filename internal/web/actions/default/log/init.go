@@ -2,6 +2,8 @@ package log
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/log/activity"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/log/anomalies"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
 	"github.com/iwind/TeaGo"
 )
@@ -18,6 +20,16 @@ func init() {
 			GetPost("/clean", new(CleanAction)).
 			GetPost("/settings", new(SettingsAction)).
 
+			// 活动分析
+			Prefix("/log/activity").
+			Get("", new(activity.IndexAction)).
+			Get("/admin", new(activity.AdminAction)).
+
+			// 异常活动
+			Prefix("/log/anomalies").
+			Get("", new(anomalies.IndexAction)).
+			Post("/read", new(anomalies.ReadAction)).
+			GetPost("/settings", new(anomalies.SettingsAction)).
 			EndAll()
 	})
 }
@@ -0,0 +1,147 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package logutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/iplibrary"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// 每次从API读取的日志数量
+const listLogsSize = 100
+
+// MaxDetectEvents 每次检测时最多分析的日志数量
+const MaxDetectEvents = 2000
+
+// NewEvent 从操作日志中提取管理员活动
+func NewEvent(log *pb.Log) *activityutils.Event {
+	var country = ""
+	var ipRegion = iplibrary.LookupIP(log.Ip)
+	if ipRegion != nil && ipRegion.IsOk() && ipRegion.CountryId() > 0 {
+		country = ipRegion.CountryName()
+	}
+
+	return &activityutils.Event{
+		LogId:       log.Id,
+		AdminId:     log.AdminId,
+		AdminName:   log.UserName,
+		Action:      log.Action,
+		Description: log.Description,
+		IP:          log.Ip,
+		Country:     country,
+		CreatedAt:   log.CreatedAt,
+	}
+}
+
+// ListAdminLogs 读取一段时间内管理员的操作日志，按照ID从大到小排列
+// 只读取ID大于 sinceLogId 的日志，最多读取 maxCount 条
+func ListAdminLogs(ctx context.Context, rpcClient *rpc.RPCClient, dayFrom string, dayTo string, sinceLogId int64, maxCount int) (result []*pb.Log, isTruncated bool, err error) {
+	var offset int64 = 0
+	for {
+		logsResp, err := rpcClient.LogRPC().ListLogs(ctx, &pb.ListLogsRequest{
+			Offset:   offset,
+			Size:     listLogsSize,
+			DayFrom:  dayFrom,
+			DayTo:    dayTo,
+			UserType: "admin",
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, log := range logsResp.Logs {
+			if log.Id <= sinceLogId {
+				return result, false, nil
+			}
+			if log.AdminId <= 0 {
+				continue
+			}
+			if len(result) >= maxCount {
+				return result, true, nil
+			}
+			result = append(result, log)
+		}
+		if len(logsResp.Logs) < listLogsSize {
+			return result, false, nil
+		}
+		offset += listLogsSize
+	}
+}
+
+// DetectAnomalies 分析最新的操作日志，发现管理员异常活动
+func DetectAnomalies(rpcClient *rpc.RPCClient) error {
+	config, err := configloaders.LoadAdminActivityConfig()
+	if err != nil {
+		return err
+	}
+	if !config.IsOn {
+		return nil
+	}
+
+	state, err := configloaders.LoadAdminActivityState()
+	if err != nil {
+		return err
+	}
+
+	var ctx = rpcClient.Context(0)
+	logs, _, err := ListAdminLogs(ctx, rpcClient, "", "", state.LastLogId, MaxDetectEvents)
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	// 从旧到新分析
+	var events = []*activityutils.Event{}
+	for i := len(logs) - 1; i >= 0; i-- {
+		events = append(events, NewEvent(logs[i]))
+	}
+
+	var alerts []*activityutils.Alert
+	err = configloaders.UpdateAdminActivityState(func(state *activityutils.State) error {
+		// 第一次启用时只建立基线，避免把历史日志都当成异常
+		if state.LastLogId == 0 {
+			state.Learn(events)
+			return nil
+		}
+		alerts = state.Detect(config, events, nil)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 记录日志
+	for _, alert := range alerts {
+		var description = "检测到管理员异常活动：" + alert.Message
+		err = dao.SharedLogDAO.CreateAdminLog(ctx, oplogs.LevelError, "/log/anomalies", description, alert.IP, "检测到管理员异常活动：%s", []any{alert.Message})
+		if err != nil {
+			return fmt.Errorf("create log failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindModuleName 根据请求路径查找所属模块名称
+func FindModuleName(event *activityutils.Event, moduleMaps []maps.Map) string {
+	if event.IsLogin() {
+		return "登录"
+	}
+	for _, moduleMap := range moduleMaps {
+		var url = moduleMap.GetString("url")
+		if len(url) > 0 && (event.Action == url || strings.HasPrefix(event.Action, url+"/")) {
+			return moduleMap.GetString("name")
+		}
+	}
+	return "其他"
+}
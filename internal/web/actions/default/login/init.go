@@ -10,6 +10,9 @@ func init() {
 			Prefix("/login").
 			GetPost("/validate", new(ValidateAction)).
 			Get("/ticket", new(TicketAction)).
+			Get("/sso", new(SsoAction)).
+			GetPost("/sso/callback", new(SsoCallbackAction)).
+			Get("/sso/metadata", new(SsoMetadataAction)).
 			EndAll()
 	})
 }
@@ -0,0 +1,86 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// SsoAction 跳转到身份提供商登录
+type SsoAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SsoAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *SsoAction) RunGet(params struct {
+	From string
+}) {
+	config, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if !config.IsOn {
+		this.RedirectURL("/")
+		return
+	}
+
+	var baseURL = config.ComposeBaseURL(this.Request)
+	var state = &ssoutils.State{
+		Value:    ssoutils.NewNonce(),
+		Redirect: ssoSafeRedirect(params.From),
+	}
+
+	switch config.Protocol {
+	case ssoutils.ProtocolOIDC:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		provider, err := ssoutils.DiscoverOIDCProvider(ctx, ssoHTTPClient, config.OIDC)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		state.Nonce = ssoutils.NewNonce()
+		state.CodeVerifier = ssoutils.NewCodeVerifier()
+		sharedSSOStateStore.Put(state)
+
+		// 回调时检查是否为同一个浏览器
+		var cookie = &http.Cookie{
+			Name:     ssoStateCookieName,
+			Value:    state.Value,
+			Path:     "/login/sso",
+			MaxAge:   int(ssoutils.StateTTL / time.Second),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		}
+		if this.Request.TLS != nil {
+			cookie.Secure = true
+		}
+		this.AddCookie(cookie)
+
+		this.RedirectURL(provider.AuthCodeURL(baseURL+ssoutils.CallbackPath, state))
+	case ssoutils.ProtocolSAML:
+		// SAML请求ID不能以数字开头
+		state.Value = "_" + state.Value
+		sharedSSOStateStore.Put(state)
+
+		var sp = ssoutils.NewSAMLServiceProvider(config.SAML, baseURL+ssoutils.MetadataPath, baseURL+ssoutils.CallbackPath)
+		authURL, err := sp.AuthnRequestURL(state.Value, "", time.Now())
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.RedirectURL(authURL)
+	default:
+		this.RedirectURL("/")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/oplogs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
+	"github.com/TeaOSLab/EdgeCommon/pkg/langs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/rands"
+)
+
+// SsoCallbackAction 身份提供商登录后回调
+// GET 为OIDC回调，POST 为SAML断言消费地址
+type SsoCallbackAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SsoCallbackAction) Init() {
+	this.Nav("", "", "")
+}
+
+// RunGet OIDC回调
+func (this *SsoCallbackAction) RunGet(params struct {
+	Code  string
+	State string
+	Error string
+
+	Auth *helpers.UserShouldAuth
+}) {
+	this.Data["redirect"] = ""
+	this.Data["secondFactorURL"] = ""
+	var errorMsg string
+
+	defer func() {
+		this.Data["errorMsg"] = errorMsg
+		this.Show()
+	}()
+
+	config, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		errorMsg = err.Error()
+		return
+	}
+	if !config.IsOn || config.Protocol != ssoutils.ProtocolOIDC {
+		errorMsg = "单点登录未启用"
+		return
+	}
+
+	if len(params.Error) > 0 {
+		errorMsg = "身份提供商返回错误：" + params.Error + " " + this.ParamString("error_description")
+		return
+	}
+
+	// 检查状态
+	cookie, err := this.Request.Cookie(ssoStateCookieName)
+	if err != nil || cookie.Value != params.State {
+		errorMsg = "登录状态不匹配，请重新登录"
+		return
+	}
+	this.AddCookie(&http.Cookie{
+		Name:     ssoStateCookieName,
+		Value:    "",
+		Path:     "/login/sso",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	state, ok := sharedSSOStateStore.Take(params.State)
+	if !ok {
+		errorMsg = "登录已过期，请重新登录"
+		return
+	}
+	this.Data["redirect"] = state.Redirect
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := ssoutils.DiscoverOIDCProvider(ctx, ssoHTTPClient, config.OIDC)
+	if err != nil {
+		errorMsg = err.Error()
+		return
+	}
+	identity, err := provider.Exchange(ctx, params.Code, config.ComposeBaseURL(this.Request)+ssoutils.CallbackPath, state)
+	if err != nil {
+		errorMsg = "校验身份失败：" + err.Error()
+		return
+	}
+
+	errorMsg = this.login(config, identity, state.Redirect, params.Auth)
+}
+
+// RunPost SAML断言消费地址
+func (this *SsoCallbackAction) RunPost(params struct {
+	Auth *helpers.UserShouldAuth
+}) {
+	this.Data["redirect"] = ""
+	this.Data["secondFactorURL"] = ""
+	var errorMsg string
+
+	defer func() {
+		this.Data["errorMsg"] = errorMsg
+		this.Show()
+	}()
+
+	config, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		errorMsg = err.Error()
+		return
+	}
+	if !config.IsOn || config.Protocol != ssoutils.ProtocolSAML {
+		errorMsg = "单点登录未启用"
+		return
+	}
+
+	var baseURL = config.ComposeBaseURL(this.Request)
+	var sp = ssoutils.NewSAMLServiceProvider(config.SAML, baseURL+ssoutils.MetadataPath, baseURL+ssoutils.CallbackPath)
+	identity, inResponseTo, err := sp.ParseResponse(this.ParamString("SAMLResponse"), time.Now())
+	if err != nil {
+		errorMsg = "校验身份失败：" + err.Error()
+		return
+	}
+
+	// 不接受身份提供商主动发起的登录
+	if len(inResponseTo) == 0 {
+		errorMsg = "不支持由身份提供商发起的登录，请从登录页面开始登录"
+		return
+	}
+	state, ok := sharedSSOStateStore.Take(inResponseTo)
+	if !ok {
+		errorMsg = "登录已过期，请重新登录"
+		return
+	}
+	this.Data["redirect"] = state.Redirect
+
+	errorMsg = this.login(config, identity, state.Redirect, params.Auth)
+}
+
+// 登录到对应的管理员，返回错误信息
+func (this *SsoCallbackAction) login(config *ssoutils.Config, identity *ssoutils.Identity, redirect string, auth *helpers.UserShouldAuth) (errorMsg string) {
+	var currentIP = loginutils.RemoteIP(&this.ActionObject)
+
+	admin, err := ssoFindAdmin(this.Parent(), config, identity)
+	if err != nil {
+		var messageCode langs.MessageCode = "单点登录失败：%s，用户：%s"
+		var args = []any{err.Error(), identity.Username}
+		logErr := dao.SharedLogDAO.CreateAdminLog(this.AdminContext(), oplogs.LevelWarn, this.Request.URL.Path, fmt.Sprintf(string(messageCode), args...), currentIP, messageCode, args)
+		if logErr != nil {
+			utils.PrintError(logErr)
+		}
+		return err.Error()
+	}
+
+	// 和密码登录一样需要通过安全密钥或OTP验证
+	secondFactor, denyMessage, err := loginutils.PrepareSecondFactor(this.AdminContext(), this.RPC(), admin.Id, this.Session().Sid, false)
+	if err != nil {
+		return err.Error()
+	}
+	if len(denyMessage) > 0 {
+		return denyMessage
+	}
+	if secondFactor.IsRequired() {
+		this.Data["secondFactorURL"] = secondFactor.URL(redirect)
+		return ""
+	}
+
+	// 写入SESSION
+	var localSid = rands.HexString(32)
+	this.Data["localSid"] = localSid
+	this.Data["ip"] = currentIP
+	auth.StoreAdmin(admin.Id, false, localSid)
+
+	// 清理老的SESSION
+	_, err = this.RPC().LoginSessionRPC().ClearOldLoginSessions(this.AdminContext(), &pb.ClearOldLoginSessionsRequest{
+		Sid: this.Session().Sid,
+		Ip:  currentIP,
+	})
+	if err != nil {
+		return err.Error()
+	}
+
+	// 记录日志
+	var messageCode langs.MessageCode = "通过单点登录成功登录系统，用户名：%s"
+	var args = []any{admin.Username}
+	err = dao.SharedLogDAO.CreateAdminLog(this.RPC().Context(admin.Id), oplogs.LevelInfo, this.Request.URL.Path, fmt.Sprintf(string(messageCode), args...), currentIP, messageCode, args)
+	if err != nil {
+		utils.PrintError(err)
+	}
+	return ""
+}
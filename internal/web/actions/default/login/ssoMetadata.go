@@ -0,0 +1,35 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// SsoMetadataAction SAML服务提供方元数据
+type SsoMetadataAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SsoMetadataAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *SsoMetadataAction) RunGet(params struct{}) {
+	config, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if !config.IsOn || config.Protocol != ssoutils.ProtocolSAML {
+		this.NotFound("sso metadata", 0)
+		return
+	}
+
+	var baseURL = config.ComposeBaseURL(this.Request)
+	var sp = ssoutils.NewSAMLServiceProvider(config.SAML, baseURL+ssoutils.MetadataPath, baseURL+ssoutils.CallbackPath)
+	this.AddHeader("Content-Type", "application/samlmetadata+xml; charset=utf-8")
+	_, _ = this.Write(sp.Metadata())
+}
@@ -24,6 +24,7 @@ func (this *TicketAction) RunGet(params struct {
 	Auth     *helpers.UserShouldAuth
 }) {
 	this.Data["redirect"] = params.Redirect
+	this.Data["secondFactorURL"] = ""
 	var errorMsg string
 
 	defer func() {
@@ -60,6 +61,21 @@ func (this *TicketAction) RunGet(params struct {
 		return
 	}
 
+	// 和密码登录一样需要通过安全密钥或OTP验证
+	secondFactor, denyMessage, err := loginutils.PrepareSecondFactor(this.AdminContext(), this.RPC(), resp.LoginTicket.AdminId, this.Session().Sid, false)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if len(denyMessage) > 0 {
+		errorMsg = denyMessage
+		return
+	}
+	if secondFactor.IsRequired() {
+		this.Data["secondFactorURL"] = secondFactor.URL(params.Redirect)
+		return
+	}
+
 	var localSid = rands.HexString(32)
 	this.Data["localSid"] = localSid
 	this.Data["ip"] = currentIP
@@ -0,0 +1,105 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/admins/adminutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// 保存尚未完成的单点登录流程
+var sharedSSOStateStore = ssoutils.NewStateStore()
+
+// 访问身份提供商使用的客户端
+var ssoHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// 保存OIDC登录状态的Cookie
+const ssoStateCookieName = "edgeSSOState"
+
+// 只允许跳转到当前系统中的地址
+func ssoSafeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		return ""
+	}
+	return redirect
+}
+
+// 根据身份提供商返回的用户信息查找或者创建管理员
+func ssoFindAdmin(action *actionutils.ParentAction, config *ssoutils.Config, identity *ssoutils.Identity) (*pb.Admin, error) {
+	moduleCodes, matched := config.MapModules(identity.Groups)
+
+	adminId, err := configloaders.FindAdminSSOLink(config.Protocol, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var admin *pb.Admin
+	if adminId > 0 {
+		adminResp, err := action.RPC().AdminRPC().FindEnabledAdmin(action.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+		if err != nil {
+			return nil, err
+		}
+		admin = adminResp.Admin
+	}
+
+	// 首次登录
+	if admin == nil {
+		var username = ssoutils.NormalizeUsername(identity.Username)
+		existResp, err := action.RPC().AdminRPC().FindAdminWithUsername(action.AdminContext(), &pb.FindAdminWithUsernameRequest{Username: username})
+		if err != nil {
+			return nil, err
+		}
+		if existResp.Admin != nil {
+			err = config.CheckLinkExisting(existResp.Admin.Username, existResp.Admin.IsSuper)
+			if err != nil {
+				return nil, err
+			}
+			admin = existResp.Admin
+		} else {
+			if !config.AutoCreate {
+				return nil, errors.New("找不到用户'" + identity.Username + "'对应的管理员")
+			}
+			if len(config.GroupMappings) > 0 && !matched {
+				return nil, errors.New("用户'" + identity.Username + "'所在的分组没有任何权限")
+			}
+
+			admin, err = adminutils.CreateExternalAdmin(action, username, identity.Fullname, moduleCodes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = configloaders.UpdateAdminSSOLink(config.Protocol, identity.Subject, admin.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !admin.IsOn || !admin.CanLogin {
+		return nil, errors.New("管理员'" + admin.Username + "'已被禁用或者不允许登录")
+	}
+
+	// 同步模块权限，超级管理员不受分组影响
+	if !admin.IsSuper && len(config.GroupMappings) > 0 {
+		if !matched {
+			return nil, errors.New("用户'" + identity.Username + "'所在的分组没有任何权限")
+		}
+
+		err = adminutils.SyncAdminModules(action, admin, moduleCodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package messages
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+)
+
+// 未读的管理员异常活动数量，只有可以查看日志的管理员才能看到
+func countUnreadAnomalies(adminId int64) (int64, error) {
+	if !configloaders.AllowModule(adminId, configloaders.AdminModuleCodeLog) {
+		return 0, nil
+	}
+	state, err := configloaders.LoadAdminActivityState()
+	if err != nil {
+		return 0, err
+	}
+	return int64(state.CountUnreadAlerts()), nil
+}
@@ -16,7 +16,14 @@ func (this *BadgeAction) RunPost(params struct{}) {
 		return
 	}
 
-	this.Data["count"] = countResp.Count
+	// 管理员异常活动
+	countAnomalies, err := countUnreadAnomalies(this.AdminId())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Data["count"] = countResp.Count + countAnomalies
 
 	this.Success()
 }
@@ -68,5 +68,13 @@ func (this *IndexAction) RunGet(params struct{}) {
 	}
 	this.Data["messages"] = messages
 
+	// 管理员异常活动
+	countAnomalies, err := countUnreadAnomalies(this.AdminId())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["countAnomalies"] = countAnomalies
+
 	this.Show()
 }
@@ -1,6 +1,8 @@
 package messages
 
 import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/activityutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -20,5 +22,17 @@ func (this *ReadAllAction) RunPost(params struct{}) {
 		return
 	}
 
+	// 管理员异常活动
+	if configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeLog) {
+		err = configloaders.UpdateAdminActivityState(func(state *activityutils.State) error {
+			state.MarkAlertsRead(nil)
+			return nil
+		})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+	}
+
 	this.Success()
 }
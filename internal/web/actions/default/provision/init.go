@@ -0,0 +1,16 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provision
+
+import "github.com/iwind/TeaGo"
+
+// 供新启动的云主机调用，使用注册令牌认证，不需要登录
+func init() {
+	TeaGo.BeforeStart(func(server *TeaGo.Server) {
+		server.
+			Prefix("/provision").
+			Get("/installer", new(InstallerAction)).
+			Post("/register", new(RegisterAction)).
+			EndAll()
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provision
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/types"
+)
+
+// InstallerAction 下载节点安装包
+type InstallerAction struct {
+	actionutils.ParentAction
+}
+
+func (this *InstallerAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *InstallerAction) RunGet(params struct {
+	Token string
+	Os    string
+	Arch  string
+}) {
+	// 检查令牌，下载时不消耗使用次数
+	store, err := clusterutils.LoadProvisionTokens(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		this.WriteString("load tokens failed")
+		return
+	}
+	_, err = store.Check(params.Token, time.Now().Unix())
+	if err != nil {
+		this.ResponseWriter.WriteHeader(http.StatusForbidden)
+		this.WriteString(err.Error())
+		return
+	}
+
+	// 检查参数，以防止路径穿越等风险
+	var nameReg = regexp.MustCompile(`^[a-z0-9]+$`)
+	if !nameReg.MatchString(params.Os) || !nameReg.MatchString(params.Arch) {
+		this.ResponseWriter.WriteHeader(http.StatusNotFound)
+		this.WriteString("file not found")
+		return
+	}
+
+	var name = "edge-node-" + params.Os + "-" + params.Arch + "-v" + teaconst.Version + ".zip"
+	fp, err := os.Open(Tea.Root + "/edge-api/deploy/" + name)
+	if err != nil {
+		this.ResponseWriter.WriteHeader(http.StatusNotFound)
+		this.WriteString("file not found")
+		return
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	stat, err := fp.Stat()
+	if err != nil {
+		this.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		this.WriteString("file can not be opened")
+		return
+	}
+
+	this.AddHeader("Content-Disposition", "attachment; filename=\""+name+"\";")
+	this.AddHeader("Content-Type", "application/zip")
+	this.AddHeader("Content-Length", types.String(stat.Size()))
+	_, _ = io.Copy(this.ResponseWriter, fp)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package provision
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/provisionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/clusterutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/index/loginutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/nodeconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/logs"
+)
+
+// RegisterAction 使用令牌注册节点，返回节点的API配置
+type RegisterAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RegisterAction) RunPost(params struct {
+	Token     string
+	ClusterId string
+	Name      string
+	Ip        string
+}) {
+	var ctx = this.AdminContext()
+	var rpcClient = this.RPC()
+
+	var name = strings.TrimSpace(params.Name)
+	if len(name) == 0 {
+		name = params.Ip
+	}
+	var ip = strings.TrimSpace(params.Ip)
+	if net.ParseIP(ip) == nil {
+		ip = ""
+	}
+	if len(name) == 0 {
+		name = loginutils.RemoteIP(&this.ActionObject)
+	}
+
+	var nodeId int64
+	var clusterId int64
+	err := clusterutils.UpdateProvisionTokens(ctx, rpcClient, func(store *provisionutils.TokenStore) error {
+		token, err := store.Check(params.Token, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+
+		// 检查集群是否一致
+		clusterResp, err := rpcClient.NodeClusterRPC().FindEnabledNodeCluster(ctx, &pb.FindEnabledNodeClusterRequest{NodeClusterId: token.ClusterId})
+		if err != nil {
+			return err
+		}
+		if clusterResp.NodeCluster == nil || clusterResp.NodeCluster.UniqueId != params.ClusterId {
+			return errors.New("cluster not match")
+		}
+		clusterId = token.ClusterId
+
+		createResp, err := rpcClient.NodeRPC().CreateNode(ctx, &pb.CreateNodeRequest{
+			Name:          name,
+			NodeClusterId: token.ClusterId,
+			NodeGroupId:   token.GroupId,
+			NodeRegionId:  token.RegionId,
+			NodeLogin:     nil,
+		})
+		if err != nil {
+			return err
+		}
+		nodeId = createResp.NodeId
+
+		_, err = store.Consume(params.Token, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		token.NodeIds = append(token.NodeIds, nodeId)
+		return nil
+	})
+	if err != nil {
+		logs.Println("[PROVISION]register node failed: " + err.Error())
+		this.ResponseWriter.WriteHeader(http.StatusForbidden)
+		this.WriteString(err.Error())
+		return
+	}
+
+	if len(ip) > 0 {
+		_, err = rpcClient.NodeIPAddressRPC().CreateNodeIPAddress(ctx, &pb.CreateNodeIPAddressRequest{
+			NodeId:    nodeId,
+			Role:      nodeconfigs.NodeRoleNode,
+			Name:      "IP地址",
+			Ip:        ip,
+			CanAccess: true,
+			IsUp:      true,
+		})
+		if err != nil {
+			logs.Println("[PROVISION]create ip address failed: " + err.Error())
+		}
+	}
+
+	nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(ctx, &pb.FindEnabledNodeRequest{NodeId: nodeId})
+	if err != nil || nodeResp.Node == nil {
+		this.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		this.WriteString("find node failed")
+		return
+	}
+
+	endpoints, err := clusterutils.FindClusterAPIEndpoints(ctx, rpcClient, clusterId)
+	if err != nil {
+		this.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		this.WriteString("find api endpoints failed")
+		return
+	}
+
+	this.AddHeader("Content-Type", "text/yaml; charset=utf-8")
+	this.WriteString("rpc.endpoints: [ \"" + strings.Join(endpoints, "\", \"") + "\" ]\n" +
+		"nodeId: \"" + nodeResp.Node.UniqueId + "\"\n" +
+		"secret: \"" + nodeResp.Node.Secret + "\"\n")
+}
@@ -1,10 +1,57 @@
 package waf
 
-import (	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/types"
 )
 
+func init() {
+	changeutils.RegisterExecutor(changeutils.KindDeleteWAFPolicy, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var firewallPolicyId int64
+		err = change.Decode(&firewallPolicyId)
+		if err != nil {
+			return err
+		}
+
+		// 提交申请后策略已被修改时不再执行
+		snapshot, err := policySnapshot(ctx, firewallPolicyId)
+		if err != nil {
+			return err
+		}
+		err = change.Check(snapshot)
+		if err != nil {
+			return err
+		}
+
+		// 审批期间可能有集群开始使用此策略
+		countResp, err := rpcClient.NodeClusterRPC().CountAllEnabledNodeClustersWithHTTPFirewallPolicyId(ctx, &pb.CountAllEnabledNodeClustersWithHTTPFirewallPolicyIdRequest{HttpFirewallPolicyId: firewallPolicyId})
+		if err != nil {
+			return err
+		}
+		if countResp.Count > 0 {
+			return errors.New("there are clusters using the firewall policy")
+		}
+
+		_, err = rpcClient.HTTPFirewallPolicyRPC().DeleteHTTPFirewallPolicy(ctx, &pb.DeleteHTTPFirewallPolicyRequest{HttpFirewallPolicyId: firewallPolicyId})
+		return err
+	})
+}
+
 type DeleteAction struct {
 	actionutils.ParentAction
 }
@@ -12,9 +59,6 @@ type DeleteAction struct {
 func (this *DeleteAction) RunPost(params struct {
 	FirewallPolicyId int64
 }) {
-	// 日志
-	defer this.CreateLogInfo(codes.WAFPolicy_LogDeleteWAFPolicy, params.FirewallPolicyId)
-
 	countResp, err := this.RPC().NodeClusterRPC().CountAllEnabledNodeClustersWithHTTPFirewallPolicyId(this.AdminContext(), &pb.CountAllEnabledNodeClustersWithHTTPFirewallPolicyIdRequest{HttpFirewallPolicyId: params.FirewallPolicyId})
 	if err != nil {
 		this.ErrorPage(err)
@@ -24,6 +68,43 @@ func (this *DeleteAction) RunPost(params struct {
 		this.Fail("此WAF策略正在被有些集群引用，请修改后再删除。")
 	}
 
+	// 是否需要审批
+	isRequired, err := changeutils.IsRequired(changeutils.KindDeleteWAFPolicy)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if isRequired {
+		firewallPolicy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(this.AdminContext(), params.FirewallPolicyId)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if firewallPolicy == nil {
+			this.NotFound("firewallPolicy", params.FirewallPolicyId)
+			return
+		}
+		change, err := approvalutils.NewSettingChange(changeutils.KindDeleteWAFPolicy, firewallPolicy, params.FirewallPolicyId)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+			Kind:    changeutils.KindDeleteWAFPolicy,
+			Title:   "删除WAF策略 " + firewallPolicy.Name,
+			Preview: []string{"WAF策略：" + firewallPolicy.Name + "（ID：" + types.String(firewallPolicy.Id) + "）"},
+		}, change)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.Success()
+		return
+	}
+
+	// 日志
+	defer this.CreateLogInfo(codes.WAFPolicy_LogDeleteWAFPolicy, params.FirewallPolicyId)
+
 	_, err = this.RPC().HTTPFirewallPolicyRPC().DeleteHTTPFirewallPolicy(this.AdminContext(), &pb.DeleteHTTPFirewallPolicyRequest{HttpFirewallPolicyId: params.FirewallPolicyId})
 	if err != nil {
 		this.ErrorPage(err)
@@ -1,9 +1,13 @@
 package waf
 
 import (
+	"context"
 	"encoding/json"
 
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -13,6 +17,46 @@ import (
 	"github.com/iwind/TeaGo/types"
 )
 
+func init() {
+	changeutils.RegisterExecutor(changeutils.KindDisableWAFPolicy, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var req = &pb.UpdateHTTPFirewallPolicyRequest{}
+		err = change.Decode(req)
+		if err != nil {
+			return err
+		}
+
+		// 提交申请后策略已被修改时不再执行，以免覆盖其他修改
+		snapshot, err := policySnapshot(ctx, req.HttpFirewallPolicyId)
+		if err != nil {
+			return err
+		}
+		err = change.Check(snapshot)
+		if err != nil {
+			return err
+		}
+
+		_, err = rpcClient.HTTPFirewallPolicyRPC().UpdateHTTPFirewallPolicy(ctx, req)
+		return err
+	})
+}
+
+// WAF策略当前的配置，提交变更申请时保存，执行前对比
+func policySnapshot(ctx context.Context, firewallPolicyId int64) (any, error) {
+	firewallPolicy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(ctx, firewallPolicyId)
+	if err != nil {
+		return nil, err
+	}
+	if firewallPolicy == nil {
+		return maps.Map{"id": firewallPolicyId, "exists": false}, nil
+	}
+	return firewallPolicy, nil
+}
+
 type UpdateAction struct {
 	actionutils.ParentAction
 }
@@ -130,9 +174,6 @@ func (this *UpdateAction) RunPost(params struct {
 
 	Must *actions.Must
 }) {
-	// 日志
-	defer this.CreateLogInfo(codes.WAFPolicy_LogUpdateWAFPolicy, params.FirewallPolicyId)
-
 	params.Must.
 		Field("name", params.Name).
 		Require("请输入策略名称")
@@ -197,7 +238,7 @@ func (this *UpdateAction) RunPost(params struct {
 		params.MaxRequestBodySize = 0
 	}
 
-	_, err = this.RPC().HTTPFirewallPolicyRPC().UpdateHTTPFirewallPolicy(this.AdminContext(), &pb.UpdateHTTPFirewallPolicyRequest{
+	var req = &pb.UpdateHTTPFirewallPolicyRequest{
 		HttpFirewallPolicyId: params.FirewallPolicyId,
 		IsOn:                 params.IsOn,
 		Name:                 params.Name,
@@ -214,7 +255,68 @@ func (this *UpdateAction) RunPost(params struct {
 		MaxRequestBodySize:   params.MaxRequestBodySize,
 		DenyCountryHTML:      params.DenyCountryHTML,
 		DenyProvinceHTML:     params.DenyProvinceHTML,
-	})
+	}
+
+	// 停用策略时是否需要审批
+	if !params.IsOn {
+		isRequired, err := changeutils.IsRequired(changeutils.KindDisableWAFPolicy)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if isRequired {
+			firewallPolicy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(this.AdminContext(), params.FirewallPolicyId)
+			if err != nil {
+				this.ErrorPage(err)
+				return
+			}
+			if firewallPolicy == nil {
+				this.NotFound("firewallPolicy", params.FirewallPolicyId)
+				return
+			}
+			if firewallPolicy.IsOn {
+				diff, err := approvalutils.Diff(maps.Map{
+					"isOn":             firewallPolicy.IsOn,
+					"name":             firewallPolicy.Name,
+					"description":      firewallPolicy.Description,
+					"mode":             firewallPolicy.Mode,
+					"useLocalFirewall": firewallPolicy.UseLocalFirewall,
+				}, maps.Map{
+					"isOn":             params.IsOn,
+					"name":             params.Name,
+					"description":      params.Description,
+					"mode":             params.Mode,
+					"useLocalFirewall": params.UseLocalFirewall,
+				})
+				if err != nil {
+					this.ErrorPage(err)
+					return
+				}
+				change, err := approvalutils.NewSettingChange(changeutils.KindDisableWAFPolicy, firewallPolicy, req)
+				if err != nil {
+					this.ErrorPage(err)
+					return
+				}
+				_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+					Kind:    changeutils.KindDisableWAFPolicy,
+					Title:   "停用WAF策略 " + firewallPolicy.Name,
+					Preview: []string{"WAF策略：" + firewallPolicy.Name + "（ID：" + types.String(firewallPolicy.Id) + "）"},
+					Diff:    diff,
+				}, change)
+				if err != nil {
+					this.ErrorPage(err)
+					return
+				}
+				this.Success()
+				return
+			}
+		}
+	}
+
+	// 日志
+	defer this.CreateLogInfo(codes.WAFPolicy_LogUpdateWAFPolicy, params.FirewallPolicyId)
+
+	_, err = this.RPC().HTTPFirewallPolicyRPC().UpdateHTTPFirewallPolicy(this.AdminContext(), req)
 	if err != nil {
 		this.ErrorPage(err)
 		return
@@ -606,5 +606,6 @@ func (this *CreateAction) RunPost(params struct {
 	// 创建日志
 	defer this.CreateLogInfo(codes.Server_LogCreateServer, createResp.ServerId)
 
+	this.Data["serverId"] = serverId
 	this.Success()
 }
@@ -3,11 +3,46 @@
 package servers
 
 import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 )
 
+func init() {
+	changeutils.RegisterExecutor(changeutils.KindDeleteServers, func(ctx context.Context, rpcClient *rpc.RPCClient, payload []byte) error {
+		var change = &approvalutils.SettingChange{}
+		err := json.Unmarshal(payload, change)
+		if err != nil {
+			return err
+		}
+		var serverIds = []int64{}
+		err = change.Decode(&serverIds)
+		if err != nil {
+			return err
+		}
+
+		// 提交申请后网站已被修改时不再执行
+		snapshot, err := changeutils.SnapshotServers(ctx, rpcClient, serverIds)
+		if err != nil {
+			return err
+		}
+		err = change.Check(snapshot)
+		if err != nil {
+			return err
+		}
+
+		_, err = rpcClient.ServerRPC().DeleteServers(ctx, &pb.DeleteServersRequest{ServerIds: serverIds})
+		return err
+	})
+}
+
 // DeleteServersAction 删除一组网站
 type DeleteServersAction struct {
 	actionutils.ParentAction
@@ -16,9 +51,45 @@ type DeleteServersAction struct {
 func (this *DeleteServersAction) RunPost(params struct {
 	ServerIds []int64
 }) {
+	// 是否需要审批
+	isRequired, err := changeutils.IsRequired(changeutils.KindDeleteServers)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if isRequired {
+		preview, clusterIds, err := changeutils.PreviewServers(this.AdminContext(), this.RPC(), params.ServerIds)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		snapshot, err := changeutils.SnapshotServers(this.AdminContext(), this.RPC(), params.ServerIds)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		change, err := approvalutils.NewSettingChange(changeutils.KindDeleteServers, snapshot, params.ServerIds)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+			Kind:       changeutils.KindDeleteServers,
+			Title:      "删除" + strconv.Itoa(len(params.ServerIds)) + "个网站",
+			Preview:    preview,
+			ClusterIds: clusterIds,
+		}, change)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.Success()
+		return
+	}
+
 	defer this.CreateLogInfo(codes.Server_LogDeleteServers)
 
-	_, err := this.RPC().ServerRPC().DeleteServers(this.AdminContext(), &pb.DeleteServersRequest{ServerIds: params.ServerIds})
+	_, err = this.RPC().ServerRPC().DeleteServers(this.AdminContext(), &pb.DeleteServersRequest{ServerIds: params.ServerIds})
 	if err != nil {
 		this.ErrorPage(err)
 		return
@@ -0,0 +1,41 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package servers
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/domaincheckutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// DomainCheckAction 域名接入检查报告
+type DomainCheckAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DomainCheckAction) Init() {
+	this.Nav("", "server", "domainCheck")
+}
+
+func (this *DomainCheckAction) RunGet(params struct{}) {
+	// 审核中的数量
+	countAuditingResp, err := this.RPC().ServerRPC().CountAllEnabledServersMatch(this.AdminContext(), &pb.CountAllEnabledServersMatchRequest{
+		AuditingFlag: 1,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["countAuditing"] = countAuditingResp.Count
+
+	var report = domaincheckutils.SharedReport()
+	this.Data["report"] = report
+	if report.CheckedAt > 0 {
+		this.Data["checkedTime"] = timeutil.FormatTime("Y-m-d H:i:s", report.CheckedAt)
+	} else {
+		this.Data["checkedTime"] = ""
+	}
+
+	this.Show()
+}
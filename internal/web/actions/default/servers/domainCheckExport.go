@@ -0,0 +1,88 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package servers
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/domaincheckutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// DomainCheckExportAction 导出域名检查报告
+type DomainCheckExportAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DomainCheckExportAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *DomainCheckExportAction) RunGet(params struct{}) {
+	var report = domaincheckutils.SharedReport()
+
+	var wb = xlsx.NewFile()
+	sheet, err := wb.AddSheet("default")
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 头部
+	{
+		var row = sheet.AddRow()
+		row.SetHeight(25)
+		row.AddCell().SetString("网站ID")
+		row.AddCell().SetString("网站")
+		row.AddCell().SetString("集群")
+		row.AddCell().SetString("域名")
+		row.AddCell().SetString("期望CNAME")
+		row.AddCell().SetString("当前CNAME")
+		row.AddCell().SetString("解析IP")
+		row.AddCell().SetString("CAA")
+		row.AddCell().SetString("问题")
+	}
+
+	// 数据
+	for _, item := range report.Items {
+		var row = sheet.AddRow()
+		row.SetHeight(25)
+		row.AddCell().SetInt64(item.ServerId)
+		row.AddCell().SetString(item.ServerName)
+		row.AddCell().SetString(item.ClusterName)
+
+		var result = item.Result
+		if result == nil {
+			for i := 0; i < 5; i++ {
+				row.AddCell().SetString("")
+			}
+			row.AddCell().SetString("检查失败：" + item.Error)
+			continue
+		}
+
+		row.AddCell().SetString(result.ServerName)
+		row.AddCell().SetString(result.ExpectedCNAME)
+		row.AddCell().SetString(result.CNAME)
+		row.AddCell().SetString(strings.Join(result.IPs, ", "))
+		row.AddCell().SetString(strings.Join(result.CAARecords, "\n"))
+		row.AddCell().SetString(strings.Join(result.Errors, "\n"))
+	}
+
+	this.AddHeader("Content-Type", "application/vnd.ms-excel")
+	this.AddHeader("Content-Disposition", "attachment; filename=\"DOMAIN-CHECK-"+timeutil.Format("YmdHis")+".xlsx\"")
+	this.AddHeader("Cache-Control", "max-age=0")
+
+	var buf = bytes.NewBuffer([]byte{})
+	err = wb.Write(buf)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.AddHeader("Content-Length", strconv.Itoa(buf.Len()))
+	_, _ = this.Write(buf.Bytes())
+}
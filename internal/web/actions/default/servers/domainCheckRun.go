@@ -0,0 +1,36 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package servers
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/goman"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/domaincheckutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+	"github.com/iwind/TeaGo/logs"
+)
+
+// DomainCheckRunAction 立即检查所有网站域名
+type DomainCheckRunAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DomainCheckRunAction) RunPost(params struct{}) {
+	if domaincheckutils.SharedReport().IsChecking {
+		this.Fail("正在检查中，请稍后刷新页面查看结果")
+		return
+	}
+
+	defer this.CreateLogInfo("检查所有网站域名的接入情况")
+
+	var rpcClient = this.RPC()
+	var adminId = this.AdminId()
+	goman.New(func() {
+		err := serverutils.CheckAllServerDomains(rpcClient, adminId)
+		if err != nil {
+			logs.Println("[DOMAIN_CHECK]" + err.Error())
+		}
+	})
+
+	this.Success()
+}
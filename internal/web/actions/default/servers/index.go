@@ -5,23 +5,32 @@ import (
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
 	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/configutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/nodeconfigs"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
 	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
 	timeutil "github.com/iwind/TeaGo/utils/time"
 )
 
 type IndexAction struct {
 	actionutils.ParentAction
+
+	scope *rbacutils.Scope
 }
 
 func (this *IndexAction) Init() {
 	this.Nav("", "server", "index")
 }
 
+// RBACFilterScope 有资源范围限制的角色只能按照范围内的集群、分组或用户查看网站
+func (this *IndexAction) RBACFilterScope(scope *rbacutils.Scope) {
+	this.scope = scope
+}
+
 func (this *IndexAction) RunGet(params struct {
 	ClusterId    int64
 	GroupId      int64
@@ -34,6 +43,19 @@ func (this *IndexAction) RunGet(params struct {
 	RequestsOrder       string
 	AttackRequestsOrder string
 }) {
+	// 有资源范围限制时转到范围内的第一个集群、分组或用户
+	if !this.scope.IsEmpty() {
+		switch {
+		case len(this.scope.ClusterIds) > 0:
+			this.RedirectURL("/servers?clusterId=" + types.String(this.scope.ClusterIds[0]))
+		case len(this.scope.ServerGroupIds) > 0:
+			this.RedirectURL("/servers?groupId=" + types.String(this.scope.ServerGroupIds[0]))
+		case len(this.scope.UserIds) > 0:
+			this.RedirectURL("/servers?userId=" + types.String(this.scope.UserIds[0]))
+		}
+		return
+	}
+
 	this.Data["clusterId"] = params.ClusterId
 	this.Data["groupId"] = params.GroupId
 	this.Data["keyword"] = params.Keyword
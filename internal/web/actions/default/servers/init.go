@@ -28,6 +28,11 @@ func init() {
 			Get("/serverNamesPopup", new(ServerNamesPopupAction)).
 			Post("/status", new(StatusAction)).
 
+			// 域名检查
+			Get("/domainCheck", new(DomainCheckAction)).
+			Post("/domainCheck/run", new(DomainCheckRunAction)).
+			Get("/domainCheck/export", new(DomainCheckExportAction)).
+
 			// user
 			Post("/users/options", new(users.OptionsAction)).
 			Post("/users/plans", new(users.PlansAction)).
@@ -0,0 +1,90 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/rands"
+)
+
+// CreateAction 创建定时报表
+type CreateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CreateAction) Init() {
+	this.Nav("", "report", "create")
+}
+
+func (this *CreateAction) RunGet(params struct{}) {
+	err := loadFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *CreateAction) RunPost(params struct {
+	Name       string
+	IsOn       bool
+	Period     string
+	WeekDay    int
+	MonthDay   int
+	Hour       int
+	TargetType string
+	TargetIds  string
+	GroupIds   []int64
+	Emails     string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var now = time.Now()
+	var schedule = &reportutils.Schedule{
+		Id:        rands.HexString(16),
+		AdminId:   this.AdminId(),
+		CreatedAt: now.Unix(),
+	}
+	defer this.CreateLogInfo("创建网站流量报表 %s", schedule.Id)
+
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入报表名称")
+
+	var form = &reportForm{
+		Name:       params.Name,
+		IsOn:       params.IsOn,
+		Period:     params.Period,
+		WeekDay:    params.WeekDay,
+		MonthDay:   params.MonthDay,
+		Hour:       params.Hour,
+		TargetType: params.TargetType,
+		TargetIds:  params.TargetIds,
+		GroupIds:   params.GroupIds,
+		Emails:     params.Emails,
+	}
+	err := form.apply(schedule)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+	schedule.ResetNextRunAt(now)
+
+	err = configloaders.UpdateServerReportStore(func(store *reportutils.Store) error {
+		store.Add(schedule)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
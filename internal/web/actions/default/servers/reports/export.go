@@ -0,0 +1,52 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// ExportAction 导出Excel格式的报表
+type ExportAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ExportAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *ExportAction) RunGet(params struct {
+	ReportId string
+}) {
+	schedule, err := findSchedule(params.ReportId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if schedule == nil {
+		this.NotFound("report", 0)
+		return
+	}
+
+	report, err := serverutils.BuildTrafficReport(this.AdminContext(), this.RPC(), schedule, time.Now())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	data, err := reportutils.RenderXLSX(report)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.AddHeader("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	this.AddHeader("Content-Disposition", "attachment; filename=\"report-"+report.DayFrom+"-"+report.DayTo+".xlsx\"")
+	this.AddHeader("Cache-Control", "max-age=0")
+	this.AddHeader("Content-Length", strconv.Itoa(len(data)))
+	_, _ = this.Write(data)
+}
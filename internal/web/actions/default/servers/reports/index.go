@@ -0,0 +1,71 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// IndexAction 定时报表列表
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "report", "index")
+}
+
+func (this *IndexAction) RunGet(params struct{}) {
+	smtpConfig, err := configloaders.LoadSMTPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["smtpIsConfigured"] = smtpConfig.IsConfigured()
+
+	store, err := configloaders.LoadServerReportStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var reportMaps = []maps.Map{}
+	for _, schedule := range store.Schedules {
+		targetName, err := findTargetName(this.Parent(), schedule)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+
+		var lastRunTime = ""
+		if schedule.LastRunAt > 0 {
+			lastRunTime = timeutil.FormatTime("Y-m-d H:i:s", schedule.LastRunAt)
+		}
+		var nextRunTime = ""
+		if schedule.NextRunAt > 0 {
+			nextRunTime = timeutil.FormatTime("Y-m-d H:i:s", schedule.NextRunAt)
+		}
+
+		reportMaps = append(reportMaps, maps.Map{
+			"id":           schedule.Id,
+			"name":         schedule.Name,
+			"isOn":         schedule.IsOn,
+			"periodName":   reportutils.PeriodName(schedule.Period),
+			"scheduleName": scheduleName(schedule),
+			"targetName":   targetName,
+			"emails":       strings.Join(schedule.Emails, ", "),
+			"lastRunTime":  lastRunTime,
+			"lastError":    schedule.LastError,
+			"nextRunTime":  nextRunTime,
+		})
+	}
+	this.Data["reports"] = reportMaps
+
+	this.Show()
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/helpers"
+	"github.com/iwind/TeaGo"
+)
+
+func init() {
+	TeaGo.BeforeStart(func(server *TeaGo.Server) {
+		server.
+			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeServer)).
+			Data("teaMenu", "servers").
+			Data("teaSubMenu", "report").
+			Prefix("/servers/reports").
+			Get("", new(IndexAction)).
+			GetPost("/create", new(CreateAction)).
+			GetPost("/update", new(UpdateAction)).
+			Post("/delete", new(DeleteAction)).
+			Post("/send", new(SendAction)).
+			Get("/preview", new(PreviewAction)).
+			Get("/export", new(ExportAction)).
+			GetPost("/smtp", new(SmtpAction)).
+			Post("/smtpTest", new(SmtpTestAction)).
+			EndAll()
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// PreviewAction 预览报表邮件内容
+type PreviewAction struct {
+	actionutils.ParentAction
+}
+
+func (this *PreviewAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *PreviewAction) RunGet(params struct {
+	ReportId string
+}) {
+	schedule, err := findSchedule(params.ReportId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if schedule == nil {
+		this.NotFound("report", 0)
+		return
+	}
+
+	report, err := serverutils.BuildTrafficReport(this.AdminContext(), this.RPC(), schedule, time.Now())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	html, err := reportutils.RenderHTML(report)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.AddHeader("Content-Type", "text/html; charset=utf-8")
+	this.WriteString(html)
+}
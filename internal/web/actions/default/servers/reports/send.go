@@ -0,0 +1,39 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// SendAction 立即发送报表
+type SendAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SendAction) RunPost(params struct {
+	ReportId string
+}) {
+	defer this.CreateLogInfo("立即发送网站流量报表 %s", params.ReportId)
+
+	schedule, err := findSchedule(params.ReportId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if schedule == nil {
+		this.NotFound("report", 0)
+		return
+	}
+
+	err = serverutils.SendTrafficReport(this.AdminContext(), this.RPC(), schedule, time.Now())
+	if err != nil {
+		this.Fail("发送失败：" + err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// SmtpAction 发信服务器设置
+type SmtpAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SmtpAction) Init() {
+	this.Nav("", "report", "smtp")
+}
+
+func (this *SmtpAction) RunGet(params struct{}) {
+	config, err := configloaders.LoadSMTPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["config"] = maps.Map{
+		"host":        config.Host,
+		"port":        config.Port,
+		"security":    config.Security,
+		"username":    config.Username,
+		"hasPassword": len(config.Password) > 0,
+		"fromEmail":   config.FromEmail,
+		"fromName":    config.FromName,
+	}
+
+	this.Show()
+}
+
+func (this *SmtpAction) RunPost(params struct {
+	Host      string
+	Port      int
+	Security  string
+	Username  string
+	Password  string
+	FromEmail string
+	FromName  string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改流量报表发信服务器设置")
+
+	params.Must.
+		Field("host", params.Host).
+		Require("请输入SMTP服务器地址").
+		Field("fromEmail", params.FromEmail).
+		Require("请输入发信人邮箱")
+
+	if !utils.ValidateEmail(params.FromEmail) {
+		this.FailField("fromEmail", "发信人邮箱格式不正确")
+		return
+	}
+
+	oldConfig, err := configloaders.LoadSMTPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var config = &reportutils.SMTPConfig{
+		Host:      params.Host,
+		Port:      params.Port,
+		Security:  params.Security,
+		Username:  params.Username,
+		Password:  params.Password,
+		FromEmail: params.FromEmail,
+		FromName:  params.FromName,
+	}
+
+	// 密码为空时保留原来的密码
+	if len(config.Password) == 0 && config.Username == oldConfig.Username {
+		config.Password = oldConfig.Password
+	}
+
+	err = config.Validate()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	err = configloaders.UpdateSMTPConfig(config)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// SmtpTestAction 发送测试邮件
+type SmtpTestAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SmtpTestAction) RunPost(params struct {
+	Email string
+}) {
+	if !utils.ValidateEmail(params.Email) {
+		this.Fail("请输入正确的收件人邮箱")
+		return
+	}
+
+	config, err := configloaders.LoadSMTPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	err = reportutils.SendMail(config, &reportutils.Mail{
+		To:      []string{params.Email},
+		Subject: "测试邮件",
+		HTML:    "<p>这是一封测试邮件，收到此邮件说明流量报表发信服务器设置正确。</p>",
+	})
+	if err != nil {
+		this.Fail("发送失败：" + err.Error())
+		return
+	}
+
+	this.Success()
+}
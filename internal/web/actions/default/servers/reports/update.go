@@ -0,0 +1,132 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
+)
+
+// UpdateAction 修改定时报表
+type UpdateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UpdateAction) Init() {
+	this.Nav("", "report", "index")
+}
+
+func (this *UpdateAction) RunGet(params struct {
+	ReportId string
+}) {
+	schedule, err := findSchedule(params.ReportId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if schedule == nil {
+		this.NotFound("report", 0)
+		return
+	}
+
+	var targetIdStrings = []string{}
+	var groupIds = []int64{}
+	if schedule.TargetType == reportutils.TargetGroup {
+		groupIds = schedule.TargetIds
+	} else {
+		for _, targetId := range schedule.TargetIds {
+			targetIdStrings = append(targetIdStrings, types.String(targetId))
+		}
+	}
+
+	this.Data["report"] = maps.Map{
+		"id":         schedule.Id,
+		"name":       schedule.Name,
+		"isOn":       schedule.IsOn,
+		"period":     schedule.Period,
+		"weekDay":    schedule.WeekDay,
+		"monthDay":   schedule.MonthDay,
+		"hour":       schedule.Hour,
+		"targetType": schedule.TargetType,
+		"targetIds":  strings.Join(targetIdStrings, ", "),
+		"groupIds":   groupIds,
+		"emails":     strings.Join(schedule.Emails, "\n"),
+	}
+
+	err = loadFormOptions(this.Parent())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Show()
+}
+
+func (this *UpdateAction) RunPost(params struct {
+	ReportId   string
+	Name       string
+	IsOn       bool
+	Period     string
+	WeekDay    int
+	MonthDay   int
+	Hour       int
+	TargetType string
+	TargetIds  string
+	GroupIds   []int64
+	Emails     string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改网站流量报表 %s", params.ReportId)
+
+	params.Must.
+		Field("name", params.Name).
+		Require("请输入报表名称")
+
+	var form = &reportForm{
+		Name:       params.Name,
+		IsOn:       params.IsOn,
+		Period:     params.Period,
+		WeekDay:    params.WeekDay,
+		MonthDay:   params.MonthDay,
+		Hour:       params.Hour,
+		TargetType: params.TargetType,
+		TargetIds:  params.TargetIds,
+		GroupIds:   params.GroupIds,
+		Emails:     params.Emails,
+	}
+
+	var errNotFound = errors.New("报表不存在")
+	var formErr error
+	err := configloaders.UpdateServerReportStore(func(store *reportutils.Store) error {
+		var schedule = store.Find(params.ReportId)
+		if schedule == nil {
+			return errNotFound
+		}
+		formErr = form.apply(schedule)
+		if formErr != nil {
+			return formErr
+		}
+		schedule.ResetNextRunAt(time.Now())
+		return nil
+	})
+	if err != nil {
+		if err == errNotFound || err == formErr {
+			this.Fail(err.Error())
+			return
+		}
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
+)
+
+var weekDayNames = []string{"周一", "周二", "周三", "周四", "周五", "周六", "周日"}
+
+// 报表表单提交的参数
+type reportForm struct {
+	Name       string
+	IsOn       bool
+	Period     string
+	WeekDay    int
+	MonthDay   int
+	Hour       int
+	TargetType string
+	TargetIds  string  // 网站或用户ID，用逗号隔开
+	GroupIds   []int64 // 网站分组ID
+	Emails     string  // 收件人，每行一个
+}
+
+// 将表单转换为报表设置
+func (this *reportForm) apply(schedule *reportutils.Schedule) error {
+	var targetIds = []int64{}
+	if this.TargetType == reportutils.TargetGroup {
+		targetIds = this.GroupIds
+	} else {
+		for _, piece := range splitFormValues(this.TargetIds) {
+			var targetId = types.Int64(piece)
+			if targetId <= 0 {
+				return errors.New("'" + piece + "'不是正确的ID")
+			}
+			if !lists.ContainsInt64(targetIds, targetId) {
+				targetIds = append(targetIds, targetId)
+			}
+		}
+	}
+
+	var emails = []string{}
+	for _, email := range splitFormValues(this.Emails) {
+		if !utils.ValidateEmail(email) {
+			return errors.New("邮箱'" + email + "'格式不正确")
+		}
+		if !lists.ContainsString(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+
+	schedule.Name = this.Name
+	schedule.IsOn = this.IsOn
+	schedule.Period = this.Period
+	schedule.WeekDay = this.WeekDay
+	schedule.MonthDay = this.MonthDay
+	schedule.Hour = this.Hour
+	schedule.TargetType = this.TargetType
+	schedule.TargetIds = targetIds
+	schedule.Emails = emails
+
+	return schedule.Validate()
+}
+
+// 分割用逗号、空格或换行隔开的值
+func splitFormValues(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == ';' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+}
+
+// 读取表单需要的选项
+func loadFormOptions(parent *actionutils.ParentAction) error {
+	groupsResp, err := parent.RPC().ServerGroupRPC().FindAllEnabledServerGroups(parent.AdminContext(), &pb.FindAllEnabledServerGroupsRequest{})
+	if err != nil {
+		return err
+	}
+	var groupMaps = []maps.Map{}
+	for _, group := range groupsResp.ServerGroups {
+		groupMaps = append(groupMaps, maps.Map{
+			"id":   group.Id,
+			"name": group.Name,
+		})
+	}
+	parent.Data["serverGroups"] = groupMaps
+	parent.Data["weekDays"] = weekDayNames
+	return nil
+}
+
+// 报表发送计划的描述
+func scheduleName(schedule *reportutils.Schedule) string {
+	var timeString = fmt.Sprintf("%02d:00", schedule.Hour)
+	switch schedule.Period {
+	case reportutils.PeriodWeekly:
+		if schedule.WeekDay >= 1 && schedule.WeekDay <= 7 {
+			return "每" + weekDayNames[schedule.WeekDay-1] + " " + timeString
+		}
+	case reportutils.PeriodMonthly:
+		return "每月" + types.String(schedule.MonthDay) + "日 " + timeString
+	}
+	return ""
+}
+
+// 报表对象的描述
+func findTargetName(parent *actionutils.ParentAction, schedule *reportutils.Schedule) (string, error) {
+	const maxNames = 3
+
+	var names = []string{}
+	switch schedule.TargetType {
+	case reportutils.TargetServer:
+		for _, serverId := range schedule.TargetIds {
+			if len(names) >= maxNames {
+				break
+			}
+			serverResp, err := parent.RPC().ServerRPC().FindEnabledServer(parent.AdminContext(), &pb.FindEnabledServerRequest{
+				ServerId:       serverId,
+				IgnoreSSLCerts: true,
+			})
+			if err != nil {
+				return "", err
+			}
+			if serverResp.Server != nil {
+				names = append(names, serverResp.Server.Name)
+			} else {
+				names = append(names, "[已删除："+types.String(serverId)+"]")
+			}
+		}
+	case reportutils.TargetUser:
+		for _, userId := range schedule.TargetIds {
+			if len(names) >= maxNames {
+				break
+			}
+			userResp, err := parent.RPC().UserRPC().FindEnabledUser(parent.AdminContext(), &pb.FindEnabledUserRequest{UserId: userId})
+			if err != nil {
+				return "", err
+			}
+			if userResp.User != nil {
+				names = append(names, userResp.User.Fullname+"（"+userResp.User.Username+"）")
+			} else {
+				names = append(names, "[已删除："+types.String(userId)+"]")
+			}
+		}
+	case reportutils.TargetGroup:
+		groupsResp, err := parent.RPC().ServerGroupRPC().FindAllEnabledServerGroups(parent.AdminContext(), &pb.FindAllEnabledServerGroupsRequest{})
+		if err != nil {
+			return "", err
+		}
+		for _, groupId := range schedule.TargetIds {
+			if len(names) >= maxNames {
+				break
+			}
+			var groupName = "[已删除：" + types.String(groupId) + "]"
+			for _, group := range groupsResp.ServerGroups {
+				if group.Id == groupId {
+					groupName = group.Name
+					break
+				}
+			}
+			names = append(names, groupName)
+		}
+	}
+
+	var result = reportutils.TargetName(schedule.TargetType) + "：" + strings.Join(names, "、")
+	if len(schedule.TargetIds) > maxNames {
+		result += " 等" + types.String(len(schedule.TargetIds)) + "个"
+	}
+	return result, nil
+}
+
+// 查找报表
+func findSchedule(scheduleId string) (*reportutils.Schedule, error) {
+	store, err := configloaders.LoadServerReportStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Find(scheduleId), nil
+}
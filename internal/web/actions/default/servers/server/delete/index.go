@@ -1,6 +1,11 @@
 package deletes
 
-import (	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+import (
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/iwind/TeaGo/actions"
@@ -23,11 +28,47 @@ func (this *IndexAction) RunPost(params struct {
 	ServerId int64
 	Must     *actions.Must
 }) {
+	// 是否需要审批
+	isRequired, err := changeutils.IsRequired(changeutils.KindDeleteServers)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if isRequired {
+		preview, clusterIds, err := changeutils.PreviewServers(this.AdminContext(), this.RPC(), []int64{params.ServerId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		snapshot, err := changeutils.SnapshotServers(this.AdminContext(), this.RPC(), []int64{params.ServerId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		change, err := approvalutils.NewSettingChange(changeutils.KindDeleteServers, snapshot, []int64{params.ServerId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		_, err = changeutils.Submit(this.Parent(), &approvalutils.ChangeRequest{
+			Kind:       changeutils.KindDeleteServers,
+			Title:      "删除网站 " + strconv.FormatInt(params.ServerId, 10),
+			Preview:    preview,
+			ClusterIds: clusterIds,
+		}, change)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		this.Success()
+		return
+	}
+
 	// 记录日志
 	defer this.CreateLogInfo(codes.Server_LogDeleteServer, params.ServerId)
 
 	// 执行删除
-	_, err := this.RPC().ServerRPC().DeleteServer(this.AdminContext(), &pb.DeleteServerRequest{ServerId: params.ServerId})
+	_, err = this.RPC().ServerRPC().DeleteServer(this.AdminContext(), &pb.DeleteServerRequest{ServerId: params.ServerId})
 	if err != nil {
 		this.ErrorPage(err)
 		return
@@ -0,0 +1,415 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/configutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs/sslconfigs"
+	"github.com/iwind/TeaGo/lists"
+)
+
+// 证书冲突处理方式
+const (
+	CertStrategyKeep  = "keep"  // 保留目标网站自己的证书
+	CertStrategyShare = "share" // 使用源网站的证书，要求证书能覆盖目标网站的所有域名
+)
+
+// 域名不属于配置文档中的分段，只在复制时使用
+const SectionServerNames = "serverNames"
+
+// 分段处理动作
+const (
+	SectionActionOverwrite = "overwrite" // 覆盖
+	SectionActionNone      = "none"      // 无变化
+	SectionActionSkip      = "skip"      // 因为冲突而跳过
+)
+
+// SectionResult 单个分段的预览或者复制结果
+type SectionResult struct {
+	Code    string                         `json:"code"`
+	Name    string                         `json:"name"`
+	Action  string                         `json:"action"`
+	Changes []*configcodeutils.FieldChange `json:"changes"`
+	Message string                         `json:"message"`
+}
+
+// TargetResult 单个目标网站的预览或者复制结果
+type TargetResult struct {
+	ServerId    int64            `json:"serverId"`
+	ServerName  string           `json:"serverName"`
+	ClusterName string           `json:"clusterName"`
+	Sections    []*SectionResult `json:"sections"`
+	IsOk        bool             `json:"isOk"`
+	Error       string           `json:"error"`
+}
+
+// 应用时会根据内容创建新对象的分段，避免多个网站共用同一个对象
+var copySectionCodes = []string{
+	configcodeutils.SectionReverseProxy,
+	configcodeutils.SectionLocations,
+	configcodeutils.SectionRequestHeaders,
+	configcodeutils.SectionResponseHeaders,
+	configcodeutils.SectionWAF,
+	configcodeutils.SectionRewrites,
+}
+
+// Cloner 网站配置复制器
+type Cloner struct {
+	ctx       context.Context
+	rpcClient *rpc.RPCClient
+
+	sourceServerId  int64
+	sectionCodes    []string
+	certStrategy    string
+	copyServerNames bool
+
+	sourceDoc          *configcodeutils.Document
+	sourceServerNames  []*serverconfigs.ServerNameConfig
+	sourceCertDNSNames []string
+}
+
+// NewCloner 获取新的复制器
+func NewCloner(ctx context.Context, rpcClient *rpc.RPCClient, sourceServerId int64, sectionCodes []string, certStrategy string, copyServerNames bool) *Cloner {
+	if certStrategy != CertStrategyShare {
+		certStrategy = CertStrategyKeep
+	}
+	return &Cloner{
+		ctx:             ctx,
+		rpcClient:       rpcClient,
+		sourceServerId:  sourceServerId,
+		sectionCodes:    sectionCodes,
+		certStrategy:    certStrategy,
+		copyServerNames: copyServerNames,
+	}
+}
+
+// Init 读取源网站配置
+func (this *Cloner) Init() error {
+	doc, _, err := serverutils.LoadServerConfigDocument(this.ctx, this.rpcClient, this.sourceServerId)
+	if err != nil {
+		return err
+	}
+	this.sourceDoc = doc
+
+	if this.copyServerNames {
+		serverNames, err := this.findServerNames(this.sourceServerId)
+		if err != nil {
+			return err
+		}
+		this.sourceServerNames = serverNames
+	}
+
+	// 源网站证书中的域名
+	if this.hasSection(configcodeutils.SectionHTTPS) && this.certStrategy == CertStrategyShare {
+		httpsJSON, _, err := doc.SectionJSON(configcodeutils.SectionHTTPS)
+		if err != nil {
+			return err
+		}
+		var httpsConfig = &serverconfigs.HTTPSProtocolConfig{}
+		if !utils.JSONIsNull(httpsJSON) {
+			err = json.Unmarshal(httpsJSON, httpsConfig)
+			if err != nil {
+				return err
+			}
+		}
+		if httpsConfig.SSLPolicyRef != nil && httpsConfig.SSLPolicyRef.SSLPolicyId > 0 {
+			policyResp, err := this.rpcClient.SSLPolicyRPC().FindEnabledSSLPolicyConfig(this.ctx, &pb.FindEnabledSSLPolicyConfigRequest{
+				SslPolicyId: httpsConfig.SSLPolicyRef.SSLPolicyId,
+				IgnoreData:  true,
+			})
+			if err != nil {
+				return err
+			}
+			if len(policyResp.SslPolicyJSON) > 0 {
+				var sslPolicy = &sslconfigs.SSLPolicy{}
+				err = json.Unmarshal(policyResp.SslPolicyJSON, sslPolicy)
+				if err != nil {
+					return err
+				}
+				for _, cert := range sslPolicy.Certs {
+					for _, dnsName := range cert.DNSNames {
+						if !lists.ContainsString(this.sourceCertDNSNames, dnsName) {
+							this.sourceCertDNSNames = append(this.sourceCertDNSNames, dnsName)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Preview 预览将要覆盖的内容
+func (this *Cloner) Preview(targetServerId int64) *TargetResult {
+	return this.run(targetServerId, false)
+}
+
+// Clone 复制配置到目标网站
+func (this *Cloner) Clone(targetServerId int64) *TargetResult {
+	return this.run(targetServerId, true)
+}
+
+func (this *Cloner) run(targetServerId int64, apply bool) *TargetResult {
+	var result = &TargetResult{
+		ServerId: targetServerId,
+		Sections: []*SectionResult{},
+	}
+
+	err := this.runTarget(result, apply)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.IsOk = true
+	return result
+}
+
+func (this *Cloner) runTarget(result *TargetResult, apply bool) error {
+	var targetServerId = result.ServerId
+	if targetServerId == this.sourceServerId {
+		return errors.New("不能复制到源网站自身")
+	}
+
+	serverResp, err := this.rpcClient.ServerRPC().FindEnabledServer(this.ctx, &pb.FindEnabledServerRequest{
+		ServerId:       targetServerId,
+		IgnoreSSLCerts: true,
+	})
+	if err != nil {
+		return err
+	}
+	var server = serverResp.Server
+	if server == nil {
+		return errors.New("找不到网站 " + strconv.FormatInt(targetServerId, 10))
+	}
+	result.ServerName = server.Name
+	var clusterId int64
+	if server.NodeCluster != nil {
+		clusterId = server.NodeCluster.Id
+		result.ClusterName = server.NodeCluster.Name
+	}
+	serverConfig, err := serverconfigs.NewServerConfigFromJSON(server.Config)
+	if err != nil {
+		return err
+	}
+	if !serverConfig.IsHTTPFamily() {
+		return errors.New("只能复制到HTTP网站")
+	}
+
+	targetDoc, targetWebId, err := serverutils.LoadServerConfigDocument(this.ctx, this.rpcClient, targetServerId)
+	if err != nil {
+		return err
+	}
+
+	for _, section := range configcodeutils.AllSections() {
+		if !this.hasSection(section.Code) || !this.sourceDoc.HasSection(section.Code) {
+			continue
+		}
+
+		var sectionResult = &SectionResult{
+			Code:    section.Code,
+			Name:    section.Name,
+			Action:  SectionActionNone,
+			Changes: []*configcodeutils.FieldChange{},
+		}
+		result.Sections = append(result.Sections, sectionResult)
+
+		err = this.cloneSection(sectionResult, targetDoc, targetServerId, targetWebId, apply)
+		if err != nil {
+			return errors.New("复制'" + section.Name + "'失败：" + err.Error())
+		}
+	}
+
+	// 域名
+	if this.copyServerNames {
+		var sectionResult = &SectionResult{
+			Code:    SectionServerNames,
+			Name:    "域名",
+			Action:  SectionActionNone,
+			Changes: []*configcodeutils.FieldChange{},
+		}
+		result.Sections = append(result.Sections, sectionResult)
+		err = this.cloneServerNames(sectionResult, targetServerId, clusterId, apply)
+		if err != nil {
+			return errors.New("复制域名失败：" + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// 复制单个分段
+func (this *Cloner) cloneSection(sectionResult *SectionResult, targetDoc *configcodeutils.Document, targetServerId int64, targetWebId int64, apply bool) error {
+	var code = sectionResult.Code
+	var desiredValue = this.sourceDoc.Sections[code]
+	var currentValue = targetDoc.Sections[code]
+
+	// HTTPS证书
+	if code == configcodeutils.SectionHTTPS {
+		desiredMap, ok := desiredValue.(map[string]any)
+		if ok {
+			var newMap = map[string]any{}
+			for k, v := range desiredMap {
+				newMap[k] = v
+			}
+
+			switch this.certStrategy {
+			case CertStrategyKeep:
+				var currentSSLPolicyRef any
+				currentMap, ok := currentValue.(map[string]any)
+				if ok {
+					currentSSLPolicyRef = currentMap["sslPolicyRef"]
+				}
+				newMap["sslPolicyRef"] = currentSSLPolicyRef
+				if currentSSLPolicyRef == nil && newMap["isOn"] == true {
+					sectionResult.Message = "目标网站尚未设置证书，需要复制后再单独设置"
+				}
+			case CertStrategyShare:
+				serverNames, err := this.findServerNames(targetServerId)
+				if err != nil {
+					return err
+				}
+				var missingNames = []string{}
+				for _, serverName := range serverconfigs.PlainServerNames(serverNames) {
+					if !configutils.MatchDomains(this.sourceCertDNSNames, serverName) {
+						missingNames = append(missingNames, serverName)
+					}
+				}
+				if len(missingNames) > 0 {
+					sectionResult.Action = SectionActionSkip
+					sectionResult.Message = "源网站证书不支持域名：" + strings.Join(missingNames, ", ")
+					return nil
+				}
+			}
+			desiredValue = newMap
+		}
+	}
+
+	sectionResult.Changes = configcodeutils.Diff("", currentValue, desiredValue)
+	if len(sectionResult.Changes) == 0 {
+		return nil
+	}
+	sectionResult.Action = SectionActionOverwrite
+	if lists.ContainsString(copySectionCodes, code) {
+		sectionResult.Message = "根据源网站的内容创建副本，替换目标网站现有的设置"
+	}
+
+	if !apply {
+		return nil
+	}
+	desiredJSON, err := json.Marshal(desiredValue)
+	if err != nil {
+		return err
+	}
+	return serverutils.ApplyServerConfigSection(this.ctx, this.rpcClient, targetServerId, targetWebId, code, desiredJSON)
+}
+
+// 复制域名，跳过在目标集群中已经被占用的域名
+func (this *Cloner) cloneServerNames(sectionResult *SectionResult, targetServerId int64, clusterId int64, apply bool) error {
+	currentServerNames, err := this.findServerNames(targetServerId)
+	if err != nil {
+		return err
+	}
+	var currentNames = serverconfigs.PlainServerNames(currentServerNames)
+
+	var sourceNames = serverconfigs.PlainServerNames(this.sourceServerNames)
+	var duplicatedNames = []string{}
+	if len(sourceNames) > 0 && clusterId > 0 {
+		dupResp, err := this.rpcClient.ServerRPC().CheckServerNameDuplicationInNodeCluster(this.ctx, &pb.CheckServerNameDuplicationInNodeClusterRequest{
+			ServerNames:     sourceNames,
+			NodeClusterId:   clusterId,
+			ExcludeServerId: targetServerId,
+		})
+		if err != nil {
+			return err
+		}
+		duplicatedNames = dupResp.DuplicatedServerNames
+	}
+
+	var newServerNames = currentServerNames
+	var addedNames = []string{}
+	var skippedNames = []string{}
+	for _, serverName := range this.sourceServerNames {
+		var plainNames = serverconfigs.PlainServerNames([]*serverconfigs.ServerNameConfig{serverName})
+		var isDuplicated = false
+		var isExisting = true
+		for _, plainName := range plainNames {
+			if lists.ContainsString(duplicatedNames, plainName) {
+				isDuplicated = true
+			}
+			if !lists.ContainsString(currentNames, plainName) {
+				isExisting = false
+			}
+		}
+		if isDuplicated {
+			skippedNames = append(skippedNames, plainNames...)
+			continue
+		}
+		if isExisting {
+			continue
+		}
+		newServerNames = append(newServerNames, serverName)
+		addedNames = append(addedNames, plainNames...)
+	}
+
+	var messages = []string{}
+	if len(addedNames) > 0 {
+		sectionResult.Action = SectionActionOverwrite
+		messages = append(messages, "增加域名："+strings.Join(addedNames, ", "))
+		for _, name := range addedNames {
+			sectionResult.Changes = append(sectionResult.Changes, &configcodeutils.FieldChange{
+				Op:       configcodeutils.ChangeOpAdd,
+				NewValue: name,
+			})
+		}
+	}
+	if len(skippedNames) > 0 {
+		messages = append(messages, "已被同集群其他网站占用而跳过："+strings.Join(skippedNames, ", "))
+	}
+	sectionResult.Message = strings.Join(messages, "；")
+
+	if !apply || len(addedNames) == 0 {
+		return nil
+	}
+
+	serverNamesJSON, err := json.Marshal(newServerNames)
+	if err != nil {
+		return err
+	}
+	_, err = this.rpcClient.ServerRPC().UpdateServerNames(this.ctx, &pb.UpdateServerNamesRequest{
+		ServerId:        targetServerId,
+		ServerNamesJSON: serverNamesJSON,
+	})
+	return err
+}
+
+func (this *Cloner) findServerNames(serverId int64) ([]*serverconfigs.ServerNameConfig, error) {
+	serverNamesResp, err := this.rpcClient.ServerRPC().FindServerNames(this.ctx, &pb.FindServerNamesRequest{ServerId: serverId})
+	if err != nil {
+		return nil, err
+	}
+	var serverNames = []*serverconfigs.ServerNameConfig{}
+	if len(serverNamesResp.ServerNamesJSON) > 0 {
+		err = json.Unmarshal(serverNamesResp.ServerNamesJSON, &serverNames)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return serverNames, nil
+}
+
+func (this *Cloner) hasSection(code string) bool {
+	return lists.ContainsString(this.sectionCodes, code)
+}
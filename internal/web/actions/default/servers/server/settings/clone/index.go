@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clone
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// IndexAction 复制配置到其他网站
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "setting", "index")
+	this.SecondMenu("clone")
+}
+
+func (this *IndexAction) RunGet(params struct {
+	ServerId int64
+}) {
+	// 只有HTTP服务才支持
+	if this.FilterHTTPFamily() {
+		return
+	}
+
+	// 所有集群
+	clustersResp, err := this.RPC().NodeClusterRPC().FindAllEnabledNodeClusters(this.AdminContext(), &pb.FindAllEnabledNodeClustersRequest{})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var clusterMaps = []maps.Map{}
+	for _, cluster := range clustersResp.NodeClusters {
+		clusterMaps = append(clusterMaps, maps.Map{
+			"id":   cluster.Id,
+			"name": cluster.Name,
+		})
+	}
+	this.Data["clusters"] = clusterMaps
+
+	this.Data["sections"] = configcodeutils.AllSections()
+	this.Data["certStrategies"] = []maps.Map{
+		{
+			"code":        CertStrategyKeep,
+			"name":        "保留目标网站证书",
+			"description": "只复制HTTPS的其他设置，目标网站继续使用自己的证书。",
+		},
+		{
+			"code":        CertStrategyShare,
+			"name":        "使用源网站证书",
+			"description": "目标网站使用源网站的证书；如果证书不能覆盖目标网站的所有域名，则跳过HTTPS设置。",
+		},
+	}
+
+	this.Show()
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clone
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// PreviewAction 预览复制后将被覆盖的内容
+type PreviewAction struct {
+	actionutils.ParentAction
+}
+
+func (this *PreviewAction) RunPost(params struct {
+	ServerId        int64
+	TargetServerIds []int64
+	SectionCodes    []string
+	CertStrategy    string
+	CopyServerNames bool
+}) {
+	if len(params.TargetServerIds) == 0 {
+		this.Fail("请选择要复制到的网站")
+		return
+	}
+	if len(params.SectionCodes) == 0 && !params.CopyServerNames {
+		this.Fail("请选择要复制的配置")
+		return
+	}
+
+	var cloner = NewCloner(this.AdminContext(), this.RPC(), params.ServerId, params.SectionCodes, params.CertStrategy, params.CopyServerNames)
+	err := cloner.Init()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var results = []*TargetResult{}
+	for _, targetServerId := range params.TargetServerIds {
+		results = append(results, cloner.Preview(targetServerId))
+	}
+	this.Data["results"] = results
+
+	this.Success()
+}
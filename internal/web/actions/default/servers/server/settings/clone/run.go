@@ -0,0 +1,60 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clone
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/types"
+)
+
+// RunAction 执行复制
+type RunAction struct {
+	actionutils.ParentAction
+}
+
+func (this *RunAction) RunPost(params struct {
+	ServerId        int64
+	TargetServerIds []int64
+	SectionCodes    []string
+	CertStrategy    string
+	CopyServerNames bool
+}) {
+	var targetIdStrings = []string{}
+	for _, targetServerId := range params.TargetServerIds {
+		targetIdStrings = append(targetIdStrings, types.String(targetServerId))
+	}
+	defer this.CreateLogInfo("复制网站 %d 的配置到网站 %s", params.ServerId, strings.Join(targetIdStrings, ", "))
+
+	if len(params.TargetServerIds) == 0 {
+		this.Fail("请选择要复制到的网站")
+		return
+	}
+	if len(params.SectionCodes) == 0 && !params.CopyServerNames {
+		this.Fail("请选择要复制的配置")
+		return
+	}
+
+	var cloner = NewCloner(this.AdminContext(), this.RPC(), params.ServerId, params.SectionCodes, params.CertStrategy, params.CopyServerNames)
+	err := cloner.Init()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 单个目标失败不影响其他目标
+	var results = []*TargetResult{}
+	var countFailed = 0
+	for _, targetServerId := range params.TargetServerIds {
+		var result = cloner.Clone(targetServerId)
+		if !result.IsOk {
+			countFailed++
+		}
+		results = append(results, result)
+	}
+	this.Data["results"] = results
+	this.Data["countFailed"] = countFailed
+
+	this.Success()
+}
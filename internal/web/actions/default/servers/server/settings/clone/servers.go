@@ -0,0 +1,54 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package clone
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// ServersAction 查找可以复制到的目标网站
+type ServersAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ServersAction) RunPost(params struct {
+	ServerId  int64
+	ClusterId int64
+	Keyword   string
+}) {
+	serversResp, err := this.RPC().ServerRPC().ListEnabledServersMatch(this.AdminContext(), &pb.ListEnabledServersMatchRequest{
+		Offset:            0,
+		Size:              100,
+		NodeClusterId:     params.ClusterId,
+		Keyword:           params.Keyword,
+		IgnoreServerNames: true,
+		IgnoreSSLCerts:    true,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var serverMaps = []maps.Map{}
+	for _, server := range serversResp.Servers {
+		if server.Id == params.ServerId {
+			continue
+		}
+
+		var clusterName = ""
+		if server.NodeCluster != nil {
+			clusterName = server.NodeCluster.Name
+		}
+		serverMaps = append(serverMaps, maps.Map{
+			"id":          server.Id,
+			"name":        server.Name,
+			"type":        server.Type,
+			"clusterName": clusterName,
+		})
+	}
+	this.Data["servers"] = serverMaps
+
+	this.Success()
+}
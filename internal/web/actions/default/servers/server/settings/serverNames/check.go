@@ -0,0 +1,34 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package serverNames
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// CheckAction 检查域名的CNAME、解析和CAA记录
+type CheckAction struct {
+	actionutils.ParentAction
+}
+
+func (this *CheckAction) RunPost(params struct {
+	ServerId int64
+}) {
+	results, err := serverutils.CheckServerDomains(this.AdminContext(), this.RPC(), params.ServerId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var countFailed = 0
+	for _, result := range results {
+		if !result.IsOk {
+			countFailed++
+		}
+	}
+	this.Data["results"] = results
+	this.Data["countFailed"] = countFailed
+
+	this.Success()
+}
@@ -17,6 +17,7 @@ func init() {
 			Prefix("/servers/server/settings/serverNames").
 			GetPost("", new(IndexAction)).
 			Post("/audit", new(AuditAction)).
+			Post("/check", new(CheckAction)).
 			EndAll()
 	})
 }
@@ -0,0 +1,64 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package yaml
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// ApplyAction 应用配置中有变化的部分
+type ApplyAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ApplyAction) RunPost(params struct {
+	ServerId int64
+	Yaml     string
+}) {
+	defer this.CreateLogInfo("通过YAML修改服务 %d 配置", params.ServerId)
+
+	plan, webId, err := buildPlan(this.Parent(), params.ServerId, params.Yaml)
+	if err != nil {
+		this.Fail("解析配置失败：" + err.Error())
+		return
+	}
+
+	// 导出后已被修改的分段不能覆盖，需要重新导出后再修改
+	var conflictSections = plan.ConflictSections()
+	if len(conflictSections) > 0 {
+		var names = []string{}
+		for _, section := range conflictSections {
+			names = append(names, section.Name)
+		}
+		this.Fail("以下部分在导出后已被修改，请重新导出后再修改：" + strings.Join(names, "、"))
+		return
+	}
+
+	// 各部分依次应用，中途失败时之前的部分已经生效，不会回滚
+	var appliedCodes = []string{}
+	var appliedNames = []string{}
+	for _, section := range plan.Sections {
+		if section.Action != configcodeutils.SectionActionUpdate {
+			continue
+		}
+		err = serverutils.ApplyServerConfigSection(this.AdminContext(), this.RPC(), params.ServerId, webId, section.Code, section.DesiredJSON)
+		if err != nil {
+			this.Data["appliedCodes"] = appliedCodes
+			var message = "应用'" + section.Name + "'失败：" + err.Error()
+			if len(appliedNames) > 0 {
+				message += "；此前的部分已经生效：" + strings.Join(appliedNames, "、")
+			}
+			this.Fail(message)
+			return
+		}
+		appliedCodes = append(appliedCodes, section.Code)
+		appliedNames = append(appliedNames, section.Name)
+	}
+
+	this.Data["appliedCodes"] = appliedCodes
+	this.Success()
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package yaml
+
+import (
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+	"github.com/iwind/TeaGo/types"
+)
+
+// DownloadAction 下载服务配置
+type DownloadAction struct {
+	actionutils.ParentAction
+}
+
+func (this *DownloadAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *DownloadAction) RunGet(params struct {
+	ServerId int64
+}) {
+	doc, _, err := serverutils.LoadServerConfigDocument(this.AdminContext(), this.RPC(), params.ServerId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	err = doc.SealRevisions()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	data, err := doc.EncodeYAML()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.AddHeader("Content-Type", "application/x-yaml")
+	this.AddHeader("Content-Disposition", "attachment; filename=\"server-"+types.String(params.ServerId)+".yaml\";")
+	this.AddHeader("Content-Length", strconv.Itoa(len(data)))
+	_, _ = this.Write(data)
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package yaml
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// IndexAction 以YAML形式查看和编辑服务配置
+type IndexAction struct {
+	actionutils.ParentAction
+}
+
+func (this *IndexAction) Init() {
+	this.Nav("", "setting", "index")
+	this.SecondMenu("yaml")
+}
+
+func (this *IndexAction) RunGet(params struct {
+	ServerId int64
+}) {
+	// 只有HTTP服务才支持
+	if this.FilterHTTPFamily() {
+		return
+	}
+
+	doc, _, err := serverutils.LoadServerConfigDocument(this.AdminContext(), this.RPC(), params.ServerId)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	err = doc.SealRevisions()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	data, err := doc.EncodeYAML()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["yaml"] = string(data)
+	this.Data["sections"] = configcodeutils.AllSections()
+
+	this.Show()
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package yaml
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// PlanAction 对比期望配置和当前配置
+type PlanAction struct {
+	actionutils.ParentAction
+}
+
+func (this *PlanAction) RunPost(params struct {
+	ServerId int64
+	Yaml     string
+}) {
+	plan, _, err := buildPlan(this.Parent(), params.ServerId, params.Yaml)
+	if err != nil {
+		this.Fail("解析配置失败：" + err.Error())
+		return
+	}
+
+	this.Data["plan"] = plan
+	this.Data["countChanges"] = plan.CountChanges()
+	this.Data["countConflicts"] = len(plan.ConflictSections())
+
+	this.Success()
+}
@@ -0,0 +1,28 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package yaml
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/serverutils"
+)
+
+// 根据提交的YAML生成计划
+func buildPlan(parent *actionutils.ParentAction, serverId int64, yamlData string) (plan *configcodeutils.Plan, webId int64, err error) {
+	desired, err := configcodeutils.DecodeYAML([]byte(yamlData))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	current, webId, err := serverutils.LoadServerConfigDocument(parent.AdminContext(), parent.RPC(), serverId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	plan, err = configcodeutils.BuildPlan(current, desired)
+	if err != nil {
+		return nil, 0, err
+	}
+	return plan, webId, nil
+}
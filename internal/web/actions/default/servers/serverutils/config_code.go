@@ -0,0 +1,331 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package serverutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+)
+
+// LoadServerConfigDocument 读取服务当前的配置文档
+func LoadServerConfigDocument(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64) (doc *configcodeutils.Document, webId int64, err error) {
+	serverResp, err := rpcClient.ServerRPC().FindEnabledServer(ctx, &pb.FindEnabledServerRequest{
+		ServerId:       serverId,
+		IgnoreSSLCerts: true,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	var server = serverResp.Server
+	if server == nil {
+		return nil, 0, errors.New("not found server with id '" + strconv.FormatInt(serverId, 10) + "'")
+	}
+
+	doc = configcodeutils.NewDocument(server.Id, server.Name)
+
+	// 协议
+	err = doc.SetSectionJSON(configcodeutils.SectionHTTP, server.HttpJSON)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = doc.SetSectionJSON(configcodeutils.SectionHTTPS, server.HttpsJSON)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 反向代理
+	reverseProxyResp, err := rpcClient.ServerRPC().FindAndInitServerReverseProxyConfig(ctx, &pb.FindAndInitServerReverseProxyConfigRequest{ServerId: serverId})
+	if err != nil {
+		return nil, 0, err
+	}
+	var reverseProxyRef *serverconfigs.ReverseProxyRef
+	if !utils.JSONIsNull(reverseProxyResp.ReverseProxyRefJSON) {
+		reverseProxyRef = &serverconfigs.ReverseProxyRef{}
+		err = json.Unmarshal(reverseProxyResp.ReverseProxyRefJSON, reverseProxyRef)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	reverseProxy, err := exportReverseProxy(ctx, rpcClient, reverseProxyRef)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = setDocumentSection(doc, configcodeutils.SectionReverseProxy, reverseProxy)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 集群中共享的缓存策略和WAF策略，仅用于审阅
+	err = loadSharedComponents(ctx, rpcClient, doc, serverId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Web设置
+	webConfig, err := dao.SharedHTTPWebDAO.FindWebConfigWithServerId(ctx, serverId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if webConfig == nil {
+		return doc, 0, nil
+	}
+
+	webSections, err := loadWebSections(ctx, rpcClient, webConfig, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	for code, value := range webSections {
+		err = setDocumentSection(doc, code, value)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return doc, webConfig.Id, nil
+}
+
+func setDocumentSection(doc *configcodeutils.Document, code string, value any) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return doc.SetSectionJSON(code, valueJSON)
+}
+
+// 读取网站所在集群共享的组件
+func loadSharedComponents(ctx context.Context, rpcClient *rpc.RPCClient, doc *configcodeutils.Document, serverId int64) error {
+	cachePolicy, err := dao.SharedHTTPCachePolicyDAO.FindEnabledHTTPCachePolicyWithServerId(ctx, serverId)
+	if err != nil {
+		return err
+	}
+	if cachePolicy != nil {
+		policyResp, err := rpcClient.HTTPCachePolicyRPC().FindEnabledHTTPCachePolicyConfig(ctx, &pb.FindEnabledHTTPCachePolicyConfigRequest{HttpCachePolicyId: cachePolicy.Id})
+		if err != nil {
+			return err
+		}
+		if !utils.JSONIsNull(policyResp.HttpCachePolicyJSON) {
+			var policy any
+			err = json.Unmarshal(policyResp.HttpCachePolicyJSON, &policy)
+			if err != nil {
+				return err
+			}
+			err = doc.SetShared("cachePolicy", policy)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	firewallPolicy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyWithServerId(ctx, serverId)
+	if err != nil {
+		return err
+	}
+	if firewallPolicy != nil {
+		policy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(ctx, firewallPolicy.Id)
+		if err != nil {
+			return err
+		}
+		if policy != nil {
+			// 集群WAF策略中的规则很多，只导出启用状态和分组
+			var groupMaps = []map[string]any{}
+			if policy.Inbound != nil {
+				for _, group := range policy.Inbound.Groups {
+					groupMaps = append(groupMaps, map[string]any{
+						"code": group.Code,
+						"name": group.Name,
+						"isOn": group.IsOn,
+					})
+				}
+			}
+			err = doc.SetShared("firewallPolicy", map[string]any{
+				"name":          policy.Name,
+				"isOn":          policy.IsOn,
+				"mode":          policy.Mode,
+				"inboundGroups": groupMaps,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyServerConfigSection 通过对应的RPC应用某个配置分段
+// 源站、路由规则、报头和重写规则会根据内容创建新的对象，然后替换原有的引用
+func ApplyServerConfigSection(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64, webId int64, code string, valueJSON []byte) error {
+	var requireWeb = func() error {
+		if webId <= 0 {
+			return errors.New("the server does not have web settings, can not apply '" + code + "'")
+		}
+		return nil
+	}
+
+	var err error
+	switch code {
+	case configcodeutils.SectionHTTP:
+		_, err = rpcClient.ServerRPC().UpdateServerHTTP(ctx, &pb.UpdateServerHTTPRequest{
+			ServerId: serverId,
+			HttpJSON: valueJSON,
+		})
+	case configcodeutils.SectionHTTPS:
+		_, err = rpcClient.ServerRPC().UpdateServerHTTPS(ctx, &pb.UpdateServerHTTPSRequest{
+			ServerId:  serverId,
+			HttpsJSON: valueJSON,
+		})
+	case configcodeutils.SectionReverseProxy:
+		err = applyReverseProxy(ctx, rpcClient, serverId, valueJSON)
+	case configcodeutils.SectionWeb:
+		if err = requireWeb(); err == nil {
+			_, err = rpcClient.HTTPWebRPC().UpdateHTTPWeb(ctx, &pb.UpdateHTTPWebRequest{
+				HttpWebId: webId,
+				RootJSON:  valueJSON,
+			})
+		}
+	case configcodeutils.SectionLocations:
+		if err = requireWeb(); err == nil {
+			err = applyLocations(ctx, rpcClient, serverId, webId, valueJSON)
+		}
+	case configcodeutils.SectionRequestHeaders, configcodeutils.SectionResponseHeaders:
+		if err = requireWeb(); err == nil {
+			err = applyHeaderPolicy(ctx, rpcClient, webId, code, valueJSON)
+		}
+	case configcodeutils.SectionCache:
+		if err = requireWeb(); err == nil {
+			_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebCache(ctx, &pb.UpdateHTTPWebCacheRequest{
+				HttpWebId: webId,
+				CacheJSON: valueJSON,
+			})
+		}
+	case configcodeutils.SectionWAF:
+		if err = requireWeb(); err == nil {
+			err = applyFirewall(ctx, rpcClient, serverId, webId, valueJSON)
+		}
+	case configcodeutils.SectionRedirects:
+		if err = requireWeb(); err == nil {
+			_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebHostRedirects(ctx, &pb.UpdateHTTPWebHostRedirectsRequest{
+				HttpWebId:         webId,
+				HostRedirectsJSON: valueJSON,
+			})
+		}
+	case configcodeutils.SectionRewrites:
+		if err = requireWeb(); err == nil {
+			err = applyRewriteRules(ctx, rpcClient, webId, valueJSON)
+		}
+	default:
+		err = errors.New("unknown section '" + code + "'")
+	}
+	return err
+}
+
+// 应用源站设置
+func applyReverseProxy(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64, valueJSON []byte) error {
+	var content *configReverseProxy
+	err := decodeSectionJSON(valueJSON, &content)
+	if err != nil {
+		return err
+	}
+	ref, err := createReverseProxy(ctx, rpcClient, content)
+	if err != nil {
+		return err
+	}
+	refJSON, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.ServerRPC().UpdateServerReverseProxy(ctx, &pb.UpdateServerReverseProxyRequest{
+		ServerId:         serverId,
+		ReverseProxyJSON: refJSON,
+	})
+	return err
+}
+
+// 应用路由规则
+func applyLocations(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64, webId int64, valueJSON []byte) error {
+	var contents = []*configLocation{}
+	err := decodeSectionJSON(valueJSON, &contents)
+	if err != nil {
+		return err
+	}
+	refs, err := createLocations(ctx, rpcClient, serverId, contents)
+	if err != nil {
+		return err
+	}
+	refsJSON, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebLocations(ctx, &pb.UpdateHTTPWebLocationsRequest{
+		HttpWebId:     webId,
+		LocationsJSON: refsJSON,
+	})
+	return err
+}
+
+// 应用请求报头或者响应报头
+func applyHeaderPolicy(ctx context.Context, rpcClient *rpc.RPCClient, webId int64, code string, valueJSON []byte) error {
+	var content *configHeaderPolicy
+	err := decodeSectionJSON(valueJSON, &content)
+	if err != nil {
+		return err
+	}
+	ref, err := createHeaderPolicy(ctx, rpcClient, content)
+	if err != nil {
+		return err
+	}
+	refJSON, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	if code == configcodeutils.SectionRequestHeaders {
+		_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebRequestHeader(ctx, &pb.UpdateHTTPWebRequestHeaderRequest{
+			HttpWebId:  webId,
+			HeaderJSON: refJSON,
+		})
+	} else {
+		_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebResponseHeader(ctx, &pb.UpdateHTTPWebResponseHeaderRequest{
+			HttpWebId:  webId,
+			HeaderJSON: refJSON,
+		})
+	}
+	return err
+}
+
+// 应用重写规则
+func applyRewriteRules(ctx context.Context, rpcClient *rpc.RPCClient, webId int64, valueJSON []byte) error {
+	var contents = []*configRewriteRule{}
+	err := decodeSectionJSON(valueJSON, &contents)
+	if err != nil {
+		return err
+	}
+	refs, err := createRewriteRules(ctx, rpcClient, contents)
+	if err != nil {
+		return err
+	}
+	refsJSON, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebRewriteRules(ctx, &pb.UpdateHTTPWebRewriteRulesRequest{
+		HttpWebId:        webId,
+		RewriteRulesJSON: refsJSON,
+	})
+	return err
+}
+
+// 解析分段内容，空值时保持默认值
+func decodeSectionJSON(valueJSON []byte, ptr any) error {
+	if utils.JSONIsNull(valueJSON) {
+		return nil
+	}
+	return json.Unmarshal(valueJSON, ptr)
+}
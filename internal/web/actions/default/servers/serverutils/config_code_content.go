@@ -0,0 +1,959 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package serverutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/configcodeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/dao"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs/firewallconfigs"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs/shared"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs/sslconfigs"
+	"github.com/iwind/TeaGo/types"
+)
+
+// 配置文档中展开的对象内容
+// 导出时把引用的对象展开为完整内容，应用时根据内容创建新的对象并替换引用，
+// 这样文档可以审阅，也可以应用到别的网站，而不会让多个网站共用同一个对象
+
+// 源站设置
+type configReverseProxy struct {
+	IsPrior bool                              `json:"isPrior"`
+	IsOn    bool                              `json:"isOn"`
+	Config  *serverconfigs.ReverseProxyConfig `json:"config,omitempty"`
+}
+
+// 路由规则
+type configLocation struct {
+	IsOn         bool                       `json:"isOn"`
+	Name         string                     `json:"name"`
+	Description  string                     `json:"description,omitempty"`
+	Pattern      string                     `json:"pattern"`
+	IsBreak      bool                       `json:"isBreak,omitempty"`
+	Conds        json.RawMessage            `json:"conds,omitempty"`
+	Domains      []string                   `json:"domains,omitempty"`
+	Web          map[string]json.RawMessage `json:"web,omitempty"` // 分段代号 => 内容
+	ReverseProxy *configReverseProxy        `json:"reverseProxy,omitempty"`
+	Children     []*configLocation          `json:"children,omitempty"`
+}
+
+// 报头策略
+type configHeaderPolicy struct {
+	IsPrior            bool            `json:"isPrior"`
+	IsOn               bool            `json:"isOn"`
+	SetHeaders         []*configHeader `json:"setHeaders"`
+	DeleteHeaders      []string        `json:"deleteHeaders,omitempty"`
+	NonStandardHeaders []string        `json:"nonStandardHeaders,omitempty"`
+	CORS               json.RawMessage `json:"cors,omitempty"`
+}
+
+// 单个报头
+type configHeader struct {
+	IsOn            bool            `json:"isOn"`
+	Name            string          `json:"name"`
+	Value           string          `json:"value"`
+	Status          []int32         `json:"status,omitempty"`
+	Methods         []string        `json:"methods,omitempty"`
+	Domains         []string        `json:"domains,omitempty"`
+	ShouldAppend    bool            `json:"shouldAppend,omitempty"`
+	DisableRedirect bool            `json:"disableRedirect,omitempty"`
+	ShouldReplace   bool            `json:"shouldReplace,omitempty"`
+	ReplaceValues   json.RawMessage `json:"replaceValues,omitempty"`
+}
+
+// 重写规则
+type configRewriteRule struct {
+	IsOn           bool            `json:"isOn"`
+	Pattern        string          `json:"pattern"`
+	Replace        string          `json:"replace"`
+	Mode           string          `json:"mode"`
+	RedirectStatus int             `json:"redirectStatus,omitempty"`
+	ProxyHost      string          `json:"proxyHost,omitempty"`
+	WithQuery      bool            `json:"withQuery,omitempty"`
+	IsBreak        bool            `json:"isBreak,omitempty"`
+	Conds          json.RawMessage `json:"conds,omitempty"`
+}
+
+// WAF策略中由IP名单等单独管理的部分，导出时去除，应用时保留目标网站原有的设置
+var firewallPreservedInboundKeys = []string{"allowListRef", "denyListRef", "greyListRef", "publicAllowListRefs", "publicDenyListRefs", "publicGreyListRefs", "groupRefs", "groups"}
+
+// WAF规则分组中可以从内容推导出来的部分
+var firewallDerivedKeys = []string{"id", "groupRefs", "setRefs", "ruleRefs"}
+
+// 读取Web设置中的分段内容
+func loadWebSections(ctx context.Context, rpcClient *rpc.RPCClient, webConfig *serverconfigs.HTTPWebConfig, withLocations bool) (map[string]any, error) {
+	// 去除不必要的部分，缓存策略在集群中共享，单独导出
+	if webConfig.Cache != nil {
+		for _, cacheRef := range webConfig.Cache.CacheRefs {
+			cacheRef.CachePolicy = nil
+		}
+	}
+
+	requestHeaderPolicy, err := exportHeaderPolicy(ctx, rpcClient, webConfig.RequestHeaderPolicyRef)
+	if err != nil {
+		return nil, err
+	}
+	responseHeaderPolicy, err := exportHeaderPolicy(ctx, rpcClient, webConfig.ResponseHeaderPolicyRef)
+	if err != nil {
+		return nil, err
+	}
+	firewall, err := exportFirewall(ctx, webConfig.FirewallRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections = map[string]any{
+		configcodeutils.SectionWeb:             webConfig.Root,
+		configcodeutils.SectionRequestHeaders:  requestHeaderPolicy,
+		configcodeutils.SectionResponseHeaders: responseHeaderPolicy,
+		configcodeutils.SectionCache:           webConfig.Cache,
+		configcodeutils.SectionWAF:             firewall,
+		configcodeutils.SectionRedirects:       webConfig.HostRedirects,
+		configcodeutils.SectionRewrites:        exportRewriteRules(webConfig),
+	}
+	if withLocations {
+		locations, err := exportLocations(ctx, rpcClient, webConfig.LocationRefs)
+		if err != nil {
+			return nil, err
+		}
+		sections[configcodeutils.SectionLocations] = locations
+	}
+	return sections, nil
+}
+
+// 导出源站设置
+func exportReverseProxy(ctx context.Context, rpcClient *rpc.RPCClient, ref *serverconfigs.ReverseProxyRef) (*configReverseProxy, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	var result = &configReverseProxy{
+		IsPrior: ref.IsPrior,
+		IsOn:    ref.IsOn,
+	}
+	if ref.ReverseProxyId <= 0 {
+		return result, nil
+	}
+
+	reverseProxyResp, err := rpcClient.ReverseProxyRPC().FindEnabledReverseProxyConfig(ctx, &pb.FindEnabledReverseProxyConfigRequest{ReverseProxyId: ref.ReverseProxyId})
+	if err != nil {
+		return nil, err
+	}
+	if utils.JSONIsNull(reverseProxyResp.ReverseProxyJSON) {
+		return result, nil
+	}
+	var config = serverconfigs.NewReverseProxyConfig()
+	err = json.Unmarshal(reverseProxyResp.ReverseProxyJSON, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 去除ID和引用，证书只保留ID，不导出证书内容
+	config.Id = 0
+	config.PrimaryOriginRefs = nil
+	config.BackupOriginRefs = nil
+	for _, origins := range [][]*serverconfigs.OriginConfig{config.PrimaryOrigins, config.BackupOrigins} {
+		for _, origin := range origins {
+			if origin == nil {
+				continue
+			}
+			origin.Id = 0
+			if origin.Cert != nil {
+				origin.Cert = &sslconfigs.SSLCertConfig{
+					Id:   origin.Cert.Id,
+					Name: origin.Cert.Name,
+				}
+			}
+		}
+	}
+	result.Config = config
+	return result, nil
+}
+
+// 根据内容创建源站设置，返回新的引用
+func createReverseProxy(ctx context.Context, rpcClient *rpc.RPCClient, content *configReverseProxy) (*serverconfigs.ReverseProxyRef, error) {
+	if content == nil {
+		return nil, nil
+	}
+	var ref = &serverconfigs.ReverseProxyRef{
+		IsPrior: content.IsPrior,
+		IsOn:    content.IsOn,
+	}
+	var config = content.Config
+	if config == nil {
+		return ref, nil
+	}
+
+	primaryOriginsJSON, err := createOrigins(ctx, rpcClient, config.PrimaryOrigins)
+	if err != nil {
+		return nil, err
+	}
+	backupOriginsJSON, err := createOrigins(ctx, rpcClient, config.BackupOrigins)
+	if err != nil {
+		return nil, err
+	}
+	var schedulingJSON []byte
+	if config.Scheduling != nil {
+		schedulingJSON, err = json.Marshal(config.Scheduling)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	createResp, err := rpcClient.ReverseProxyRPC().CreateReverseProxy(ctx, &pb.CreateReverseProxyRequest{
+		SchedulingJSON:     schedulingJSON,
+		PrimaryOriginsJSON: primaryOriginsJSON,
+		BackupOriginsJSON:  backupOriginsJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var reverseProxyId = createResp.ReverseProxyId
+
+	var timeoutJSONList = [][]byte{}
+	for _, timeout := range []*shared.TimeDuration{config.ConnTimeout, config.ReadTimeout, config.IdleTimeout} {
+		if timeout == nil {
+			timeout = &shared.TimeDuration{Count: 0, Unit: shared.TimeDurationUnitSecond}
+		}
+		timeoutJSON, err := json.Marshal(timeout)
+		if err != nil {
+			return nil, err
+		}
+		timeoutJSONList = append(timeoutJSONList, timeoutJSON)
+	}
+	var proxyProtocolJSON = []byte{}
+	if config.ProxyProtocol != nil {
+		proxyProtocolJSON, err = json.Marshal(config.ProxyProtocol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = rpcClient.ReverseProxyRPC().UpdateReverseProxy(ctx, &pb.UpdateReverseProxyRequest{
+		ReverseProxyId:           reverseProxyId,
+		RequestHostType:          types.Int32(config.RequestHostType),
+		RequestHost:              config.RequestHost,
+		RequestURI:               config.RequestURI,
+		StripPrefix:              config.StripPrefix,
+		AutoFlush:                config.AutoFlush,
+		AddHeaders:               config.AddHeaders,
+		ConnTimeoutJSON:          timeoutJSONList[0],
+		ReadTimeoutJSON:          timeoutJSONList[1],
+		IdleTimeoutJSON:          timeoutJSONList[2],
+		MaxConns:                 types.Int32(config.MaxConns),
+		MaxIdleConns:             types.Int32(config.MaxIdleConns),
+		ProxyProtocolJSON:        proxyProtocolJSON,
+		FollowRedirects:          config.FollowRedirects,
+		RequestHostExcludingPort: config.RequestHostExcludingPort,
+		Retry50X:                 config.Retry50X,
+		Retry40X:                 config.Retry40X,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ref.ReverseProxyId = reverseProxyId
+	return ref, nil
+}
+
+// 根据内容创建源站，返回新的源站引用
+func createOrigins(ctx context.Context, rpcClient *rpc.RPCClient, origins []*serverconfigs.OriginConfig) ([]byte, error) {
+	var refs = []*serverconfigs.OriginRef{}
+	for _, origin := range origins {
+		if origin == nil {
+			continue
+		}
+
+		var pbAddr *pb.NetworkAddress
+		if origin.Addr != nil {
+			pbAddr = &pb.NetworkAddress{
+				Protocol:  string(origin.Addr.Protocol),
+				Host:      origin.Addr.Host,
+				PortRange: origin.Addr.PortRange,
+			}
+		}
+
+		var ossJSON []byte
+		var err error
+		if origin.OSS != nil {
+			ossJSON, err = json.Marshal(origin.OSS)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var timeoutJSONList = [][]byte{}
+		for _, timeout := range []*shared.TimeDuration{origin.ConnTimeout, origin.ReadTimeout, origin.IdleTimeout} {
+			var timeoutJSON []byte
+			if timeout != nil {
+				timeoutJSON, err = json.Marshal(timeout)
+				if err != nil {
+					return nil, err
+				}
+			}
+			timeoutJSONList = append(timeoutJSONList, timeoutJSON)
+		}
+
+		var certRefJSON []byte
+		if origin.Cert != nil && origin.Cert.Id > 0 {
+			certRefJSON, err = json.Marshal(&sslconfigs.SSLCertRef{
+				IsOn:   true,
+				CertId: origin.Cert.Id,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		createResp, err := rpcClient.OriginRPC().CreateOrigin(ctx, &pb.CreateOriginRequest{
+			Name:            origin.Name,
+			Addr:            pbAddr,
+			OssJSON:         ossJSON,
+			Description:     origin.Description,
+			Weight:          types.Int32(origin.Weight),
+			IsOn:            origin.IsOn,
+			ConnTimeoutJSON: timeoutJSONList[0],
+			ReadTimeoutJSON: timeoutJSONList[1],
+			IdleTimeoutJSON: timeoutJSONList[2],
+			MaxConns:        types.Int32(origin.MaxConns),
+			MaxIdleConns:    types.Int32(origin.MaxIdleConns),
+			CertRefJSON:     certRefJSON,
+			Domains:         origin.Domains,
+			Host:            origin.RequestHost,
+			FollowPort:      origin.FollowPort,
+			Http2Enabled:    origin.HTTP2Enabled,
+		})
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, &serverconfigs.OriginRef{
+			IsOn:     true,
+			OriginId: createResp.OriginId,
+		})
+	}
+	return json.Marshal(refs)
+}
+
+// 导出路由规则
+func exportLocations(ctx context.Context, rpcClient *rpc.RPCClient, refs []*serverconfigs.HTTPLocationRef) ([]*configLocation, error) {
+	var result = []*configLocation{}
+	for _, ref := range refs {
+		if ref == nil || ref.LocationId <= 0 {
+			continue
+		}
+		locationResp, err := rpcClient.HTTPLocationRPC().FindEnabledHTTPLocationConfig(ctx, &pb.FindEnabledHTTPLocationConfigRequest{LocationId: ref.LocationId})
+		if err != nil {
+			return nil, err
+		}
+		if utils.JSONIsNull(locationResp.LocationJSON) {
+			continue
+		}
+		var location = &serverconfigs.HTTPLocationConfig{}
+		err = json.Unmarshal(locationResp.LocationJSON, location)
+		if err != nil {
+			return nil, err
+		}
+
+		var content = &configLocation{
+			IsOn:        ref.IsOn && location.IsOn,
+			Name:        location.Name,
+			Description: location.Description,
+			Pattern:     location.Pattern,
+			IsBreak:     location.IsBreak,
+			Domains:     location.Domains,
+		}
+		if location.Conds != nil {
+			content.Conds, err = json.Marshal(location.Conds)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Web设置
+		if location.Web != nil && location.Web.Id > 0 {
+			webConfig, err := dao.SharedHTTPWebDAO.FindWebConfigWithId(ctx, location.Web.Id)
+			if err != nil {
+				return nil, err
+			}
+			if webConfig != nil {
+				sections, err := loadWebSections(ctx, rpcClient, webConfig, false)
+				if err != nil {
+					return nil, err
+				}
+				content.Web = map[string]json.RawMessage{}
+				for code, value := range sections {
+					valueJSON, err := json.Marshal(value)
+					if err != nil {
+						return nil, err
+					}
+					if utils.JSONIsNull(valueJSON) {
+						continue
+					}
+					content.Web[code] = valueJSON
+				}
+			}
+		}
+
+		// 源站设置
+		content.ReverseProxy, err = exportReverseProxy(ctx, rpcClient, location.ReverseProxyRef)
+		if err != nil {
+			return nil, err
+		}
+
+		content.Children, err = exportLocations(ctx, rpcClient, ref.Children)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, content)
+	}
+	return result, nil
+}
+
+// 根据内容创建路由规则，返回新的引用
+func createLocations(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64, contents []*configLocation) ([]*serverconfigs.HTTPLocationRef, error) {
+	var refs = []*serverconfigs.HTTPLocationRef{}
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+
+		var condsJSON []byte
+		if !utils.JSONIsNull(content.Conds) {
+			condsJSON = content.Conds
+		}
+
+		createResp, err := rpcClient.HTTPLocationRPC().CreateHTTPLocation(ctx, &pb.CreateHTTPLocationRequest{
+			ParentId:    0,
+			Name:        content.Name,
+			Description: content.Description,
+			Pattern:     content.Pattern,
+			IsBreak:     content.IsBreak,
+			CondsJSON:   condsJSON,
+			Domains:     content.Domains,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var locationId = createResp.LocationId
+
+		if !content.IsOn {
+			_, err = rpcClient.HTTPLocationRPC().UpdateHTTPLocation(ctx, &pb.UpdateHTTPLocationRequest{
+				LocationId:  locationId,
+				Name:        content.Name,
+				Description: content.Description,
+				Pattern:     content.Pattern,
+				IsBreak:     content.IsBreak,
+				IsOn:        false,
+				CondsJSON:   condsJSON,
+				Domains:     content.Domains,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Web设置
+		if len(content.Web) > 0 {
+			webConfig, err := dao.SharedHTTPWebDAO.FindWebConfigWithLocationId(ctx, locationId)
+			if err != nil {
+				return nil, err
+			}
+			if webConfig == nil {
+				return nil, errors.New("can not find web settings of location '" + strconv.FormatInt(locationId, 10) + "'")
+			}
+			for _, section := range configcodeutils.AllSections() {
+				valueJSON, ok := content.Web[section.Code]
+				if !ok || section.Code == configcodeutils.SectionLocations {
+					continue
+				}
+				err = ApplyServerConfigSection(ctx, rpcClient, serverId, webConfig.Id, section.Code, valueJSON)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// 源站设置
+		if content.ReverseProxy != nil {
+			reverseProxyRef, err := createReverseProxy(ctx, rpcClient, content.ReverseProxy)
+			if err != nil {
+				return nil, err
+			}
+			refJSON, err := json.Marshal(reverseProxyRef)
+			if err != nil {
+				return nil, err
+			}
+			_, err = rpcClient.HTTPLocationRPC().UpdateHTTPLocationReverseProxy(ctx, &pb.UpdateHTTPLocationReverseProxyRequest{
+				LocationId:       locationId,
+				ReverseProxyJSON: refJSON,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		children, err := createLocations(ctx, rpcClient, serverId, content.Children)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, &serverconfigs.HTTPLocationRef{
+			IsOn:       true,
+			LocationId: locationId,
+			Children:   children,
+		})
+	}
+	return refs, nil
+}
+
+// 导出报头策略
+func exportHeaderPolicy(ctx context.Context, rpcClient *rpc.RPCClient, ref *shared.HTTPHeaderPolicyRef) (*configHeaderPolicy, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	var result = &configHeaderPolicy{
+		IsPrior:    ref.IsPrior,
+		IsOn:       ref.IsOn,
+		SetHeaders: []*configHeader{},
+	}
+	if ref.HeaderPolicyId <= 0 {
+		return result, nil
+	}
+
+	policyResp, err := rpcClient.HTTPHeaderPolicyRPC().FindEnabledHTTPHeaderPolicyConfig(ctx, &pb.FindEnabledHTTPHeaderPolicyConfigRequest{HttpHeaderPolicyId: ref.HeaderPolicyId})
+	if err != nil {
+		return nil, err
+	}
+	if utils.JSONIsNull(policyResp.HttpHeaderPolicyJSON) {
+		return result, nil
+	}
+	var policy = &shared.HTTPHeaderPolicy{}
+	err = json.Unmarshal(policyResp.HttpHeaderPolicyJSON, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, headerRef := range policy.SetHeaderRefs {
+		if headerRef == nil || headerRef.HeaderId <= 0 {
+			continue
+		}
+		header, err := exportHeader(ctx, rpcClient, headerRef.HeaderId)
+		if err != nil {
+			return nil, err
+		}
+		header.IsOn = headerRef.IsOn
+		result.SetHeaders = append(result.SetHeaders, header)
+	}
+	result.DeleteHeaders = policy.DeleteHeaders
+	result.NonStandardHeaders = policy.NonStandardHeaders
+	if policy.CORS != nil {
+		result.CORS, err = json.Marshal(policy.CORS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// 导出单个报头
+func exportHeader(ctx context.Context, rpcClient *rpc.RPCClient, headerId int64) (*configHeader, error) {
+	headerResp, err := rpcClient.HTTPHeaderRPC().FindEnabledHTTPHeaderConfig(ctx, &pb.FindEnabledHTTPHeaderConfigRequest{HeaderId: headerId})
+	if err != nil {
+		return nil, err
+	}
+
+	// 只解析创建报头时需要的字段
+	var header = &struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Status *struct {
+			Codes []int32 `json:"codes"`
+		} `json:"status"`
+		Methods         []string        `json:"methods"`
+		Domains         []string        `json:"domains"`
+		ShouldAppend    bool            `json:"shouldAppend"`
+		DisableRedirect bool            `json:"disableRedirect"`
+		ShouldReplace   bool            `json:"shouldReplace"`
+		ReplaceValues   json.RawMessage `json:"replaceValues"`
+	}{}
+	err = json.Unmarshal(headerResp.HeaderJSON, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = &configHeader{
+		Name:            header.Name,
+		Value:           header.Value,
+		Methods:         header.Methods,
+		Domains:         header.Domains,
+		ShouldAppend:    header.ShouldAppend,
+		DisableRedirect: header.DisableRedirect,
+		ShouldReplace:   header.ShouldReplace,
+	}
+	if header.Status != nil {
+		result.Status = header.Status.Codes
+	}
+	if !utils.JSONIsNull(header.ReplaceValues) {
+		result.ReplaceValues = header.ReplaceValues
+	}
+	return result, nil
+}
+
+// 根据内容创建报头策略，返回新的引用
+func createHeaderPolicy(ctx context.Context, rpcClient *rpc.RPCClient, content *configHeaderPolicy) (*shared.HTTPHeaderPolicyRef, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	createPolicyResp, err := rpcClient.HTTPHeaderPolicyRPC().CreateHTTPHeaderPolicy(ctx, &pb.CreateHTTPHeaderPolicyRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var policyId = createPolicyResp.HttpHeaderPolicyId
+
+	// 设置的报头
+	var headerRefs = []*shared.HTTPHeaderRef{}
+	for _, header := range content.SetHeaders {
+		if header == nil {
+			continue
+		}
+		var statusList = header.Status
+		if statusList == nil {
+			statusList = []int32{}
+		}
+		createResp, err := rpcClient.HTTPHeaderRPC().CreateHTTPHeader(ctx, &pb.CreateHTTPHeaderRequest{
+			Name:              header.Name,
+			Value:             header.Value,
+			Status:            statusList,
+			Methods:           header.Methods,
+			Domains:           header.Domains,
+			ShouldAppend:      header.ShouldAppend,
+			DisableRedirect:   header.DisableRedirect,
+			ShouldReplace:     header.ShouldReplace,
+			ReplaceValuesJSON: header.ReplaceValues,
+		})
+		if err != nil {
+			return nil, err
+		}
+		headerRefs = append(headerRefs, &shared.HTTPHeaderRef{
+			IsOn:     header.IsOn,
+			HeaderId: createResp.HeaderId,
+		})
+	}
+	headerRefsJSON, err := json.Marshal(headerRefs)
+	if err != nil {
+		return nil, err
+	}
+	_, err = rpcClient.HTTPHeaderPolicyRPC().UpdateHTTPHeaderPolicySettingHeaders(ctx, &pb.UpdateHTTPHeaderPolicySettingHeadersRequest{
+		HttpHeaderPolicyId: policyId,
+		HeadersJSON:        headerRefsJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 删除的报头
+	if len(content.DeleteHeaders) > 0 {
+		_, err = rpcClient.HTTPHeaderPolicyRPC().UpdateHTTPHeaderPolicyDeletingHeaders(ctx, &pb.UpdateHTTPHeaderPolicyDeletingHeadersRequest{
+			HttpHeaderPolicyId: policyId,
+			HeaderNames:        content.DeleteHeaders,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 非标报头
+	if len(content.NonStandardHeaders) > 0 {
+		_, err = rpcClient.HTTPHeaderPolicyRPC().UpdateHTTPHeaderPolicyNonStandardHeaders(ctx, &pb.UpdateHTTPHeaderPolicyNonStandardHeadersRequest{
+			HttpHeaderPolicyId: policyId,
+			HeaderNames:        content.NonStandardHeaders,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// CORS
+	if !utils.JSONIsNull(content.CORS) {
+		_, err = rpcClient.HTTPHeaderPolicyRPC().UpdateHTTPHeaderPolicyCORS(ctx, &pb.UpdateHTTPHeaderPolicyCORSRequest{
+			HttpHeaderPolicyId: policyId,
+			CorsJSON:           content.CORS,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &shared.HTTPHeaderPolicyRef{
+		IsPrior:        content.IsPrior,
+		IsOn:           content.IsOn,
+		HeaderPolicyId: policyId,
+	}, nil
+}
+
+// 导出重写规则
+func exportRewriteRules(webConfig *serverconfigs.HTTPWebConfig) []*configRewriteRule {
+	var result = []*configRewriteRule{}
+	for _, ref := range webConfig.RewriteRefs {
+		if ref == nil {
+			continue
+		}
+		for _, rule := range webConfig.RewriteRules {
+			if rule == nil || rule.Id != ref.RewriteRuleId {
+				continue
+			}
+			var content = &configRewriteRule{
+				IsOn:           ref.IsOn && rule.IsOn,
+				Pattern:        rule.Pattern,
+				Replace:        rule.Replace,
+				Mode:           string(rule.Mode),
+				RedirectStatus: types.Int(rule.RedirectStatus),
+				ProxyHost:      rule.ProxyHost,
+				WithQuery:      rule.WithQuery,
+				IsBreak:        rule.IsBreak,
+			}
+			if rule.Conds != nil {
+				condsJSON, err := json.Marshal(rule.Conds)
+				if err == nil {
+					content.Conds = condsJSON
+				}
+			}
+			result = append(result, content)
+			break
+		}
+	}
+	return result
+}
+
+// 根据内容创建重写规则，返回新的引用
+func createRewriteRules(ctx context.Context, rpcClient *rpc.RPCClient, contents []*configRewriteRule) ([]*serverconfigs.HTTPRewriteRef, error) {
+	var refs = []*serverconfigs.HTTPRewriteRef{}
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+		var condsJSON []byte
+		if !utils.JSONIsNull(content.Conds) {
+			condsJSON = content.Conds
+		}
+		createResp, err := rpcClient.HTTPRewriteRuleRPC().CreateHTTPRewriteRule(ctx, &pb.CreateHTTPRewriteRuleRequest{
+			Pattern:        content.Pattern,
+			Replace:        content.Replace,
+			Mode:           content.Mode,
+			RedirectStatus: types.Int32(content.RedirectStatus),
+			ProxyHost:      content.ProxyHost,
+			WithQuery:      content.WithQuery,
+			IsBreak:        content.IsBreak,
+			IsOn:           content.IsOn,
+			CondsJSON:      condsJSON,
+		})
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, &serverconfigs.HTTPRewriteRef{
+			IsOn:          true,
+			RewriteRuleId: createResp.RewriteRuleId,
+		})
+	}
+	return refs, nil
+}
+
+// 导出WAF设置，网站独立的WAF策略展开为完整内容
+func exportFirewall(ctx context.Context, ref *firewallconfigs.HTTPFirewallRef) (map[string]any, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	refJSON, err := json.Marshal(ref)
+	if err != nil {
+		return nil, err
+	}
+	var result = map[string]any{}
+	err = json.Unmarshal(refJSON, &result)
+	if err != nil {
+		return nil, err
+	}
+	var policyId = types.Int64(result["firewallPolicyId"])
+	delete(result, "firewallPolicyId")
+	if policyId <= 0 {
+		return result, nil
+	}
+
+	policy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(ctx, policyId)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return result, nil
+	}
+
+	var policyContent = map[string]any{}
+	for _, direction := range []struct {
+		code   string
+		config any
+	}{
+		{code: "inbound", config: policy.Inbound},
+		{code: "outbound", config: policy.Outbound},
+	} {
+		configJSON, err := json.Marshal(direction.config)
+		if err != nil {
+			return nil, err
+		}
+		if utils.JSONIsNull(configJSON) {
+			continue
+		}
+		var configMap = map[string]any{}
+		err = json.Unmarshal(configJSON, &configMap)
+		if err != nil {
+			return nil, err
+		}
+		var groups = configMap["groups"]
+		var content = configcodeutils.RemoveKeys(configMap, firewallPreservedInboundKeys...).(map[string]any)
+		if groups != nil {
+			content["groups"] = configcodeutils.RemoveKeys(groups, firewallDerivedKeys...)
+		}
+		policyContent[direction.code] = content
+	}
+	result["policy"] = policyContent
+	return result, nil
+}
+
+// 应用WAF设置
+func applyFirewall(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64, webId int64, valueJSON []byte) error {
+	if utils.JSONIsNull(valueJSON) {
+		_, err := rpcClient.HTTPWebRPC().UpdateHTTPWebFirewall(ctx, &pb.UpdateHTTPWebFirewallRequest{
+			HttpWebId:    webId,
+			FirewallJSON: valueJSON,
+		})
+		return err
+	}
+
+	var ref = map[string]any{}
+	err := json.Unmarshal(valueJSON, &ref)
+	if err != nil {
+		return err
+	}
+	var policyContent, _ = ref["policy"].(map[string]any)
+	delete(ref, "policy")
+
+	// 使用目标Web自己的WAF策略
+	webConfig, err := dao.SharedHTTPWebDAO.FindWebConfigWithId(ctx, webId)
+	if err != nil {
+		return err
+	}
+	if webConfig == nil {
+		return errors.New("can not find web '" + strconv.FormatInt(webId, 10) + "'")
+	}
+	var policyId int64
+	if webConfig.FirewallRef != nil {
+		policyId = webConfig.FirewallRef.FirewallPolicyId
+	}
+	if policyId <= 0 && policyContent != nil {
+		policyId, err = dao.SharedHTTPWebDAO.InitEmptyHTTPFirewallPolicy(ctx, 0, serverId, webId, ref["isOn"] == true)
+		if err != nil {
+			return err
+		}
+	}
+	ref["firewallPolicyId"] = policyId
+
+	refJSON, err := json.Marshal(ref)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.HTTPWebRPC().UpdateHTTPWebFirewall(ctx, &pb.UpdateHTTPWebFirewallRequest{
+		HttpWebId:    webId,
+		FirewallJSON: refJSON,
+	})
+	if err != nil {
+		return err
+	}
+
+	if policyContent == nil || policyId <= 0 {
+		return nil
+	}
+	return applyFirewallPolicy(ctx, rpcClient, policyId, policyContent)
+}
+
+// 应用WAF策略内容
+// 入站设置保留目标策略原有的IP名单和分组引用；规则分组通过导入合并，按分组代号覆盖已有分组
+func applyFirewallPolicy(ctx context.Context, rpcClient *rpc.RPCClient, policyId int64, policyContent map[string]any) error {
+	policy, err := dao.SharedHTTPFirewallPolicyDAO.FindEnabledHTTPFirewallPolicyConfig(ctx, policyId)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return errors.New("can not find firewall policy '" + strconv.FormatInt(policyId, 10) + "'")
+	}
+
+	var importingPolicy = map[string]any{}
+
+	for _, direction := range []string{"inbound", "outbound"} {
+		desired, ok := policyContent[direction].(map[string]any)
+		if !ok {
+			continue
+		}
+		if groups, ok := desired["groups"].([]any); ok && len(groups) > 0 {
+			importingPolicy[direction] = map[string]any{
+				"isOn":   true,
+				"groups": groups,
+			}
+		}
+		if direction != "inbound" {
+			continue
+		}
+
+		var inbound = map[string]any{}
+		if policy.Inbound != nil {
+			inboundJSON, err := json.Marshal(policy.Inbound)
+			if err != nil {
+				return err
+			}
+			err = json.Unmarshal(inboundJSON, &inbound)
+			if err != nil {
+				return err
+			}
+		}
+		for key, value := range desired {
+			var preserved = false
+			for _, preservedKey := range firewallPreservedInboundKeys {
+				if key == preservedKey {
+					preserved = true
+					break
+				}
+			}
+			if !preserved {
+				inbound[key] = value
+			}
+		}
+		inboundJSON, err := json.Marshal(inbound)
+		if err != nil {
+			return err
+		}
+		_, err = rpcClient.HTTPFirewallPolicyRPC().UpdateHTTPFirewallInboundConfig(ctx, &pb.UpdateHTTPFirewallInboundConfigRequest{
+			HttpFirewallPolicyId: policyId,
+			InboundJSON:          inboundJSON,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(importingPolicy) == 0 {
+		return nil
+	}
+	importingJSON, err := json.Marshal(importingPolicy)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.HTTPFirewallPolicyRPC().ImportHTTPFirewallPolicy(ctx, &pb.ImportHTTPFirewallPolicyRequest{
+		HttpFirewallPolicyId:   policyId,
+		HttpFirewallPolicyJSON: importingJSON,
+	})
+	return err
+}
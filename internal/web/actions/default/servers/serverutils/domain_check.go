@@ -0,0 +1,190 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package serverutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/domaincheckutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs"
+)
+
+// NewClusterDomainChecker 根据集群节点IP构造域名检查器
+func NewClusterDomainChecker(ctx context.Context, rpcClient *rpc.RPCClient, clusterId int64, expectedCNAME string) (*domaincheckutils.Checker, error) {
+	var nodeIPs = []string{}
+	if clusterId > 0 {
+		nodesResp, err := rpcClient.NodeRPC().FindAllEnabledNodesDNSWithNodeClusterId(ctx, &pb.FindAllEnabledNodesDNSWithNodeClusterIdRequest{
+			NodeClusterId: clusterId,
+			IsInstalled:   true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodesResp.Nodes {
+			if len(node.IpAddr) > 0 {
+				nodeIPs = append(nodeIPs, node.IpAddr)
+			}
+		}
+	}
+
+	var checker = domaincheckutils.NewChecker(expectedCNAME, nodeIPs)
+	checker.LookupCNAME = utils.LookupCNAME
+	checker.LookupIPs = func(domain string) ([]string, error) {
+		return utils.LookupIPWithTimeout(domain, 5000)
+	}
+	return checker, nil
+}
+
+// FindServerExpectedCNAME 读取网站应该设置的CNAME
+func FindServerExpectedCNAME(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64) (string, error) {
+	dnsResp, err := rpcClient.ServerRPC().FindEnabledServerDNS(ctx, &pb.FindEnabledServerDNSRequest{ServerId: serverId})
+	if err != nil {
+		return "", err
+	}
+	if len(dnsResp.DnsName) == 0 || dnsResp.Domain == nil || len(dnsResp.Domain.Name) == 0 {
+		return "", nil
+	}
+	return dnsResp.DnsName + "." + dnsResp.Domain.Name, nil
+}
+
+// CheckServerDomains 检查网站所有域名的CNAME、解析IP和CAA记录
+func CheckServerDomains(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64) ([]*domaincheckutils.CheckResult, error) {
+	serverResp, err := rpcClient.ServerRPC().FindEnabledServer(ctx, &pb.FindEnabledServerRequest{
+		ServerId:       serverId,
+		IgnoreSSLCerts: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var server = serverResp.Server
+	if server == nil {
+		return nil, errors.New("can not find server '" + strconv.FormatInt(serverId, 10) + "'")
+	}
+	var clusterId int64
+	if server.NodeCluster != nil {
+		clusterId = server.NodeCluster.Id
+	}
+
+	expectedCNAME, err := FindServerExpectedCNAME(ctx, rpcClient, serverId)
+	if err != nil {
+		return nil, err
+	}
+	checker, err := NewClusterDomainChecker(ctx, rpcClient, clusterId, expectedCNAME)
+	if err != nil {
+		return nil, err
+	}
+
+	serverNames, err := FindPlainServerNames(ctx, rpcClient, serverId)
+	if err != nil {
+		return nil, err
+	}
+	var results = []*domaincheckutils.CheckResult{}
+	for _, serverName := range serverNames {
+		results = append(results, checker.Check(serverName))
+	}
+	return results, nil
+}
+
+// FindPlainServerNames 读取网站的所有域名
+func FindPlainServerNames(ctx context.Context, rpcClient *rpc.RPCClient, serverId int64) ([]string, error) {
+	serverNamesResp, err := rpcClient.ServerRPC().FindServerNames(ctx, &pb.FindServerNamesRequest{ServerId: serverId})
+	if err != nil {
+		return nil, err
+	}
+	var serverNameConfigs = []*serverconfigs.ServerNameConfig{}
+	if len(serverNamesResp.ServerNamesJSON) > 0 {
+		err = json.Unmarshal(serverNamesResp.ServerNamesJSON, &serverNameConfigs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var serverNames = serverconfigs.PlainServerNames(serverNameConfigs)
+	if serverNames == nil {
+		serverNames = []string{}
+	}
+	return serverNames, nil
+}
+
+// CheckAllServerDomains 检查所有集群中网站的域名，并更新检查报告
+// 检查耗时可能比较长，所以每个网站都会重新构造上下文
+func CheckAllServerDomains(rpcClient *rpc.RPCClient, adminId int64) error {
+	if !domaincheckutils.SetChecking(true) {
+		return nil
+	}
+	defer domaincheckutils.SetChecking(false)
+
+	var ctx = rpcClient.Context(adminId)
+	clustersResp, err := rpcClient.NodeClusterRPC().FindAllEnabledNodeClusters(ctx, &pb.FindAllEnabledNodeClustersRequest{})
+	if err != nil {
+		return err
+	}
+
+	var countDomains = 0
+	var items = []*domaincheckutils.ReportItem{}
+	for _, cluster := range clustersResp.NodeClusters {
+		ctx = rpcClient.Context(adminId)
+		serversResp, err := rpcClient.ServerRPC().FindAllEnabledServersDNSWithNodeClusterId(ctx, &pb.FindAllEnabledServersDNSWithNodeClusterIdRequest{NodeClusterId: cluster.Id})
+		if err != nil {
+			return err
+		}
+		if len(serversResp.Servers) == 0 {
+			continue
+		}
+
+		checker, err := NewClusterDomainChecker(ctx, rpcClient, cluster.Id, "")
+		if err != nil {
+			return err
+		}
+
+		for _, server := range serversResp.Servers {
+			// 单个网站读取失败时记录错误，继续检查其他网站
+			ctx = rpcClient.Context(adminId)
+			serverNames, err := findServerCheckNames(ctx, rpcClient, checker, server.Id)
+			if err != nil {
+				items = append(items, &domaincheckutils.ReportItem{
+					ServerId:    server.Id,
+					ServerName:  server.Name,
+					ClusterId:   cluster.Id,
+					ClusterName: cluster.Name,
+					Error:       err.Error(),
+				})
+				continue
+			}
+			for _, serverName := range serverNames {
+				countDomains++
+				var result = checker.Check(serverName)
+				if result.IsOk {
+					continue
+				}
+				items = append(items, &domaincheckutils.ReportItem{
+					ServerId:    server.Id,
+					ServerName:  server.Name,
+					ClusterId:   cluster.Id,
+					ClusterName: cluster.Name,
+					Result:      result,
+				})
+			}
+		}
+	}
+
+	domaincheckutils.UpdateReport(time.Now().Unix(), countDomains, items)
+	return nil
+}
+
+// 读取网站需要检查的域名，并设置检查器中期望的CNAME
+func findServerCheckNames(ctx context.Context, rpcClient *rpc.RPCClient, checker *domaincheckutils.Checker, serverId int64) ([]string, error) {
+	expectedCNAME, err := FindServerExpectedCNAME(ctx, rpcClient, serverId)
+	if err != nil {
+		return nil, err
+	}
+	checker.ExpectedCNAME = expectedCNAME
+
+	return FindPlainServerNames(ctx, rpcClient, serverId)
+}
@@ -437,6 +437,16 @@ func (this *ServerHelper) createSettingsMenu(secondMenuItem string, serverIdStri
 			"isActive": secondMenuItem == "common",
 			"isOn":     serverConfig.Web != nil && serverConfig.Web.MergeSlashes,
 		})
+		menuItems = append(menuItems, maps.Map{
+			"name":     "YAML配置",
+			"url":      "/servers/server/settings/yaml?serverId=" + serverIdString,
+			"isActive": secondMenuItem == "yaml",
+		})
+		menuItems = append(menuItems, maps.Map{
+			"name":     "复制配置",
+			"url":      "/servers/server/settings/clone?serverId=" + serverIdString,
+			"isActive": secondMenuItem == "clone",
+		})
 	} else if serverConfig.IsTCPFamily() {
 		menuItems = append(menuItems, maps.Map{
 			"name":     this.Lang(actionPtr, codes.Server_MenuSettingDNS),
@@ -0,0 +1,330 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package serverutils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/reportutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/logs"
+)
+
+// 排行中最多显示的条目数
+const trafficReportRankSize = 10
+
+// FindTrafficReportServers 读取报表中包含的网站
+func FindTrafficReportServers(ctx context.Context, rpcClient *rpc.RPCClient, schedule *reportutils.Schedule) ([]*pb.Server, error) {
+	var result = []*pb.Server{}
+	var serverIdMap = map[int64]bool{}
+	var addServer = func(server *pb.Server) {
+		if server == nil || serverIdMap[server.Id] || len(result) >= reportutils.MaxServersPerReport {
+			return
+		}
+		serverIdMap[server.Id] = true
+		result = append(result, server)
+	}
+
+	switch schedule.TargetType {
+	case reportutils.TargetServer:
+		for _, serverId := range schedule.TargetIds {
+			serverResp, err := rpcClient.ServerRPC().FindEnabledServer(ctx, &pb.FindEnabledServerRequest{
+				ServerId:       serverId,
+				IgnoreSSLCerts: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+			addServer(serverResp.Server)
+		}
+	case reportutils.TargetUser, reportutils.TargetGroup:
+		for _, targetId := range schedule.TargetIds {
+			var req = &pb.ListEnabledServersMatchRequest{
+				Offset:            0,
+				Size:              reportutils.MaxServersPerReport,
+				IgnoreServerNames: true,
+				IgnoreSSLCerts:    true,
+			}
+			if schedule.TargetType == reportutils.TargetUser {
+				req.UserId = targetId
+			} else {
+				req.ServerGroupId = targetId
+			}
+			serversResp, err := rpcClient.ServerRPC().ListEnabledServersMatch(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			for _, server := range serversResp.Servers {
+				addServer(server)
+			}
+		}
+	default:
+		return nil, errors.New("invalid target type '" + schedule.TargetType + "'")
+	}
+	return result, nil
+}
+
+// BuildTrafficReport 生成某个时间发送的报表数据
+func BuildTrafficReport(ctx context.Context, rpcClient *rpc.RPCClient, schedule *reportutils.Schedule, runAt time.Time) (*reportutils.Report, error) {
+	dayFrom, dayTo := schedule.Range(runAt)
+	var report = &reportutils.Report{
+		Title:       schedule.Name,
+		DayFrom:     dayFrom.Format("20060102"),
+		DayTo:       dayTo.Format("20060102"),
+		Month:       dayTo.Format("200601"),
+		GeneratedAt: time.Now(),
+		Servers:     []*reportutils.ServerReport{},
+	}
+
+	servers, err := FindTrafficReportServers(ctx, rpcClient, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	// 需要读取的天数，从开始日期到今天
+	var days = int32(time.Since(dayFrom).Hours()/24) + 1
+
+	for _, server := range servers {
+		var serverReport = &reportutils.ServerReport{
+			ServerId:   server.Id,
+			ServerName: server.Name,
+			DailyStats: []*reportutils.DailyStat{},
+		}
+		var dailyStatMap = map[string]*reportutils.DailyStat{} // day => stat
+
+		// 每日流量
+		dailyResp, err := rpcClient.ServerDailyStatRPC().FindLatestServerDailyStats(ctx, &pb.FindLatestServerDailyStatsRequest{
+			ServerId: server.Id,
+			Days:     days,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range dailyResp.Stats {
+			if stat.Day < report.DayFrom || stat.Day > report.DayTo {
+				continue
+			}
+			var dailyStat = &reportutils.DailyStat{
+				Day:                 stat.Day,
+				Bytes:               stat.Bytes,
+				CachedBytes:         stat.CachedBytes,
+				CountRequests:       stat.CountRequests,
+				CountCachedRequests: stat.CountCachedRequests,
+			}
+			dailyStatMap[stat.Day] = dailyStat
+			serverReport.DailyStats = append(serverReport.DailyStats, dailyStat)
+		}
+
+		// WAF
+		wafResp, err := rpcClient.ServerHTTPFirewallDailyStatRPC().ComposeServerHTTPFirewallDashboard(ctx, &pb.ComposeServerHTTPFirewallDashboardRequest{
+			Day:      report.DayTo,
+			ServerId: server.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var findDailyStat = func(day string) *reportutils.DailyStat {
+			if day < report.DayFrom || day > report.DayTo {
+				return nil
+			}
+			dailyStat, ok := dailyStatMap[day]
+			if !ok {
+				dailyStat = &reportutils.DailyStat{Day: day}
+				dailyStatMap[day] = dailyStat
+				serverReport.DailyStats = append(serverReport.DailyStats, dailyStat)
+			}
+			return dailyStat
+		}
+		for _, stat := range wafResp.BlockDailyStats {
+			var dailyStat = findDailyStat(stat.Day)
+			if dailyStat != nil {
+				dailyStat.CountWAFBlocks = stat.Count
+			}
+		}
+		for _, stat := range wafResp.CaptchaDailyStats {
+			var dailyStat = findDailyStat(stat.Day)
+			if dailyStat != nil {
+				dailyStat.CountWAFCaptchas = stat.Count
+			}
+		}
+		for _, stat := range wafResp.LogDailyStats {
+			var dailyStat = findDailyStat(stat.Day)
+			if dailyStat != nil {
+				dailyStat.CountWAFLogs = stat.Count
+			}
+		}
+		for _, group := range wafResp.HttpFirewallRuleGroups {
+			if group.HttpFirewallRuleGroup == nil {
+				continue
+			}
+			serverReport.WAFGroups = append(serverReport.WAFGroups, &reportutils.RankItem{
+				Name:  group.HttpFirewallRuleGroup.Name,
+				Count: group.Count,
+			})
+		}
+		sort.Slice(serverReport.DailyStats, func(i, j int) bool {
+			return serverReport.DailyStats[i].Day < serverReport.DailyStats[j].Day
+		})
+
+		// 地区
+		countriesResp, err := rpcClient.ServerRegionCountryMonthlyStatRPC().FindTopServerRegionCountryMonthlyStats(ctx, &pb.FindTopServerRegionCountryMonthlyStatsRequest{
+			Month:    report.Month,
+			ServerId: server.Id,
+			Offset:   0,
+			Size:     trafficReportRankSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range countriesResp.Stats {
+			if stat.RegionCountry == nil {
+				continue
+			}
+			serverReport.Countries = append(serverReport.Countries, &reportutils.RankItem{
+				Name:  stat.RegionCountry.Name,
+				Count: stat.Count,
+			})
+		}
+
+		// 运营商
+		providersResp, err := rpcClient.ServerRegionProviderMonthlyStatRPC().FindTopServerRegionProviderMonthlyStats(ctx, &pb.FindTopServerRegionProviderMonthlyStatsRequest{
+			Month:    report.Month,
+			ServerId: server.Id,
+			Offset:   0,
+			Size:     trafficReportRankSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range providersResp.Stats {
+			if stat.RegionProvider == nil {
+				continue
+			}
+			serverReport.Providers = append(serverReport.Providers, &reportutils.RankItem{
+				Name:  stat.RegionProvider.Name,
+				Count: stat.Count,
+			})
+		}
+
+		// 操作系统
+		systemsResp, err := rpcClient.ServerClientSystemMonthlyStatRPC().FindTopServerClientSystemMonthlyStats(ctx, &pb.FindTopServerClientSystemMonthlyStatsRequest{
+			ServerId: server.Id,
+			Month:    report.Month,
+			Offset:   0,
+			Size:     trafficReportRankSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range systemsResp.Stats {
+			if stat.ClientSystem == nil {
+				continue
+			}
+			serverReport.Systems = append(serverReport.Systems, &reportutils.RankItem{
+				Name:  stat.ClientSystem.Name + " " + stat.Version,
+				Count: stat.Count,
+			})
+		}
+
+		// 浏览器
+		browsersResp, err := rpcClient.ServerClientBrowserMonthlyStatRPC().FindTopServerClientBrowserMonthlyStats(ctx, &pb.FindTopServerClientBrowserMonthlyStatsRequest{
+			ServerId: server.Id,
+			Month:    report.Month,
+			Offset:   0,
+			Size:     trafficReportRankSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range browsersResp.Stats {
+			if stat.ClientBrowser == nil {
+				continue
+			}
+			serverReport.Browsers = append(serverReport.Browsers, &reportutils.RankItem{
+				Name:  stat.ClientBrowser.Name + " " + stat.Version,
+				Count: stat.Count,
+			})
+		}
+
+		report.Servers = append(report.Servers, serverReport)
+	}
+
+	return report, nil
+}
+
+// SendTrafficReport 生成并发送报表
+func SendTrafficReport(ctx context.Context, rpcClient *rpc.RPCClient, schedule *reportutils.Schedule, runAt time.Time) error {
+	smtpConfig, err := configloaders.LoadSMTPConfig()
+	if err != nil {
+		return err
+	}
+	if !smtpConfig.IsConfigured() {
+		return errors.New("尚未设置发信服务器")
+	}
+
+	report, err := BuildTrafficReport(ctx, rpcClient, schedule, runAt)
+	if err != nil {
+		return err
+	}
+	html, err := reportutils.RenderHTML(report)
+	if err != nil {
+		return err
+	}
+	xlsxData, err := reportutils.RenderXLSX(report)
+	if err != nil {
+		return err
+	}
+
+	return reportutils.SendMail(smtpConfig, &reportutils.Mail{
+		To:      schedule.Emails,
+		Subject: report.Title + "（" + reportutils.FormatDay(report.DayFrom) + " 至 " + reportutils.FormatDay(report.DayTo) + "）",
+		HTML:    html,
+		Attachments: []*reportutils.Attachment{
+			{
+				Name:        "report-" + report.DayFrom + "-" + report.DayTo + ".xlsx",
+				ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				Data:        xlsxData,
+			},
+		},
+	})
+}
+
+// CheckScheduledTrafficReports 发送到了发送时间的报表
+func CheckScheduledTrafficReports(rpcClient *rpc.RPCClient) error {
+	store, err := configloaders.LoadServerReportStore()
+	if err != nil {
+		return err
+	}
+
+	var now = time.Now()
+	for _, schedule := range store.FindDueSchedules(now.Unix()) {
+		var sendErr = SendTrafficReport(rpcClient.Context(0), rpcClient, schedule, now)
+		if sendErr != nil {
+			logs.Println("[TRAFFIC_REPORT]send report '" + schedule.Name + "' failed: " + sendErr.Error())
+		}
+
+		var scheduleId = schedule.Id
+		err = configloaders.UpdateServerReportStore(func(store *reportutils.Store) error {
+			var schedule = store.Find(scheduleId)
+			if schedule == nil {
+				return nil
+			}
+			schedule.LastRunAt = now.Unix()
+			schedule.LastError = ""
+			if sendErr != nil {
+				schedule.LastError = sendErr.Error()
+			}
+			schedule.ResetNextRunAt(now)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
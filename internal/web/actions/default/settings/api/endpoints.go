@@ -0,0 +1,78 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/apiendpointutils"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// 当前管理系统使用的API地址，以及最近一次测速结果
+func loadEndpointsMap() (maps.Map, error) {
+	config, err := configs.LoadAPIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var tierMap = map[string]int{} // endpoint => tier
+	for index, tier := range config.RPCEndpointTiers {
+		for _, endpoint := range tier {
+			tierMap[endpoint] = index
+		}
+	}
+	var countTiers = len(config.RPCEndpointTiers)
+
+	var resultMap = map[string]*apiendpointutils.ProbeResult{} // endpoint => result
+	var probedTime = ""
+	var snapshot = apiendpointutils.LastSnapshot()
+	if snapshot != nil {
+		for _, result := range snapshot.Results {
+			resultMap[result.Endpoint] = result
+		}
+		probedTime = timeutil.FormatTime("Y-m-d H:i:s", snapshot.ProbedAt)
+	}
+
+	var endpointMaps = []maps.Map{}
+	for _, endpoint := range config.RPCEndpoints {
+		var tier = -1
+		if countTiers > 1 {
+			tierIndex, ok := tierMap[endpoint]
+			if ok {
+				tier = tierIndex
+			} else {
+				tier = countTiers
+			}
+		}
+
+		var rttText = ""
+		var isOk = true
+		var errString = ""
+		result, ok := resultMap[endpoint]
+		if ok {
+			isOk = result.IsOk
+			errString = result.Error
+			if result.IsOk {
+				rttText = fmt.Sprintf("%.2fms", float64(result.RTT.Microseconds())/1000)
+			}
+		}
+
+		endpointMaps = append(endpointMaps, maps.Map{
+			"endpoint": endpoint,
+			"tier":     tier,
+			"isProbed": ok,
+			"isOk":     isOk,
+			"rtt":      rttText,
+			"error":    errString,
+		})
+	}
+
+	return maps.Map{
+		"isPinned":   config.RPCDisableUpdate,
+		"endpoints":  endpointMaps,
+		"probedTime": probedTime,
+	}, nil
+}
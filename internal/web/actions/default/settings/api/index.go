@@ -127,5 +127,13 @@ func (this *IndexAction) RunGet(params struct{}) {
 	}
 	this.Data["hasMethodStats"] = countMethodStatsResp.Count > 0
 
+	// 当前管理系统使用的API地址
+	endpointsMap, err := loadEndpointsMap()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["currentEndpoints"] = endpointsMap
+
 	this.Show()
 }
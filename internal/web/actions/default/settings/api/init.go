@@ -19,6 +19,8 @@ func init() {
 			Get("/methodStats", new(MethodStatsAction)).
 			GetPost("/node/createPopup", new(node.CreatePopupAction)).
 			Post("/delete", new(DeleteAction)).
+			Post("/probeEndpoints", new(ProbeEndpointsAction)).
+			Post("/updateEndpointsPin", new(UpdateEndpointsPinAction)).
 			EndAll()
 	})
 }
@@ -0,0 +1,43 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package api
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/tasks"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/apiendpointutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// ProbeEndpointsAction 立即测试API地址延迟
+type ProbeEndpointsAction struct {
+	actionutils.ParentAction
+}
+
+func (this *ProbeEndpointsAction) RunPost(params struct{}) {
+	config, err := configs.LoadAPIConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	if config.RPCDisableUpdate {
+		// 已固定地址时只测试，不修改配置
+		var results = apiendpointutils.ProbeAll(config.RPCEndpoints, apiendpointutils.DefaultSamples, apiendpointutils.DefaultTimeout)
+		apiendpointutils.SaveSnapshot(&apiendpointutils.Snapshot{
+			Results:  results,
+			Tiers:    apiendpointutils.BuildTiers(results),
+			ProbedAt: time.Now().Unix(),
+		})
+	} else {
+		err = tasks.NewSyncAPINodesTask().Loop()
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+	}
+
+	this.Success()
+}
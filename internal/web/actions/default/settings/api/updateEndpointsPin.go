@@ -0,0 +1,38 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package api
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/Tea"
+)
+
+// UpdateEndpointsPinAction 固定或取消固定当前使用的API地址
+type UpdateEndpointsPinAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UpdateEndpointsPinAction) RunPost(params struct {
+	IsPinned bool
+}) {
+	if params.IsPinned {
+		defer this.CreateLogInfo("固定管理系统使用的API节点地址")
+	} else {
+		defer this.CreateLogInfo("取消固定管理系统使用的API节点地址")
+	}
+
+	config, err := configs.LoadAPIConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	config.RPCDisableUpdate = params.IsPinned
+	err = config.WriteFile(Tea.ConfigFile(configs.ConfigFileName))
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbgrowthutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/settingutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/TeaOSLab/EdgeCommon/pkg/systemconfigs"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// GrowthAction 数据表增长分析和保留天数设置
+type GrowthAction struct {
+	actionutils.ParentAction
+}
+
+func (this *GrowthAction) Init() {
+	this.Nav("", "", "growth")
+}
+
+func (this *GrowthAction) RunGet(params struct{}) {
+	store, err := settingutils.LoadDBGrowthStore(this.AdminContext(), this.RPC())
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	config, err := this.readDatabaseConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 概要
+	var latest = store.Latest()
+	var totalBytes int64
+	var snapshotTime = ""
+	if latest != nil {
+		totalBytes = latest.Bytes("")
+		snapshotTime = timeutil.FormatTime("Y-m-d H:i:s", latest.CreatedAt)
+	}
+	var totalGrowth = store.DailyGrowth("", dbgrowthutils.GrowthWindowDays)
+	daysUntilFull, hasDaysUntilFull := store.DaysUntilFull()
+	_, shouldWarn := store.ShouldWarn()
+	this.Data["summary"] = maps.Map{
+		"hasSnapshots":      latest != nil,
+		"countSnapshots":    len(store.Snapshots),
+		"snapshotTime":      snapshotTime,
+		"totalBytes":        numberutils.FormatBytes(totalBytes),
+		"dailyGrowth":       formatGrowthBytes(totalGrowth),
+		"project30Bytes":    numberutils.FormatBytes(store.ProjectBytes("", 30)),
+		"project90Bytes":    numberutils.FormatBytes(store.ProjectBytes("", 90)),
+		"diskCapacityGB":    math.Round(float64(store.DiskCapacityBytes)*100/(1<<30)) / 100,
+		"warningDays":       store.WarningDays,
+		"hasDaysUntilFull":  hasDaysUntilFull,
+		"daysUntilFull":     daysUntilFull,
+		"shouldWarn":        shouldWarn,
+		"diskCapacityIsSet": store.DiskCapacityBytes > 0,
+	}
+
+	// 各个分类
+	var familyMaps = []maps.Map{}
+	for _, family := range dbgrowthutils.AllFamilies() {
+		var stat *dbgrowthutils.FamilyStat
+		if latest != nil {
+			stat = latest.FindFamily(family.Code)
+		}
+		if stat == nil {
+			stat = &dbgrowthutils.FamilyStat{Code: family.Code}
+		}
+		var growth = store.DailyGrowth(family.Code, dbgrowthutils.GrowthWindowDays)
+
+		var retentionDays = 0
+		var retentionDaysPtr = findRetentionDays(config, family.Code)
+		if retentionDaysPtr != nil {
+			retentionDays = *retentionDaysPtr
+		}
+
+		familyMaps = append(familyMaps, maps.Map{
+			"code":            family.Code,
+			"name":            family.Name,
+			"hasRetention":    retentionDaysPtr != nil,
+			"retentionDays":   retentionDays,
+			"countTables":     stat.CountTables,
+			"rows":            numberutils.FormatInt64(stat.Rows),
+			"bytes":           stat.Bytes,
+			"formattedBytes":  numberutils.FormatBytes(stat.Bytes),
+			"dailyGrowth":     math.Round(growth),
+			"formattedGrowth": formatGrowthBytes(growth),
+			"project30Bytes":  numberutils.FormatBytes(store.ProjectBytes(family.Code, 30)),
+		})
+	}
+	this.Data["families"] = familyMaps
+
+	// 图表
+	var chartDays = []string{}
+	var chartSeries = []maps.Map{}
+	for _, snapshot := range store.Snapshots {
+		chartDays = append(chartDays, snapshot.Day[4:6]+"-"+snapshot.Day[6:])
+	}
+	for _, family := range dbgrowthutils.AllFamilies() {
+		var values = []int64{}
+		var hasValues = false
+		for _, snapshot := range store.Snapshots {
+			var bytes = snapshot.Bytes(family.Code)
+			if bytes > 0 {
+				hasValues = true
+			}
+			values = append(values, bytes)
+		}
+		if !hasValues {
+			continue
+		}
+		chartSeries = append(chartSeries, maps.Map{
+			"name":   family.Name,
+			"values": values,
+		})
+	}
+	this.Data["chartDays"] = chartDays
+	this.Data["chartSeries"] = chartSeries
+
+	this.Show()
+}
+
+func (this *GrowthAction) RunPost(params struct {
+	RetentionJSON  []byte
+	DiskCapacityGB float64
+	WarningDays    int
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("修改数据表保留天数和数据库磁盘容量")
+
+	params.Must.
+		Field("diskCapacityGB", params.DiskCapacityGB).
+		Gte(0, "磁盘容量不能小于0").
+		Field("warningDays", params.WarningDays).
+		Gte(0, "提醒天数不能小于0")
+
+	var retentionMap = map[string]int{}
+	if len(params.RetentionJSON) > 0 {
+		err := json.Unmarshal(params.RetentionJSON, &retentionMap)
+		if err != nil {
+			this.Fail("保留天数数据错误：" + err.Error())
+			return
+		}
+	}
+
+	// 保留天数
+	config, err := this.readDatabaseConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	for code, days := range retentionMap {
+		var retentionDaysPtr = findRetentionDays(config, code)
+		if retentionDaysPtr == nil {
+			continue
+		}
+		if days < 0 {
+			days = 0
+		}
+		*retentionDaysPtr = days
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	_, err = this.RPC().SysSettingRPC().UpdateSysSetting(this.AdminContext(), &pb.UpdateSysSettingRequest{
+		Code:      systemconfigs.SettingCodeDatabaseConfigSetting,
+		ValueJSON: configJSON,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 磁盘容量
+	err = settingutils.UpdateDBGrowthStore(this.AdminContext(), this.RPC(), func(store *dbgrowthutils.Store) error {
+		store.DiskCapacityBytes = int64(params.DiskCapacityGB * (1 << 30))
+		store.WarningDays = params.WarningDays
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
+
+func (this *GrowthAction) readDatabaseConfig() (*systemconfigs.DatabaseConfig, error) {
+	configResp, err := this.RPC().SysSettingRPC().ReadSysSetting(this.AdminContext(), &pb.ReadSysSettingRequest{Code: systemconfigs.SettingCodeDatabaseConfigSetting})
+	if err != nil {
+		return nil, err
+	}
+	var config = systemconfigs.NewDatabaseConfig()
+	if len(configResp.ValueJSON) > 0 {
+		err = json.Unmarshal(configResp.ValueJSON, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// 查找数据表分类对应的保留天数设置
+func findRetentionDays(config *systemconfigs.DatabaseConfig, familyCode string) *int {
+	switch familyCode {
+	case dbgrowthutils.FamilyAccessLogs:
+		return &config.ServerAccessLog.Clean.Days
+	case dbgrowthutils.FamilyServerBandwidthStats:
+		return &config.ServerBandwidthStat.Clean.Days
+	case dbgrowthutils.FamilyUserBandwidthStats:
+		return &config.UserBandwidthStat.Clean.Days
+	case dbgrowthutils.FamilyUserPlanBandwidthStats:
+		return &config.UserPlanBandwidthStat.Clean.Days
+	case dbgrowthutils.FamilyServerDailyStats:
+		return &config.ServerDailyStat.Clean.Days
+	case dbgrowthutils.FamilyServerDomainHourlyStats:
+		return &config.ServerDomainHourlyStat.Clean.Days
+	case dbgrowthutils.FamilyTrafficDailyStats:
+		return &config.TrafficDailyStat.Clean.Days
+	case dbgrowthutils.FamilyTrafficHourlyStats:
+		return &config.TrafficHourlyStat.Clean.Days
+	case dbgrowthutils.FamilyNodeClusterTrafficDailyStats:
+		return &config.NodeClusterTrafficDailyStat.Clean.Days
+	case dbgrowthutils.FamilyNodeTrafficDailyStats:
+		return &config.NodeTrafficDailyStat.Clean.Days
+	case dbgrowthutils.FamilyNodeTrafficHourlyStats:
+		return &config.NodeTrafficHourlyStat.Clean.Days
+	case dbgrowthutils.FamilyHTTPCacheTasks:
+		return &config.HTTPCacheTask.Clean.Days
+	}
+	return nil
+}
+
+// 格式化每日增长
+func formatGrowthBytes(growth float64) string {
+	if growth < 0 {
+		return "-" + numberutils.FormatBytes(int64(-growth)) + "/天"
+	}
+	return "+" + numberutils.FormatBytes(int64(growth)) + "/天"
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/settings/settingutils"
+)
+
+// GrowthSnapshotAction 立即更新当天的数据表快照
+type GrowthSnapshotAction struct {
+	actionutils.ParentAction
+}
+
+func (this *GrowthSnapshotAction) RunPost(params struct{}) {
+	err := settingutils.TakeDBTableSnapshot(this.AdminContext(), this.RPC(), true)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
@@ -19,6 +19,16 @@ func init() {
 			GetPost("/cleanSetting", new(CleanSettingAction)).
 			GetPost("/truncateTable", new(TruncateTableAction)).
 			GetPost("/deleteTable", new(DeleteTableAction)).
+			GetPost("/growth", new(GrowthAction)).
+			Post("/growth/snapshot", new(GrowthSnapshotAction)).
+			GetPost("/migrate", new(MigrateAction)).
+			Post("/migrate/test", new(MigrateTestAction)).
+			Post("/migrate/status", new(MigrateStatusAction)).
+			Post("/migrate/verify", new(MigrateVerifyAction)).
+			Post("/migrate/recopy", new(MigrateRecopyAction)).
+			Post("/migrate/switch", new(MigrateSwitchAction)).
+			Post("/migrate/rollback", new(MigrateRollbackAction)).
+			Post("/migrate/clear", new(MigrateClearAction)).
 			EndAll()
 	})
 }
@@ -0,0 +1,118 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"net"
+	"regexp"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// MigrateAction 迁移到外部数据库
+type MigrateAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateAction) Init() {
+	this.Nav("", "", "migrate")
+}
+
+func (this *MigrateAction) RunGet(params struct{}) {
+	this.Data["error"] = ""
+
+	source, err := readCurrentDBConfig()
+	if err != nil {
+		this.Data["error"] = err.Error()
+		this.Data["source"] = nil
+	} else {
+		this.Data["source"] = source.Masked()
+	}
+
+	migration, err := sharedMigrator.Current()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if migration != nil {
+		this.Data["migration"] = migrationMap(migration)
+	} else {
+		this.Data["migration"] = nil
+	}
+
+	this.Show()
+}
+
+func (this *MigrateAction) RunPost(params struct {
+	Host           string
+	Port           int
+	Database       string
+	Username       string
+	Password       string
+	SkipAccessLogs bool
+	DropExisting   bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	defer this.CreateLogInfo("开始迁移数据库到 %s:%d/%s", params.Host, params.Port, params.Database)
+
+	var target = validateTargetParams(params.Must, params.Host, params.Port, params.Database, params.Username, params.Password)
+
+	source, err := readCurrentDBConfig()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	err = sharedMigrator.Start(source, target, &dbmigrateutils.Options{
+		SkipAccessLogs: params.SkipAccessLogs,
+		DropExisting:   params.DropExisting,
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
+
+// 校验目标数据库参数
+func validateTargetParams(must *actions.Must, host string, port int, database string, username string, password string) *dbmigrateutils.DBConfig {
+	must.
+		Field("host", host).
+		Require("请输入主机地址").
+		Expect(func() (message string, success bool) {
+			// 是否为IP
+			if net.ParseIP(host) != nil {
+				success = true
+				return
+			}
+			if !regexp.MustCompile(`^[\w.-]+$`).MatchString(host) {
+				message = "主机地址中不能包含特殊字符"
+				success = false
+				return
+			}
+			success = true
+			return
+		}).
+		Field("port", port).
+		Gt(0, "端口需要大于0").
+		Lt(65535, "端口需要小于65535").
+		Field("database", database).
+		Require("请输入数据库名称").
+		Match(`^[\w\.-]+$`, "数据库名称中不能包含特殊字符").
+		Field("username", username).
+		Require("请输入连接数据库的用户名").
+		Match(`^[\w\.-]+$`, "用户名中不能包含特殊字符")
+
+	return &dbmigrateutils.DBConfig{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		Database: database,
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateClearAction 清除迁移记录
+type MigrateClearAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateClearAction) RunPost(params struct{}) {
+	defer this.CreateLogInfo("清除数据库迁移记录")
+
+	err := sharedMigrator.Clear()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
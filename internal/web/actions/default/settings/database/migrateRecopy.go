@@ -0,0 +1,24 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateRecopyAction 重新复制失败或者校验不一致的数据表
+type MigrateRecopyAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateRecopyAction) RunPost(params struct{}) {
+	defer this.CreateLogInfo("重新复制迁移失败的数据表")
+
+	err := sharedMigrator.Recopy()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
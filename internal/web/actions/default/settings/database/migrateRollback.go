@@ -0,0 +1,39 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateRollbackAction 回滚到迁移前的数据库
+type MigrateRollbackAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateRollbackAction) RunPost(params struct{}) {
+	defer this.CreateLogInfo("将API节点回滚到迁移前的数据库")
+
+	err := sharedMigrator.Update(func(migration *dbmigrateutils.Migration) error {
+		err := migration.RollBack(time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		return restoreDBConfigFiles(migration.Backups)
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	hasLocalAPINode, err := restartLocalAPINode()
+	this.Data["hasLocalAPINode"] = hasLocalAPINode
+	if err != nil {
+		this.Fail("配置已回滚，但是" + err.Error() + "，请手动重启API节点")
+		return
+	}
+	this.Success()
+}
@@ -0,0 +1,27 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateStatusAction 刷新迁移进度
+type MigrateStatusAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateStatusAction) RunPost(params struct{}) {
+	migration, err := sharedMigrator.Current()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if migration == nil {
+		this.Data["migration"] = nil
+	} else {
+		this.Data["migration"] = migrationMap(migration)
+	}
+
+	this.Success()
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateSwitchAction 将API节点切换到新的数据库
+// 原来的配置文件会保存在迁移记录中，用于回滚
+type MigrateSwitchAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateSwitchAction) RunPost(params struct {
+	Force bool
+}) {
+	defer this.CreateLogInfo("将API节点切换到迁移后的数据库")
+
+	err := sharedMigrator.Update(func(migration *dbmigrateutils.Migration) error {
+		err := migration.CheckSwitch(params.Force)
+		if err != nil {
+			return err
+		}
+		backups, err := writeDBConfigFiles(migration.Target)
+		if err != nil {
+			return err
+		}
+		migration.Switch(backups, time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	hasLocalAPINode, err := restartLocalAPINode()
+	this.Data["hasLocalAPINode"] = hasLocalAPINode
+	if err != nil {
+		this.Fail("配置已切换，但是" + err.Error() + "，请手动重启API节点")
+		return
+	}
+	this.Success()
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// MigrateTestAction 测试目标数据库
+type MigrateTestAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateTestAction) RunPost(params struct {
+	Host     string
+	Port     int
+	Database string
+	Username string
+	Password string
+
+	Must *actions.Must
+}) {
+	var target = validateTargetParams(params.Must, params.Host, params.Port, params.Database, params.Username, params.Password)
+
+	source, err := readCurrentDBConfig()
+	if err == nil && source.Equals(target) {
+		this.Fail("目标数据库不能和当前数据库相同")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	info, err := dbmigrateutils.CheckTarget(ctx, target)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Data["target"] = maps.Map{
+		"version":             info.Version,
+		"countExistingTables": len(info.ExistingTables),
+	}
+	this.Success()
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// MigrateVerifyAction 校验迁移后的数据
+type MigrateVerifyAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MigrateVerifyAction) RunPost(params struct{}) {
+	defer this.CreateLogInfo("校验迁移后的数据库数据")
+
+	err := sharedMigrator.Verify()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,244 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbmigrateutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
+	"github.com/go-sql-driver/mysql"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/dbs"
+	"github.com/iwind/TeaGo/maps"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+	"gopkg.in/yaml.v3"
+)
+
+var sharedMigrator = dbmigrateutils.NewMigrator(Tea.ConfigFile("db_migration.json"))
+
+// 读取API节点当前使用的数据库
+func readCurrentDBConfig() (*dbmigrateutils.DBConfig, error) {
+	data, err := os.ReadFile(Tea.ConfigFile("api_db.yaml"))
+	if err != nil {
+		return nil, errors.New("read config file failed: api_db.yaml: " + err.Error())
+	}
+
+	var config = &configs.SimpleDBConfig{}
+	err = yaml.Unmarshal(data, config)
+	if err == nil && len(config.Host) > 0 {
+		return dbmigrateutils.ParseSimpleDBConfig(config), nil
+	}
+
+	// 旧的配置格式
+	var oldConfig = &dbs.Config{}
+	err = yaml.Unmarshal(data, oldConfig)
+	if err != nil {
+		return nil, errors.New("parse config file failed: api_db.yaml: " + err.Error())
+	}
+	for _, dbConfig := range oldConfig.DBs {
+		cfg, err := mysql.ParseDSN(dbConfig.Dsn)
+		if err != nil {
+			return nil, errors.New("parse dsn error: " + err.Error())
+		}
+		return dbmigrateutils.ParseSimpleDBConfig(&configs.SimpleDBConfig{
+			User:     cfg.User,
+			Password: cfg.Passwd,
+			Database: cfg.DBName,
+			Host:     cfg.Addr,
+		}), nil
+	}
+	return nil, errors.New("no database configured in config file: api_db.yaml")
+}
+
+// 需要在切换数据库时修改的配置文件
+func findDBConfigFiles() (newFormatFiles []string, oldFormatFiles []string) {
+	newFormatFiles = []string{Tea.ConfigFile("api_db.yaml")}
+
+	// 本地API节点
+	var apiConfigDir = Tea.Root + "/edge-api/configs"
+	stat, err := os.Stat(apiConfigDir)
+	if err == nil && stat.IsDir() {
+		newFormatFiles = append(newFormatFiles, apiConfigDir+"/db.yaml")
+		_, err = os.Stat(apiConfigDir + "/.db.yaml")
+		if err == nil {
+			oldFormatFiles = append(oldFormatFiles, apiConfigDir+"/.db.yaml")
+		}
+	}
+
+	// 备份的配置文件，只修改已经存在的
+	var backupFiles = []string{"/etc/edge-api/db.yaml", "/etc/edge-admin/api_db.yaml"}
+	homeDir, _ := os.UserHomeDir()
+	if len(homeDir) > 0 {
+		backupFiles = append(backupFiles, homeDir+"/.edge-api/db.yaml", homeDir+"/.edge-admin/api_db.yaml")
+	}
+	for _, file := range backupFiles {
+		_, err = os.Stat(file)
+		if err == nil {
+			newFormatFiles = append(newFormatFiles, file)
+		}
+	}
+	return
+}
+
+// 将API节点的数据库配置切换到新的数据库，返回原来的配置文件用于回滚
+func writeDBConfigFiles(dbConfig *dbmigrateutils.DBConfig) ([]*dbmigrateutils.FileBackup, error) {
+	var simpleConfig = dbConfig.SimpleDBConfig()
+	configYAML, err := yaml.Marshal(simpleConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var newFormatFiles, oldFormatFiles = findDBConfigFiles()
+	var backups = []*dbmigrateutils.FileBackup{}
+	for _, file := range append(append([]string{}, newFormatFiles...), oldFormatFiles...) {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		backups = append(backups, &dbmigrateutils.FileBackup{
+			Path:   file,
+			Data:   data,
+			Exists: err == nil,
+		})
+	}
+
+	for _, file := range newFormatFiles {
+		err = os.WriteFile(file, configYAML, 0666)
+		if err != nil {
+			_ = restoreDBConfigFiles(backups)
+			return nil, errors.New("write '" + filepath.Base(file) + "' failed: " + err.Error())
+		}
+	}
+	for _, file := range oldFormatFiles {
+		err = simpleConfig.GenerateOldConfig(file)
+		if err != nil {
+			_ = restoreDBConfigFiles(backups)
+			return nil, err
+		}
+	}
+	return backups, nil
+}
+
+// 恢复切换前的配置文件
+func restoreDBConfigFiles(backups []*dbmigrateutils.FileBackup) error {
+	var lastErr error
+	for _, backup := range backups {
+		var err error
+		if backup.Exists {
+			err = os.WriteFile(backup.Path, backup.Data, 0666)
+		} else {
+			err = os.Remove(backup.Path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		}
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// 重启本地API节点使新的数据库配置生效
+// 如果没有本地API节点，则返回false
+func restartLocalAPINode() (bool, error) {
+	var exePath = Tea.Root + "/edge-api/bin/edge-api"
+	_, err := os.Stat(exePath)
+	if err != nil {
+		return false, nil
+	}
+
+	var stdoutBuffer = &bytes.Buffer{}
+	var cmd = exec.Command(exePath, "restart")
+	cmd.Stdout = stdoutBuffer
+	cmd.Stderr = stdoutBuffer
+	err = cmd.Run()
+	if err != nil {
+		return true, errors.New("restart local api node failed: " + stdoutBuffer.String())
+	}
+
+	// 等待命令运行完毕
+	time.Sleep(1 * time.Second)
+	return true, nil
+}
+
+// 迁移状态名称
+func migrationStatusName(status string) string {
+	switch status {
+	case dbmigrateutils.StatusCopying:
+		return "正在复制"
+	case dbmigrateutils.StatusCopied:
+		return "复制完成，等待校验"
+	case dbmigrateutils.StatusVerifying:
+		return "正在校验"
+	case dbmigrateutils.StatusVerified:
+		return "校验完成，等待切换"
+	case dbmigrateutils.StatusFailed:
+		return "失败"
+	case dbmigrateutils.StatusSwitched:
+		return "已切换到新数据库"
+	case dbmigrateutils.StatusRolledBack:
+		return "已回滚到原数据库"
+	}
+	return status
+}
+
+// 迁移信息
+func migrationMap(migration *dbmigrateutils.Migration) maps.Map {
+	var tableMaps = []maps.Map{}
+	for _, table := range migration.Tables {
+		tableMaps = append(tableMaps, maps.Map{
+			"name":           table.Name,
+			"status":         table.Status,
+			"estimatedRows":  numberutils.FormatInt64(table.EstimatedRows),
+			"copiedRows":     numberutils.FormatInt64(table.CopiedRows),
+			"error":          table.Error,
+			"verifyStatus":   table.VerifyStatus,
+			"sourceRows":     numberutils.FormatInt64(table.SourceRows),
+			"targetRows":     numberutils.FormatInt64(table.TargetRows),
+			"sourceChecksum": table.SourceChecksum,
+			"targetChecksum": table.TargetChecksum,
+			"verifyError":    table.VerifyError,
+		})
+	}
+
+	var switchedTime = ""
+	if migration.SwitchedAt > 0 {
+		switchedTime = timeutil.FormatTime("Y-m-d H:i:s", migration.SwitchedAt)
+	}
+	var rolledBackTime = ""
+	if migration.RolledBackAt > 0 {
+		rolledBackTime = timeutil.FormatTime("Y-m-d H:i:s", migration.RolledBackAt)
+	}
+
+	return maps.Map{
+		"id":                    migration.Id,
+		"status":                migration.Status,
+		"statusName":            migrationStatusName(migration.Status),
+		"isRunning":             migration.IsRunning(),
+		"error":                 migration.Error,
+		"source":                migration.Source.Masked(),
+		"target":                migration.Target.Masked(),
+		"options":               migration.Options,
+		"progress":              int(migration.Progress()),
+		"tables":                tableMaps,
+		"countTables":           len(migration.Tables),
+		"countCopied":           migration.CountTables(dbmigrateutils.TableStatusCopied),
+		"countFailed":           migration.CountTables(dbmigrateutils.TableStatusFailed),
+		"countSkipped":          migration.CountTables(dbmigrateutils.TableStatusSkipped),
+		"countVerifyOk":         migration.CountVerified(dbmigrateutils.VerifyOk),
+		"countCountMismatch":    migration.CountVerified(dbmigrateutils.VerifyCountMismatch),
+		"countChecksumMismatch": migration.CountVerified(dbmigrateutils.VerifyChecksumMismatch),
+		"countVerifyFailed":     migration.CountVerified(dbmigrateutils.VerifyFailed),
+		"createdTime":           timeutil.FormatTime("Y-m-d H:i:s", migration.CreatedAt),
+		"switchedTime":          switchedTime,
+		"rolledBackTime":        rolledBackTime,
+	}
+}
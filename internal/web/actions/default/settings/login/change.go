@@ -0,0 +1,105 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+)
+
+// 登录认证设置代号
+const (
+	settingCodeSSO       = "sso"
+	settingCodeLDAP      = "ldap"
+	settingCodeLDAPLinks = "ldapLinks"
+)
+
+// 单点登录和LDAP认证设置的读取和保存方法，用于审批通过后执行变更
+var settingDefinitions = map[string]*changeutils.SettingDefinition{
+	settingCodeSSO: {
+		Title: "修改单点登录设置",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminSSOConfig()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var config = ssoutils.NewConfig()
+			err := change.Decode(config)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateAdminSSOConfig(config)
+		},
+		Mask: func(oldValue any, newValue any) (any, any) {
+			var oldConfig = *oldValue.(*ssoutils.Config)
+			var newConfig = *newValue.(*ssoutils.Config)
+			if oldConfig.OIDC != nil && newConfig.OIDC != nil {
+				var oldOIDC = *oldConfig.OIDC
+				var newOIDC = *newConfig.OIDC
+				oldOIDC.ClientSecret, newOIDC.ClientSecret = maskSecret(oldOIDC.ClientSecret, newOIDC.ClientSecret)
+				oldConfig.OIDC = &oldOIDC
+				newConfig.OIDC = &newOIDC
+			}
+			return &oldConfig, &newConfig
+		},
+	},
+	settingCodeLDAP: {
+		Title: "修改LDAP认证设置",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminLDAPConfig()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var config = ldaputils.NewConfig()
+			err := change.Decode(config)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateAdminLDAPConfig(config)
+		},
+		Mask: func(oldValue any, newValue any) (any, any) {
+			var oldConfig = *oldValue.(*ldaputils.Config)
+			var newConfig = *newValue.(*ldaputils.Config)
+			oldConfig.BindPassword, newConfig.BindPassword = maskSecret(oldConfig.BindPassword, newConfig.BindPassword)
+			return &oldConfig, &newConfig
+		},
+	},
+	settingCodeLDAPLinks: {
+		Title: "关联LDAP用户和管理员",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminLDAPLinks()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var newStore = ldaputils.NewLinkStore()
+			err := change.Decode(newStore)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateAdminLDAPLinks(func(store *ldaputils.LinkStore) error {
+				store.Links = newStore.Links
+				return nil
+			})
+		},
+	},
+}
+
+func init() {
+	for code, definition := range settingDefinitions {
+		changeutils.RegisterSetting(code, definition)
+	}
+}
+
+// 在修改前后的对比中隐藏密钥，只显示是否已修改
+func maskSecret(oldSecret string, newSecret string) (oldMasked string, newMasked string) {
+	if len(oldSecret) > 0 {
+		oldMasked = "******"
+	}
+	if len(newSecret) > 0 {
+		newMasked = "******"
+	}
+	if oldMasked == newMasked && oldSecret != newSecret {
+		newMasked += "（已修改）"
+	}
+	return
+}
@@ -2,6 +2,8 @@ package login
 
 import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -14,7 +16,7 @@ type IndexAction struct {
 }
 
 func (this *IndexAction) Init() {
-	this.Nav("", "", "")
+	this.Nav("", "", "index")
 }
 
 func (this *IndexAction) RunGet(params struct{}) {
@@ -32,6 +34,8 @@ func (this *IndexAction) RunGet(params struct{}) {
 		"username": admin.Username,
 		"fullname": admin.Fullname,
 	}
+	this.Data["canManageSSO"] = configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeSetting)
+	this.Data["passwordExpired"] = this.Session().GetString(teaconst.SessionPasswordExpired) == "1"
 
 	this.Show()
 }
@@ -62,10 +66,23 @@ func (this *IndexAction) RunPost(params struct {
 		this.FailField("username", "此用户名已经被别的管理员使用，请换一个")
 	}
 
+	// 密码已过期时必须修改密码
+	var passwordExpired = this.Session().GetString(teaconst.SessionPasswordExpired) == "1"
+	if passwordExpired && len(params.Password) == 0 {
+		this.FailField("password", "密码已过期，请输入新的登录密码")
+	}
+
 	if len(params.Password) > 0 {
 		if params.Password != params.Password2 {
 			this.FailField("password2", "两次输入的密码不一致")
 		}
+
+		// 密码策略
+		err = configloaders.CheckPassword(passwordpolicyutils.KindAdmin, this.AdminId(), params.Username, params.Password)
+		if err != nil {
+			this.FailField("password", err.Error())
+			return
+		}
 	}
 
 	_, err = this.RPC().AdminRPC().UpdateAdminLogin(this.AdminContext(), &pb.UpdateAdminLoginRequest{
@@ -78,6 +95,17 @@ func (this *IndexAction) RunPost(params struct {
 		return
 	}
 
+	if len(params.Password) > 0 {
+		err = configloaders.RecordPasswordChange(passwordpolicyutils.KindAdmin, this.AdminId(), params.Password)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if passwordExpired {
+			this.Session().Write(teaconst.SessionPasswordExpired, "")
+		}
+	}
+
 	// 通知更新
 	err = configloaders.NotifyAdminModuleMappingChange()
 	if err != nil {
@@ -14,6 +14,22 @@ func init() {
 			Helper(settingutils.NewHelper("login")).
 			Prefix("/settings/login").
 			GetPost("", new(IndexAction)).
+			GetPost("/webauthn", new(WebauthnAction)).
+			Post("/webauthn/rename", new(WebauthnRenameAction)).
+			Post("/webauthn/delete", new(WebauthnDeleteAction)).
+			Post("/webauthn/recoveryCodes", new(WebauthnRecoveryCodesAction)).
+			EndAll()
+
+		// 单点登录和LDAP认证
+		server.
+			Helper(helpers.NewUserMustAuth(configloaders.AdminModuleCodeSetting)).
+			Helper(settingutils.NewHelper("login")).
+			Prefix("/settings/login").
+			GetPost("/sso", new(SsoAction)).
+			GetPost("/ldap", new(LdapAction)).
+			GetPost("/ldapTestPopup", new(LdapTestPopupAction)).
+			GetPost("/ldapLinkPopup", new(LdapLinkPopupAction)).
+			Post("/ldapLinkDelete", new(LdapLinkDeleteAction)).
 			EndAll()
 	})
 }
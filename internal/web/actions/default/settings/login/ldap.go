@@ -0,0 +1,178 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// LdapAction LDAP认证设置
+type LdapAction struct {
+	actionutils.ParentAction
+}
+
+func (this *LdapAction) Init() {
+	this.Nav("", "", "ldap")
+}
+
+func (this *LdapAction) RunGet(params struct{}) {
+	this.Data["canManageSSO"] = true
+
+	config, err := configloaders.LoadAdminLDAPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 不在页面中显示密码
+	var configCopy = *config
+	configCopy.BindPassword = ""
+	this.Data["config"] = configCopy
+	this.Data["hasBindPassword"] = len(config.BindPassword) > 0
+	this.Data["modules"] = configloaders.AllModuleMaps(this.LangCode())
+
+	// 已关联的管理员
+	links, err := configloaders.LoadAdminLDAPLinks()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var linkMaps = []maps.Map{}
+	for dn, adminId := range links.Links {
+		adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: adminId})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		var admin = adminResp.Admin
+		if admin == nil {
+			continue
+		}
+		linkMaps = append(linkMaps, maps.Map{
+			"dn": dn,
+			"admin": maps.Map{
+				"id":       admin.Id,
+				"username": admin.Username,
+				"fullname": admin.Fullname,
+				"isSuper":  admin.IsSuper,
+			},
+		})
+	}
+	sort.Slice(linkMaps, func(i, j int) bool {
+		return linkMaps[i].GetString("dn") < linkMaps[j].GetString("dn")
+	})
+	this.Data["links"] = linkMaps
+
+	this.Show()
+}
+
+func (this *LdapAction) RunPost(params struct {
+	IsOn               bool
+	Url                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	CaCertificate      string
+	Timeout            int
+
+	BindDN       string
+	BindPassword string
+
+	BaseDN            string
+	UserFilter        string
+	UsernameAttribute string
+	FullnameAttribute string
+
+	GroupAttribute string
+	GroupBaseDN    string
+	GroupFilter    string
+
+	GroupMappingsJSON []byte
+	CacheTTL          int
+	AutoCreate        bool
+	LinkExisting      bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	oldConfig, err := configloaders.LoadAdminLDAPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var config = ldaputils.NewConfig()
+	config.IsOn = params.IsOn
+	config.URL = strings.TrimSpace(params.Url)
+	config.StartTLS = params.StartTLS
+	config.InsecureSkipVerify = params.InsecureSkipVerify
+	config.CACertificate = strings.TrimSpace(params.CaCertificate)
+	config.Timeout = params.Timeout
+	config.BindDN = strings.TrimSpace(params.BindDN)
+	config.BindPassword = params.BindPassword
+	if len(config.BindPassword) == 0 && len(config.BindDN) > 0 {
+		config.BindPassword = oldConfig.BindPassword
+	}
+	config.BaseDN = strings.TrimSpace(params.BaseDN)
+	config.UserFilter = strings.TrimSpace(params.UserFilter)
+	config.UsernameAttribute = strings.TrimSpace(params.UsernameAttribute)
+	config.FullnameAttribute = strings.TrimSpace(params.FullnameAttribute)
+	config.GroupAttribute = strings.TrimSpace(params.GroupAttribute)
+	config.GroupBaseDN = strings.TrimSpace(params.GroupBaseDN)
+	config.GroupFilter = strings.TrimSpace(params.GroupFilter)
+	config.CacheTTL = params.CacheTTL
+	config.AutoCreate = params.AutoCreate
+	config.LinkExisting = params.LinkExisting
+
+	if len(params.GroupMappingsJSON) > 0 {
+		err = json.Unmarshal(params.GroupMappingsJSON, &config.GroupMappings)
+		if err != nil {
+			this.Fail("分组权限数据格式错误：" + err.Error())
+			return
+		}
+	}
+
+	if config.IsOn {
+		params.Must.
+			Field("url", config.URL).
+			Require("请输入LDAP服务地址").
+			Match(`^ldaps?://`, "LDAP服务地址需要以ldap://或ldaps://开头").
+			Field("baseDN", config.BaseDN).
+			Require("请输入用户查询的基础DN").
+			Field("userFilter", config.UserFilter).
+			Require("请输入用户过滤器")
+		if !strings.Contains(config.UserFilter, "{username}") {
+			this.FailField("userFilter", "用户过滤器中需要包含{username}")
+		}
+	}
+
+	err = config.Validate()
+	if err != nil {
+		this.Fail("保存失败：" + err.Error())
+		return
+	}
+
+	// 是否需要审批
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeLDAP, oldConfig, config) {
+		return
+	}
+
+	defer this.CreateLogInfo("修改管理员LDAP认证设置")
+
+	err = configloaders.UpdateAdminLDAPConfig(config)
+	if err != nil {
+		this.Fail("保存失败：" + err.Error())
+		return
+	}
+
+	this.Success()
+}
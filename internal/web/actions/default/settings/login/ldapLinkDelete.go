@@ -0,0 +1,31 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// LdapLinkDeleteAction 取消LDAP用户和管理员的关联
+type LdapLinkDeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *LdapLinkDeleteAction) RunPost(params struct {
+	Dn string
+}) {
+	defer this.CreateLogInfo("取消LDAP用户 %s 和管理员的关联", params.Dn)
+
+	err := configloaders.UpdateAdminLDAPLinks(func(store *ldaputils.LinkStore) error {
+		store.Unlink(params.Dn)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
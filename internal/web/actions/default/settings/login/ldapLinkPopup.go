@@ -0,0 +1,113 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// LdapLinkPopupAction 手动关联LDAP用户和管理员
+type LdapLinkPopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *LdapLinkPopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *LdapLinkPopupAction) RunGet(params struct{}) {
+	adminsResp, err := this.RPC().AdminRPC().ListEnabledAdmins(this.AdminContext(), &pb.ListEnabledAdminsRequest{
+		Offset: 0,
+		Size:   1000,
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var adminMaps = []maps.Map{}
+	for _, admin := range adminsResp.Admins {
+		adminMaps = append(adminMaps, maps.Map{
+			"id":       admin.Id,
+			"username": admin.Username,
+			"fullname": admin.Fullname,
+			"isSuper":  admin.IsSuper,
+		})
+	}
+	this.Data["admins"] = adminMaps
+
+	this.Show()
+}
+
+func (this *LdapLinkPopupAction) RunPost(params struct {
+	Dn      string
+	AdminId int64
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	params.Dn = strings.TrimSpace(params.Dn)
+	params.Must.
+		Field("dn", params.Dn).
+		Require("请输入LDAP用户的DN")
+	if params.AdminId <= 0 {
+		this.Fail("请选择要关联的管理员")
+		return
+	}
+
+	adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: params.AdminId})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var admin = adminResp.Admin
+	if admin == nil {
+		this.Fail("找不到要关联的管理员")
+		return
+	}
+
+	// 只有超级管理员才能关联超级管理员
+	if admin.IsSuper {
+		currentAdminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: this.AdminId()})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		if currentAdminResp.Admin == nil || !currentAdminResp.Admin.IsSuper {
+			this.Fail("只有超级管理员才能关联超级管理员")
+			return
+		}
+	}
+
+	// 是否需要审批
+	oldStore, err := configloaders.LoadAdminLDAPLinks()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var newStore = oldStore.Clone()
+	newStore.Link(params.Dn, admin.Id)
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeLDAPLinks, oldStore, newStore) {
+		return
+	}
+
+	defer this.CreateLogInfo("关联LDAP用户 %s 和管理员 %d", params.Dn, params.AdminId)
+
+	err = configloaders.UpdateAdminLDAPLinks(func(store *ldaputils.LinkStore) error {
+		store.Link(params.Dn, admin.Id)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
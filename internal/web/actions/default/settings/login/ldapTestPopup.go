@@ -0,0 +1,75 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ldaputils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// LdapTestPopupAction 使用已保存的LDAP配置测试认证
+type LdapTestPopupAction struct {
+	actionutils.ParentAction
+}
+
+func (this *LdapTestPopupAction) Init() {
+	this.Nav("", "", "")
+}
+
+func (this *LdapTestPopupAction) RunGet(params struct{}) {
+	this.Show()
+}
+
+func (this *LdapTestPopupAction) RunPost(params struct {
+	Username string
+	Password string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	params.Must.
+		Field("username", params.Username).
+		Require("请输入用户名").
+		Field("password", params.Password).
+		Require("请输入密码")
+
+	config, err := configloaders.LoadAdminLDAPConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 测试时不使用缓存
+	identity, err := ldaputils.Authenticate(config, nil, params.Username, params.Password)
+	if err != nil {
+		if err == ldaputils.ErrInvalidCredentials {
+			this.Fail("用户名或密码错误")
+			return
+		}
+		this.Fail("连接LDAP服务失败：" + err.Error())
+		return
+	}
+
+	moduleCodes, matched := config.MapModules(identity.Groups)
+	var moduleNames = []string{}
+	for _, m := range configloaders.AllModuleMaps(this.LangCode()) {
+		for _, code := range moduleCodes {
+			if m.GetString("code") == code {
+				moduleNames = append(moduleNames, m.GetString("name"))
+			}
+		}
+	}
+
+	this.Data["result"] = maps.Map{
+		"dn":          identity.DN,
+		"username":    identity.Username,
+		"fullname":    identity.Fullname,
+		"groups":      identity.Groups,
+		"matched":     matched,
+		"moduleNames": moduleNames,
+	}
+	this.Success()
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/ssoutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// SsoAction 单点登录设置
+type SsoAction struct {
+	actionutils.ParentAction
+}
+
+func (this *SsoAction) Init() {
+	this.Nav("", "", "sso")
+}
+
+func (this *SsoAction) RunGet(params struct{}) {
+	this.Data["canManageSSO"] = true
+
+	config, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	// 不在页面中显示密钥
+	var configCopy = *config
+	var oidcCopy = *config.OIDC
+	oidcCopy.ClientSecret = ""
+	configCopy.OIDC = &oidcCopy
+	this.Data["config"] = configCopy
+	this.Data["hasClientSecret"] = len(config.OIDC.ClientSecret) > 0
+
+	var baseURL = config.ComposeBaseURL(this.Request)
+	this.Data["callbackURL"] = baseURL + ssoutils.CallbackPath
+	this.Data["metadataURL"] = baseURL + ssoutils.MetadataPath
+	this.Data["modules"] = configloaders.AllModuleMaps(this.LangCode())
+
+	this.Show()
+}
+
+func (this *SsoAction) RunPost(params struct {
+	IsOn     bool
+	Protocol string
+	Name     string
+	BaseURL  string
+
+	OidcIssuer        string
+	OidcClientId      string
+	OidcClientSecret  string
+	OidcScopes        string
+	OidcUsernameClaim string
+	OidcGroupsClaim   string
+
+	SamlIdpEntityId       string
+	SamlIdpSSOURL         string
+	SamlIdpCertificate    string
+	SamlSPEntityId        string
+	SamlUsernameAttribute string
+	SamlFullnameAttribute string
+	SamlGroupsAttribute   string
+
+	GroupMappingsJSON    []byte
+	AutoCreate           bool
+	LinkExisting         bool
+	DisableLocalPassword bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	oldConfig, err := configloaders.LoadAdminSSOConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var config = ssoutils.NewConfig()
+	config.IsOn = params.IsOn
+	config.Protocol = params.Protocol
+	config.Name = strings.TrimSpace(params.Name)
+	config.BaseURL = strings.TrimSuffix(strings.TrimSpace(params.BaseURL), "/")
+
+	config.OIDC = &ssoutils.OIDCConfig{
+		Issuer:        strings.TrimSpace(params.OidcIssuer),
+		ClientId:      strings.TrimSpace(params.OidcClientId),
+		ClientSecret:  params.OidcClientSecret,
+		Scopes:        strings.Fields(params.OidcScopes),
+		UsernameClaim: strings.TrimSpace(params.OidcUsernameClaim),
+		GroupsClaim:   strings.TrimSpace(params.OidcGroupsClaim),
+	}
+	if len(config.OIDC.ClientSecret) == 0 {
+		config.OIDC.ClientSecret = oldConfig.OIDC.ClientSecret
+	}
+
+	config.SAML = &ssoutils.SAMLConfig{
+		IdPEntityId:       strings.TrimSpace(params.SamlIdpEntityId),
+		IdPSSOURL:         strings.TrimSpace(params.SamlIdpSSOURL),
+		IdPCertificate:    strings.TrimSpace(params.SamlIdpCertificate),
+		SPEntityId:        strings.TrimSpace(params.SamlSPEntityId),
+		UsernameAttribute: strings.TrimSpace(params.SamlUsernameAttribute),
+		FullnameAttribute: strings.TrimSpace(params.SamlFullnameAttribute),
+		GroupsAttribute:   strings.TrimSpace(params.SamlGroupsAttribute),
+	}
+
+	if len(params.GroupMappingsJSON) > 0 {
+		err = json.Unmarshal(params.GroupMappingsJSON, &config.GroupMappings)
+		if err != nil {
+			this.Fail("分组权限数据格式错误：" + err.Error())
+			return
+		}
+	}
+	config.AutoCreate = params.AutoCreate
+	config.LinkExisting = params.LinkExisting
+	config.DisableLocalPassword = params.DisableLocalPassword
+
+	if config.IsOn {
+		switch config.Protocol {
+		case ssoutils.ProtocolOIDC:
+			params.Must.
+				Field("oidcIssuer", config.OIDC.Issuer).
+				Require("请输入Issuer地址").
+				Match(`^https?://`, "Issuer地址需要以http://或https://开头").
+				Field("oidcClientId", config.OIDC.ClientId).
+				Require("请输入Client ID")
+		case ssoutils.ProtocolSAML:
+			params.Must.
+				Field("samlIdpSSOURL", config.SAML.IdPSSOURL).
+				Require("请输入身份提供商登录地址").
+				Match(`^https?://`, "登录地址需要以http://或https://开头").
+				Field("samlIdpCertificate", config.SAML.IdPCertificate).
+				Require("请输入身份提供商签名证书")
+		}
+	}
+
+	err = config.Validate()
+	if err != nil {
+		this.Fail("保存失败：" + err.Error())
+		return
+	}
+
+	// 是否需要审批
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeSSO, oldConfig, config) {
+		return
+	}
+
+	defer this.CreateLogInfo("修改管理员单点登录设置")
+
+	err = configloaders.UpdateAdminSSOConfig(config)
+	if err != nil {
+		this.Fail("保存失败：" + err.Error())
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/maps"
+	"github.com/iwind/TeaGo/types"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// 注册安全密钥时使用的挑战值在SESSION中的键
+const webAuthnChallengeSessionKey = "@webAuthnChallenge"
+
+// WebauthnAction 安全密钥管理
+type WebauthnAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WebauthnAction) Init() {
+	this.Nav("", "", "webauthn")
+}
+
+func (this *WebauthnAction) RunGet(params struct{}) {
+	this.Data["canManageSSO"] = configloaders.AllowModule(this.AdminId(), configloaders.AdminModuleCodeSetting)
+
+	adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: this.AdminId()})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var admin = adminResp.Admin
+	if admin == nil {
+		this.NotFound("admin", this.AdminId())
+		return
+	}
+
+	store, err := configloaders.LoadAdminWebAuthnStore()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	var credentialMaps = []maps.Map{}
+	var credentialIds = []string{}
+	for _, credential := range store.FindAdminCredentials(this.AdminId()) {
+		var lastUsedTime = ""
+		if credential.LastUsedAt > 0 {
+			lastUsedTime = timeutil.FormatTime("Y-m-d H:i:s", credential.LastUsedAt)
+		}
+		credentialMaps = append(credentialMaps, maps.Map{
+			"id":           credential.Id,
+			"name":         credential.Name,
+			"createdTime":  timeutil.FormatTime("Y-m-d H:i:s", credential.CreatedAt),
+			"lastUsedTime": lastUsedTime,
+		})
+		credentialIds = append(credentialIds, credential.Id)
+	}
+	this.Data["credentials"] = credentialMaps
+	this.Data["countRecoveryCodes"] = store.CountRecoveryCodes(this.AdminId())
+	this.Data["requireForSuperAdmins"] = policy.RequireForSuperAdmins && admin.IsSuper
+
+	// 注册用的参数
+	var challenge = webauthnutils.NewChallenge()
+	this.Session().Write(webAuthnChallengeSessionKey, challenge)
+
+	var rp = webauthnutils.NewRelyingParty(this.Request, policy.RPId)
+	var fullname = admin.Fullname
+	if len(fullname) == 0 {
+		fullname = admin.Username
+	}
+	this.Data["options"] = maps.Map{
+		"challenge":     challenge,
+		"rpId":          rp.Id,
+		"userId":        base64.RawURLEncoding.EncodeToString([]byte(types.String(admin.Id))),
+		"username":      admin.Username,
+		"fullname":      fullname,
+		"algorithms":    webauthnutils.SupportedAlgorithms,
+		"credentialIds": credentialIds,
+	}
+
+	this.Show()
+}
+
+// RunPost 注册新的安全密钥
+func (this *WebauthnAction) RunPost(params struct {
+	Name              string
+	ClientDataJSON    string
+	AttestationObject string
+}) {
+	defer this.CreateLogInfo("注册安全密钥 %s", params.Name)
+
+	params.Name = strings.TrimSpace(params.Name)
+	if len(params.Name) == 0 {
+		this.FailField("name", "请输入安全密钥名称")
+		return
+	}
+	if len([]rune(params.Name)) > 50 {
+		this.FailField("name", "安全密钥名称不能超过50个字符")
+		return
+	}
+
+	// 挑战值只能使用一次
+	var challenge = this.Session().GetString(webAuthnChallengeSessionKey)
+	this.Session().Write(webAuthnChallengeSessionKey, "")
+	if len(challenge) == 0 {
+		this.Fail("页面已过期，请刷新后重试")
+		return
+	}
+
+	clientDataJSON, err1 := base64.RawURLEncoding.DecodeString(strings.TrimRight(params.ClientDataJSON, "="))
+	attestationObject, err2 := base64.RawURLEncoding.DecodeString(strings.TrimRight(params.AttestationObject, "="))
+	if err1 != nil || err2 != nil {
+		this.Fail("参数错误，请刷新后重试")
+		return
+	}
+
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var rp = webauthnutils.NewRelyingParty(this.Request, policy.RPId)
+	credential, err := webauthnutils.VerifyRegistration(rp, challenge, clientDataJSON, attestationObject, false)
+	if err != nil {
+		this.Fail("安全密钥注册失败：" + err.Error())
+		return
+	}
+	credential.Name = params.Name
+
+	// 注册第一个安全密钥时自动生成恢复码
+	var recoveryCodes = []string{}
+	err = configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+		err := store.AddCredential(this.AdminId(), credential)
+		if err != nil {
+			return err
+		}
+		if store.CountRecoveryCodes(this.AdminId()) == 0 {
+			recoveryCodes = store.ResetRecoveryCodes(this.AdminId())
+		}
+		return nil
+	})
+	if err != nil {
+		this.Fail("安全密钥注册失败：" + err.Error())
+		return
+	}
+	this.Data["recoveryCodes"] = recoveryCodes
+
+	this.Success()
+}
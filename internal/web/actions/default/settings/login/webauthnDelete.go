@@ -0,0 +1,58 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+)
+
+// WebauthnDeleteAction 撤销安全密钥
+type WebauthnDeleteAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WebauthnDeleteAction) RunPost(params struct {
+	CredentialId string
+}) {
+	defer this.CreateLogInfo("撤销安全密钥 %s", params.CredentialId)
+
+	// 超级管理员必须使用安全密钥时，不能删除最后一个
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var isSuper = false
+	if policy.RequireForSuperAdmins {
+		adminResp, err := this.RPC().AdminRPC().FindEnabledAdmin(this.AdminContext(), &pb.FindEnabledAdminRequest{AdminId: this.AdminId()})
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+		isSuper = adminResp.Admin != nil && adminResp.Admin.IsSuper
+	}
+
+	var found = false
+	err = configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+		if isSuper && len(store.FindAdminCredentials(this.AdminId())) == 1 {
+			return errors.New("超级管理员必须使用安全密钥登录，不能撤销最后一个安全密钥")
+		}
+		found = store.RemoveCredential(this.AdminId(), params.CredentialId)
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+	if !found {
+		this.Fail("找不到要撤销的安全密钥")
+		return
+	}
+
+	this.Success()
+}
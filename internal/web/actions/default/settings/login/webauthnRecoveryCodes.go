@@ -0,0 +1,36 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"errors"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// WebauthnRecoveryCodesAction 重新生成恢复码
+type WebauthnRecoveryCodesAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WebauthnRecoveryCodesAction) RunPost(params struct{}) {
+	defer this.CreateLogInfo("重新生成安全密钥恢复码")
+
+	var recoveryCodes []string
+	err := configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+		if !store.HasCredentials(this.AdminId()) {
+			return errors.New("请先注册安全密钥")
+		}
+		recoveryCodes = store.ResetRecoveryCodes(this.AdminId())
+		return nil
+	})
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+	this.Data["recoveryCodes"] = recoveryCodes
+
+	this.Success()
+}
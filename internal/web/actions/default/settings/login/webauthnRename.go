@@ -0,0 +1,49 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package login
+
+import (
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+)
+
+// WebauthnRenameAction 修改安全密钥名称
+type WebauthnRenameAction struct {
+	actionutils.ParentAction
+}
+
+func (this *WebauthnRenameAction) RunPost(params struct {
+	CredentialId string
+	Name         string
+}) {
+	defer this.CreateLogInfo("修改安全密钥名称为 %s", params.Name)
+
+	params.Name = strings.TrimSpace(params.Name)
+	if len(params.Name) == 0 {
+		this.Fail("请输入安全密钥名称")
+		return
+	}
+	if len([]rune(params.Name)) > 50 {
+		this.Fail("安全密钥名称不能超过50个字符")
+		return
+	}
+
+	var found = false
+	err := configloaders.UpdateAdminWebAuthnStore(func(store *webauthnutils.Store) error {
+		found = store.RenameCredential(this.AdminId(), params.CredentialId, params.Name)
+		return nil
+	})
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if !found {
+		this.Fail("找不到要修改的安全密钥")
+		return
+	}
+
+	this.Success()
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package security
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/approvalutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+)
+
+// 安全设置代号
+const (
+	settingCodeSecurity       = "security"
+	settingCodeMFA            = "mfa"
+	settingCodeLoginLimit     = "loginLimit"
+	settingCodePasswordPolicy = "passwordPolicy"
+)
+
+// 安全设置的读取和保存方法，用于审批通过后执行变更
+var settingDefinitions = map[string]*changeutils.SettingDefinition{
+	settingCodeSecurity: {
+		Title: "修改安全设置",
+		Load: func() (any, error) {
+			return configloaders.LoadSecurityConfig()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var config = configloaders.NewSecurityConfig()
+			err := change.Decode(config)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateSecurityConfig(config)
+		},
+	},
+	settingCodeMFA: {
+		Title: "修改多因素认证设置",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminWebAuthnPolicy()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var policy = webauthnutils.NewPolicy()
+			err := change.Decode(policy)
+			if err != nil {
+				return err
+			}
+
+			// 审批期间可能增加了新的超级管理员
+			if policy.RequireForSuperAdmins {
+				err = checkSuperAdminsWebAuthn()
+				if err != nil {
+					return err
+				}
+			}
+			return configloaders.UpdateAdminWebAuthnPolicy(policy)
+		},
+	},
+	settingCodeLoginLimit: {
+		Title: "修改登录保护设置",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminLoginLimitConfig()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var config = loginlimitutils.NewConfig()
+			err := change.Decode(config)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateAdminLoginLimitConfig(config)
+		},
+	},
+	settingCodePasswordPolicy: {
+		Title: "修改密码策略",
+		Load: func() (any, error) {
+			return configloaders.LoadAdminPasswordPolicy()
+		},
+		Update: func(change *approvalutils.SettingChange) error {
+			var policy = passwordpolicyutils.NewPolicy()
+			err := change.Decode(policy)
+			if err != nil {
+				return err
+			}
+			return configloaders.UpdateAdminPasswordPolicy(policy)
+		},
+	},
+}
+
+func init() {
+	for code, definition := range settingDefinitions {
+		changeutils.RegisterSetting(code, definition)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
 	"github.com/TeaOSLab/EdgeCommon/pkg/serverconfigs/shared"
@@ -17,7 +18,7 @@ type IndexAction struct {
 }
 
 func (this *IndexAction) Init() {
-	this.Nav("", "", "")
+	this.Nav("", "", "index")
 }
 
 func (this *IndexAction) RunGet(params struct {
@@ -97,13 +98,12 @@ func (this *IndexAction) RunPost(params struct {
 	Must *actions.Must
 	CSRF *actionutils.CSRF
 }) {
-	defer this.CreateLogInfo(codes.AdminSecurity_LogUpdateSecuritySettings)
-
 	config, err := configloaders.LoadSecurityConfig()
 	if err != nil {
 		this.ErrorPage(err)
 		return
 	}
+	var oldConfig = *config
 
 	// 框架
 	config.Frame = params.Frame
@@ -171,6 +171,13 @@ func (this *IndexAction) RunPost(params struct {
 	config.CheckClientFingerprint = params.CheckClientFingerprint
 	config.CheckClientRegion = params.CheckClientRegion
 
+	// 是否需要审批
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeSecurity, &oldConfig, config) {
+		return
+	}
+
+	defer this.CreateLogInfo(codes.AdminSecurity_LogUpdateSecuritySettings)
+
 	err = configloaders.UpdateSecurityConfig(config)
 	if err != nil {
 		this.ErrorPage(err)
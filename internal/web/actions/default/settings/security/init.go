@@ -15,6 +15,9 @@ func init() {
 			Prefix("/settings/security").
 			GetPost("", new(IndexAction)).
 			Post("/dismissXFFPrompt", new(DismissXFFPromptAction)).
+			GetPost("/mfa", new(MfaAction)).
+			GetPost("/loginLimit", new(LoginLimitAction)).
+			GetPost("/passwordPolicy", new(PasswordPolicyAction)).
 			EndAll()
 	})
 }
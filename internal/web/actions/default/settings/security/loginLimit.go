@@ -0,0 +1,82 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package security
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/loginlimitutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// LoginLimitAction 登录保护设置
+type LoginLimitAction struct {
+	actionutils.ParentAction
+}
+
+func (this *LoginLimitAction) Init() {
+	this.Nav("", "", "loginLimit")
+}
+
+func (this *LoginLimitAction) RunGet(params struct{}) {
+	config, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["config"] = config
+
+	this.Show()
+}
+
+func (this *LoginLimitAction) RunPost(params struct {
+	IsOn             bool
+	WindowSeconds    int
+	DelayAfter       int
+	BaseDelaySeconds int
+	MaxDelaySeconds  int
+	UserLockAfter    int
+	IpLockAfter      int
+	LockSeconds      int
+	CaptchaAfter     int
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var config = loginlimitutils.NewConfig()
+	config.IsOn = params.IsOn
+	config.WindowSeconds = params.WindowSeconds
+	config.DelayAfter = params.DelayAfter
+	config.BaseDelaySeconds = params.BaseDelaySeconds
+	config.MaxDelaySeconds = params.MaxDelaySeconds
+	config.UserLockAfter = params.UserLockAfter
+	config.IPLockAfter = params.IpLockAfter
+	config.LockSeconds = params.LockSeconds
+	config.CaptchaAfter = params.CaptchaAfter
+	err := config.Validate()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	// 是否需要审批
+	oldConfig, err := configloaders.LoadAdminLoginLimitConfig()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeLoginLimit, oldConfig, config) {
+		return
+	}
+
+	defer this.CreateLogInfo("修改登录保护设置")
+
+	err = configloaders.UpdateAdminLoginLimitConfig(config)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
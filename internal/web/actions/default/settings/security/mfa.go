@@ -0,0 +1,116 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package security
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/webauthnutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// MfaAction 多因素认证策略
+type MfaAction struct {
+	actionutils.ParentAction
+}
+
+func (this *MfaAction) Init() {
+	this.Nav("", "", "mfa")
+}
+
+func (this *MfaAction) RunGet(params struct{}) {
+	policy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["policy"] = policy
+
+	// 尚未注册安全密钥的超级管理员
+	admins, err := configloaders.FindSuperAdminsWithoutWebAuthn()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	var adminMaps = []maps.Map{}
+	for _, admin := range admins {
+		adminMaps = append(adminMaps, maps.Map{
+			"id":       admin.Id,
+			"fullname": admin.Fullname,
+			"username": admin.Username,
+		})
+	}
+	this.Data["superAdminsWithoutKeys"] = adminMaps
+
+	this.Show()
+}
+
+func (this *MfaAction) RunPost(params struct {
+	RequireForSuperAdmins bool
+	AllowPasswordless     bool
+	RpId                  string
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var rpId = strings.ToLower(strings.TrimSpace(params.RpId))
+	if len(rpId) > 0 && (strings.Contains(rpId, "/") || strings.Contains(rpId, ":")) {
+		this.FailField("rpId", "依赖方ID只能是域名，不能包含协议或端口")
+		return
+	}
+
+	// 防止开启后有超级管理员无法登录
+	if params.RequireForSuperAdmins {
+		err := checkSuperAdminsWebAuthn()
+		if err != nil {
+			this.Fail(err.Error())
+			return
+		}
+	}
+
+	var policy = webauthnutils.NewPolicy()
+	policy.RequireForSuperAdmins = params.RequireForSuperAdmins
+	policy.AllowPasswordless = params.AllowPasswordless
+	policy.RPId = rpId
+
+	// 是否需要审批
+	oldPolicy, err := configloaders.LoadAdminWebAuthnPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodeMFA, oldPolicy, policy) {
+		return
+	}
+
+	defer this.CreateLogInfo("修改多因素认证设置")
+
+	err = configloaders.UpdateAdminWebAuthnPolicy(policy)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
+	this.Success()
+}
+
+// 检查是否所有的超级管理员都已经注册了安全密钥
+func checkSuperAdminsWebAuthn() error {
+	admins, err := configloaders.FindSuperAdminsWithoutWebAuthn()
+	if err != nil {
+		return err
+	}
+	if len(admins) > 0 {
+		var usernames = []string{}
+		for _, admin := range admins {
+			usernames = append(usernames, admin.Username)
+		}
+		return errors.New("以下超级管理员尚未注册安全密钥，开启后将无法登录，请先在“登录设置 - 安全密钥”中注册：" + strings.Join(usernames, "、"))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package security
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes/changeutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// PasswordPolicyAction 密码策略设置
+type PasswordPolicyAction struct {
+	actionutils.ParentAction
+}
+
+func (this *PasswordPolicyAction) Init() {
+	this.Nav("", "", "passwordPolicy")
+}
+
+func (this *PasswordPolicyAction) RunGet(params struct{}) {
+	policy, err := configloaders.LoadAdminPasswordPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	this.Data["policy"] = policy
+	this.Data["maxHistoryCount"] = passwordpolicyutils.MaxHistoryCount
+
+	this.Show()
+}
+
+func (this *PasswordPolicyAction) RunPost(params struct {
+	IsOn           bool
+	MinLength      int
+	MinCharClasses int
+	CheckBreached  bool
+	HistoryCount   int
+	MaxAgeDays     int
+	ApplyToUsers   bool
+
+	Must *actions.Must
+	CSRF *actionutils.CSRF
+}) {
+	var policy = passwordpolicyutils.NewPolicy()
+	policy.IsOn = params.IsOn
+	policy.MinLength = params.MinLength
+	policy.MinCharClasses = params.MinCharClasses
+	policy.CheckBreached = params.CheckBreached
+	policy.HistoryCount = params.HistoryCount
+	policy.MaxAgeDays = params.MaxAgeDays
+	policy.ApplyToUsers = params.ApplyToUsers
+	err := policy.Validate()
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	// 是否需要审批
+	oldPolicy, err := configloaders.LoadAdminPasswordPolicy()
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+	if changeutils.SubmitSettingChange(this.Parent(), settingCodePasswordPolicy, oldPolicy, policy) {
+		return
+	}
+
+	defer this.CreateLogInfo("修改密码策略")
+
+	err = configloaders.UpdateAdminPasswordPolicy(policy)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	this.Success()
+}
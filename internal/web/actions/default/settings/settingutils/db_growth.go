@@ -0,0 +1,98 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package settingutils
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/dbgrowthutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	timeutil "github.com/iwind/TeaGo/utils/time"
+)
+
+// SettingCodeDBGrowth 数据表增长快照在系统设置中的代号
+const SettingCodeDBGrowth = "adminDBGrowth"
+
+var dbGrowthLocker = &sync.Mutex{}
+
+// LoadDBGrowthStore 读取数据表增长快照
+func LoadDBGrowthStore(ctx context.Context, rpcClient *rpc.RPCClient) (*dbgrowthutils.Store, error) {
+	resp, err := rpcClient.SysSettingRPC().ReadSysSetting(ctx, &pb.ReadSysSettingRequest{Code: SettingCodeDBGrowth})
+	if err != nil {
+		return nil, err
+	}
+	var store = dbgrowthutils.NewStore()
+	if len(resp.ValueJSON) > 0 {
+		err = json.Unmarshal(resp.ValueJSON, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// UpdateDBGrowthStore 修改数据表增长快照
+func UpdateDBGrowthStore(ctx context.Context, rpcClient *rpc.RPCClient, f func(store *dbgrowthutils.Store) error) error {
+	dbGrowthLocker.Lock()
+	defer dbGrowthLocker.Unlock()
+
+	store, err := LoadDBGrowthStore(ctx, rpcClient)
+	if err != nil {
+		return err
+	}
+	err = f(store)
+	if err != nil {
+		return err
+	}
+
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	_, err = rpcClient.SysSettingRPC().UpdateSysSetting(ctx, &pb.UpdateSysSettingRequest{
+		Code:      SettingCodeDBGrowth,
+		ValueJSON: storeJSON,
+	})
+	return err
+}
+
+// TakeDBTableSnapshot 生成当天的数据表快照
+// 如果 force 为false，并且当天已经有快照，则不再重复生成
+func TakeDBTableSnapshot(ctx context.Context, rpcClient *rpc.RPCClient, force bool) error {
+	var day = timeutil.Format("Ymd")
+	if !force {
+		store, err := LoadDBGrowthStore(ctx, rpcClient)
+		if err != nil {
+			return err
+		}
+		if store.HasDay(day) {
+			return nil
+		}
+	}
+
+	tablesResp, err := rpcClient.DBRPC().FindAllDBTables(ctx, &pb.FindAllDBTablesRequest{})
+	if err != nil {
+		return err
+	}
+	var tables = []*dbgrowthutils.TableInfo{}
+	for _, table := range tablesResp.DbTables {
+		if !table.IsBaseTable {
+			continue
+		}
+		tables = append(tables, &dbgrowthutils.TableInfo{
+			Name:  table.Name,
+			Rows:  table.Rows,
+			Bytes: table.DataLength + table.IndexLength,
+		})
+	}
+
+	var snapshot = dbgrowthutils.NewSnapshot(day, tables, time.Now().Unix())
+	return UpdateDBGrowthStore(ctx, rpcClient, func(store *dbgrowthutils.Store) error {
+		store.Add(snapshot)
+		return nil
+	})
+}
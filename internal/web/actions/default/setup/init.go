@@ -20,6 +20,9 @@ func init() {
 			Post("/checkLocalIP", new(CheckLocalIPAction)).
 			GetPost("/mysql/installPopup", new(mysql.InstallPopupAction)).
 			Post("/mysql/installLogs", new(mysql.InstallLogsAction)).
+			Post("/mysql/upload", new(mysql.UploadAction)).
+			Post("/mysql/preflight", new(mysql.PreflightAction)).
+			Post("/mysql/installOffline", new(mysql.InstallOfflineAction)).
 			EndAll()
 	})
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/TeaOSLab/EdgeAdmin/internal/configs"
 	"github.com/TeaOSLab/EdgeAdmin/internal/nodes"
 	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/setup/unattended"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/configutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -21,7 +22,6 @@ import (
 	"github.com/iwind/TeaGo/logs"
 	"github.com/iwind/TeaGo/maps"
 	"github.com/iwind/gosock/pkg/gosock"
-	"gopkg.in/yaml.v3"
 )
 
 type InstallAction struct {
@@ -98,63 +98,11 @@ func (this *InstallAction) RunPost(params struct {
 			Database: dbMap.GetString("database"),
 			Host:     configutils.QuoteIP(dbMap.GetString("host")) + ":" + dbMap.GetString("port"),
 		}
-		dbConfigData, err := yaml.Marshal(dbConfig)
+		err = unattended.WriteAPIDBConfig(apiNodeDir, dbConfig)
 		if err != nil {
-			this.Fail("生成数据库配置失败：" + err.Error())
+			this.Fail("保存数据库配置失败：" + err.Error())
 			return
 		}
-		err = os.WriteFile(apiNodeDir+"/configs/db.yaml", dbConfigData, 0666)
-		if err != nil {
-			this.Fail("保存数据库配置失败（db.yaml）：" + err.Error())
-			return
-		}
-
-		err = dbConfig.GenerateOldConfig(apiNodeDir + "/configs/.db.yaml")
-		if err != nil {
-			this.Fail("保存数据库配置失败（.db.yaml）：" + err.Error())
-			return
-		}
-
-		// 生成备份文件
-		homeDir, _ := os.UserHomeDir()
-		var backupDirs = []string{"/etc/edge-api"}
-		if len(homeDir) > 0 {
-			backupDirs = append(backupDirs, homeDir+"/.edge-api")
-		}
-		for _, backupDir := range backupDirs {
-			stat, err := os.Stat(backupDir)
-			if err == nil && stat.IsDir() {
-				_ = os.WriteFile(backupDir+"/db.yaml", dbConfigData, 0666)
-			} else if err != nil && os.IsNotExist(err) {
-				err = os.Mkdir(backupDir, 0777)
-				if err == nil {
-					_ = os.WriteFile(backupDir+"/db.yaml", dbConfigData, 0666)
-				}
-			}
-		}
-
-		err = os.WriteFile(Tea.ConfigFile("/api_db.yaml"), dbConfigData, 0666)
-		if err != nil {
-			this.Fail("保存数据库配置失败（api_db.yaml）：" + err.Error())
-			return
-		}
-
-		// 生成备份文件
-		backupDirs = []string{"/etc/edge-admin"}
-		if len(homeDir) > 0 {
-			backupDirs = append(backupDirs, homeDir+"/.edge-admin")
-		}
-		for _, backupDir := range backupDirs {
-			stat, err := os.Stat(backupDir)
-			if err == nil && stat.IsDir() {
-				_ = os.WriteFile(backupDir+"/api_db.yaml", dbConfigData, 0666)
-			} else if err != nil && os.IsNotExist(err) {
-				err = os.Mkdir(backupDir, 0777)
-				if err == nil {
-					_ = os.WriteFile(backupDir+"/api_db.yaml", dbConfigData, 0666)
-				}
-			}
-		}
 
 		// 开始安装
 		currentStatusText = "正在安装数据库表结构并写入数据"
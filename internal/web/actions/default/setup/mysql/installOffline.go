@@ -0,0 +1,58 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysql
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers/utils"
+)
+
+// InstallOfflineAction 使用离线安装包在本机安装MySQL
+type InstallOfflineAction struct {
+	actionutils.ParentAction
+}
+
+func (this *InstallOfflineAction) RunPost(params struct {
+	PackagePath   string
+	Checksum      string
+	ManifestPath  string
+	SignaturePath string
+}) {
+	// 清空日志
+	utils.SharedLogger.Reset()
+
+	this.Data["isOk"] = false
+
+	var installer = mysqlinstallers.NewMySQLInstaller()
+	installer.SetOffline(true)
+
+	// 安装前再次检查，防止文件在检查之后被修改
+	options, err := newPreflightOptions(params.PackagePath, params.Checksum, params.ManifestPath, params.SignaturePath)
+	if err != nil {
+		this.Data["err"] = err.Error()
+		this.Success()
+		return
+	}
+	var report = installer.Preflight(options)
+	this.Data["report"] = report
+	if report.HasFailures() {
+		this.Data["err"] = "pre-flight check failed:\n" + report.String()
+		this.Success()
+		return
+	}
+
+	err = installer.InstallFromFile(options.PackagePath, options.TargetDir)
+	if err != nil {
+		this.Data["err"] = "install from '" + options.PackagePath + "' failed: " + err.Error()
+		this.Success()
+		return
+	}
+
+	this.Data["user"] = "root"
+	this.Data["password"] = installer.Password()
+	this.Data["dir"] = options.TargetDir
+	this.Data["isOk"] = true
+
+	this.Success()
+}
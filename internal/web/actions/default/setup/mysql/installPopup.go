@@ -13,6 +13,11 @@ type InstallPopupAction struct {
 }
 
 func (this *InstallPopupAction) RunGet(params struct{}) {
+	// 离线安装
+	this.Data["packageDir"] = packageDir()
+	this.Data["packageFiles"] = listPackageFiles()
+	this.Data["releaseKeyFile"] = releaseKeyFile()
+
 	this.Show()
 }
 
@@ -23,7 +28,7 @@ func (this *InstallPopupAction) RunPost(params struct{}) {
 	this.Data["isOk"] = false
 
 	var installer = mysqlinstallers.NewMySQLInstaller()
-	var targetDir = "/usr/local/mysql"
+	var targetDir = defaultTargetDir
 	xzFile, err := installer.Download()
 	if err != nil {
 		this.Data["err"] = "download failed: " + err.Error()
@@ -27,12 +27,18 @@ import (
 
 type MySQLInstaller struct {
 	password string
+	offline  bool
 }
 
 func NewMySQLInstaller() *MySQLInstaller {
 	return &MySQLInstaller{}
 }
 
+// SetOffline 设置是否为离线安装，离线安装时不会尝试使用yum、apt-get等命令安装依赖
+func (this *MySQLInstaller) SetOffline(offline bool) {
+	this.offline = offline
+}
+
 func (this *MySQLInstaller) InstallFromFile(xzFilePath string, targetDir string) error {
 	// check whether mysql already running
 	this.log("checking mysqld ...")
@@ -60,7 +66,7 @@ func (this *MySQLInstaller) InstallFromFile(xzFilePath string, targetDir string)
 	// check 'tar' command
 	this.log("checking 'tar' command ...")
 	var tarExe, _ = executils.LookPath("tar")
-	if len(tarExe) == 0 {
+	if len(tarExe) == 0 && !this.offline {
 		this.log("installing 'tar' command ...")
 		err = this.installTarCommand()
 		if err != nil {
@@ -90,7 +96,11 @@ func (this *MySQLInstaller) InstallFromFile(xzFilePath string, targetDir string)
 
 	// ubuntu apt
 	aptGetExe, err := exec.LookPath("apt-get")
-	if err == nil && len(aptGetExe) > 0 {
+	if this.offline {
+		// dependencies should be checked in preflight
+		this.log("skip installing dependencies in offline mode")
+	}
+	if err == nil && len(aptGetExe) > 0 && !this.offline {
 		for _, lib := range []string{"libaio1", "libncurses5", "libnuma1"} {
 			this.log("checking " + lib + " ...")
 			var cmd = utils.NewCmd(aptGetExe, "-y", "install", lib)
@@ -117,7 +127,7 @@ func (this *MySQLInstaller) InstallFromFile(xzFilePath string, targetDir string)
 		}
 	} else { // yum
 		yumExe, err := executils.LookPath("yum")
-		if err == nil && len(yumExe) > 0 {
+		if err == nil && len(yumExe) > 0 && !this.offline {
 			for _, lib := range []string{"libaio", "ncurses-libs", "ncurses-compat-libs", "numactl-libs"} {
 				var cmd = utils.NewCmd("yum", "-y", "install", lib)
 				_ = cmd.Run()
@@ -578,13 +588,21 @@ func (this *MySQLInstaller) log(message string) {
 	utils.SharedLogger.Push("[" + timeutil.Format("H:i:s") + "]" + message)
 }
 
-// copy file
+// install service with the init system of current os
 func (this *MySQLInstaller) installService(baseDir string) error {
-	_, err := executils.LookPath("systemctl")
-	if err != nil {
-		return err
-	}
+	switch DetectInitSystem() {
+	case InitSystemd:
+		return this.installSystemdService(baseDir)
+	case InitOpenRC:
+		return this.installOpenRCService(baseDir)
+	case InitSysV:
+		return this.installSysVService(baseDir)
+	}
+	return errors.New("could not detect init system, please start mysql manually with '" + baseDir + "/support-files/mysql.server start'")
+}
 
+// install systemd service
+func (this *MySQLInstaller) installSystemdService(baseDir string) error {
 	this.log("registering systemd service ...")
 
 	var startCmd = "${BASE_DIR}/support-files/mysql.server start"
@@ -625,7 +643,7 @@ WantedBy=multi-user.target`
 
 	desc = strings.ReplaceAll(desc, "${BASE_DIR}", baseDir)
 
-	err = os.WriteFile("/etc/systemd/system/mysqld.service", []byte(desc), 0666)
+	err := os.WriteFile("/etc/systemd/system/mysqld.service", []byte(desc), 0666)
 	if err != nil {
 		return err
 	}
@@ -640,6 +658,85 @@ WantedBy=multi-user.target`
 	return nil
 }
 
+// install OpenRC service
+func (this *MySQLInstaller) installOpenRCService(baseDir string) error {
+	this.log("registering openrc service ...")
+
+	var script = `#!/sbin/openrc-run
+
+name="mysqld"
+description="MySQL Service"
+
+depend() {
+	need net
+	use dns logger
+	after firewall
+}
+
+start() {
+	ebegin "Starting ${name}"
+	${BASE_DIR}/support-files/mysql.server start
+	eend $?
+}
+
+stop() {
+	ebegin "Stopping ${name}"
+	${BASE_DIR}/support-files/mysql.server stop
+	eend $?
+}
+
+status() {
+	${BASE_DIR}/support-files/mysql.server status
+}
+`
+	script = strings.ReplaceAll(script, "${BASE_DIR}", baseDir)
+
+	err := os.WriteFile("/etc/init.d/mysqld", []byte(script), 0755)
+	if err != nil {
+		return err
+	}
+
+	var cmd = utils.NewTimeoutCmd(5*time.Second, "rc-update", "add", "mysqld", "default")
+	cmd.WithStderr()
+	err = cmd.Run()
+	if err != nil {
+		return errors.New("add mysqld to default runlevel failed: " + cmd.Stderr())
+	}
+
+	return nil
+}
+
+// install SysV init service
+func (this *MySQLInstaller) installSysVService(baseDir string) error {
+	this.log("registering sysvinit service ...")
+
+	data, err := os.ReadFile(baseDir + "/support-files/mysql.server")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile("/etc/init.d/mysqld", data, 0755)
+	if err != nil {
+		return err
+	}
+
+	// chkconfig (RHEL), update-rc.d (Debian)
+	for _, args := range [][]string{{"chkconfig", "--add", "mysqld"}, {"update-rc.d", "mysqld", "defaults"}} {
+		exe, lookupErr := executils.LookPath(args[0])
+		if lookupErr != nil || len(exe) == 0 {
+			continue
+		}
+		var cmd = utils.NewTimeoutCmd(5*time.Second, exe, args[1:]...)
+		cmd.WithStderr()
+		err = cmd.Run()
+		if err != nil {
+			return errors.New("register mysqld service failed: " + cmd.Stderr())
+		}
+		return nil
+	}
+
+	return errors.New("could not find 'chkconfig' or 'update-rc.d' command, please add '/etc/init.d/mysqld' to runlevels manually")
+}
+
 // install 'tar' command automatically
 func (this *MySQLInstaller) installTarCommand() error {
 	// dnf
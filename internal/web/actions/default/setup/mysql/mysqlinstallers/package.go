@@ -0,0 +1,227 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysqlinstallers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	stringutil "github.com/iwind/TeaGo/utils/string"
+)
+
+// 安装包文件名中的版本号、glibc版本和CPU架构
+var (
+	packageVersionReg = regexp.MustCompile(`^mysql-(\d+\.\d+\.\d+)`)
+	packageGlibcReg   = regexp.MustCompile(`glibc(\d+\.\d+)`)
+	packageArchReg    = regexp.MustCompile(`(x86_64|aarch64|i686)`)
+)
+
+// PackageVersion 从安装包文件名中读取MySQL版本号
+func PackageVersion(packagePath string) string {
+	var matches = packageVersionReg.FindStringSubmatch(filepath.Base(packagePath))
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// PackageGlibcVersion 从安装包文件名中读取依赖的glibc版本
+func PackageGlibcVersion(packagePath string) string {
+	var matches = packageGlibcReg.FindStringSubmatch(filepath.Base(packagePath))
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// PackageArch 从安装包文件名中读取CPU架构
+func PackageArch(packagePath string) string {
+	var matches = packageArchReg.FindStringSubmatch(filepath.Base(packagePath))
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// FindPackages 查找某个目录下的MySQL安装包，新版本排在前面
+func FindPackages(dir string) []string {
+	matches, err := filepath.Glob(filepath.Clean(dir) + "/mysql-*.tar.xz")
+	if err != nil {
+		return nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return stringutil.VersionCompare(PackageVersion(matches[i]), PackageVersion(matches[j])) > 0
+	})
+	return matches
+}
+
+// ReleaseKeyFilename 校验安装包签名用的MySQL官方发布公钥文件名
+const ReleaseKeyFilename = "mysql-release-key.asc"
+
+// ResolvePathInDir 清理路径并解析其中的符号链接，要求结果位于dir目录中
+// 用来防止通过"../"或者符号链接读取目录以外的文件
+func ResolvePathInDir(dir string, path string) (string, error) {
+	realDir, err := filepath.EvalSymlinks(filepath.Clean(dir))
+	if err != nil {
+		return "", err
+	}
+	realPath, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(realDir, realPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("'" + path + "' is not in directory '" + dir + "'")
+	}
+	return realPath, nil
+}
+
+// Manifest 校验和清单
+// 支持 sha256sum/md5sum 等命令输出的格式（"HASH  FILENAME"），以及BSD格式（"SHA256 (FILENAME) = HASH"）
+type Manifest struct {
+	checksums map[string]string // filename => hex checksum
+}
+
+var bsdManifestLineReg = regexp.MustCompile(`^(?i:MD5|SHA256|SHA512)\s*\((.+)\)\s*=\s*([0-9a-fA-F]+)$`)
+
+// ParseManifest 分析校验和清单
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest = &Manifest{
+		checksums: map[string]string{},
+	}
+	var scanner = bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// BSD
+		var matches = bsdManifestLineReg.FindStringSubmatch(line)
+		if len(matches) > 0 {
+			manifest.checksums[filepath.Base(matches[1])] = strings.ToLower(matches[2])
+			continue
+		}
+
+		// GNU
+		var fields = strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.New("invalid manifest line '" + line + "'")
+		}
+		var checksum = strings.ToLower(fields[0])
+		if newChecksumHash(checksum) == nil {
+			return nil, errors.New("invalid checksum in line '" + line + "'")
+		}
+		manifest.checksums[filepath.Base(strings.TrimPrefix(fields[1], "*"))] = checksum
+	}
+	if len(manifest.checksums) == 0 {
+		return nil, errors.New("no checksums found in manifest")
+	}
+	return manifest, nil
+}
+
+// Checksum 查找某个文件的校验和
+func (this *Manifest) Checksum(filename string) string {
+	return this.checksums[filepath.Base(filename)]
+}
+
+// VerifyChecksum 校验文件内容，根据校验和长度自动选择MD5、SHA256或SHA512算法
+func VerifyChecksum(path string, expected string) error {
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	var h = newChecksumHash(expected)
+	if h == nil {
+		return errors.New("unsupported checksum '" + expected + "'")
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	_, err = io.Copy(h, fp)
+	if err != nil {
+		return err
+	}
+	var actual = hex.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		return errors.New("checksum mismatch, expected '" + expected + "', actual '" + actual + "'")
+	}
+	return nil
+}
+
+// VerifySignature 使用OpenPGP公钥校验文件的分离签名（比如MySQL官方提供的.asc文件）
+// 返回签名者信息
+func VerifySignature(path string, signaturePath string, keyringPath string) (signer string, err error) {
+	keyringData, err := os.ReadFile(keyringPath)
+	if err != nil {
+		return "", errors.New("read public key failed: " + err.Error())
+	}
+	var keyring openpgp.EntityList
+	if bytes.Contains(keyringData, []byte("-----BEGIN PGP")) {
+		keyring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(keyringData))
+	} else {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(keyringData))
+	}
+	if err != nil {
+		return "", errors.New("parse public key failed: " + err.Error())
+	}
+
+	signatureData, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return "", errors.New("read signature failed: " + err.Error())
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = fp.Close()
+	}()
+
+	var entity *openpgp.Entity
+	if bytes.Contains(signatureData, []byte("-----BEGIN PGP")) {
+		entity, err = openpgp.CheckArmoredDetachedSignature(keyring, fp, bytes.NewReader(signatureData), nil)
+	} else {
+		entity, err = openpgp.CheckDetachedSignature(keyring, fp, bytes.NewReader(signatureData), nil)
+	}
+	if err != nil {
+		return "", errors.New("invalid signature: " + err.Error())
+	}
+	for name := range entity.Identities {
+		return name, nil
+	}
+	return entity.PrimaryKey.KeyIdString(), nil
+}
+
+func newChecksumHash(checksum string) hash.Hash {
+	_, err := hex.DecodeString(checksum)
+	if err != nil {
+		return nil
+	}
+	switch len(checksum) {
+	case md5.Size * 2:
+		return md5.New()
+	case sha256.Size * 2:
+		return sha256.New()
+	case sha512.Size * 2:
+		return sha512.New()
+	}
+	return nil
+}
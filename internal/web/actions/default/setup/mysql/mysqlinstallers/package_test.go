@@ -0,0 +1,114 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysqlinstallers_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers"
+	"github.com/iwind/TeaGo/assert"
+)
+
+func TestPackageInfo(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var path = "/opt/mysql-8.0.36-linux-glibc2.28-x86_64.tar.xz"
+	a.IsTrue(mysqlinstallers.PackageVersion(path) == "8.0.36")
+	a.IsTrue(mysqlinstallers.PackageGlibcVersion(path) == "2.28")
+	a.IsTrue(mysqlinstallers.PackageArch(path) == "x86_64")
+	a.IsTrue(mysqlinstallers.PackageGlibcVersion("mysql.tar.xz") == "")
+}
+
+func TestParseManifest(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	manifest, err := mysqlinstallers.ParseManifest([]byte(`# checksums
+d41d8cd98f00b204e9800998ecf8427e  mysql-8.0.36-linux-glibc2.28-x86_64.tar.xz
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *mysql-8.2.0-linux-glibc2.17-x86_64-minimal.tar.xz
+SHA256 (mysql-8.4.0.tar.xz) = E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IsTrue(manifest.Checksum("/tmp/mysql-8.0.36-linux-glibc2.28-x86_64.tar.xz") == "d41d8cd98f00b204e9800998ecf8427e")
+	a.IsTrue(manifest.Checksum("mysql-8.2.0-linux-glibc2.17-x86_64-minimal.tar.xz") == "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	a.IsTrue(manifest.Checksum("mysql-8.4.0.tar.xz") == "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	a.IsTrue(manifest.Checksum("mysql-5.7.tar.xz") == "")
+
+	_, err = mysqlinstallers.ParseManifest([]byte("abc mysql.tar.xz"))
+	a.IsNotNil(err)
+	_, err = mysqlinstallers.ParseManifest([]byte("# empty"))
+	a.IsNotNil(err)
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var path = t.TempDir() + "/mysql.tar.xz"
+	err := os.WriteFile(path, []byte{}, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.IsNil(mysqlinstallers.VerifyChecksum(path, "d41d8cd98f00b204e9800998ecf8427e"))
+	a.IsNil(mysqlinstallers.VerifyChecksum(path, "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"))
+	a.IsNotNil(mysqlinstallers.VerifyChecksum(path, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b856"))
+	a.IsNotNil(mysqlinstallers.VerifyChecksum(path, "123"))
+}
+
+func TestParseGlibcVersion(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	a.IsTrue(mysqlinstallers.ParseGlibcVersion("glibc 2.31\n") == "2.31")
+	a.IsTrue(mysqlinstallers.ParseGlibcVersion("ldd (Ubuntu GLIBC 2.35-0ubuntu3.6) 2.35\nCopyright (C) 2022") == "2.35")
+	a.IsTrue(mysqlinstallers.ParseGlibcVersion("ldd (GNU libc) 2.17\n") == "2.17")
+	a.IsTrue(mysqlinstallers.ParseGlibcVersion("musl libc (x86_64)\nVersion 1.2.4") == "")
+}
+
+func TestMySQLInstaller_Preflight(t *testing.T) {
+	var installer = mysqlinstallers.NewMySQLInstaller()
+	var report = installer.Preflight(&mysqlinstallers.PreflightOptions{
+		PackagePath: "/tmp/mysql-not-exists.tar.xz",
+		TargetDir:   t.TempDir() + "/mysql",
+	})
+	if !report.HasFailures() {
+		t.Fatal("should fail with missing package")
+	}
+	t.Log(report.String())
+}
+
+func TestResolvePathInDir(t *testing.T) {
+	var a = assert.NewAssertion(t)
+
+	var root = t.TempDir()
+	var dir = root + "/packages"
+	err := os.Mkdir(dir, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{dir + "/mysql.tar.xz", root + "/outside.asc"} {
+		err = os.WriteFile(path, []byte{}, 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink(root+"/outside.asc", dir+"/link.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := mysqlinstallers.ResolvePathInDir(dir, dir+"/./mysql.tar.xz")
+	a.IsNil(err)
+	a.IsTrue(filepath.Base(path) == "mysql.tar.xz")
+
+	_, err = mysqlinstallers.ResolvePathInDir(dir, dir+"/../outside.asc")
+	a.IsNotNil(err)
+	_, err = mysqlinstallers.ResolvePathInDir(dir, dir+"/link.asc")
+	a.IsNotNil(err)
+	_, err = mysqlinstallers.ResolvePathInDir(dir, dir)
+	a.IsNotNil(err)
+	_, err = mysqlinstallers.ResolvePathInDir(dir, dir+"/not-exists.tar.xz")
+	a.IsNotNil(err)
+}
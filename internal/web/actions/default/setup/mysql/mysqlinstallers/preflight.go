@@ -0,0 +1,431 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysqlinstallers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	executils "github.com/TeaOSLab/EdgeAdmin/internal/utils/exec"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers/utils"
+	stringutil "github.com/iwind/TeaGo/utils/string"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// 检查项状态
+const (
+	PreflightOk   = "ok"
+	PreflightWarn = "warn"
+	PreflightFail = "fail"
+)
+
+// 服务管理方式
+const (
+	InitSystemd = "systemd"
+	InitOpenRC  = "openrc"
+	InitSysV    = "sysvinit"
+)
+
+// PreflightOptions 安装前检查选项
+type PreflightOptions struct {
+	PackagePath   string // 安装包路径
+	TargetDir     string // 安装目录
+	Checksum      string // 直接填写的校验和
+	ManifestPath  string // 校验和清单文件
+	SignaturePath string // 安装包的分离签名文件
+	KeyringPath   string // 用来校验签名的OpenPGP公钥文件，只能使用管理员放置的固定文件，不能由用户指定
+}
+
+// PreflightItem 单个检查项
+type PreflightItem struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// PreflightReport 安装前检查报告
+type PreflightReport struct {
+	Items []*PreflightItem `json:"items"`
+}
+
+// HasFailures 是否有未通过的检查项
+func (this *PreflightReport) HasFailures() bool {
+	for _, item := range this.Items {
+		if item.Status == PreflightFail {
+			return true
+		}
+	}
+	return false
+}
+
+// String 转换为可以输出到日志中的文本
+func (this *PreflightReport) String() string {
+	var lines = []string{}
+	for _, item := range this.Items {
+		lines = append(lines, "["+strings.ToUpper(item.Status)+"]"+item.Name+": "+item.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (this *PreflightReport) add(name string, status string, message string) *PreflightItem {
+	var item = &PreflightItem{
+		Name:    name,
+		Status:  status,
+		Message: message,
+	}
+	this.Items = append(this.Items, item)
+	return item
+}
+
+// Preflight 在不访问网络的情况下检查安装包和当前系统是否满足安装条件
+func (this *MySQLInstaller) Preflight(options *PreflightOptions) *PreflightReport {
+	var report = &PreflightReport{}
+
+	// 安装包
+	var packageSize int64
+	stat, err := os.Stat(options.PackagePath)
+	if err != nil {
+		report.add("安装包", PreflightFail, "无法读取安装包'"+options.PackagePath+"'："+err.Error())
+	} else if stat.IsDir() || !strings.HasSuffix(options.PackagePath, ".tar.xz") {
+		report.add("安装包", PreflightFail, "'"+options.PackagePath+"'不是有效的.tar.xz安装包")
+	} else {
+		packageSize = stat.Size()
+		var version = PackageVersion(options.PackagePath)
+		if len(version) == 0 {
+			version = "未知"
+		}
+		report.add("安装包", PreflightOk, filepath.Base(options.PackagePath)+"，版本："+version+"，大小："+this.formatBytes(packageSize))
+	}
+	var packageOk = packageSize > 0
+
+	// 校验和
+	var checksumItem *PreflightItem
+	{
+		var checksum = strings.TrimSpace(options.Checksum)
+		var source = "填写的校验和"
+		if len(checksum) == 0 && len(options.ManifestPath) > 0 {
+			source = "校验和清单" + filepath.Base(options.ManifestPath)
+			manifestData, err := os.ReadFile(options.ManifestPath)
+			if err != nil {
+				checksumItem = report.add("校验和", PreflightFail, "无法读取校验和清单："+err.Error())
+			} else {
+				manifest, err := ParseManifest(manifestData)
+				if err != nil {
+					checksumItem = report.add("校验和", PreflightFail, "校验和清单格式错误："+err.Error())
+				} else {
+					checksum = manifest.Checksum(options.PackagePath)
+					if len(checksum) == 0 {
+						checksumItem = report.add("校验和", PreflightFail, "校验和清单中没有找到'"+filepath.Base(options.PackagePath)+"'")
+					}
+				}
+			}
+		}
+		if checksumItem == nil {
+			if len(checksum) == 0 {
+				checksumItem = report.add("校验和", PreflightFail, "没有提供校验和，需要填写校验和或者提供校验和清单")
+			} else if packageOk {
+				err = VerifyChecksum(options.PackagePath, checksum)
+				if err != nil {
+					checksumItem = report.add("校验和", PreflightFail, "和"+source+"不一致："+err.Error())
+				} else {
+					checksumItem = report.add("校验和", PreflightOk, "和"+source+"一致")
+				}
+			} else {
+				checksumItem = report.add("校验和", PreflightWarn, "安装包无效，跳过校验")
+			}
+		}
+	}
+
+	// 签名
+	if len(options.SignaturePath) > 0 {
+		if len(options.KeyringPath) == 0 {
+			report.add("签名", PreflightFail, "没有配置校验签名用的MySQL官方发布公钥")
+		} else if packageOk {
+			signer, err := VerifySignature(options.PackagePath, options.SignaturePath, options.KeyringPath)
+			if err != nil {
+				report.add("签名", PreflightFail, "签名校验失败："+err.Error())
+			} else {
+				report.add("签名", PreflightOk, "签名有效，签名者："+signer)
+			}
+		} else {
+			report.add("签名", PreflightWarn, "安装包无效，跳过校验")
+		}
+	} else {
+		report.add("签名", PreflightFail, "没有提供签名文件，需要提供安装包的签名文件")
+	}
+
+	// CPU架构
+	{
+		var packageArch = PackageArch(options.PackagePath)
+		var systemArch = this.systemArch()
+		if len(packageArch) == 0 {
+			report.add("CPU架构", PreflightWarn, "无法从安装包文件名中识别CPU架构，当前系统为"+systemArch)
+		} else if packageArch != systemArch {
+			report.add("CPU架构", PreflightFail, "安装包适用于"+packageArch+"，当前系统为"+systemArch)
+		} else {
+			report.add("CPU架构", PreflightOk, systemArch)
+		}
+	}
+
+	// glibc
+	{
+		var packageGlibc = PackageGlibcVersion(options.PackagePath)
+		var systemGlibc = SystemGlibcVersion()
+		if len(systemGlibc) == 0 {
+			report.add("glibc", PreflightWarn, "无法检测当前系统的glibc版本，如果系统使用的是musl等其他C库，将无法运行此安装包")
+		} else if len(packageGlibc) == 0 {
+			report.add("glibc", PreflightWarn, "无法从安装包文件名中识别依赖的glibc版本，当前系统为"+systemGlibc)
+		} else if stringutil.VersionCompare(systemGlibc, packageGlibc) < 0 {
+			report.add("glibc", PreflightFail, "安装包需要glibc "+packageGlibc+"以上，当前系统为"+systemGlibc)
+		} else {
+			report.add("glibc", PreflightOk, "安装包需要"+packageGlibc+"，当前系统为"+systemGlibc)
+		}
+	}
+
+	// 依赖的库
+	{
+		var libFile = FindLibrary("libaio.so.1", "libaio.so.1t64")
+		if len(libFile) == 0 {
+			report.add("libaio", PreflightFail, "没有找到libaio库，请使用系统安装介质离线安装libaio（CentOS等为libaio，Ubuntu等为libaio1或libaio1t64）")
+		} else {
+			report.add("libaio", PreflightOk, libFile)
+		}
+
+		libFile = FindLibrary("libnuma.so.1")
+		if len(libFile) == 0 {
+			report.add("libnuma", PreflightWarn, "没有找到libnuma库，在部分系统上可能会导致MySQL无法启动")
+		} else {
+			report.add("libnuma", PreflightOk, libFile)
+		}
+
+		libFile = FindLibrary("libncurses.so.5", "libncurses.so.6", "libtinfo.so.5", "libtinfo.so.6")
+		if len(libFile) == 0 {
+			report.add("libncurses", PreflightWarn, "没有找到libncurses库，不影响MySQL服务，但mysql命令行客户端可能无法使用")
+		} else {
+			report.add("libncurses", PreflightOk, libFile)
+		}
+	}
+
+	// 系统命令
+	{
+		var missingCommands = []string{}
+		for _, cmdList := range [][]string{{"tar"}, {"xz"}, {"chown"}, {"sh"}, {"groupadd", "addgroup"}, {"useradd", "adduser"}} {
+			var found = false
+			for _, cmd := range cmdList {
+				cmdPath, err := executils.LookPath(cmd)
+				if err == nil && len(cmdPath) > 0 {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missingCommands = append(missingCommands, strings.Join(cmdList, "/"))
+			}
+		}
+		if len(missingCommands) > 0 {
+			report.add("系统命令", PreflightFail, "缺少命令："+strings.Join(missingCommands, "、"))
+		} else {
+			report.add("系统命令", PreflightOk, "tar、xz、chown、sh、groupadd、useradd")
+		}
+	}
+
+	// 用户权限
+	if runtime.GOOS != "windows" {
+		if os.Geteuid() != 0 {
+			report.add("用户权限", PreflightFail, "需要使用root用户运行管理系统才能安装MySQL")
+		} else {
+			report.add("用户权限", PreflightOk, "root")
+		}
+	}
+
+	// 磁盘空间
+	{
+		// 解压后的文件和初始化的数据大约是安装包的10倍
+		var requiredBytes = packageSize*10 + 1<<30
+		var checkedDirs = map[string]bool{}
+		for _, dir := range []string{filepath.Dir(options.TargetDir), os.TempDir()} {
+			var existDir = this.findExistDir(dir)
+			if checkedDirs[existDir] {
+				continue
+			}
+			checkedDirs[existDir] = true
+
+			usage, err := disk.Usage(existDir)
+			if err != nil {
+				report.add("磁盘空间", PreflightWarn, "无法检查'"+existDir+"'所在磁盘的剩余空间："+err.Error())
+				continue
+			}
+			var message = "'" + existDir + "'所在磁盘剩余" + this.formatBytes(int64(usage.Free)) + "，需要" + this.formatBytes(requiredBytes)
+			if int64(usage.Free) < requiredBytes {
+				report.add("磁盘空间", PreflightFail, message)
+			} else {
+				report.add("磁盘空间", PreflightOk, message)
+			}
+		}
+	}
+
+	// 内存
+	{
+		var memoryGB = this.sysMemoryGB()
+		if memoryGB <= 0 {
+			report.add("内存", PreflightWarn, "系统内存不足1GiB或无法检测，MySQL可能无法正常运行")
+		} else if memoryGB < 2 {
+			report.add("内存", PreflightWarn, "系统内存"+strconv.Itoa(memoryGB)+"GiB，建议至少2GiB，InnoDB缓冲池将设置为1GiB")
+		} else {
+			report.add("内存", PreflightOk, "系统内存"+strconv.Itoa(memoryGB)+"GiB，InnoDB缓冲池将设置为"+strconv.Itoa(memoryGB/2)+"GiB")
+		}
+	}
+
+	// 进程和端口
+	{
+		var pid = utils.FindPidWithName("mysqld")
+		if pid > 0 {
+			report.add("mysqld进程", PreflightFail, "已经有正在运行的mysqld进程，PID："+strconv.Itoa(pid))
+		} else {
+			report.add("mysqld进程", PreflightOk, "没有正在运行的mysqld进程")
+		}
+
+		conn, err := net.DialTimeout("tcp", "127.0.0.1:3306", 1*time.Second)
+		if err == nil {
+			_ = conn.Close()
+			report.add("3306端口", PreflightFail, "3306端口已经被占用")
+		} else {
+			report.add("3306端口", PreflightOk, "未被占用")
+		}
+	}
+
+	// 安装目录
+	{
+		matches, _ := filepath.Glob(options.TargetDir + "/*")
+		if len(matches) > 0 {
+			report.add("安装目录", PreflightFail, "'"+options.TargetDir+"'已经存在并且不为空")
+		} else {
+			report.add("安装目录", PreflightOk, options.TargetDir)
+		}
+	}
+
+	// 服务管理
+	{
+		var initSystem = DetectInitSystem()
+		if len(initSystem) == 0 {
+			report.add("服务管理", PreflightWarn, "没有检测到systemd、OpenRC或SysV init，安装后MySQL不会随系统自动启动")
+		} else {
+			report.add("服务管理", PreflightOk, "将注册为"+initSystem+"服务")
+		}
+	}
+
+	return report
+}
+
+// SystemGlibcVersion 检测当前系统的glibc版本
+func SystemGlibcVersion() string {
+	for _, args := range [][]string{{"getconf", "GNU_LIBC_VERSION"}, {"ldd", "--version"}} {
+		exe, err := executils.LookPath(args[0])
+		if err != nil || len(exe) == 0 {
+			continue
+		}
+		var cmd = utils.NewTimeoutCmd(5*time.Second, exe, args[1:]...)
+		cmd.WithStdout()
+		cmd.WithStderr()
+		err = cmd.Run()
+		if err != nil {
+			continue
+		}
+		var version = ParseGlibcVersion(cmd.Stdout())
+		if len(version) > 0 {
+			return version
+		}
+	}
+	return ""
+}
+
+var glibcVersionReg = regexp.MustCompile(`(?i)(?:glibc|gnu libc)[^\n]*?(\d+\.\d+)`)
+
+// ParseGlibcVersion 从 getconf GNU_LIBC_VERSION 或 ldd --version 的输出中读取glibc版本
+func ParseGlibcVersion(output string) string {
+	var matches = glibcVersionReg.FindStringSubmatch(output)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// FindLibrary 在系统库目录中查找动态库，返回找到的第一个文件路径
+func FindLibrary(names ...string) string {
+	var dirs = []string{"/lib64", "/usr/lib64", "/lib", "/usr/lib", "/usr/local/lib"}
+	for _, multiArch := range []string{"x86_64-linux-gnu", "aarch64-linux-gnu", "i386-linux-gnu"} {
+		dirs = append(dirs, "/lib/"+multiArch, "/usr/lib/"+multiArch)
+	}
+	for _, name := range names {
+		for _, dir := range dirs {
+			var path = dir + "/" + name
+			_, err := os.Stat(path)
+			if err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// DetectInitSystem 检测系统使用的服务管理方式
+func DetectInitSystem() string {
+	stat, err := os.Stat("/run/systemd/system")
+	if err == nil && stat.IsDir() {
+		systemctlExe, _ := executils.LookPath("systemctl")
+		if len(systemctlExe) > 0 {
+			return InitSystemd
+		}
+	}
+
+	stat, err = os.Stat("/etc/init.d")
+	if err != nil || !stat.IsDir() {
+		return ""
+	}
+	rcUpdateExe, _ := executils.LookPath("rc-update")
+	if len(rcUpdateExe) > 0 {
+		return InitOpenRC
+	}
+	return InitSysV
+}
+
+func (this *MySQLInstaller) systemArch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i686"
+	}
+	return runtime.GOARCH
+}
+
+// 查找存在的上级目录
+func (this *MySQLInstaller) findExistDir(dir string) string {
+	for {
+		_, err := os.Stat(dir)
+		if err == nil {
+			return dir
+		}
+		var parentDir = filepath.Dir(dir)
+		if parentDir == dir {
+			return dir
+		}
+		dir = parentDir
+	}
+}
+
+func (this *MySQLInstaller) formatBytes(bytes int64) string {
+	if bytes >= 1<<30 {
+		return fmt.Sprintf("%.2fGiB", float64(bytes)/(1<<30))
+	}
+	return fmt.Sprintf("%.2fMiB", float64(bytes)/(1<<20))
+}
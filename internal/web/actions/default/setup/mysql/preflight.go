@@ -0,0 +1,36 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysql
+
+import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers"
+)
+
+// PreflightAction 离线安装前检查
+type PreflightAction struct {
+	actionutils.ParentAction
+}
+
+func (this *PreflightAction) RunPost(params struct {
+	PackagePath   string
+	Checksum      string
+	ManifestPath  string
+	SignaturePath string
+}) {
+	if len(params.PackagePath) == 0 {
+		this.Fail("请选择或者填写安装包路径")
+		return
+	}
+
+	options, err := newPreflightOptions(params.PackagePath, params.Checksum, params.ManifestPath, params.SignaturePath)
+	if err != nil {
+		this.Fail(err.Error())
+		return
+	}
+
+	var report = mysqlinstallers.NewMySQLInstaller().Preflight(options)
+	this.Data["report"] = report
+	this.Data["hasFailures"] = report.HasFailures()
+	this.Success()
+}
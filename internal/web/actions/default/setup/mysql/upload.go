@@ -0,0 +1,46 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysql
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
+	"github.com/iwind/TeaGo/actions"
+)
+
+// UploadAction 上传离线安装包或校验文件
+type UploadAction struct {
+	actionutils.ParentAction
+}
+
+func (this *UploadAction) RunPost(params struct {
+	File *actions.File
+}) {
+	if params.File == nil {
+		this.Fail("请选择要上传的文件")
+		return
+	}
+
+	var filename = filepath.Base(params.File.Filename)
+	if len(filename) == 0 || strings.HasPrefix(filename, ".") {
+		this.Fail("文件名'" + params.File.Filename + "'无效")
+		return
+	}
+	if len(detectPackageFileType(filename)) == 0 {
+		this.Fail("不支持的文件'" + filename + "'，只能上传.tar.xz安装包、校验和清单或者签名文件（.asc、.sig）")
+		return
+	}
+
+	var path = packageDir() + "/" + filename
+	_, err := params.File.WriteToPath(path)
+	if err != nil {
+		this.Fail("保存文件失败：" + err.Error())
+		return
+	}
+
+	this.Data["fileType"] = detectPackageFileType(filename)
+	this.Data["path"] = path
+	this.Success()
+}
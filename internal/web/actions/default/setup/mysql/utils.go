@@ -0,0 +1,133 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package mysql
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/setup/mysql/mysqlinstallers"
+	"github.com/iwind/TeaGo/Tea"
+	"github.com/iwind/TeaGo/maps"
+)
+
+// 默认安装目录
+const defaultTargetDir = "/usr/local/mysql"
+
+// 离线安装包和校验文件存放目录，可以通过上传或者预先复制的方式放置文件
+func packageDir() string {
+	return Tea.Root + "/mysql-packages"
+}
+
+// 校验安装包签名用的MySQL官方发布公钥
+// 放在配置目录中，需要管理员手动放置，不能通过上传修改
+func releaseKeyFile() string {
+	return Tea.ConfigFile(mysqlinstallers.ReleaseKeyFilename)
+}
+
+// 离线安装文件类型
+const (
+	packageFileTypePackage   = "package"
+	packageFileTypeManifest  = "manifest"
+	packageFileTypeSignature = "signature"
+)
+
+// 根据文件名判断离线安装文件类型
+func detectPackageFileType(filename string) string {
+	var lowerName = strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lowerName, ".tar.xz"):
+		return packageFileTypePackage
+	case strings.HasSuffix(lowerName, ".asc") || strings.HasSuffix(lowerName, ".sig"):
+		return packageFileTypeSignature
+	case strings.Contains(lowerName, "sums") || strings.HasSuffix(lowerName, ".sha256") || strings.HasSuffix(lowerName, ".sha512") || strings.HasSuffix(lowerName, ".md5") || strings.HasSuffix(lowerName, ".txt"):
+		return packageFileTypeManifest
+	}
+	return ""
+}
+
+// 列出离线安装文件
+func listPackageFiles() map[string][]maps.Map {
+	var result = map[string][]maps.Map{
+		packageFileTypePackage:   {},
+		packageFileTypeManifest:  {},
+		packageFileTypeSignature: {},
+	}
+
+	var dir = packageDir()
+
+	// 安装包按版本排序
+	for _, path := range mysqlinstallers.FindPackages(dir) {
+		result[packageFileTypePackage] = append(result[packageFileTypePackage], maps.Map{
+			"name": filepath.Base(path),
+			"path": path,
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		var fileType = detectPackageFileType(entry.Name())
+		if len(fileType) == 0 || fileType == packageFileTypePackage {
+			continue
+		}
+		result[fileType] = append(result[fileType], maps.Map{
+			"name": entry.Name(),
+			"path": dir + "/" + entry.Name(),
+		})
+	}
+	return result
+}
+
+// 构造安装前检查选项
+// 安装包、校验和清单和签名文件都必须位于离线安装目录中，公钥只使用配置目录中的固定文件
+func newPreflightOptions(packagePath string, checksum string, manifestPath string, signaturePath string) (*mysqlinstallers.PreflightOptions, error) {
+	packagePath, err := resolvePackageFile(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	manifestPath, err = resolvePackageFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	signaturePath, err = resolvePackageFile(signaturePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqlinstallers.PreflightOptions{
+		PackagePath:   packagePath,
+		TargetDir:     defaultTargetDir,
+		Checksum:      strings.TrimSpace(checksum),
+		ManifestPath:  manifestPath,
+		SignaturePath: signaturePath,
+		KeyringPath:   releaseKeyFile(),
+	}, nil
+}
+
+// 检查文件是否位于离线安装目录中，返回解析符号链接后的路径
+func resolvePackageFile(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if len(path) == 0 {
+		return "", nil
+	}
+	resolvedPath, err := mysqlinstallers.ResolvePathInDir(packageDir(), path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.New("文件'" + path + "'不存在")
+		}
+		return "", errors.New("文件'" + path + "'必须位于目录'" + packageDir() + "'中")
+	}
+	return resolvedPath, nil
+}
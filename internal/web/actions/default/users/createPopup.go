@@ -3,8 +3,10 @@ package users
 import (
 	"encoding/json"
 
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
 	teaconst "github.com/TeaOSLab/EdgeAdmin/internal/const"
 	"github.com/TeaOSLab/EdgeAdmin/internal/utils/numberutils"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
 	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
@@ -75,6 +77,13 @@ func (this *CreatePopupAction) RunPost(params struct {
 		Require("请再次输入确认密码").
 		Equal(params.Pass1, "两次输入的密码不一致")
 
+	// 密码策略
+	err = configloaders.CheckPassword(passwordpolicyutils.KindUser, 0, params.Username, params.Pass1)
+	if err != nil {
+		this.FailField("pass1", err.Error())
+		return
+	}
+
 	params.Must.
 		Field("fullname", params.Fullname).
 		Require("请输入全名")
@@ -112,6 +121,13 @@ func (this *CreatePopupAction) RunPost(params struct {
 
 	userId = createResp.UserId
 
+	// 记录密码
+	err = configloaders.RecordPasswordChange(passwordpolicyutils.KindUser, userId, params.Pass1)
+	if err != nil {
+		this.ErrorPage(err)
+		return
+	}
+
 	// 功能
 	if teaconst.IsPlus {
 		if params.FeaturesType == "default" {
@@ -1,6 +1,8 @@
 package users
 
 import (
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/passwordpolicyutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/actionutils"
 	"github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/users/userutils"
 	"github.com/TeaOSLab/EdgeCommon/pkg/langs/codes"
@@ -134,6 +136,13 @@ func (this *UpdateAction) RunPost(params struct {
 			Field("pass2", params.Pass2).
 			Require("请再次输入确认密码").
 			Equal(params.Pass1, "两次输入的密码不一致")
+
+		// 密码策略
+		err = configloaders.CheckPassword(passwordpolicyutils.KindUser, params.UserId, params.Username, params.Pass1)
+		if err != nil {
+			this.FailField("pass1", err.Error())
+			return
+		}
 	}
 
 	params.Must.
@@ -169,6 +178,15 @@ func (this *UpdateAction) RunPost(params struct {
 		return
 	}
 
+	// 记录密码
+	if len(params.Pass1) > 0 {
+		err = configloaders.RecordPasswordChange(passwordpolicyutils.KindUser, params.UserId, params.Pass1)
+		if err != nil {
+			this.ErrorPage(err)
+			return
+		}
+	}
+
 	// 修改OTP
 	otpLoginResp, err := this.RPC().LoginRPC().FindEnabledLogin(this.AdminContext(), &pb.FindEnabledLoginRequest{
 		UserId: params.UserId,
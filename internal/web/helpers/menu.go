@@ -81,6 +81,11 @@ func FindAllMenuMaps(langCode string, nodeLogsType string, countUnreadNodeLogs i
 					"url":  "/servers/metrics",
 					"code": "metric",
 				},
+				{
+					"name": "流量报表",
+					"url":  "/servers/reports",
+					"code": "report",
+				},
 			},
 		},
 		{
@@ -111,6 +116,11 @@ func FindAllMenuMaps(langCode string, nodeLogsType string, countUnreadNodeLogs i
 					"url":  "/clusters/grants",
 					"code": "grant",
 				},
+				{
+					"name": "运行手册",
+					"url":  "/clusters/runbooks",
+					"code": "runbook",
+				},
 			},
 		},
 		{
@@ -163,6 +173,12 @@ func FindAllMenuMaps(langCode string, nodeLogsType string, countUnreadNodeLogs i
 			"name":   langs.Message(langCode, codes.AdminMenu_Logs),
 			"icon":   "history",
 		},
+		{
+			"code":   "changes",
+			"module": configloaders.AdminModuleCodeCommon,
+			"name":   "变更审批",
+			"icon":   "check square outline",
+		},
 		{
 			"code":     "settings",
 			"module":   configloaders.AdminModuleCodeSetting,
@@ -136,8 +136,7 @@ func (this *userMustAuth) BeforeAction(actionPtr actions.ActionWrapper, paramNam
 	if teaconst.IsDemoMode {
 		if action.Request.Method == http.MethodPost {
 			var actionName = action.Spec.ClassName[strings.LastIndex(action.Spec.ClassName, ".")+1:]
-			var denyPrefixes = []string{"Update", "Create", "Delete", "Truncate", "Clean", "Clear", "Reset", "Add", "Remove", "Sync", "Run", "Exec"}
-			for _, prefix := range denyPrefixes {
+			for _, prefix := range writeActionPrefixes {
 				if strings.HasPrefix(actionName, prefix) {
 					action.Fail(teaconst.ErrorDemoOperation)
 					return false
@@ -246,6 +245,16 @@ func (this *userMustAuth) BeforeAction(actionPtr actions.ActionWrapper, paramNam
 		return false
 	}
 
+	// 密码已过期时，只允许修改密码和退出登录
+	if session.GetString(teaconst.SessionPasswordExpired) == "1" && !lists.ContainsString([]string{"/settings/login", "/logout", "/messages/badge"}, action.Request.URL.Path) {
+		if action.Request.Method == http.MethodGet {
+			action.RedirectURL("/settings/login")
+		} else {
+			action.Fail("密码已过期，请先修改登录密码")
+		}
+		return false
+	}
+
 	// 检查用户权限
 	if len(this.module) > 0 && !configloaders.AllowModule(adminId, this.module) {
 		action.ResponseWriter.WriteHeader(http.StatusForbidden)
@@ -253,6 +262,17 @@ func (this *userMustAuth) BeforeAction(actionPtr actions.ActionWrapper, paramNam
 		return false
 	}
 
+	// 检查角色中的读写权限和资源范围
+	if len(this.module) > 0 && !this.allowRBAC(actionPtr, adminId) {
+		if action.Request.Method == http.MethodGet {
+			action.ResponseWriter.WriteHeader(http.StatusForbidden)
+			action.WriteString("Permission Denied.")
+		} else {
+			action.Fail("权限不足：当前角色没有此操作的权限")
+		}
+		return false
+	}
+
 	this.AdminId = adminId
 	action.Context.Set("adminId", this.AdminId)
 
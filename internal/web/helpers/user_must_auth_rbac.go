@@ -0,0 +1,305 @@
+// Copyright 2024 GoEdge CDN goedge.cdn@gmail.com. All rights reserved. Official site: https://goedge.cloud .
+
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TeaOSLab/EdgeAdmin/internal/configloaders"
+	"github.com/TeaOSLab/EdgeAdmin/internal/rpc"
+	"github.com/TeaOSLab/EdgeAdmin/internal/utils/rbacutils"
+	"github.com/TeaOSLab/EdgeCommon/pkg/rpc/pb"
+	"github.com/iwind/TeaGo/actions"
+	"github.com/iwind/TeaGo/lists"
+	"github.com/iwind/TeaGo/logs"
+	"github.com/iwind/TeaGo/types"
+)
+
+// 页面中通过POST加载数据的只读动作，除此之外的POST请求都需要读写权限
+var rbacReadPostPaths = []string{
+	"/admins/options",
+	"/clusters/options",
+	"/clusters/nodeOptions",
+	"/clusters/tasks/check",
+	"/clusters/cluster/installStatus",
+	"/clusters/cluster/upgradeStatus",
+	"/clusters/cluster/node/status",
+	"/clusters/cluster/node/settings/ddos-protection/status",
+	"/clusters/cluster/settings/services/status",
+	"/clusters/cluster/settings/ddos-protection/status",
+	"/db/status",
+	"/dns/domainOptions",
+	"/dns/providerOptions",
+	"/dns/tasks/check",
+	"/servers/status",
+	"/servers/users/options",
+	"/servers/headers/options",
+	"/servers/iplists/levelOptions",
+	"/servers/certs/acme/userOptions",
+	"/servers/server/settings/serverNames/check",
+	"/settings/api/node/upgradeCheck",
+	"/settings/database/migrate/status",
+}
+
+// 无法确定所属范围的对象参数，有资源范围限制的角色不能修改这些对象
+var rbacUnresolvedParams = []string{"locationId", "policyId", "firewallPolicyId", "httpFirewallPolicyId", "cachePolicyId", "reverseProxyId", "originId", "listId", "ipListId"}
+
+// 资源缓存，避免每次请求都需要查询节点和网站所属的集群
+const rbacResourceCacheTTL = 60 * time.Second
+
+type rbacResourceCacheItem struct {
+	object    *rbacutils.Object
+	expiresAt time.Time
+}
+
+var rbacResourceCache = map[string]*rbacResourceCacheItem{} // key => item
+var rbacResourceCacheLocker = &sync.Mutex{}
+
+// 检查角色中的读写权限和资源范围
+func (this *userMustAuth) allowRBAC(actionPtr actions.ActionWrapper, adminId int64) bool {
+	if this.module == configloaders.AdminModuleCodeCommon {
+		return true
+	}
+
+	var grant = configloaders.FindAdminGrant(adminId)
+	if grant == nil {
+		return true
+	}
+
+	var action = actionPtr.Object()
+	var permission = this.rbacPermission(actionPtr)
+	resource, err := this.rbacResource(action)
+	if err != nil {
+		logs.Println("[RBAC]find resource failed: " + err.Error())
+		return false
+	}
+	if grant.Allow(this.module, permission, resource) {
+		return true
+	}
+
+	// 有资源范围限制的角色访问列表页等不涉及具体对象的页面时，只能由动作自行按照范围过滤数据
+	if permission == rbacutils.PermissionRead && !resource.HasObjects() {
+		filterAction, ok := actionPtr.(rbacutils.ScopeFilterAction)
+		if ok {
+			var scope = grant.ReadScope(this.module)
+			if scope != nil {
+				filterAction.RBACFilterScope(scope)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 动作需要的权限
+func (this *userMustAuth) rbacPermission(actionPtr actions.ActionWrapper) string {
+	// 动作中声明的权限
+	permissionAction, ok := actionPtr.(rbacutils.PermissionAction)
+	if ok {
+		return permissionAction.RBACPermission()
+	}
+
+	var action = actionPtr.Object()
+	if action.Request.Method == http.MethodGet || action.Request.Method == http.MethodHead {
+		return rbacutils.PermissionRead
+	}
+	if lists.ContainsString(rbacReadPostPaths, action.Request.URL.Path) {
+		return rbacutils.PermissionRead
+	}
+	return rbacutils.PermissionWrite
+}
+
+// 从请求参数中分析当前请求涉及的资源
+// 每个对象都使用查询到的实际所属范围，不信任和对象一起传入的 clusterId 等参数
+func (this *userMustAuth) rbacResource(action *actions.ActionObject) (*rbacutils.Resource, error) {
+	var resource = &rbacutils.Resource{}
+
+	// 集群
+	for _, clusterId := range rbacParamIds(action, "clusterId") {
+		resource.AddObject(&rbacutils.Object{ClusterIds: []int64{clusterId}})
+	}
+
+	// 用户
+	for _, userId := range rbacParamIds(action, "userId") {
+		resource.AddObject(&rbacutils.Object{UserIds: []int64{userId}})
+	}
+
+	// 分组：网站分组可以直接判断，其他模块中的分组（比如节点分组）无法确定所属集群
+	var groupIds = rbacParamIds(action, "groupId")
+	if len(groupIds) > 0 {
+		if this.module == configloaders.AdminModuleCodeServer {
+			for _, groupId := range groupIds {
+				resource.AddObject(&rbacutils.Object{ServerGroupIds: []int64{groupId}})
+			}
+		} else {
+			resource.AddUnresolved("groupId")
+		}
+	}
+
+	// 节点
+	for _, nodeId := range rbacParamIds(action, "nodeId") {
+		object, err := this.findNodeObject(nodeId)
+		if err != nil {
+			return nil, err
+		}
+		resource.AddObject(object)
+	}
+
+	// 网站
+	var serverIds = rbacParamIds(action, "serverId")
+
+	// 网站Web配置
+	for _, webId := range rbacParamIds(action, "webId") {
+		serverId, err := this.findWebServerId(webId)
+		if err != nil {
+			return nil, err
+		}
+		if serverId <= 0 {
+			resource.AddUnresolved("webId")
+			continue
+		}
+		serverIds = append(serverIds, serverId)
+	}
+
+	for _, serverId := range serverIds {
+		object, err := this.findServerObject(serverId)
+		if err != nil {
+			return nil, err
+		}
+		resource.AddObject(object)
+	}
+
+	// 其他无法确定所属范围的对象
+	for _, param := range rbacUnresolvedParams {
+		if len(rbacParamIds(action, param)) > 0 {
+			resource.AddUnresolved(param)
+		}
+	}
+
+	return resource, nil
+}
+
+// 读取某个对象的ID参数，支持 xxxId、xxxIds、xxxIds[] 和 xxxIdsJSON 等形式
+func rbacParamIds(action *actions.ActionObject, name string) []int64 {
+	var result = []int64{}
+	var add = func(id int64) {
+		if id > 0 && !lists.ContainsInt64(result, id) {
+			result = append(result, id)
+		}
+	}
+
+	add(action.ParamInt64(name))
+	for _, arrayName := range []string{name + "s", name + "s[]"} {
+		for _, value := range action.ParamArray(arrayName) {
+			for _, piece := range strings.Split(value, ",") {
+				add(types.Int64(strings.TrimSpace(piece)))
+			}
+		}
+	}
+
+	var idsJSON = action.ParamString(name + "sJSON")
+	if len(idsJSON) > 0 {
+		var ids = []int64{}
+		if json.Unmarshal([]byte(idsJSON), &ids) == nil {
+			for _, id := range ids {
+				add(id)
+			}
+		}
+	}
+
+	return result
+}
+
+// 查询节点所属的集群，找不到节点时返回空对象，此时不会匹配任何资源范围
+func (this *userMustAuth) findNodeObject(nodeId int64) (*rbacutils.Object, error) {
+	return this.findCachedObject("node:"+strconv.FormatInt(nodeId, 10), func(rpcClient *rpc.RPCClient) (*rbacutils.Object, error) {
+		nodeResp, err := rpcClient.NodeRPC().FindEnabledNode(rpcClient.Context(0), &pb.FindEnabledNodeRequest{NodeId: nodeId})
+		if err != nil {
+			return nil, err
+		}
+		var object = &rbacutils.Object{}
+		var node = nodeResp.Node
+		if node != nil && node.NodeCluster != nil {
+			object.ClusterIds = append(object.ClusterIds, node.NodeCluster.Id)
+		}
+		return object, nil
+	})
+}
+
+// 查询网站所属的集群、分组和用户，找不到网站时返回空对象，此时不会匹配任何资源范围
+func (this *userMustAuth) findServerObject(serverId int64) (*rbacutils.Object, error) {
+	return this.findCachedObject("server:"+strconv.FormatInt(serverId, 10), func(rpcClient *rpc.RPCClient) (*rbacutils.Object, error) {
+		serverResp, err := rpcClient.ServerRPC().FindEnabledServer(rpcClient.Context(0), &pb.FindEnabledServerRequest{
+			ServerId:       serverId,
+			IgnoreSSLCerts: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var object = &rbacutils.Object{}
+		var server = serverResp.Server
+		if server != nil {
+			if server.NodeCluster != nil {
+				object.ClusterIds = append(object.ClusterIds, server.NodeCluster.Id)
+			}
+			for _, group := range server.ServerGroups {
+				object.ServerGroupIds = append(object.ServerGroupIds, group.Id)
+			}
+			if server.UserId > 0 {
+				object.UserIds = append(object.UserIds, server.UserId)
+			}
+		}
+		return object, nil
+	})
+}
+
+// 查询Web配置所属的网站
+func (this *userMustAuth) findWebServerId(webId int64) (int64, error) {
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return 0, err
+	}
+	serverIdResp, err := rpcClient.HTTPWebRPC().FindServerIdWithHTTPWebId(rpcClient.Context(0), &pb.FindServerIdWithHTTPWebIdRequest{HttpWebId: webId})
+	if err != nil {
+		return 0, err
+	}
+	return serverIdResp.ServerId, nil
+}
+
+func (this *userMustAuth) findCachedObject(key string, f func(rpcClient *rpc.RPCClient) (*rbacutils.Object, error)) (*rbacutils.Object, error) {
+	rbacResourceCacheLocker.Lock()
+	item, ok := rbacResourceCache[key]
+	rbacResourceCacheLocker.Unlock()
+	if ok && item.expiresAt.After(time.Now()) {
+		return item.object, nil
+	}
+
+	rpcClient, err := rpc.SharedRPC()
+	if err != nil {
+		return nil, err
+	}
+	object, err := f(rpcClient)
+	if err != nil {
+		return nil, err
+	}
+
+	rbacResourceCacheLocker.Lock()
+	var now = time.Now()
+	for cacheKey, cacheItem := range rbacResourceCache {
+		if cacheItem.expiresAt.Before(now) {
+			delete(rbacResourceCache, cacheKey)
+		}
+	}
+	rbacResourceCache[key] = &rbacResourceCacheItem{
+		object:    object,
+		expiresAt: now.Add(rbacResourceCacheTTL),
+	}
+	rbacResourceCacheLocker.Unlock()
+
+	return object, nil
+}
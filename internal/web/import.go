@@ -19,9 +19,11 @@ import (
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/grants"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/logs"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/regions"
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/runbooks"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/clusters/tasks"
 
 	// 通用
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/changes"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/csrf"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/dashboard"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/db"
@@ -33,6 +35,7 @@ import (
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/logout"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/messages"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/nodes"
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/provision"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/ui"
 
 	// 服务相关
@@ -51,6 +54,7 @@ import (
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/logs"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/metrics"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/metrics/charts"
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/reports"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/delete"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/log"
@@ -59,6 +63,7 @@ import (
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/accessLog"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/cache"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/charset"
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/clone"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/common"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/compression"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/conds"
@@ -107,6 +112,7 @@ import (
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/web"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/webp"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/websocket"
+	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/settings/yaml"
 	_ "github.com/TeaOSLab/EdgeAdmin/internal/web/actions/default/servers/server/stat"
 
 	// IP相关
@@ -222,13 +222,28 @@ Tea.context(function () {
 	}
 });
 
+// 操作需要审批时提示已提交变更申请
+window.NotifyChangeRequest = function (resp) {
+	if (resp == null || resp.data == null || resp.data.changeRequestId == null || resp.data.changeRequestId <= 0) {
+		return false;
+	}
+	let requestId = resp.data.changeRequestId;
+	teaweb.success("此操作需要审批，已提交变更申请，等待其他管理员审批后执行", function () {
+		window.location = "/changes/request?requestId=" + requestId;
+	});
+	return true;
+};
+
 window.NotifySuccess = function (message, url, params) {
 	if (typeof (url) == "string" && url.length > 0) {
 		if (url[0] != "/") {
 			url = Tea.url(url, params);
 		}
 	}
-	return function () {
+	return function (resp) {
+		if (NotifyChangeRequest(resp)) {
+			return;
+		}
 		teaweb.success(message, function () {
 			window.location = url;
 		});
@@ -236,7 +251,10 @@ window.NotifySuccess = function (message, url, params) {
 };
 
 window.NotifyReloadSuccess = function (message) {
-	return function () {
+	return function (resp) {
+		if (NotifyChangeRequest(resp)) {
+			return;
+		}
 		teaweb.success(message, function () {
 			window.location.reload()
 		})
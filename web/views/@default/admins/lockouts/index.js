@@ -0,0 +1,13 @@
+Tea.context(function () {
+	this.clearLockout = function (lockout) {
+		let that = this
+		teaweb.confirm("确定要解除对“" + lockout.value + "”的锁定吗？", function () {
+			that.$post("/admins/lockouts/clear")
+				.params({
+					kind: lockout.kind,
+					value: lockout.value
+				})
+				.refresh()
+		})
+	}
+})
@@ -0,0 +1,64 @@
+Tea.context(function () {
+	this.createRole = function () {
+		teaweb.popup(Tea.url(".createPopup"), {
+			width: "50em",
+			height: "36em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.updateRole = function (roleId) {
+		teaweb.popup(Tea.url(".updatePopup?roleId=" + roleId), {
+			width: "50em",
+			height: "36em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.deleteRole = function (roleId) {
+		let that = this
+		teaweb.confirm("确定要删除此角色吗？删除后分配了此角色的管理员将失去相应的权限。", function () {
+			that.$post(".delete")
+				.params({
+					roleId: roleId
+				})
+				.refresh()
+		})
+	}
+
+	this.moduleName = function (code) {
+		let module = this.modules.$find(function (k, v) {
+			return v.code == code
+		})
+		return (module != null) ? module.name : code
+	}
+
+	this.clusterName = function (clusterId) {
+		let cluster = this.clusters.$find(function (k, v) {
+			return v.id == clusterId
+		})
+		return (cluster != null) ? cluster.name : ("#" + clusterId)
+	}
+
+	this.serverGroupName = function (groupId) {
+		let group = this.serverGroups.$find(function (k, v) {
+			return v.id == groupId
+		})
+		return (group != null) ? group.name : ("#" + groupId)
+	}
+
+	this.adminName = function (adminId) {
+		let admin = this.admins.$find(function (k, v) {
+			return v.id == adminId
+		})
+		return (admin != null) ? admin.fullname : ("#" + adminId)
+	}
+})
@@ -0,0 +1,38 @@
+Tea.context(function () {
+	if (typeof this.role == "undefined") {
+		this.role = null
+	}
+
+	this.name = (this.role != null) ? this.role.name : ""
+	this.description = (this.role != null) ? this.role.description : ""
+
+	let that = this
+	this.moduleItems = this.modules.map(function (module) {
+		let permission = ""
+		if (that.role != null) {
+			that.role.modules.forEach(function (m) {
+				if (m.code == module.code) {
+					permission = m.permission
+				}
+			})
+		}
+		return {
+			code: module.code,
+			name: module.name,
+			permission: permission
+		}
+	})
+
+	this.modulesValue = function () {
+		return this.moduleItems
+			.filter(function (module) {
+				return module.permission.length > 0
+			})
+			.map(function (module) {
+				return {
+					code: module.code,
+					permission: module.permission
+				}
+			})
+	}
+})
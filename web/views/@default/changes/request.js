@@ -0,0 +1,56 @@
+Tea.context(function () {
+	this.reason = ""
+
+	this.approve = function () {
+		let that = this
+		teaweb.confirm("确定要批准此变更申请吗？批准后将立即以申请人的身份执行。", function () {
+			that.$post(".approve")
+				.params({
+					requestId: that.request.id,
+					reason: that.reason
+				})
+				.timeout(60)
+				.success(function () {
+					teaweb.success("已批准并执行", function () {
+						teaweb.reload()
+					})
+				})
+				.fail(function (resp) {
+					teaweb.warn(resp.message, function () {
+						teaweb.reload()
+					})
+				})
+		})
+	}
+
+	this.reject = function () {
+		if (this.reason.length == 0) {
+			teaweb.warn("请输入拒绝的原因")
+			return
+		}
+		let that = this
+		teaweb.confirm("确定要拒绝此变更申请吗？", function () {
+			that.$post(".reject")
+				.params({
+					requestId: that.request.id,
+					reason: that.reason
+				})
+				.success(function () {
+					teaweb.success("已拒绝", function () {
+						teaweb.reload()
+					})
+				})
+		})
+	}
+
+	this.cancel = function () {
+		let that = this
+		teaweb.confirm("确定要撤回此变更申请吗？", function () {
+			that.$post(".cancel")
+				.params({
+					requestId: that.request.id
+				})
+				.refresh()
+		})
+	}
+})
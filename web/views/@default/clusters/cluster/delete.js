@@ -6,7 +6,10 @@ Tea.context(function () {
                 .params({
                     clusterId: clusterId
                 })
-                .success(function () {
+                .success(function (resp) {
+                    if (NotifyChangeRequest(resp)) {
+                        return
+                    }
                     teaweb.success("删除成功", function () {
                         window.location = "/clusters"
                     })
@@ -0,0 +1,26 @@
+Tea.context(function () {
+	this.success = function () {
+		teaweb.success("节点已进入维护模式，正在等待连接排空", function () {
+			teaweb.reload()
+		})
+	}
+
+	if (this.maintenance != null && this.maintenance.status == "draining") {
+		this.$delay(function () {
+			teaweb.reload()
+		}, 10000)
+	}
+
+	this.exitMaintenance = function (force) {
+		let that = this
+		let message = force ? "确定要强制退出维护模式吗？将不再检查节点是否在线。" : "确定要退出维护模式吗？节点将重新上线并加入DNS。"
+		teaweb.confirm(message, function () {
+			that.$post("/clusters/cluster/node/maintenance/exit")
+				.params({
+					nodeId: that.node.id,
+					force: force ? 1 : 0
+				})
+				.refresh()
+		})
+	}
+})
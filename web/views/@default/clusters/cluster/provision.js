@@ -0,0 +1,24 @@
+Tea.context(function () {
+	this.result = null
+	this.resultTab = "cloudInit"
+
+	this.success = function (resp) {
+		this.result = resp.data
+	}
+
+	this.reload = function () {
+		teaweb.reload()
+	}
+
+	this.deleteToken = function (tokenId) {
+		let that = this
+		teaweb.confirm("确定要删除此注册令牌吗？删除后使用此令牌的云主机将无法注册。", function () {
+			that.$post("/clusters/cluster/provision/delete")
+				.params({
+					clusterId: that.clusterId,
+					tokenId: tokenId
+				})
+				.refresh()
+		})
+	}
+})
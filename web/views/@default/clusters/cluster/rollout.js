@@ -0,0 +1,8 @@
+Tea.context(function () {
+	this.success = function (resp) {
+		let that = this
+		teaweb.success("升级计划已开始执行", function () {
+			window.location = "/clusters/cluster/rollout/detail?clusterId=" + that.clusterId + "&rolloutId=" + resp.data.rolloutId
+		})
+	}
+})
@@ -0,0 +1,74 @@
+Tea.context(function () {
+	this.$delay(function () {
+		this.refresh()
+	}, 5000)
+
+	this.refresh = function () {
+		if (this.rollout.status != "running") {
+			return
+		}
+		this.$post("/clusters/cluster/rollout/detail")
+			.params({
+				clusterId: this.clusterId,
+				rolloutId: this.rollout.id
+			})
+			.success(function (resp) {
+				this.rollout = resp.data.rollout
+			})
+			.done(function () {
+				this.$delay(function () {
+					this.refresh()
+				}, 5000)
+			})
+	}
+
+	this.findNodeName = function (nodeId) {
+		for (let i = 0; i < this.rollout.batches.length; i++) {
+			let node = this.rollout.batches[i].nodes.$find(function (k, v) {
+				return v.id == nodeId
+			})
+			if (node != null) {
+				return node.name
+			}
+		}
+		return nodeId
+	}
+
+	this.haltRollout = function () {
+		let that = this
+		teaweb.confirm("确定要暂停升级吗？正在升级的节点会继续完成。", function () {
+			that.$post("/clusters/cluster/rollout/halt")
+				.params({
+					clusterId: that.clusterId,
+					rolloutId: that.rollout.id
+				})
+				.refresh()
+		})
+	}
+
+	this.resumeRollout = function (skipFailed) {
+		let that = this
+		let message = skipFailed ? "确定要跳过失败的节点并继续升级吗？" : "确定要重新升级失败的节点并继续吗？"
+		teaweb.confirm(message, function () {
+			that.$post("/clusters/cluster/rollout/resume")
+				.params({
+					clusterId: that.clusterId,
+					rolloutId: that.rollout.id,
+					skipFailed: skipFailed ? 1 : 0
+				})
+				.refresh()
+		})
+	}
+
+	this.cancelRollout = function () {
+		let that = this
+		teaweb.confirm("确定要取消此升级计划吗？已经升级的节点不会回退。", function () {
+			that.$post("/clusters/cluster/rollout/cancel")
+				.params({
+					clusterId: that.clusterId,
+					rolloutId: that.rollout.id
+				})
+				.refresh()
+		})
+	}
+})
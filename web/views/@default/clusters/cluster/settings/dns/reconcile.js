@@ -0,0 +1,40 @@
+Tea.context(function () {
+	this.clusterId = this.cluster.id
+	this.filterStatus = "problem"
+	this.selectedItems = []
+
+	this.repairItem = function (result) {
+		return result.kind + ":" + result.targetId + ":" + result.ipAddrId
+	}
+
+	this.selectAll = function (b) {
+		let that = this
+		if (b) {
+			this.selectedItems = this.results
+				.filter(function (result) {
+					return result.status != "ok"
+				})
+				.map(function (result) {
+					return that.repairItem(result)
+				})
+		} else {
+			this.selectedItems = []
+		}
+	}
+
+	this.repair = function () {
+		let that = this
+		teaweb.confirm("确定要修复选中的解析记录吗？", function () {
+			that.$post("/clusters/cluster/settings/dns/reconcile/repair")
+				.params({
+					clusterId: that.clusterId,
+					items: that.selectedItems
+				})
+				.success(function () {
+					teaweb.success("已提交修复，DNS任务执行完成后可以重新核对", function () {
+						teaweb.reload()
+					})
+				})
+		})
+	}
+})
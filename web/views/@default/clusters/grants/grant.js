@@ -0,0 +1,23 @@
+Tea.context(function () {
+	this.sealGrant = function () {
+		let that = this
+		teaweb.confirm("确定要加密保存此认证吗？加密后API节点将无法使用此认证远程安装或升级节点。", function () {
+			that.$post(".seal")
+				.params({
+					grantId: that.grant.id
+				})
+				.refresh()
+		})
+	}
+
+	this.unsealGrant = function () {
+		let that = this
+		teaweb.confirm("确定要解密此认证吗？解密后认证信息将以明文保存。", function () {
+			that.$post(".unseal")
+				.params({
+					grantId: that.grant.id
+				})
+				.refresh()
+		})
+	}
+})
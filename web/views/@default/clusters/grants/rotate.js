@@ -0,0 +1,86 @@
+Tea.context(function () {
+	this.result = null
+	this.isRotating = false
+
+	this.rotate = function () {
+		let that = this
+		teaweb.confirm("确定要轮换此认证的密钥吗？轮换过程中请不要关闭页面。", function () {
+			that.isRotating = true
+			that.result = null
+			that.$post("$")
+				.params({
+					grantId: that.grant.id
+				})
+				.timeout(600)
+				.success(function (resp) {
+					that.result = resp.data.result
+					if (that.result.isOk) {
+						teaweb.successToast("轮换成功")
+					}
+				})
+				.done(function () {
+					that.isRotating = false
+				})
+		})
+	}
+
+	this.findNodeResult = function (nodeId, field) {
+		if (this.result == null) {
+			return null
+		}
+		let nodeResult = this.result.nodes.$find(function (k, v) {
+			return v.target.nodeId == nodeId
+		})
+		if (nodeResult == null) {
+			return null
+		}
+		return nodeResult[field]
+	}
+
+	// 主机公钥改变后重新记录
+	this.forgetHostKey = function (node) {
+		let that = this
+		teaweb.confirm("确定节点\"" + node.nodeName + "\"已经重装或者更换了SSH主机公钥吗？删除旧记录后下次连接时会记录新的主机公钥。", function () {
+			that.$post(".forgetHostKey")
+				.params({
+					host: node.host,
+					port: node.port
+				})
+				.success(function () {
+					teaweb.successToast("已删除旧记录")
+				})
+		})
+	}
+
+	// 检查旧认证
+	this.oldResults = null
+	this.isCheckingOld = false
+	this.checkOld = function () {
+		this.isCheckingOld = true
+		this.$post(".oldCredentials")
+			.params({
+				grantId: this.grant.id
+			})
+			.timeout(600)
+			.success(function (resp) {
+				this.oldResults = resp.data.results
+				if (resp.data.countAccepted > 0) {
+					teaweb.warn("有" + resp.data.countAccepted + "个节点仍然接受旧认证")
+				} else {
+					teaweb.successToast("没有节点接受旧认证")
+				}
+			})
+			.done(function () {
+				this.isCheckingOld = false
+			})
+	}
+
+	this.findOldResult = function (nodeId) {
+		if (this.oldResults == null) {
+			return null
+		}
+		return this.oldResults.$find(function (k, v) {
+			return v.target.nodeId == nodeId
+		})
+	}
+})
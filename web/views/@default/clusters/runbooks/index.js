@@ -0,0 +1,25 @@
+Tea.context(function () {
+	this.runRunbook = function (runbookId) {
+		let that = this
+		teaweb.confirm("确定要立即在目标节点上执行此运行手册吗？", function () {
+			that.$post("/clusters/runbooks/run")
+				.params({
+					runbookId: runbookId
+				})
+				.success(function (resp) {
+					window.location = "/clusters/runbooks/runDetail?runId=" + resp.data.runId
+				})
+		})
+	}
+
+	this.deleteRunbook = function (runbookId) {
+		let that = this
+		teaweb.confirm("确定要删除此运行手册吗？执行记录也会一并删除。", function () {
+			that.$post("/clusters/runbooks/delete")
+				.params({
+					runbookId: runbookId
+				})
+				.refresh()
+		})
+	}
+})
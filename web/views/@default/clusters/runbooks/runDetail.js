@@ -0,0 +1,37 @@
+Tea.context(function () {
+	this.$delay(function () {
+		this.reload()
+	}, 3000)
+
+	this.reload = function () {
+		if (!this.run.isRunning) {
+			return
+		}
+		this.$post("/clusters/runbooks/runDetail")
+			.params({
+				runId: this.run.id
+			})
+			.success(function (resp) {
+				this.run = resp.data.run
+			})
+			.done(function () {
+				this.$delay(function () {
+					this.reload()
+				}, 3000)
+			})
+	}
+
+	this.rerun = function () {
+		let that = this
+		teaweb.confirm("确定要在相关节点上重新执行失败和跳过的步骤吗？", function () {
+			that.$post("/clusters/runbooks/rerun")
+				.params({
+					runId: that.run.id
+				})
+				.success(function () {
+					that.run.isRunning = true
+					that.reload()
+				})
+		})
+	}
+})
@@ -0,0 +1,14 @@
+Tea.context(function () {
+	this.runRunbook = function () {
+		let that = this
+		teaweb.confirm("确定要立即在目标节点上执行此运行手册吗？", function () {
+			that.$post("/clusters/runbooks/run")
+				.params({
+					runbookId: that.runbook.id
+				})
+				.success(function (resp) {
+					window.location = "/clusters/runbooks/runDetail?runId=" + resp.data.runId
+				})
+		})
+	}
+})
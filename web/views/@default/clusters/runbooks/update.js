@@ -0,0 +1,52 @@
+Tea.context(function () {
+	this.form = {
+		name: this.runbook.name,
+		description: this.runbook.description,
+		steps: this.runbook.steps,
+		target: this.runbook.target,
+		schedule: this.runbook.schedule,
+		isOn: this.runbook.isOn
+	}
+
+	this.findStepDefinition = function (code) {
+		let def = this.stepDefinitions.$find(function (k, v) {
+			return v.code == code
+		})
+		if (def == null) {
+			return {}
+		}
+		return def
+	}
+
+	this.findClusterGroups = function (clusterId) {
+		let cluster = this.clusters.$find(function (k, v) {
+			return v.id == clusterId
+		})
+		if (cluster == null) {
+			return []
+		}
+		return cluster.groups
+	}
+
+	this.addStep = function () {
+		this.form.steps.push({
+			code: this.stepDefinitions[0].code,
+			cachePolicyId: 0,
+			firewallName: "",
+			timeoutSeconds: 10,
+			continueOnError: false
+		})
+	}
+
+	this.removeStep = function (index) {
+		this.form.steps.$remove(index)
+	}
+
+	this.moveStepUp = function (index) {
+		let step = this.form.steps[index]
+		this.form.steps.$remove(index)
+		this.form.steps.splice(index - 1, 0, step)
+	}
+
+	this.success = NotifySuccess("保存成功", "/clusters/runbooks/runbook?runbookId=" + this.runbook.id)
+})
@@ -0,0 +1,363 @@
+Tea.context(function () {
+	this.isLoading = false
+	this.data = null
+	this.dataVersion = 0
+	this.updatedTime = ""
+	this.refreshSecondsValue = this.board.refreshSeconds
+
+	let refreshTimer = null
+
+	this.$delay(function () {
+		this.reload()
+		this.startRefresh()
+	})
+
+	this.timeRangeName = function () {
+		let that = this
+		let name = ""
+		this.timeRanges.forEach(function (r) {
+			if (r.code == that.timeRange) {
+				name = r.name
+			}
+		})
+		return name
+	}
+
+	this.selectTimeRange = function (timeRange) {
+		this.timeRange = timeRange
+		this.reload()
+	}
+
+	this.formatRefresh = function (seconds) {
+		if (seconds == 0) {
+			return "不自动刷新"
+		}
+		if (seconds < 60) {
+			return "每" + seconds + "秒刷新"
+		}
+		return "每" + (seconds / 60) + "分钟刷新"
+	}
+
+	this.changeRefresh = function () {
+		this.startRefresh()
+	}
+
+	this.startRefresh = function () {
+		if (refreshTimer != null) {
+			clearInterval(refreshTimer)
+			refreshTimer = null
+		}
+		let seconds = parseInt(this.refreshSecondsValue)
+		if (seconds > 0) {
+			let that = this
+			refreshTimer = setInterval(function () {
+				that.reload()
+			}, seconds * 1000)
+		}
+	}
+
+	this.reload = function () {
+		if (this.isLoading) {
+			return
+		}
+		this.isLoading = true
+		this.$post(".data")
+			.params({
+				boardId: this.board.id,
+				timeRange: this.timeRange
+			})
+			.success(function (resp) {
+				this.data = resp.data
+				this.dataVersion++
+				this.updatedTime = new Date().toLocaleTimeString()
+				this.$delay(function () {
+					this.renderCharts()
+				})
+			})
+			.done(function () {
+				this.isLoading = false
+			})
+	}
+
+	this.formatBytes = function (bytes) {
+		return teaweb.formatBytes(bytes)
+	}
+
+	this.formatCount = function (count) {
+		return teaweb.formatCount(count)
+	}
+
+	this.ratio = function (value, total) {
+		if (total <= 0) {
+			return 0
+		}
+		return Math.round(value * 10000 / total) / 100
+	}
+
+	this.healthColor = function (cluster) {
+		let ratio = this.ratio(cluster.countActiveNodes, cluster.countNodes)
+		if (cluster.countNodes == 0 || ratio >= 100) {
+			return "green"
+		}
+		if (ratio >= 80) {
+			return "yellow"
+		}
+		return "red"
+	}
+
+	/**
+	 * 图表
+	 */
+	this.renderCharts = function () {
+		let that = this
+		this.board.widgets.forEach(function (widget) {
+			let chartId = "widget-chart-" + widget.id
+			switch (widget.kind) {
+				case "trafficTrend":
+					that.renderTrafficChart(chartId)
+					break
+				case "requestTrend":
+					that.renderRequestChart(chartId)
+					break
+				case "bandwidthTrend":
+					that.renderBandwidthChart(chartId)
+					break
+				case "wafTrend":
+					that.renderWAFChart(chartId)
+					break
+				case "topDomains":
+					that.renderTopDomainsChart(chartId)
+					break
+			}
+		})
+	}
+
+	this.statLabel = function (stat) {
+		if (stat.hour.length > 0) {
+			return stat.day + " " + stat.hour + "时"
+		}
+		return stat.day
+	}
+
+	this.renderTrafficChart = function (chartId) {
+		let stats = this.data.stats
+		let that = this
+		let axis = teaweb.bytesAxis(stats, function (v) {
+			return v.bytes
+		})
+		this.renderLines(chartId, axis, [
+			{name: "总流量", field: "bytes", color: teaweb.DefaultChartColor},
+			{name: "缓存流量", field: "cachedBytes", color: "#61A0A8"},
+			{name: "攻击流量", field: "attackBytes", color: "#F39494"}
+		], function (args) {
+			let stat = stats[args.dataIndex]
+			return that.statLabel(stat) + "<br/>总流量：" + teaweb.formatBytes(stat.bytes) + "<br/>缓存流量：" + teaweb.formatBytes(stat.cachedBytes) + "<br/>缓存命中率：" + that.ratio(stat.cachedBytes, stat.bytes) + "%<br/>攻击流量：" + teaweb.formatBytes(stat.attackBytes)
+		})
+	}
+
+	this.renderRequestChart = function (chartId) {
+		let stats = this.data.stats
+		let that = this
+		let axis = teaweb.countAxis(stats, function (v) {
+			return v.countRequests
+		})
+		this.renderLines(chartId, axis, [
+			{name: "总请求数", field: "countRequests", color: teaweb.DefaultChartColor},
+			{name: "缓存请求数", field: "countCachedRequests", color: "#61A0A8"}
+		], function (args) {
+			let stat = stats[args.dataIndex]
+			return that.statLabel(stat) + "<br/>总请求数：" + teaweb.formatNumber(stat.countRequests) + "<br/>缓存请求数：" + teaweb.formatNumber(stat.countCachedRequests) + "<br/>缓存命中率：" + that.ratio(stat.countCachedRequests, stat.countRequests) + "%"
+		})
+	}
+
+	this.renderBandwidthChart = function (chartId) {
+		let stats = this.data.stats
+		let that = this
+		let axis = teaweb.bitsAxis(stats, function (v) {
+			return v.bandwidthBits
+		})
+		teaweb.renderLineChart({
+			id: chartId,
+			name: "平均带宽",
+			values: stats,
+			x: function (v) {
+				return v.label
+			},
+			tooltip: function (args, stats) {
+				let stat = stats[args.dataIndex]
+				return that.statLabel(stat) + "<br/>平均带宽：" + teaweb.formatBits(stat.bandwidthBits)
+			},
+			value: function (v) {
+				return v.bandwidthBits / axis.divider
+			},
+			axis: axis,
+			left: 10
+		})
+	}
+
+	this.renderWAFChart = function (chartId) {
+		let stats = this.data.stats
+		let that = this
+		let axis = teaweb.countAxis(stats, function (v) {
+			return v.countAttackRequests
+		})
+		teaweb.renderBarChart({
+			id: chartId,
+			name: "拦截请求数",
+			values: stats,
+			x: function (v) {
+				return v.label
+			},
+			tooltip: function (args, stats) {
+				let stat = stats[args.dataIndex]
+				return that.statLabel(stat) + "<br/>拦截请求数：" + teaweb.formatNumber(stat.countAttackRequests) + "<br/>拦截流量：" + teaweb.formatBytes(stat.attackBytes)
+			},
+			value: function (v) {
+				return v.countAttackRequests / axis.divider
+			},
+			axis: axis
+		})
+	}
+
+	this.renderTopDomainsChart = function (chartId) {
+		let axis = teaweb.countAxis(this.data.topDomainStats, function (v) {
+			return v.countRequests
+		})
+		teaweb.renderBarChart({
+			id: chartId,
+			name: "域名",
+			values: this.data.topDomainStats,
+			x: function (v) {
+				return v.domain
+			},
+			tooltip: function (args, stats) {
+				return stats[args.dataIndex].domain + "<br/>请求数：" + teaweb.formatNumber(stats[args.dataIndex].countRequests) + "<br/>流量：" + teaweb.formatBytes(stats[args.dataIndex].bytes)
+			},
+			value: function (v) {
+				return v.countRequests / axis.divider
+			},
+			axis: axis,
+			click: function (args, stats) {
+				window.location = "/servers/server?serverId=" + stats[args.dataIndex].serverId
+			}
+		})
+	}
+
+	this.renderLines = function (chartId, axis, lines, tooltipFunc) {
+		let chartBox = document.getElementById(chartId)
+		if (chartBox == null) {
+			return
+		}
+		let stats = this.data.stats
+		let chart = teaweb.initChart(chartBox)
+		chart.setOption({
+			xAxis: {
+				data: stats.map(function (v) {
+					return v.label
+				})
+			},
+			yAxis: {
+				axisLabel: {
+					formatter: function (v) {
+						return v + axis.unit
+					}
+				}
+			},
+			tooltip: {
+				show: true,
+				trigger: "item",
+				formatter: tooltipFunc
+			},
+			grid: {
+				left: 50,
+				top: 40,
+				right: 20,
+				bottom: 20
+			},
+			series: lines.map(function (line) {
+				return {
+					name: line.name,
+					type: "line",
+					data: stats.map(function (v) {
+						return v[line.field] / axis.divider
+					}),
+					itemStyle: {
+						color: line.color
+					},
+					lineStyle: {
+						color: line.color
+					},
+					areaStyle: {
+						color: line.color
+					},
+					smooth: true
+				}
+			}),
+			legend: {
+				data: lines.map(function (line) {
+					return line.name
+				})
+			},
+			animation: false
+		})
+		chart.resize()
+	}
+
+	/**
+	 * 看板和组件操作
+	 */
+	this.updateBoard = function () {
+		teaweb.popup("/dashboard/custom/updatePopup?boardId=" + this.board.id, {
+			height: "26em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.addWidget = function () {
+		teaweb.popup("/dashboard/custom/widgetPopup?boardId=" + this.board.id, {
+			height: "26em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.updateWidget = function (widgetId) {
+		teaweb.popup("/dashboard/custom/widgetPopup?boardId=" + this.board.id + "&widgetId=" + widgetId, {
+			height: "26em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.deleteWidget = function (widgetId) {
+		let that = this
+		teaweb.confirm("确定要从看板中删除此组件吗？", function () {
+			that.$post(".widgetDelete")
+				.params({
+					boardId: that.board.id,
+					widgetId: widgetId
+				})
+				.refresh()
+		})
+	}
+
+	this.moveWidget = function (widgetId, delta) {
+		this.$post(".widgetMove")
+			.params({
+				boardId: this.board.id,
+				widgetId: widgetId,
+				delta: delta
+			})
+			.refresh()
+	}
+})
@@ -0,0 +1,34 @@
+Tea.context(function () {
+	this.createBoard = function () {
+		teaweb.popup("/dashboard/custom/createPopup", {
+			height: "26em",
+			callback: function (resp) {
+				teaweb.success("保存成功", function () {
+					window.location = "/dashboard/custom/board?boardId=" + resp.data.boardId
+				})
+			}
+		})
+	}
+
+	this.updateBoard = function (boardId) {
+		teaweb.popup("/dashboard/custom/updatePopup?boardId=" + boardId, {
+			height: "26em",
+			callback: function () {
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.deleteBoard = function (boardId) {
+		let that = this
+		teaweb.confirm("确定要删除此看板吗？", function () {
+			that.$post(".delete")
+				.params({
+					boardId: boardId
+				})
+				.refresh()
+		})
+	}
+})
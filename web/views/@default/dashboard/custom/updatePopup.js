@@ -0,0 +1,35 @@
+Tea.context(function () {
+	if (typeof this.board == "undefined") {
+		this.board = null
+	}
+
+	if (this.board != null) {
+		this.form = {
+			name: this.board.name,
+			timeRange: this.board.timeRange,
+			refreshSeconds: this.board.refreshSeconds,
+			isShared: this.board.isShared,
+			shareScope: this.board.shareScope
+		}
+	} else {
+		this.form = {
+			name: "",
+			timeRange: "24h",
+			refreshSeconds: 0,
+			isShared: false,
+			shareScope: "all"
+		}
+	}
+
+	this.formatRefresh = function (seconds) {
+		if (seconds == 0) {
+			return "不自动刷新"
+		}
+		if (seconds < 60) {
+			return "每" + seconds + "秒"
+		}
+		return "每" + (seconds / 60) + "分钟"
+	}
+
+	this.success = NotifyPopup
+})
@@ -0,0 +1,20 @@
+Tea.context(function () {
+	this.definition = null
+
+	this.changeKind = function () {
+		let that = this
+		this.definition = this.definitions.$find(function (k, v) {
+			return v.kind == that.widget.kind
+		})
+		if (this.widget.id.length == 0 && this.definition != null) {
+			this.widget.width = this.definition.defaultWidth
+		}
+	}
+
+	if (this.widget.width == 0) {
+		this.widget.width = 2
+	}
+	this.changeKind()
+
+	this.success = NotifyPopup
+})
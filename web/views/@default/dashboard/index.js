@@ -5,6 +5,7 @@ Tea.context(function () {
 	this.dashboard = {}
 	this.localLowerVersionAPINode = null
 	this.countWeakAdmins = 0
+	this.dbDiskWarningDays = -1
 	this.todayCountIPsFormat = "0"
 
 	this.$delay(function () {
@@ -17,4 +17,38 @@ Tea.context(function () {
 
 	// DNSPod
 	this.paramDNSPodAPIType = "tencentDNS"
-})
\ No newline at end of file
+
+	// RFC 2136
+	this.rfc2136 = {
+		server: "",
+		zone: "",
+		network: "udp",
+		tsigKeyName: "",
+		tsigAlgorithm: "hmac-sha256",
+		tsigSecret: ""
+	}
+
+	this.isTesting = false
+	this.testResult = null
+	this.testRFC2136 = function () {
+		this.isTesting = true
+		this.testResult = null
+		this.$post(".test")
+			.params({
+				providerId: 0,
+				type: "rfc2136",
+				paramRFC2136Server: this.rfc2136.server,
+				paramRFC2136Zone: this.rfc2136.zone,
+				paramRFC2136Network: this.rfc2136.network,
+				paramRFC2136TSIGKeyName: this.rfc2136.tsigKeyName,
+				paramRFC2136TSIGAlgorithm: this.rfc2136.tsigAlgorithm,
+				paramRFC2136TSIGSecret: this.rfc2136.tsigSecret
+			})
+			.success(function (resp) {
+				this.testResult = resp.data
+			})
+			.done(function () {
+				this.isTesting = false
+			})
+	}
+})
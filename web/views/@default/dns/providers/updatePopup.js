@@ -12,4 +12,38 @@ Tea.context(function () {
 	if (this.provider.type == "dnspod" && this.provider.params != null && (this.provider.params.apiType == null || this.provider.params.apiType.length == 0)) {
 		this.provider.params.apiType = "dnsPodToken"
 	}
-})
\ No newline at end of file
+
+	// RFC 2136
+	if (this.provider.type == "rfc2136" && this.provider.params != null) {
+		if (this.provider.params.network == null || this.provider.params.network.length == 0) {
+			this.provider.params.network = "udp"
+		}
+		if (this.provider.params.tsigAlgorithm == null || this.provider.params.tsigAlgorithm.length == 0) {
+			this.provider.params.tsigAlgorithm = "hmac-sha256"
+		}
+	}
+
+	this.isTesting = false
+	this.testResult = null
+	this.testRFC2136 = function () {
+		this.isTesting = true
+		this.testResult = null
+		this.$post(".test")
+			.params({
+				providerId: this.provider.id,
+				type: "rfc2136",
+				paramRFC2136Server: this.provider.params.server,
+				paramRFC2136Zone: this.provider.params.zone,
+				paramRFC2136Network: this.provider.params.network,
+				paramRFC2136TSIGKeyName: this.provider.params.tsigKeyName,
+				paramRFC2136TSIGAlgorithm: this.provider.params.tsigAlgorithm,
+				paramRFC2136TSIGSecret: this.provider.params.tsigSecret
+			})
+			.success(function (resp) {
+				this.testResult = resp.data
+			})
+			.done(function () {
+				this.isTesting = false
+			})
+	}
+})
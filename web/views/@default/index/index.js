@@ -38,6 +38,27 @@ Tea.context(function () {
 		this.isSubmitting = false;
 	};
 
+	this.submitFail = function (resp) {
+		// 需要输入验证码
+		if (resp.data != null && resp.data.captchaId != null && resp.data.captchaId.length > 0) {
+			this.captchaId = resp.data.captchaId
+			this.captchaCode = ""
+		}
+		teaweb.warn(resp.message)
+	};
+
+	this.captchaCode = ""
+	this.refreshCaptcha = function () {
+		this.$post("/index/captcha")
+			.params({
+				id: this.captchaId
+			})
+			.success(function (resp) {
+				this.captchaId = resp.data.captchaId
+				this.captchaCode = ""
+			})
+	};
+
 	this.submitSuccess = function (resp) {
 		// store information to local
 		localStorage.setItem("sid", resp.data.localSid)
@@ -45,6 +66,10 @@ Tea.context(function () {
 
 		// redirect back
 		this.$delay(function () {
+			if (resp.data.requireWebAuthn) {
+				window.location = "/index/webauthn?token=" + resp.data.webAuthnToken + "&from=" + window.encodeURIComponent(this.from)
+				return
+			}
 			if (resp.data.requireOTP) {
 				window.location = "/index/otp?sid=" + resp.data.sid + "&remember=" + (resp.data.remember ? 1 : 0) + "&from=" + window.encodeURIComponent(this.from)
 				return
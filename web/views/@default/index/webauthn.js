@@ -0,0 +1,133 @@
+Tea.context(function () {
+	this.isSubmitting = false
+	this.isSupported = (window.PublicKeyCredential != null && navigator.credentials != null)
+	this.recoveryVisible = false
+	this.recoveryCode = ""
+
+	this.encodedFrom = window.encodeURIComponent(this.from)
+
+	this.$delay(function () {
+		if (this.isSupported && !this.passwordless) {
+			this.verify()
+		}
+	})
+
+	// base64url和ArrayBuffer之间转换
+	let decodeBase64URL = function (s) {
+		s = s.replace(/-/g, "+").replace(/_/g, "/")
+		while (s.length % 4 != 0) {
+			s += "="
+		}
+		let raw = window.atob(s)
+		let bytes = new Uint8Array(raw.length)
+		for (let i = 0; i < raw.length; i++) {
+			bytes[i] = raw.charCodeAt(i)
+		}
+		return bytes.buffer
+	}
+	let encodeBase64URL = function (buffer) {
+		let bytes = new Uint8Array(buffer)
+		let raw = ""
+		for (let i = 0; i < bytes.length; i++) {
+			raw += String.fromCharCode(bytes[i])
+		}
+		return window.btoa(raw).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "")
+	}
+
+	this.verify = function () {
+		if (!this.isSupported || this.isSubmitting) {
+			return
+		}
+
+		let options = {
+			challenge: decodeBase64URL(this.challenge),
+			rpId: this.rpId,
+			timeout: 60000,
+			userVerification: this.passwordless ? "required" : "preferred",
+			allowCredentials: this.credentialIds.map(function (id) {
+				return {
+					type: "public-key",
+					id: decodeBase64URL(id)
+				}
+			})
+		}
+
+		this.isSubmitting = true
+		let that = this
+		navigator.credentials.get({publicKey: options})
+			.then(function (credential) {
+				that.$post("/index/webauthn")
+					.params({
+						token: that.token,
+						credentialId: encodeBase64URL(credential.rawId),
+						clientDataJSON: encodeBase64URL(credential.response.clientDataJSON),
+						authenticatorData: encodeBase64URL(credential.response.authenticatorData),
+						signature: encodeBase64URL(credential.response.signature)
+					})
+					.success(function (resp) {
+						that.submitSuccess(resp)
+					})
+					.fail(function (resp) {
+						teaweb.warn(resp.message)
+					})
+					.done(function () {
+						that.isSubmitting = false
+					})
+			})
+			.catch(function (err) {
+				that.isSubmitting = false
+				if (err.name != "NotAllowedError") {
+					teaweb.warn("安全密钥验证失败：" + err.message)
+				}
+			})
+	}
+
+	this.showRecovery = function () {
+		this.recoveryVisible = !this.recoveryVisible
+		if (this.recoveryVisible) {
+			this.$delay(function () {
+				this.$refs.recoveryCodeRef.focus()
+			})
+		}
+	}
+
+	this.submitRecovery = function () {
+		if (!this.recoveryVisible || this.isSubmitting) {
+			return
+		}
+		if (this.recoveryCode.trim().length == 0) {
+			teaweb.warn("请输入恢复码")
+			return
+		}
+		this.isSubmitting = true
+		this.$post("/index/webauthn")
+			.params({
+				token: this.token,
+				recoveryCode: this.recoveryCode
+			})
+			.success(function (resp) {
+				this.submitSuccess(resp)
+			})
+			.fail(function (resp) {
+				teaweb.warn(resp.message)
+			})
+			.done(function () {
+				this.isSubmitting = false
+			})
+	}
+
+	this.submitSuccess = function (resp) {
+		// store information to local
+		localStorage.setItem("sid", resp.data.localSid)
+		localStorage.setItem("ip", resp.data.ip)
+
+		// redirect back
+		this.$delay(function () {
+			if (this.from.length == 0) {
+				window.location = "/dashboard";
+			} else {
+				window.location = this.from;
+			}
+		})
+	}
+})
@@ -0,0 +1,6 @@
+Tea.context(function () {
+	this.$delay(function () {
+		teaweb.datepicker("day-from-picker")
+		teaweb.datepicker("day-to-picker")
+	})
+})
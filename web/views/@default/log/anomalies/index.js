@@ -0,0 +1,17 @@
+Tea.context(function () {
+	this.read = function (alertId) {
+		this.$post(".read")
+			.params({
+				alertIds: [alertId]
+			})
+			.refresh()
+	}
+
+	this.readAll = function () {
+		let that = this
+		teaweb.confirm("确定要将所有异常活动设置为已读吗？", function () {
+			that.$post(".read")
+				.refresh()
+		})
+	}
+})
@@ -0,0 +1,19 @@
+Tea.context(function () {
+	if (this.errorMsg.length == 0) {
+		// 需要继续验证安全密钥或OTP
+		if (this.secondFactorURL.length > 0) {
+			window.location = this.secondFactorURL
+			return
+		}
+
+		// store information to local
+		localStorage.setItem("sid", this.localSid)
+		localStorage.setItem("ip", this.ip)
+
+		if (this.redirect.length > 0) {
+			window.location = this.redirect
+		} else {
+			window.location = "/dashboard"
+		}
+	}
+})
\ No newline at end of file
@@ -1,4 +1,10 @@
 Tea.context(function () {
+	if (this.errorMsg.length == 0 && this.secondFactorURL.length > 0) {
+		// 需要继续验证安全密钥或OTP
+		window.location = this.secondFactorURL
+		return
+	}
+
 	// store information to local
 	localStorage.setItem("sid", this.localSid)
 	localStorage.setItem("ip", this.ip)
@@ -19,7 +19,12 @@ Tea.context(function () {
 				.params({
 					firewallPolicyId: policyId
 				})
-				.refresh()
+				.success(function (resp) {
+					if (NotifyChangeRequest(resp)) {
+						return
+					}
+					teaweb.reload()
+				})
 		})
 	}
 })
\ No newline at end of file
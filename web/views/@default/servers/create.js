@@ -4,7 +4,18 @@ Tea.context(function () {
 	this.origins = []
 	this.defaultAddresses = []
 
-	this.success = NotifySuccess("保存成功", "/servers");
+	this.success = function (resp) {
+		let serverId = resp.data.serverId
+		let that = this
+		teaweb.success("保存成功", function () {
+			// HTTP网站创建后跳转到域名设置，检查域名接入情况
+			if (serverId != null && serverId > 0 && (that.serverType == "httpProxy" || that.serverType == "httpWeb")) {
+				window.location = "/servers/server/settings/serverNames?serverId=" + serverId
+				return
+			}
+			window.location = "/servers"
+		})
+	}
 
 	this.fail = function (resp) {
 		if (resp.errors != null && resp.errors.length > 0) {
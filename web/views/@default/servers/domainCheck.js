@@ -0,0 +1,10 @@
+Tea.context(function () {
+	this.runCheck = function () {
+		this.$post("/servers/domainCheck/run")
+			.success(function () {
+				teaweb.success("已开始检查，请稍后刷新页面查看结果", function () {
+					teaweb.reload()
+				})
+			})
+	}
+})
@@ -100,7 +100,10 @@ Tea.context(function () {
 				.params({
 					serverIds: this.checkedServerIds
 				})
-				.success(function () {
+				.success(function (resp) {
+					if (NotifyChangeRequest(resp)) {
+						return
+					}
 					teaweb.reload()
 				})
 		})
@@ -0,0 +1,16 @@
+Tea.context(function () {
+	this.form = {
+		name: "",
+		targetType: "server",
+		targetIds: "",
+		groupIds: [],
+		period: "weekly",
+		weekDay: 1,
+		monthDay: 1,
+		hour: 9,
+		emails: "",
+		isOn: true
+	}
+
+	this.success = NotifySuccess("保存成功", "/servers/reports")
+})
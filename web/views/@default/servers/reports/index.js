@@ -0,0 +1,26 @@
+Tea.context(function () {
+	this.sendReport = function (reportId) {
+		let that = this
+		teaweb.confirm("确定要立即统计并发送此报表吗？", function () {
+			that.$post("/servers/reports/send")
+				.params({
+					reportId: reportId
+				})
+				.timeout(120)
+				.success(function () {
+					teaweb.success("发送成功")
+				})
+		})
+	}
+
+	this.deleteReport = function (reportId) {
+		let that = this
+		teaweb.confirm("确定要删除此报表吗？", function () {
+			that.$post("/servers/reports/delete")
+				.params({
+					reportId: reportId
+				})
+				.refresh()
+		})
+	}
+})
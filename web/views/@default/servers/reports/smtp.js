@@ -0,0 +1,24 @@
+Tea.context(function () {
+	this.success = NotifyReloadSuccess("保存成功")
+
+	this.testEmail = ""
+	this.isTesting = false
+
+	this.sendTestMail = function () {
+		if (this.isTesting) {
+			return
+		}
+		this.isTesting = true
+		this.$post("/servers/reports/smtpTest")
+			.params({
+				email: this.testEmail
+			})
+			.timeout(60)
+			.success(function () {
+				teaweb.success("发送成功")
+			})
+			.done(function () {
+				this.isTesting = false
+			})
+	}
+})
@@ -0,0 +1,16 @@
+Tea.context(function () {
+	this.form = {
+		name: this.report.name,
+		targetType: this.report.targetType,
+		targetIds: this.report.targetIds,
+		groupIds: this.report.groupIds,
+		period: this.report.period,
+		weekDay: this.report.weekDay,
+		monthDay: this.report.monthDay,
+		hour: this.report.hour,
+		emails: this.report.emails,
+		isOn: this.report.isOn
+	}
+
+	this.success = NotifySuccess("保存成功", "/servers/reports")
+})
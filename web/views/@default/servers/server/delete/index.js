@@ -5,7 +5,10 @@ Tea.context(function () {
 				.params({
 					"serverId": serverId
 				})
-				.success(function () {
+				.success(function (resp) {
+					if (NotifyChangeRequest(resp)) {
+						return
+					}
 					teaweb.success("删除成功", function () {
 						window.location = "/servers"
 					})
@@ -0,0 +1,92 @@
+Tea.context(function () {
+	this.sectionCodes = []
+	this.certStrategy = "keep"
+	this.copyServerNames = false
+
+	this.clusterId = 0
+	this.keyword = ""
+	this.servers = []
+	this.targetServers = []
+
+	this.previewResults = null
+	this.runResults = null
+
+	this.selectAllSections = function () {
+		this.sectionCodes = this.sections.map(function (section) {
+			return section.code
+		})
+	}
+
+	this.searchServers = function () {
+		this.$post(".servers")
+			.params({
+				serverId: this.serverId,
+				clusterId: this.clusterId,
+				keyword: this.keyword
+			})
+			.success(function (resp) {
+				this.servers = resp.data.servers
+			})
+	}
+
+	this.isTarget = function (serverId) {
+		return this.targetServers.$any(function (k, server) {
+			return server.id == serverId
+		})
+	}
+
+	this.addTarget = function (server) {
+		this.targetServers.push(server)
+		this.previewResults = null
+	}
+
+	this.removeTarget = function (index) {
+		this.targetServers.$remove(index)
+		this.previewResults = null
+	}
+
+	this.buildParams = function () {
+		return {
+			serverId: this.serverId,
+			targetServerIds: this.targetServers.map(function (server) {
+				return server.id
+			}),
+			sectionCodes: this.sectionCodes,
+			certStrategy: this.certStrategy,
+			copyServerNames: this.copyServerNames ? 1 : 0
+		}
+	}
+
+	this.preview = function () {
+		this.runResults = null
+		this.$post(".preview")
+			.params(this.buildParams())
+			.success(function (resp) {
+				this.previewResults = resp.data.results
+			})
+	}
+
+	this.run = function () {
+		let that = this
+		teaweb.confirm("确定要将配置复制到选中的" + this.targetServers.length + "个网站吗？目标网站中对应的配置将被覆盖。", function () {
+			that.$post(".run")
+				.params(that.buildParams())
+				.success(function (resp) {
+					that.previewResults = null
+					that.runResults = resp.data.results
+					if (resp.data.countFailed == 0) {
+						teaweb.successToast("复制成功")
+					} else {
+						teaweb.warn("有" + resp.data.countFailed + "个网站复制失败，请查看结果")
+					}
+				})
+		})
+	}
+
+	this.formatValue = function (value) {
+		if (typeof value == "object") {
+			return JSON.stringify(value)
+		}
+		return value.toString()
+	}
+})
@@ -28,4 +28,27 @@ Tea.context(function () {
 	})
 
 	this.auditing = 1
-})
\ No newline at end of file
+
+	// 接入检查
+	this.isChecking = false
+	this.checkResults = []
+	this.checkDomains = function () {
+		this.isChecking = true
+		this.$post(".check")
+			.params({
+				serverId: this.serverId
+			})
+			.success(function (resp) {
+				this.checkResults = resp.data.results
+			})
+			.done(function () {
+				this.isChecking = false
+			})
+	}
+
+	if (!this.isAuditing && this.allServerNames.length > 0) {
+		this.$delay(function () {
+			this.checkDomains()
+		})
+	}
+})
@@ -0,0 +1,42 @@
+Tea.context(function () {
+	this.planResult = null
+
+	this.plan = function () {
+		this.$post(".plan")
+			.params({
+				serverId: this.serverId,
+				yaml: this.yaml
+			})
+			.success(function (resp) {
+				this.planResult = resp.data
+			})
+	}
+
+	this.apply = function () {
+		let that = this
+		teaweb.confirm("确定要应用这些变更吗？中途失败时已应用的部分不会回滚。", function () {
+			that.$post(".apply")
+				.params({
+					serverId: that.serverId,
+					yaml: that.yaml
+				})
+				.success(function () {
+					teaweb.successToast("应用成功", null, function () {
+						teaweb.reload()
+					})
+				})
+				.fail(function (resp) {
+					// 部分应用后需要重新生成计划
+					that.planResult = null
+					teaweb.warn(resp.message)
+				})
+		})
+	}
+
+	this.formatValue = function (value) {
+		if (typeof value == "object") {
+			return JSON.stringify(value, null, 2)
+		}
+		return value.toString()
+	}
+})
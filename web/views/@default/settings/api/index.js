@@ -1,4 +1,32 @@
 Tea.context(function () {
+	this.isProbing = false
+
+	// 测试API地址延迟
+	this.probeEndpoints = function () {
+		this.isProbing = true
+		this.$post(".probeEndpoints")
+			.timeout(60)
+			.success(function () {
+				teaweb.reload()
+			})
+			.done(function () {
+				this.isProbing = false
+			})
+	}
+
+	// 固定或取消固定API地址
+	this.updateEndpointsPin = function (isPinned) {
+		let message = isPinned ? "确定要固定当前使用的API地址吗？固定后将不再根据延迟自动调整。" : "确定要取消固定API地址吗？取消后将根据延迟自动调整。"
+		let that = this
+		teaweb.confirm(message, function () {
+			that.$post(".updateEndpointsPin")
+				.params({
+					isPinned: isPinned
+				})
+				.refresh()
+		})
+	}
+
 	// 创建节点
 	this.createNode = function () {
 		teaweb.popup(".node.createPopup", {
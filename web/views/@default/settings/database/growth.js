@@ -0,0 +1,120 @@
+Tea.context(function () {
+	this.growthWindowDays = 7
+
+	// 保留天数
+	let originRetentionMap = {}
+	this.retentionMap = {}
+	let that = this
+	this.families.forEach(function (family) {
+		if (family.hasRetention) {
+			originRetentionMap[family.code] = family.retentionDays
+			that.retentionMap[family.code] = family.retentionDays
+		}
+	})
+
+	this.retentionChanged = function (family) {
+		return parseInt(this.retentionMap[family.code]) != originRetentionMap[family.code]
+	}
+
+	// 和后台dbgrowthutils.ProjectRetention()算法一致
+	this.projectRetention = function (family) {
+		let newDays = parseInt(this.retentionMap[family.code])
+		if (isNaN(newDays) || newDays <= 0) {
+			return "不限制"
+		}
+		let currentDays = originRetentionMap[family.code]
+		if (currentDays > 0) {
+			return teaweb.formatBytes(family.bytes * newDays / currentDays)
+		}
+		if (family.dailyGrowth <= 0) {
+			return "无法预测"
+		}
+		return teaweb.formatBytes(family.dailyGrowth * newDays)
+	}
+
+	this.beforeSave = function () {
+		for (let code in this.retentionMap) {
+			let days = parseInt(this.retentionMap[code])
+			if (isNaN(days)) {
+				days = 0
+			}
+			this.retentionMap[code] = days
+		}
+	}
+
+	this.success = NotifyReloadSuccess("保存成功")
+
+	this.takeSnapshot = function () {
+		this.$post("/settings/database/growth/snapshot")
+			.success(function () {
+				teaweb.reload()
+			})
+	}
+
+	this.$delay(function () {
+		this.reloadChart()
+	})
+
+	this.reloadChart = function () {
+		let chartBox = document.getElementById("growth-chart")
+		if (chartBox == null || this.chartSeries.length == 0) {
+			return
+		}
+
+		let totals = this.chartDays.map(function (day, index) {
+			let total = 0
+			that.chartSeries.forEach(function (series) {
+				total += series.values[index]
+			})
+			return total
+		})
+		let axis = teaweb.bytesAxis(totals, function (v) {
+			return v
+		})
+
+		let chart = teaweb.initChart(chartBox)
+		chart.setOption({
+			xAxis: {
+				data: this.chartDays
+			},
+			yAxis: {
+				axisLabel: {
+					formatter: function (value) {
+						return value + axis.unit
+					}
+				}
+			},
+			legend: {
+				type: "scroll",
+				top: 0
+			},
+			tooltip: {
+				show: true,
+				trigger: "item",
+				formatter: function (args) {
+					let series = that.chartSeries[args.seriesIndex]
+					return that.chartDays[args.dataIndex] + "<br/>" + series.name + ": " + teaweb.formatBytes(series.values[args.dataIndex])
+				}
+			},
+			grid: {
+				left: 50,
+				top: 40,
+				right: 20,
+				bottom: 20
+			},
+			series: this.chartSeries.map(function (series) {
+				return {
+					name: series.name,
+					type: "line",
+					stack: "total",
+					areaStyle: {},
+					data: series.values.map(function (v) {
+						return v / axis.divider
+					})
+				}
+			}),
+			animation: false
+		})
+		chart.resize()
+	}
+})
@@ -0,0 +1,118 @@
+Tea.context(function () {
+	this.target = {
+		host: "",
+		port: "3306",
+		database: "edges",
+		username: "",
+		password: ""
+	}
+	this.isTesting = false
+	this.testResult = ""
+
+	this.$delay(function () {
+		this.reload()
+	}, 2000)
+
+	this.reload = function () {
+		if (this.migration == null || !this.migration.isRunning) {
+			return
+		}
+		this.$post("/settings/database/migrate/status")
+			.success(function (resp) {
+				this.migration = resp.data.migration
+			})
+			.done(function () {
+				this.$delay(function () {
+					this.reload()
+				}, 2000)
+			})
+	}
+
+	this.testTarget = function () {
+		this.isTesting = true
+		this.testResult = ""
+		this.$post("/settings/database/migrate/test")
+			.params(this.target)
+			.success(function (resp) {
+				let result = "连接成功，MySQL版本：" + resp.data.target.version
+				if (resp.data.target.countExistingTables > 0) {
+					result += "，目标数据库中已有" + resp.data.target.countExistingTables + "个数据表"
+				}
+				this.testResult = result
+			})
+			.done(function () {
+				this.isTesting = false
+			})
+	}
+
+	this.before = function () {
+		this.testResult = ""
+	}
+
+	this.success = function () {
+		teaweb.success("已开始迁移", function () {
+			teaweb.reload()
+		})
+	}
+
+	this.verify = function () {
+		this.$post("/settings/database/migrate/verify")
+			.refresh()
+	}
+
+	this.recopy = function () {
+		let that = this
+		teaweb.confirm("确定要重新复制所有数据表吗？为了保证数据一致，所有数据表都会在新的快照中重新复制，目标数据库中的这些表会被删除后重新复制。", function () {
+			that.$post("/settings/database/migrate/recopy")
+				.refresh()
+		})
+	}
+
+	this.switchDB = function (force) {
+		let that = this
+		let message = "确定要将API节点切换到新数据库吗？切换后会重启本地API节点，原来的配置会被保留用于回滚。"
+		if (force) {
+			message = "有数据表的校验和不一致，确定要强制切换到新数据库吗？"
+		}
+		teaweb.confirm(message, function () {
+			that.$post("/settings/database/migrate/switch")
+				.params({
+					force: force ? 1 : 0
+				})
+				.success(function (resp) {
+					that.notifyRestarted(resp.data.hasLocalAPINode, "已切换到新数据库")
+				})
+		})
+	}
+
+	this.rollback = function () {
+		let that = this
+		teaweb.confirm("确定要回滚到原数据库吗？切换后写入新数据库的数据不会同步到原数据库。", function () {
+			that.$post("/settings/database/migrate/rollback")
+				.success(function (resp) {
+					that.notifyRestarted(resp.data.hasLocalAPINode, "已回滚到原数据库")
+				})
+		})
+	}
+
+	this.notifyRestarted = function (hasLocalAPINode, message) {
+		if (!hasLocalAPINode) {
+			message += "，请重启所有API节点使配置生效"
+		}
+		teaweb.success(message, function () {
+			teaweb.reload()
+		})
+	}
+
+	this.clear = function () {
+		let that = this
+		let message = "确定要清除迁移记录吗？"
+		if (this.migration.status == "switched") {
+			message = "确定要清除迁移记录吗？清除后将无法回滚到原数据库。"
+		}
+		teaweb.confirm(message, function () {
+			that.$post("/settings/database/migrate/clear")
+				.refresh()
+		})
+	}
+})
@@ -0,0 +1,85 @@
+Tea.context(function () {
+	this.success = NotifyReloadSuccess("保存成功")
+
+	this.groupMappings = (this.config.groupMappings == null) ? [] : this.config.groupMappings
+	this.groupMappings.forEach(function (mapping) {
+		if (mapping.moduleCodes == null) {
+			mapping.moduleCodes = []
+		}
+	})
+
+	this.moduleName = function (code) {
+		let module = this.modules.$find(function (k, v) {
+			return v.code == code
+		})
+		if (module == null) {
+			return code
+		}
+		return module.name
+	}
+
+	// 分组权限
+	this.addingMapping = false
+	this.addingGroup = ""
+	this.addingModuleCodes = []
+
+	this.addGroupMapping = function () {
+		this.addingMapping = true
+		this.addingGroup = ""
+		this.addingModuleCodes = []
+	}
+
+	this.confirmGroupMapping = function () {
+		let group = this.addingGroup.trim()
+		if (group.length == 0) {
+			teaweb.warn("请输入分组")
+			return
+		}
+		this.groupMappings.push({
+			group: group,
+			moduleCodes: this.addingModuleCodes
+		})
+		this.cancelGroupMapping()
+	}
+
+	this.cancelGroupMapping = function () {
+		this.addingMapping = false
+	}
+
+	this.removeGroupMapping = function (index) {
+		this.groupMappings.$remove(index)
+	}
+
+	// 关联管理员
+	this.addLink = function () {
+		teaweb.popup("/settings/login/ldapLinkPopup", {
+			height: "20em",
+			callback: function (resp) {
+				if (NotifyChangeRequest(resp)) {
+					return
+				}
+				teaweb.success("保存成功", function () {
+					teaweb.reload()
+				})
+			}
+		})
+	}
+
+	this.deleteLink = function (dn) {
+		let that = this
+		teaweb.confirm("确定要删除此关联吗？", function () {
+			that.$post(".ldapLinkDelete")
+				.params({
+					dn: dn
+				})
+				.refresh()
+		})
+	}
+
+	// 测试
+	this.test = function () {
+		teaweb.popup("/settings/login/ldapTestPopup", {
+			height: "26em"
+		})
+	}
+})
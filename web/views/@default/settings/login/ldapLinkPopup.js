@@ -0,0 +1,3 @@
+Tea.context(function () {
+	this.success = NotifyPopup
+})
@@ -0,0 +1,14 @@
+Tea.context(function () {
+	this.result = null
+	this.errorMessage = ""
+
+	this.success = function (resp) {
+		this.errorMessage = ""
+		this.result = resp.data.result
+	}
+
+	this.fail = function (resp) {
+		this.result = null
+		this.errorMessage = resp.message
+	}
+})
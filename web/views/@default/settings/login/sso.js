@@ -0,0 +1,53 @@
+Tea.context(function () {
+	this.success = NotifyReloadSuccess("保存成功")
+
+	this.oidcScopes = (this.config.oidc.scopes == null) ? "" : this.config.oidc.scopes.join(" ")
+	this.groupMappings = (this.config.groupMappings == null) ? [] : this.config.groupMappings
+	this.groupMappings.forEach(function (mapping) {
+		if (mapping.moduleCodes == null) {
+			mapping.moduleCodes = []
+		}
+	})
+
+	this.moduleName = function (code) {
+		let module = this.modules.$find(function (k, v) {
+			return v.code == code
+		})
+		if (module == null) {
+			return code
+		}
+		return module.name
+	}
+
+	// 分组权限
+	this.addingMapping = false
+	this.addingGroup = ""
+	this.addingModuleCodes = []
+
+	this.addGroupMapping = function () {
+		this.addingMapping = true
+		this.addingGroup = ""
+		this.addingModuleCodes = []
+	}
+
+	this.confirmGroupMapping = function () {
+		let group = this.addingGroup.trim()
+		if (group.length == 0) {
+			teaweb.warn("请输入分组名称")
+			return
+		}
+		this.groupMappings.push({
+			group: group,
+			moduleCodes: this.addingModuleCodes
+		})
+		this.cancelGroupMapping()
+	}
+
+	this.cancelGroupMapping = function () {
+		this.addingMapping = false
+	}
+
+	this.removeGroupMapping = function (index) {
+		this.groupMappings.$remove(index)
+	}
+})
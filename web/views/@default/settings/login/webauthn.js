@@ -0,0 +1,168 @@
+Tea.context(function () {
+	this.isSupported = (window.PublicKeyCredential != null && navigator.credentials != null)
+	this.isRegistering = false
+	this.newName = ""
+	this.recoveryCodes = []
+
+	// base64url和ArrayBuffer之间转换
+	let decodeBase64URL = function (s) {
+		s = s.replace(/-/g, "+").replace(/_/g, "/")
+		while (s.length % 4 != 0) {
+			s += "="
+		}
+		let raw = window.atob(s)
+		let bytes = new Uint8Array(raw.length)
+		for (let i = 0; i < raw.length; i++) {
+			bytes[i] = raw.charCodeAt(i)
+		}
+		return bytes.buffer
+	}
+	let encodeBase64URL = function (buffer) {
+		let bytes = new Uint8Array(buffer)
+		let raw = ""
+		for (let i = 0; i < bytes.length; i++) {
+			raw += String.fromCharCode(bytes[i])
+		}
+		return window.btoa(raw).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "")
+	}
+
+	/**
+	 * 注册
+	 */
+	this.register = function () {
+		if (this.isRegistering) {
+			return
+		}
+		let name = this.newName.trim()
+		if (name.length == 0) {
+			teaweb.warn("请输入安全密钥名称")
+			return
+		}
+
+		let options = {
+			challenge: decodeBase64URL(this.options.challenge),
+			rp: {
+				id: this.options.rpId,
+				name: document.title
+			},
+			user: {
+				id: decodeBase64URL(this.options.userId),
+				name: this.options.username,
+				displayName: this.options.fullname
+			},
+			pubKeyCredParams: this.options.algorithms.map(function (alg) {
+				return {
+					type: "public-key",
+					alg: alg
+				}
+			}),
+			excludeCredentials: this.options.credentialIds.map(function (id) {
+				return {
+					type: "public-key",
+					id: decodeBase64URL(id)
+				}
+			}),
+			authenticatorSelection: {
+				residentKey: "preferred",
+				userVerification: "preferred"
+			},
+			attestation: "none",
+			timeout: 60000
+		}
+
+		this.isRegistering = true
+		let that = this
+		navigator.credentials.create({publicKey: options})
+			.then(function (credential) {
+				that.$post("/settings/login/webauthn")
+					.params({
+						name: name,
+						clientDataJSON: encodeBase64URL(credential.response.clientDataJSON),
+						attestationObject: encodeBase64URL(credential.response.attestationObject)
+					})
+					.success(function (resp) {
+						if (resp.data.recoveryCodes != null && resp.data.recoveryCodes.length > 0) {
+							that.recoveryCodes = resp.data.recoveryCodes
+							return
+						}
+						teaweb.success("注册成功", function () {
+							teaweb.reload()
+						})
+					})
+					.fail(function (resp) {
+						teaweb.warn(resp.message, function () {
+							teaweb.reload()
+						})
+					})
+					.done(function () {
+						that.isRegistering = false
+					})
+			})
+			.catch(function (err) {
+				that.isRegistering = false
+				if (err.name == "InvalidStateError") {
+					teaweb.warn("此安全密钥已经注册过")
+				} else if (err.name != "NotAllowedError") {
+					teaweb.warn("注册失败：" + err.message)
+				}
+			})
+	}
+
+	/**
+	 * 改名
+	 */
+	this.renamingCredentialId = ""
+	this.renamingName = ""
+
+	this.rename = function (credential) {
+		this.renamingCredentialId = credential.id
+		this.renamingName = credential.name
+	}
+
+	this.cancelRename = function () {
+		this.renamingCredentialId = ""
+	}
+
+	this.saveRename = function (credential) {
+		this.$post("/settings/login/webauthn/rename")
+			.params({
+				credentialId: credential.id,
+				name: this.renamingName
+			})
+			.success(function () {
+				credential.name = this.renamingName.trim()
+				this.renamingCredentialId = ""
+			})
+	}
+
+	/**
+	 * 撤销
+	 */
+	this.deleteCredential = function (credential) {
+		let that = this
+		teaweb.confirm("确定要撤销安全密钥“" + credential.name + "”吗？撤销后将无法再使用它登录。", function () {
+			that.$post("/settings/login/webauthn/delete")
+				.params({
+					credentialId: credential.id
+				})
+				.refresh()
+		})
+	}
+
+	/**
+	 * 恢复码
+	 */
+	this.resetRecoveryCodes = function () {
+		let that = this
+		teaweb.confirm("确定要重新生成恢复码吗？旧的恢复码将全部失效。", function () {
+			that.$post("/settings/login/webauthn/recoveryCodes")
+				.success(function (resp) {
+					that.recoveryCodes = resp.data.recoveryCodes
+				})
+		})
+	}
+
+	this.finishRecoveryCodes = function () {
+		teaweb.reload()
+	}
+})
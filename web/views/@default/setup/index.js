@@ -179,7 +179,7 @@ Tea.context(function () {
 	this.installMySQL = function () {
 		let that = this
 		teaweb.popup("/setup/mysql/installPopup", {
-			height: "28em",
+			height: "36em",
 			onClose: function () {
 				that.detectDB()
 			}
@@ -1,5 +1,6 @@
 Tea.context(function () {
 	this.result = {
+		mode: "online",
 		isInstalling: false,
 		isInstalled: false,
 		isOk: false,
@@ -17,6 +18,7 @@ Tea.context(function () {
 	})
 
 	this.install = function () {
+		this.result.mode = "online"
 		this.result.isInstalling = true
 		this.result.isInstalled = false
 		this.result.logs = []
@@ -24,16 +26,86 @@ Tea.context(function () {
 		this.$post(".installPopup")
 			.timeout(3600)
 			.success(function (resp) {
-				this.result.isOk = resp.data.isOk
-				if (!resp.data.isOk) {
-					this.result.err = resp.data.err
-				} else {
-					this.result.user = resp.data.user
-					this.result.password = resp.data.password
-					this.result.dir = resp.data.dir
+				this.finishInstall(resp)
+			})
+	}
+
+	this.finishInstall = function (resp) {
+		this.result.isOk = resp.data.isOk
+		if (!resp.data.isOk) {
+			this.result.err = resp.data.err
+		} else {
+			this.result.user = resp.data.user
+			this.result.password = resp.data.password
+			this.result.dir = resp.data.dir
+		}
+		this.result.isInstalled = true
+		this.result.isInstalling = false
+	}
+
+	/**
+	 * 离线安装
+	 */
+	this.offline = {
+		packagePath: (this.packageFiles.package.length > 0) ? this.packageFiles.package[0].path : "",
+		customPackagePath: "",
+		checksum: "",
+		manifestPath: (this.packageFiles.manifest.length > 0) ? this.packageFiles.manifest[0].path : "",
+		signaturePath: (this.packageFiles.signature.length > 0) ? this.packageFiles.signature[0].path : "",
+
+		isChecking: false,
+		report: null,
+		hasFailures: false
+	}
+
+	this.uploadSuccess = function () {
+		teaweb.success("上传成功", function () {
+			teaweb.reload()
+		})
+	}
+
+	this.offlineParams = function () {
+		let packagePath = this.offline.packagePath
+		if (packagePath.length == 0) {
+			packagePath = this.offline.customPackagePath
+		}
+		return {
+			packagePath: packagePath,
+			checksum: this.offline.checksum,
+			manifestPath: this.offline.manifestPath,
+			signaturePath: this.offline.signaturePath
+		}
+	}
+
+	this.preflight = function () {
+		this.offline.isChecking = true
+		this.offline.report = null
+		this.$post(".preflight")
+			.params(this.offlineParams())
+			.timeout(600)
+			.success(function (resp) {
+				this.offline.report = resp.data.report
+				this.offline.hasFailures = resp.data.hasFailures
+			})
+			.done(function () {
+				this.offline.isChecking = false
+			})
+	}
+
+	this.installOffline = function () {
+		this.result.mode = "offline"
+		this.result.isInstalling = true
+		this.result.isInstalled = false
+		this.result.logs = []
+
+		this.$post(".installOffline")
+			.params(this.offlineParams())
+			.timeout(3600)
+			.success(function (resp) {
+				if (resp.data.report != null) {
+					this.offline.report = resp.data.report
 				}
-				this.result.isInstalled = true
-				this.result.isInstalling = false
+				this.finishInstall(resp)
 			})
 	}
 